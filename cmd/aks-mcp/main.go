@@ -18,6 +18,12 @@ func main() {
 	cfg := config.NewConfig()
 	cfg.ParseFlags()
 
+	// Load the optional configuration file (cluster context aliases)
+	if err := cfg.LoadConfigFile(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config file: %v\n", err)
+		os.Exit(1)
+	}
+
 	// Create validator and run validation checks
 	v := config.NewValidator(cfg)
 	if !v.Validate() {
@@ -6,8 +6,11 @@ import (
 
 	"github.com/Azure/aks-mcp/internal/command"
 	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/confirm"
+	"github.com/Azure/aks-mcp/internal/dryrun"
 	"github.com/Azure/aks-mcp/internal/security"
 	"github.com/Azure/aks-mcp/internal/tools"
+	"github.com/Azure/aks-mcp/internal/utils"
 )
 
 // AzExecutor implements the CommandExecutor interface for az commands
@@ -72,6 +75,11 @@ func (e *AzExecutor) ExecuteSpecificCommand(cmd string, params map[string]interf
 		fullCmd += " " + args
 	}
 
+	toolName := utils.ReplaceSpacesWithUnderscores(cmd)
+	if dryrun.Requested(params) {
+		return previewCommand(toolName, cmd, fullCmd, nil, cfg)
+	}
+
 	// Validate the command against security settings
 	validator := security.NewValidator(cfg.SecurityConfig)
 	err := validator.ValidateCommand(fullCmd, security.CommandTypeAz)
@@ -79,6 +87,20 @@ func (e *AzExecutor) ExecuteSpecificCommand(cmd string, params map[string]interf
 		return "", err
 	}
 
+	// Destructive commands only run once the user has confirmed them
+	confirmation, err := confirm.DefaultGate().Check(confirm.Request{
+		Tool:      toolName,
+		Operation: cmd,
+		Target:    fullCmd,
+		Args:      map[string]interface{}{"args": args},
+	}, params)
+	if err != nil {
+		return "", err
+	}
+	if confirmation != nil {
+		return confirmation.JSON()
+	}
+
 	// Extract binary name from command (should be "az")
 	cmdParts := strings.Fields(fullCmd)
 	if len(cmdParts) == 0 {
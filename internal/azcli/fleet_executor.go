@@ -6,6 +6,8 @@ import (
 
 	"github.com/Azure/aks-mcp/internal/components/fleet/kubernetes"
 	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/confirm"
+	"github.com/Azure/aks-mcp/internal/dryrun"
 )
 
 // FleetExecutor handles structured fleet command execution
@@ -14,6 +16,7 @@ type FleetExecutor struct {
 	k8sClient            *kubernetes.Client
 	placementOps         *kubernetes.PlacementOperations
 	k8sClientInitialized bool
+	gate                 *confirm.Gate
 }
 
 // NewFleetExecutor creates a new fleet command executor
@@ -21,6 +24,7 @@ func NewFleetExecutor() *FleetExecutor {
 	return &FleetExecutor{
 		AzExecutor:           NewExecutor(),
 		k8sClientInitialized: false,
+		gate:                 confirm.DefaultGate(),
 	}
 }
 
@@ -42,12 +46,20 @@ func (e *FleetExecutor) Execute(params map[string]interface{}, cfg *config.Confi
 		return "", fmt.Errorf("args parameter is required and must be a string")
 	}
 
+	dryRun := dryrun.Requested(params)
+
 	// Route clusterresourceplacement operations to Kubernetes
 	if resource == "clusterresourceplacement" {
 		// Validate clusterresourceplacement operations separately
 		if err := e.validateClusterResourcePlacementCombination(operation); err != nil {
 			return "", err
 		}
+		if dryRun {
+			return e.previewClusterResourcePlacement(operation, args, cfg)
+		}
+		if confirmation, err := e.confirmOperation(operation, resource, args, "clusterresourceplacement "+args, params, cfg); err != nil || confirmation != "" {
+			return confirmation, err
+		}
 		return e.executeKubernetesClusterResourcePlacement(operation, args, cfg)
 	}
 
@@ -68,17 +80,24 @@ func (e *FleetExecutor) Execute(params map[string]interface{}, cfg *config.Confi
 		command = fmt.Sprintf("az fleet %s %s", resource, operation)
 	}
 
-	// Check access level
-	if err := e.checkAccessLevel(operation, resource, cfg.AccessLevel); err != nil {
-		return "", err
-	}
-
 	// Build full command with args
 	fullCommand := command
 	if args != "" {
 		fullCommand = fmt.Sprintf("%s %s", command, args)
 	}
 
+	// Check access level
+	accessErr := e.checkAccessLevel(operation, resource, cfg.AccessLevel)
+	if dryRun {
+		return previewCommand("az_fleet", operation, fullCommand, accessErr, cfg)
+	}
+	if accessErr != nil {
+		return "", accessErr
+	}
+	if confirmation, err := e.confirmOperation(operation, resource, args, fullCommand, params, cfg); err != nil || confirmation != "" {
+		return confirmation, err
+	}
+
 	// Create params for the base executor
 	execParams := map[string]interface{}{
 		"command": fullCommand,
@@ -131,6 +150,25 @@ func (e *FleetExecutor) checkAccessLevel(operation, resource string, accessLevel
 	return nil
 }
 
+// confirmOperation asks for confirmation of destructive operations the access level allows.
+// It returns the confirmation request to send back, or an empty string when the operation can run.
+func (e *FleetExecutor) confirmOperation(operation, resource, args, target string, params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+	if err := e.checkAccessLevel(operation, resource, cfg.AccessLevel); err != nil {
+		return "", err
+	}
+
+	confirmation, err := e.gate.Check(confirm.Request{
+		Tool:      "az_fleet",
+		Operation: operation,
+		Target:    target,
+		Args:      map[string]interface{}{"resource": resource, "args": args},
+	}, params)
+	if err != nil || confirmation == nil {
+		return "", err
+	}
+	return confirmation.JSON()
+}
+
 // GetCommandForValidation returns the constructed command for security validation
 func (e *FleetExecutor) GetCommandForValidation(operation, resource, args string) string {
 	var command string
@@ -263,13 +301,27 @@ func (e *FleetExecutor) validateClusterResourcePlacementCombination(operation st
 
 // createClusterResourcePlacement creates a clusterresourceplacement using placement operations
 func (e *FleetExecutor) createClusterResourcePlacement(args map[string]string, cfg *config.ConfigData) (string, error) {
+	name, selector, policy, err := resolvePlacementCreateArgs(args)
+	if err != nil {
+		return "", err
+	}
+
+	if e.placementOps == nil {
+		return "", fmt.Errorf("clusterresourceplacement operations not initialized")
+	}
+
+	return e.placementOps.CreatePlacement(name, selector, policy, cfg)
+}
+
+// resolvePlacementCreateArgs validates the create arguments and applies the default policy
+func resolvePlacementCreateArgs(args map[string]string) (name, selector, policy string, err error) {
 	name, ok := args["name"]
 	if !ok || name == "" {
-		return "", fmt.Errorf("--name is required for create operation")
+		return "", "", "", fmt.Errorf("--name is required for create operation")
 	}
 
-	selector := args["selector"]
-	policy := args["policy"]
+	selector = args["selector"]
+	policy = args["policy"]
 
 	// Default policy if not specified
 	if policy == "" {
@@ -278,23 +330,55 @@ func (e *FleetExecutor) createClusterResourcePlacement(args map[string]string, c
 
 	// Validate policy
 	validPolicies := []string{"PickAll", "PickFixed", "PickN"}
-	isValidPolicy := false
 	for _, validPolicy := range validPolicies {
 		if strings.EqualFold(policy, validPolicy) {
-			policy = validPolicy
-			isValidPolicy = true
-			break
+			return name, selector, validPolicy, nil
 		}
 	}
-	if !isValidPolicy {
-		return "", fmt.Errorf("invalid policy '%s'. Valid policies: %s", policy, strings.Join(validPolicies, ", "))
+	return "", "", "", fmt.Errorf("invalid policy '%s'. Valid policies: %s", policy, strings.Join(validPolicies, ", "))
+}
+
+// previewClusterResourcePlacement describes a clusterresourceplacement operation without
+// contacting the cluster. Create previews include the manifest that would be applied.
+func (e *FleetExecutor) previewClusterResourcePlacement(operation, args string, cfg *config.ConfigData) (string, error) {
+	accessErr := e.checkAccessLevel(operation, "clusterresourceplacement", cfg.AccessLevel)
+	preview := dryrun.New("az_fleet", operation+" clusterresourceplacement", dryrun.NewPolicy(cfg.AccessLevel, accessErr))
+	if _, destructive := confirm.Classify("az_fleet", operation); destructive {
+		preview.AddNote("This operation requires confirmation before it runs")
+	}
+
+	parsedArgs, err := kubernetes.ParsePlacementArgs(args)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse clusterresourceplacement arguments: %w", err)
 	}
 
-	if e.placementOps == nil {
-		return "", fmt.Errorf("clusterresourceplacement operations not initialized")
+	switch operation {
+	case "create":
+		name, selector, policy, err := resolvePlacementCreateArgs(parsedArgs)
+		if err != nil {
+			return "", err
+		}
+		preview.Command = "kubectl apply -f <manifest>"
+		preview.Request = &dryrun.Request{
+			Method: "APPLY",
+			API:    "placement.kubernetes-fleet.io/v1beta1 ClusterResourcePlacement",
+			Body:   kubernetes.BuildPlacementManifest(name, selector, policy),
+		}
+		preview.AddChange("clusterresourceplacement/"+name, "unknown (the cluster is not contacted during a dry run)", "applied")
+	case "delete":
+		name := parsedArgs["name"]
+		if name == "" {
+			return "", fmt.Errorf("--name is required for delete operation")
+		}
+		preview.Command = "kubectl delete clusterresourceplacement " + name
+		preview.AddChange("clusterresourceplacement/"+name, "exists", "deleted")
+	case "get", "show":
+		preview.Command = "kubectl get clusterresourceplacement " + parsedArgs["name"] + " -o json"
+	case "list":
+		preview.Command = "kubectl get clusterresourceplacement -o json"
 	}
 
-	return e.placementOps.CreatePlacement(name, selector, policy, cfg)
+	return preview.JSON()
 }
 
 // getClusterResourcePlacement retrieves a clusterresourceplacement using placement operations
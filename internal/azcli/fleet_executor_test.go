@@ -1,10 +1,14 @@
 package azcli
 
 import (
+	"encoding/json"
+	"fmt"
 	"strings"
 	"testing"
 
 	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/confirm"
+	"github.com/Azure/aks-mcp/internal/dryrun"
 	"github.com/Azure/aks-mcp/internal/security"
 )
 
@@ -623,3 +627,99 @@ func TestFleetExecutor_DeleteClusterResourcePlacement(t *testing.T) {
 		})
 	}
 }
+
+func TestFleetExecutor_DryRun(t *testing.T) {
+	readonly := &config.ConfigData{
+		AccessLevel:    "readonly",
+		SecurityConfig: &security.SecurityConfig{AccessLevel: "readonly"},
+	}
+	readwrite := &config.ConfigData{
+		AccessLevel:    "readwrite",
+		SecurityConfig: &security.SecurityConfig{AccessLevel: "readwrite"},
+	}
+
+	tests := []struct {
+		name        string
+		params      map[string]any
+		cfg         *config.ConfigData
+		wantAllowed bool
+		wantCommand string
+		wantBody    string
+	}{
+		{
+			name:        "denied az command",
+			params:      map[string]any{"operation": "delete", "resource": "member", "args": "--name m1 --fleet-name f1 --resource-group rg", "dry_run": true},
+			cfg:         readonly,
+			wantAllowed: false,
+			wantCommand: "az fleet member delete --name m1 --fleet-name f1 --resource-group rg",
+		},
+		{
+			name:        "allowed az command",
+			params:      map[string]any{"operation": "create", "resource": "fleet", "args": "--name f1 --resource-group rg", "dry_run": true},
+			cfg:         readwrite,
+			wantAllowed: true,
+			wantCommand: "az fleet fleet create --name f1 --resource-group rg",
+		},
+		{
+			name:        "placement create",
+			params:      map[string]any{"operation": "create", "resource": "clusterresourceplacement", "args": "--name crp1 --selector env=prod --policy pickfixed", "dry_run": true},
+			cfg:         readwrite,
+			wantAllowed: true,
+			wantCommand: "kubectl apply -f <manifest>",
+			wantBody:    "placementType: PickFixed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := NewFleetExecutor().Execute(tt.params, tt.cfg)
+			if err != nil {
+				t.Fatalf("Execute() unexpected error = %v", err)
+			}
+
+			var preview dryrun.Preview
+			if err := json.Unmarshal([]byte(output), &preview); err != nil {
+				t.Fatalf("Failed to parse preview: %v", err)
+			}
+			if preview.Policy.Allowed != tt.wantAllowed {
+				t.Errorf("Policy.Allowed = %v, want %v (%s)", preview.Policy.Allowed, tt.wantAllowed, preview.Policy.Reason)
+			}
+			if preview.Command != tt.wantCommand {
+				t.Errorf("Command = %q, want %q", preview.Command, tt.wantCommand)
+			}
+			if tt.wantBody != "" {
+				if preview.Request == nil || !strings.Contains(fmt.Sprint(preview.Request.Body), tt.wantBody) {
+					t.Errorf("Expected request body containing %q, got %+v", tt.wantBody, preview.Request)
+				}
+			}
+		})
+	}
+}
+
+func TestFleetExecutor_DeleteRequiresConfirmation(t *testing.T) {
+	cfg := &config.ConfigData{
+		AccessLevel:    "readwrite",
+		SecurityConfig: &security.SecurityConfig{AccessLevel: "readwrite"},
+	}
+	params := map[string]any{"operation": "delete", "resource": "clusterresourceplacement", "args": "--name crp1"}
+
+	output, err := NewFleetExecutor().Execute(params, cfg)
+	if err != nil {
+		t.Fatalf("Execute() unexpected error = %v", err)
+	}
+
+	var confirmation confirm.Confirmation
+	if err := json.Unmarshal([]byte(output), &confirmation); err != nil {
+		t.Fatalf("Failed to parse confirmation: %v", err)
+	}
+	if !confirmation.ConfirmationRequired || confirmation.Token == "" {
+		t.Errorf("Expected a confirmation request, got %+v", confirmation)
+	}
+
+	// A token for another placement is rejected
+	params["args"] = "--name crp2"
+	params[confirm.ParamName] = confirmation.Token
+	if _, err := NewFleetExecutor().Execute(params, cfg); err == nil || !strings.Contains(err.Error(), "different operation") {
+		t.Errorf("Expected token mismatch error, got %v", err)
+	}
+}
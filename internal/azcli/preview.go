@@ -0,0 +1,28 @@
+package azcli
+
+import (
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/confirm"
+	"github.com/Azure/aks-mcp/internal/dryrun"
+	"github.com/Azure/aks-mcp/internal/security"
+)
+
+// previewCommand describes an az command that would be run, without running it.
+// accessErr is the result of the tool's own access check; the security validator
+// is only consulted when that check passes.
+func previewCommand(tool, operation, command string, accessErr error, cfg *config.ConfigData) (string, error) {
+	preview := dryrun.New(tool, operation, dryrun.NewPolicy(cfg.AccessLevel, accessErr))
+	preview.Command = command
+	if _, destructive := confirm.Classify(tool, operation); destructive {
+		preview.AddNote("This operation requires confirmation before it runs")
+	}
+
+	if accessErr == nil {
+		validator := security.NewValidator(cfg.SecurityConfig)
+		if err := validator.ValidateCommand(command, security.CommandTypeAz); err != nil {
+			preview.Policy = dryrun.NewPolicy(cfg.AccessLevel, err)
+		}
+	}
+	preview.AddNote("The command runs through the Azure CLI, so the expected changes are not computed")
+	return preview.JSON()
+}
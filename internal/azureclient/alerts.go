@@ -0,0 +1,192 @@
+package azureclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+)
+
+const (
+	// prometheusRuleGroupsAPIVersion is the Microsoft.AlertsManagement API version for Prometheus rule groups
+	prometheusRuleGroupsAPIVersion = "2023-03-01"
+	// alertsAPIVersion is the Microsoft.AlertsManagement API version for fired alerts
+	alertsAPIVersion = "2019-05-05-preview"
+	// MaxFiredAlertsWindow is how far back the alerts API keeps fired alerts
+	MaxFiredAlertsWindow = 30 * 24 * time.Hour
+)
+
+// PrometheusRuleGroup is a group of Prometheus alerting and recording rules
+type PrometheusRuleGroup struct {
+	ID         string                        `json:"id"`
+	Name       string                        `json:"name"`
+	Location   string                        `json:"location"`
+	Properties PrometheusRuleGroupProperties `json:"properties"`
+}
+
+// PrometheusRuleGroupProperties holds the rules of a Prometheus rule group and where they apply
+type PrometheusRuleGroupProperties struct {
+	Description string           `json:"description"`
+	Enabled     *bool            `json:"enabled"`
+	ClusterName string           `json:"clusterName"`
+	Scopes      []string         `json:"scopes"`
+	Interval    string           `json:"interval"`
+	Rules       []PrometheusRule `json:"rules"`
+}
+
+// PrometheusRule is an alerting rule when Alert is set, and a recording rule otherwise
+type PrometheusRule struct {
+	Alert      string `json:"alert"`
+	Record     string `json:"record"`
+	Expression string `json:"expression"`
+	For        string `json:"for"`
+	Severity   *int   `json:"severity"`
+	Enabled    *bool  `json:"enabled"`
+	Actions    []struct {
+		ActionGroupID string `json:"actionGroupId"`
+	} `json:"actions"`
+}
+
+// FiredAlert is an alert instance from Azure Monitor alerts management
+type FiredAlert struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Properties struct {
+		Essentials FiredAlertEssentials `json:"essentials"`
+	} `json:"properties"`
+}
+
+// FiredAlertEssentials are the common fields of a fired alert. Times are ISO 8601 strings as
+// returned by the API.
+type FiredAlertEssentials struct {
+	Severity                         string `json:"severity"`
+	SignalType                       string `json:"signalType"`
+	AlertState                       string `json:"alertState"`
+	MonitorCondition                 string `json:"monitorCondition"`
+	MonitorService                   string `json:"monitorService"`
+	TargetResource                   string `json:"targetResource"`
+	TargetResourceName               string `json:"targetResourceName"`
+	TargetResourceType               string `json:"targetResourceType"`
+	AlertRule                        string `json:"alertRule"`
+	Description                      string `json:"description"`
+	StartDateTime                    string `json:"startDateTime"`
+	LastModifiedDateTime             string `json:"lastModifiedDateTime"`
+	MonitorConditionResolvedDateTime string `json:"monitorConditionResolvedDateTime"`
+}
+
+// ListMetricAlertRules returns the metric alert rules of a subscription
+func (c *AzureClient) ListMetricAlertRules(ctx context.Context, subscriptionID string) ([]*armmonitor.MetricAlertResource, error) {
+	clients, err := c.GetOrCreateClientsForSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []*armmonitor.MetricAlertResource
+	pager := clients.MetricAlertsClient.NewListBySubscriptionPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list metric alert rules: %v", err)
+		}
+		rules = append(rules, page.Value...)
+	}
+	return rules, nil
+}
+
+// ListScheduledQueryRules returns the log search alert rules of a subscription
+func (c *AzureClient) ListScheduledQueryRules(ctx context.Context, subscriptionID string) ([]*armmonitor.ScheduledQueryRuleResource, error) {
+	clients, err := c.GetOrCreateClientsForSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []*armmonitor.ScheduledQueryRuleResource
+	pager := clients.ScheduledQueryRulesClient.NewListBySubscriptionPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list log search alert rules: %v", err)
+		}
+		rules = append(rules, page.Value...)
+	}
+	return rules, nil
+}
+
+// ListActionGroups returns the action groups of a subscription
+func (c *AzureClient) ListActionGroups(ctx context.Context, subscriptionID string) ([]*armmonitor.ActionGroupResource, error) {
+	clients, err := c.GetOrCreateClientsForSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []*armmonitor.ActionGroupResource
+	pager := clients.ActionGroupsClient.NewListBySubscriptionIDPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list action groups: %v", err)
+		}
+		groups = append(groups, page.Value...)
+	}
+	return groups, nil
+}
+
+// CreateOrUpdateMetricAlertRule creates or replaces a metric alert rule in a resource group
+func (c *AzureClient) CreateOrUpdateMetricAlertRule(ctx context.Context, subscriptionID, resourceGroup, name string, rule armmonitor.MetricAlertResource) (*armmonitor.MetricAlertResource, error) {
+	clients, err := c.GetOrCreateClientsForSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := clients.MetricAlertsClient.CreateOrUpdate(ctx, resourceGroup, name, rule, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create or update metric alert rule %s: %v", name, err)
+	}
+	return &resp.MetricAlertResource, nil
+}
+
+// ListPrometheusRuleGroups returns the Prometheus rule groups of a subscription
+func (c *AzureClient) ListPrometheusRuleGroups(ctx context.Context, subscriptionID string) ([]PrometheusRuleGroup, error) {
+	items, err := c.ListARMValues(ctx, "/subscriptions/"+subscriptionID+"/providers/Microsoft.AlertsManagement/prometheusRuleGroups", prometheusRuleGroupsAPIVersion, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Prometheus rule groups: %v", err)
+	}
+
+	groups := make([]PrometheusRuleGroup, 0, len(items))
+	for _, item := range items {
+		var group PrometheusRuleGroup
+		if err := json.Unmarshal(item, &group); err != nil {
+			return nil, fmt.Errorf("failed to decode Prometheus rule group: %v", err)
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// ListFiredAlerts returns the alerts fired on a resource that started in [start, end]. The
+// alerts API keeps alerts for 30 days.
+func (c *AzureClient) ListFiredAlerts(ctx context.Context, subscriptionID, targetResourceID string, start, end time.Time) ([]FiredAlert, error) {
+	query := url.Values{}
+	query.Set("targetResource", targetResourceID)
+	query.Set("customTimeRange", start.UTC().Format(time.RFC3339)+"/"+end.UTC().Format(time.RFC3339))
+	query.Set("sortBy", "startDateTime")
+	query.Set("sortOrder", "desc")
+
+	items, err := c.ListARMValues(ctx, "/subscriptions/"+subscriptionID+"/providers/Microsoft.AlertsManagement/alerts", alertsAPIVersion, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fired alerts: %v", err)
+	}
+
+	alerts := make([]FiredAlert, 0, len(items))
+	for _, item := range items {
+		var alert FiredAlert
+		if err := json.Unmarshal(item, &alert); err != nil {
+			return nil, fmt.Errorf("failed to decode fired alert: %v", err)
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts, nil
+}
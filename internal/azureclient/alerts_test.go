@@ -0,0 +1,55 @@
+package azureclient
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestListFiredAlerts(t *testing.T) {
+	const clusterID = "/subscriptions/sub-1/resourceGroups/rg-1/providers/Microsoft.ContainerService/managedClusters/aks-1"
+	azClient := newTestARMAzureClient(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.URL.Path != "/subscriptions/sub-1/providers/Microsoft.AlertsManagement/alerts" || query.Get("api-version") != alertsAPIVersion {
+			t.Errorf("Unexpected request %s", r.URL)
+		}
+		if query.Get("targetResource") != clusterID || query.Get("customTimeRange") != "2025-03-01T00:00:00Z/2025-03-02T00:00:00Z" {
+			t.Errorf("Unexpected filters: %v", query)
+		}
+		_, _ = w.Write([]byte(`{"value":[{"name":"aks-1-node-cpu-high","properties":{"essentials":{"severity":"Sev3","alertState":"New","monitorCondition":"Fired","startDateTime":"2025-03-01T10:00:00Z"}}}]}`))
+	})
+
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	alerts, err := azClient.ListFiredAlerts(context.Background(), "sub-1", clusterID, start, start.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("ListFiredAlerts returned error: %v", err)
+	}
+	if len(alerts) != 1 || alerts[0].Properties.Essentials.Severity != "Sev3" || alerts[0].Properties.Essentials.MonitorCondition != "Fired" {
+		t.Errorf("Unexpected alerts: %+v", alerts)
+	}
+}
+
+func TestListPrometheusRuleGroups(t *testing.T) {
+	azClient := newTestARMAzureClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("api-version") != prometheusRuleGroupsAPIVersion {
+			t.Errorf("Unexpected api-version in %s", r.URL)
+		}
+		_, _ = w.Write([]byte(`{"value":[{"name":"KubernetesAlert-aks-1","properties":{"clusterName":"aks-1","enabled":true,"interval":"PT1M",
+			"scopes":["/subscriptions/sub-1/resourceGroups/rg-1/providers/Microsoft.Monitor/accounts/amw-1"],
+			"rules":[{"alert":"KubeNodeNotReady","expression":"kube_node_status_condition == 0","for":"PT15M","severity":3,
+			"actions":[{"actionGroupId":"/subscriptions/sub-1/resourceGroups/rg-1/providers/microsoft.insights/actionGroups/oncall"}]}]}}]}`))
+	})
+
+	groups, err := azClient.ListPrometheusRuleGroups(context.Background(), "sub-1")
+	if err != nil {
+		t.Fatalf("ListPrometheusRuleGroups returned error: %v", err)
+	}
+	if len(groups) != 1 || groups[0].Properties.ClusterName != "aks-1" || len(groups[0].Properties.Rules) != 1 {
+		t.Fatalf("Unexpected groups: %+v", groups)
+	}
+	rule := groups[0].Properties.Rules[0]
+	if rule.Alert != "KubeNodeNotReady" || rule.Severity == nil || *rule.Severity != 3 || len(rule.Actions) != 1 {
+		t.Errorf("Unexpected rule: %+v", rule)
+	}
+}
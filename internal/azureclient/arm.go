@@ -0,0 +1,110 @@
+package azureclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+// maxARMPages bounds how many pages ListARMValues follows
+const maxARMPages = 20
+
+// getARMClient returns the shared ARM client used for APIs without an SDK module, creating it on first use
+func (c *AzureClient) getARMClient() (*arm.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.armClient != nil {
+		return c.armClient, nil
+	}
+	if c.credential == nil {
+		return nil, fmt.Errorf("azure credential is not configured")
+	}
+	client, err := arm.NewClient("aks-mcp/arm", "v1.0.0", c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ARM client: %v", err)
+	}
+	c.armClient = client
+	return client, nil
+}
+
+// ListARMValues sends a GET request to an ARM list API and returns the items of the value
+// array, following nextLink. path is the resource path starting with /subscriptions/ and
+// query holds extra query parameters besides api-version.
+func (c *AzureClient) ListARMValues(ctx context.Context, path, apiVersion string, query url.Values) ([]json.RawMessage, error) {
+	client, err := c.getARMClient()
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("invalid ARM path: %s", path)
+	}
+
+	params := url.Values{}
+	for key, values := range query {
+		params[key] = values
+	}
+	params.Set("api-version", apiVersion)
+	next := strings.TrimSuffix(client.Endpoint(), "/") + path + "?" + params.Encode()
+
+	var items []json.RawMessage
+	for page := 0; next != "" && page < maxARMPages; page++ {
+		req, err := runtime.NewRequest(ctx, http.MethodGet, next)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %v", err)
+		}
+		resp, err := client.Pipeline().Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("request to %s failed: %v", path, err)
+		}
+		if !runtime.HasStatusCode(resp, http.StatusOK) {
+			return nil, runtime.NewResponseError(resp)
+		}
+
+		var body struct {
+			Value    []json.RawMessage `json:"value"`
+			NextLink string            `json:"nextLink"`
+		}
+		if err := runtime.UnmarshalAsJSON(resp, &body); err != nil {
+			return nil, fmt.Errorf("failed to decode response from %s: %v", path, err)
+		}
+		items = append(items, body.Value...)
+		next = body.NextLink
+	}
+	return items, nil
+}
+
+// GetARMResource sends a GET request for a single ARM resource and decodes its JSON body into out
+func (c *AzureClient) GetARMResource(ctx context.Context, path, apiVersion string, out interface{}) error {
+	client, err := c.getARMClient()
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("invalid ARM path: %s", path)
+	}
+
+	params := url.Values{}
+	params.Set("api-version", apiVersion)
+	req, err := runtime.NewRequest(ctx, http.MethodGet, strings.TrimSuffix(client.Endpoint(), "/")+path+"?"+params.Encode())
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	resp, err := client.Pipeline().Do(req)
+	if err != nil {
+		return fmt.Errorf("request to %s failed: %v", path, err)
+	}
+	if !runtime.HasStatusCode(resp, http.StatusOK) {
+		return runtime.NewResponseError(resp)
+	}
+	if err := runtime.UnmarshalAsJSON(resp, out); err != nil {
+		return fmt.Errorf("failed to decode response from %s: %v", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package azureclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+func newTestARMAzureClient(t *testing.T, handler http.HandlerFunc) *AzureClient {
+	t.Helper()
+	server := httptest.NewTLSServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := arm.NewClient("aks-mcp/arm", "v1.0.0", fakeCredential{}, &arm.ClientOptions{
+		ClientOptions: policy.ClientOptions{
+			Cloud: cloud.Configuration{Services: map[cloud.ServiceName]cloud.ServiceConfiguration{
+				cloud.ResourceManager: {Endpoint: server.URL, Audience: "https://management.azure.com"},
+			}},
+			Transport: server.Client(),
+			Retry:     policy.RetryOptions{MaxRetries: -1},
+		},
+	})
+	if err != nil {
+		t.Fatalf("arm.NewClient returned error: %v", err)
+	}
+	return &AzureClient{armClient: client}
+}
+
+func TestListARMValuesFollowsNextLink(t *testing.T) {
+	var serverURL string
+	azClient := newTestARMAzureClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("api-version") != "2022-10-01" {
+			t.Errorf("missing api-version in %s", r.URL)
+		}
+		if r.URL.Query().Get("page") == "2" {
+			_, _ = w.Write([]byte(`{"value":[{"name":"second","properties":{"availabilityState":"Unavailable","occuredTime":"2025-03-01T12:00:00Z"}}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"value":[{"name":"first","properties":{"availabilityState":"Available"}}],"nextLink":"` + serverURL + r.URL.Path + `?api-version=2022-10-01&page=2"}`))
+	})
+	serverURL = azClient.armClient.Endpoint()
+
+	statuses, err := azClient.ListAvailabilityStatuses(context.Background(), "/subscriptions/sub-1/resourceGroups/rg-1/providers/Microsoft.ContainerService/managedClusters/aks-1")
+	if err != nil {
+		t.Fatalf("ListAvailabilityStatuses returned error: %v", err)
+	}
+	if len(statuses) != 2 || statuses[1].Name != "second" || statuses[1].Properties.OccurredTime != "2025-03-01T12:00:00Z" {
+		t.Errorf("Unexpected statuses: %+v", statuses)
+	}
+}
+
+func TestListARMValuesErrors(t *testing.T) {
+	azClient := newTestARMAzureClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error":{"code":"AuthorizationFailed","message":"no access"}}`))
+	})
+
+	if _, err := azClient.ListARMValues(context.Background(), "/subscriptions/sub-1/providers/Microsoft.ResourceHealth/events", "2022-10-01", nil); err == nil {
+		t.Error("Expected an error for a forbidden response")
+	}
+	if _, err := azClient.ListARMValues(context.Background(), "subscriptions/sub-1", "2022-10-01", nil); err == nil {
+		t.Error("Expected an error for a relative path")
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/Azure/aks-mcp/internal/config"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/monitor/azquery"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
@@ -17,17 +18,25 @@ import (
 
 // SubscriptionClients contains Azure clients for a specific subscription.
 type SubscriptionClients struct {
-	SubscriptionID           string
-	ContainerServiceClient   *armcontainerservice.ManagedClustersClient
-	VNetClient               *armnetwork.VirtualNetworksClient
-	SubnetsClient            *armnetwork.SubnetsClient
-	RouteTableClient         *armnetwork.RouteTablesClient
-	NSGClient                *armnetwork.SecurityGroupsClient
-	LoadBalancerClient       *armnetwork.LoadBalancersClient
-	PrivateEndpointsClient   *armnetwork.PrivateEndpointsClient
-	VMSSClient               *armcompute.VirtualMachineScaleSetsClient
-	VMSSVMsClient            *armcompute.VirtualMachineScaleSetVMsClient
-	DiagnosticSettingsClient *armmonitor.DiagnosticSettingsClient
+	SubscriptionID            string
+	ContainerServiceClient    *armcontainerservice.ManagedClustersClient
+	AgentPoolsClient          *armcontainerservice.AgentPoolsClient
+	VNetClient                *armnetwork.VirtualNetworksClient
+	SubnetsClient             *armnetwork.SubnetsClient
+	RouteTableClient          *armnetwork.RouteTablesClient
+	NSGClient                 *armnetwork.SecurityGroupsClient
+	LoadBalancerClient        *armnetwork.LoadBalancersClient
+	NatGatewaysClient         *armnetwork.NatGatewaysClient
+	InterfacesClient          *armnetwork.InterfacesClient
+	PrivateEndpointsClient    *armnetwork.PrivateEndpointsClient
+	VMSSClient                *armcompute.VirtualMachineScaleSetsClient
+	VMSSVMsClient             *armcompute.VirtualMachineScaleSetVMsClient
+	DiagnosticSettingsClient  *armmonitor.DiagnosticSettingsClient
+	MetricsClient             *armmonitor.MetricsClient
+	ActivityLogsClient        *armmonitor.ActivityLogsClient
+	MetricAlertsClient        *armmonitor.MetricAlertsClient
+	ScheduledQueryRulesClient *armmonitor.ScheduledQueryRulesClient
+	ActionGroupsClient        *armmonitor.ActionGroupsClient
 }
 
 // AzureClient represents an Azure API client that can handle multiple subscriptions.
@@ -40,6 +49,12 @@ type AzureClient struct {
 	credential *azidentity.DefaultAzureCredential
 	// Cache for Azure resources
 	cache *AzureCache
+	// Log Analytics query client, created on first use
+	logsClient *azquery.LogsClient
+	// ARM client for APIs without an SDK module, created on first use
+	armClient *arm.Client
+	// PromQL clients by query endpoint, created on first use
+	prometheusClients map[string]*PrometheusClient
 }
 
 // NewAzureClient creates a new Azure client using default credentials and the provided configuration.
@@ -83,6 +98,11 @@ func (c *AzureClient) GetOrCreateClientsForSubscription(subscriptionID string) (
 		return nil, fmt.Errorf("failed to create container service client for subscription %s: %v", subscriptionID, err)
 	}
 
+	agentPoolsClient, err := armcontainerservice.NewAgentPoolsClient(subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create agent pools client for subscription %s: %v", subscriptionID, err)
+	}
+
 	vnetClient, err := armnetwork.NewVirtualNetworksClient(subscriptionID, c.credential, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create virtual network client for subscription %s: %v", subscriptionID, err)
@@ -108,6 +128,16 @@ func (c *AzureClient) GetOrCreateClientsForSubscription(subscriptionID string) (
 		return nil, fmt.Errorf("failed to create load balancer client for subscription %s: %v", subscriptionID, err)
 	}
 
+	natGatewaysClient, err := armnetwork.NewNatGatewaysClient(subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create NAT gateways client for subscription %s: %v", subscriptionID, err)
+	}
+
+	interfacesClient, err := armnetwork.NewInterfacesClient(subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create network interfaces client for subscription %s: %v", subscriptionID, err)
+	}
+
 	privateEndpointsClient, err := armnetwork.NewPrivateEndpointsClient(subscriptionID, c.credential, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create private endpoints client for subscription %s: %v", subscriptionID, err)
@@ -128,19 +158,52 @@ func (c *AzureClient) GetOrCreateClientsForSubscription(subscriptionID string) (
 		return nil, fmt.Errorf("failed to create diagnostic settings client for subscription %s: %v", subscriptionID, err)
 	}
 
+	metricsClient, err := armmonitor.NewMetricsClient(subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metrics client for subscription %s: %v", subscriptionID, err)
+	}
+
+	activityLogsClient, err := armmonitor.NewActivityLogsClient(subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create activity logs client for subscription %s: %v", subscriptionID, err)
+	}
+
+	metricAlertsClient, err := armmonitor.NewMetricAlertsClient(subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metric alerts client for subscription %s: %v", subscriptionID, err)
+	}
+
+	scheduledQueryRulesClient, err := armmonitor.NewScheduledQueryRulesClient(subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scheduled query rules client for subscription %s: %v", subscriptionID, err)
+	}
+
+	actionGroupsClient, err := armmonitor.NewActionGroupsClient(subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create action groups client for subscription %s: %v", subscriptionID, err)
+	}
+
 	// Create and store the clients
 	clients = &SubscriptionClients{
-		SubscriptionID:           subscriptionID,
-		ContainerServiceClient:   containerServiceClient,
-		VNetClient:               vnetClient,
-		SubnetsClient:            subnetsClient,
-		RouteTableClient:         routeTableClient,
-		NSGClient:                nsgClient,
-		LoadBalancerClient:       loadBalancerClient,
-		PrivateEndpointsClient:   privateEndpointsClient,
-		VMSSClient:               vmssClient,
-		VMSSVMsClient:            vmssVMsClient,
-		DiagnosticSettingsClient: diagnosticSettingsClient,
+		SubscriptionID:            subscriptionID,
+		ContainerServiceClient:    containerServiceClient,
+		AgentPoolsClient:          agentPoolsClient,
+		VNetClient:                vnetClient,
+		SubnetsClient:             subnetsClient,
+		RouteTableClient:          routeTableClient,
+		NSGClient:                 nsgClient,
+		LoadBalancerClient:        loadBalancerClient,
+		NatGatewaysClient:         natGatewaysClient,
+		InterfacesClient:          interfacesClient,
+		PrivateEndpointsClient:    privateEndpointsClient,
+		VMSSClient:                vmssClient,
+		VMSSVMsClient:             vmssVMsClient,
+		DiagnosticSettingsClient:  diagnosticSettingsClient,
+		MetricsClient:             metricsClient,
+		ActivityLogsClient:        activityLogsClient,
+		MetricAlertsClient:        metricAlertsClient,
+		ScheduledQueryRulesClient: scheduledQueryRulesClient,
+		ActionGroupsClient:        actionGroupsClient,
 	}
 
 	c.clientsMap[subscriptionID] = clients
@@ -176,6 +239,55 @@ func (c *AzureClient) GetAKSCluster(ctx context.Context, subscriptionID, resourc
 	return cluster, nil
 }
 
+// InvalidateAKSCluster removes the cached copy of a cluster, e.g. after starting an operation that changes it.
+func (c *AzureClient) InvalidateAKSCluster(subscriptionID, resourceGroup, clusterName string) {
+	c.cache.Delete(fmt.Sprintf("resource:cluster:%s:%s:%s", subscriptionID, resourceGroup, clusterName))
+}
+
+// ListAKSClusters lists the AKS clusters in a subscription, optionally restricted to a resource group.
+func (c *AzureClient) ListAKSClusters(ctx context.Context, subscriptionID, resourceGroup string) ([]*armcontainerservice.ManagedCluster, error) {
+	// Create cache key
+	cacheKey := fmt.Sprintf("resource:clusterlist:%s:%s", subscriptionID, resourceGroup)
+
+	// Check cache first
+	if cached, found := c.cache.Get(cacheKey); found {
+		if clusters, ok := cached.([]*armcontainerservice.ManagedCluster); ok {
+			return clusters, nil
+		}
+	}
+
+	clients, err := c.GetOrCreateClientsForSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var clusters []*armcontainerservice.ManagedCluster
+	if resourceGroup != "" {
+		pager := clients.ContainerServiceClient.NewListByResourceGroupPager(resourceGroup, nil)
+		for pager.More() {
+			page, err := pager.NextPage(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list AKS clusters in resource group %s: %v", resourceGroup, err)
+			}
+			clusters = append(clusters, page.Value...)
+		}
+	} else {
+		pager := clients.ContainerServiceClient.NewListPager(nil)
+		for pager.More() {
+			page, err := pager.NextPage(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list AKS clusters in subscription %s: %v", subscriptionID, err)
+			}
+			clusters = append(clusters, page.Value...)
+		}
+	}
+
+	// Store in cache
+	c.cache.Set(cacheKey, clusters)
+
+	return clusters, nil
+}
+
 // GetVirtualNetwork retrieves information about the specified virtual network.
 func (c *AzureClient) GetVirtualNetwork(ctx context.Context, subscriptionID, resourceGroup, vnetName string) (*armnetwork.VirtualNetwork, error) {
 	// Create cache key
@@ -321,6 +433,35 @@ func (c *AzureClient) GetLoadBalancer(ctx context.Context, subscriptionID, resou
 	return lb, nil
 }
 
+// GetNatGateway retrieves information about the specified NAT gateway.
+func (c *AzureClient) GetNatGateway(ctx context.Context, subscriptionID, resourceGroup, natGatewayName string) (*armnetwork.NatGateway, error) {
+	// Create cache key
+	cacheKey := fmt.Sprintf("resource:natgateway:%s:%s:%s", subscriptionID, resourceGroup, natGatewayName)
+
+	// Check cache first
+	if cached, found := c.cache.Get(cacheKey); found {
+		if natGateway, ok := cached.(*armnetwork.NatGateway); ok {
+			return natGateway, nil
+		}
+	}
+
+	clients, err := c.GetOrCreateClientsForSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := clients.NatGatewaysClient.Get(ctx, resourceGroup, natGatewayName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get NAT gateway: %v", err)
+	}
+
+	natGateway := &resp.NatGateway
+	// Store in cache
+	c.cache.Set(cacheKey, natGateway)
+
+	return natGateway, nil
+}
+
 // GetPrivateEndpoint retrieves information about the specified private endpoint.
 func (c *AzureClient) GetPrivateEndpoint(ctx context.Context, subscriptionID, resourceGroup, peName string) (*armnetwork.PrivateEndpoint, error) {
 	// Create cache key
@@ -417,6 +558,8 @@ func (c *AzureClient) GetResourceByID(ctx context.Context, resourceID string) (i
 		return c.GetNetworkSecurityGroup(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
 	case "Microsoft.Network/loadBalancers":
 		return c.GetLoadBalancer(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
+	case "Microsoft.Network/natGateways":
+		return c.GetNatGateway(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
 	case "Microsoft.Network/virtualNetworks/subnets":
 		// For subnets, we need the VNet name from parent and subnet name
 		if parsed.Parent != nil {
@@ -468,3 +611,21 @@ func (c *AzureClient) GetDiagnosticSettings(ctx context.Context, subscriptionID,
 
 	return diagnosticSettings, nil
 }
+
+// CreateOrUpdateDiagnosticSetting creates or replaces a diagnostic setting on the specified resource.
+func (c *AzureClient) CreateOrUpdateDiagnosticSetting(ctx context.Context, subscriptionID, resourceURI, name string, setting armmonitor.DiagnosticSettingsResource) (*armmonitor.DiagnosticSettingsResource, error) {
+	clients, err := c.GetOrCreateClientsForSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := clients.DiagnosticSettingsClient.CreateOrUpdate(ctx, resourceURI, name, setting, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create or update diagnostic setting %s: %v", name, err)
+	}
+
+	// The cached list no longer reflects the resource
+	c.cache.Delete(fmt.Sprintf("resource:diagnosticsettings:%s:%s", subscriptionID, resourceURI))
+
+	return &resp.DiagnosticSettingsResource, nil
+}
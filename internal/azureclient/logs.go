@@ -0,0 +1,96 @@
+package azureclient
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/monitor/azquery"
+)
+
+// PrimaryLogsTable returns the first table of a Logs query result, which holds the results of the
+// last statement. A result without tables gives an empty table.
+func PrimaryLogsTable(results azquery.Results) *azquery.Table {
+	if len(results.Tables) == 0 || results.Tables[0] == nil {
+		return &azquery.Table{Rows: []azquery.Row{}}
+	}
+	table := results.Tables[0]
+	if table.Rows == nil {
+		table.Rows = []azquery.Row{}
+	}
+	return table
+}
+
+// LogsRowMaps returns the rows of a Logs query table keyed by column name
+func LogsRowMaps(table *azquery.Table) []map[string]interface{} {
+	rows := make([]map[string]interface{}, 0, len(table.Rows))
+	for _, row := range table.Rows {
+		values := make(map[string]interface{}, len(table.Columns))
+		for i, column := range table.Columns {
+			if i < len(row) && column != nil && column.Name != nil {
+				values[*column.Name] = row[i]
+			}
+		}
+		rows = append(rows, values)
+	}
+	return rows
+}
+
+// getLogsClient returns the shared Logs client, creating it on first use. The Logs API endpoint
+// and token audience come from the azquery service configuration of the public cloud, which is
+// also where the ARM clients send their requests.
+func (c *AzureClient) getLogsClient() (*azquery.LogsClient, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.logsClient != nil {
+		return c.logsClient, nil
+	}
+	if c.credential == nil {
+		return nil, fmt.Errorf("azure credential is not configured")
+	}
+	logsClient, err := azquery.NewLogsClient(c.credential, &azquery.LogsClientOptions{
+		ClientOptions: policy.ClientOptions{Cloud: cloud.AzurePublic},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create logs client: %v", err)
+	}
+	c.logsClient = logsClient
+	return logsClient, nil
+}
+
+// QueryLogs runs a KQL query against a Log Analytics workspace identified by its resource ID.
+// The workspace is queried as a resource so no workspace GUID lookup is needed. The timespan is
+// an ISO 8601 interval such as 2025-01-01T00:00:00Z/2025-01-01T01:00:00Z.
+func (c *AzureClient) QueryLogs(ctx context.Context, workspaceResourceID, query, timespan string) (azquery.Results, error) {
+	if !strings.HasPrefix(workspaceResourceID, "/subscriptions/") {
+		return azquery.Results{}, fmt.Errorf("invalid workspace resource ID: %s", workspaceResourceID)
+	}
+	return c.QueryResourceLogs(ctx, workspaceResourceID, query, timespan)
+}
+
+// QueryResourceLogs runs a KQL query against the logs of an Azure resource identified by its
+// resource ID, such as an Application Insights component. The timespan is an ISO 8601 interval
+// or duration. Partial results are returned with Error set.
+func (c *AzureClient) QueryResourceLogs(ctx context.Context, resourceID, query, timespan string) (azquery.Results, error) {
+	if !strings.HasPrefix(resourceID, "/subscriptions/") {
+		return azquery.Results{}, fmt.Errorf("invalid resource ID: %s", resourceID)
+	}
+	logsClient, err := c.getLogsClient()
+	if err != nil {
+		return azquery.Results{}, err
+	}
+
+	body := azquery.Body{Query: to.Ptr(query)}
+	if timespan != "" {
+		body.Timespan = to.Ptr(azquery.TimeInterval(timespan))
+	}
+	resp, err := logsClient.QueryResource(ctx, resourceID, body, nil)
+	if err != nil {
+		return azquery.Results{}, fmt.Errorf("logs query failed: %w", err)
+	}
+	return resp.Results, nil
+}
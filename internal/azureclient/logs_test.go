@@ -0,0 +1,48 @@
+package azureclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/monitor/azquery"
+)
+
+type fakeCredential struct{}
+
+func (fakeCredential) GetToken(context.Context, policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+func TestPrimaryLogsTable(t *testing.T) {
+	table := PrimaryLogsTable(azquery.Results{})
+	if table.Rows == nil || len(table.Rows) != 0 {
+		t.Errorf("Expected an empty table for a result without tables, got %+v", table)
+	}
+
+	results := azquery.Results{Tables: []*azquery.Table{
+		{Name: to.Ptr("PrimaryResult"), Columns: []*azquery.Column{{Name: to.Ptr("Namespace")}, {Name: to.Ptr("Count")}},
+			Rows: []azquery.Row{{"kube-system", float64(3)}, {"default"}}},
+		{Name: to.Ptr("Other")},
+	}}
+	rows := LogsRowMaps(PrimaryLogsTable(results))
+	if len(rows) != 2 || rows[0]["Namespace"] != "kube-system" || rows[0]["Count"] != float64(3) {
+		t.Errorf("Unexpected rows: %+v", rows)
+	}
+	if _, ok := rows[1]["Count"]; ok || rows[1]["Namespace"] != "default" {
+		t.Errorf("Expected a short row to only set its own columns, got %+v", rows[1])
+	}
+}
+
+func TestQueryLogs_InvalidResourceID(t *testing.T) {
+	client := &AzureClient{}
+	if _, err := client.QueryLogs(context.Background(), "ws-1", "AKSAudit", ""); err == nil {
+		t.Error("Expected an error for a workspace name instead of a resource ID")
+	}
+	if _, err := client.QueryResourceLogs(context.Background(), "app-1", "requests", ""); err == nil {
+		t.Error("Expected an error for an invalid resource ID")
+	}
+}
@@ -0,0 +1,328 @@
+package azureclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/streaming"
+)
+
+const (
+	// prometheusScope is the token scope of Azure Monitor workspace query endpoints
+	prometheusScope = "https://prometheus.monitor.azure.com/.default"
+	// dataCollectionAPIVersion is the Microsoft.Insights API version of data collection rules and associations
+	dataCollectionAPIVersion = "2022-06-01"
+	// monitorWorkspaceAPIVersion is the Microsoft.Monitor API version of Azure Monitor workspaces
+	monitorWorkspaceAPIVersion = "2023-04-03"
+)
+
+// Result types of a PromQL query
+const (
+	PrometheusResultVector = "vector"
+	PrometheusResultMatrix = "matrix"
+	PrometheusResultScalar = "scalar"
+)
+
+// PrometheusClient runs PromQL queries against the HTTP API of Prometheus or of an Azure
+// Monitor workspace query endpoint
+type PrometheusClient struct {
+	endpoint string
+	pipeline runtime.Pipeline
+}
+
+// NewPrometheusClient creates a client for the query endpoint. Requests are authenticated for
+// Azure Monitor when cred is set; without a credential the endpoint is queried anonymously,
+// as for a self-hosted Prometheus.
+func NewPrometheusClient(endpoint string, cred azcore.TokenCredential, options *policy.ClientOptions) (*PrometheusClient, error) {
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "https" && parsed.Scheme != "http") {
+		return nil, fmt.Errorf("invalid Prometheus query endpoint: %s", endpoint)
+	}
+	if cred != nil && parsed.Scheme != "https" {
+		return nil, fmt.Errorf("the Prometheus query endpoint must use https to send credentials: %s", endpoint)
+	}
+	if options == nil {
+		options = &policy.ClientOptions{}
+	}
+
+	var perRetry []policy.Policy
+	if cred != nil {
+		perRetry = append(perRetry, runtime.NewBearerTokenPolicy(cred, []string{prometheusScope}, nil))
+	}
+	pipeline := runtime.NewPipeline("aks-mcp/prometheus", "v1.0.0", runtime.PipelineOptions{PerRetry: perRetry}, options)
+	return &PrometheusClient{endpoint: strings.TrimSuffix(endpoint, "/"), pipeline: pipeline}, nil
+}
+
+// PrometheusPoint is a sample of a series
+type PrometheusPoint struct {
+	Time  time.Time
+	Value float64
+}
+
+// PrometheusSeries is a series of a PromQL result with its labels. Instant queries return one
+// point per series.
+type PrometheusSeries struct {
+	Labels map[string]string
+	Points []PrometheusPoint
+}
+
+// PrometheusResult is the decoded result of a PromQL query
+type PrometheusResult struct {
+	ResultType string
+	Series     []PrometheusSeries
+	Warnings   []string
+}
+
+// PrometheusError is an error reported by the Prometheus HTTP API
+type PrometheusError struct {
+	StatusCode int
+	Type       string
+	Message    string
+}
+
+func (e *PrometheusError) Error() string {
+	return fmt.Sprintf("PromQL query failed (%s): %s", e.Type, e.Message)
+}
+
+// Query runs an instant query evaluated at the given time
+func (c *PrometheusClient) Query(ctx context.Context, query string, at time.Time) (*PrometheusResult, error) {
+	form := url.Values{}
+	form.Set("query", query)
+	form.Set("time", formatPrometheusTime(at))
+	return c.do(ctx, "/api/v1/query", form)
+}
+
+// QueryRange runs a range query over [start, end] with the given resolution step
+func (c *PrometheusClient) QueryRange(ctx context.Context, query string, start, end time.Time, step time.Duration) (*PrometheusResult, error) {
+	if step <= 0 {
+		return nil, fmt.Errorf("step must be positive")
+	}
+	form := url.Values{}
+	form.Set("query", query)
+	form.Set("start", formatPrometheusTime(start))
+	form.Set("end", formatPrometheusTime(end))
+	form.Set("step", strconv.FormatFloat(step.Seconds(), 'f', -1, 64))
+	return c.do(ctx, "/api/v1/query_range", form)
+}
+
+func formatPrometheusTime(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixMilli())/1000, 'f', -1, 64)
+}
+
+// do posts a form encoded query, which keeps long queries out of the URL
+func (c *PrometheusClient) do(ctx context.Context, path string, form url.Values) (*PrometheusResult, error) {
+	req, err := runtime.NewRequest(ctx, http.MethodPost, c.endpoint+path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PromQL request: %w", err)
+	}
+	if err := req.SetBody(streaming.NopCloser(strings.NewReader(form.Encode())), "application/x-www-form-urlencoded"); err != nil {
+		return nil, fmt.Errorf("failed to encode PromQL request: %w", err)
+	}
+
+	resp, err := c.pipeline.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("PromQL request failed: %w", err)
+	}
+
+	var payload struct {
+		Status    string   `json:"status"`
+		ErrorType string   `json:"errorType"`
+		Error     string   `json:"error"`
+		Warnings  []string `json:"warnings"`
+		Data      struct {
+			ResultType string          `json:"resultType"`
+			Result     json.RawMessage `json:"result"`
+		} `json:"data"`
+	}
+	body, err := runtime.Payload(resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PromQL response: %w", err)
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, runtime.NewResponseError(resp)
+		}
+		return nil, fmt.Errorf("failed to decode PromQL response: %w", err)
+	}
+	if payload.Status != "success" {
+		return nil, &PrometheusError{StatusCode: resp.StatusCode, Type: payload.ErrorType, Message: payload.Error}
+	}
+
+	result := &PrometheusResult{ResultType: payload.Data.ResultType, Series: []PrometheusSeries{}, Warnings: payload.Warnings}
+	switch payload.Data.ResultType {
+	case PrometheusResultVector:
+		var samples []struct {
+			Metric map[string]string `json:"metric"`
+			Value  []interface{}     `json:"value"`
+		}
+		if err := json.Unmarshal(payload.Data.Result, &samples); err != nil {
+			return nil, fmt.Errorf("failed to decode vector result: %w", err)
+		}
+		for _, sample := range samples {
+			point, err := parsePrometheusSample(sample.Value)
+			if err != nil {
+				return nil, err
+			}
+			result.Series = append(result.Series, PrometheusSeries{Labels: sample.Metric, Points: []PrometheusPoint{point}})
+		}
+	case PrometheusResultMatrix:
+		var series []struct {
+			Metric map[string]string `json:"metric"`
+			Values [][]interface{}   `json:"values"`
+		}
+		if err := json.Unmarshal(payload.Data.Result, &series); err != nil {
+			return nil, fmt.Errorf("failed to decode matrix result: %w", err)
+		}
+		for _, s := range series {
+			points := make([]PrometheusPoint, 0, len(s.Values))
+			for _, value := range s.Values {
+				point, err := parsePrometheusSample(value)
+				if err != nil {
+					return nil, err
+				}
+				points = append(points, point)
+			}
+			sort.SliceStable(points, func(i, j int) bool { return points[i].Time.Before(points[j].Time) })
+			result.Series = append(result.Series, PrometheusSeries{Labels: s.Metric, Points: points})
+		}
+	case PrometheusResultScalar:
+		var value []interface{}
+		if err := json.Unmarshal(payload.Data.Result, &value); err != nil {
+			return nil, fmt.Errorf("failed to decode scalar result: %w", err)
+		}
+		point, err := parsePrometheusSample(value)
+		if err != nil {
+			return nil, err
+		}
+		result.Series = append(result.Series, PrometheusSeries{Labels: map[string]string{}, Points: []PrometheusPoint{point}})
+	default:
+		return nil, fmt.Errorf("unsupported PromQL result type '%s'", payload.Data.ResultType)
+	}
+	return result, nil
+}
+
+// parsePrometheusSample decodes a [<unix seconds>, "<value>"] pair
+func parsePrometheusSample(pair []interface{}) (PrometheusPoint, error) {
+	if len(pair) != 2 {
+		return PrometheusPoint{}, fmt.Errorf("malformed PromQL sample: %v", pair)
+	}
+	seconds, ok := pair[0].(float64)
+	if !ok {
+		return PrometheusPoint{}, fmt.Errorf("malformed PromQL sample time: %v", pair[0])
+	}
+	text, ok := pair[1].(string)
+	if !ok {
+		return PrometheusPoint{}, fmt.Errorf("malformed PromQL sample value: %v", pair[1])
+	}
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return PrometheusPoint{}, fmt.Errorf("malformed PromQL sample value '%s': %v", text, err)
+	}
+	sec, frac := math.Modf(seconds)
+	return PrometheusPoint{Time: time.Unix(int64(sec), int64(frac*1e9)).UTC().Round(time.Millisecond), Value: value}, nil
+}
+
+// GetPrometheusClient returns the shared PromQL client of an Azure Monitor workspace query endpoint
+func (c *AzureClient) GetPrometheusClient(endpoint string) (*PrometheusClient, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if client, ok := c.prometheusClients[endpoint]; ok {
+		return client, nil
+	}
+	if c.credential == nil {
+		return nil, fmt.Errorf("azure credential is not configured")
+	}
+	client, err := NewPrometheusClient(endpoint, c.credential, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.prometheusClients == nil {
+		c.prometheusClients = make(map[string]*PrometheusClient)
+	}
+	c.prometheusClients[endpoint] = client
+	return client, nil
+}
+
+// MonitorWorkspace is an Azure Monitor workspace that receives a cluster's Prometheus metrics
+type MonitorWorkspace struct {
+	ResourceID    string `json:"resource_id"`
+	QueryEndpoint string `json:"query_endpoint"`
+	// DataCollectionRuleID is the rule that sends the cluster's metrics to the workspace
+	DataCollectionRuleID string `json:"data_collection_rule_id"`
+}
+
+// FindMonitorWorkspaces follows the data collection rule associations of a cluster to the
+// Azure Monitor workspaces its managed Prometheus metrics are sent to
+func (c *AzureClient) FindMonitorWorkspaces(ctx context.Context, clusterResourceID string) ([]MonitorWorkspace, error) {
+	associations, err := c.ListARMValues(ctx, clusterResourceID+"/providers/Microsoft.Insights/dataCollectionRuleAssociations", dataCollectionAPIVersion, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list data collection rule associations: %v", err)
+	}
+
+	var workspaces []MonitorWorkspace
+	seen := make(map[string]bool)
+	for _, item := range associations {
+		var association struct {
+			Properties struct {
+				DataCollectionRuleID string `json:"dataCollectionRuleId"`
+			} `json:"properties"`
+		}
+		if err := json.Unmarshal(item, &association); err != nil {
+			return nil, fmt.Errorf("failed to decode data collection rule association: %v", err)
+		}
+		ruleID := association.Properties.DataCollectionRuleID
+		if ruleID == "" {
+			continue
+		}
+
+		var rule struct {
+			Properties struct {
+				Destinations struct {
+					MonitoringAccounts []struct {
+						AccountResourceID string `json:"accountResourceId"`
+					} `json:"monitoringAccounts"`
+				} `json:"destinations"`
+			} `json:"properties"`
+		}
+		if err := c.GetARMResource(ctx, ruleID, dataCollectionAPIVersion, &rule); err != nil {
+			return nil, fmt.Errorf("failed to get data collection rule %s: %v", ruleID, err)
+		}
+
+		for _, account := range rule.Properties.Destinations.MonitoringAccounts {
+			key := strings.ToLower(account.AccountResourceID)
+			if account.AccountResourceID == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			var workspace struct {
+				Properties struct {
+					Metrics struct {
+						PrometheusQueryEndpoint string `json:"prometheusQueryEndpoint"`
+					} `json:"metrics"`
+				} `json:"properties"`
+			}
+			if err := c.GetARMResource(ctx, account.AccountResourceID, monitorWorkspaceAPIVersion, &workspace); err != nil {
+				return nil, fmt.Errorf("failed to get Azure Monitor workspace %s: %v", account.AccountResourceID, err)
+			}
+			workspaces = append(workspaces, MonitorWorkspace{
+				ResourceID:           account.AccountResourceID,
+				QueryEndpoint:        workspace.Properties.Metrics.PrometheusQueryEndpoint,
+				DataCollectionRuleID: ruleID,
+			})
+		}
+	}
+	return workspaces, nil
+}
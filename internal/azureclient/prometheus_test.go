@@ -0,0 +1,168 @@
+package azureclient
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+func newTestPrometheusServer(t *testing.T, handler http.HandlerFunc) *PrometheusClient {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := NewPrometheusClient(server.URL, nil, &policy.ClientOptions{
+		Transport: server.Client(),
+		Retry:     policy.RetryOptions{MaxRetries: -1},
+	})
+	if err != nil {
+		t.Fatalf("NewPrometheusClient returned error: %v", err)
+	}
+	return client
+}
+
+func TestPrometheusInstantQuery(t *testing.T) {
+	client := newTestPrometheusServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/query" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("ParseForm returned error: %v", err)
+		}
+		if r.PostForm.Get("query") != `up{job="kubelet"}` || r.PostForm.Get("time") != "1740823200" {
+			t.Errorf("Unexpected form: %v", r.PostForm)
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"__name__":"up","instance":"node-1"},"value":[1740823200,"1"]},
+			{"metric":{"__name__":"up","instance":"node-2"},"value":[1740823200.5,"NaN"]}]}}`))
+	})
+
+	result, err := client.Query(context.Background(), `up{job="kubelet"}`, time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Query returned error: %v", err)
+	}
+	if result.ResultType != PrometheusResultVector || len(result.Series) != 2 {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+	first := result.Series[0]
+	if first.Labels["instance"] != "node-1" || first.Points[0].Value != 1 || !first.Points[0].Time.Equal(time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected first series: %+v", first)
+	}
+	if second := result.Series[1].Points[0]; !math.IsNaN(second.Value) || second.Time.Nanosecond() != 500*int(time.Millisecond) {
+		t.Errorf("Expected a NaN sample at a fractional second, got %+v", second)
+	}
+}
+
+func TestPrometheusRangeQuery(t *testing.T) {
+	client := newTestPrometheusServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query_range" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		_ = r.ParseForm()
+		if r.PostForm.Get("step") != "60" || r.PostForm.Get("start") != "1740823200" || r.PostForm.Get("end") != "1740826800" {
+			t.Errorf("Unexpected form: %v", r.PostForm)
+		}
+		_, _ = w.Write([]byte(`{"status":"success","warnings":["partial data"],"data":{"resultType":"matrix","result":[
+			{"metric":{"instance":"node-1"},"values":[[1740823260,"0.5"],[1740823200,"0.25"]]}]}}`))
+	})
+
+	start := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	result, err := client.QueryRange(context.Background(), "node_load1", start, start.Add(time.Hour), time.Minute)
+	if err != nil {
+		t.Fatalf("QueryRange returned error: %v", err)
+	}
+	if len(result.Series) != 1 || len(result.Warnings) != 1 {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+	points := result.Series[0].Points
+	if len(points) != 2 || points[0].Value != 0.25 || points[1].Value != 0.5 {
+		t.Errorf("Expected points ordered by time, got %+v", points)
+	}
+}
+
+func TestPrometheusQueryErrors(t *testing.T) {
+	client := newTestPrometheusServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"parse error at char 5"}`))
+	})
+
+	_, err := client.Query(context.Background(), "sum(", time.Now())
+	var promErr *PrometheusError
+	if !errors.As(err, &promErr) || promErr.Type != "bad_data" || promErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected a PrometheusError, got %v", err)
+	}
+	if _, err := client.QueryRange(context.Background(), "up", time.Now(), time.Now(), 0); err == nil {
+		t.Error("Expected an error for a zero step")
+	}
+}
+
+func TestPrometheusClientAuthentication(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+			t.Errorf("Expected a bearer token, got %q", r.Header.Get("Authorization"))
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"scalar","result":[1740823200,"42"]}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewPrometheusClient(server.URL, fakeCredential{}, &policy.ClientOptions{
+		Transport: server.Client(),
+		Retry:     policy.RetryOptions{MaxRetries: -1},
+	})
+	if err != nil {
+		t.Fatalf("NewPrometheusClient returned error: %v", err)
+	}
+	result, err := client.Query(context.Background(), "scalar(42)", time.Now())
+	if err != nil {
+		t.Fatalf("Query returned error: %v", err)
+	}
+	if result.ResultType != PrometheusResultScalar || result.Series[0].Points[0].Value != 42 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	if _, err := NewPrometheusClient("http://prometheus.local:9090", fakeCredential{}, nil); err == nil {
+		t.Error("Expected an error when sending credentials over http")
+	}
+	if _, err := NewPrometheusClient("not a url", nil, nil); err == nil {
+		t.Error("Expected an error for an invalid endpoint")
+	}
+}
+
+func TestFindMonitorWorkspaces(t *testing.T) {
+	const (
+		clusterID   = "/subscriptions/sub-1/resourceGroups/rg-1/providers/Microsoft.ContainerService/managedClusters/aks-1"
+		ruleID      = "/subscriptions/sub-1/resourceGroups/rg-1/providers/Microsoft.Insights/dataCollectionRules/MSProm-eastus-aks-1"
+		workspaceID = "/subscriptions/sub-1/resourceGroups/rg-1/providers/Microsoft.Monitor/accounts/amw-1"
+	)
+	azClient := newTestARMAzureClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case clusterID + "/providers/Microsoft.Insights/dataCollectionRuleAssociations":
+			_, _ = w.Write([]byte(`{"value":[{"properties":{"dataCollectionRuleId":"` + ruleID + `"}},{"properties":{"dataCollectionEndpointId":"/endpoint"}}]}`))
+		case ruleID:
+			_, _ = w.Write([]byte(`{"properties":{"destinations":{"monitoringAccounts":[{"accountResourceId":"` + workspaceID + `","name":"MonitoringAccount1"}]}}}`))
+		case workspaceID:
+			if r.URL.Query().Get("api-version") != monitorWorkspaceAPIVersion {
+				t.Errorf("Unexpected api-version %s", r.URL.Query().Get("api-version"))
+			}
+			_, _ = w.Write([]byte(`{"properties":{"metrics":{"prometheusQueryEndpoint":"https://amw-1.eastus.prometheus.monitor.azure.com"}}}`))
+		default:
+			t.Errorf("Unexpected request %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	workspaces, err := azClient.FindMonitorWorkspaces(context.Background(), clusterID)
+	if err != nil {
+		t.Fatalf("FindMonitorWorkspaces returned error: %v", err)
+	}
+	if len(workspaces) != 1 || workspaces[0].ResourceID != workspaceID || workspaces[0].QueryEndpoint != "https://amw-1.eastus.prometheus.monitor.azure.com" || workspaces[0].DataCollectionRuleID != ruleID {
+		t.Errorf("Unexpected workspaces: %+v", workspaces)
+	}
+}
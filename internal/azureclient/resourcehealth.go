@@ -0,0 +1,110 @@
+package azureclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// resourceHealthAPIVersion is the Microsoft.ResourceHealth API version used for availability statuses and events
+const resourceHealthAPIVersion = "2022-10-01"
+
+// AvailabilityStatus is a Resource Health availability status of a resource. Times are
+// ISO 8601 strings as returned by the API.
+type AvailabilityStatus struct {
+	ID         string                       `json:"id"`
+	Name       string                       `json:"name"`
+	Location   string                       `json:"location"`
+	Properties AvailabilityStatusProperties `json:"properties"`
+}
+
+// AvailabilityStatusProperties holds the state of a resource and why it is in that state
+type AvailabilityStatusProperties struct {
+	AvailabilityState        string `json:"availabilityState"`
+	Title                    string `json:"title"`
+	Summary                  string `json:"summary"`
+	DetailedStatus           string `json:"detailedStatus"`
+	ReasonType               string `json:"reasonType"`
+	ReasonChronicity         string `json:"reasonChronicity"`
+	Context                  string `json:"context"`
+	Category                 string `json:"category"`
+	OccurredTime             string `json:"occuredTime"` // The API spells it "occured"
+	ReportedTime             string `json:"reportedTime"`
+	RootCauseAttributionTime string `json:"rootCauseAttributionTime"`
+	ResolutionETA            string `json:"resolutionETA"`
+	HealthEventType          string `json:"healthEventType"`
+	HealthEventCause         string `json:"healthEventCause"`
+	HealthEventCategory      string `json:"healthEventCategory"`
+	HealthEventID            string `json:"healthEventId"`
+	RecentlyResolved         *struct {
+		UnavailableOccurredTime string `json:"unavailableOccuredTime"`
+		ResolvedTime            string `json:"resolvedTime"`
+		UnavailableSummary      string `json:"unavailableSummary"`
+	} `json:"recentlyResolved,omitempty"`
+}
+
+// ServiceHealthEvent is a service health event of a subscription, such as a service issue
+type ServiceHealthEvent struct {
+	ID         string                       `json:"id"`
+	Name       string                       `json:"name"`
+	Properties ServiceHealthEventProperties `json:"properties"`
+}
+
+// ServiceHealthEventProperties describes a service health event and the regions it impacts
+type ServiceHealthEventProperties struct {
+	EventType            string `json:"eventType"`
+	EventSource          string `json:"eventSource"`
+	Status               string `json:"status"`
+	Title                string `json:"title"`
+	Summary              string `json:"summary"`
+	Level                string `json:"level"`
+	EventLevel           string `json:"eventLevel"`
+	ImpactStartTime      string `json:"impactStartTime"`
+	ImpactMitigationTime string `json:"impactMitigationTime"`
+	LastUpdateTime       string `json:"lastUpdateTime"`
+	Impact               []struct {
+		ImpactedService string `json:"impactedService"`
+		ImpactedRegions []struct {
+			ImpactedRegion string `json:"impactedRegion"`
+			Status         string `json:"status"`
+		} `json:"impactedRegions"`
+	} `json:"impact"`
+}
+
+// ListAvailabilityStatuses returns the current and past availability statuses of a resource
+func (c *AzureClient) ListAvailabilityStatuses(ctx context.Context, resourceID string) ([]AvailabilityStatus, error) {
+	items, err := c.ListARMValues(ctx, resourceID+"/providers/Microsoft.ResourceHealth/availabilityStatuses", resourceHealthAPIVersion, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list availability statuses: %v", err)
+	}
+	statuses := make([]AvailabilityStatus, 0, len(items))
+	for _, item := range items {
+		var status AvailabilityStatus
+		if err := json.Unmarshal(item, &status); err != nil {
+			return nil, fmt.Errorf("failed to decode availability status: %v", err)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// ListServiceHealthEvents returns the service health events of a subscription since queryStartTime
+func (c *AzureClient) ListServiceHealthEvents(ctx context.Context, subscriptionID string, queryStartTime time.Time) ([]ServiceHealthEvent, error) {
+	query := url.Values{}
+	query.Set("queryStartTime", queryStartTime.UTC().Format("1/2/2006"))
+	items, err := c.ListARMValues(ctx, "/subscriptions/"+subscriptionID+"/providers/Microsoft.ResourceHealth/events", resourceHealthAPIVersion, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list service health events: %v", err)
+	}
+	events := make([]ServiceHealthEvent, 0, len(items))
+	for _, item := range items {
+		var event ServiceHealthEvent
+		if err := json.Unmarshal(item, &event); err != nil {
+			return nil, fmt.Errorf("failed to decode service health event: %v", err)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
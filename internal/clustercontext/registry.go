@@ -0,0 +1,336 @@
+// Package clustercontext keeps track of named AKS cluster contexts and the
+// currently active one, so tools can be called without repeating the
+// subscription, resource group and cluster name on every request.
+package clustercontext
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"sigs.k8s.io/yaml"
+)
+
+// ClusterContext identifies a single AKS cluster
+type ClusterContext struct {
+	Name           string `json:"name,omitempty"`
+	SubscriptionID string `json:"subscription_id"`
+	ResourceGroup  string `json:"resource_group"`
+	ClusterName    string `json:"cluster_name"`
+}
+
+// Validate checks that all identifiers of the context are set
+func (c ClusterContext) Validate() error {
+	if c.SubscriptionID == "" {
+		return fmt.Errorf("missing subscription_id")
+	}
+	if c.ResourceGroup == "" {
+		return fmt.Errorf("missing resource_group")
+	}
+	if c.ClusterName == "" {
+		return fmt.Errorf("missing cluster_name")
+	}
+	return nil
+}
+
+// SessionParam is the tool argument the server sets to the MCP session ID of the call.
+// Clients cannot set it; the tool handler wrappers overwrite it on every call.
+const SessionParam = "_mcp_session_id"
+
+// SessionID returns the MCP session ID of a tool call, or "" outside of a session
+func SessionID(params map[string]interface{}) string {
+	sessionID, _ := params[SessionParam].(string)
+	return sessionID
+}
+
+// Registry holds the configured cluster aliases, the default active context and the
+// active context and aliases each MCP session chose
+type Registry struct {
+	mu       sync.RWMutex
+	aliases  map[string]ClusterContext
+	active   *ClusterContext
+	sessions map[string]*sessionState
+}
+
+// sessionState is what one MCP session changed on top of the configuration
+type sessionState struct {
+	active *ClusterContext
+	// cleared is set when the session cleared its context, so the default no longer applies
+	cleared bool
+	aliases map[string]ClusterContext
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		aliases:  make(map[string]ClusterContext),
+		sessions: make(map[string]*sessionState),
+	}
+}
+
+// defaultRegistry is the registry shared by the server process. The configured aliases and
+// default context apply to every session; what a session sets is kept per session.
+var defaultRegistry = NewRegistry()
+
+// Default returns the registry of the server process
+func Default() *Registry {
+	return defaultRegistry
+}
+
+// AddAlias registers a named cluster context, replacing any existing alias with the same name
+func (r *Registry) AddAlias(ctx ClusterContext) error {
+	if ctx.Name == "" {
+		return fmt.Errorf("cluster context name cannot be empty")
+	}
+	if err := ctx.Validate(); err != nil {
+		return fmt.Errorf("invalid cluster context '%s': %w", ctx.Name, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.aliases[ctx.Name] = ctx
+	return nil
+}
+
+// GetAlias returns the cluster context registered under the given name
+func (r *Registry) GetAlias(name string) (ClusterContext, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ctx, ok := r.aliases[name]
+	return ctx, ok
+}
+
+// ListAliases returns all registered aliases sorted by name
+func (r *Registry) ListAliases() []ClusterContext {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	contexts := make([]ClusterContext, 0, len(r.aliases))
+	for _, ctx := range r.aliases {
+		contexts = append(contexts, ctx)
+	}
+	sort.Slice(contexts, func(i, j int) bool {
+		return contexts[i].Name < contexts[j].Name
+	})
+	return contexts
+}
+
+// SetActive makes the given cluster context the active one
+func (r *Registry) SetActive(ctx ClusterContext) error {
+	if err := ctx.Validate(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.active = &ctx
+	return nil
+}
+
+// Active returns the active cluster context, if one is set
+func (r *Registry) Active() (ClusterContext, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.active == nil {
+		return ClusterContext{}, false
+	}
+	return *r.active, true
+}
+
+// ClearActive unsets the active cluster context
+func (r *Registry) ClearActive() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.active = nil
+}
+
+// Reset removes all aliases, the active context and all session state
+func (r *Registry) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.aliases = make(map[string]ClusterContext)
+	r.active = nil
+	r.sessions = make(map[string]*sessionState)
+}
+
+// RemoveSession drops the active context and aliases of an MCP session that ended
+func (r *Registry) RemoveSession(sessionID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.sessions, sessionID)
+}
+
+// Session returns the view of the registry for an MCP session. An empty session ID, as used
+// outside of a session, addresses the process-wide aliases and active context.
+func (r *Registry) Session(sessionID string) *Session {
+	return &Session{registry: r, id: sessionID}
+}
+
+// Session is the cluster context view of one MCP session. It sees the configured aliases and
+// default context, plus the aliases and active context the session set itself, which other
+// sessions do not see.
+type Session struct {
+	registry *Registry
+	id       string
+}
+
+// state returns the state of the session, creating it if needed. The caller holds the write lock.
+func (s *Session) state() *sessionState {
+	state, ok := s.registry.sessions[s.id]
+	if !ok {
+		state = &sessionState{aliases: make(map[string]ClusterContext)}
+		s.registry.sessions[s.id] = state
+	}
+	return state
+}
+
+// AddAlias registers a named cluster context for the session
+func (s *Session) AddAlias(ctx ClusterContext) error {
+	if s.id == "" {
+		return s.registry.AddAlias(ctx)
+	}
+	if ctx.Name == "" {
+		return fmt.Errorf("cluster context name cannot be empty")
+	}
+	if err := ctx.Validate(); err != nil {
+		return fmt.Errorf("invalid cluster context '%s': %w", ctx.Name, err)
+	}
+
+	s.registry.mu.Lock()
+	defer s.registry.mu.Unlock()
+	s.state().aliases[ctx.Name] = ctx
+	return nil
+}
+
+// GetAlias returns the alias of the session or, if it has none with that name, the configured one
+func (s *Session) GetAlias(name string) (ClusterContext, bool) {
+	s.registry.mu.RLock()
+	if state, ok := s.registry.sessions[s.id]; ok {
+		if ctx, ok := state.aliases[name]; ok {
+			s.registry.mu.RUnlock()
+			return ctx, true
+		}
+	}
+	s.registry.mu.RUnlock()
+	return s.registry.GetAlias(name)
+}
+
+// ListAliases returns the configured aliases and those of the session, sorted by name
+func (s *Session) ListAliases() []ClusterContext {
+	s.registry.mu.RLock()
+	defer s.registry.mu.RUnlock()
+
+	byName := make(map[string]ClusterContext, len(s.registry.aliases))
+	for name, ctx := range s.registry.aliases {
+		byName[name] = ctx
+	}
+	if state, ok := s.registry.sessions[s.id]; ok {
+		for name, ctx := range state.aliases {
+			byName[name] = ctx
+		}
+	}
+
+	contexts := make([]ClusterContext, 0, len(byName))
+	for _, ctx := range byName {
+		contexts = append(contexts, ctx)
+	}
+	sort.Slice(contexts, func(i, j int) bool {
+		return contexts[i].Name < contexts[j].Name
+	})
+	return contexts
+}
+
+// SetActive makes the given cluster context the active one of the session
+func (s *Session) SetActive(ctx ClusterContext) error {
+	if s.id == "" {
+		return s.registry.SetActive(ctx)
+	}
+	if err := ctx.Validate(); err != nil {
+		return err
+	}
+
+	s.registry.mu.Lock()
+	defer s.registry.mu.Unlock()
+	state := s.state()
+	state.active = &ctx
+	state.cleared = false
+	return nil
+}
+
+// Active returns the active cluster context of the session, falling back to the default
+// context until the session sets or clears its own
+func (s *Session) Active() (ClusterContext, bool) {
+	s.registry.mu.RLock()
+	if state, ok := s.registry.sessions[s.id]; ok && (state.active != nil || state.cleared) {
+		defer s.registry.mu.RUnlock()
+		if state.active == nil {
+			return ClusterContext{}, false
+		}
+		return *state.active, true
+	}
+	s.registry.mu.RUnlock()
+	return s.registry.Active()
+}
+
+// ClearActive unsets the active cluster context of the session
+func (s *Session) ClearActive() {
+	if s.id == "" {
+		s.registry.ClearActive()
+		return
+	}
+
+	s.registry.mu.Lock()
+	defer s.registry.mu.Unlock()
+	state := s.state()
+	state.active = nil
+	state.cleared = true
+}
+
+// fileConfig is the part of the configuration file that describes cluster contexts
+type fileConfig struct {
+	ClusterContexts map[string]ClusterContext `json:"cluster_contexts"`
+	// DefaultContext is the alias activated at startup
+	DefaultContext string `json:"default_context"`
+}
+
+// LoadFile reads cluster aliases from a YAML or JSON configuration file into the registry
+//
+// Example:
+//
+//	cluster_contexts:
+//	  prod-weu:
+//	    subscription_id: 00000000-0000-0000-0000-000000000000
+//	    resource_group: prod-rg
+//	    cluster_name: prod-weu-aks
+//	default_context: prod-weu
+func (r *Registry) LoadFile(path string) error {
+	data, err := os.ReadFile(path) // #nosec G304 -- path is provided by the operator on the command line
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var cfg fileConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	for name, ctx := range cfg.ClusterContexts {
+		ctx.Name = name
+		if err := r.AddAlias(ctx); err != nil {
+			return err
+		}
+	}
+
+	if cfg.DefaultContext != "" {
+		ctx, ok := r.GetAlias(cfg.DefaultContext)
+		if !ok {
+			return fmt.Errorf("default_context '%s' is not defined in cluster_contexts", cfg.DefaultContext)
+		}
+		if err := r.SetActive(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package clustercontext
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRegistry_AliasesAndActive(t *testing.T) {
+	r := NewRegistry()
+
+	if _, ok := r.Active(); ok {
+		t.Fatal("Expected no active context on a new registry")
+	}
+
+	prod := ClusterContext{Name: "prod-weu", SubscriptionID: "sub-1", ResourceGroup: "rg-1", ClusterName: "aks-1"}
+	dev := ClusterContext{Name: "dev", SubscriptionID: "sub-2", ResourceGroup: "rg-2", ClusterName: "aks-2"}
+	for _, ctx := range []ClusterContext{prod, dev} {
+		if err := r.AddAlias(ctx); err != nil {
+			t.Fatalf("AddAlias(%s) returned error: %v", ctx.Name, err)
+		}
+	}
+
+	aliases := r.ListAliases()
+	if len(aliases) != 2 || aliases[0].Name != "dev" || aliases[1].Name != "prod-weu" {
+		t.Errorf("Expected aliases sorted by name, got %+v", aliases)
+	}
+
+	got, ok := r.GetAlias("prod-weu")
+	if !ok || got != prod {
+		t.Errorf("Expected to find alias prod-weu, got %+v (found=%v)", got, ok)
+	}
+
+	if err := r.SetActive(dev); err != nil {
+		t.Fatalf("SetActive returned error: %v", err)
+	}
+	active, ok := r.Active()
+	if !ok || active != dev {
+		t.Errorf("Expected active context %+v, got %+v", dev, active)
+	}
+
+	r.ClearActive()
+	if _, ok := r.Active(); ok {
+		t.Error("Expected no active context after ClearActive")
+	}
+}
+
+func TestRegistry_RejectsIncompleteContexts(t *testing.T) {
+	r := NewRegistry()
+
+	tests := []struct {
+		name string
+		ctx  ClusterContext
+	}{
+		{"missing name", ClusterContext{SubscriptionID: "s", ResourceGroup: "rg", ClusterName: "c"}},
+		{"missing subscription", ClusterContext{Name: "a", ResourceGroup: "rg", ClusterName: "c"}},
+		{"missing resource group", ClusterContext{Name: "a", SubscriptionID: "s", ClusterName: "c"}},
+		{"missing cluster name", ClusterContext{Name: "a", SubscriptionID: "s", ResourceGroup: "rg"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := r.AddAlias(tt.ctx); err == nil {
+				t.Error("Expected error for incomplete context")
+			}
+		})
+	}
+
+	if err := r.SetActive(ClusterContext{SubscriptionID: "s"}); err == nil {
+		t.Error("Expected SetActive to reject an incomplete context")
+	}
+}
+
+func TestRegistry_LoadFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	content := `cluster_contexts:
+  prod-weu:
+    subscription_id: sub-1
+    resource_group: prod-rg
+    cluster_name: prod-aks
+  staging:
+    subscription_id: sub-2
+    resource_group: staging-rg
+    cluster_name: staging-aks
+default_context: staging
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	r := NewRegistry()
+	if err := r.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile returned error: %v", err)
+	}
+
+	prod, ok := r.GetAlias("prod-weu")
+	if !ok {
+		t.Fatal("Expected alias prod-weu to be loaded")
+	}
+	if prod.Name != "prod-weu" || prod.ResourceGroup != "prod-rg" || prod.ClusterName != "prod-aks" {
+		t.Errorf("Unexpected alias contents: %+v", prod)
+	}
+
+	active, ok := r.Active()
+	if !ok || active.Name != "staging" {
+		t.Errorf("Expected default context staging to be active, got %+v (found=%v)", active, ok)
+	}
+}
+
+func TestRegistry_LoadFileErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := NewRegistry().LoadFile(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+
+	unknownDefault := filepath.Join(dir, "unknown-default.yaml")
+	if err := os.WriteFile(unknownDefault, []byte("default_context: nope\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	if err := NewRegistry().LoadFile(unknownDefault); err == nil {
+		t.Error("Expected error for undefined default_context")
+	}
+
+	incomplete := filepath.Join(dir, "incomplete.yaml")
+	if err := os.WriteFile(incomplete, []byte("cluster_contexts:\n  a:\n    subscription_id: s\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	if err := NewRegistry().LoadFile(incomplete); err == nil {
+		t.Error("Expected error for incomplete alias")
+	}
+}
@@ -1,20 +1,33 @@
 package azaks
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
+	"github.com/Azure/aks-mcp/internal/azureclient"
 	"github.com/Azure/aks-mcp/internal/command"
 	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/confirm"
+	"github.com/Azure/aks-mcp/internal/dryrun"
 	"github.com/Azure/aks-mcp/internal/security"
 )
 
 // AksOperationsExecutor handles execution of AKS operations
-type AksOperationsExecutor struct{}
+type AksOperationsExecutor struct {
+	azClient *azureclient.AzureClient
+	tracker  *OperationTracker
+	gate     *confirm.Gate
+}
 
-// NewAksOperationsExecutor creates a new AksOperationsExecutor
-func NewAksOperationsExecutor() *AksOperationsExecutor {
-	return &AksOperationsExecutor{}
+// NewAksOperationsExecutor creates a new AksOperationsExecutor.
+// The Azure client is used for the operations implemented with the AKS SDK.
+func NewAksOperationsExecutor(azClient *azureclient.AzureClient) *AksOperationsExecutor {
+	return &AksOperationsExecutor{
+		azClient: azClient,
+		tracker:  DefaultOperationTracker(),
+		gate:     confirm.DefaultGate(),
+	}
 }
 
 // Execute handles the AKS operations
@@ -25,27 +38,46 @@ func (e *AksOperationsExecutor) Execute(params map[string]interface{}, cfg *conf
 		return "", fmt.Errorf("missing or invalid 'operation' parameter")
 	}
 
-	// Parse args parameter
-	args, ok := params["args"].(string)
-	if !ok {
-		args = ""
+	dryRun := dryrun.Requested(params)
+
+	// Validate access for this operation. A dry run reports the decision instead of failing.
+	accessErr := ValidateOperationAccess(operation, cfg)
+	if accessErr != nil && !dryRun {
+		return "", accessErr
 	}
 
-	// Validate access for this operation
-	if err := ValidateOperationAccess(operation, cfg); err != nil {
+	// Validate the structured parameters against the operation schema
+	values, err := ValidateOperationParams(operation, params)
+	if err != nil {
 		return "", err
 	}
 
-	// Map operation to Azure CLI command
-	baseCommand, err := MapOperationToCommand(operation)
+	if dryRun {
+		return e.preview(operation, values, cfg, accessErr)
+	}
+
+	// Destructive operations only run once the user has confirmed them
+	confirmation, err := e.gate.Check(confirm.Request{
+		Tool:      "az_aks_operations",
+		Operation: operation,
+		Target:    mutationParamsFromValues(operation, values).ResourceID(),
+		Args:      values,
+	}, params)
 	if err != nil {
 		return "", err
 	}
+	if confirmation != nil {
+		return confirmation.JSON()
+	}
 
-	// Build full command
-	fullCommand := baseCommand
-	if args != "" {
-		fullCommand += " " + args
+	// Mutating operations with structured parameters go through the AKS SDK
+	if IsNativeOperation(operation) {
+		return e.executeNative(context.Background(), operation, values)
+	}
+
+	fullCommand, err := buildCommand(operation, values)
+	if err != nil {
+		return "", err
 	}
 
 	// Validate the command against security settings
@@ -80,6 +112,55 @@ func (e *AksOperationsExecutor) Execute(params map[string]interface{}, cfg *conf
 	return process.Run(cmdArgs)
 }
 
+// preview describes what the operation would do without executing it
+func (e *AksOperationsExecutor) preview(operation string, values map[string]interface{}, cfg *config.ConfigData, accessErr error) (string, error) {
+	preview := dryrun.New("az_aks_operations", operation, dryrun.NewPolicy(cfg.AccessLevel, accessErr))
+	if _, destructive := confirm.Classify("az_aks_operations", operation); destructive {
+		preview.AddNote("This operation requires confirmation before it runs")
+	}
+
+	if IsNativeOperation(operation) {
+		e.previewNative(context.Background(), operation, values, preview)
+		return preview.JSON()
+	}
+
+	fullCommand, err := buildCommand(operation, values)
+	if err != nil {
+		return "", err
+	}
+	preview.Command = fullCommand
+
+	if preview.Policy.Allowed {
+		validator := security.NewValidator(cfg.SecurityConfig)
+		if err := validator.ValidateCommand(fullCommand, security.CommandTypeAz); err != nil {
+			preview.Policy = dryrun.NewPolicy(cfg.AccessLevel, err)
+		}
+	}
+	preview.AddNote("The command runs through the Azure CLI, so the expected changes are not computed")
+	return preview.JSON()
+}
+
+// buildCommand returns the az CLI command for an operation with validated parameters
+func buildCommand(operation string, values map[string]interface{}) (string, error) {
+	// Translate the parameters to Azure CLI arguments
+	args, err := BuildCLIArgs(operation, values)
+	if err != nil {
+		return "", err
+	}
+
+	// Map operation to Azure CLI command
+	baseCommand, err := MapOperationToCommand(operation)
+	if err != nil {
+		return "", err
+	}
+
+	fullCommand := baseCommand
+	if args != "" {
+		fullCommand += " " + args
+	}
+	return fullCommand, nil
+}
+
 // ExecuteSpecificCommand executes a specific operation with the given arguments (for backward compatibility)
 func (e *AksOperationsExecutor) ExecuteSpecificCommand(operation string, params map[string]interface{}, cfg *config.ConfigData) (string, error) {
 	// Create new params with operation
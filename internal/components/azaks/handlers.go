@@ -0,0 +1,43 @@
+package azaks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/tools"
+)
+
+// GetAksOperationStatusHandler returns handler for the aks_operation_status tool
+func GetAksOperationStatusHandler(tracker *OperationTracker, azClient *azureclient.AzureClient) tools.ResourceHandler {
+	return tools.ResourceHandlerFunc(func(params map[string]interface{}, _ *config.ConfigData) (string, error) {
+		var result interface{}
+
+		operationID, _ := params["operation_id"].(string)
+		resumeToken, _ := params["resume_token"].(string)
+		switch {
+		case resumeToken != "":
+			status, err := tracker.Resume(context.Background(), resumeToken, nativeResumer(azClient))
+			if err != nil {
+				return "", err
+			}
+			result = status
+		case operationID == "":
+			result = map[string]interface{}{"operations": tracker.List()}
+		default:
+			status, err := tracker.Status(context.Background(), operationID)
+			if err != nil {
+				return "", err
+			}
+			result = status
+		}
+
+		resultJSON, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal operation status: %v", err)
+		}
+		return string(resultJSON), nil
+	})
+}
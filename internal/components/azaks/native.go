@@ -0,0 +1,441 @@
+package azaks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/dryrun"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+)
+
+// nativeOperations are the mutating operations implemented with the AKS SDK instead of the az CLI.
+// They return as soon as the operation is accepted and are tracked with aks_operation_status.
+var nativeOperations = map[AksOperationType]bool{
+	OpClusterScale:    true,
+	OpClusterUpgrade:  true,
+	OpClusterDelete:   true,
+	OpNodepoolAdd:     true,
+	OpNodepoolScale:   true,
+	OpNodepoolUpgrade: true,
+}
+
+// IsNativeOperation reports whether the operation is executed through the AKS SDK
+func IsNativeOperation(operation string) bool {
+	return nativeOperations[AksOperationType(operation)]
+}
+
+// MutationParams holds the structured parameters of a native mutating operation
+type MutationParams struct {
+	SubscriptionID    string `json:"subscription_id"`
+	ResourceGroup     string `json:"resource_group"`
+	ClusterName       string `json:"cluster_name"`
+	NodePoolName      string `json:"nodepool_name,omitempty"`
+	NodeCount         *int32 `json:"node_count,omitempty"`
+	KubernetesVersion string `json:"kubernetes_version,omitempty"`
+	VMSize            string `json:"vm_size,omitempty"`
+	Mode              string `json:"mode,omitempty"`
+	ControlPlaneOnly  bool   `json:"control_plane_only,omitempty"`
+}
+
+// ResourceID returns the resource ID of the cluster or node pool the operation acts on
+func (p MutationParams) ResourceID() string {
+	resourceID := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ContainerService/managedClusters/%s",
+		p.SubscriptionID, p.ResourceGroup, p.ClusterName)
+	if p.NodePoolName != "" {
+		resourceID += "/agentPools/" + p.NodePoolName
+	}
+	return resourceID
+}
+
+// ParseMutationParams validates the parameters of a native operation and returns them as MutationParams
+func ParseMutationParams(operation string, params map[string]interface{}) (*MutationParams, error) {
+	if !IsNativeOperation(operation) {
+		return nil, fmt.Errorf("operation '%s' is not implemented natively", operation)
+	}
+
+	values, err := ValidateOperationParams(operation, params)
+	if err != nil {
+		return nil, err
+	}
+	return mutationParamsFromValues(operation, values), nil
+}
+
+// mutationParamsFromValues builds MutationParams from values returned by ValidateOperationParams
+func mutationParamsFromValues(operation string, values map[string]interface{}) *MutationParams {
+	p := &MutationParams{}
+	p.SubscriptionID, _ = values["subscription_id"].(string)
+	p.ResourceGroup, _ = values["resource_group"].(string)
+	p.ClusterName, _ = values["cluster_name"].(string)
+	p.NodePoolName, _ = values["nodepool_name"].(string)
+	p.KubernetesVersion, _ = values["kubernetes_version"].(string)
+	p.VMSize, _ = values["vm_size"].(string)
+	p.Mode, _ = values["mode"].(string)
+	p.ControlPlaneOnly, _ = values["control_plane_only"].(bool)
+	if count, ok := values["node_count"].(int); ok {
+		p.NodeCount = to.Ptr(int32(count))
+	}
+
+	// Defaults matching `az aks nodepool add`
+	if AksOperationType(operation) == OpNodepoolAdd {
+		if p.NodeCount == nil {
+			p.NodeCount = to.Ptr(int32(3))
+		}
+		if p.Mode == "" {
+			p.Mode = string(armcontainerservice.AgentPoolModeUser)
+		}
+	}
+	return p
+}
+
+// executeNative starts a native operation and returns its tracking information without waiting for completion
+func (e *AksOperationsExecutor) executeNative(ctx context.Context, operation string, values map[string]interface{}) (string, error) {
+	if e.azClient == nil {
+		return "", fmt.Errorf("azure client is required for operation '%s' but not available", operation)
+	}
+
+	p := mutationParamsFromValues(operation, values)
+
+	clients, err := e.azClient.GetOrCreateClientsForSubscription(p.SubscriptionID)
+	if err != nil {
+		return "", err
+	}
+
+	plan := dryrun.New("az_aks_operations", operation, dryrun.Policy{Allowed: true})
+	begin, err := prepareOperation(ctx, clients, operation, p, plan)
+	if err != nil {
+		return "", err
+	}
+
+	poller, err := begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	e.azClient.InvalidateAKSCluster(p.SubscriptionID, p.ResourceGroup, p.ClusterName)
+
+	status := e.tracker.Track(operation, p.ResourceID(), mutationSummary(p), poller)
+
+	result := map[string]interface{}{
+		"operation_id": status.OperationID,
+		"operation":    status.Operation,
+		"resource_id":  status.ResourceID,
+		"status":       status.Status,
+		"message":      fmt.Sprintf("Operation accepted. Track progress with aks_operation_status operation_id=\"%s\", or resume_token after a server restart", status.OperationID),
+	}
+	if status.Error != "" {
+		result["error"] = status.Error
+	}
+	if status.ResumeToken != "" {
+		result["resume_token"] = status.ResumeToken
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal operation result: %v", err)
+	}
+	return string(resultJSON), nil
+}
+
+// previewNative resolves the request a native operation would send and how it would change the cluster
+func (e *AksOperationsExecutor) previewNative(ctx context.Context, operation string, values map[string]interface{}, preview *dryrun.Preview) {
+	p := mutationParamsFromValues(operation, values)
+
+	if e.azClient == nil {
+		preview.AddNote("Azure client is not available, so the current state could not be read")
+		return
+	}
+
+	clients, err := e.azClient.GetOrCreateClientsForSubscription(p.SubscriptionID)
+	if err != nil {
+		preview.AddNote(fmt.Sprintf("Could not create Azure clients: %v", err))
+		return
+	}
+
+	if _, err := prepareOperation(ctx, clients, operation, p, preview); err != nil {
+		preview.AddNote(fmt.Sprintf("The operation would fail: %v", err))
+	}
+}
+
+// nativeResumer rebuilds the SDK poller of a native operation from its resume token
+func nativeResumer(azClient *azureclient.AzureClient) pollerResumer {
+	return func(ctx context.Context, operation, resourceID, token string) (lroPoller, error) {
+		if azClient == nil {
+			return nil, fmt.Errorf("azure client is required to resume operations but not available")
+		}
+		id, err := arm.ParseResourceID(resourceID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid resource ID in resume token: %v", err)
+		}
+		clients, err := azClient.GetOrCreateClientsForSubscription(id.SubscriptionID)
+		if err != nil {
+			return nil, err
+		}
+
+		// With a resume token the SDK only uses the token, not the resource names or body
+		switch AksOperationType(operation) {
+		case OpClusterDelete:
+			poller, err := clients.ContainerServiceClient.BeginDelete(ctx, "", "",
+				&armcontainerservice.ManagedClustersClientBeginDeleteOptions{ResumeToken: token})
+			if err != nil {
+				return nil, err
+			}
+			return sdkPoller[armcontainerservice.ManagedClustersClientDeleteResponse]{poller}, nil
+		case OpClusterUpgrade:
+			poller, err := clients.ContainerServiceClient.BeginCreateOrUpdate(ctx, "", "", armcontainerservice.ManagedCluster{},
+				&armcontainerservice.ManagedClustersClientBeginCreateOrUpdateOptions{ResumeToken: token})
+			if err != nil {
+				return nil, err
+			}
+			return sdkPoller[armcontainerservice.ManagedClustersClientCreateOrUpdateResponse]{poller}, nil
+		case OpClusterScale, OpNodepoolAdd, OpNodepoolScale, OpNodepoolUpgrade:
+			poller, err := clients.AgentPoolsClient.BeginCreateOrUpdate(ctx, "", "", "", armcontainerservice.AgentPool{},
+				&armcontainerservice.AgentPoolsClientBeginCreateOrUpdateOptions{ResumeToken: token})
+			if err != nil {
+				return nil, err
+			}
+			return sdkPoller[armcontainerservice.AgentPoolsClientCreateOrUpdateResponse]{poller}, nil
+		}
+		return nil, fmt.Errorf("operation '%s' cannot be resumed", operation)
+	}
+}
+
+// beginFunc starts a prepared long-running operation
+type beginFunc func(ctx context.Context) (lroPoller, error)
+
+// prepareOperation reads the current state, builds the request for the operation and records
+// the request and the expected changes in plan. The returned function sends the request.
+func prepareOperation(ctx context.Context, clients *azureclient.SubscriptionClients, operation string, p *MutationParams, plan *dryrun.Preview) (beginFunc, error) {
+	switch AksOperationType(operation) {
+	case OpClusterDelete:
+		resp, err := clients.ContainerServiceClient.Get(ctx, p.ResourceGroup, p.ClusterName, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get AKS cluster: %v", err)
+		}
+		plan.Request = &dryrun.Request{Method: "DELETE", API: "ManagedClustersClient.BeginDelete", ResourceID: p.ResourceID()}
+		plan.AddChange("cluster", describeCluster(&resp.ManagedCluster), "deleted")
+		return func(ctx context.Context) (lroPoller, error) {
+			poller, err := clients.ContainerServiceClient.BeginDelete(ctx, p.ResourceGroup, p.ClusterName, nil)
+			if err != nil {
+				return nil, fmt.Errorf("failed to start cluster deletion: %v", err)
+			}
+			return sdkPoller[armcontainerservice.ManagedClustersClientDeleteResponse]{poller}, nil
+		}, nil
+
+	case OpClusterUpgrade:
+		resp, err := clients.ContainerServiceClient.Get(ctx, p.ResourceGroup, p.ClusterName, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get AKS cluster: %v", err)
+		}
+		cluster := resp.ManagedCluster
+		if err := planClusterUpgrade(&cluster, p, plan); err != nil {
+			return nil, err
+		}
+		plan.Request = &dryrun.Request{Method: "PUT", API: "ManagedClustersClient.BeginCreateOrUpdate", ResourceID: p.ResourceID(), Body: cluster}
+		return func(ctx context.Context) (lroPoller, error) {
+			poller, err := clients.ContainerServiceClient.BeginCreateOrUpdate(ctx, p.ResourceGroup, p.ClusterName, cluster, nil)
+			if err != nil {
+				return nil, fmt.Errorf("failed to start cluster upgrade: %v", err)
+			}
+			return sdkPoller[armcontainerservice.ManagedClustersClientCreateOrUpdateResponse]{poller}, nil
+		}, nil
+
+	case OpClusterScale:
+		// Like `az aks scale`, scaling a cluster scales one of its node pools
+		if p.NodePoolName == "" {
+			resp, err := clients.ContainerServiceClient.Get(ctx, p.ResourceGroup, p.ClusterName, nil)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get AKS cluster: %v", err)
+			}
+			if resp.Properties == nil || len(resp.Properties.AgentPoolProfiles) != 1 || resp.Properties.AgentPoolProfiles[0].Name == nil {
+				return nil, fmt.Errorf("cluster %s has more than one node pool, nodepool_name is required", p.ClusterName)
+			}
+			p.NodePoolName = *resp.Properties.AgentPoolProfiles[0].Name
+		}
+		return prepareAgentPoolUpdate(ctx, clients, p, plan, planNodeCount)
+
+	case OpNodepoolScale:
+		return prepareAgentPoolUpdate(ctx, clients, p, plan, planNodeCount)
+
+	case OpNodepoolUpgrade:
+		return prepareAgentPoolUpdate(ctx, clients, p, plan, planNodepoolUpgrade)
+
+	case OpNodepoolAdd:
+		if _, err := clients.AgentPoolsClient.Get(ctx, p.ResourceGroup, p.ClusterName, p.NodePoolName, nil); err == nil {
+			return nil, fmt.Errorf("node pool %s already exists in cluster %s", p.NodePoolName, p.ClusterName)
+		}
+		pool := newAgentPool(p)
+		plan.Request = &dryrun.Request{Method: "PUT", API: "AgentPoolsClient.BeginCreateOrUpdate", ResourceID: p.ResourceID(), Body: pool}
+		plan.AddChange("node_pool", "absent", describeAgentPool(pool.Properties))
+		return func(ctx context.Context) (lroPoller, error) {
+			poller, err := clients.AgentPoolsClient.BeginCreateOrUpdate(ctx, p.ResourceGroup, p.ClusterName, p.NodePoolName, pool, nil)
+			if err != nil {
+				return nil, fmt.Errorf("failed to start node pool creation: %v", err)
+			}
+			return sdkPoller[armcontainerservice.AgentPoolsClientCreateOrUpdateResponse]{poller}, nil
+		}, nil
+	}
+
+	return nil, fmt.Errorf("operation '%s' is not implemented natively", operation)
+}
+
+// prepareAgentPoolUpdate reads the node pool, applies the planned change and returns the update call
+func prepareAgentPoolUpdate(ctx context.Context, clients *azureclient.SubscriptionClients, p *MutationParams, plan *dryrun.Preview,
+	planUpdate func(*armcontainerservice.AgentPool, *MutationParams, *dryrun.Preview) error) (beginFunc, error) {
+	resp, err := clients.AgentPoolsClient.Get(ctx, p.ResourceGroup, p.ClusterName, p.NodePoolName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get node pool %s: %v", p.NodePoolName, err)
+	}
+
+	pool := resp.AgentPool
+	if pool.Properties == nil {
+		return nil, fmt.Errorf("node pool %s has no properties", p.NodePoolName)
+	}
+	if err := planUpdate(&pool, p, plan); err != nil {
+		return nil, err
+	}
+	plan.Request = &dryrun.Request{Method: "PUT", API: "AgentPoolsClient.BeginCreateOrUpdate", ResourceID: p.ResourceID(), Body: pool}
+
+	return func(ctx context.Context) (lroPoller, error) {
+		poller, err := clients.AgentPoolsClient.BeginCreateOrUpdate(ctx, p.ResourceGroup, p.ClusterName, p.NodePoolName, pool, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to start node pool update: %v", err)
+		}
+		return sdkPoller[armcontainerservice.AgentPoolsClientCreateOrUpdateResponse]{poller}, nil
+	}, nil
+}
+
+// planClusterUpgrade sets the target version on the cluster and, unless control_plane_only is set, on its node pools
+func planClusterUpgrade(cluster *armcontainerservice.ManagedCluster, p *MutationParams, plan *dryrun.Preview) error {
+	if cluster.Properties == nil {
+		return fmt.Errorf("cluster %s has no properties", p.ClusterName)
+	}
+	props := cluster.Properties
+
+	current := derefString(props.CurrentKubernetesVersion)
+	if current == "" {
+		current = derefString(props.KubernetesVersion)
+	}
+	plan.AddChange("kubernetes_version", current, p.KubernetesVersion)
+	props.KubernetesVersion = to.Ptr(p.KubernetesVersion)
+
+	if p.ControlPlaneOnly {
+		plan.AddNote("control_plane_only is set, node pools keep their current version")
+		return nil
+	}
+	for _, pool := range props.AgentPoolProfiles {
+		if pool == nil {
+			continue
+		}
+		currentPool := derefString(pool.CurrentOrchestratorVersion)
+		if currentPool == "" {
+			currentPool = derefString(pool.OrchestratorVersion)
+		}
+		plan.AddChange(fmt.Sprintf("node_pools[%s].orchestrator_version", derefString(pool.Name)), currentPool, p.KubernetesVersion)
+		pool.OrchestratorVersion = to.Ptr(p.KubernetesVersion)
+	}
+	return nil
+}
+
+// planNodeCount sets the node count, refusing pools managed by the cluster autoscaler
+func planNodeCount(pool *armcontainerservice.AgentPool, p *MutationParams, plan *dryrun.Preview) error {
+	if pool.Properties.EnableAutoScaling != nil && *pool.Properties.EnableAutoScaling {
+		return fmt.Errorf("node pool %s has the cluster autoscaler enabled; change its min/max count instead of scaling it manually", p.NodePoolName)
+	}
+	plan.AddChange(fmt.Sprintf("node_pools[%s].count", p.NodePoolName), derefInt32(pool.Properties.Count), *p.NodeCount)
+	pool.Properties.Count = p.NodeCount
+	return nil
+}
+
+// planNodepoolUpgrade sets the target orchestrator version of the node pool
+func planNodepoolUpgrade(pool *armcontainerservice.AgentPool, p *MutationParams, plan *dryrun.Preview) error {
+	current := derefString(pool.Properties.CurrentOrchestratorVersion)
+	if current == "" {
+		current = derefString(pool.Properties.OrchestratorVersion)
+	}
+	plan.AddChange(fmt.Sprintf("node_pools[%s].orchestrator_version", p.NodePoolName), current, p.KubernetesVersion)
+	pool.Properties.OrchestratorVersion = to.Ptr(p.KubernetesVersion)
+	return nil
+}
+
+// newAgentPool builds the node pool created by nodepool-add
+func newAgentPool(p *MutationParams) armcontainerservice.AgentPool {
+	properties := &armcontainerservice.ManagedClusterAgentPoolProfileProperties{
+		Count: p.NodeCount,
+		Mode:  to.Ptr(armcontainerservice.AgentPoolMode(p.Mode)),
+	}
+	if p.VMSize != "" {
+		properties.VMSize = to.Ptr(p.VMSize)
+	}
+	if p.KubernetesVersion != "" {
+		properties.OrchestratorVersion = to.Ptr(p.KubernetesVersion)
+	}
+	return armcontainerservice.AgentPool{Properties: properties}
+}
+
+// describeCluster summarizes a cluster for a change preview
+func describeCluster(cluster *armcontainerservice.ManagedCluster) string {
+	if cluster.Properties == nil {
+		return "exists"
+	}
+	nodes := int32(0)
+	for _, pool := range cluster.Properties.AgentPoolProfiles {
+		if pool != nil {
+			nodes += derefInt32(pool.Count)
+		}
+	}
+	return fmt.Sprintf("exists (Kubernetes %s, %d node pools, %d nodes)",
+		derefString(cluster.Properties.CurrentKubernetesVersion), len(cluster.Properties.AgentPoolProfiles), nodes)
+}
+
+// describeAgentPool summarizes a new node pool for a change preview
+func describeAgentPool(props *armcontainerservice.ManagedClusterAgentPoolProfileProperties) string {
+	description := fmt.Sprintf("%s pool with %d nodes", derefString((*string)(props.Mode)), derefInt32(props.Count))
+	if props.VMSize != nil {
+		description += " of size " + *props.VMSize
+	}
+	if props.OrchestratorVersion != nil {
+		description += " on Kubernetes " + *props.OrchestratorVersion
+	}
+	return description
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func derefInt32(i *int32) int32 {
+	if i == nil {
+		return 0
+	}
+	return *i
+}
+
+// mutationSummary returns the operation-specific parameters recorded with the tracked operation
+func mutationSummary(p *MutationParams) map[string]interface{} {
+	summary := make(map[string]interface{})
+	if p.NodePoolName != "" {
+		summary["nodepool_name"] = p.NodePoolName
+	}
+	if p.NodeCount != nil {
+		summary["node_count"] = *p.NodeCount
+	}
+	if p.KubernetesVersion != "" {
+		summary["kubernetes_version"] = p.KubernetesVersion
+	}
+	if p.VMSize != "" {
+		summary["vm_size"] = p.VMSize
+	}
+	if p.Mode != "" {
+		summary["mode"] = p.Mode
+	}
+	if p.ControlPlaneOnly {
+		summary["control_plane_only"] = true
+	}
+	return summary
+}
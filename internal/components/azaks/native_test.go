@@ -0,0 +1,248 @@
+package azaks
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/confirm"
+	"github.com/Azure/aks-mcp/internal/dryrun"
+	"github.com/Azure/aks-mcp/internal/security"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+)
+
+func baseMutationParams(extra map[string]interface{}) map[string]interface{} {
+	params := map[string]interface{}{
+		"subscription_id": "sub-1",
+		"resource_group":  "rg-1",
+		"cluster_name":    "aks-1",
+	}
+	for key, value := range extra {
+		params[key] = value
+	}
+	return params
+}
+
+func TestParseMutationParams_Validation(t *testing.T) {
+	testCases := []struct {
+		name      string
+		operation string
+		params    map[string]interface{}
+		wantErr   string
+	}{
+		{"scale requires node_count", "scale", baseMutationParams(nil), "missing required parameter 'node_count'"},
+		{"scale", "scale", baseMutationParams(map[string]interface{}{"node_count": float64(4)}), ""},
+		{"scale with string count", "scale", baseMutationParams(map[string]interface{}{"node_count": "4"}), ""},
+		{"fractional node_count", "scale", baseMutationParams(map[string]interface{}{"node_count": 2.5}), "expected an integer"},
+		{"negative node_count", "nodepool-scale", baseMutationParams(map[string]interface{}{"nodepool_name": "np", "node_count": float64(-1)}), "must be between 0 and 1000"},
+		{"upgrade requires version", "upgrade", baseMutationParams(nil), "missing required parameter 'kubernetes_version'"},
+		{"nodepool-scale requires name", "nodepool-scale", baseMutationParams(map[string]interface{}{"node_count": float64(2)}), "missing required parameter 'nodepool_name'"},
+		{"nodepool-upgrade requires version", "nodepool-upgrade", baseMutationParams(map[string]interface{}{"nodepool_name": "np"}), "missing required parameter 'kubernetes_version'"},
+		{"nodepool-add invalid mode", "nodepool-add", baseMutationParams(map[string]interface{}{"nodepool_name": "np", "mode": "Spot"}), "must be one of System, User"},
+		{"delete", "delete", baseMutationParams(nil), ""},
+		{"missing cluster", "delete", map[string]interface{}{"subscription_id": "s", "resource_group": "rg"}, "cluster_name"},
+		{"missing subscription", "nodepool-scale", map[string]interface{}{"resource_group": "rg", "cluster_name": "c", "nodepool_name": "np", "node_count": float64(2)}, "missing required parameter 'subscription_id'"},
+		{"not native", "show", baseMutationParams(nil), "not implemented natively"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseMutationParams(tc.operation, tc.params)
+			if tc.wantErr == "" && err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Errorf("Expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestParseMutationParams_NodepoolAddDefaults(t *testing.T) {
+	p, err := ParseMutationParams("nodepool-add", baseMutationParams(map[string]interface{}{
+		"nodepool_name": "gpu",
+		"vm_size":       "Standard_NC6s_v3",
+		"mode":          "system",
+	}))
+	if err != nil {
+		t.Fatalf("ParseMutationParams returned error: %v", err)
+	}
+
+	if p.NodeCount == nil || *p.NodeCount != 3 {
+		t.Errorf("Expected default node count 3, got %v", p.NodeCount)
+	}
+	if p.Mode != "System" {
+		t.Errorf("Expected mode to be normalized to System, got %s", p.Mode)
+	}
+	expectedID := "/subscriptions/sub-1/resourceGroups/rg-1/providers/Microsoft.ContainerService/managedClusters/aks-1/agentPools/gpu"
+	if p.ResourceID() != expectedID {
+		t.Errorf("Expected resource ID %s, got %s", expectedID, p.ResourceID())
+	}
+}
+
+func TestExecute_NativeOperations(t *testing.T) {
+	executor := NewAksOperationsExecutor(nil)
+
+	// Access checks still apply before the SDK is used
+	_, err := executor.Execute(baseMutationParams(map[string]interface{}{"operation": "scale", "node_count": float64(3)}),
+		&config.ConfigData{AccessLevel: "readonly"})
+	if err == nil || !strings.Contains(err.Error(), "requires readwrite") {
+		t.Errorf("Expected access level error, got %v", err)
+	}
+
+	_, err = executor.Execute(baseMutationParams(map[string]interface{}{"operation": "scale", "node_count": float64(3)}),
+		&config.ConfigData{AccessLevel: "readwrite"})
+	if err == nil || !strings.Contains(err.Error(), "azure client is required") {
+		t.Errorf("Expected missing client error, got %v", err)
+	}
+
+	for _, operation := range []string{"scale", "upgrade", "delete", "nodepool-add", "nodepool-scale", "nodepool-upgrade"} {
+		if !IsNativeOperation(operation) {
+			t.Errorf("Expected %s to be a native operation", operation)
+		}
+	}
+	if IsNativeOperation("create") || IsNativeOperation("show") {
+		t.Error("Expected create and show to stay on the az CLI")
+	}
+}
+
+func TestExecute_DryRun(t *testing.T) {
+	executor := NewAksOperationsExecutor(nil)
+
+	t.Run("native operation denied by access level", func(t *testing.T) {
+		output, err := executor.Execute(baseMutationParams(map[string]interface{}{
+			"operation":  "scale",
+			"node_count": float64(3),
+			"dry_run":    true,
+		}), &config.ConfigData{AccessLevel: "readonly"})
+		if err != nil {
+			t.Fatalf("Expected a preview, got error %v", err)
+		}
+
+		var preview dryrun.Preview
+		if err := json.Unmarshal([]byte(output), &preview); err != nil {
+			t.Fatalf("Failed to parse preview: %v", err)
+		}
+		if !preview.DryRun || preview.Policy.Allowed || !strings.Contains(preview.Policy.Reason, "requires readwrite") {
+			t.Errorf("Expected a denied policy decision, got %+v", preview.Policy)
+		}
+	})
+
+	t.Run("cli operation", func(t *testing.T) {
+		output, err := executor.Execute(baseMutationParams(map[string]interface{}{
+			"operation": "show",
+			"dry_run":   "true",
+		}), &config.ConfigData{AccessLevel: "readonly", SecurityConfig: security.NewSecurityConfig()})
+		if err != nil {
+			t.Fatalf("Expected a preview, got error %v", err)
+		}
+
+		var preview dryrun.Preview
+		if err := json.Unmarshal([]byte(output), &preview); err != nil {
+			t.Fatalf("Failed to parse preview: %v", err)
+		}
+		if preview.Command != "az aks show --subscription sub-1 --resource-group rg-1 --name aks-1" {
+			t.Errorf("Unexpected command: %s", preview.Command)
+		}
+		if !preview.Policy.Allowed {
+			t.Errorf("Expected show to be allowed, got %+v", preview.Policy)
+		}
+	})
+
+	t.Run("invalid parameters still fail", func(t *testing.T) {
+		_, err := executor.Execute(baseMutationParams(map[string]interface{}{
+			"operation": "scale",
+			"dry_run":   true,
+		}), &config.ConfigData{AccessLevel: "readwrite"})
+		if err == nil || !strings.Contains(err.Error(), "node_count") {
+			t.Errorf("Expected missing node_count error, got %v", err)
+		}
+	})
+}
+
+func TestPlanNodeCount(t *testing.T) {
+	p := &MutationParams{NodePoolName: "np1", NodeCount: to.Ptr(int32(5))}
+
+	pool := &armcontainerservice.AgentPool{Properties: &armcontainerservice.ManagedClusterAgentPoolProfileProperties{Count: to.Ptr(int32(3))}}
+	plan := dryrun.New("az_aks_operations", "nodepool-scale", dryrun.Policy{Allowed: true})
+	if err := planNodeCount(pool, p, plan); err != nil {
+		t.Fatalf("planNodeCount returned error: %v", err)
+	}
+	if *pool.Properties.Count != 5 {
+		t.Errorf("Expected count 5, got %d", *pool.Properties.Count)
+	}
+	if len(plan.Changes) != 1 || plan.Changes[0].Current != int32(3) || plan.Changes[0].Proposed != int32(5) {
+		t.Errorf("Unexpected changes: %+v", plan.Changes)
+	}
+
+	autoscaled := &armcontainerservice.AgentPool{Properties: &armcontainerservice.ManagedClusterAgentPoolProfileProperties{
+		Count:             to.Ptr(int32(3)),
+		EnableAutoScaling: to.Ptr(true),
+	}}
+	if err := planNodeCount(autoscaled, p, plan); err == nil || !strings.Contains(err.Error(), "autoscaler") {
+		t.Errorf("Expected autoscaler error, got %v", err)
+	}
+}
+
+func TestPlanClusterUpgrade(t *testing.T) {
+	newCluster := func() *armcontainerservice.ManagedCluster {
+		return &armcontainerservice.ManagedCluster{Properties: &armcontainerservice.ManagedClusterProperties{
+			CurrentKubernetesVersion: to.Ptr("1.29.4"),
+			AgentPoolProfiles: []*armcontainerservice.ManagedClusterAgentPoolProfile{
+				{Name: to.Ptr("system"), CurrentOrchestratorVersion: to.Ptr("1.29.4")},
+				{Name: to.Ptr("user"), CurrentOrchestratorVersion: to.Ptr("1.30.0")},
+			},
+		}}
+	}
+
+	cluster := newCluster()
+	plan := dryrun.New("az_aks_operations", "upgrade", dryrun.Policy{Allowed: true})
+	if err := planClusterUpgrade(cluster, &MutationParams{KubernetesVersion: "1.30.0"}, plan); err != nil {
+		t.Fatalf("planClusterUpgrade returned error: %v", err)
+	}
+	if *cluster.Properties.KubernetesVersion != "1.30.0" || *cluster.Properties.AgentPoolProfiles[0].OrchestratorVersion != "1.30.0" {
+		t.Error("Expected the cluster and its node pools to target 1.30.0")
+	}
+	// The user pool is already on the target version
+	if len(plan.Changes) != 2 || len(plan.Notes) != 1 {
+		t.Errorf("Expected 2 changes and 1 note, got %+v / %+v", plan.Changes, plan.Notes)
+	}
+
+	cluster = newCluster()
+	plan = dryrun.New("az_aks_operations", "upgrade", dryrun.Policy{Allowed: true})
+	if err := planClusterUpgrade(cluster, &MutationParams{KubernetesVersion: "1.30.0", ControlPlaneOnly: true}, plan); err != nil {
+		t.Fatalf("planClusterUpgrade returned error: %v", err)
+	}
+	if cluster.Properties.AgentPoolProfiles[0].OrchestratorVersion != nil {
+		t.Error("Expected node pools to be left alone for a control plane only upgrade")
+	}
+	if len(plan.Changes) != 1 {
+		t.Errorf("Expected only the control plane change, got %+v", plan.Changes)
+	}
+}
+
+func TestExecute_RequiresConfirmation(t *testing.T) {
+	executor := NewAksOperationsExecutor(nil)
+	cfg := &config.ConfigData{AccessLevel: "readwrite"}
+	params := baseMutationParams(map[string]interface{}{"operation": "delete"})
+
+	output, err := executor.Execute(params, cfg)
+	if err != nil {
+		t.Fatalf("Expected a confirmation request, got error %v", err)
+	}
+	var confirmation confirm.Confirmation
+	if err := json.Unmarshal([]byte(output), &confirmation); err != nil {
+		t.Fatalf("Failed to parse confirmation: %v", err)
+	}
+	if !confirmation.ConfirmationRequired || !strings.HasSuffix(confirmation.Target, "/managedClusters/aks-1") {
+		t.Errorf("Unexpected confirmation: %+v", confirmation)
+	}
+
+	// With the token the operation proceeds to the SDK
+	params[confirm.ParamName] = confirmation.Token
+	if _, err := executor.Execute(params, cfg); err == nil || !strings.Contains(err.Error(), "azure client is required") {
+		t.Errorf("Expected the confirmed operation to run, got %v", err)
+	}
+}
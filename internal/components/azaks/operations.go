@@ -0,0 +1,388 @@
+package azaks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+// Operation states reported by aks_operation_status
+const (
+	OperationStatusInProgress = "InProgress"
+	OperationStatusSucceeded  = "Succeeded"
+	OperationStatusFailed     = "Failed"
+)
+
+// FinishedOperationRetention is how long a finished operation stays in the tracker
+const FinishedOperationRetention = time.Hour
+
+// lroPoller is the subset of runtime.Poller used to track a long-running operation
+type lroPoller interface {
+	Done() bool
+	Poll(ctx context.Context) (*http.Response, error)
+	Result(ctx context.Context) (interface{}, error)
+	ResumeToken() (string, error)
+}
+
+// pollerResumer rebuilds the poller of an operation from the SDK resume token of its poller
+type pollerResumer func(ctx context.Context, operation, resourceID, token string) (lroPoller, error)
+
+// sdkPoller adapts a typed SDK poller to lroPoller
+type sdkPoller[T any] struct {
+	poller *runtime.Poller[T]
+}
+
+func (p sdkPoller[T]) Done() bool {
+	return p.poller.Done()
+}
+
+func (p sdkPoller[T]) Poll(ctx context.Context) (*http.Response, error) {
+	return p.poller.Poll(ctx)
+}
+
+func (p sdkPoller[T]) Result(ctx context.Context) (interface{}, error) {
+	return p.poller.Result(ctx)
+}
+
+func (p sdkPoller[T]) ResumeToken() (string, error) {
+	return p.poller.ResumeToken()
+}
+
+// OperationStatus describes the state of a tracked long-running operation
+type OperationStatus struct {
+	OperationID string                 `json:"operation_id"`
+	Operation   string                 `json:"operation"`
+	ResourceID  string                 `json:"resource_id"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+	Status      string                 `json:"status"`
+	Error       string                 `json:"error,omitempty"`
+	StartedAt   time.Time              `json:"started_at"`
+	CompletedAt *time.Time             `json:"completed_at,omitempty"`
+	// ResumeToken continues tracking the operation in another server process, e.g. after a restart
+	ResumeToken string `json:"resume_token,omitempty"`
+}
+
+// resumeState is the content of a resume token
+type resumeState struct {
+	Operation  string `json:"operation"`
+	ResourceID string `json:"resource_id"`
+	Token      string `json:"token"`
+}
+
+// encodeResumeToken wraps the SDK poller token with what is needed to rebuild the poller
+func encodeResumeToken(operation, resourceID, token string) (string, error) {
+	data, err := json.Marshal(resumeState{Operation: operation, ResourceID: resourceID, Token: token})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeResumeToken reads a resume token and checks that its resource fits the operation and that
+// the SDK token only polls the Azure Resource Manager endpoint within the resource's subscription.
+// Resume tokens come from the client, so a crafted one must not make the server send its ARM
+// credential to another host.
+func decodeResumeToken(resumeToken, armEndpoint string) (resumeState, error) {
+	var state resumeState
+	data, err := base64.RawURLEncoding.DecodeString(resumeToken)
+	if err == nil {
+		err = json.Unmarshal(data, &state)
+	}
+	if err != nil || state.Operation == "" || state.ResourceID == "" || state.Token == "" {
+		return resumeState{}, fmt.Errorf("invalid resume_token")
+	}
+
+	id, err := arm.ParseResourceID(state.ResourceID)
+	if err != nil {
+		return resumeState{}, fmt.Errorf("invalid resume_token: invalid resource ID: %v", err)
+	}
+	if expected, ok := resumableResourceTypes[AksOperationType(state.Operation)]; !ok {
+		return resumeState{}, fmt.Errorf("invalid resume_token: operation '%s' cannot be resumed", state.Operation)
+	} else if !strings.EqualFold(id.ResourceType.String(), expected) {
+		return resumeState{}, fmt.Errorf("invalid resume_token: operation '%s' acts on %s, not %s", state.Operation, expected, id.ResourceType)
+	}
+	if err := checkPollingURLs(state.Token, armEndpoint, id.SubscriptionID); err != nil {
+		return resumeState{}, fmt.Errorf("invalid resume_token: %v", err)
+	}
+	return state, nil
+}
+
+// resumableResourceTypes is the resource type each resumable operation acts on
+var resumableResourceTypes = map[AksOperationType]string{
+	OpClusterDelete:   "Microsoft.ContainerService/managedClusters",
+	OpClusterUpgrade:  "Microsoft.ContainerService/managedClusters",
+	OpClusterScale:    "Microsoft.ContainerService/managedClusters/agentPools",
+	OpNodepoolAdd:     "Microsoft.ContainerService/managedClusters/agentPools",
+	OpNodepoolScale:   "Microsoft.ContainerService/managedClusters/agentPools",
+	OpNodepoolUpgrade: "Microsoft.ContainerService/managedClusters/agentPools",
+}
+
+// checkPollingURLs checks the URLs of an SDK poller token. The SDK wraps the poller state as
+// {"type": ..., "token": {...}} and names the URLs it polls and fetches the result from with a
+// "URL" suffix (asyncURL, locURL, origURL, oplocURL, pollURL).
+func checkPollingURLs(sdkToken, armEndpoint, subscriptionID string) error {
+	var wrapper struct {
+		Token map[string]interface{} `json:"token"`
+	}
+	if err := json.Unmarshal([]byte(sdkToken), &wrapper); err != nil || wrapper.Token == nil {
+		return fmt.Errorf("malformed poller token")
+	}
+	endpoint, err := url.Parse(armEndpoint)
+	if err != nil {
+		return fmt.Errorf("invalid Azure Resource Manager endpoint %s", armEndpoint)
+	}
+
+	subscriptionPath := "/subscriptions/" + strings.ToLower(subscriptionID) + "/"
+	urls := 0
+	for key, value := range wrapper.Token {
+		if !strings.HasSuffix(key, "URL") {
+			continue
+		}
+		raw, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("poller token field %s is not a URL", key)
+		}
+		if raw == "" {
+			continue
+		}
+		parsed, err := url.Parse(raw)
+		if err != nil || parsed.Scheme != "https" || !strings.EqualFold(parsed.Host, endpoint.Host) || parsed.User != nil {
+			return fmt.Errorf("poller token field %s does not point to %s", key, armEndpoint)
+		}
+		if !strings.HasPrefix(strings.ToLower(parsed.EscapedPath()), subscriptionPath) {
+			return fmt.Errorf("poller token field %s is outside subscription %s", key, subscriptionID)
+		}
+		urls++
+	}
+	if urls == 0 {
+		return fmt.Errorf("poller token has no polling URL")
+	}
+	return nil
+}
+
+// trackedOperation is a long-running operation started by this server. pollMu serializes the
+// requests of the poller; mu only guards status, so reading it never waits for a request.
+type trackedOperation struct {
+	pollMu sync.Mutex
+	mu     sync.Mutex
+	status OperationStatus
+	poller lroPoller
+}
+
+// OperationTracker keeps the long-running operations started by this server process.
+// Finished operations are evicted once they are older than the retention.
+type OperationTracker struct {
+	mu         sync.RWMutex
+	operations map[string]*trackedOperation
+	retention  time.Duration
+	// armEndpoint is the only host resumed pollers may poll
+	armEndpoint string
+}
+
+// NewOperationTracker creates an empty tracker for operations on the public Azure cloud, where
+// the ARM clients of this server send their requests
+func NewOperationTracker() *OperationTracker {
+	return &OperationTracker{
+		operations:  make(map[string]*trackedOperation),
+		retention:   FinishedOperationRetention,
+		armEndpoint: cloud.AzurePublic.Services[cloud.ResourceManager].Endpoint,
+	}
+}
+
+// defaultTracker is shared by the az_aks_operations and aks_operation_status tools
+var defaultTracker = NewOperationTracker()
+
+// DefaultOperationTracker returns the process-wide operation tracker
+func DefaultOperationTracker() *OperationTracker {
+	return defaultTracker
+}
+
+// Track registers a started operation and returns its initial status
+func (t *OperationTracker) Track(operation, resourceID string, parameters map[string]interface{}, poller lroPoller) OperationStatus {
+	op := &trackedOperation{
+		status: OperationStatus{
+			OperationID: newOperationID(),
+			Operation:   operation,
+			ResourceID:  resourceID,
+			Parameters:  parameters,
+			Status:      OperationStatusInProgress,
+			StartedAt:   time.Now().UTC(),
+		},
+		poller: poller,
+	}
+	if !poller.Done() {
+		if token, err := poller.ResumeToken(); err == nil {
+			op.status.ResumeToken, _ = encodeResumeToken(operation, resourceID, token)
+		}
+	}
+
+	t.evictFinished()
+	t.mu.Lock()
+	t.operations[op.status.OperationID] = op
+	t.mu.Unlock()
+
+	// Operations the service completes synchronously are reported as finished right away
+	if poller.Done() {
+		op.complete(context.Background())
+	}
+	return op.snapshot()
+}
+
+// Status polls the operation once, if it is still running, and returns its current status
+func (t *OperationTracker) Status(ctx context.Context, operationID string) (OperationStatus, error) {
+	t.evictFinished()
+	t.mu.RLock()
+	op, ok := t.operations[operationID]
+	t.mu.RUnlock()
+	if !ok {
+		return OperationStatus{}, fmt.Errorf("unknown operation_id '%s'. Operations are only tracked by the server process that started them "+
+			"and finished ones are kept for %s; pass the resume_token of a running operation instead", operationID, t.retention)
+	}
+
+	op.refresh(ctx)
+	return op.snapshot(), nil
+}
+
+// Resume returns the status of the operation a resume token belongs to. Operations this tracker
+// does not know, e.g. because they were started before a restart, are rebuilt with resume.
+func (t *OperationTracker) Resume(ctx context.Context, resumeToken string, resume pollerResumer) (OperationStatus, error) {
+	t.evictFinished()
+	for _, op := range t.tracked() {
+		if status := op.snapshot(); status.ResumeToken == resumeToken {
+			return t.Status(ctx, status.OperationID)
+		}
+	}
+
+	state, err := decodeResumeToken(resumeToken, t.armEndpoint)
+	if err != nil {
+		return OperationStatus{}, err
+	}
+	poller, err := resume(ctx, state.Operation, state.ResourceID, state.Token)
+	if err != nil {
+		return OperationStatus{}, fmt.Errorf("failed to resume operation %s on %s: %v", state.Operation, state.ResourceID, err)
+	}
+	started := t.Track(state.Operation, state.ResourceID, nil, poller)
+	return t.Status(ctx, started.OperationID)
+}
+
+// tracked returns the tracked operations. Their status is read after t.mu is released.
+func (t *OperationTracker) tracked() []*trackedOperation {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	ops := make([]*trackedOperation, 0, len(t.operations))
+	for _, op := range t.operations {
+		ops = append(ops, op)
+	}
+	return ops
+}
+
+// evictFinished drops the operations that finished longer ago than the retention
+func (t *OperationTracker) evictFinished() {
+	now := time.Now()
+	var expired []string
+	for _, op := range t.tracked() {
+		if status := op.snapshot(); status.CompletedAt != nil && now.Sub(*status.CompletedAt) >= t.retention {
+			expired = append(expired, status.OperationID)
+		}
+	}
+	if len(expired) == 0 {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, id := range expired {
+		delete(t.operations, id)
+	}
+}
+
+// List returns the status of all tracked operations, newest first, without polling them
+func (t *OperationTracker) List() []OperationStatus {
+	t.evictFinished()
+	ops := t.tracked()
+	statuses := make([]OperationStatus, 0, len(ops))
+	for _, op := range ops {
+		statuses = append(statuses, op.snapshot())
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].StartedAt.After(statuses[j].StartedAt)
+	})
+	return statuses
+}
+
+// refresh advances the poller by one request
+func (op *trackedOperation) refresh(ctx context.Context) {
+	op.pollMu.Lock()
+	defer op.pollMu.Unlock()
+
+	if op.snapshot().Status != OperationStatusInProgress {
+		return
+	}
+
+	if !op.poller.Done() {
+		if _, err := op.poller.Poll(ctx); err != nil {
+			op.finish(OperationStatusFailed, err)
+			return
+		}
+		if !op.poller.Done() {
+			return
+		}
+	}
+
+	op.completePolled(ctx)
+}
+
+// complete records the final result of a finished poller
+func (op *trackedOperation) complete(ctx context.Context) {
+	op.pollMu.Lock()
+	defer op.pollMu.Unlock()
+	op.completePolled(ctx)
+}
+
+// completePolled fetches the result of a finished poller. The caller holds pollMu.
+func (op *trackedOperation) completePolled(ctx context.Context) {
+	if _, err := op.poller.Result(ctx); err != nil {
+		op.finish(OperationStatusFailed, err)
+		return
+	}
+	op.finish(OperationStatusSucceeded, nil)
+}
+
+func (op *trackedOperation) finish(status string, err error) {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	now := time.Now().UTC()
+	op.status.Status = status
+	op.status.CompletedAt = &now
+	if err != nil {
+		op.status.Error = err.Error()
+	}
+}
+
+func (op *trackedOperation) snapshot() OperationStatus {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	return op.status
+}
+
+// newOperationID returns a random identifier for a tracked operation
+func newOperationID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("op-%d", time.Now().UnixNano())
+	}
+	return "op-" + hex.EncodeToString(b)
+}
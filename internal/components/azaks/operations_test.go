@@ -0,0 +1,249 @@
+package azaks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakePoller completes after the configured number of polls
+type fakePoller struct {
+	pollsLeft int
+	pollErr   error
+	resultErr error
+	polls     int
+}
+
+func (p *fakePoller) Done() bool {
+	return p.pollsLeft <= 0
+}
+
+func (p *fakePoller) Poll(context.Context) (*http.Response, error) {
+	p.polls++
+	if p.pollErr != nil {
+		return nil, p.pollErr
+	}
+	p.pollsLeft--
+	return &http.Response{StatusCode: http.StatusOK}, nil
+}
+
+func (p *fakePoller) Result(context.Context) (interface{}, error) {
+	return nil, p.resultErr
+}
+
+func (p *fakePoller) ResumeToken() (string, error) {
+	if p.Done() {
+		return "", fmt.Errorf("poller is done")
+	}
+	return sdkToken("https://management.azure.com/subscriptions/s/providers/Microsoft.ContainerService/locations/westus/operations/1"), nil
+}
+
+// sdkToken builds an SDK poller token that polls asyncURL
+func sdkToken(asyncURL string) string {
+	return fmt.Sprintf(`{"type":"Azure-AsyncOperation","token":{"asyncURL":%q,"locURL":"","origURL":"https://management.azure.com/subscriptions/s/resourceGroups/rg/providers/Microsoft.ContainerService/managedClusters/c","method":"PUT","state":"InProgress"}}`, asyncURL)
+}
+
+func TestOperationTracker_Lifecycle(t *testing.T) {
+	tracker := NewOperationTracker()
+	poller := &fakePoller{pollsLeft: 2}
+
+	started := tracker.Track("nodepool-scale", "/subscriptions/s/resourceGroups/rg/providers/Microsoft.ContainerService/managedClusters/c/agentPools/np",
+		map[string]interface{}{"node_count": int32(5)}, poller)
+	if started.Status != OperationStatusInProgress || !strings.HasPrefix(started.OperationID, "op-") {
+		t.Fatalf("Unexpected initial status: %+v", started)
+	}
+
+	status, err := tracker.Status(context.Background(), started.OperationID)
+	if err != nil {
+		t.Fatalf("Status returned error: %v", err)
+	}
+	if status.Status != OperationStatusInProgress {
+		t.Errorf("Expected operation to still be in progress, got %s", status.Status)
+	}
+
+	status, _ = tracker.Status(context.Background(), started.OperationID)
+	if status.Status != OperationStatusSucceeded || status.CompletedAt == nil {
+		t.Errorf("Expected operation to have succeeded, got %+v", status)
+	}
+
+	// Finished operations are not polled again
+	_, _ = tracker.Status(context.Background(), started.OperationID)
+	if poller.polls != 2 {
+		t.Errorf("Expected 2 polls, got %d", poller.polls)
+	}
+
+	if _, err := tracker.Status(context.Background(), "op-unknown"); err == nil {
+		t.Error("Expected error for unknown operation ID")
+	}
+}
+
+func TestOperationTracker_Failures(t *testing.T) {
+	tracker := NewOperationTracker()
+
+	pollFailure := tracker.Track("upgrade", "id-1", nil, &fakePoller{pollsLeft: 1, pollErr: fmt.Errorf("throttled")})
+	status, _ := tracker.Status(context.Background(), pollFailure.OperationID)
+	if status.Status != OperationStatusFailed || status.Error != "throttled" {
+		t.Errorf("Expected poll failure to be reported, got %+v", status)
+	}
+
+	// A poller that is already done is resolved when it is tracked
+	immediate := tracker.Track("delete", "id-2", nil, &fakePoller{resultErr: fmt.Errorf("conflict")})
+	if immediate.Status != OperationStatusFailed || immediate.Error != "conflict" {
+		t.Errorf("Expected synchronous failure to be reported, got %+v", immediate)
+	}
+
+	if len(tracker.List()) != 2 {
+		t.Errorf("Expected 2 tracked operations, got %d", len(tracker.List()))
+	}
+}
+
+func TestGetAksOperationStatusHandler(t *testing.T) {
+	tracker := NewOperationTracker()
+	started := tracker.Track("scale", "id-1", nil, &fakePoller{pollsLeft: 1})
+	handler := GetAksOperationStatusHandler(tracker, nil)
+
+	output, err := handler.Handle(map[string]interface{}{"operation_id": started.OperationID}, nil)
+	if err != nil {
+		t.Fatalf("Handle returned error: %v", err)
+	}
+	var status OperationStatus
+	if err := json.Unmarshal([]byte(output), &status); err != nil {
+		t.Fatalf("Failed to parse status: %v", err)
+	}
+	if status.OperationID != started.OperationID || status.Status != OperationStatusSucceeded {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+
+	output, err = handler.Handle(map[string]interface{}{}, nil)
+	if err != nil {
+		t.Fatalf("Handle returned error: %v", err)
+	}
+	if !strings.Contains(output, started.OperationID) {
+		t.Errorf("Expected operation list to contain %s, got %s", started.OperationID, output)
+	}
+}
+
+func TestOperationTracker_Resume(t *testing.T) {
+	resourceID := "/subscriptions/s/resourceGroups/rg/providers/Microsoft.ContainerService/managedClusters/c"
+	started := NewOperationTracker().Track("upgrade", resourceID, nil, &fakePoller{pollsLeft: 2})
+	if started.ResumeToken == "" {
+		t.Fatal("Expected an in-progress operation to have a resume token")
+	}
+
+	// A new tracker, e.g. after a restart, rebuilds the poller from the token
+	tracker := NewOperationTracker()
+	var gotOperation, gotResourceID, gotToken string
+	resumer := func(_ context.Context, operation, resourceID, token string) (lroPoller, error) {
+		gotOperation, gotResourceID, gotToken = operation, resourceID, token
+		return &fakePoller{pollsLeft: 1}, nil
+	}
+	status, err := tracker.Resume(context.Background(), started.ResumeToken, resumer)
+	if err != nil {
+		t.Fatalf("Resume returned error: %v", err)
+	}
+	if gotOperation != "upgrade" || gotResourceID != resourceID || !strings.Contains(gotToken, "asyncURL") {
+		t.Errorf("Unexpected resumer arguments: %q %q %q", gotOperation, gotResourceID, gotToken)
+	}
+	if status.Status != OperationStatusSucceeded || status.Operation != "upgrade" {
+		t.Errorf("Expected resumed operation to have succeeded, got %+v", status)
+	}
+
+	if _, err := tracker.Resume(context.Background(), "not-a-token", resumer); err == nil {
+		t.Error("Expected error for an invalid resume token")
+	}
+}
+
+func TestOperationTracker_ResumeRejectsForeignTokens(t *testing.T) {
+	clusterID := "/subscriptions/s/resourceGroups/rg/providers/Microsoft.ContainerService/managedClusters/c"
+	asyncURL := "https://management.azure.com/subscriptions/s/providers/Microsoft.ContainerService/locations/westus/operations/1"
+	tests := []struct {
+		name       string
+		operation  string
+		resourceID string
+		token      string
+	}{
+		{"foreign host", "upgrade", clusterID, sdkToken("https://attacker.example.com/subscriptions/s/operations/1")},
+		{"plain http", "upgrade", clusterID, sdkToken("http://management.azure.com/subscriptions/s/operations/1")},
+		{"other subscription", "upgrade", clusterID, sdkToken("https://management.azure.com/subscriptions/other/operations/1")},
+		{"no polling URL", "upgrade", clusterID, `{"type":"Location","token":{"state":"InProgress"}}`},
+		{"not an SDK token", "upgrade", clusterID, "sdk-token"},
+		{"operation on wrong resource", "nodepool-scale", clusterID, sdkToken(asyncURL)},
+		{"not resumable", "get-credentials", clusterID, sdkToken(asyncURL)},
+		{"not an AKS resource", "upgrade", "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/vm", sdkToken(asyncURL)},
+	}
+
+	tracker := NewOperationTracker()
+	resumer := func(context.Context, string, string, string) (lroPoller, error) {
+		t.Fatal("Resumer must not be called for a rejected token")
+		return nil, nil
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resumeToken, err := encodeResumeToken(tt.operation, tt.resourceID, tt.token)
+			if err != nil {
+				t.Fatalf("encodeResumeToken returned error: %v", err)
+			}
+			if _, err := tracker.Resume(context.Background(), resumeToken, resumer); err == nil || !strings.Contains(err.Error(), "invalid resume_token") {
+				t.Errorf("Expected the token to be rejected, got %v", err)
+			}
+		})
+	}
+}
+
+func TestOperationTracker_EvictsFinished(t *testing.T) {
+	tracker := NewOperationTracker()
+	tracker.retention = 0
+	finished := tracker.Track("delete", "id-1", nil, &fakePoller{})
+	running := tracker.Track("scale", "id-2", nil, &fakePoller{pollsLeft: 5})
+
+	operations := tracker.List()
+	if len(operations) != 1 || operations[0].OperationID != running.OperationID {
+		t.Errorf("Expected only the running operation to be kept, got %+v", operations)
+	}
+	if _, err := tracker.Status(context.Background(), finished.OperationID); err == nil {
+		t.Error("Expected evicted operation to be unknown")
+	}
+}
+
+// blockingPoller blocks in Poll until release is closed
+type blockingPoller struct {
+	fakePoller
+	polling chan struct{}
+	release chan struct{}
+}
+
+func (p *blockingPoller) Poll(ctx context.Context) (*http.Response, error) {
+	close(p.polling)
+	<-p.release
+	return p.fakePoller.Poll(ctx)
+}
+
+func TestOperationTracker_ListDoesNotWaitForPoll(t *testing.T) {
+	tracker := NewOperationTracker()
+	poller := &blockingPoller{fakePoller: fakePoller{pollsLeft: 1}, polling: make(chan struct{}), release: make(chan struct{})}
+	started := tracker.Track("upgrade", "id-1", nil, poller)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = tracker.Status(context.Background(), started.OperationID)
+	}()
+	<-poller.polling
+
+	listed := make(chan []OperationStatus)
+	go func() { listed <- tracker.List() }()
+	select {
+	case operations := <-listed:
+		if len(operations) != 1 || operations[0].Status != OperationStatusInProgress {
+			t.Errorf("Expected the polled operation to be listed as in progress, got %+v", operations)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("List blocked on an in-flight poll")
+	}
+	close(poller.release)
+	<-done
+}
@@ -3,8 +3,11 @@ package azaks
 import (
 	"fmt"
 	"slices"
+	"strings"
 
 	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/confirm"
+	"github.com/Azure/aks-mcp/internal/dryrun"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
@@ -38,12 +41,8 @@ const (
 	OpLogin       AksOperationType = "login"
 )
 
-// generateToolDescription creates a tool description based on access level
-func generateToolDescription(accessLevel string) string {
-	baseDesc := "Unified tool for managing Azure Kubernetes Service (AKS) clusters and related operations.\n\nSupported operations:\n"
-
-	var clusterOps, nodepoolOps, accountOps []string
-
+// operationGroups returns the operations available at the given access level, grouped by resource type
+func operationGroups(accessLevel string) (clusterOps, nodepoolOps, accountOps []string) {
 	// Add read-only operations for all access levels
 	clusterOps = append(clusterOps, "show", "list", "get-versions", "check-network")
 	nodepoolOps = append(nodepoolOps, "nodepool-list", "nodepool-show")
@@ -61,25 +60,70 @@ func generateToolDescription(accessLevel string) string {
 		clusterOps = append(clusterOps, "get-credentials")
 	}
 
+	return clusterOps, nodepoolOps, accountOps
+}
+
+// availableOperations returns all operations available at the given access level
+func availableOperations(accessLevel string) []string {
+	clusterOps, nodepoolOps, accountOps := operationGroups(accessLevel)
+	return slices.Concat(clusterOps, nodepoolOps, accountOps)
+}
+
+// generateToolDescription creates a tool description based on access level
+func generateToolDescription(accessLevel string) string {
+	baseDesc := "Unified tool for managing Azure Kubernetes Service (AKS) clusters and related operations.\n\nSupported operations:\n"
+
+	clusterOps, nodepoolOps, accountOps := operationGroups(accessLevel)
+
 	// Build the operations description
 	desc := baseDesc
 	desc += fmt.Sprintf("- Cluster: %s\n", joinOps(clusterOps))
 	desc += fmt.Sprintf("- Nodepool: %s\n", joinOps(nodepoolOps))
 	desc += fmt.Sprintf("- Account: %s\n", joinOps(accountOps))
 
+	// Document the parameters of each operation; the full schemas are in the input schema $defs
+	desc += "\nParameters by operation (* = required):\n"
+	for _, operation := range availableOperations(accessLevel) {
+		desc += fmt.Sprintf("- %s: %s\n", operation, describeOperationParams(operation))
+	}
+
 	// Add examples based on access level
 	desc += "\nExamples:\n"
-	desc += "- Show cluster: operation=\"show\", args=\"--name myCluster --resource-group myRG\"\n"
-	desc += "- List nodepools: operation=\"nodepool-list\", args=\"--cluster-name myCluster --resource-group myRG\"\n"
+	desc += "- Show cluster: operation=\"show\", resource_group=\"myRG\", cluster_name=\"myCluster\"\n"
+	desc += "- List nodepools: operation=\"nodepool-list\", resource_group=\"myRG\", cluster_name=\"myCluster\"\n"
 
 	// Only show write operation examples if access level allows it
 	if accessLevel == "readwrite" || accessLevel == "admin" {
-		desc += "- Scale cluster: operation=\"scale\", args=\"--name myCluster --resource-group myRG --node-count 5\"\n"
+		desc += "- Scale cluster: operation=\"scale\", subscription_id=\"<subscription-id>\", resource_group=\"myRG\", cluster_name=\"myCluster\", node_count=5\n"
+		desc += "- Upgrade node pool: operation=\"nodepool-upgrade\", subscription_id=\"<subscription-id>\", resource_group=\"myRG\", cluster_name=\"myCluster\", nodepool_name=\"userpool\", kubernetes_version=\"1.30.5\"\n"
+		desc += "\nscale, upgrade, delete, nodepool-add, nodepool-scale and nodepool-upgrade return an operation_id as soon as Azure accepts the request; " +
+			"use aks_operation_status to follow progress.\n"
 	}
 
 	return desc
 }
 
+// describeOperationParams renders the parameter list of an operation for the tool description
+func describeOperationParams(operation string) string {
+	schema, _ := GetOperationSchema(operation)
+
+	var parts []string
+	for _, spec := range schema.Params {
+		part := spec.Name
+		if spec.Required {
+			part += "*"
+		}
+		parts = append(parts, part)
+	}
+	if schema.AllowExtraArgs {
+		parts = append(parts, "args (extra az CLI flags)")
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, ", ")
+}
+
 // joinOps joins operation names with commas
 func joinOps(ops []string) string {
 	result := ""
@@ -92,22 +136,71 @@ func joinOps(ops []string) string {
 	return result
 }
 
-// RegisterAzAksOperations registers the AKS operations tool
+// RegisterAzAksOperations registers the AKS operations tool.
+// The input schema lists the union of the operation parameters; the per-operation
+// schemas are published under $defs and enforced by ValidateOperationParams.
 func RegisterAzAksOperations(cfg *config.ConfigData) mcp.Tool {
 	description := generateToolDescription(cfg.AccessLevel)
+	operations := availableOperations(cfg.AccessLevel)
 
-	return mcp.NewTool("az_aks_operations",
+	options := []mcp.ToolOption{
 		mcp.WithDescription(description),
 		mcp.WithString("operation",
 			mcp.Required(),
 			mcp.Description("The operation to perform"),
+			mcp.Enum(operations...),
 		),
 		mcp.WithString("resource_type",
 			mcp.Description("The resource type (cluster, nodepool, account). Can be inferred from operation."),
 		),
 		mcp.WithString("args",
-			mcp.Required(),
-			mcp.Description("Arguments for the operation"),
+			mcp.Description("Additional az CLI flags. Only accepted by create, update and login; all other operations take structured parameters"),
+		),
+		mcp.WithBoolean(dryrun.ParamName,
+			mcp.Description(dryrun.ParamDescription),
+		),
+		mcp.WithString(confirm.ParamName,
+			mcp.Description(confirm.ParamDescription),
+		),
+	}
+
+	for _, spec := range allParamSpecs(operations) {
+		propertyOptions := []mcp.PropertyOption{mcp.Description(paramDescriptions[spec.Name])}
+		switch spec.Type {
+		case ParamTypeInteger:
+			propertyOptions = append(propertyOptions, mcp.Min(float64(spec.Min)), mcp.Max(float64(spec.Max)))
+			options = append(options, mcp.WithNumber(spec.Name, propertyOptions...))
+		case ParamTypeBoolean:
+			options = append(options, mcp.WithBoolean(spec.Name, propertyOptions...))
+		default:
+			if len(spec.Enum) > 0 {
+				propertyOptions = append(propertyOptions, mcp.Enum(spec.Enum...))
+			}
+			options = append(options, mcp.WithString(spec.Name, propertyOptions...))
+		}
+	}
+
+	tool := mcp.NewTool("az_aks_operations", options...)
+
+	operationSchemas := make(map[string]any, len(operations))
+	for _, operation := range operations {
+		schema, _ := GetOperationSchema(operation)
+		operationSchemas[operation] = schema.JSONSchema()
+	}
+	tool.InputSchema.Defs = operationSchemas
+
+	return tool
+}
+
+// RegisterAksOperationStatusTool registers the tool that reports the progress of long-running AKS operations
+func RegisterAksOperationStatusTool() mcp.Tool {
+	return mcp.NewTool("aks_operation_status",
+		mcp.WithDescription("Get the status of long-running AKS operations (scale, upgrade, delete, nodepool-add, nodepool-scale, nodepool-upgrade) started with az_aks_operations. Omit operation_id to list the operations tracked by this server; finished operations are kept for an hour. Pass resume_token to follow an operation started before the server restarted."),
+		mcp.WithString("operation_id",
+			mcp.Description("The operation_id returned by az_aks_operations"),
+		),
+		mcp.WithString("resume_token",
+			mcp.Description("The resume_token returned by az_aks_operations or aks_operation_status"),
 		),
 	)
 }
@@ -146,6 +239,15 @@ func GetOperationAccessLevel(operation string) string {
 	return "unknown"
 }
 
+// IsClusterScopedReadOperation reports whether the operation is read-only and targets a single cluster
+func IsClusterScopedReadOperation(operation string) bool {
+	switch AksOperationType(operation) {
+	case OpClusterShow, OpClusterCheckNetwork, OpNodepoolList, OpNodepoolShow:
+		return true
+	}
+	return false
+}
+
 // ValidateOperationAccess checks if the operation is allowed for the given access level
 func ValidateOperationAccess(operation string, cfg *config.ConfigData) error {
 	requiredLevel := GetOperationAccessLevel(operation)
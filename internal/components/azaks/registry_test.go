@@ -1,6 +1,8 @@
 package azaks
 
 import (
+	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/Azure/aks-mcp/internal/config"
@@ -20,6 +22,26 @@ func TestRegisterAzAksOperations_Tool(t *testing.T) {
 	}
 }
 
+func TestGenerateToolDescription_NativeExamplesHaveRequiredParams(t *testing.T) {
+	exampleOperation := regexp.MustCompile(`^- .*operation="([^"]+)"`)
+	found := 0
+	for _, line := range strings.Split(generateToolDescription("admin"), "\n") {
+		match := exampleOperation.FindStringSubmatch(line)
+		if match == nil || !IsNativeOperation(match[1]) {
+			continue
+		}
+		found++
+		for _, param := range []string{"subscription_id=", "resource_group=", "cluster_name="} {
+			if !strings.Contains(line, param) {
+				t.Errorf("Example for %s is missing %s: %s", match[1], param, line)
+			}
+		}
+	}
+	if found == 0 {
+		t.Error("Expected the admin description to contain native operation examples")
+	}
+}
+
 func TestGetSupportedOperations_ContainsExpectedOps(t *testing.T) {
 	// Test that supported operations include expected operations
 	operations := GetSupportedOperations()
@@ -72,3 +94,22 @@ func TestValidateOperationAccess_ChecksAccessLevels(t *testing.T) {
 		}
 	}
 }
+
+func TestIsClusterScopedReadOperation(t *testing.T) {
+	testCases := map[string]bool{
+		"show":            true,
+		"check-network":   true,
+		"nodepool-list":   true,
+		"nodepool-show":   true,
+		"list":            false,
+		"get-versions":    false,
+		"scale":           false,
+		"get-credentials": false,
+	}
+
+	for operation, expected := range testCases {
+		if got := IsClusterScopedReadOperation(operation); got != expected {
+			t.Errorf("IsClusterScopedReadOperation(%q) = %v, want %v", operation, got, expected)
+		}
+	}
+}
@@ -0,0 +1,433 @@
+package azaks
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/aks-mcp/internal/clustercontext"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/confirm"
+	"github.com/Azure/aks-mcp/internal/dryrun"
+)
+
+// ParamType is the JSON type of an operation parameter
+type ParamType string
+
+const (
+	ParamTypeString  ParamType = "string"
+	ParamTypeInteger ParamType = "integer"
+	ParamTypeBoolean ParamType = "boolean"
+)
+
+// ParamSpec describes one structured parameter of an operation
+type ParamSpec struct {
+	Name     string
+	Type     ParamType
+	Required bool
+	// Flag is the az CLI flag the parameter is translated to
+	Flag string
+	// Min and Max bound integer parameters
+	Min, Max int
+	// Enum lists the allowed values of a string parameter
+	Enum []string
+}
+
+// OperationSchema describes the parameters accepted by an operation
+type OperationSchema struct {
+	Params []ParamSpec
+	// AllowExtraArgs permits additional free-form az CLI flags in args
+	AllowExtraArgs bool
+}
+
+// paramDescriptions documents each structured parameter in the tool input schema
+var paramDescriptions = map[string]string{
+	"subscription_id":    "Azure subscription ID (defaults to the active cluster context). Required by scale, upgrade, delete, nodepool-add, nodepool-scale and nodepool-upgrade; other operations default to the az CLI subscription",
+	"resource_group":     "Azure resource group containing the AKS cluster (defaults to the active cluster context)",
+	"cluster_name":       "Name of the AKS cluster (defaults to the active cluster context)",
+	"nodepool_name":      "Node pool name",
+	"node_count":         "Number of nodes (0-1000); nodepool-add defaults to 3",
+	"kubernetes_version": "Kubernetes version, e.g. 1.30.5",
+	"vm_size":            "VM size, e.g. Standard_D4s_v5",
+	"mode":               "Node pool mode: 'User' (default) or 'System'",
+	"control_plane_only": "For upgrade: upgrade only the control plane and leave node pools on their current version",
+	"location":           "Azure region, e.g. westeurope",
+	"node_name":          "For check-network: the node to run the outbound connectivity check from",
+}
+
+// Parameter building blocks shared by the operation schemas
+var (
+	subscriptionParam  = ParamSpec{Name: "subscription_id", Type: ParamTypeString, Flag: "--subscription"}
+	resourceGroupParam = ParamSpec{Name: "resource_group", Type: ParamTypeString, Required: true, Flag: "--resource-group"}
+	nodeCountParam     = ParamSpec{Name: "node_count", Type: ParamTypeInteger, Flag: "--node-count", Min: 0, Max: 1000}
+	versionParam       = ParamSpec{Name: "kubernetes_version", Type: ParamTypeString, Flag: "--kubernetes-version"}
+	nodepoolNameParam  = ParamSpec{Name: "nodepool_name", Type: ParamTypeString, Required: true, Flag: "--name"}
+)
+
+// clusterParams returns the parameters identifying a cluster. Node pool commands name the cluster with --cluster-name.
+func clusterParams(nameFlag string) []ParamSpec {
+	return []ParamSpec{
+		subscriptionParam,
+		resourceGroupParam,
+		{Name: "cluster_name", Type: ParamTypeString, Required: true, Flag: nameFlag},
+	}
+}
+
+// nativeClusterParams returns the parameters identifying the cluster of an operation that runs through the AKS SDK.
+// Unlike the az CLI, the SDK has no default subscription, so subscription_id is required.
+func nativeClusterParams(nameFlag string) []ParamSpec {
+	params := clusterParams(nameFlag)
+	params[0] = required(subscriptionParam)
+	return params
+}
+
+// required returns a copy of the spec marked as required
+func required(spec ParamSpec) ParamSpec {
+	spec.Required = true
+	return spec
+}
+
+// optional returns a copy of the spec marked as optional
+func optional(spec ParamSpec) ParamSpec {
+	spec.Required = false
+	return spec
+}
+
+// operationSchemas holds the parameter schema of every supported operation
+var operationSchemas = map[AksOperationType]OperationSchema{
+	OpClusterShow: {Params: clusterParams("--name")},
+	OpClusterList: {Params: []ParamSpec{subscriptionParam, optional(resourceGroupParam)}},
+	OpClusterCreate: {
+		Params: append(clusterParams("--name"),
+			ParamSpec{Name: "location", Type: ParamTypeString, Flag: "--location"},
+			versionParam,
+			ParamSpec{Name: "node_count", Type: ParamTypeInteger, Flag: "--node-count", Min: 1, Max: 1000},
+			ParamSpec{Name: "vm_size", Type: ParamTypeString, Flag: "--node-vm-size"},
+		),
+		AllowExtraArgs: true,
+	},
+	OpClusterDelete: {Params: nativeClusterParams("--name")},
+	OpClusterScale: {
+		Params: append(nativeClusterParams("--name"),
+			required(nodeCountParam),
+			ParamSpec{Name: "nodepool_name", Type: ParamTypeString, Flag: "--nodepool-name"},
+		),
+	},
+	OpClusterUpdate: {Params: clusterParams("--name"), AllowExtraArgs: true},
+	OpClusterUpgrade: {
+		Params: append(nativeClusterParams("--name"),
+			required(versionParam),
+			ParamSpec{Name: "control_plane_only", Type: ParamTypeBoolean, Flag: "--control-plane-only"},
+		),
+	},
+	OpClusterGetVersions: {
+		Params: []ParamSpec{subscriptionParam, {Name: "location", Type: ParamTypeString, Required: true, Flag: "--location"}},
+	},
+	OpClusterCheckNetwork: {
+		Params: append(clusterParams("--name"), ParamSpec{Name: "node_name", Type: ParamTypeString, Flag: "--node-name"}),
+	},
+	OpClusterGetCredentials: {Params: clusterParams("--name")},
+
+	OpNodepoolList:   {Params: clusterParams("--cluster-name")},
+	OpNodepoolShow:   {Params: append(clusterParams("--cluster-name"), nodepoolNameParam)},
+	OpNodepoolDelete: {Params: append(clusterParams("--cluster-name"), nodepoolNameParam)},
+	OpNodepoolAdd: {
+		Params: append(nativeClusterParams("--cluster-name"),
+			nodepoolNameParam,
+			nodeCountParam,
+			versionParam,
+			ParamSpec{Name: "vm_size", Type: ParamTypeString, Flag: "--node-vm-size"},
+			ParamSpec{Name: "mode", Type: ParamTypeString, Flag: "--mode", Enum: []string{"System", "User"}},
+		),
+	},
+	OpNodepoolScale: {
+		Params: append(nativeClusterParams("--cluster-name"), nodepoolNameParam, required(nodeCountParam)),
+	},
+	OpNodepoolUpgrade: {
+		Params: append(nativeClusterParams("--cluster-name"), nodepoolNameParam, required(versionParam)),
+	},
+
+	OpAccountList: {},
+	OpAccountSet:  {Params: []ParamSpec{required(subscriptionParam)}},
+	OpLogin:       {AllowExtraArgs: true},
+}
+
+// GetOperationSchema returns the parameter schema of an operation
+func GetOperationSchema(operation string) (OperationSchema, bool) {
+	schema, ok := operationSchemas[AksOperationType(operation)]
+	return schema, ok
+}
+
+// param returns the spec of the named parameter
+func (s OperationSchema) param(name string) (ParamSpec, bool) {
+	for _, spec := range s.Params {
+		if spec.Name == name {
+			return spec, true
+		}
+	}
+	return ParamSpec{}, false
+}
+
+// RequiredParams returns the names of the required parameters
+func (s OperationSchema) RequiredParams() []string {
+	var names []string
+	for _, spec := range s.Params {
+		if spec.Required {
+			names = append(names, spec.Name)
+		}
+	}
+	return names
+}
+
+// ParamNames returns the names of all parameters
+func (s OperationSchema) ParamNames() []string {
+	names := make([]string, 0, len(s.Params))
+	for _, spec := range s.Params {
+		names = append(names, spec.Name)
+	}
+	return names
+}
+
+// JSONSchema returns the JSON schema of the operation parameters
+func (s OperationSchema) JSONSchema() map[string]any {
+	properties := make(map[string]any, len(s.Params))
+	for _, spec := range s.Params {
+		properties[spec.Name] = spec.jsonSchema()
+	}
+	if s.AllowExtraArgs {
+		properties["args"] = map[string]any{"type": "string", "description": "Additional az CLI flags"}
+	}
+
+	schema := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if requiredNames := s.RequiredParams(); len(requiredNames) > 0 {
+		schema["required"] = requiredNames
+	}
+	return schema
+}
+
+func (p ParamSpec) jsonSchema() map[string]any {
+	schema := map[string]any{"type": string(p.Type)}
+	if description, ok := paramDescriptions[p.Name]; ok {
+		schema["description"] = description
+	}
+	if p.Type == ParamTypeInteger {
+		schema["minimum"] = p.Min
+		schema["maximum"] = p.Max
+	}
+	if len(p.Enum) > 0 {
+		schema["enum"] = p.Enum
+	}
+	return schema
+}
+
+// metaParams are tool arguments that are not operation parameters
+var metaParams = map[string]bool{
+	"operation":                 true,
+	"resource_type":             true,
+	dryrun.ParamName:            true,
+	confirm.ParamName:           true,
+	clustercontext.SessionParam: true,
+}
+
+// ValidateOperationParams checks the arguments of an operation against its schema and returns the
+// normalized parameter values. When no cluster identifier is passed, they are taken from the active cluster context.
+func ValidateOperationParams(operation string, params map[string]interface{}) (map[string]interface{}, error) {
+	schema, ok := GetOperationSchema(operation)
+	if !ok {
+		return nil, fmt.Errorf("unknown operation: %s", operation)
+	}
+
+	args := make(map[string]interface{}, len(params))
+	for key, value := range params {
+		args[key] = value
+	}
+
+	if _, ok := schema.param("cluster_name"); ok {
+		subID, rg, clusterName := common.ResolveAKSParameters(args)
+		args["subscription_id"], args["resource_group"], args["cluster_name"] = subID, rg, clusterName
+	}
+
+	// Reject arguments the operation does not understand instead of silently ignoring them
+	for key, value := range args {
+		if metaParams[key] || isEmptyValue(value) {
+			continue
+		}
+		if key == "args" {
+			if !schema.AllowExtraArgs {
+				return nil, fmt.Errorf("operation '%s' does not accept free-form args; use the structured parameters: %s", operation, strings.Join(schema.ParamNames(), ", "))
+			}
+			continue
+		}
+		if _, ok := schema.param(key); !ok {
+			supported := "none"
+			if names := schema.ParamNames(); len(names) > 0 {
+				supported = strings.Join(names, ", ")
+			}
+			return nil, fmt.Errorf("parameter '%s' is not supported by operation '%s' (supported: %s)", key, operation, supported)
+		}
+	}
+
+	values := make(map[string]interface{})
+	for _, spec := range schema.Params {
+		raw, present := args[spec.Name]
+		if !present || isEmptyValue(raw) {
+			if spec.Required {
+				return nil, fmt.Errorf("missing required parameter '%s' for operation '%s'", spec.Name, operation)
+			}
+			continue
+		}
+
+		value, err := spec.normalize(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parameter '%s' for operation '%s': %w", spec.Name, operation, err)
+		}
+		values[spec.Name] = value
+	}
+
+	if extraArgs, ok := args["args"].(string); ok && strings.TrimSpace(extraArgs) != "" {
+		values["args"] = strings.TrimSpace(extraArgs)
+	}
+
+	return values, nil
+}
+
+// normalize converts a raw argument to the parameter type and checks its constraints
+func (p ParamSpec) normalize(raw interface{}) (interface{}, error) {
+	switch p.Type {
+	case ParamTypeInteger:
+		var number float64
+		switch v := raw.(type) {
+		case float64:
+			number = v
+		case int:
+			number = float64(v)
+		case string:
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, fmt.Errorf("expected an integer, got '%s'", v)
+			}
+			number = parsed
+		default:
+			return nil, fmt.Errorf("expected an integer, got %T", raw)
+		}
+		if number != math.Trunc(number) {
+			return nil, fmt.Errorf("expected an integer, got %v", number)
+		}
+		if number < float64(p.Min) || number > float64(p.Max) {
+			return nil, fmt.Errorf("must be between %d and %d, got %v", p.Min, p.Max, number)
+		}
+		return int(number), nil
+
+	case ParamTypeBoolean:
+		switch v := raw.(type) {
+		case bool:
+			return v, nil
+		case string:
+			parsed, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("expected a boolean, got '%s'", v)
+			}
+			return parsed, nil
+		default:
+			return nil, fmt.Errorf("expected a boolean, got %T", raw)
+		}
+
+	default:
+		value, ok := raw.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a string, got %T", raw)
+		}
+		value = strings.TrimSpace(value)
+		// Values are passed to the CLI as single arguments
+		if strings.ContainsAny(value, " \t\r\n") {
+			return nil, fmt.Errorf("must not contain whitespace")
+		}
+		if len(p.Enum) > 0 {
+			for _, allowed := range p.Enum {
+				if strings.EqualFold(value, allowed) {
+					return allowed, nil
+				}
+			}
+			return nil, fmt.Errorf("must be one of %s, got '%s'", strings.Join(p.Enum, ", "), value)
+		}
+		return value, nil
+	}
+}
+
+// isEmptyValue reports whether an argument was effectively omitted
+func isEmptyValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	if s, ok := value.(string); ok {
+		return strings.TrimSpace(s) == ""
+	}
+	return false
+}
+
+// BuildCLIArgs translates validated parameter values to az CLI arguments
+func BuildCLIArgs(operation string, values map[string]interface{}) (string, error) {
+	schema, ok := GetOperationSchema(operation)
+	if !ok {
+		return "", fmt.Errorf("unknown operation: %s", operation)
+	}
+
+	var args []string
+	for _, spec := range schema.Params {
+		value, ok := values[spec.Name]
+		if !ok {
+			continue
+		}
+		switch v := value.(type) {
+		case bool:
+			if v {
+				args = append(args, spec.Flag)
+			}
+		default:
+			args = append(args, spec.Flag, fmt.Sprint(v))
+		}
+	}
+
+	if extraArgs, ok := values["args"].(string); ok && extraArgs != "" {
+		args = append(args, extraArgs)
+	}
+	return strings.Join(args, " "), nil
+}
+
+// allParamSpecs returns one spec per parameter name across the given operations, sorted by name.
+// Integer bounds are widened to cover every operation.
+func allParamSpecs(operations []string) []ParamSpec {
+	byName := make(map[string]ParamSpec)
+	for _, operation := range operations {
+		schema, ok := GetOperationSchema(operation)
+		if !ok {
+			continue
+		}
+		for _, spec := range schema.Params {
+			existing, seen := byName[spec.Name]
+			if !seen {
+				byName[spec.Name] = spec
+				continue
+			}
+			if spec.Type == ParamTypeInteger {
+				existing.Min = min(existing.Min, spec.Min)
+				existing.Max = max(existing.Max, spec.Max)
+				byName[spec.Name] = existing
+			}
+		}
+	}
+
+	specs := make([]ParamSpec, 0, len(byName))
+	for _, spec := range byName {
+		specs = append(specs, spec)
+	}
+	sort.Slice(specs, func(i, j int) bool {
+		return specs[i].Name < specs[j].Name
+	})
+	return specs
+}
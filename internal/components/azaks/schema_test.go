@@ -0,0 +1,150 @@
+package azaks
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-mcp/internal/clustercontext"
+	"github.com/Azure/aks-mcp/internal/config"
+)
+
+func TestOperationSchemas_CoverSupportedOperations(t *testing.T) {
+	for _, operation := range GetSupportedOperations() {
+		if _, ok := GetOperationSchema(operation); !ok {
+			t.Errorf("Operation '%s' has no parameter schema", operation)
+		}
+	}
+}
+
+func TestValidateOperationParams_NodepoolScale(t *testing.T) {
+	values, err := ValidateOperationParams("nodepool-scale", map[string]interface{}{
+		"operation":       "nodepool-scale",
+		"subscription_id": "sub-1",
+		"resource_group":  "rg-1",
+		"cluster_name":    "aks-1",
+		"nodepool_name":   "userpool",
+		"node_count":      float64(7),
+		"vm_size":         "",
+	})
+	if err != nil {
+		t.Fatalf("ValidateOperationParams returned error: %v", err)
+	}
+	if values["node_count"] != 7 || values["nodepool_name"] != "userpool" {
+		t.Errorf("Unexpected values: %+v", values)
+	}
+
+	schema, _ := GetOperationSchema("nodepool-scale")
+	expected := []string{"subscription_id", "resource_group", "cluster_name", "nodepool_name", "node_count"}
+	if strings.Join(schema.RequiredParams(), ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected required params %v, got %v", expected, schema.RequiredParams())
+	}
+}
+
+func TestValidateOperationParams_Errors(t *testing.T) {
+	testCases := []struct {
+		name      string
+		operation string
+		params    map[string]interface{}
+		wantErr   string
+	}{
+		{"unknown operation", "rotate", map[string]interface{}{}, "unknown operation"},
+		{"missing required", "nodepool-show", map[string]interface{}{"resource_group": "rg", "cluster_name": "c"}, "missing required parameter 'nodepool_name'"},
+		{"unsupported parameter", "show", map[string]interface{}{"resource_group": "rg", "cluster_name": "c", "node_count": float64(3)}, "parameter 'node_count' is not supported by operation 'show'"},
+		{"free-form args rejected", "show", map[string]interface{}{"resource_group": "rg", "cluster_name": "c", "args": "--output table"}, "does not accept free-form args"},
+		{"node count above range", "nodepool-scale", map[string]interface{}{"subscription_id": "s", "resource_group": "rg", "cluster_name": "c", "nodepool_name": "np", "node_count": float64(1001)}, "must be between 0 and 1000"},
+		{"node count not a number", "nodepool-scale", map[string]interface{}{"subscription_id": "s", "resource_group": "rg", "cluster_name": "c", "nodepool_name": "np", "node_count": "many"}, "expected an integer"},
+		{"whitespace in value", "show", map[string]interface{}{"resource_group": "rg --debug", "cluster_name": "c"}, "must not contain whitespace"},
+		{"wrong type", "upgrade", map[string]interface{}{"subscription_id": "s", "resource_group": "rg", "cluster_name": "c", "kubernetes_version": "1.30", "control_plane_only": "maybe"}, "expected a boolean"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ValidateOperationParams(tc.operation, tc.params)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateOperationParams_ActiveContext(t *testing.T) {
+	registry := clustercontext.Default()
+	defer registry.Reset()
+	if err := registry.SetActive(clustercontext.ClusterContext{SubscriptionID: "sub-1", ResourceGroup: "rg-1", ClusterName: "aks-1"}); err != nil {
+		t.Fatalf("SetActive returned error: %v", err)
+	}
+
+	values, err := ValidateOperationParams("nodepool-list", map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("ValidateOperationParams returned error: %v", err)
+	}
+
+	args, _ := BuildCLIArgs("nodepool-list", values)
+	if args != "--subscription sub-1 --resource-group rg-1 --cluster-name aks-1" {
+		t.Errorf("Unexpected CLI args: %s", args)
+	}
+
+	// Operations that do not target a cluster are not filled from the context
+	if _, err := ValidateOperationParams("account-set", map[string]interface{}{}); err == nil {
+		t.Error("Expected account-set to require an explicit subscription_id")
+	}
+}
+
+func TestBuildCLIArgs(t *testing.T) {
+	testCases := []struct {
+		operation string
+		params    map[string]interface{}
+		want      string
+	}{
+		{"show", map[string]interface{}{"resource_group": "rg", "cluster_name": "c"}, "--resource-group rg --name c"},
+		{"nodepool-delete", map[string]interface{}{"resource_group": "rg", "cluster_name": "c", "nodepool_name": "np"}, "--resource-group rg --cluster-name c --name np"},
+		{"get-versions", map[string]interface{}{"location": "westeurope"}, "--location westeurope"},
+		{"check-network", map[string]interface{}{"resource_group": "rg", "cluster_name": "c", "node_name": "aks-node-0"}, "--resource-group rg --name c --node-name aks-node-0"},
+		{"create", map[string]interface{}{"resource_group": "rg", "cluster_name": "c", "node_count": "2", "args": "--generate-ssh-keys"}, "--resource-group rg --name c --node-count 2 --generate-ssh-keys"},
+		{"account-list", map[string]interface{}{}, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.operation, func(t *testing.T) {
+			values, err := ValidateOperationParams(tc.operation, tc.params)
+			if err != nil {
+				t.Fatalf("ValidateOperationParams returned error: %v", err)
+			}
+			got, err := BuildCLIArgs(tc.operation, values)
+			if err != nil {
+				t.Fatalf("BuildCLIArgs returned error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("BuildCLIArgs() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRegisterAzAksOperations_Schema(t *testing.T) {
+	readonly := RegisterAzAksOperations(&config.ConfigData{AccessLevel: "readonly"})
+	if _, ok := readonly.InputSchema.Defs["show"]; !ok {
+		t.Error("Expected $defs to contain the show schema")
+	}
+	if _, ok := readonly.InputSchema.Defs["nodepool-scale"]; ok {
+		t.Error("Expected read-write operations not to be advertised at readonly access level")
+	}
+	if _, ok := readonly.InputSchema.Properties["node_count"]; ok {
+		t.Error("Expected node_count not to be advertised at readonly access level")
+	}
+
+	readwrite := RegisterAzAksOperations(&config.ConfigData{AccessLevel: "readwrite"})
+	scaleSchema, ok := readwrite.InputSchema.Defs["nodepool-scale"].(map[string]any)
+	if !ok {
+		t.Fatal("Expected $defs to contain the nodepool-scale schema")
+	}
+	requiredParams, _ := scaleSchema["required"].([]string)
+	if strings.Join(requiredParams, ",") != "subscription_id,resource_group,cluster_name,nodepool_name,node_count" {
+		t.Errorf("Unexpected required params for nodepool-scale: %v", requiredParams)
+	}
+
+	nodeCount, ok := readwrite.InputSchema.Properties["node_count"].(map[string]any)
+	if !ok || nodeCount["minimum"] != float64(0) || nodeCount["maximum"] != float64(1000) {
+		t.Errorf("Expected node_count bounded to 0-1000, got %+v", nodeCount)
+	}
+}
@@ -0,0 +1,382 @@
+package baseline
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+)
+
+// Deviation severities
+const (
+	SeverityHigh   = "high"
+	SeverityMedium = "medium"
+	SeverityLow    = "low"
+)
+
+// Setting names, used in deviations and severity overrides
+const (
+	SettingNetworkPlugin        = "network_plugin"
+	SettingNetworkPolicy        = "network_policy"
+	SettingPrivateCluster       = "private_cluster"
+	SettingAuthorizedIPRanges   = "authorized_ip_ranges"
+	SettingAADManaged           = "aad.managed"
+	SettingAADAzureRBAC         = "aad.azure_rbac"
+	SettingAADAdminGroups       = "aad.admin_group_object_ids"
+	SettingDisableLocalAccounts = "disable_local_accounts"
+	SettingAutoUpgradeChannel   = "auto_upgrade_channel"
+	SettingDefender             = "defender_enabled"
+	SettingDiagnosticCategories = "diagnostic_categories"
+)
+
+// defaultSeverities is the severity of a deviation for each setting unless the baseline overrides it
+var defaultSeverities = map[string]string{
+	SettingNetworkPlugin:        SeverityMedium,
+	SettingNetworkPolicy:        SeverityMedium,
+	SettingPrivateCluster:       SeverityHigh,
+	SettingAuthorizedIPRanges:   SeverityHigh,
+	SettingAADManaged:           SeverityHigh,
+	SettingAADAzureRBAC:         SeverityHigh,
+	SettingAADAdminGroups:       SeverityMedium,
+	SettingDisableLocalAccounts: SeverityHigh,
+	SettingAutoUpgradeChannel:   SeverityMedium,
+	SettingDefender:             SeverityHigh,
+	SettingDiagnosticCategories: SeverityMedium,
+}
+
+// categoryGroups lists the AKS log categories covered by a diagnostic setting category group
+var categoryGroups = map[string][]string{
+	"audit": {"kube-audit", "kube-audit-admin", "guard"},
+}
+
+// AADBaseline is the expected Microsoft Entra ID integration
+type AADBaseline struct {
+	Managed             *bool    `json:"managed,omitempty"`
+	EnableAzureRBAC     *bool    `json:"enable_azure_rbac,omitempty"`
+	AdminGroupObjectIDs []string `json:"admin_group_object_ids,omitempty"`
+}
+
+// Baseline is a document of golden cluster settings. Omitted settings are not checked.
+type Baseline struct {
+	NetworkPlugin        *string      `json:"network_plugin,omitempty"`
+	NetworkPolicy        *string      `json:"network_policy,omitempty"`
+	PrivateCluster       *bool        `json:"private_cluster,omitempty"`
+	AuthorizedIPRanges   []string     `json:"authorized_ip_ranges,omitempty"`
+	AAD                  *AADBaseline `json:"aad,omitempty"`
+	DisableLocalAccounts *bool        `json:"disable_local_accounts,omitempty"`
+	AutoUpgradeChannel   *string      `json:"auto_upgrade_channel,omitempty"`
+	DefenderEnabled      *bool        `json:"defender_enabled,omitempty"`
+	DiagnosticCategories []string     `json:"diagnostic_categories,omitempty"`
+	// Severities overrides the default severity of individual settings
+	Severities map[string]string `json:"severities,omitempty"`
+}
+
+// ParseBaseline decodes a baseline document, rejecting unknown settings so typos are not silently ignored
+func ParseBaseline(document string) (*Baseline, error) {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(document)))
+	decoder.DisallowUnknownFields()
+
+	var baseline Baseline
+	if err := decoder.Decode(&baseline); err != nil {
+		return nil, fmt.Errorf("invalid baseline document: %w", err)
+	}
+
+	for setting, severity := range baseline.Severities {
+		if _, ok := defaultSeverities[setting]; !ok {
+			return nil, fmt.Errorf("invalid baseline document: unknown setting '%s' in severities", setting)
+		}
+		if severity != SeverityHigh && severity != SeverityMedium && severity != SeverityLow {
+			return nil, fmt.Errorf("invalid baseline document: severity of '%s' must be high, medium or low, got '%s'", setting, severity)
+		}
+	}
+
+	settings := baseline
+	settings.Severities = nil
+	if encoded, _ := json.Marshal(settings); string(encoded) == "{}" {
+		return nil, fmt.Errorf("invalid baseline document: no settings to check")
+	}
+	return &baseline, nil
+}
+
+// Deviation is a setting whose actual value differs from the baseline
+type Deviation struct {
+	Setting  string      `json:"setting"`
+	Severity string      `json:"severity"`
+	Expected interface{} `json:"expected"`
+	Actual   interface{} `json:"actual"`
+	Message  string      `json:"message"`
+}
+
+// Report is the result of comparing a cluster against a baseline
+type Report struct {
+	ClusterName     string         `json:"cluster_name"`
+	ResourceID      string         `json:"resource_id"`
+	Compliant       bool           `json:"compliant"`
+	SettingsChecked int            `json:"settings_checked"`
+	Summary         map[string]int `json:"summary"`
+	Deviations      []Deviation    `json:"deviations"`
+}
+
+// comparison accumulates the deviations of one check run
+type comparison struct {
+	baseline *Baseline
+	report   *Report
+}
+
+func (c *comparison) check(setting string, expected, actual interface{}, matches bool, message string) {
+	c.report.SettingsChecked++
+	if matches {
+		return
+	}
+
+	severity := defaultSeverities[setting]
+	if override, ok := c.baseline.Severities[setting]; ok {
+		severity = override
+	}
+	c.report.Deviations = append(c.report.Deviations, Deviation{
+		Setting:  setting,
+		Severity: severity,
+		Expected: expected,
+		Actual:   actual,
+		Message:  message,
+	})
+	c.report.Summary[severity]++
+}
+
+// Compare reports every setting of the cluster and its diagnostic settings that deviates from the baseline
+func Compare(baseline *Baseline, cluster *armcontainerservice.ManagedCluster, diagnosticSettings []*armmonitor.DiagnosticSettingsResource) *Report {
+	report := &Report{
+		ClusterName: derefString(cluster.Name),
+		ResourceID:  derefString(cluster.ID),
+		Summary:     map[string]int{SeverityHigh: 0, SeverityMedium: 0, SeverityLow: 0},
+		Deviations:  []Deviation{},
+	}
+	c := &comparison{baseline: baseline, report: report}
+
+	props := cluster.Properties
+	if props == nil {
+		props = &armcontainerservice.ManagedClusterProperties{}
+	}
+
+	if baseline.NetworkPlugin != nil || baseline.NetworkPolicy != nil {
+		var plugin, policy string
+		if props.NetworkProfile != nil {
+			if props.NetworkProfile.NetworkPlugin != nil {
+				plugin = string(*props.NetworkProfile.NetworkPlugin)
+			}
+			if props.NetworkProfile.NetworkPolicy != nil {
+				policy = string(*props.NetworkProfile.NetworkPolicy)
+			}
+		}
+		if baseline.NetworkPlugin != nil {
+			c.check(SettingNetworkPlugin, *baseline.NetworkPlugin, plugin, strings.EqualFold(plugin, *baseline.NetworkPlugin),
+				fmt.Sprintf("Network plugin is %s, expected %s", valueOrNone(plugin), *baseline.NetworkPlugin))
+		}
+		if baseline.NetworkPolicy != nil {
+			c.check(SettingNetworkPolicy, *baseline.NetworkPolicy, policy, strings.EqualFold(policy, *baseline.NetworkPolicy),
+				fmt.Sprintf("Network policy is %s, expected %s", valueOrNone(policy), *baseline.NetworkPolicy))
+		}
+	}
+
+	access := props.APIServerAccessProfile
+	if access == nil {
+		access = &armcontainerservice.ManagedClusterAPIServerAccessProfile{}
+	}
+	if baseline.PrivateCluster != nil {
+		private := derefBool(access.EnablePrivateCluster)
+		c.check(SettingPrivateCluster, *baseline.PrivateCluster, private, private == *baseline.PrivateCluster,
+			fmt.Sprintf("Private cluster is %s, expected %s", enabledString(private), enabledString(*baseline.PrivateCluster)))
+	}
+	if baseline.AuthorizedIPRanges != nil {
+		actual := derefStrings(access.AuthorizedIPRanges)
+		missing, unexpected := diffSets(baseline.AuthorizedIPRanges, actual)
+		c.check(SettingAuthorizedIPRanges, sortedCopy(baseline.AuthorizedIPRanges), sortedCopy(actual), len(missing) == 0 && len(unexpected) == 0,
+			describeSetDiff("authorized IP ranges", missing, unexpected))
+	}
+
+	if baseline.AAD != nil {
+		aad := props.AADProfile
+		if aad == nil {
+			aad = &armcontainerservice.ManagedClusterAADProfile{}
+		}
+		if baseline.AAD.Managed != nil {
+			managed := derefBool(aad.Managed)
+			c.check(SettingAADManaged, *baseline.AAD.Managed, managed, managed == *baseline.AAD.Managed,
+				fmt.Sprintf("Managed Entra ID integration is %s, expected %s", enabledString(managed), enabledString(*baseline.AAD.Managed)))
+		}
+		if baseline.AAD.EnableAzureRBAC != nil {
+			rbac := derefBool(aad.EnableAzureRBAC)
+			c.check(SettingAADAzureRBAC, *baseline.AAD.EnableAzureRBAC, rbac, rbac == *baseline.AAD.EnableAzureRBAC,
+				fmt.Sprintf("Azure RBAC for Kubernetes authorization is %s, expected %s", enabledString(rbac), enabledString(*baseline.AAD.EnableAzureRBAC)))
+		}
+		if baseline.AAD.AdminGroupObjectIDs != nil {
+			actual := derefStrings(aad.AdminGroupObjectIDs)
+			missing, unexpected := diffSets(baseline.AAD.AdminGroupObjectIDs, actual)
+			c.check(SettingAADAdminGroups, sortedCopy(baseline.AAD.AdminGroupObjectIDs), sortedCopy(actual), len(missing) == 0 && len(unexpected) == 0,
+				describeSetDiff("cluster admin groups", missing, unexpected))
+		}
+	}
+
+	if baseline.DisableLocalAccounts != nil {
+		disabled := derefBool(props.DisableLocalAccounts)
+		c.check(SettingDisableLocalAccounts, *baseline.DisableLocalAccounts, disabled, disabled == *baseline.DisableLocalAccounts,
+			fmt.Sprintf("Local accounts disabled is %t, expected %t", disabled, *baseline.DisableLocalAccounts))
+	}
+
+	if baseline.AutoUpgradeChannel != nil {
+		channel := "none"
+		if props.AutoUpgradeProfile != nil && props.AutoUpgradeProfile.UpgradeChannel != nil {
+			channel = string(*props.AutoUpgradeProfile.UpgradeChannel)
+		}
+		c.check(SettingAutoUpgradeChannel, *baseline.AutoUpgradeChannel, channel, strings.EqualFold(channel, *baseline.AutoUpgradeChannel),
+			fmt.Sprintf("Auto-upgrade channel is %s, expected %s", channel, *baseline.AutoUpgradeChannel))
+	}
+
+	if baseline.DefenderEnabled != nil {
+		defender := false
+		if props.SecurityProfile != nil && props.SecurityProfile.Defender != nil && props.SecurityProfile.Defender.SecurityMonitoring != nil {
+			defender = derefBool(props.SecurityProfile.Defender.SecurityMonitoring.Enabled)
+		}
+		c.check(SettingDefender, *baseline.DefenderEnabled, defender, defender == *baseline.DefenderEnabled,
+			fmt.Sprintf("Microsoft Defender for Containers is %s, expected %s", enabledString(defender), enabledString(*baseline.DefenderEnabled)))
+	}
+
+	if baseline.DiagnosticCategories != nil {
+		enabled := enabledLogCategories(diagnosticSettings)
+		var missing []string
+		for _, category := range baseline.DiagnosticCategories {
+			if !enabled[strings.ToLower(category)] && !enabled["alllogs"] {
+				missing = append(missing, category)
+			}
+		}
+		c.check(SettingDiagnosticCategories, sortedCopy(baseline.DiagnosticCategories), sortedKeys(enabled), len(missing) == 0,
+			fmt.Sprintf("Diagnostic log categories not exported by any diagnostic setting: %s", strings.Join(missing, ", ")))
+	}
+
+	report.Compliant = len(report.Deviations) == 0
+	sort.SliceStable(report.Deviations, func(i, j int) bool {
+		return severityRank(report.Deviations[i].Severity) < severityRank(report.Deviations[j].Severity)
+	})
+	return report
+}
+
+// enabledLogCategories returns the lowercased log categories enabled across all diagnostic settings.
+// Category groups are expanded; "alllogs" is kept as a marker that every category is exported.
+func enabledLogCategories(settings []*armmonitor.DiagnosticSettingsResource) map[string]bool {
+	enabled := make(map[string]bool)
+	for _, setting := range settings {
+		if setting == nil || setting.Properties == nil {
+			continue
+		}
+		for _, log := range setting.Properties.Logs {
+			if log == nil || !derefBool(log.Enabled) {
+				continue
+			}
+			if log.Category != nil {
+				enabled[strings.ToLower(*log.Category)] = true
+			}
+			if log.CategoryGroup != nil {
+				group := strings.ToLower(*log.CategoryGroup)
+				if group == "alllogs" {
+					enabled[group] = true
+				}
+				for _, category := range categoryGroups[group] {
+					enabled[category] = true
+				}
+			}
+		}
+	}
+	return enabled
+}
+
+// diffSets returns the expected values that are missing and the actual values that are not expected
+func diffSets(expected, actual []string) (missing, unexpected []string) {
+	for _, value := range expected {
+		if !slices.Contains(actual, value) {
+			missing = append(missing, value)
+		}
+	}
+	for _, value := range actual {
+		if !slices.Contains(expected, value) {
+			unexpected = append(unexpected, value)
+		}
+	}
+	return missing, unexpected
+}
+
+func describeSetDiff(name string, missing, unexpected []string) string {
+	var parts []string
+	if len(missing) > 0 {
+		parts = append(parts, "missing "+strings.Join(missing, ", "))
+	}
+	if len(unexpected) > 0 {
+		parts = append(parts, "unexpected "+strings.Join(unexpected, ", "))
+	}
+	return fmt.Sprintf("The %s differ from the baseline: %s", name, strings.Join(parts, "; "))
+}
+
+func severityRank(severity string) int {
+	switch severity {
+	case SeverityHigh:
+		return 0
+	case SeverityMedium:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func sortedCopy(values []string) []string {
+	sorted := append([]string{}, values...)
+	sort.Strings(sorted)
+	return sorted
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func enabledString(enabled bool) string {
+	if enabled {
+		return "enabled"
+	}
+	return "disabled"
+}
+
+func valueOrNone(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return value
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func derefBool(b *bool) bool {
+	return b != nil && *b
+}
+
+func derefStrings(values []*string) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != nil {
+			result = append(result, *value)
+		}
+	}
+	return result
+}
@@ -0,0 +1,145 @@
+package baseline
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+)
+
+func TestParseBaseline(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		wantErr  string
+	}{
+		{name: "valid", document: `{"network_plugin": "azure", "aad": {"managed": true}, "severities": {"network_plugin": "low"}}`},
+		{name: "unknown setting", document: `{"network_plugn": "azure"}`, wantErr: "unknown field"},
+		{name: "unknown severity setting", document: `{"private_cluster": true, "severities": {"foo": "high"}}`, wantErr: "unknown setting 'foo'"},
+		{name: "invalid severity", document: `{"private_cluster": true, "severities": {"private_cluster": "critical"}}`, wantErr: "must be high, medium or low"},
+		{name: "empty", document: `{}`, wantErr: "no settings to check"},
+		{name: "not json", document: `network_plugin=azure`, wantErr: "invalid baseline document"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseBaseline(tt.document)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func testCluster() *armcontainerservice.ManagedCluster {
+	plugin := armcontainerservice.NetworkPluginKubenet
+	channel := armcontainerservice.UpgradeChannelPatch
+	return &armcontainerservice.ManagedCluster{
+		Name: to.Ptr("aks-1"),
+		ID:   to.Ptr("/subscriptions/sub-1/resourceGroups/rg-1/providers/Microsoft.ContainerService/managedClusters/aks-1"),
+		Properties: &armcontainerservice.ManagedClusterProperties{
+			NetworkProfile: &armcontainerservice.NetworkProfile{NetworkPlugin: &plugin},
+			APIServerAccessProfile: &armcontainerservice.ManagedClusterAPIServerAccessProfile{
+				AuthorizedIPRanges: []*string{to.Ptr("198.51.100.0/24"), to.Ptr("203.0.113.0/24")},
+			},
+			AADProfile: &armcontainerservice.ManagedClusterAADProfile{
+				Managed:         to.Ptr(true),
+				EnableAzureRBAC: to.Ptr(true),
+			},
+			AutoUpgradeProfile: &armcontainerservice.ManagedClusterAutoUpgradeProfile{UpgradeChannel: &channel},
+		},
+	}
+}
+
+func TestCompare(t *testing.T) {
+	baseline, err := ParseBaseline(`{
+		"network_plugin": "azure",
+		"private_cluster": true,
+		"authorized_ip_ranges": ["203.0.113.0/24"],
+		"aad": {"managed": true, "enable_azure_rbac": true},
+		"disable_local_accounts": true,
+		"auto_upgrade_channel": "patch",
+		"diagnostic_categories": ["kube-audit", "kube-apiserver"],
+		"severities": {"disable_local_accounts": "low"}
+	}`)
+	if err != nil {
+		t.Fatalf("ParseBaseline failed: %v", err)
+	}
+
+	settings := []*armmonitor.DiagnosticSettingsResource{{
+		Properties: &armmonitor.DiagnosticSettings{
+			Logs: []*armmonitor.LogSettings{
+				{CategoryGroup: to.Ptr("audit"), Enabled: to.Ptr(true)},
+				{Category: to.Ptr("kube-apiserver"), Enabled: to.Ptr(false)},
+			},
+		},
+	}}
+
+	report := Compare(baseline, testCluster(), settings)
+
+	if report.Compliant {
+		t.Fatal("expected the cluster to deviate from the baseline")
+	}
+	if report.SettingsChecked != 8 {
+		t.Errorf("expected 8 settings checked, got %d", report.SettingsChecked)
+	}
+
+	got := make(map[string]Deviation)
+	var order []string
+	for _, deviation := range report.Deviations {
+		got[deviation.Setting] = deviation
+		order = append(order, deviation.Severity)
+	}
+
+	for _, setting := range []string{SettingNetworkPlugin, SettingPrivateCluster, SettingAuthorizedIPRanges, SettingDisableLocalAccounts, SettingDiagnosticCategories} {
+		if _, ok := got[setting]; !ok {
+			t.Errorf("expected a deviation for %s", setting)
+		}
+	}
+	for _, setting := range []string{SettingAADManaged, SettingAADAzureRBAC, SettingAutoUpgradeChannel} {
+		if _, ok := got[setting]; ok {
+			t.Errorf("unexpected deviation for %s", setting)
+		}
+	}
+
+	if got[SettingDisableLocalAccounts].Severity != SeverityLow {
+		t.Errorf("expected severity override to apply, got %s", got[SettingDisableLocalAccounts].Severity)
+	}
+	if !strings.Contains(got[SettingAuthorizedIPRanges].Message, "unexpected 198.51.100.0/24") {
+		t.Errorf("unexpected authorized IP ranges message: %s", got[SettingAuthorizedIPRanges].Message)
+	}
+	if !strings.Contains(got[SettingDiagnosticCategories].Message, "kube-apiserver") || strings.Contains(got[SettingDiagnosticCategories].Message, "kube-audit,") {
+		t.Errorf("unexpected diagnostic categories message: %s", got[SettingDiagnosticCategories].Message)
+	}
+
+	for i := 1; i < len(order); i++ {
+		if severityRank(order[i-1]) > severityRank(order[i]) {
+			t.Fatalf("deviations are not sorted by severity: %v", order)
+		}
+	}
+	if report.Summary[SeverityHigh] != 2 || report.Summary[SeverityMedium] != 2 || report.Summary[SeverityLow] != 1 {
+		t.Errorf("unexpected summary: %v", report.Summary)
+	}
+}
+
+func TestCompare_AllLogs(t *testing.T) {
+	baseline := &Baseline{DiagnosticCategories: []string{"kube-audit", "cluster-autoscaler"}}
+	settings := []*armmonitor.DiagnosticSettingsResource{{
+		Properties: &armmonitor.DiagnosticSettings{
+			Logs: []*armmonitor.LogSettings{{CategoryGroup: to.Ptr("allLogs"), Enabled: to.Ptr(true)}},
+		},
+	}}
+
+	report := Compare(baseline, testCluster(), settings)
+	if !report.Compliant {
+		t.Fatalf("expected allLogs to cover every category, got %+v", report.Deviations)
+	}
+}
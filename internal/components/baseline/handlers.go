@@ -0,0 +1,64 @@
+package baseline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/tools"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+)
+
+// GetBaselineCheckHandler returns the handler for the cluster_baseline_check tool
+func GetBaselineCheckHandler(azClient *azureclient.AzureClient, cfg *config.ConfigData) tools.ResourceHandler {
+	return tools.ResourceHandlerFunc(func(params map[string]interface{}, _ *config.ConfigData) (string, error) {
+		return HandleBaselineCheck(params, azClient)
+	})
+}
+
+// HandleBaselineCheck compares a cluster against the baseline document in params
+func HandleBaselineCheck(params map[string]interface{}, azClient *azureclient.AzureClient) (string, error) {
+	document, ok := params["baseline"].(string)
+	if !ok || document == "" {
+		return "", fmt.Errorf("missing or invalid baseline parameter")
+	}
+	baseline, err := ParseBaseline(document)
+	if err != nil {
+		return "", err
+	}
+
+	subscriptionID, resourceGroup, clusterName, err := common.ExtractAKSParameters(params)
+	if err != nil {
+		return "", err
+	}
+	if azClient == nil {
+		return "", fmt.Errorf("azure client is required but not provided")
+	}
+
+	ctx := context.Background()
+	cluster, err := azClient.GetAKSCluster(ctx, subscriptionID, resourceGroup, clusterName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get AKS cluster: %w", err)
+	}
+
+	var diagnosticSettings []*armmonitor.DiagnosticSettingsResource
+	if baseline.DiagnosticCategories != nil {
+		clusterResourceID := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ContainerService/managedClusters/%s",
+			subscriptionID, resourceGroup, clusterName)
+		diagnosticSettings, err = azClient.GetDiagnosticSettings(ctx, subscriptionID, clusterResourceID)
+		if err != nil {
+			return "", fmt.Errorf("failed to get diagnostic settings: %w", err)
+		}
+	}
+
+	report := Compare(baseline, cluster, diagnosticSettings)
+
+	result, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal baseline report: %w", err)
+	}
+	return string(result), nil
+}
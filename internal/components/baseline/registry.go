@@ -0,0 +1,43 @@
+package baseline
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// RegisterBaselineCheckTool registers the cluster_baseline_check tool
+func RegisterBaselineCheckTool() mcp.Tool {
+	description := `Compare an AKS cluster against a baseline of golden settings and report every deviation with its severity.
+
+The baseline is a JSON document. Omitted settings are not checked:
+{
+  "network_plugin": "azure",
+  "network_policy": "cilium",
+  "private_cluster": true,
+  "authorized_ip_ranges": ["203.0.113.0/24"],
+  "aad": {"managed": true, "enable_azure_rbac": true, "admin_group_object_ids": ["<group-id>"]},
+  "disable_local_accounts": true,
+  "auto_upgrade_channel": "stable",
+  "defender_enabled": true,
+  "diagnostic_categories": ["kube-audit", "kube-apiserver"],
+  "severities": {"network_policy": "high"}
+}
+
+Authorized IP ranges and admin groups must match exactly. Diagnostic categories must be enabled in at least one diagnostic setting.`
+
+	return mcp.NewTool("cluster_baseline_check",
+		mcp.WithDescription(description),
+		mcp.WithString("baseline",
+			mcp.Required(),
+			mcp.Description("Baseline document as a JSON string"),
+		),
+		mcp.WithString("subscription_id",
+			mcp.Description("Azure Subscription ID (defaults to the active cluster context)"),
+		),
+		mcp.WithString("resource_group",
+			mcp.Description("Azure Resource Group containing the AKS cluster (defaults to the active cluster context)"),
+		),
+		mcp.WithString("cluster_name",
+			mcp.Description("Name of the AKS cluster (defaults to the active cluster context)"),
+		),
+	)
+}
@@ -6,29 +6,47 @@ import (
 	"fmt"
 
 	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/clustercontext"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
 )
 
-// ExtractAKSParameters extracts and validates the common AKS parameters from the params map
+// ExtractAKSParameters extracts and validates the common AKS parameters from the params map.
+// When none of them is passed they are taken from the active cluster context.
 func ExtractAKSParameters(params map[string]interface{}) (subscriptionID, resourceGroup, clusterName string, err error) {
-	subID, ok := params["subscription_id"].(string)
-	if !ok || subID == "" {
+	subID, rg, clusterNameParam := ResolveAKSParameters(params)
+
+	if subID == "" {
 		return "", "", "", fmt.Errorf("missing or invalid subscription_id parameter")
 	}
 
-	rg, ok := params["resource_group"].(string)
-	if !ok || rg == "" {
+	if rg == "" {
 		return "", "", "", fmt.Errorf("missing or invalid resource_group parameter")
 	}
 
-	clusterNameParam, ok := params["cluster_name"].(string)
-	if !ok || clusterNameParam == "" {
+	if clusterNameParam == "" {
 		return "", "", "", fmt.Errorf("missing or invalid cluster_name parameter")
 	}
 
 	return subID, rg, clusterNameParam, nil
 }
 
+// ResolveAKSParameters returns the subscription, resource group and cluster name from the params map
+// without validating them. The active cluster context of the calling session is used only when none of
+// them is passed, so a partial identifier is never completed with values that belong to another cluster.
+func ResolveAKSParameters(params map[string]interface{}) (subscriptionID, resourceGroup, clusterName string) {
+	subscriptionID, _ = params["subscription_id"].(string)
+	resourceGroup, _ = params["resource_group"].(string)
+	clusterName, _ = params["cluster_name"].(string)
+
+	if subscriptionID != "" || resourceGroup != "" || clusterName != "" {
+		return subscriptionID, resourceGroup, clusterName
+	}
+	if active, ok := clustercontext.Default().Session(clustercontext.SessionID(params)).Active(); ok {
+		return active.SubscriptionID, active.ResourceGroup, active.ClusterName
+	}
+	return "", "", ""
+}
+
 // GetClusterDetails gets the details of an AKS cluster
 func GetClusterDetails(ctx context.Context, client *azureclient.AzureClient, subscriptionID, resourceGroup, clusterName string) (*armcontainerservice.ManagedCluster, error) {
 	// Get the cluster from Azure client (which now handles caching internally)
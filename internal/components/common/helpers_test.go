@@ -2,6 +2,8 @@ package common
 
 import (
 	"testing"
+
+	"github.com/Azure/aks-mcp/internal/clustercontext"
 )
 
 // TestExtractAKSParameters tests the parameter extraction function
@@ -97,3 +99,84 @@ func TestExtractAKSParameters(t *testing.T) {
 		})
 	}
 }
+
+// TestExtractAKSParameters_ActiveContextFallback tests that omitted parameters come from the active cluster context
+func TestExtractAKSParameters_ActiveContextFallback(t *testing.T) {
+	registry := clustercontext.Default()
+	defer registry.ClearActive()
+
+	if err := registry.SetActive(clustercontext.ClusterContext{
+		Name:           "prod-weu",
+		SubscriptionID: "ctx-sub",
+		ResourceGroup:  "ctx-rg",
+		ClusterName:    "ctx-cluster",
+	}); err != nil {
+		t.Fatalf("Failed to set active context: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		params      map[string]interface{}
+		wantSubID   string
+		wantRG      string
+		wantCluster string
+		wantErr     bool
+	}{
+		{
+			name:        "all parameters omitted",
+			params:      map[string]interface{}{},
+			wantSubID:   "ctx-sub",
+			wantRG:      "ctx-rg",
+			wantCluster: "ctx-cluster",
+		},
+		{
+			name: "explicit parameters take precedence",
+			params: map[string]interface{}{
+				"subscription_id": "other-sub",
+				"resource_group":  "other-rg",
+				"cluster_name":    "other-cluster",
+			},
+			wantSubID:   "other-sub",
+			wantRG:      "other-rg",
+			wantCluster: "other-cluster",
+		},
+		{
+			name: "same cluster name does not borrow from the context",
+			params: map[string]interface{}{
+				"cluster_name": "ctx-cluster",
+			},
+			wantErr: true,
+		},
+		{
+			name: "partial identifier does not borrow from the context",
+			params: map[string]interface{}{
+				"resource_group": "other-rg",
+				"cluster_name":   "other-cluster",
+			},
+			wantErr: true,
+		},
+		{
+			name: "different cluster name does not borrow from the context",
+			params: map[string]interface{}{
+				"cluster_name": "other-cluster",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subID, rg, clusterName, err := ExtractAKSParameters(tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExtractAKSParameters() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if subID != tt.wantSubID || rg != tt.wantRG || clusterName != tt.wantCluster {
+				t.Errorf("ExtractAKSParameters() = (%s, %s, %s), want (%s, %s, %s)",
+					subID, rg, clusterName, tt.wantSubID, tt.wantRG, tt.wantCluster)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package compute
 
 import (
+	"github.com/Azure/aks-mcp/internal/confirm"
+	"github.com/Azure/aks-mcp/internal/dryrun"
 	"github.com/Azure/aks-mcp/internal/utils"
 	"github.com/mark3labs/mcp-go/mcp"
 )
@@ -31,6 +33,12 @@ func RegisterAzComputeCommand(cmd ComputeCommand) mcp.Tool {
 			mcp.Required(),
 			mcp.Description("Arguments for the `"+cmd.Name+"` command"),
 		),
+		mcp.WithBoolean(dryrun.ParamName,
+			mcp.Description(dryrun.ParamDescription),
+		),
+		mcp.WithString(confirm.ParamName,
+			mcp.Description(confirm.ParamDescription),
+		),
 	)
 }
 
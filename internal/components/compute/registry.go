@@ -12,16 +12,13 @@ func RegisterAKSVMSSInfoTool() mcp.Tool {
 		"get_aks_vmss_info",
 		mcp.WithDescription("Get detailed VMSS configuration for a specific node pool or all node pools in the AKS cluster (provides low-level VMSS settings not available in az aks nodepool show). Leave node_pool_name empty to get info for all node pools."),
 		mcp.WithString("subscription_id",
-			mcp.Description("Azure Subscription ID"),
-			mcp.Required(),
+			mcp.Description("Azure Subscription ID (defaults to the active cluster context)"),
 		),
 		mcp.WithString("resource_group",
-			mcp.Description("Azure Resource Group containing the AKS cluster"),
-			mcp.Required(),
+			mcp.Description("Azure Resource Group containing the AKS cluster (defaults to the active cluster context)"),
 		),
 		mcp.WithString("cluster_name",
-			mcp.Description("Name of the AKS cluster"),
-			mcp.Required(),
+			mcp.Description("Name of the AKS cluster (defaults to the active cluster context)"),
 		),
 		mcp.WithString("node_pool_name",
 			mcp.Description("Name of the node pool to get VMSS information for. Leave empty to get info for all node pools."),
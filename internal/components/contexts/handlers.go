@@ -0,0 +1,153 @@
+// Package contexts provides tools for managing the active AKS cluster context.
+package contexts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/clustercontext"
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/tools"
+)
+
+// =============================================================================
+// Cluster Context Handlers
+// =============================================================================
+
+// GetSetClusterContextHandler returns handler for the set_cluster_context tool
+func GetSetClusterContextHandler(azClient *azureclient.AzureClient, cfg *config.ConfigData) tools.ResourceHandler {
+	return tools.ResourceHandlerFunc(func(params map[string]interface{}, _ *config.ConfigData) (string, error) {
+		return HandleSetClusterContext(params, azClient, cfg.ClusterContexts.Session(clustercontext.SessionID(params)))
+	})
+}
+
+// GetGetClusterContextHandler returns handler for the get_cluster_context tool
+func GetGetClusterContextHandler(cfg *config.ConfigData) tools.ResourceHandler {
+	return tools.ResourceHandlerFunc(func(params map[string]interface{}, _ *config.ConfigData) (string, error) {
+		return HandleGetClusterContext(cfg.ClusterContexts.Session(clustercontext.SessionID(params)))
+	})
+}
+
+// GetListClusterContextsHandler returns handler for the list_cluster_contexts tool
+func GetListClusterContextsHandler(cfg *config.ConfigData) tools.ResourceHandler {
+	return tools.ResourceHandlerFunc(func(params map[string]interface{}, _ *config.ConfigData) (string, error) {
+		return HandleListClusterContexts(cfg.ClusterContexts.Session(clustercontext.SessionID(params)))
+	})
+}
+
+// =============================================================================
+// Handler Implementation Functions
+// =============================================================================
+
+// HandleSetClusterContext implements the set_cluster_context functionality. The active context and
+// save_as aliases only apply to the session that set them.
+func HandleSetClusterContext(params map[string]interface{}, azClient *azureclient.AzureClient, registry *clustercontext.Session) (string, error) {
+	if clearActive, ok := params["clear"].(bool); ok && clearActive {
+		registry.ClearActive()
+		return marshalResult(map[string]interface{}{
+			"message": "Active cluster context cleared",
+		})
+	}
+
+	clusterCtx, err := resolveClusterContext(params, registry)
+	if err != nil {
+		return "", err
+	}
+
+	// Make sure the cluster exists before making it the active context
+	if azClient != nil {
+		if _, err := azClient.GetAKSCluster(context.Background(), clusterCtx.SubscriptionID, clusterCtx.ResourceGroup, clusterCtx.ClusterName); err != nil {
+			return "", fmt.Errorf("failed to verify cluster %s in resource group %s: %v", clusterCtx.ClusterName, clusterCtx.ResourceGroup, err)
+		}
+	}
+
+	if saveAs, ok := params["save_as"].(string); ok && saveAs != "" {
+		clusterCtx.Name = saveAs
+		if err := registry.AddAlias(clusterCtx); err != nil {
+			return "", err
+		}
+	}
+
+	if err := registry.SetActive(clusterCtx); err != nil {
+		return "", fmt.Errorf("invalid cluster context: %v", err)
+	}
+
+	return marshalResult(map[string]interface{}{
+		"message":        fmt.Sprintf("Active cluster context set to %s", describeContext(clusterCtx)),
+		"active_context": clusterCtx,
+	})
+}
+
+// HandleGetClusterContext implements the get_cluster_context functionality
+func HandleGetClusterContext(registry *clustercontext.Session) (string, error) {
+	active, ok := registry.Active()
+	if !ok {
+		return marshalResult(map[string]interface{}{
+			"message":        "No active cluster context. Use set_cluster_context to select a cluster.",
+			"active_context": nil,
+		})
+	}
+
+	return marshalResult(map[string]interface{}{
+		"active_context": active,
+	})
+}
+
+// HandleListClusterContexts implements the list_cluster_contexts functionality
+func HandleListClusterContexts(registry *clustercontext.Session) (string, error) {
+	result := map[string]interface{}{
+		"contexts":       registry.ListAliases(),
+		"active_context": nil,
+	}
+	if active, ok := registry.Active(); ok {
+		result["active_context"] = active
+	}
+
+	return marshalResult(result)
+}
+
+// resolveClusterContext builds the requested cluster context from an alias or explicit parameters
+func resolveClusterContext(params map[string]interface{}, registry *clustercontext.Session) (clustercontext.ClusterContext, error) {
+	if name, ok := params["context_name"].(string); ok && name != "" {
+		clusterCtx, found := registry.GetAlias(name)
+		if !found {
+			return clustercontext.ClusterContext{}, fmt.Errorf("unknown cluster context '%s'. Use list_cluster_contexts to see configured contexts", name)
+		}
+		return clusterCtx, nil
+	}
+
+	subscriptionID, _ := params["subscription_id"].(string)
+	resourceGroup, _ := params["resource_group"].(string)
+	clusterName, _ := params["cluster_name"].(string)
+
+	clusterCtx := clustercontext.ClusterContext{
+		SubscriptionID: subscriptionID,
+		ResourceGroup:  resourceGroup,
+		ClusterName:    clusterName,
+	}
+	if err := clusterCtx.Validate(); err != nil {
+		return clustercontext.ClusterContext{}, fmt.Errorf("either context_name or subscription_id, resource_group and cluster_name are required: %v", err)
+	}
+
+	return clusterCtx, nil
+}
+
+// describeContext returns a human readable description of a cluster context
+func describeContext(clusterCtx clustercontext.ClusterContext) string {
+	desc := fmt.Sprintf("cluster %s in resource group %s (subscription %s)", clusterCtx.ClusterName, clusterCtx.ResourceGroup, clusterCtx.SubscriptionID)
+	if clusterCtx.Name != "" {
+		desc = fmt.Sprintf("'%s': %s", clusterCtx.Name, desc)
+	}
+	return desc
+}
+
+// marshalResult converts a result map to indented JSON
+func marshalResult(result map[string]interface{}) (string, error) {
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal result to JSON: %v", err)
+	}
+	return string(resultJSON), nil
+}
@@ -0,0 +1,172 @@
+package contexts
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-mcp/internal/clustercontext"
+)
+
+func newTestRegistry(t *testing.T) *clustercontext.Registry {
+	t.Helper()
+	registry := clustercontext.NewRegistry()
+	if err := registry.AddAlias(clustercontext.ClusterContext{
+		Name:           "prod-weu",
+		SubscriptionID: "sub-1",
+		ResourceGroup:  "prod-rg",
+		ClusterName:    "prod-aks",
+	}); err != nil {
+		t.Fatalf("Failed to add alias: %v", err)
+	}
+	return registry
+}
+
+func TestHandleSetClusterContext_Alias(t *testing.T) {
+	registry := newTestRegistry(t).Session("session-1")
+
+	result, err := HandleSetClusterContext(map[string]interface{}{"context_name": "prod-weu"}, nil, registry)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !strings.Contains(result, "prod-aks") {
+		t.Errorf("Expected result to mention the cluster, got: %s", result)
+	}
+
+	active, ok := registry.Active()
+	if !ok || active.Name != "prod-weu" {
+		t.Errorf("Expected prod-weu to be active, got %+v", active)
+	}
+}
+
+func TestHandleSetClusterContext_ExplicitWithSaveAs(t *testing.T) {
+	registry := newTestRegistry(t).Session("session-1")
+
+	params := map[string]interface{}{
+		"subscription_id": "sub-2",
+		"resource_group":  "dev-rg",
+		"cluster_name":    "dev-aks",
+		"save_as":         "dev",
+	}
+	if _, err := HandleSetClusterContext(params, nil, registry); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if _, ok := registry.GetAlias("dev"); !ok {
+		t.Error("Expected alias dev to be registered")
+	}
+	active, _ := registry.Active()
+	if active.ClusterName != "dev-aks" {
+		t.Errorf("Expected dev-aks to be active, got %+v", active)
+	}
+}
+
+func TestHandleSetClusterContext_Errors(t *testing.T) {
+	registry := newTestRegistry(t).Session("session-1")
+
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+	}{
+		{"unknown alias", map[string]interface{}{"context_name": "missing"}},
+		{"no parameters", map[string]interface{}{}},
+		{"missing cluster name", map[string]interface{}{"subscription_id": "s", "resource_group": "rg"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := HandleSetClusterContext(tt.params, nil, registry); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+
+	if _, ok := registry.Active(); ok {
+		t.Error("Expected no active context after failed calls")
+	}
+}
+
+func TestHandleSetClusterContext_Clear(t *testing.T) {
+	registry := newTestRegistry(t).Session("session-1")
+	if _, err := HandleSetClusterContext(map[string]interface{}{"context_name": "prod-weu"}, nil, registry); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if _, err := HandleSetClusterContext(map[string]interface{}{"clear": true}, nil, registry); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if _, ok := registry.Active(); ok {
+		t.Error("Expected active context to be cleared")
+	}
+}
+
+func TestHandleListAndGetClusterContexts(t *testing.T) {
+	registry := newTestRegistry(t).Session("session-1")
+
+	result, err := HandleGetClusterContext(registry)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !strings.Contains(result, "No active cluster context") {
+		t.Errorf("Expected message about missing context, got: %s", result)
+	}
+
+	if _, err := HandleSetClusterContext(map[string]interface{}{"context_name": "prod-weu"}, nil, registry); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	result, err = HandleListClusterContexts(registry)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	var listed struct {
+		Contexts      []clustercontext.ClusterContext `json:"contexts"`
+		ActiveContext *clustercontext.ClusterContext  `json:"active_context"`
+	}
+	if err := json.Unmarshal([]byte(result), &listed); err != nil {
+		t.Fatalf("Failed to parse result: %v", err)
+	}
+	if len(listed.Contexts) != 1 || listed.Contexts[0].Name != "prod-weu" {
+		t.Errorf("Unexpected contexts: %+v", listed.Contexts)
+	}
+	if listed.ActiveContext == nil || listed.ActiveContext.ClusterName != "prod-aks" {
+		t.Errorf("Unexpected active context: %+v", listed.ActiveContext)
+	}
+}
+
+func TestHandleSetClusterContext_SessionsAreIsolated(t *testing.T) {
+	registry := newTestRegistry(t)
+	if err := registry.SetActive(clustercontext.ClusterContext{Name: "prod-weu", SubscriptionID: "sub-1", ResourceGroup: "prod-rg", ClusterName: "prod-aks"}); err != nil {
+		t.Fatalf("SetActive returned error: %v", err)
+	}
+	first, second := registry.Session("session-1"), registry.Session("session-2")
+
+	params := map[string]interface{}{"subscription_id": "sub-2", "resource_group": "dev-rg", "cluster_name": "dev-aks", "save_as": "dev"}
+	if _, err := HandleSetClusterContext(params, nil, first); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if active, _ := first.Active(); active.ClusterName != "dev-aks" {
+		t.Errorf("Expected dev-aks to be active in the first session, got %+v", active)
+	}
+	// The other session keeps the default context and does not see the saved alias
+	if active, _ := second.Active(); active.ClusterName != "prod-aks" {
+		t.Errorf("Expected the second session to keep the default context, got %+v", active)
+	}
+	if _, ok := second.GetAlias("dev"); ok {
+		t.Error("Expected the alias saved by the first session not to be visible to the second")
+	}
+
+	if _, err := HandleSetClusterContext(map[string]interface{}{"clear": true}, nil, second); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if _, ok := second.Active(); ok {
+		t.Error("Expected the second session to have no active context after clearing it")
+	}
+
+	registry.RemoveSession("session-1")
+	if active, _ := first.Active(); active.ClusterName != "prod-aks" {
+		t.Errorf("Expected a removed session to fall back to the default context, got %+v", active)
+	}
+}
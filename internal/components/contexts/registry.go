@@ -0,0 +1,50 @@
+package contexts
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Cluster context tool registrations
+
+// RegisterSetClusterContextTool registers the set_cluster_context tool
+func RegisterSetClusterContextTool() mcp.Tool {
+	return mcp.NewTool(
+		"set_cluster_context",
+		mcp.WithDescription("Set the active AKS cluster for this session. Tools that take subscription_id, resource_group and cluster_name fall back to the active cluster when those arguments are omitted. "+
+			"Select a configured alias with context_name, or pass subscription_id, resource_group and cluster_name directly (optionally saving them under save_as)."),
+		mcp.WithString("context_name",
+			mcp.Description("Name of a configured cluster context alias (see list_cluster_contexts)"),
+		),
+		mcp.WithString("subscription_id",
+			mcp.Description("Azure Subscription ID (when not using context_name)"),
+		),
+		mcp.WithString("resource_group",
+			mcp.Description("Azure Resource Group containing the AKS cluster (when not using context_name)"),
+		),
+		mcp.WithString("cluster_name",
+			mcp.Description("Name of the AKS cluster (when not using context_name)"),
+		),
+		mcp.WithString("save_as",
+			mcp.Description("Optional alias to register the explicitly provided cluster under for the rest of the session"),
+		),
+		mcp.WithBoolean("clear",
+			mcp.Description("Clear the active cluster context instead of setting one"),
+		),
+	)
+}
+
+// RegisterGetClusterContextTool registers the get_cluster_context tool
+func RegisterGetClusterContextTool() mcp.Tool {
+	return mcp.NewTool(
+		"get_cluster_context",
+		mcp.WithDescription("Get the active AKS cluster context for this session"),
+	)
+}
+
+// RegisterListClusterContextsTool registers the list_cluster_contexts tool
+func RegisterListClusterContextsTool() mcp.Tool {
+	return mcp.NewTool(
+		"list_cluster_contexts",
+		mcp.WithDescription("List the configured AKS cluster context aliases and the active cluster context"),
+	)
+}
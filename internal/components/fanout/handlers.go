@@ -0,0 +1,300 @@
+// Package fanout runs read-only tools across many AKS clusters.
+package fanout
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/tools"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+)
+
+// ClusterResult holds the outcome of running the tool against one cluster
+type ClusterResult struct {
+	Result interface{} `json:"result,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// FanOutResult is the merged result of a fan-out, keyed by cluster resource ID
+type FanOutResult struct {
+	Tool         string                    `json:"tool"`
+	ClusterCount int                       `json:"cluster_count"`
+	Succeeded    int                       `json:"succeeded"`
+	Failed       int                       `json:"failed"`
+	Results      map[string]*ClusterResult `json:"results"`
+}
+
+// GetFanOutHandler returns handler for the fan_out tool
+func GetFanOutHandler(azClient *azureclient.AzureClient, targets map[string]Target, cfg *config.ConfigData) tools.ResourceHandler {
+	return tools.ResourceHandlerFunc(func(params map[string]interface{}, _ *config.ConfigData) (string, error) {
+		return HandleFanOut(params, azClient, targets, cfg)
+	})
+}
+
+// HandleFanOut implements the fan_out functionality
+func HandleFanOut(params map[string]interface{}, azClient *azureclient.AzureClient, targets map[string]Target, cfg *config.ConfigData) (string, error) {
+	toolName, ok := params["tool"].(string)
+	if !ok || toolName == "" {
+		return "", fmt.Errorf("missing or invalid tool parameter")
+	}
+
+	target, ok := targets[toolName]
+	if !ok {
+		return "", fmt.Errorf("tool '%s' cannot be fanned out. Supported tools: %s", toolName, strings.Join(TargetNames(targets), ", "))
+	}
+
+	toolArgs, err := parseToolArguments(params)
+	if err != nil {
+		return "", err
+	}
+
+	if target.IsReadOnly != nil && !target.IsReadOnly(toolArgs) {
+		return "", fmt.Errorf("fan_out only supports read-only, cluster-scoped calls; the requested %s call is not one", toolName)
+	}
+
+	clusters, err := selectClusters(context.Background(), params, azClient)
+	if err != nil {
+		return "", err
+	}
+	if len(clusters) == 0 {
+		return "", fmt.Errorf("no clusters matched the selection")
+	}
+	if len(clusters) > MaxClusters {
+		return "", fmt.Errorf("selection matched %d clusters, which exceeds the limit of %d. Narrow it down with resource_group or tag_selector", len(clusters), MaxClusters)
+	}
+
+	result := RunFanOut(toolName, target, toolArgs, clusters, getMaxConcurrency(params), cfg)
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal fan-out result to JSON: %v", err)
+	}
+	return string(resultJSON), nil
+}
+
+// RunFanOut runs the target against every cluster with bounded concurrency
+func RunFanOut(toolName string, target Target, toolArgs map[string]interface{}, clusters []ClusterRef, maxConcurrency int, cfg *config.ConfigData) *FanOutResult {
+	result := &FanOutResult{
+		Tool:         toolName,
+		ClusterCount: len(clusters),
+		Results:      make(map[string]*ClusterResult, len(clusters)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, maxConcurrency)
+
+	for _, cluster := range clusters {
+		wg.Add(1)
+		go func(cluster ClusterRef) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			clusterResult := runForCluster(target, toolArgs, cluster, cfg)
+
+			mu.Lock()
+			defer mu.Unlock()
+			result.Results[cluster.ResourceID()] = clusterResult
+			if clusterResult.Error != "" {
+				result.Failed++
+			} else {
+				result.Succeeded++
+			}
+		}(cluster)
+	}
+
+	wg.Wait()
+	return result
+}
+
+// runForCluster executes the target for a single cluster, recovering from handler panics
+func runForCluster(target Target, toolArgs map[string]interface{}, cluster ClusterRef, cfg *config.ConfigData) (clusterResult *ClusterResult) {
+	defer func() {
+		if r := recover(); r != nil {
+			clusterResult = &ClusterResult{Error: fmt.Sprintf("tool execution panicked: %v", r)}
+		}
+	}()
+
+	// Each cluster gets its own copy of the arguments
+	params := make(map[string]interface{}, len(toolArgs)+3)
+	for key, value := range toolArgs {
+		params[key] = value
+	}
+	if target.ScopeParams != nil {
+		target.ScopeParams(cluster, params)
+	}
+
+	output, err := target.Handler.Handle(params, cfg)
+	if err != nil {
+		return &ClusterResult{Error: err.Error()}
+	}
+
+	// Embed JSON output as structured data so the merged result stays machine readable
+	var parsed interface{}
+	if json.Unmarshal([]byte(output), &parsed) == nil {
+		return &ClusterResult{Result: parsed}
+	}
+	return &ClusterResult{Result: output}
+}
+
+// TargetNames returns the sorted names of the fan-out targets
+func TargetNames(targets map[string]Target) []string {
+	names := make([]string, 0, len(targets))
+	for name := range targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parseToolArguments parses the JSON arguments passed to the fanned-out tool
+func parseToolArguments(params map[string]interface{}) (map[string]interface{}, error) {
+	toolArgs := make(map[string]interface{})
+
+	argsStr, ok := params["arguments"].(string)
+	if !ok || strings.TrimSpace(argsStr) == "" {
+		return toolArgs, nil
+	}
+
+	if err := json.Unmarshal([]byte(argsStr), &toolArgs); err != nil {
+		return nil, fmt.Errorf("failed to parse arguments JSON: %v", err)
+	}
+	return toolArgs, nil
+}
+
+// getMaxConcurrency extracts and bounds the max_concurrency parameter
+func getMaxConcurrency(params map[string]interface{}) int {
+	value, ok := params["max_concurrency"].(float64)
+	if !ok || value < 1 {
+		return DefaultMaxConcurrency
+	}
+	if value > MaxMaxConcurrency {
+		return MaxMaxConcurrency
+	}
+	return int(value)
+}
+
+// selectClusters resolves the clusters to run against from an explicit list or a subscription-wide selection
+func selectClusters(ctx context.Context, params map[string]interface{}, azClient *azureclient.AzureClient) ([]ClusterRef, error) {
+	if clusterList, ok := params["clusters"].(string); ok && strings.TrimSpace(clusterList) != "" {
+		return ParseClusterList(clusterList)
+	}
+
+	subscriptionID, ok := params["subscription_id"].(string)
+	if !ok || subscriptionID == "" {
+		return nil, fmt.Errorf("either clusters or subscription_id is required")
+	}
+	resourceGroup, _ := params["resource_group"].(string)
+
+	selector, err := ParseTagSelector(stringParam(params, "tag_selector"))
+	if err != nil {
+		return nil, err
+	}
+
+	if azClient == nil {
+		return nil, fmt.Errorf("azure client is required but not provided")
+	}
+
+	managedClusters, err := azClient.ListAKSClusters(ctx, subscriptionID, resourceGroup)
+	if err != nil {
+		return nil, err
+	}
+
+	var clusters []ClusterRef
+	for _, managedCluster := range managedClusters {
+		if managedCluster == nil || managedCluster.ID == nil || !selector.Matches(managedCluster) {
+			continue
+		}
+		subID, rg, name, err := azureclient.ParseAKSResourceID(*managedCluster.ID)
+		if err != nil {
+			continue
+		}
+		clusters = append(clusters, ClusterRef{SubscriptionID: subID, ResourceGroup: rg, ClusterName: name})
+	}
+
+	return clusters, nil
+}
+
+// ParseClusterList parses a comma-separated list of AKS cluster resource IDs
+func ParseClusterList(clusterList string) ([]ClusterRef, error) {
+	seen := make(map[string]bool)
+	var clusters []ClusterRef
+	for _, resourceID := range strings.Split(clusterList, ",") {
+		resourceID = strings.TrimSpace(resourceID)
+		if resourceID == "" {
+			continue
+		}
+
+		subID, rg, name, err := azureclient.ParseAKSResourceID(resourceID)
+		if err != nil {
+			return nil, err
+		}
+
+		cluster := ClusterRef{SubscriptionID: subID, ResourceGroup: rg, ClusterName: name}
+		key := strings.ToLower(cluster.ResourceID())
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		clusters = append(clusters, cluster)
+	}
+	return clusters, nil
+}
+
+// TagSelector filters clusters by their Azure tags
+type TagSelector struct {
+	// Required maps tag keys to required values; an empty value only requires the key to exist
+	Required map[string]string
+}
+
+// ParseTagSelector parses selectors of the form "key=value,key2=value2,key3"
+func ParseTagSelector(selector string) (*TagSelector, error) {
+	tagSelector := &TagSelector{Required: make(map[string]string)}
+	for _, term := range strings.Split(selector, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(term, "=")
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return nil, fmt.Errorf("invalid tag selector term '%s'", term)
+		}
+		tagSelector.Required[key] = strings.TrimSpace(value)
+	}
+	return tagSelector, nil
+}
+
+// Matches reports whether the cluster satisfies every tag requirement.
+// Tag keys are matched case-insensitively, as Azure treats them that way.
+func (s *TagSelector) Matches(cluster *armcontainerservice.ManagedCluster) bool {
+	for key, value := range s.Required {
+		found := false
+		for tagKey, tagValue := range cluster.Tags {
+			if !strings.EqualFold(tagKey, key) {
+				continue
+			}
+			if value == "" || (tagValue != nil && *tagValue == value) {
+				found = true
+			}
+			break
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// stringParam returns a string parameter or an empty string
+func stringParam(params map[string]interface{}, key string) string {
+	value, _ := params[key].(string)
+	return value
+}
@@ -0,0 +1,259 @@
+package fanout
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/tools"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestParseTagSelector(t *testing.T) {
+	selector, err := ParseTagSelector("env=prod, team = payments ,critical")
+	if err != nil {
+		t.Fatalf("ParseTagSelector returned error: %v", err)
+	}
+
+	expected := map[string]string{"env": "prod", "team": "payments", "critical": ""}
+	if len(selector.Required) != len(expected) {
+		t.Fatalf("Expected %d terms, got %+v", len(expected), selector.Required)
+	}
+	for key, value := range expected {
+		if selector.Required[key] != value {
+			t.Errorf("Expected %s=%q, got %q", key, value, selector.Required[key])
+		}
+	}
+
+	if _, err := ParseTagSelector("=prod"); err == nil {
+		t.Error("Expected error for a term without a key")
+	}
+}
+
+func TestTagSelector_Matches(t *testing.T) {
+	selector, _ := ParseTagSelector("env=prod,critical")
+
+	tests := []struct {
+		name string
+		tags map[string]*string
+		want bool
+	}{
+		{"all match", map[string]*string{"env": strPtr("prod"), "critical": strPtr("yes")}, true},
+		{"key case-insensitive", map[string]*string{"Env": strPtr("prod"), "CRITICAL": nil}, true},
+		{"wrong value", map[string]*string{"env": strPtr("dev"), "critical": strPtr("yes")}, false},
+		{"missing key", map[string]*string{"env": strPtr("prod")}, false},
+		{"no tags", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := &armcontainerservice.ManagedCluster{Tags: tt.tags}
+			if got := selector.Matches(cluster); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	empty, _ := ParseTagSelector("")
+	if !empty.Matches(&armcontainerservice.ManagedCluster{}) {
+		t.Error("Expected an empty selector to match every cluster")
+	}
+}
+
+func TestParseClusterList(t *testing.T) {
+	id1 := "/subscriptions/sub-1/resourceGroups/rg-1/providers/Microsoft.ContainerService/managedClusters/aks-1"
+	id2 := "/subscriptions/sub-2/resourceGroups/rg-2/providers/Microsoft.ContainerService/managedClusters/aks-2"
+
+	clusters, err := ParseClusterList(id1 + ", " + id2 + "," + strings.ToUpper(id1) + ",")
+	if err != nil {
+		t.Fatalf("ParseClusterList returned error: %v", err)
+	}
+	if len(clusters) != 2 {
+		t.Fatalf("Expected 2 unique clusters, got %+v", clusters)
+	}
+	if clusters[0] != (ClusterRef{SubscriptionID: "sub-1", ResourceGroup: "rg-1", ClusterName: "aks-1"}) {
+		t.Errorf("Unexpected first cluster: %+v", clusters[0])
+	}
+	if clusters[1].ResourceID() != id2 {
+		t.Errorf("Expected resource ID %s, got %s", id2, clusters[1].ResourceID())
+	}
+
+	if _, err := ParseClusterList("not-a-resource-id"); err == nil {
+		t.Error("Expected error for an invalid resource ID")
+	}
+}
+
+func TestGetMaxConcurrency(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+		want   int
+	}{
+		{"default", map[string]interface{}{}, DefaultMaxConcurrency},
+		{"explicit", map[string]interface{}{"max_concurrency": float64(3)}, 3},
+		{"too low", map[string]interface{}{"max_concurrency": float64(0)}, DefaultMaxConcurrency},
+		{"too high", map[string]interface{}{"max_concurrency": float64(500)}, MaxMaxConcurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getMaxConcurrency(tt.params); got != tt.want {
+				t.Errorf("getMaxConcurrency() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunFanOut_MergesResultsAndErrors(t *testing.T) {
+	var inFlight, maxInFlight int32
+	target := Target{
+		ScopeParams: AKSParamsScope,
+		Handler: tools.ResourceHandlerFunc(func(params map[string]interface{}, _ *config.ConfigData) (string, error) {
+			current := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				observed := atomic.LoadInt32(&maxInFlight)
+				if current <= observed || atomic.CompareAndSwapInt32(&maxInFlight, observed, current) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+
+			clusterName := params["cluster_name"].(string)
+			if clusterName == "broken" {
+				return "", fmt.Errorf("cluster unreachable")
+			}
+			if clusterName == "plain" {
+				return "not json", nil
+			}
+			return fmt.Sprintf(`{"name":%q,"verbose":%v}`, clusterName, params["verbose"]), nil
+		}),
+	}
+
+	clusters := []ClusterRef{
+		{SubscriptionID: "s", ResourceGroup: "rg", ClusterName: "a"},
+		{SubscriptionID: "s", ResourceGroup: "rg", ClusterName: "b"},
+		{SubscriptionID: "s", ResourceGroup: "rg", ClusterName: "c"},
+		{SubscriptionID: "s", ResourceGroup: "rg", ClusterName: "plain"},
+		{SubscriptionID: "s", ResourceGroup: "rg", ClusterName: "broken"},
+	}
+	toolArgs := map[string]interface{}{"verbose": true}
+
+	result := RunFanOut("cluster_summary", target, toolArgs, clusters, 2, config.NewConfig())
+
+	if result.ClusterCount != 5 || result.Succeeded != 4 || result.Failed != 1 {
+		t.Errorf("Unexpected counts: %+v", result)
+	}
+	if maxInFlight > 2 {
+		t.Errorf("Expected at most 2 concurrent calls, observed %d", maxInFlight)
+	}
+	if _, ok := toolArgs["cluster_name"]; ok {
+		t.Error("Expected the shared arguments not to be modified")
+	}
+
+	broken := result.Results[clusters[4].ResourceID()]
+	if broken == nil || broken.Error != "cluster unreachable" {
+		t.Errorf("Expected error result for broken cluster, got %+v", broken)
+	}
+
+	structured, ok := result.Results[clusters[0].ResourceID()].Result.(map[string]interface{})
+	if !ok || structured["name"] != "a" || structured["verbose"] != true {
+		t.Errorf("Expected structured result for cluster a, got %+v", result.Results[clusters[0].ResourceID()])
+	}
+
+	if plain := result.Results[clusters[3].ResourceID()].Result; plain != "not json" {
+		t.Errorf("Expected raw string result for non-JSON output, got %v", plain)
+	}
+}
+
+func TestRunFanOut_RecoversFromPanics(t *testing.T) {
+	target := Target{
+		Handler: tools.ResourceHandlerFunc(func(map[string]interface{}, *config.ConfigData) (string, error) {
+			panic("boom")
+		}),
+	}
+	clusters := []ClusterRef{{SubscriptionID: "s", ResourceGroup: "rg", ClusterName: "a"}}
+
+	result := RunFanOut("x", target, map[string]interface{}{}, clusters, 1, config.NewConfig())
+	if result.Failed != 1 || !strings.Contains(result.Results[clusters[0].ResourceID()].Error, "boom") {
+		t.Errorf("Expected panic to be reported as a failure, got %+v", result)
+	}
+}
+
+func TestHandleFanOut(t *testing.T) {
+	called := false
+	targets := map[string]Target{
+		"az_aks_operations": {
+			ScopeParams: AKSParamsScope,
+			IsReadOnly: func(params map[string]interface{}) bool {
+				return params["operation"] == "show"
+			},
+			Handler: tools.ResourceHandlerFunc(func(params map[string]interface{}, _ *config.ConfigData) (string, error) {
+				called = true
+				return fmt.Sprintf(`{"cluster":%q}`, params["cluster_name"]), nil
+			}),
+		},
+	}
+	cfg := config.NewConfig()
+	clusterID := "/subscriptions/sub-1/resourceGroups/rg-1/providers/Microsoft.ContainerService/managedClusters/aks-1"
+
+	t.Run("unknown tool", func(t *testing.T) {
+		_, err := HandleFanOut(map[string]interface{}{"tool": "kubectl", "clusters": clusterID}, nil, targets, cfg)
+		if err == nil || !strings.Contains(err.Error(), "cannot be fanned out") {
+			t.Errorf("Expected unsupported tool error, got %v", err)
+		}
+	})
+
+	t.Run("mutating call rejected", func(t *testing.T) {
+		_, err := HandleFanOut(map[string]interface{}{
+			"tool":      "az_aks_operations",
+			"arguments": `{"operation":"scale"}`,
+			"clusters":  clusterID,
+		}, nil, targets, cfg)
+		if err == nil || !strings.Contains(err.Error(), "read-only") {
+			t.Errorf("Expected read-only error, got %v", err)
+		}
+		if called {
+			t.Error("Expected handler not to be called for a mutating operation")
+		}
+	})
+
+	t.Run("missing selection", func(t *testing.T) {
+		_, err := HandleFanOut(map[string]interface{}{
+			"tool":      "az_aks_operations",
+			"arguments": `{"operation":"show"}`,
+		}, nil, targets, cfg)
+		if err == nil {
+			t.Error("Expected error when neither clusters nor subscription_id is given")
+		}
+	})
+
+	t.Run("read-only call", func(t *testing.T) {
+		output, err := HandleFanOut(map[string]interface{}{
+			"tool":      "az_aks_operations",
+			"arguments": `{"operation":"show"}`,
+			"clusters":  clusterID,
+		}, nil, targets, cfg)
+		if err != nil {
+			t.Fatalf("HandleFanOut returned error: %v", err)
+		}
+
+		var result FanOutResult
+		if err := json.Unmarshal([]byte(output), &result); err != nil {
+			t.Fatalf("Failed to parse result: %v", err)
+		}
+		if result.Succeeded != 1 {
+			t.Fatalf("Expected one successful cluster, got %+v", result)
+		}
+		cluster := result.Results[clusterID].Result.(map[string]interface{})["cluster"]
+		if cluster != "aks-1" {
+			t.Errorf("Expected the call to be scoped to aks-1, got %v", cluster)
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package fanout
+
+import (
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Fan-out limits
+const (
+	DefaultMaxConcurrency = 5
+	MaxMaxConcurrency     = 20
+	MaxClusters           = 100
+)
+
+// RegisterFanOutTool registers the fan_out tool
+func RegisterFanOutTool(targetNames []string) mcp.Tool {
+	description := `Run a read-only tool across many AKS clusters and return a merged result keyed by cluster resource ID.
+
+Clusters are selected by exactly one of:
+- clusters: comma-separated list of AKS cluster resource IDs
+- subscription_id (+ optional resource_group, tag_selector): all clusters in the subscription, optionally filtered by tags
+
+Per-cluster failures are reported next to the successful results and do not fail the whole call.
+
+Supported tools: ` + strings.Join(targetNames, ", ") + `
+
+Examples:
+- Kubernetes versions of all production clusters: tool="cluster_summary", subscription_id="<subscription-id>", tag_selector="env=prod"
+- Diagnostic settings of two clusters: tool="az_monitoring", clusters="/subscriptions/.../managedClusters/a,/subscriptions/.../managedClusters/b", arguments="{\"operation\":\"diagnostics\",\"parameters\":\"{}\"}"
+- Node health detectors: tool="run_detectors_by_category", subscription_id="<subscription-id>", arguments="{\"category\":\"Node Health\",\"start_time\":\"<start-time>\",\"end_time\":\"<end-time>\"}"`
+
+	return mcp.NewTool("fan_out",
+		mcp.WithDescription(description),
+		mcp.WithString("tool",
+			mcp.Required(),
+			mcp.Description("Name of the read-only tool to run against every selected cluster"),
+		),
+		mcp.WithString("arguments",
+			mcp.Description("JSON object with the tool arguments, excluding the cluster identifiers which are filled in per cluster"),
+		),
+		mcp.WithString("clusters",
+			mcp.Description("Comma-separated list of AKS cluster resource IDs"),
+		),
+		mcp.WithString("subscription_id",
+			mcp.Description("Run against all clusters in this subscription (when clusters is not provided)"),
+		),
+		mcp.WithString("resource_group",
+			mcp.Description("Restrict subscription-wide selection to this resource group"),
+		),
+		mcp.WithString("tag_selector",
+			mcp.Description("Comma-separated tag filters for subscription-wide selection, e.g. 'env=prod,team=payments' or 'critical' to require a tag key"),
+		),
+		mcp.WithNumber("max_concurrency",
+			mcp.Description("Maximum number of clusters queried in parallel (default 5, max 20)"),
+		),
+	)
+}
@@ -0,0 +1,156 @@
+package fanout
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/tools"
+)
+
+// ClusterRef identifies a single AKS cluster targeted by a fan-out
+type ClusterRef struct {
+	SubscriptionID string
+	ResourceGroup  string
+	ClusterName    string
+}
+
+// ResourceID returns the Azure resource ID of the cluster
+func (c ClusterRef) ResourceID() string {
+	return fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ContainerService/managedClusters/%s",
+		c.SubscriptionID, c.ResourceGroup, c.ClusterName)
+}
+
+// Target describes a read-only tool that can be fanned out across clusters
+type Target struct {
+	// Handler executes the tool for a single cluster
+	Handler tools.ResourceHandler
+	// ScopeParams adds the parameters identifying the cluster to the tool arguments
+	ScopeParams func(cluster ClusterRef, params map[string]interface{})
+	// IsReadOnly reports whether the given arguments describe a read-only call scoped to one cluster.
+	// A nil IsReadOnly means every call of the tool qualifies.
+	IsReadOnly func(params map[string]interface{}) bool
+}
+
+// AKSParamsScope fills subscription_id, resource_group and cluster_name
+func AKSParamsScope(cluster ClusterRef, params map[string]interface{}) {
+	params["subscription_id"] = cluster.SubscriptionID
+	params["resource_group"] = cluster.ResourceGroup
+	params["cluster_name"] = cluster.ClusterName
+}
+
+// ResourceIDScope fills cluster_resource_id
+func ResourceIDScope(cluster ClusterRef, params map[string]interface{}) {
+	params["cluster_resource_id"] = cluster.ResourceID()
+}
+
+// ClusterSummary is a compact view of a cluster used by the cluster_summary target
+type ClusterSummary struct {
+	Name              string            `json:"name"`
+	Location          string            `json:"location,omitempty"`
+	KubernetesVersion string            `json:"kubernetes_version,omitempty"`
+	ProvisioningState string            `json:"provisioning_state,omitempty"`
+	PowerState        string            `json:"power_state,omitempty"`
+	NetworkPlugin     string            `json:"network_plugin,omitempty"`
+	AutoUpgrade       string            `json:"auto_upgrade_channel,omitempty"`
+	Tags              map[string]string `json:"tags,omitempty"`
+	NodePools         []NodePoolSummary `json:"node_pools,omitempty"`
+}
+
+// NodePoolSummary is a compact view of a node pool
+type NodePoolSummary struct {
+	Name                string `json:"name"`
+	Mode                string `json:"mode,omitempty"`
+	VMSize              string `json:"vm_size,omitempty"`
+	Count               int32  `json:"count"`
+	OrchestratorVersion string `json:"orchestrator_version,omitempty"`
+	NodeImageVersion    string `json:"node_image_version,omitempty"`
+	EnableAutoScaling   bool   `json:"enable_auto_scaling"`
+	MinCount            *int32 `json:"min_count,omitempty"`
+	MaxCount            *int32 `json:"max_count,omitempty"`
+}
+
+// ClusterSummaryHandler returns a handler that summarizes the cluster via the SDK.
+// It answers the most common fan-out questions (versions, node pool SKUs) without the full cluster JSON.
+func ClusterSummaryHandler(azClient *azureclient.AzureClient) tools.ResourceHandler {
+	return tools.ResourceHandlerFunc(func(params map[string]interface{}, _ *config.ConfigData) (string, error) {
+		subscriptionID, _ := params["subscription_id"].(string)
+		resourceGroup, _ := params["resource_group"].(string)
+		clusterName, _ := params["cluster_name"].(string)
+
+		cluster, err := azClient.GetAKSCluster(context.Background(), subscriptionID, resourceGroup, clusterName)
+		if err != nil {
+			return "", err
+		}
+
+		summary := ClusterSummary{Name: clusterName, Tags: map[string]string{}}
+		if cluster.Location != nil {
+			summary.Location = *cluster.Location
+		}
+		for key, value := range cluster.Tags {
+			if value != nil {
+				summary.Tags[key] = *value
+			}
+		}
+
+		if props := cluster.Properties; props != nil {
+			if props.CurrentKubernetesVersion != nil {
+				summary.KubernetesVersion = *props.CurrentKubernetesVersion
+			} else if props.KubernetesVersion != nil {
+				summary.KubernetesVersion = *props.KubernetesVersion
+			}
+			if props.ProvisioningState != nil {
+				summary.ProvisioningState = *props.ProvisioningState
+			}
+			if props.PowerState != nil && props.PowerState.Code != nil {
+				summary.PowerState = string(*props.PowerState.Code)
+			}
+			if props.NetworkProfile != nil && props.NetworkProfile.NetworkPlugin != nil {
+				summary.NetworkPlugin = string(*props.NetworkProfile.NetworkPlugin)
+			}
+			if props.AutoUpgradeProfile != nil && props.AutoUpgradeProfile.UpgradeChannel != nil {
+				summary.AutoUpgrade = string(*props.AutoUpgradeProfile.UpgradeChannel)
+			}
+
+			for _, pool := range props.AgentPoolProfiles {
+				if pool == nil || pool.Name == nil {
+					continue
+				}
+				poolSummary := NodePoolSummary{
+					Name:     *pool.Name,
+					MinCount: pool.MinCount,
+					MaxCount: pool.MaxCount,
+				}
+				if pool.Mode != nil {
+					poolSummary.Mode = string(*pool.Mode)
+				}
+				if pool.VMSize != nil {
+					poolSummary.VMSize = *pool.VMSize
+				}
+				if pool.Count != nil {
+					poolSummary.Count = *pool.Count
+				}
+				if pool.CurrentOrchestratorVersion != nil {
+					poolSummary.OrchestratorVersion = *pool.CurrentOrchestratorVersion
+				} else if pool.OrchestratorVersion != nil {
+					poolSummary.OrchestratorVersion = *pool.OrchestratorVersion
+				}
+				if pool.NodeImageVersion != nil {
+					poolSummary.NodeImageVersion = *pool.NodeImageVersion
+				}
+				if pool.EnableAutoScaling != nil {
+					poolSummary.EnableAutoScaling = *pool.EnableAutoScaling
+				}
+				summary.NodePools = append(summary.NodePools, poolSummary)
+			}
+		}
+
+		resultJSON, err := json.Marshal(summary)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal cluster summary: %v", err)
+		}
+		return string(resultJSON), nil
+	})
+}
@@ -25,6 +25,27 @@ func NewPlacementOperations(client *Client) *PlacementOperations {
 
 // CreatePlacement creates a new ClusterResourcePlacement using kubectl
 func (p *PlacementOperations) CreatePlacement(name, selector, policy string, cfg *config.ConfigData) (string, error) {
+	manifest := BuildPlacementManifest(name, selector, policy)
+
+	tempFile, err := os.CreateTemp("", "placement-*.yaml")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer func() { _ = os.Remove(tempFile.Name()) }()
+
+	if _, err := tempFile.WriteString(manifest); err != nil {
+		_ = tempFile.Close()
+		return "", fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := tempFile.Close(); err != nil {
+		return "", fmt.Errorf("failed to close temp file: %w", err)
+	}
+
+	return p.client.ExecuteKubectl(fmt.Sprintf("apply -f %s", tempFile.Name()), cfg)
+}
+
+// BuildPlacementManifest returns the ClusterResourcePlacement manifest applied by CreatePlacement
+func BuildPlacementManifest(name, selector, policy string) string {
 	// Build resource selectors
 	var resourceSelectors string
 	if selector != "" {
@@ -53,29 +74,13 @@ func (p *PlacementOperations) CreatePlacement(name, selector, policy string, cfg
         fleet.azure.com/name: "default"`
 	}
 
-	manifest := fmt.Sprintf(`apiVersion: placement.kubernetes-fleet.io/v1beta1
+	return fmt.Sprintf(`apiVersion: placement.kubernetes-fleet.io/v1beta1
 kind: ClusterResourcePlacement
 metadata:
   name: %s
 spec:%s
   policy:
     placementType: %s`, name, resourceSelectors, policy)
-
-	tempFile, err := os.CreateTemp("", "placement-*.yaml")
-	if err != nil {
-		return "", fmt.Errorf("failed to create temp file: %w", err)
-	}
-	defer func() { _ = os.Remove(tempFile.Name()) }()
-
-	if _, err := tempFile.WriteString(manifest); err != nil {
-		_ = tempFile.Close()
-		return "", fmt.Errorf("failed to write manifest: %w", err)
-	}
-	if err := tempFile.Close(); err != nil {
-		return "", fmt.Errorf("failed to close temp file: %w", err)
-	}
-
-	return p.client.ExecuteKubectl(fmt.Sprintf("apply -f %s", tempFile.Name()), cfg)
 }
 
 // GetPlacement retrieves a ClusterResourcePlacement by name using kubectl
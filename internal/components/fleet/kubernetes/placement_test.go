@@ -152,6 +152,20 @@ func TestPlacementOperations_CreatePlacement(t *testing.T) {
 	}
 }
 
+func TestBuildPlacementManifest(t *testing.T) {
+	manifest := BuildPlacementManifest("my-crp", "env=prod,team=web", "PickFixed")
+	for _, want := range []string{"name: my-crp", `env: "prod"`, `team: "web"`, "placementType: PickFixed"} {
+		if !strings.Contains(manifest, want) {
+			t.Errorf("Expected manifest to contain %q, got:\n%s", want, manifest)
+		}
+	}
+
+	defaultManifest := BuildPlacementManifest("my-crp", "", "PickAll")
+	if !strings.Contains(defaultManifest, `fleet.azure.com/name: "default"`) {
+		t.Errorf("Expected the default namespace selector, got:\n%s", defaultManifest)
+	}
+}
+
 func TestPlacementOperations_DeletePlacement(t *testing.T) {
 	placementName := "test-placement"
 	mockOutput := "clusterresourceplacement.placement.kubernetes-fleet.io \"test-placement\" deleted"
@@ -1,6 +1,8 @@
 package fleet
 
 import (
+	"github.com/Azure/aks-mcp/internal/confirm"
+	"github.com/Azure/aks-mcp/internal/dryrun"
 	"github.com/Azure/aks-mcp/internal/utils"
 	"github.com/mark3labs/mcp-go/mcp"
 )
@@ -45,6 +47,12 @@ Examples:
 			mcp.Required(),
 			mcp.Description("Additional arguments for the command (e.g., '--name myFleet --resource-group myRG')"),
 		),
+		mcp.WithBoolean(dryrun.ParamName,
+			mcp.Description(dryrun.ParamDescription),
+		),
+		mcp.WithString(confirm.ParamName,
+			mcp.Description(confirm.ParamDescription),
+		),
 	)
 }
 
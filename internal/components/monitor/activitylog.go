@@ -0,0 +1,376 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+)
+
+const (
+	// DefaultActivityLogWindow is how far back activity_log looks without start_time
+	DefaultActivityLogWindow = 7 * 24 * time.Hour
+	// MaxActivityLogWindow is the retention of the Azure activity log
+	MaxActivityLogWindow = 90 * 24 * time.Hour
+	// maxActivityLogOperations limits the operations returned in a report
+	maxActivityLogOperations = 200
+)
+
+// Scopes of an activity log operation relative to the cluster
+const (
+	ActivityScopeCluster           = "cluster"
+	ActivityScopeAgentPool         = "agent_pool"
+	ActivityScopeNodeResourceGroup = "node_resource_group"
+)
+
+// ActivityOperation is one control plane operation, merged from its Started, Accepted and final events
+type ActivityOperation struct {
+	OperationName string `json:"operation_name"`
+	Kind          string `json:"kind"`
+	Scope         string `json:"scope"`
+	ResourceID    string `json:"resource_id"`
+	Caller        string `json:"caller,omitempty"`
+	Status        string `json:"status"`
+	SubStatus     string `json:"sub_status,omitempty"`
+	Start         string `json:"start"`
+	End           string `json:"end,omitempty"`
+	Duration      string `json:"duration,omitempty"`
+	CorrelationID string `json:"correlation_id,omitempty"`
+	// DuringClusterOperation is set on node resource group operations that happened while a
+	// cluster or agent pool operation was running, and are most likely made by AKS itself
+	DuringClusterOperation string `json:"during_cluster_operation,omitempty"`
+
+	start time.Time
+	end   time.Time
+}
+
+// ActivityGroup summarizes the operations of one caller and operation name
+type ActivityGroup struct {
+	Caller        string         `json:"caller"`
+	OperationName string         `json:"operation_name"`
+	Kind          string         `json:"kind"`
+	Count         int            `json:"count"`
+	FirstSeen     string         `json:"first_seen"`
+	LastSeen      string         `json:"last_seen"`
+	Statuses      map[string]int `json:"statuses"`
+}
+
+// ProvisioningTransition is a period in which the cluster or an agent pool was being changed
+type ProvisioningTransition struct {
+	ResourceID    string `json:"resource_id"`
+	State         string `json:"state"`
+	Result        string `json:"result"`
+	Start         string `json:"start"`
+	End           string `json:"end,omitempty"`
+	Duration      string `json:"duration,omitempty"`
+	OperationName string `json:"operation_name"`
+	Caller        string `json:"caller,omitempty"`
+}
+
+// ActivityLogReport is the change history of a cluster, its agent pools and its node resource group
+type ActivityLogReport struct {
+	ClusterResourceID        string                   `json:"cluster_resource_id"`
+	NodeResourceGroup        string                   `json:"node_resource_group,omitempty"`
+	Start                    string                   `json:"start"`
+	End                      string                   `json:"end"`
+	CurrentProvisioningState string                   `json:"current_provisioning_state,omitempty"`
+	ProvisioningTimeline     []ProvisioningTransition `json:"provisioning_timeline"`
+	Groups                   []ActivityGroup          `json:"groups"`
+	Operations               []ActivityOperation      `json:"operations"`
+	OutsideClusterOperations int                      `json:"node_resource_group_changes_outside_cluster_operations"`
+	Notes                    []string                 `json:"notes,omitempty"`
+}
+
+// operationKind classifies an operation name such as Microsoft.ContainerService/managedClusters/write
+func operationKind(operationName string) string {
+	name := strings.ToLower(operationName)
+	switch {
+	case strings.HasSuffix(name, "/write"):
+		return "write"
+	case strings.HasSuffix(name, "/delete"):
+		return "delete"
+	default:
+		return "action"
+	}
+}
+
+// activityScope places a resource relative to the cluster, or returns "" when it is unrelated
+func activityScope(resourceID, clusterResourceID, nodeResourceGroup string) string {
+	id := strings.ToLower(resourceID)
+	cluster := strings.ToLower(clusterResourceID)
+	switch {
+	case id == cluster:
+		return ActivityScopeCluster
+	case strings.HasPrefix(id, cluster+"/agentpools/"):
+		return ActivityScopeAgentPool
+	case strings.HasPrefix(id, cluster+"/"):
+		return ActivityScopeCluster
+	case nodeResourceGroup != "" && strings.Contains(id, "/resourcegroups/"+strings.ToLower(nodeResourceGroup)+"/"):
+		return ActivityScopeNodeResourceGroup
+	}
+	return ""
+}
+
+// provisioningStateFor returns the provisioning state a resource is in while an operation runs
+func provisioningStateFor(operationName string) string {
+	name := strings.ToLower(operationName)
+	switch {
+	case strings.HasSuffix(name, "/delete"):
+		return "Deleting"
+	case strings.HasSuffix(name, "/write"):
+		return "Updating"
+	case strings.Contains(name, "/stop/"):
+		return "Stopping"
+	case strings.Contains(name, "/start/"):
+		return "Starting"
+	case strings.Contains(name, "/upgradenodeimageversion/"):
+		return "UpgradingNodeImageVersion"
+	}
+	return "Updating"
+}
+
+func localizedValue(s *armmonitor.LocalizableString) string {
+	if s == nil || s.Value == nil {
+		return ""
+	}
+	return *s.Value
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// SummarizeActivityLog merges activity log events into operations for the cluster, its agent
+// pools and its node resource group, groups them by caller and operation, and derives the
+// provisioning state transitions of the cluster and agent pools
+func SummarizeActivityLog(events []*armmonitor.EventData, clusterResourceID, nodeResourceGroup string) *ActivityLogReport {
+	report := &ActivityLogReport{
+		ClusterResourceID:    clusterResourceID,
+		NodeResourceGroup:    nodeResourceGroup,
+		ProvisioningTimeline: []ProvisioningTransition{},
+		Groups:               []ActivityGroup{},
+		Operations:           []ActivityOperation{},
+	}
+
+	// Events of the same operation share an operation ID; read-only calls are not logged
+	byID := map[string]*ActivityOperation{}
+	var order []string
+	for _, event := range events {
+		if event == nil || event.EventTimestamp == nil {
+			continue
+		}
+		if category := localizedValue(event.Category); category != "" && !strings.EqualFold(category, "Administrative") {
+			continue
+		}
+		resourceID := deref(event.ResourceID)
+		scope := activityScope(resourceID, clusterResourceID, nodeResourceGroup)
+		if scope == "" {
+			continue
+		}
+
+		key := deref(event.OperationID)
+		if key == "" {
+			key = deref(event.EventDataID)
+		}
+		op, ok := byID[key]
+		if !ok {
+			op = &ActivityOperation{
+				OperationName: localizedValue(event.OperationName),
+				Scope:         scope,
+				ResourceID:    resourceID,
+				CorrelationID: deref(event.CorrelationID),
+				start:         *event.EventTimestamp,
+			}
+			op.Kind = operationKind(op.OperationName)
+			byID[key] = op
+			order = append(order, key)
+		}
+
+		at := *event.EventTimestamp
+		if at.Before(op.start) {
+			op.start = at
+		}
+		if op.Caller == "" {
+			op.Caller = deref(event.Caller)
+		}
+		status := localizedValue(event.Status)
+		switch strings.ToLower(status) {
+		case "started", "accepted", "":
+			if op.end.IsZero() {
+				op.Status = "InProgress"
+			}
+		default:
+			// Succeeded, Failed or Canceled end the operation
+			if at.After(op.end) {
+				op.end = at
+				op.Status = status
+				op.SubStatus = localizedValue(event.SubStatus)
+			}
+		}
+	}
+
+	operations := make([]*ActivityOperation, 0, len(order))
+	for _, key := range order {
+		operations = append(operations, byID[key])
+	}
+	sort.SliceStable(operations, func(i, j int) bool { return operations[i].start.Before(operations[j].start) })
+
+	// The cluster and agent pool operations are the provisioning state transitions
+	var clusterOps []*ActivityOperation
+	for _, op := range operations {
+		if op.Scope == ActivityScopeNodeResourceGroup || (op.Kind == "action" && !isProvisioningAction(op.OperationName)) {
+			continue
+		}
+		clusterOps = append(clusterOps, op)
+		transition := ProvisioningTransition{
+			ResourceID:    op.ResourceID,
+			State:         provisioningStateFor(op.OperationName),
+			Result:        op.Status,
+			Start:         op.start.UTC().Format(time.RFC3339),
+			OperationName: op.OperationName,
+			Caller:        op.Caller,
+		}
+		if !op.end.IsZero() {
+			transition.End = op.end.UTC().Format(time.RFC3339)
+			transition.Duration = op.end.Sub(op.start).Round(time.Second).String()
+		}
+		report.ProvisioningTimeline = append(report.ProvisioningTimeline, transition)
+	}
+
+	// Correlate node resource group changes with the cluster operations running at the time
+	for _, op := range operations {
+		if op.Scope != ActivityScopeNodeResourceGroup {
+			continue
+		}
+		for _, clusterOp := range clusterOps {
+			sameCorrelation := op.CorrelationID != "" && strings.EqualFold(op.CorrelationID, clusterOp.CorrelationID)
+			clusterEnd := clusterOp.end
+			if clusterEnd.IsZero() {
+				clusterEnd = op.start
+			}
+			if sameCorrelation || !op.start.Before(clusterOp.start) && !op.start.After(clusterEnd) {
+				op.DuringClusterOperation = clusterOp.OperationName + " at " + clusterOp.start.UTC().Format(time.RFC3339)
+				break
+			}
+		}
+		if op.DuringClusterOperation == "" {
+			report.OutsideClusterOperations++
+		}
+	}
+	if report.OutsideClusterOperations > 0 {
+		report.Notes = append(report.Notes, fmt.Sprintf("%d change(s) in the node resource group happened outside any cluster operation; AKS does not support changing resources it manages there directly", report.OutsideClusterOperations))
+	}
+
+	groups := map[string]*ActivityGroup{}
+	var groupOrder []string
+	for _, op := range operations {
+		if !op.end.IsZero() {
+			op.End = op.end.UTC().Format(time.RFC3339)
+			op.Duration = op.end.Sub(op.start).Round(time.Second).String()
+		}
+		op.Start = op.start.UTC().Format(time.RFC3339)
+
+		key := strings.ToLower(op.Caller + "|" + op.OperationName)
+		group, ok := groups[key]
+		if !ok {
+			group = &ActivityGroup{Caller: op.Caller, OperationName: op.OperationName, Kind: op.Kind, FirstSeen: op.Start, Statuses: map[string]int{}}
+			groups[key] = group
+			groupOrder = append(groupOrder, key)
+		}
+		group.Count++
+		group.LastSeen = op.Start
+		group.Statuses[op.Status]++
+	}
+	for _, key := range groupOrder {
+		report.Groups = append(report.Groups, *groups[key])
+	}
+	sort.SliceStable(report.Groups, func(i, j int) bool { return report.Groups[i].Count > report.Groups[j].Count })
+
+	// Keep the most recent operations
+	if len(operations) > maxActivityLogOperations {
+		report.Notes = append(report.Notes, fmt.Sprintf("Only the %d most recent of %d operations are listed", maxActivityLogOperations, len(operations)))
+		operations = operations[len(operations)-maxActivityLogOperations:]
+	}
+	for _, op := range operations {
+		report.Operations = append(report.Operations, *op)
+	}
+	return report
+}
+
+// isProvisioningAction reports whether an action changes the provisioning state of a cluster or agent pool
+func isProvisioningAction(operationName string) bool {
+	name := strings.ToLower(operationName)
+	for _, action := range []string{"/stop/", "/start/", "/upgradenodeimageversion/", "/resetserviceprincipalprofile/", "/resetaadprofile/", "/rotateclustercertificates/", "/abort/"} {
+		if strings.Contains(name, action) {
+			return true
+		}
+	}
+	return false
+}
+
+// listActivityLogEvents lists the activity log events of a resource group in [start, end]
+func listActivityLogEvents(ctx context.Context, clients *azureclient.SubscriptionClients, resourceGroup string, start, end time.Time) ([]*armmonitor.EventData, error) {
+	filter := fmt.Sprintf("eventTimestamp ge '%s' and eventTimestamp le '%s' and resourceGroupName eq '%s'",
+		start.UTC().Format(time.RFC3339), end.UTC().Format(time.RFC3339), resourceGroup)
+
+	var events []*armmonitor.EventData
+	pager := clients.ActivityLogsClient.NewListPager(filter, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return events, fmt.Errorf("failed to list activity log of resource group %s: %v", resourceGroup, err)
+		}
+		events = append(events, page.Value...)
+	}
+	return events, nil
+}
+
+// QueryActivityLog builds the change history of a cluster from the activity logs of its
+// resource group and node resource group
+func QueryActivityLog(ctx context.Context, azClient *azureclient.AzureClient, subscriptionID, resourceGroup, clusterName string, start, end time.Time) (*ActivityLogReport, error) {
+	clusterResourceID := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ContainerService/managedClusters/%s",
+		subscriptionID, resourceGroup, clusterName)
+
+	var nodeResourceGroup, provisioningState string
+	cluster, clusterErr := azClient.GetAKSCluster(ctx, subscriptionID, resourceGroup, clusterName)
+	if clusterErr == nil && cluster.Properties != nil {
+		nodeResourceGroup = deref(cluster.Properties.NodeResourceGroup)
+		provisioningState = deref(cluster.Properties.ProvisioningState)
+	}
+
+	clients, err := azClient.GetOrCreateClientsForSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	events, err := listActivityLogEvents(ctx, clients, resourceGroup, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	var notes []string
+	if nodeResourceGroup != "" && !strings.EqualFold(nodeResourceGroup, resourceGroup) {
+		nodeEvents, err := listActivityLogEvents(ctx, clients, nodeResourceGroup, start, end)
+		if err != nil {
+			notes = append(notes, fmt.Sprintf("The node resource group activity log could not be read: %v", err))
+		}
+		events = append(events, nodeEvents...)
+	}
+	if clusterErr != nil {
+		// A deleted cluster still has its history
+		notes = append(notes, fmt.Sprintf("The cluster could not be read, so node resource group changes are not included: %v", clusterErr))
+	}
+
+	report := SummarizeActivityLog(events, clusterResourceID, nodeResourceGroup)
+	report.Start = start.UTC().Format(time.RFC3339)
+	report.End = end.UTC().Format(time.RFC3339)
+	report.CurrentProvisioningState = provisioningState
+	report.Notes = append(notes, report.Notes...)
+	return report, nil
+}
@@ -0,0 +1,124 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+)
+
+func activityEvent(operationID, operationName, resourceID, caller, status, correlationID string, at time.Time) *armmonitor.EventData {
+	return &armmonitor.EventData{
+		OperationID:    &operationID,
+		OperationName:  &armmonitor.LocalizableString{Value: &operationName},
+		ResourceID:     &resourceID,
+		Caller:         &caller,
+		Status:         &armmonitor.LocalizableString{Value: &status},
+		CorrelationID:  &correlationID,
+		Category:       &armmonitor.LocalizableString{Value: stringPtr("Administrative")},
+		EventTimestamp: &at,
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestSummarizeActivityLog(t *testing.T) {
+	base := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	poolID := testClusterResourceID + "/agentPools/nodepool1"
+	vmssID := "/subscriptions/sub-1/resourceGroups/MC_rg_aks_eastus/providers/Microsoft.Compute/virtualMachineScaleSets/aks-nodepool1-vmss"
+	nsgID := "/subscriptions/sub-1/resourceGroups/MC_rg_aks_eastus/providers/Microsoft.Network/networkSecurityGroups/aks-agentpool-nsg"
+
+	readEvent := activityEvent("op-5", "Microsoft.ContainerService/managedClusters/listClusterUserCredential/action", testClusterResourceID, "alice@contoso.com", "Succeeded", "c5", base)
+	readEvent.Category = &armmonitor.LocalizableString{Value: stringPtr("Policy")}
+
+	events := []*armmonitor.EventData{
+		activityEvent("op-1", "Microsoft.ContainerService/managedClusters/agentPools/write", poolID, "alice@contoso.com", "Succeeded", "c1", base.Add(10*time.Minute)),
+		activityEvent("op-1", "Microsoft.ContainerService/managedClusters/agentPools/write", poolID, "alice@contoso.com", "Started", "c1", base),
+		activityEvent("op-2", "Microsoft.Compute/virtualMachineScaleSets/write", vmssID, "AzureContainerService", "Succeeded", "c2", base.Add(5*time.Minute)),
+		activityEvent("op-3", "Microsoft.Network/networkSecurityGroups/write", nsgID, "bob@contoso.com", "Succeeded", "c3", base.Add(2*time.Hour)),
+		activityEvent("op-4", "Microsoft.ContainerService/managedClusters/agentPools/write", poolID, "alice@contoso.com", "Failed", "c4", base.Add(3*time.Hour)),
+		readEvent,
+		activityEvent("op-6", "Microsoft.Storage/storageAccounts/write", "/subscriptions/sub-1/resourceGroups/rg-1/providers/Microsoft.Storage/storageAccounts/other", "bob@contoso.com", "Succeeded", "c6", base),
+	}
+
+	report := SummarizeActivityLog(events, testClusterResourceID, "MC_rg_aks_eastus")
+
+	if len(report.Operations) != 4 {
+		t.Fatalf("expected 4 operations, got %d: %+v", len(report.Operations), report.Operations)
+	}
+	first := report.Operations[0]
+	if first.Scope != ActivityScopeAgentPool || first.Status != "Succeeded" || first.Duration != "10m0s" {
+		t.Errorf("expected the agent pool write to be merged into one operation, got %+v", first)
+	}
+
+	if len(report.ProvisioningTimeline) != 2 {
+		t.Fatalf("expected 2 provisioning transitions, got %+v", report.ProvisioningTimeline)
+	}
+	if report.ProvisioningTimeline[0].State != "Updating" || report.ProvisioningTimeline[1].Result != "Failed" {
+		t.Errorf("unexpected provisioning timeline: %+v", report.ProvisioningTimeline)
+	}
+
+	vmss := report.Operations[1]
+	if vmss.Scope != ActivityScopeNodeResourceGroup || !strings.HasPrefix(vmss.DuringClusterOperation, "Microsoft.ContainerService/managedClusters/agentPools/write") {
+		t.Errorf("expected the VMSS write to be correlated with the agent pool write, got %+v", vmss)
+	}
+	if nsg := report.Operations[2]; nsg.DuringClusterOperation != "" {
+		t.Errorf("expected the NSG write to happen outside cluster operations, got %+v", nsg)
+	}
+	if report.OutsideClusterOperations != 1 || len(report.Notes) != 1 {
+		t.Errorf("expected one change outside cluster operations with a note, got %d and %v", report.OutsideClusterOperations, report.Notes)
+	}
+
+	if len(report.Groups) != 3 {
+		t.Fatalf("expected 3 groups, got %+v", report.Groups)
+	}
+	top := report.Groups[0]
+	if top.Caller != "alice@contoso.com" || top.Count != 2 || top.Statuses["Succeeded"] != 1 || top.Statuses["Failed"] != 1 {
+		t.Errorf("unexpected top group: %+v", top)
+	}
+}
+
+func TestSummarizeActivityLogInProgress(t *testing.T) {
+	base := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	events := []*armmonitor.EventData{
+		activityEvent("op-1", "Microsoft.ContainerService/managedClusters/stop/action", testClusterResourceID, "alice@contoso.com", "Started", "c1", base),
+		activityEvent("op-2", "Microsoft.ContainerService/managedClusters/listClusterAdminCredential/action", testClusterResourceID, "alice@contoso.com", "Succeeded", "c2", base),
+	}
+
+	report := SummarizeActivityLog(events, testClusterResourceID, "")
+	if len(report.ProvisioningTimeline) != 1 {
+		t.Fatalf("expected only the stop action in the provisioning timeline, got %+v", report.ProvisioningTimeline)
+	}
+	transition := report.ProvisioningTimeline[0]
+	if transition.State != "Stopping" || transition.Result != "InProgress" || transition.End != "" {
+		t.Errorf("unexpected transition: %+v", transition)
+	}
+}
+
+func TestParseActivityLogWindow(t *testing.T) {
+	now := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	start, end, err := parseActivityLogWindow(map[string]interface{}{}, now)
+	if err != nil || !end.Equal(now) || !start.Equal(now.Add(-DefaultActivityLogWindow)) {
+		t.Errorf("expected the default window, got %v-%v (%v)", start, end, err)
+	}
+
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+	}{
+		{"invalid start", map[string]interface{}{"start_time": "yesterday"}},
+		{"end before start", map[string]interface{}{"start_time": "2025-03-05T00:00:00Z", "end_time": "2025-03-04T00:00:00Z"}},
+		{"beyond retention", map[string]interface{}{"start_time": "2024-11-01T00:00:00Z"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := parseActivityLogWindow(tt.params, now); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
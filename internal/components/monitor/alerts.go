@@ -0,0 +1,987 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/components/monitor/diagnostics"
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/dryrun"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+)
+
+// Kinds of alert rules
+const (
+	AlertRuleKindMetric     = "metric"
+	AlertRuleKindLog        = "log"
+	AlertRuleKindPrometheus = "prometheus"
+)
+
+// How an alert rule's scope relates to the cluster
+const (
+	AlertScopeCluster       = "cluster"
+	AlertScopeResourceGroup = "resource_group"
+	AlertScopeSubscription  = "subscription"
+	AlertScopeWorkspace     = "workspace"
+)
+
+const (
+	// DefaultFiredAlertsWindow is how far back alerts_fired looks without start_time
+	DefaultFiredAlertsWindow = 24 * time.Hour
+	// maxFiredAlerts limits the alerts returned by alerts_fired
+	maxFiredAlerts = 200
+	// maxAlertQueryLength limits the log search query text shown per rule
+	maxAlertQueryLength = 300
+	// baselineTagName marks the rules created by alerts_create_baseline with their template
+	baselineTagName = "aks-mcp-baseline"
+	// managedClustersResourceType is the resource type of AKS clusters
+	managedClustersResourceType = "Microsoft.ContainerService/managedClusters"
+)
+
+// actionGroupIDPattern matches an action group resource ID
+var actionGroupIDPattern = regexp.MustCompile(`(?i)^/subscriptions/[^/]+/resourceGroups/[^/]+/providers/microsoft\.insights/actionGroups/[^/]+$`)
+
+// AlertTemplateDimension restricts or splits a baseline alert by a metric dimension
+type AlertTemplateDimension struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+// AlertTemplate is a recommended metric alert for AKS clusters
+type AlertTemplate struct {
+	Name        string                   `json:"name"`
+	Description string                   `json:"description"`
+	Metric      string                   `json:"metric"`
+	Aggregation string                   `json:"aggregation"`
+	Operator    string                   `json:"operator"`
+	Threshold   float64                  `json:"threshold"`
+	Severity    int32                    `json:"severity"`
+	WindowSize  string                   `json:"window_size"`
+	Frequency   string                   `json:"evaluation_frequency"`
+	Dimensions  []AlertTemplateDimension `json:"dimensions,omitempty"`
+}
+
+// alertTemplates are the baseline metric alerts, following the alert rules Azure recommends
+// for AKS clusters. Metric names are those of the metric catalog.
+var alertTemplates = []AlertTemplate{
+	{Name: "node-cpu-high", Metric: "node_cpu_usage_percentage", Aggregation: "Average", Operator: "GreaterThan", Threshold: 95, Severity: 3, WindowSize: "PT5M", Frequency: "PT1M",
+		Dimensions:  []AlertTemplateDimension{{Name: "node", Values: []string{"*"}}},
+		Description: "A node uses more than 95% of its allocatable CPU"},
+	{Name: "node-memory-high", Metric: "node_memory_working_set_percentage", Aggregation: "Average", Operator: "GreaterThan", Threshold: 100, Severity: 3, WindowSize: "PT5M", Frequency: "PT1M",
+		Dimensions:  []AlertTemplateDimension{{Name: "node", Values: []string{"*"}}},
+		Description: "The working set of a node exceeds its allocatable memory, so the kubelet is about to evict pods"},
+	{Name: "node-disk-high", Metric: "node_disk_usage_percentage", Aggregation: "Average", Operator: "GreaterThan", Threshold: 90, Severity: 3, WindowSize: "PT5M", Frequency: "PT1M",
+		Dimensions:  []AlertTemplateDimension{{Name: "node", Values: []string{"*"}}, {Name: "device", Values: []string{"*"}}},
+		Description: "A node disk is more than 90% full"},
+	{Name: "nodes-not-ready", Metric: "kube_node_status_condition", Aggregation: "Average", Operator: "GreaterThan", Threshold: 0, Severity: 1, WindowSize: "PT15M", Frequency: "PT1M",
+		Dimensions:  []AlertTemplateDimension{{Name: "condition", Values: []string{"Ready"}}, {Name: "status2", Values: []string{"NotReady"}}},
+		Description: "Nodes have been NotReady for 15 minutes"},
+	{Name: "pods-failed", Metric: "kube_pod_status_phase", Aggregation: "Average", Operator: "GreaterThan", Threshold: 0, Severity: 3, WindowSize: "PT5M", Frequency: "PT1M",
+		Dimensions:  []AlertTemplateDimension{{Name: "phase", Values: []string{"Failed"}}, {Name: "namespace", Values: []string{"*"}}},
+		Description: "Pods are in the Failed phase"},
+}
+
+// GetAlertTemplates returns the baseline alert templates
+func GetAlertTemplates() []AlertTemplate {
+	return alertTemplates
+}
+
+// lookupAlertTemplate returns the template with the given name
+func lookupAlertTemplate(name string) (AlertTemplate, bool) {
+	for _, template := range alertTemplates {
+		if strings.EqualFold(template.Name, name) {
+			return template, true
+		}
+	}
+	return AlertTemplate{}, false
+}
+
+// AlertRuleSummary is an alert rule that covers the cluster
+type AlertRuleSummary struct {
+	Name                string   `json:"name"`
+	ID                  string   `json:"id"`
+	Kind                string   `json:"kind"`
+	Scope               string   `json:"scope"`
+	Enabled             bool     `json:"enabled"`
+	Severity            *int     `json:"severity,omitempty"`
+	Condition           string   `json:"condition"`
+	Metrics             []string `json:"metrics,omitempty"`
+	EvaluationFrequency string   `json:"evaluation_frequency,omitempty"`
+	WindowSize          string   `json:"window_size,omitempty"`
+	Description         string   `json:"description,omitempty"`
+	ActionGroups        []string `json:"action_groups"`
+	BaselineTemplate    string   `json:"baseline_template,omitempty"`
+
+	actionGroupIDs []string
+}
+
+// ActionGroupSummary is an action group notified by the cluster's alert rules
+type ActionGroupSummary struct {
+	Name      string         `json:"name"`
+	ID        string         `json:"id"`
+	ShortName string         `json:"short_name,omitempty"`
+	Enabled   bool           `json:"enabled"`
+	Receivers map[string]int `json:"receivers"`
+	Rules     int            `json:"rules"`
+}
+
+// AlertRuleInventory holds the alert rules and action groups of a subscription
+type AlertRuleInventory struct {
+	MetricRules          []*armmonitor.MetricAlertResource
+	LogRules             []*armmonitor.ScheduledQueryRuleResource
+	PrometheusRuleGroups []azureclient.PrometheusRuleGroup
+	ActionGroups         []*armmonitor.ActionGroupResource
+}
+
+// AlertRulesReport lists the alert rules covering a cluster and who they notify
+type AlertRulesReport struct {
+	ClusterResourceID string               `json:"cluster_resource_id"`
+	Rules             []AlertRuleSummary   `json:"rules"`
+	ActionGroups      []ActionGroupSummary `json:"action_groups"`
+	Counts            map[string]int       `json:"counts"`
+	MissingBaseline   []string             `json:"missing_baseline,omitempty"`
+	Notes             []string             `json:"notes,omitempty"`
+}
+
+// alertScope relates an alert rule scope to the cluster, or returns "" when it is unrelated.
+// workspaces are the Log Analytics and Azure Monitor workspaces the cluster sends data to.
+func alertScope(scope, clusterResourceID string, workspaces []string) string {
+	scope = strings.TrimSuffix(strings.ToLower(scope), "/")
+	cluster := strings.ToLower(clusterResourceID)
+	switch {
+	case scope == cluster:
+		return AlertScopeCluster
+	case strings.Contains(scope, "/resourcegroups/") && strings.HasPrefix(cluster, scope+"/providers/"):
+		return AlertScopeResourceGroup
+	case !strings.Contains(scope, "/resourcegroups/") && strings.HasPrefix(cluster, scope+"/resourcegroups/"):
+		return AlertScopeSubscription
+	}
+	for _, workspace := range workspaces {
+		if scope == strings.ToLower(workspace) {
+			return AlertScopeWorkspace
+		}
+	}
+	return ""
+}
+
+// closestScope returns the scope closest to the cluster among the rule scopes. Resource group
+// and subscription scopes only count when the rule targets AKS clusters, and workspace scopes
+// only when allowed.
+func closestScope(scopes []string, clusterResourceID string, workspaces []string, targetsClusters bool) string {
+	rank := map[string]int{AlertScopeCluster: 1, AlertScopeWorkspace: 2, AlertScopeResourceGroup: 3, AlertScopeSubscription: 4}
+	closest := ""
+	for _, scope := range scopes {
+		relation := alertScope(scope, clusterResourceID, workspaces)
+		if (relation == AlertScopeResourceGroup || relation == AlertScopeSubscription) && !targetsClusters {
+			continue
+		}
+		if relation != "" && (closest == "" || rank[relation] < rank[closest]) {
+			closest = relation
+		}
+	}
+	return closest
+}
+
+// operatorSymbols renders alert operators as comparison symbols
+var operatorSymbols = map[string]string{
+	"Equals":             "==",
+	"GreaterThan":        ">",
+	"GreaterThanOrEqual": ">=",
+	"LessThan":           "<",
+	"LessThanOrEqual":    "<=",
+	"GreaterOrLessThan":  "<>",
+}
+
+func operatorSymbol(operator string) string {
+	if symbol, ok := operatorSymbols[operator]; ok {
+		return symbol
+	}
+	return operator
+}
+
+func stringValues(values []*string) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != nil {
+			result = append(result, *value)
+		}
+	}
+	return result
+}
+
+// dimensionText renders metric dimension filters such as [phase=Failed, node=*]
+func dimensionText(dimensions []*armmonitor.MetricDimension) string {
+	var parts []string
+	for _, dimension := range dimensions {
+		if dimension == nil || dimension.Name == nil {
+			continue
+		}
+		operator := "="
+		if dimension.Operator != nil && strings.EqualFold(*dimension.Operator, "Exclude") {
+			operator = "!="
+		}
+		parts = append(parts, *dimension.Name+operator+strings.Join(stringValues(dimension.Values), "|"))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(parts, ", ") + "]"
+}
+
+// metricCriterionText renders a static or dynamic metric criterion
+func metricCriterionText(criterion armmonitor.MultiMetricCriteriaClassification) (string, string) {
+	switch c := criterion.(type) {
+	case *armmonitor.MetricCriteria:
+		text := fmt.Sprintf("%s %s %s %s", enumValue(c.TimeAggregation), deref(c.MetricName), operatorSymbol(enumValue(c.Operator)), formatThreshold(c.Threshold))
+		return text + dimensionText(c.Dimensions), deref(c.MetricName)
+	case *armmonitor.DynamicMetricCriteria:
+		text := fmt.Sprintf("%s %s %s dynamic threshold (%s sensitivity)", enumValue(c.TimeAggregation), deref(c.MetricName), operatorSymbol(enumValue(c.Operator)), enumValue(c.AlertSensitivity))
+		return text + dimensionText(c.Dimensions), deref(c.MetricName)
+	}
+	return "", ""
+}
+
+func enumValue[T ~string](value *T) string {
+	if value == nil {
+		return ""
+	}
+	return string(*value)
+}
+
+func formatThreshold(threshold *float64) string {
+	if threshold == nil {
+		return "?"
+	}
+	return strconv.FormatFloat(*threshold, 'f', -1, 64)
+}
+
+// summarizeMetricRule describes a metric alert rule and returns "" as scope when it does not cover the cluster
+func summarizeMetricRule(rule *armmonitor.MetricAlertResource, clusterResourceID string) (AlertRuleSummary, string) {
+	summary := AlertRuleSummary{Name: deref(rule.Name), ID: deref(rule.ID), Kind: AlertRuleKindMetric}
+	props := rule.Properties
+	if props == nil {
+		return summary, ""
+	}
+	targetsClusters := props.TargetResourceType == nil || strings.EqualFold(*props.TargetResourceType, managedClustersResourceType)
+	scope := closestScope(stringValues(props.Scopes), clusterResourceID, nil, targetsClusters)
+
+	summary.Enabled = props.Enabled != nil && *props.Enabled
+	if props.Severity != nil {
+		summary.Severity = to.Ptr(int(*props.Severity))
+	}
+	summary.EvaluationFrequency = deref(props.EvaluationFrequency)
+	summary.WindowSize = deref(props.WindowSize)
+	summary.Description = deref(props.Description)
+
+	var conditions []string
+	add := func(criterion armmonitor.MultiMetricCriteriaClassification) {
+		if text, metric := metricCriterionText(criterion); text != "" {
+			conditions = append(conditions, text)
+			summary.Metrics = append(summary.Metrics, metric)
+		}
+	}
+	switch criteria := props.Criteria.(type) {
+	case *armmonitor.MetricAlertSingleResourceMultipleMetricCriteria:
+		for _, criterion := range criteria.AllOf {
+			if criterion != nil {
+				add(criterion)
+			}
+		}
+	case *armmonitor.MetricAlertMultipleResourceMultipleMetricCriteria:
+		for _, criterion := range criteria.AllOf {
+			add(criterion)
+		}
+	}
+	summary.Condition = strings.Join(conditions, " and ")
+
+	for _, action := range props.Actions {
+		if action != nil && action.ActionGroupID != nil {
+			summary.actionGroupIDs = append(summary.actionGroupIDs, *action.ActionGroupID)
+		}
+	}
+	if rule.Tags != nil && rule.Tags[baselineTagName] != nil {
+		summary.BaselineTemplate = *rule.Tags[baselineTagName]
+	}
+	return summary, scope
+}
+
+// summarizeLogRule describes a log search alert rule and returns "" as scope when it does not cover the cluster
+func summarizeLogRule(rule *armmonitor.ScheduledQueryRuleResource, clusterResourceID string, workspaces []string) (AlertRuleSummary, string) {
+	summary := AlertRuleSummary{Name: deref(rule.Name), ID: deref(rule.ID), Kind: AlertRuleKindLog}
+	props := rule.Properties
+	if props == nil {
+		return summary, ""
+	}
+	targetsClusters := slices.ContainsFunc(stringValues(props.TargetResourceTypes), func(resourceType string) bool {
+		return strings.EqualFold(resourceType, managedClustersResourceType)
+	})
+	scope := closestScope(stringValues(props.Scopes), clusterResourceID, workspaces, targetsClusters)
+
+	if props.DisplayName != nil && *props.DisplayName != "" {
+		summary.Name = *props.DisplayName
+	}
+	summary.Enabled = props.Enabled != nil && *props.Enabled
+	if props.Severity != nil {
+		summary.Severity = to.Ptr(int(*props.Severity))
+	}
+	summary.EvaluationFrequency = deref(props.EvaluationFrequency)
+	summary.WindowSize = deref(props.WindowSize)
+	summary.Description = deref(props.Description)
+
+	if props.Criteria != nil {
+		var conditions []string
+		for _, condition := range props.Criteria.AllOf {
+			if condition == nil {
+				continue
+			}
+			query := strings.Join(strings.Fields(deref(condition.Query)), " ")
+			if len(query) > maxAlertQueryLength {
+				query = query[:maxAlertQueryLength] + "..."
+			}
+			measure := enumValue(condition.TimeAggregation)
+			if condition.MetricMeasureColumn != nil {
+				measure += "(" + *condition.MetricMeasureColumn + ")"
+			}
+			conditions = append(conditions, fmt.Sprintf("%s %s %s of: %s", measure, operatorSymbol(enumValue(condition.Operator)), formatThreshold(condition.Threshold), query))
+		}
+		summary.Condition = strings.Join(conditions, " and ")
+	}
+	if props.Actions != nil {
+		summary.actionGroupIDs = stringValues(props.Actions.ActionGroups)
+	}
+	return summary, scope
+}
+
+// summarizePrometheusRules describes the alerting rules of a Prometheus rule group that covers the cluster
+func summarizePrometheusRules(group azureclient.PrometheusRuleGroup, clusterResourceID, clusterName string, workspaces []string) []AlertRuleSummary {
+	props := group.Properties
+	scope := ""
+	if props.ClusterName != "" {
+		// The scopes of a cluster-specific group hold the Azure Monitor workspace and the cluster
+		if !strings.EqualFold(props.ClusterName, clusterName) || slices.ContainsFunc(props.Scopes, func(s string) bool {
+			return strings.Contains(strings.ToLower(s), "/providers/microsoft.containerservice/managedclusters/") && alertScope(s, clusterResourceID, nil) != AlertScopeCluster
+		}) {
+			return nil
+		}
+		scope = AlertScopeCluster
+	} else if scope = closestScope(props.Scopes, clusterResourceID, workspaces, false); scope == "" {
+		// A group without a cluster name evaluates every cluster of its Azure Monitor workspace
+		return nil
+	}
+
+	groupEnabled := props.Enabled == nil || *props.Enabled
+	var rules []AlertRuleSummary
+	for _, rule := range props.Rules {
+		if rule.Alert == "" {
+			continue // recording rule
+		}
+		summary := AlertRuleSummary{
+			Name:                group.Name + "/" + rule.Alert,
+			ID:                  group.ID,
+			Kind:                AlertRuleKindPrometheus,
+			Scope:               scope,
+			Enabled:             groupEnabled && (rule.Enabled == nil || *rule.Enabled),
+			Severity:            rule.Severity,
+			Condition:           strings.Join(strings.Fields(rule.Expression), " "),
+			EvaluationFrequency: props.Interval,
+			WindowSize:          rule.For,
+		}
+		for _, action := range rule.Actions {
+			summary.actionGroupIDs = append(summary.actionGroupIDs, action.ActionGroupID)
+		}
+		rules = append(rules, summary)
+	}
+	return rules
+}
+
+// receiverCounts counts the receivers of an action group by kind
+func receiverCounts(group *armmonitor.ActionGroup) map[string]int {
+	counts := map[string]int{}
+	add := func(kind string, n int) {
+		if n > 0 {
+			counts[kind] = n
+		}
+	}
+	add("email", len(group.EmailReceivers))
+	add("sms", len(group.SmsReceivers))
+	add("voice", len(group.VoiceReceivers))
+	add("webhook", len(group.WebhookReceivers))
+	add("azure_app_push", len(group.AzureAppPushReceivers))
+	add("arm_role", len(group.ArmRoleReceivers))
+	add("logic_app", len(group.LogicAppReceivers))
+	add("azure_function", len(group.AzureFunctionReceivers))
+	add("automation_runbook", len(group.AutomationRunbookReceivers))
+	add("event_hub", len(group.EventHubReceivers))
+	add("itsm", len(group.ItsmReceivers))
+	return counts
+}
+
+// templateCoveredBy returns the first enabled cluster-scoped metric rule alerting on the template's metric
+func templateCoveredBy(rules []AlertRuleSummary, template AlertTemplate) string {
+	for _, rule := range rules {
+		if rule.Kind != AlertRuleKindMetric || !rule.Enabled || rule.Scope == AlertScopeWorkspace {
+			continue
+		}
+		if strings.EqualFold(rule.BaselineTemplate, template.Name) || slices.ContainsFunc(rule.Metrics, func(metric string) bool {
+			return strings.EqualFold(metric, template.Metric)
+		}) {
+			return rule.Name
+		}
+	}
+	return ""
+}
+
+// CollectClusterAlertRules picks the alert rules of a subscription that cover the cluster, resolves
+// the action groups they notify and lists the baseline templates no rule covers. workspaces are the
+// Log Analytics and Azure Monitor workspaces the cluster sends data to.
+func CollectClusterAlertRules(inventory AlertRuleInventory, clusterResourceID, clusterName string, workspaces []string) *AlertRulesReport {
+	report := &AlertRulesReport{
+		ClusterResourceID: clusterResourceID,
+		Rules:             []AlertRuleSummary{},
+		ActionGroups:      []ActionGroupSummary{},
+		Counts:            map[string]int{},
+	}
+
+	for _, rule := range inventory.MetricRules {
+		if rule == nil {
+			continue
+		}
+		if summary, scope := summarizeMetricRule(rule, clusterResourceID); scope != "" {
+			summary.Scope = scope
+			report.Rules = append(report.Rules, summary)
+		}
+	}
+	for _, rule := range inventory.LogRules {
+		if rule == nil {
+			continue
+		}
+		if summary, scope := summarizeLogRule(rule, clusterResourceID, workspaces); scope != "" {
+			summary.Scope = scope
+			report.Rules = append(report.Rules, summary)
+		}
+	}
+	for _, group := range inventory.PrometheusRuleGroups {
+		report.Rules = append(report.Rules, summarizePrometheusRules(group, clusterResourceID, clusterName, workspaces)...)
+	}
+
+	groupsByID := map[string]*armmonitor.ActionGroupResource{}
+	for _, group := range inventory.ActionGroups {
+		if group != nil && group.ID != nil {
+			groupsByID[strings.ToLower(*group.ID)] = group
+		}
+	}
+	used := map[string]*ActionGroupSummary{}
+	var usedOrder []string
+	silent := 0
+	for i := range report.Rules {
+		rule := &report.Rules[i]
+		rule.ActionGroups = []string{}
+		for _, id := range rule.actionGroupIDs {
+			key := strings.ToLower(id)
+			summary, ok := used[key]
+			if !ok {
+				summary = &ActionGroupSummary{Name: id[strings.LastIndex(id, "/")+1:], ID: id, Receivers: map[string]int{}}
+				if group := groupsByID[key]; group != nil {
+					summary.Name = deref(group.Name)
+					if group.Properties != nil {
+						summary.ShortName = deref(group.Properties.GroupShortName)
+						summary.Enabled = group.Properties.Enabled != nil && *group.Properties.Enabled
+						summary.Receivers = receiverCounts(group.Properties)
+					}
+				}
+				used[key] = summary
+				usedOrder = append(usedOrder, key)
+			}
+			summary.Rules++
+			rule.ActionGroups = append(rule.ActionGroups, summary.Name)
+		}
+		if rule.Enabled && len(rule.ActionGroups) == 0 {
+			silent++
+		}
+		report.Counts[rule.Kind]++
+		if !rule.Enabled {
+			report.Counts["disabled"]++
+		}
+	}
+	for _, key := range usedOrder {
+		group := used[key]
+		if _, found := groupsByID[key]; !found {
+			report.Notes = append(report.Notes, fmt.Sprintf("Action group %s was not found in the subscription; it may be in another subscription or deleted", group.ID))
+		} else if !group.Enabled {
+			report.Notes = append(report.Notes, fmt.Sprintf("Action group %s is disabled, so its rules notify nobody", group.Name))
+		}
+		report.ActionGroups = append(report.ActionGroups, *group)
+	}
+	if silent > 0 {
+		report.Notes = append(report.Notes, fmt.Sprintf("%d enabled rule(s) have no action group and only show up in the portal", silent))
+	}
+
+	sort.SliceStable(report.Rules, func(i, j int) bool {
+		a, b := report.Rules[i], report.Rules[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	})
+
+	for _, template := range alertTemplates {
+		if templateCoveredBy(report.Rules, template) == "" {
+			report.MissingBaseline = append(report.MissingBaseline, template.Name)
+		}
+	}
+	return report
+}
+
+// clusterWorkspaces returns the Log Analytics workspace of the monitoring addon and the Azure
+// Monitor workspaces receiving the cluster's Prometheus metrics
+func clusterWorkspaces(ctx context.Context, azClient *azureclient.AzureClient, subscriptionID, resourceGroup, clusterName string) ([]string, []string) {
+	cluster, err := azClient.GetAKSCluster(ctx, subscriptionID, resourceGroup, clusterName)
+	if err != nil {
+		return nil, []string{fmt.Sprintf("The cluster could not be read, so workspace-scoped rules are not included: %v", err)}
+	}
+
+	var workspaces, notes []string
+	if workspace := diagnostics.MonitoringAddonWorkspace(cluster); workspace != "" {
+		workspaces = append(workspaces, workspace)
+	}
+	if profile := cluster.Properties.AzureMonitorProfile; profile != nil && profile.Metrics != nil && profile.Metrics.Enabled != nil && *profile.Metrics.Enabled {
+		monitorWorkspaces, err := azClient.FindMonitorWorkspaces(ctx, deref(cluster.ID))
+		if err != nil {
+			notes = append(notes, fmt.Sprintf("The Azure Monitor workspaces of the cluster could not be found, so Prometheus rule groups without a cluster name are not included: %v", err))
+		}
+		for _, workspace := range monitorWorkspaces {
+			workspaces = append(workspaces, workspace.ResourceID)
+		}
+	}
+	return workspaces, notes
+}
+
+// ListClusterAlertRules lists the metric, log search and Prometheus alert rules covering a cluster
+func ListClusterAlertRules(ctx context.Context, azClient *azureclient.AzureClient, subscriptionID, resourceGroup, clusterName string) (*AlertRulesReport, error) {
+	clusterResourceID := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ContainerService/managedClusters/%s",
+		subscriptionID, resourceGroup, clusterName)
+	workspaces, notes := clusterWorkspaces(ctx, azClient, subscriptionID, resourceGroup, clusterName)
+
+	var inventory AlertRuleInventory
+	var err error
+	failures := 0
+	if inventory.MetricRules, err = azClient.ListMetricAlertRules(ctx, subscriptionID); err != nil {
+		failures++
+		notes = append(notes, err.Error())
+	}
+	if inventory.LogRules, err = azClient.ListScheduledQueryRules(ctx, subscriptionID); err != nil {
+		failures++
+		notes = append(notes, err.Error())
+	}
+	if inventory.PrometheusRuleGroups, err = azClient.ListPrometheusRuleGroups(ctx, subscriptionID); err != nil {
+		failures++
+		notes = append(notes, err.Error())
+	}
+	if failures == 3 {
+		return nil, fmt.Errorf("failed to list alert rules: %s", strings.Join(notes, "; "))
+	}
+	if inventory.ActionGroups, err = azClient.ListActionGroups(ctx, subscriptionID); err != nil {
+		notes = append(notes, err.Error())
+	}
+
+	report := CollectClusterAlertRules(inventory, clusterResourceID, clusterName, workspaces)
+	if slices.ContainsFunc(report.Rules, func(rule AlertRuleSummary) bool { return rule.Scope == AlertScopeWorkspace }) {
+		report.Notes = append(report.Notes, "Rules scoped to a workspace may also cover other clusters sending data to it")
+	}
+	report.Notes = append(notes, report.Notes...)
+	return report, nil
+}
+
+// FiredAlertSummary is an alert fired on the cluster
+type FiredAlertSummary struct {
+	Name             string `json:"name"`
+	Rule             string `json:"rule"`
+	Severity         string `json:"severity"`
+	State            string `json:"state"`
+	MonitorCondition string `json:"monitor_condition"`
+	SignalType       string `json:"signal_type,omitempty"`
+	MonitorService   string `json:"monitor_service,omitempty"`
+	Description      string `json:"description,omitempty"`
+	Started          string `json:"started"`
+	Resolved         string `json:"resolved,omitempty"`
+	Duration         string `json:"duration,omitempty"`
+}
+
+// FiredAlertRuleCount counts the alerts of one rule
+type FiredAlertRuleCount struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Count    int    `json:"count"`
+	Active   int    `json:"active"`
+	LastSeen string `json:"last_seen"`
+}
+
+// FiredAlertsReport lists the alerts fired on a cluster in a window
+type FiredAlertsReport struct {
+	ClusterResourceID string                `json:"cluster_resource_id"`
+	Start             string                `json:"start"`
+	End               string                `json:"end"`
+	Total             int                   `json:"total"`
+	Active            int                   `json:"active"`
+	BySeverity        map[string]int        `json:"by_severity"`
+	ByRule            []FiredAlertRuleCount `json:"by_rule"`
+	Alerts            []FiredAlertSummary   `json:"alerts"`
+	Notes             []string              `json:"notes,omitempty"`
+}
+
+// validateFiredAlertFilters checks the optional alert state and monitor condition filters
+func validateFiredAlertFilters(state, monitorCondition string) error {
+	if state != "" && !slices.ContainsFunc([]string{"New", "Acknowledged", "Closed"}, func(s string) bool { return strings.EqualFold(s, state) }) {
+		return fmt.Errorf("invalid state: %s. Valid states: New, Acknowledged, Closed", state)
+	}
+	if monitorCondition != "" && !strings.EqualFold(monitorCondition, "Fired") && !strings.EqualFold(monitorCondition, "Resolved") {
+		return fmt.Errorf("invalid monitor_condition: %s. Valid conditions: Fired, Resolved", monitorCondition)
+	}
+	return nil
+}
+
+// SummarizeFiredAlerts groups fired alerts by rule and severity. state and monitorCondition
+// filter the alerts when set. Alerts are ordered newest first.
+func SummarizeFiredAlerts(alerts []azureclient.FiredAlert, state, monitorCondition string) *FiredAlertsReport {
+	report := &FiredAlertsReport{BySeverity: map[string]int{}, ByRule: []FiredAlertRuleCount{}, Alerts: []FiredAlertSummary{}}
+
+	byRule := map[string]*FiredAlertRuleCount{}
+	for _, alert := range alerts {
+		essentials := alert.Properties.Essentials
+		if state != "" && !strings.EqualFold(essentials.AlertState, state) {
+			continue
+		}
+		if monitorCondition != "" && !strings.EqualFold(essentials.MonitorCondition, monitorCondition) {
+			continue
+		}
+
+		rule := essentials.AlertRule
+		if i := strings.LastIndex(rule, "/"); i >= 0 {
+			rule = rule[i+1:]
+		}
+		summary := FiredAlertSummary{
+			Name:             alert.Name,
+			Rule:             rule,
+			Severity:         essentials.Severity,
+			State:            essentials.AlertState,
+			MonitorCondition: essentials.MonitorCondition,
+			SignalType:       essentials.SignalType,
+			MonitorService:   essentials.MonitorService,
+			Description:      essentials.Description,
+			Started:          essentials.StartDateTime,
+			Resolved:         essentials.MonitorConditionResolvedDateTime,
+		}
+		active := strings.EqualFold(essentials.MonitorCondition, "Fired") && !strings.EqualFold(essentials.AlertState, "Closed")
+		if summary.Resolved != "" {
+			started, startErr := time.Parse(time.RFC3339, summary.Started)
+			resolved, resolvedErr := time.Parse(time.RFC3339, summary.Resolved)
+			if startErr == nil && resolvedErr == nil && resolved.After(started) {
+				summary.Duration = resolved.Sub(started).Round(time.Second).String()
+			}
+		}
+
+		report.Total++
+		report.BySeverity[summary.Severity]++
+		count, ok := byRule[strings.ToLower(rule)]
+		if !ok {
+			count = &FiredAlertRuleCount{Rule: rule, Severity: summary.Severity}
+			byRule[strings.ToLower(rule)] = count
+		}
+		count.Count++
+		if summary.Started > count.LastSeen {
+			count.LastSeen = summary.Started
+		}
+		if active {
+			report.Active++
+			count.Active++
+		}
+		report.Alerts = append(report.Alerts, summary)
+	}
+
+	for _, count := range byRule {
+		report.ByRule = append(report.ByRule, *count)
+	}
+	sort.Slice(report.ByRule, func(i, j int) bool {
+		if report.ByRule[i].Count != report.ByRule[j].Count {
+			return report.ByRule[i].Count > report.ByRule[j].Count
+		}
+		return report.ByRule[i].Rule < report.ByRule[j].Rule
+	})
+	sort.SliceStable(report.Alerts, func(i, j int) bool {
+		return report.Alerts[i].Started > report.Alerts[j].Started
+	})
+	if len(report.Alerts) > maxFiredAlerts {
+		report.Notes = append(report.Notes, fmt.Sprintf("Showing the newest %d of %d alerts; the counts include all of them", maxFiredAlerts, len(report.Alerts)))
+		report.Alerts = report.Alerts[:maxFiredAlerts]
+	}
+	return report
+}
+
+// BaselineAlertPlan is the rule alerts_create_baseline creates for one template
+type BaselineAlertPlan struct {
+	Template  string `json:"template"`
+	RuleName  string `json:"rule_name"`
+	Condition string `json:"condition"`
+	CoveredBy string `json:"covered_by,omitempty"`
+	// Exists is set when a rule named RuleName is already in the resource group, enabled or not
+	Exists bool                           `json:"exists,omitempty"`
+	Rule   armmonitor.MetricAlertResource `json:"-"`
+}
+
+// BuildBaselineAlertRule builds the metric alert rule of a template for a cluster
+func BuildBaselineAlertRule(template AlertTemplate, clusterResourceID, actionGroupID string) armmonitor.MetricAlertResource {
+	criterion := &armmonitor.MetricCriteria{
+		CriterionType:   to.Ptr(armmonitor.CriterionTypeStaticThresholdCriterion),
+		Name:            to.Ptr("criterion1"),
+		MetricName:      to.Ptr(template.Metric),
+		MetricNamespace: to.Ptr(managedClustersResourceType),
+		Operator:        to.Ptr(armmonitor.Operator(template.Operator)),
+		Threshold:       to.Ptr(template.Threshold),
+		TimeAggregation: to.Ptr(armmonitor.AggregationTypeEnum(template.Aggregation)),
+	}
+	for _, dimension := range template.Dimensions {
+		criterion.Dimensions = append(criterion.Dimensions, &armmonitor.MetricDimension{
+			Name:     to.Ptr(dimension.Name),
+			Operator: to.Ptr("Include"),
+			Values:   to.SliceOfPtrs(dimension.Values...),
+		})
+	}
+
+	properties := &armmonitor.MetricAlertProperties{
+		Description:         to.Ptr(template.Description),
+		Enabled:             to.Ptr(true),
+		Severity:            to.Ptr(template.Severity),
+		Scopes:              []*string{to.Ptr(clusterResourceID)},
+		EvaluationFrequency: to.Ptr(template.Frequency),
+		WindowSize:          to.Ptr(template.WindowSize),
+		AutoMitigate:        to.Ptr(true),
+		TargetResourceType:  to.Ptr(managedClustersResourceType),
+		Criteria: &armmonitor.MetricAlertSingleResourceMultipleMetricCriteria{
+			ODataType: to.Ptr(armmonitor.OdatatypeMicrosoftAzureMonitorSingleResourceMultipleMetricCriteria),
+			AllOf:     []*armmonitor.MetricCriteria{criterion},
+		},
+	}
+	if actionGroupID != "" {
+		properties.Actions = []*armmonitor.MetricAlertAction{{ActionGroupID: to.Ptr(actionGroupID)}}
+	}
+	return armmonitor.MetricAlertResource{
+		Location:   to.Ptr("global"),
+		Properties: properties,
+		Tags:       map[string]*string{baselineTagName: to.Ptr(template.Name)},
+	}
+}
+
+// PlanBaselineAlerts works out the baseline rules to create for a cluster. Templates already
+// covered by an enabled cluster rule on the same metric are skipped, and so are templates whose
+// rule name is already taken in the cluster's resource group, so an existing rule is never
+// overwritten. An empty template list selects all of them.
+func PlanBaselineAlerts(existing []*armmonitor.MetricAlertResource, clusterResourceID, clusterName string, templateNames []string, actionGroupID string) ([]BaselineAlertPlan, error) {
+	if actionGroupID != "" && !actionGroupIDPattern.MatchString(actionGroupID) {
+		return nil, fmt.Errorf("invalid action_group_id. Expected format: /subscriptions/{subscription-id}/resourceGroups/{resource-group}/providers/Microsoft.Insights/actionGroups/{name}")
+	}
+	templates := alertTemplates
+	if len(templateNames) > 0 {
+		templates = nil
+		for _, name := range templateNames {
+			template, ok := lookupAlertTemplate(name)
+			if !ok {
+				var names []string
+				for _, t := range alertTemplates {
+					names = append(names, t.Name)
+				}
+				return nil, fmt.Errorf("unknown alert template: %s. Valid templates: %s", name, strings.Join(names, ", "))
+			}
+			templates = append(templates, template)
+		}
+	}
+
+	// Baseline rules are created next to the cluster, so their IDs share its subscription and resource group
+	ruleIDPrefix := clusterResourceID
+	if i := strings.Index(strings.ToLower(clusterResourceID), "/providers/"); i >= 0 {
+		ruleIDPrefix = clusterResourceID[:i]
+	}
+	ruleIDPrefix += "/providers/Microsoft.Insights/metricAlerts/"
+
+	var rules []AlertRuleSummary
+	existingIDs := make(map[string]bool)
+	for _, rule := range existing {
+		if rule == nil {
+			continue
+		}
+		if rule.ID != nil {
+			existingIDs[strings.ToLower(*rule.ID)] = true
+		}
+		if summary, scope := summarizeMetricRule(rule, clusterResourceID); scope != "" {
+			summary.Scope = scope
+			rules = append(rules, summary)
+		}
+	}
+
+	plans := make([]BaselineAlertPlan, 0, len(templates))
+	for _, template := range templates {
+		plan := BaselineAlertPlan{
+			Template:  template.Name,
+			RuleName:  clusterName + "-" + template.Name,
+			CoveredBy: templateCoveredBy(rules, template),
+		}
+		if existingIDs[strings.ToLower(ruleIDPrefix+plan.RuleName)] {
+			plan.Exists = true
+			plan.CoveredBy = plan.RuleName
+		}
+		plan.Rule = BuildBaselineAlertRule(template, clusterResourceID, actionGroupID)
+		plan.Condition, _ = metricCriterionText(plan.Rule.Properties.Criteria.(*armmonitor.MetricAlertSingleResourceMultipleMetricCriteria).AllOf[0])
+		plans = append(plans, plan)
+	}
+	return plans, nil
+}
+
+// BaselineAlertResult reports what alerts_create_baseline did for one template
+type BaselineAlertResult struct {
+	Template  string `json:"template"`
+	RuleName  string `json:"rule_name"`
+	Condition string `json:"condition"`
+	Status    string `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+}
+
+// HandleCreateBaselineAlerts creates the recommended metric alert rules for a cluster in its
+// resource group. It needs readwrite access unless dry_run is set, which only previews the rules.
+func HandleCreateBaselineAlerts(params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
+	subscriptionID, resourceGroup, clusterName, err := common.ExtractAKSParameters(params)
+	if err != nil {
+		return "", err
+	}
+	templateNames := parseNameList(params["templates"])
+	actionGroupID, _ := params["action_group_id"].(string)
+	actionGroupID = strings.TrimSpace(actionGroupID)
+
+	accessLevel := ""
+	if cfg != nil {
+		accessLevel = cfg.AccessLevel
+	}
+	var accessErr error
+	if accessLevel != "readwrite" && accessLevel != "admin" {
+		accessErr = fmt.Errorf("operation 'alerts_create_baseline' requires readwrite or admin access level")
+	}
+	dryRun := dryrun.Requested(params)
+	if accessErr != nil && !dryRun {
+		return "", accessErr
+	}
+	if azClient == nil {
+		return "", fmt.Errorf("azure client is required but not provided")
+	}
+
+	ctx := context.Background()
+	clusterResourceID := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ContainerService/managedClusters/%s",
+		subscriptionID, resourceGroup, clusterName)
+	existing, err := azClient.ListMetricAlertRules(ctx, subscriptionID)
+	if err != nil {
+		return "", err
+	}
+	plans, err := PlanBaselineAlerts(existing, clusterResourceID, clusterName, templateNames, actionGroupID)
+	if err != nil {
+		return "", err
+	}
+
+	if dryRun {
+		preview := dryrun.New("az_monitoring", "alerts_create_baseline", dryrun.NewPolicy(accessLevel, accessErr))
+		var current, proposed []string
+		for _, plan := range plans {
+			if plan.CoveredBy != "" {
+				current = append(current, plan.CoveredBy)
+				preview.AddNote(fmt.Sprintf("%s is skipped: %s", plan.Template, skipReason(plan)))
+				continue
+			}
+			proposed = append(proposed, plan.RuleName)
+			preview.AddNote(fmt.Sprintf("%s -> %s: %s over %s (severity %d)", plan.Template, plan.RuleName, plan.Condition,
+				deref(plan.Rule.Properties.WindowSize), *plan.Rule.Properties.Severity))
+			preview.AddRequest(dryrun.Request{
+				Method:     "PUT",
+				API:        "MetricAlertsClient.CreateOrUpdate",
+				ResourceID: fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Insights/metricAlerts/%s", subscriptionID, resourceGroup, plan.RuleName),
+				Body:       plan.Rule,
+			})
+		}
+		preview.AddChange("baseline alert rules", current, append(slices.Clone(current), proposed...))
+		if actionGroupID == "" && len(proposed) > 0 {
+			preview.AddNote("No action_group_id was given, so the new rules will not notify anyone")
+		}
+		return preview.JSON()
+	}
+
+	results := make([]BaselineAlertResult, 0, len(plans))
+	created := 0
+	for _, plan := range plans {
+		result := BaselineAlertResult{Template: plan.Template, RuleName: plan.RuleName, Condition: plan.Condition}
+		switch {
+		case plan.CoveredBy != "":
+			result.Status = "skipped"
+			result.Detail = skipReason(plan)
+		default:
+			if _, err := azClient.CreateOrUpdateMetricAlertRule(ctx, subscriptionID, resourceGroup, plan.RuleName, plan.Rule); err != nil {
+				result.Status = "failed"
+				result.Detail = err.Error()
+			} else {
+				result.Status = "created"
+				created++
+			}
+		}
+		results = append(results, result)
+	}
+
+	response := map[string]interface{}{
+		"cluster_resource_id": clusterResourceID,
+		"resource_group":      resourceGroup,
+		"created":             created,
+		"rules":               results,
+	}
+	if actionGroupID == "" && created > 0 {
+		response["notes"] = []string{"No action_group_id was given, so the new rules do not notify anyone"}
+	}
+	out, err := json.MarshalIndent(response, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal alerts_create_baseline result: %w", err)
+	}
+	return string(out), nil
+}
+
+// skipReason explains why a baseline template is not created
+func skipReason(plan BaselineAlertPlan) string {
+	if plan.Exists {
+		return fmt.Sprintf("rule %s already exists and is not overwritten", plan.RuleName)
+	}
+	return fmt.Sprintf("rule %s already alerts on the metric", plan.CoveredBy)
+}
+
+// parseNameList accepts names as a comma-separated string or a JSON array
+func parseNameList(value interface{}) []string {
+	var raw []string
+	switch v := value.(type) {
+	case string:
+		raw = strings.Split(v, ",")
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				raw = append(raw, s)
+			}
+		}
+	}
+
+	var names []string
+	for _, name := range raw {
+		if name = strings.TrimSpace(name); name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
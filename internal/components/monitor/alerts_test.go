@@ -0,0 +1,246 @@
+package monitor
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+)
+
+const (
+	testWorkspaceID   = "/subscriptions/sub-1/resourceGroups/rg-1/providers/Microsoft.OperationalInsights/workspaces/ws-1"
+	testActionGroupID = "/subscriptions/sub-1/resourceGroups/rg-1/providers/microsoft.insights/actionGroups/oncall"
+)
+
+func metricRule(name, scope string, metric string, actionGroupIDs ...string) *armmonitor.MetricAlertResource {
+	rule := &armmonitor.MetricAlertResource{
+		Name: to.Ptr(name),
+		ID:   to.Ptr("/subscriptions/sub-1/resourceGroups/rg-1/providers/Microsoft.Insights/metricAlerts/" + name),
+		Properties: &armmonitor.MetricAlertProperties{
+			Enabled:  to.Ptr(true),
+			Severity: to.Ptr(int32(2)),
+			Scopes:   []*string{to.Ptr(scope)},
+			Criteria: &armmonitor.MetricAlertSingleResourceMultipleMetricCriteria{
+				AllOf: []*armmonitor.MetricCriteria{{
+					MetricName:      to.Ptr(metric),
+					Operator:        to.Ptr(armmonitor.OperatorGreaterThan),
+					Threshold:       to.Ptr(80.5),
+					TimeAggregation: to.Ptr(armmonitor.AggregationTypeEnumAverage),
+				}},
+			},
+		},
+	}
+	for _, id := range actionGroupIDs {
+		rule.Properties.Actions = append(rule.Properties.Actions, &armmonitor.MetricAlertAction{ActionGroupID: to.Ptr(id)})
+	}
+	return rule
+}
+
+func TestAlertScope(t *testing.T) {
+	tests := []struct {
+		scope string
+		want  string
+	}{
+		{strings.ToUpper(testClusterResourceID), AlertScopeCluster},
+		{"/subscriptions/sub-1/resourceGroups/rg-1", AlertScopeResourceGroup},
+		{"/subscriptions/sub-1", AlertScopeSubscription},
+		{testWorkspaceID, AlertScopeWorkspace},
+		{"/subscriptions/sub-1/resourceGroups/rg-10", ""},
+		{"/subscriptions/sub-10", ""},
+		{testClusterResourceID + "-other", ""},
+	}
+	for _, tt := range tests {
+		if got := alertScope(tt.scope, testClusterResourceID, []string{testWorkspaceID}); got != tt.want {
+			t.Errorf("alertScope(%s) = %q, want %q", tt.scope, got, tt.want)
+		}
+	}
+}
+
+func TestCollectClusterAlertRules(t *testing.T) {
+	rgRule := metricRule("rg-cpu", "/subscriptions/sub-1/resourceGroups/rg-1", "node_cpu_usage_percentage")
+	rgRule.Properties.TargetResourceType = to.Ptr("Microsoft.ContainerService/managedClusters")
+	vmRule := metricRule("vm-cpu", "/subscriptions/sub-1/resourceGroups/rg-1", "Percentage CPU")
+	vmRule.Properties.TargetResourceType = to.Ptr("Microsoft.Compute/virtualMachines")
+	disabled := metricRule("disk", testClusterResourceID, "node_disk_usage_percentage")
+	disabled.Properties.Enabled = to.Ptr(false)
+
+	logRule := &armmonitor.ScheduledQueryRuleResource{
+		Name: to.Ptr("crashloop"),
+		ID:   to.Ptr("/subscriptions/sub-1/resourceGroups/rg-1/providers/Microsoft.Insights/scheduledQueryRules/crashloop"),
+		Properties: &armmonitor.ScheduledQueryRuleProperties{
+			DisplayName: to.Ptr("Pods crash looping"),
+			Enabled:     to.Ptr(true),
+			Severity:    to.Ptr(armmonitor.AlertSeverity(1)),
+			Scopes:      []*string{to.Ptr(testWorkspaceID)},
+			Criteria: &armmonitor.ScheduledQueryRuleCriteria{AllOf: []*armmonitor.Condition{{
+				Query:           to.Ptr("KubePodInventory\n| where ContainerStatusReason == 'CrashLoopBackOff'"),
+				TimeAggregation: to.Ptr(armmonitor.TimeAggregationCount),
+				Operator:        to.Ptr(armmonitor.ConditionOperatorGreaterThan),
+				Threshold:       to.Ptr(0.0),
+			}}},
+			Actions: &armmonitor.Actions{ActionGroups: []*string{to.Ptr("/subscriptions/other/resourceGroups/rg/providers/microsoft.insights/actionGroups/gone")}},
+		},
+	}
+
+	inventory := AlertRuleInventory{
+		MetricRules: []*armmonitor.MetricAlertResource{
+			metricRule("cpu", testClusterResourceID, "node_cpu_usage_percentage", testActionGroupID),
+			rgRule, vmRule, disabled,
+		},
+		LogRules: []*armmonitor.ScheduledQueryRuleResource{logRule},
+		PrometheusRuleGroups: []azureclient.PrometheusRuleGroup{
+			{ID: "/prg/aks-1", Name: "KubernetesAlert-aks-1", Properties: azureclient.PrometheusRuleGroupProperties{
+				ClusterName: "aks-1", Interval: "PT1M", Scopes: []string{testClusterResourceID},
+				Rules: []azureclient.PrometheusRule{
+					{Alert: "KubePodCrashLooping", Expression: "max_over_time(kube_pod_container_status_waiting_reason{reason=\"CrashLoopBackOff\"}[5m]) >= 1", For: "PT15M", Severity: to.Ptr(4)},
+					{Record: "node:cpu:rate5m", Expression: "rate(node_cpu_seconds_total[5m])"},
+				},
+			}},
+			{ID: "/prg/aks-2", Name: "KubernetesAlert-aks-2", Properties: azureclient.PrometheusRuleGroupProperties{
+				ClusterName: "aks-1", Scopes: []string{"/subscriptions/sub-1/resourceGroups/rg-2/providers/Microsoft.ContainerService/managedClusters/aks-1"},
+				Rules: []azureclient.PrometheusRule{{Alert: "OtherCluster", Expression: "up == 0"}},
+			}},
+		},
+		ActionGroups: []*armmonitor.ActionGroupResource{{
+			ID:   to.Ptr("/subscriptions/sub-1/resourceGroups/rg-1/providers/Microsoft.Insights/actionGroups/OnCall"),
+			Name: to.Ptr("OnCall"),
+			Properties: &armmonitor.ActionGroup{
+				Enabled:          to.Ptr(true),
+				GroupShortName:   to.Ptr("oncall"),
+				EmailReceivers:   []*armmonitor.EmailReceiver{{}, {}},
+				WebhookReceivers: []*armmonitor.WebhookReceiver{{}},
+			},
+		}},
+	}
+
+	report := CollectClusterAlertRules(inventory, testClusterResourceID, "aks-1", []string{testWorkspaceID})
+
+	var names []string
+	for _, rule := range report.Rules {
+		names = append(names, rule.Name)
+	}
+	if strings.Join(names, ",") != "Pods crash looping,cpu,disk,rg-cpu,KubernetesAlert-aks-1/KubePodCrashLooping" {
+		t.Fatalf("Unexpected rules: %v", names)
+	}
+	if cpu := report.Rules[1]; cpu.Scope != AlertScopeCluster || cpu.Condition != "Average node_cpu_usage_percentage > 80.5" || len(cpu.ActionGroups) != 1 || cpu.ActionGroups[0] != "OnCall" {
+		t.Errorf("Unexpected cpu rule: %+v", cpu)
+	}
+	if logSummary := report.Rules[0]; logSummary.Scope != AlertScopeWorkspace || !strings.HasPrefix(logSummary.Condition, "Count > 0 of: KubePodInventory | where") {
+		t.Errorf("Unexpected log rule: %+v", logSummary)
+	}
+	if rg := report.Rules[3]; rg.Scope != AlertScopeResourceGroup {
+		t.Errorf("Expected the resource group scope, got %+v", rg)
+	}
+	if prom := report.Rules[4]; prom.Kind != AlertRuleKindPrometheus || prom.WindowSize != "PT15M" || prom.Severity == nil || *prom.Severity != 4 || !prom.Enabled {
+		t.Errorf("Unexpected Prometheus rule: %+v", prom)
+	}
+
+	if len(report.ActionGroups) != 2 {
+		t.Fatalf("Expected 2 action groups, got %+v", report.ActionGroups)
+	}
+	if oncall := report.ActionGroups[0]; oncall.Receivers["email"] != 2 || oncall.Receivers["webhook"] != 1 || oncall.Rules != 1 || !oncall.Enabled {
+		t.Errorf("Unexpected action group summary: %+v", oncall)
+	}
+	if report.Counts[AlertRuleKindMetric] != 3 || report.Counts["disabled"] != 1 {
+		t.Errorf("Unexpected counts: %v", report.Counts)
+	}
+	// The disabled disk rule does not cover the node-disk-high template
+	if strings.Join(report.MissingBaseline, ",") != "node-memory-high,node-disk-high,nodes-not-ready,pods-failed" {
+		t.Errorf("Unexpected missing baseline templates: %v", report.MissingBaseline)
+	}
+	if len(report.Notes) != 2 || !strings.Contains(report.Notes[0], "was not found") || !strings.Contains(report.Notes[1], "2 enabled rule(s) have no action group") {
+		t.Errorf("Unexpected notes: %v", report.Notes)
+	}
+}
+
+func TestSummarizeFiredAlerts(t *testing.T) {
+	alert := func(name, rule, severity, state, condition, start, resolved string) azureclient.FiredAlert {
+		var a azureclient.FiredAlert
+		a.Name = name
+		a.Properties.Essentials = azureclient.FiredAlertEssentials{
+			AlertRule: "/subscriptions/sub-1/resourceGroups/rg-1/providers/Microsoft.Insights/metricAlerts/" + rule, Severity: severity,
+			AlertState: state, MonitorCondition: condition, StartDateTime: start, MonitorConditionResolvedDateTime: resolved,
+		}
+		return a
+	}
+	alerts := []azureclient.FiredAlert{
+		alert("cpu-1", "cpu", "Sev2", "New", "Resolved", "2025-03-01T10:00:00Z", "2025-03-01T10:12:30Z"),
+		alert("cpu-2", "cpu", "Sev2", "New", "Fired", "2025-03-01T12:00:00Z", ""),
+		alert("ready-1", "nodes-not-ready", "Sev1", "Closed", "Fired", "2025-03-01T11:00:00Z", ""),
+	}
+
+	report := SummarizeFiredAlerts(alerts, "", "")
+	if report.Total != 3 || report.Active != 1 || report.BySeverity["Sev2"] != 2 {
+		t.Errorf("Unexpected totals: %+v", report)
+	}
+	if report.Alerts[0].Name != "cpu-2" || report.Alerts[2].Duration != "12m30s" {
+		t.Errorf("Expected alerts newest first with durations, got %+v", report.Alerts)
+	}
+	if first := report.ByRule[0]; first.Rule != "cpu" || first.Count != 2 || first.Active != 1 || first.LastSeen != "2025-03-01T12:00:00Z" {
+		t.Errorf("Unexpected rule counts: %+v", report.ByRule)
+	}
+
+	if report := SummarizeFiredAlerts(alerts, "closed", ""); report.Total != 1 || report.Alerts[0].Rule != "nodes-not-ready" {
+		t.Errorf("Expected only the closed alert, got %+v", report.Alerts)
+	}
+	if err := validateFiredAlertFilters("Open", ""); err == nil {
+		t.Error("Expected an error for an unknown state")
+	}
+}
+
+func TestPlanBaselineAlerts(t *testing.T) {
+	existing := []*armmonitor.MetricAlertResource{metricRule("my-cpu", testClusterResourceID, "node_cpu_usage_percentage")}
+
+	plans, err := PlanBaselineAlerts(existing, testClusterResourceID, "aks-1", nil, testActionGroupID)
+	if err != nil {
+		t.Fatalf("PlanBaselineAlerts returned error: %v", err)
+	}
+	if len(plans) != len(GetAlertTemplates()) || plans[0].CoveredBy != "my-cpu" || plans[1].CoveredBy != "" {
+		t.Fatalf("Unexpected plans: %+v", plans)
+	}
+
+	notReady := plans[3]
+	if notReady.RuleName != "aks-1-nodes-not-ready" || notReady.Condition != "Average kube_node_status_condition > 0 [condition=Ready, status2=NotReady]" {
+		t.Errorf("Unexpected nodes-not-ready plan: %+v", notReady)
+	}
+	body, err := json.Marshal(notReady.Rule)
+	if err != nil {
+		t.Fatalf("Failed to marshal rule: %v", err)
+	}
+	for _, want := range []string{`"location":"global"`, `"odata.type":"Microsoft.Azure.Monitor.SingleResourceMultipleMetricCriteria"`, `"actionGroupId":"` + testActionGroupID + `"`, `"aks-mcp-baseline":"nodes-not-ready"`} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("Expected %s in the rule body %s", want, body)
+		}
+	}
+
+	// A disabled rule with the baseline name is never overwritten, even on another metric
+	taken := metricRule("aks-1-node-memory-high", "/subscriptions/sub-1/resourceGroups/rg-1/providers/Microsoft.ContainerService/managedClusters/other", "node_cpu_usage_percentage")
+	taken.Properties.Enabled = to.Ptr(false)
+	plans, err = PlanBaselineAlerts([]*armmonitor.MetricAlertResource{taken}, testClusterResourceID, "aks-1", []string{"node-memory-high"}, "")
+	if err != nil || len(plans) != 1 || !plans[0].Exists || plans[0].CoveredBy != "aks-1-node-memory-high" ||
+		skipReason(plans[0]) != "rule aks-1-node-memory-high already exists and is not overwritten" {
+		t.Errorf("Expected the existing rule to be kept, got %+v, %v", plans, err)
+	}
+
+	if _, err := PlanBaselineAlerts(nil, testClusterResourceID, "aks-1", []string{"node-cpu-high", "gpu-hot"}, ""); err == nil || !strings.Contains(err.Error(), "unknown alert template") {
+		t.Errorf("Expected an unknown template error, got %v", err)
+	}
+	if _, err := PlanBaselineAlerts(nil, testClusterResourceID, "aks-1", nil, "/subscriptions/sub-1/resourceGroups/rg-1"); err == nil {
+		t.Error("Expected an error for an invalid action group ID")
+	}
+}
+
+func TestHandleCreateBaselineAlertsRequiresReadwrite(t *testing.T) {
+	params := map[string]interface{}{"subscription_id": "sub-1", "resource_group": "rg-1", "cluster_name": "aks-1"}
+	_, err := HandleCreateBaselineAlerts(params, nil, &config.ConfigData{AccessLevel: "readonly"})
+	if err == nil || !strings.Contains(err.Error(), "requires readwrite or admin access level") {
+		t.Errorf("Expected an access level error, got %v", err)
+	}
+	if IsReadOnlyMonitoringOperation(string(OpAlertsCreateBaseline)) || !IsReadOnlyMonitoringOperation(string(OpAlertsFired)) {
+		t.Error("Only alerts_create_baseline should be treated as a write operation")
+	}
+}
@@ -0,0 +1,567 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/monitor/diagnostics"
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/security"
+	"github.com/Azure/azure-sdk-for-go/sdk/monitor/azquery"
+)
+
+// Application Insights analyses built from structured parameters instead of raw KQL
+const (
+	AppInsightsFailedRequests     = "failed_requests"
+	AppInsightsDependencyFailures = "dependency_failures"
+	AppInsightsTopExceptions      = "top_exceptions"
+	AppInsightsTrace              = "trace"
+)
+
+const (
+	// DefaultAppInsightsTop is the number of groups returned by the summarizing analyses
+	DefaultAppInsightsTop = 10
+	// MaxAppInsightsTop limits the groups returned by the summarizing analyses
+	MaxAppInsightsTop = 50
+	// maxTraceItems limits the telemetry items stitched into one trace
+	maxTraceItems = 1000
+	// defaultAppInsightsTimespan is queried when no time range is given
+	defaultAppInsightsTimespan = "P1D"
+	// defaultTraceTimespan is searched for an operation ID when no time range is given
+	defaultTraceTimespan = "P7D"
+)
+
+var (
+	// operationIDPattern matches W3C trace IDs and legacy Request-Id root IDs
+	operationIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+	// roleNamePattern matches cloud_RoleName values such as "orders-api" or "shop/orders"
+	roleNamePattern = regexp.MustCompile(`^[A-Za-z0-9 ._:/@-]{1,128}$`)
+	// operationNamePattern matches request operation names such as "GET /api/orders/{id}"
+	operationNamePattern = regexp.MustCompile(`^[A-Za-z0-9 ._:/@{}\[\]()*-]{1,256}$`)
+
+	// Pod names generated by Kubernetes controllers; the random suffixes use this alphabet
+	deploymentPodPattern  = regexp.MustCompile(`^([a-z0-9][-a-z0-9.]*)-[bcdfghjklmnpqrstvwxz2456789]{6,10}-[bcdfghjklmnpqrstvwxz2456789]{5}$`)
+	statefulSetPodPattern = regexp.MustCompile(`^([a-z0-9][-a-z0-9.]*)-([0-9]+)$`)
+	generatedPodPattern   = regexp.MustCompile(`^([a-z0-9][-a-z0-9.]*)-[bcdfghjklmnpqrstvwxz2456789]{5}$`)
+)
+
+// AppInsightsAnalysisOptions are the validated parameters of an analysis
+type AppInsightsAnalysisOptions struct {
+	Analysis      string
+	OperationID   string
+	RoleName      string
+	OperationName string
+	Top           int
+}
+
+// TraceItem is a telemetry item of an end-to-end trace
+type TraceItem struct {
+	Time     string `json:"time"`
+	OffsetMs int64  `json:"offset_ms"`
+	ItemType string `json:"item_type"`
+	Name     string `json:"name,omitempty"`
+	ID       string `json:"id,omitempty"`
+	ParentID string `json:"parent_id,omitempty"`
+	// Depth is the nesting level below the root request, derived from operation_ParentId
+	Depth      int      `json:"depth"`
+	DurationMs *float64 `json:"duration_ms,omitempty"`
+	Success    *bool    `json:"success,omitempty"`
+	ResultCode string   `json:"result_code,omitempty"`
+	Target     string   `json:"target,omitempty"`
+	Type       string   `json:"type,omitempty"`
+	Message    string   `json:"message,omitempty"`
+	Severity   *int     `json:"severity,omitempty"`
+	Role       string   `json:"role,omitempty"`
+	Instance   string   `json:"instance,omitempty"`
+
+	at time.Time
+}
+
+// TraceTimeline is an operation stitched together from its requests, dependencies, traces and exceptions
+type TraceTimeline struct {
+	OperationID string      `json:"operation_id"`
+	Start       string      `json:"start,omitempty"`
+	End         string      `json:"end,omitempty"`
+	DurationMs  int64       `json:"duration_ms"`
+	Roles       []string    `json:"roles"`
+	Failures    int         `json:"failures"`
+	Exceptions  int         `json:"exceptions"`
+	Items       []TraceItem `json:"items"`
+}
+
+// PodCorrelation maps a cloud_RoleInstance to the AKS pod that reported it
+type PodCorrelation struct {
+	Instance     string `json:"instance"`
+	Pod          string `json:"pod"`
+	Namespace    string `json:"namespace,omitempty"`
+	Node         string `json:"node,omitempty"`
+	Status       string `json:"status,omitempty"`
+	Workload     string `json:"workload,omitempty"`
+	WorkloadKind string `json:"workload_kind,omitempty"`
+	// Source is KubePodInventory when the pod was found in the cluster, or name_pattern when
+	// only the pod naming convention of Kubernetes controllers matched
+	Source string `json:"source"`
+}
+
+// AppInsightsReport is the result of an Application Insights analysis
+type AppInsightsReport struct {
+	Analysis string                   `json:"analysis"`
+	App      string                   `json:"app"`
+	Results  []map[string]interface{} `json:"results,omitempty"`
+	Trace    *TraceTimeline           `json:"trace,omitempty"`
+	Pods     []PodCorrelation         `json:"pods,omitempty"`
+	Notes    []string                 `json:"notes,omitempty"`
+	Query    string                   `json:"query"`
+}
+
+// GetSupportedAppInsightsAnalyses returns the analyses of the app_insights operation
+func GetSupportedAppInsightsAnalyses() []string {
+	return []string{AppInsightsFailedRequests, AppInsightsDependencyFailures, AppInsightsTopExceptions, AppInsightsTrace}
+}
+
+// ParseAppInsightsAnalysis validates the parameters of an Application Insights analysis
+func ParseAppInsightsAnalysis(params map[string]interface{}) (*AppInsightsAnalysisOptions, error) {
+	options := &AppInsightsAnalysisOptions{
+		Analysis:      stringValue(params, "analysis"),
+		OperationID:   stringValue(params, "operation_id"),
+		RoleName:      stringValue(params, "role_name"),
+		OperationName: stringValue(params, "operation_name"),
+		Top:           DefaultAppInsightsTop,
+	}
+	if !slices.Contains(GetSupportedAppInsightsAnalyses(), options.Analysis) {
+		return nil, fmt.Errorf("unsupported analysis '%s'. Supported analyses: %s", options.Analysis, strings.Join(GetSupportedAppInsightsAnalyses(), ", "))
+	}
+
+	if options.Analysis == AppInsightsTrace {
+		if options.OperationID == "" {
+			return nil, fmt.Errorf("missing operation_id parameter for the trace analysis")
+		}
+		if !operationIDPattern.MatchString(options.OperationID) {
+			return nil, fmt.Errorf("invalid operation_id '%s'", options.OperationID)
+		}
+		return options, nil
+	}
+	if options.OperationID != "" {
+		return nil, fmt.Errorf("operation_id only applies to the trace analysis")
+	}
+
+	if options.RoleName != "" && !roleNamePattern.MatchString(options.RoleName) {
+		return nil, fmt.Errorf("invalid role_name '%s'", options.RoleName)
+	}
+	if options.OperationName != "" {
+		if options.Analysis != AppInsightsFailedRequests {
+			return nil, fmt.Errorf("operation_name only applies to the failed_requests analysis")
+		}
+		if !operationNamePattern.MatchString(options.OperationName) {
+			return nil, fmt.Errorf("invalid operation_name '%s'", options.OperationName)
+		}
+	}
+	if top := stringValue(params, "top"); top != "" {
+		n, err := strconv.Atoi(top)
+		if err != nil || n < 1 || n > MaxAppInsightsTop {
+			return nil, fmt.Errorf("top must be between 1 and %d", MaxAppInsightsTop)
+		}
+		options.Top = n
+	}
+	return options, nil
+}
+
+// BuildAppInsightsQuery builds the KQL of an analysis against the Application Insights
+// schema (requests, dependencies, exceptions, traces). Every value placed in the query has
+// been validated by ParseAppInsightsAnalysis.
+func BuildAppInsightsQuery(options *AppInsightsAnalysisOptions) string {
+	if options.Analysis == AppInsightsTrace {
+		return fmt.Sprintf("union requests, dependencies, exceptions, traces"+
+			" | where operation_Id == '%s'"+
+			" | project timestamp, itemType, id, operation_ParentId, name, duration, success, resultCode, target, type, message, outerMessage, severityLevel, cloud_RoleName, cloud_RoleInstance"+
+			" | order by timestamp asc"+
+			" | take %d", options.OperationID, maxTraceItems)
+	}
+
+	var filters string
+	if options.RoleName != "" {
+		filters += fmt.Sprintf(" | where cloud_RoleName == '%s'", options.RoleName)
+	}
+	if options.OperationName != "" {
+		filters += fmt.Sprintf(" | where operation_Name == '%s'", options.OperationName)
+	}
+
+	switch options.Analysis {
+	case AppInsightsFailedRequests:
+		return "requests" + filters +
+			" | summarize total = count(), failed = countif(success == false), p95_duration_ms = percentile(duration, 95)," +
+			" result_codes = make_set_if(resultCode, success == false, 10), role_instances = make_set_if(cloud_RoleInstance, success == false, 10)," +
+			" sample_operation_id = take_anyif(operation_Id, success == false) by operation_Name, cloud_RoleName" +
+			" | where failed > 0" +
+			" | extend failure_rate_percent = round(100.0 * failed / total, 2)" +
+			fmt.Sprintf(" | top %d by failed desc", options.Top)
+	case AppInsightsDependencyFailures:
+		return "dependencies" + filters +
+			" | summarize total = count(), failed = countif(success == false), p95_duration_ms = percentile(duration, 95)," +
+			" result_codes = make_set_if(resultCode, success == false, 10), role_instances = make_set_if(cloud_RoleInstance, success == false, 10)," +
+			" sample_operation_id = take_anyif(operation_Id, success == false) by type, target, cloud_RoleName" +
+			" | where failed > 0" +
+			" | extend failure_rate_percent = round(100.0 * failed / total, 2)" +
+			fmt.Sprintf(" | top %d by failed desc", options.Top)
+	default:
+		return "exceptions" + filters +
+			" | summarize occurrences = count(), operations = dcount(operation_Id), first_seen = min(timestamp), last_seen = max(timestamp)," +
+			" message = take_any(outerMessage), role_instances = make_set(cloud_RoleInstance, 10), sample_operation_id = take_any(operation_Id)" +
+			" by problemId, type, cloud_RoleName" +
+			fmt.Sprintf(" | top %d by occurrences desc", options.Top)
+	}
+}
+
+// StitchTrace orders the telemetry items of an operation into one timeline, nesting each item
+// under the request or dependency it was reported from
+func StitchTrace(operationID string, rows []map[string]interface{}) *TraceTimeline {
+	timeline := &TraceTimeline{OperationID: operationID, Roles: []string{}, Items: []TraceItem{}}
+
+	var items []TraceItem
+	for _, row := range rows {
+		at, ok := parseTelemetryTime(row["timestamp"])
+		if !ok {
+			continue
+		}
+		item := TraceItem{
+			at:         at,
+			Time:       at.Format(time.RFC3339Nano),
+			ItemType:   textOf(row["itemType"]),
+			Name:       textOf(row["name"]),
+			ID:         textOf(row["id"]),
+			ParentID:   textOf(row["operation_ParentId"]),
+			ResultCode: textOf(row["resultCode"]),
+			Target:     textOf(row["target"]),
+			Type:       textOf(row["type"]),
+			Message:    textOf(row["message"]),
+			Role:       textOf(row["cloud_RoleName"]),
+			Instance:   textOf(row["cloud_RoleInstance"]),
+			DurationMs: numberOf(row["duration"]),
+			Success:    boolOf(row["success"]),
+		}
+		if item.Message == "" {
+			item.Message = textOf(row["outerMessage"])
+		}
+		if severity := numberOf(row["severityLevel"]); severity != nil {
+			level := int(*severity)
+			item.Severity = &level
+		}
+		items = append(items, item)
+	}
+	if len(items) == 0 {
+		return timeline
+	}
+
+	// Requests and dependencies are the spans other items point to through operation_ParentId
+	spans := make(map[string]int)
+	for i, item := range items {
+		if item.ID != "" && (item.ItemType == "request" || item.ItemType == "dependency") {
+			spans[item.ID] = i
+		}
+	}
+	depths := make(map[int]int)
+	var depthOf func(i int, seen map[int]bool) int
+	depthOf = func(i int, seen map[int]bool) int {
+		if depth, ok := depths[i]; ok {
+			return depth
+		}
+		parent, ok := spans[items[i].ParentID]
+		if !ok || parent == i || seen[parent] {
+			depths[i] = 0
+			return 0
+		}
+		seen[i] = true
+		depths[i] = depthOf(parent, seen) + 1
+		return depths[i]
+	}
+	for i := range items {
+		items[i].Depth = depthOf(i, map[int]bool{})
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if !items[i].at.Equal(items[j].at) {
+			return items[i].at.Before(items[j].at)
+		}
+		return items[i].Depth < items[j].Depth
+	})
+
+	start, end := items[0].at, items[0].at
+	roles := make(map[string]bool)
+	for i := range items {
+		item := &items[i]
+		item.OffsetMs = item.at.Sub(start).Milliseconds()
+		finish := item.at
+		if item.DurationMs != nil {
+			finish = item.at.Add(time.Duration(*item.DurationMs * float64(time.Millisecond)))
+		}
+		if finish.After(end) {
+			end = finish
+		}
+		if item.Role != "" && !roles[item.Role] {
+			roles[item.Role] = true
+			timeline.Roles = append(timeline.Roles, item.Role)
+		}
+		if item.Success != nil && !*item.Success {
+			timeline.Failures++
+		}
+		if item.ItemType == "exception" {
+			timeline.Exceptions++
+		}
+	}
+	timeline.Start = start.Format(time.RFC3339Nano)
+	timeline.End = end.Format(time.RFC3339Nano)
+	timeline.DurationMs = end.Sub(start).Milliseconds()
+	timeline.Items = items
+	return timeline
+}
+
+func parseTelemetryTime(value interface{}) (time.Time, bool) {
+	text, ok := value.(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	at, err := time.Parse(time.RFC3339Nano, text)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return at.UTC(), true
+}
+
+func textOf(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+func numberOf(value interface{}) *float64 {
+	switch v := value.(type) {
+	case float64:
+		return &v
+	case string:
+		if n, err := strconv.ParseFloat(v, 64); err == nil {
+			return &n
+		}
+	}
+	return nil
+}
+
+func boolOf(value interface{}) *bool {
+	switch v := value.(type) {
+	case bool:
+		return &v
+	case string:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return &b
+		}
+	}
+	return nil
+}
+
+// GuessPodWorkload derives the workload of a pod from the names Kubernetes controllers give pods
+func GuessPodWorkload(pod string) (string, string, bool) {
+	if match := deploymentPodPattern.FindStringSubmatch(pod); match != nil {
+		return match[1], "Deployment", true
+	}
+	if match := statefulSetPodPattern.FindStringSubmatch(pod); match != nil {
+		return match[1], "StatefulSet", true
+	}
+	if match := generatedPodPattern.FindStringSubmatch(pod); match != nil {
+		return match[1], "DaemonSet/Job", true
+	}
+	return "", "", false
+}
+
+// CorrelatePods maps role instances to pods, preferring the cluster's pod inventory over name patterns.
+// Instances that look like neither are left out, as they are not AKS pods.
+func CorrelatePods(instances []string, inventory []diagnostics.PodLocation) []PodCorrelation {
+	byName := make(map[string]diagnostics.PodLocation, len(inventory))
+	for _, location := range inventory {
+		byName[strings.ToLower(location.Pod)] = location
+	}
+
+	correlations := []PodCorrelation{}
+	for _, instance := range instances {
+		pod := strings.ToLower(instance)
+		workload, kind, matched := GuessPodWorkload(pod)
+		if location, ok := byName[pod]; ok {
+			correlation := PodCorrelation{
+				Instance:     instance,
+				Pod:          location.Pod,
+				Namespace:    location.Namespace,
+				Node:         location.Node,
+				Status:       location.Status,
+				Workload:     location.ControllerName,
+				WorkloadKind: location.ControllerKind,
+				Source:       diagnostics.TableKubePodInventory,
+			}
+			// A Deployment's pods are owned by a ReplicaSet; report the Deployment
+			if strings.EqualFold(location.ControllerKind, "ReplicaSet") && kind == "Deployment" {
+				correlation.Workload, correlation.WorkloadKind = workload, kind
+			}
+			correlations = append(correlations, correlation)
+			continue
+		}
+		if matched {
+			correlations = append(correlations, PodCorrelation{Instance: instance, Pod: pod, Workload: workload, WorkloadKind: kind, Source: "name_pattern"})
+		}
+	}
+	return correlations
+}
+
+// roleInstances collects the distinct cloud_RoleInstance values of analysis results and trace items
+func roleInstances(results []map[string]interface{}, trace *TraceTimeline) []string {
+	var instances []string
+	add := func(instance string) {
+		if instance != "" && !slices.Contains(instances, instance) {
+			instances = append(instances, instance)
+		}
+	}
+	for _, row := range results {
+		switch v := row["role_instances"].(type) {
+		case []interface{}:
+			for _, instance := range v {
+				add(textOf(instance))
+			}
+		case string:
+			// Dynamic columns may be returned as JSON text
+			var values []string
+			if json.Unmarshal([]byte(v), &values) == nil {
+				for _, instance := range values {
+					add(instance)
+				}
+			}
+		}
+	}
+	if trace != nil {
+		for _, item := range trace.Items {
+			add(item.Instance)
+		}
+	}
+	return instances
+}
+
+// HandleAppInsightsAnalysis runs a structured Application Insights analysis and, when a cluster
+// is given, maps the reporting role instances to its pods
+func HandleAppInsightsAnalysis(params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
+	if err := validateAppInsightsParams(params); err != nil {
+		return "", err
+	}
+	options, err := ParseAppInsightsAnalysis(params)
+	if err != nil {
+		return "", err
+	}
+
+	subscriptionID, _ := params["subscription_id"].(string)
+	resourceGroup, _ := params["resource_group"].(string)
+	appInsightsName, _ := params["app_insights_name"].(string)
+	appResourceID := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Insights/components/%s",
+		subscriptionID, resourceGroup, appInsightsName)
+
+	timespan := defaultAppInsightsTimespan
+	if options.Analysis == AppInsightsTrace {
+		timespan = defaultTraceTimespan
+	}
+	query := BuildAppInsightsQuery(options)
+	result, err := runAppInsightsQuery(azClient, appResourceID, query, params, timespan)
+	if err != nil {
+		return "", err
+	}
+
+	report := &AppInsightsReport{Analysis: options.Analysis, App: appResourceID, Query: query}
+	rows := azureclient.LogsRowMaps(azureclient.PrimaryLogsTable(result))
+	if options.Analysis == AppInsightsTrace {
+		report.Trace = StitchTrace(options.OperationID, rows)
+		if len(report.Trace.Items) == 0 {
+			report.Notes = append(report.Notes, fmt.Sprintf("No telemetry found for operation_id '%s'; widen the time range or check the sampling settings", options.OperationID))
+		}
+		if len(rows) == maxTraceItems {
+			report.Notes = append(report.Notes, fmt.Sprintf("Only the first %d telemetry items of the operation are included", maxTraceItems))
+		}
+	} else {
+		report.Results = rows
+	}
+
+	instances := roleInstances(report.Results, report.Trace)
+	if len(instances) > 0 {
+		var inventory []diagnostics.PodLocation
+		clusterName := stringValue(params, "cluster_name")
+		if clusterName != "" && azClient != nil {
+			clusterResourceGroup := stringValue(params, "cluster_resource_group")
+			if clusterResourceGroup == "" {
+				clusterResourceGroup = resourceGroup
+			}
+			// Pods of the last 7 days cover the default trace search window
+			inventory, err = diagnostics.LookupPods(subscriptionID, clusterResourceGroup, clusterName, instances, defaultTraceTimespan, azClient, cfg)
+			if err != nil {
+				report.Notes = append(report.Notes, fmt.Sprintf("Role instances could not be looked up in the cluster, pods are matched by name only: %v", err))
+			}
+		}
+		report.Pods = CorrelatePods(instances, inventory)
+	}
+
+	out, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal Application Insights report: %w", err)
+	}
+	return string(out), nil
+}
+
+// runAppInsightsQuery runs a read-only KQL query against an Application Insights resource through
+// the Azure Monitor Logs API. defaultTimespan is used when the parameters give no time range.
+func runAppInsightsQuery(azClient *azureclient.AzureClient, appResourceID, query string, params map[string]interface{}, defaultTimespan string) (azquery.Results, error) {
+	if err := security.ValidateKQLReadOnly(query); err != nil {
+		return azquery.Results{}, err
+	}
+	if azClient == nil {
+		return azquery.Results{}, fmt.Errorf("azure client is required but not provided")
+	}
+	timespan, err := appInsightsTimespan(params, defaultTimespan, time.Now())
+	if err != nil {
+		return azquery.Results{}, err
+	}
+	result, err := azClient.QueryResourceLogs(context.Background(), appResourceID, query, timespan)
+	if err != nil {
+		return azquery.Results{}, fmt.Errorf("failed to execute Application Insights query: %w", err)
+	}
+	return result, nil
+}
+
+// appInsightsTimespan converts start_time, end_time and timespan into the ISO 8601 interval of a
+// Logs API query. A timespan given with one end of the range extends from that end.
+func appInsightsTimespan(params map[string]interface{}, defaultTimespan string, now time.Time) (string, error) {
+	startTime, _ := params["start_time"].(string)
+	endTime, _ := params["end_time"].(string)
+	timespan, _ := params["timespan"].(string)
+
+	switch {
+	case startTime != "" && endTime != "":
+		return startTime + "/" + endTime, nil
+	case startTime != "" && timespan != "":
+		return startTime + "/" + timespan, nil
+	case startTime != "":
+		return startTime + "/" + now.UTC().Format(time.RFC3339), nil
+	case endTime != "":
+		if timespan == "" {
+			timespan = defaultTimespan
+		}
+		if timespan == "" {
+			return "", fmt.Errorf("end_time requires start_time or timespan")
+		}
+		return timespan + "/" + endTime, nil
+	case timespan != "":
+		return timespan, nil
+	}
+	return defaultTimespan, nil
+}
@@ -0,0 +1,160 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/components/monitor/diagnostics"
+	"github.com/Azure/aks-mcp/internal/security"
+)
+
+func TestParseAppInsightsAnalysis(t *testing.T) {
+	options, err := ParseAppInsightsAnalysis(map[string]interface{}{
+		"analysis": "failed_requests", "role_name": "orders-api", "operation_name": "GET /api/orders/{id}", "top": float64(5),
+	})
+	if err != nil {
+		t.Fatalf("ParseAppInsightsAnalysis returned error: %v", err)
+	}
+	if options.Top != 5 || options.RoleName != "orders-api" {
+		t.Errorf("Unexpected options: %+v", options)
+	}
+
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+	}{
+		{"unknown analysis", map[string]interface{}{"analysis": "slow_pages"}},
+		{"trace without operation_id", map[string]interface{}{"analysis": "trace"}},
+		{"injected operation_id", map[string]interface{}{"analysis": "trace", "operation_id": "abc' or 1==1 //"}},
+		{"operation_id outside trace", map[string]interface{}{"analysis": "top_exceptions", "operation_id": "abc"}},
+		{"injected role_name", map[string]interface{}{"analysis": "top_exceptions", "role_name": "api'"}},
+		{"operation_name outside failed_requests", map[string]interface{}{"analysis": "dependency_failures", "operation_name": "GET /"}},
+		{"top out of range", map[string]interface{}{"analysis": "top_exceptions", "top": "500"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseAppInsightsAnalysis(tt.params); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+func TestBuildAppInsightsQueryIsReadOnly(t *testing.T) {
+	for _, analysis := range GetSupportedAppInsightsAnalyses() {
+		t.Run(analysis, func(t *testing.T) {
+			options := &AppInsightsAnalysisOptions{Analysis: analysis, RoleName: "orders-api", Top: DefaultAppInsightsTop}
+			if analysis == AppInsightsTrace {
+				options = &AppInsightsAnalysisOptions{Analysis: analysis, OperationID: "4bf92f3577b34da6a3ce929d0e0e4736"}
+			}
+			query := BuildAppInsightsQuery(options)
+			if err := security.ValidateKQLReadOnly(query); err != nil {
+				t.Errorf("Query %q was rejected: %v", query, err)
+			}
+			if analysis != AppInsightsTrace && !strings.Contains(query, "cloud_RoleName == 'orders-api'") {
+				t.Errorf("Expected the role filter in %q", query)
+			}
+		})
+	}
+}
+
+func TestRunAppInsightsQueryRejectsControlCommands(t *testing.T) {
+	_, err := runAppInsightsQuery(nil, "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Insights/components/app", ".drop table requests", map[string]interface{}{}, "")
+	if err == nil || strings.Contains(err.Error(), "azure client") {
+		t.Errorf("Expected the query to be rejected before it is sent, got %v", err)
+	}
+}
+
+func TestAppInsightsTimespan(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		want    string
+		wantErr bool
+	}{
+		{"default", map[string]interface{}{}, "P1D", false},
+		{"timespan", map[string]interface{}{"timespan": "PT1H"}, "PT1H", false},
+		{"start and end", map[string]interface{}{"start_time": "2025-03-01T00:00:00Z", "end_time": "2025-03-01T06:00:00Z"}, "2025-03-01T00:00:00Z/2025-03-01T06:00:00Z", false},
+		{"start only", map[string]interface{}{"start_time": "2025-03-01T00:00:00Z"}, "2025-03-01T00:00:00Z/2025-03-01T12:00:00Z", false},
+		{"start and timespan", map[string]interface{}{"start_time": "2025-03-01T00:00:00Z", "timespan": "PT2H"}, "2025-03-01T00:00:00Z/PT2H", false},
+		{"end and timespan", map[string]interface{}{"end_time": "2025-03-01T06:00:00Z", "timespan": "PT2H"}, "PT2H/2025-03-01T06:00:00Z", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := appInsightsTimespan(tt.params, "P1D", now)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("appInsightsTimespan() = %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+	if _, err := appInsightsTimespan(map[string]interface{}{"end_time": "2025-03-01T06:00:00Z"}, "", now); err == nil {
+		t.Error("Expected an error for end_time without a range")
+	}
+}
+
+func TestStitchTrace(t *testing.T) {
+	rows := []map[string]interface{}{
+		{"timestamp": "2025-03-01T10:00:00.250Z", "itemType": "dependency", "id": "dep-1", "operation_ParentId": "req-1", "name": "SQL: orders", "duration": 120.0, "success": false, "resultCode": "1205", "cloud_RoleName": "orders-api", "cloud_RoleInstance": "orders-api-7d9f8b6c5d-x2x4k"},
+		{"timestamp": "2025-03-01T10:00:00Z", "itemType": "request", "id": "req-1", "operation_ParentId": "4bf92f35", "name": "GET /api/orders", "duration": 500.0, "success": "False", "resultCode": "500", "cloud_RoleName": "orders-api", "cloud_RoleInstance": "orders-api-7d9f8b6c5d-x2x4k"},
+		{"timestamp": "2025-03-01T10:00:00.380Z", "itemType": "exception", "operation_ParentId": "req-1", "outerMessage": "deadlock victim", "severityLevel": 3.0, "cloud_RoleName": "orders-api"},
+		{"timestamp": "2025-03-01T10:00:00.300Z", "itemType": "request", "id": "req-2", "operation_ParentId": "dep-1", "name": "POST /reserve", "duration": 20.0, "success": true, "cloud_RoleName": "inventory"},
+		{"timestamp": "not a time", "itemType": "trace"},
+	}
+
+	timeline := StitchTrace("4bf92f35", rows)
+	if len(timeline.Items) != 4 {
+		t.Fatalf("Expected 4 items, got %+v", timeline.Items)
+	}
+	order := []string{"req-1", "dep-1", "req-2", ""}
+	depths := []int{0, 1, 2, 1}
+	for i, item := range timeline.Items {
+		if item.ID != order[i] || item.Depth != depths[i] {
+			t.Errorf("Item %d: got id %q depth %d, want %q depth %d", i, item.ID, item.Depth, order[i], depths[i])
+		}
+	}
+	if exception := timeline.Items[3]; exception.Message != "deadlock victim" || exception.Severity == nil || *exception.Severity != 3 || exception.OffsetMs != 380 {
+		t.Errorf("Unexpected exception item: %+v", exception)
+	}
+	if timeline.Failures != 2 || timeline.Exceptions != 1 || timeline.DurationMs != 500 {
+		t.Errorf("Unexpected timeline summary: failures %d, exceptions %d, duration %d", timeline.Failures, timeline.Exceptions, timeline.DurationMs)
+	}
+	if len(timeline.Roles) != 2 || timeline.Roles[0] != "orders-api" || timeline.Roles[1] != "inventory" {
+		t.Errorf("Unexpected roles: %v", timeline.Roles)
+	}
+}
+
+func TestCorrelatePods(t *testing.T) {
+	instances := []string{"orders-api-7d9f8b6c5d-x2x4k", "redis-0", "fluent-bit-zx9qb", "vm-build-agent-01.contoso.com", "payments-5f6b7c8d9-abcde"}
+	inventory := []diagnostics.PodLocation{
+		{Pod: "orders-api-7d9f8b6c5d-x2x4k", Namespace: "shop", Node: "aks-nodepool1-vmss000001", Status: "Running", ControllerKind: "ReplicaSet", ControllerName: "orders-api-7d9f8b6c5d"},
+		{Pod: "redis-0", Namespace: "shop", ControllerKind: "StatefulSet", ControllerName: "redis"},
+	}
+
+	correlations := CorrelatePods(instances, inventory)
+	if len(correlations) != 3 {
+		t.Fatalf("Expected 3 correlations, got %+v", correlations)
+	}
+	orders := correlations[0]
+	if orders.Namespace != "shop" || orders.Workload != "orders-api" || orders.WorkloadKind != "Deployment" || orders.Source != diagnostics.TableKubePodInventory {
+		t.Errorf("Unexpected orders-api correlation: %+v", orders)
+	}
+	if redis := correlations[1]; redis.Workload != "redis" || redis.WorkloadKind != "StatefulSet" {
+		t.Errorf("Unexpected redis correlation: %+v", redis)
+	}
+	if fluentBit := correlations[2]; fluentBit.Workload != "fluent-bit" || fluentBit.Source != "name_pattern" {
+		t.Errorf("Unexpected fluent-bit correlation: %+v", fluentBit)
+	}
+}
+
+func TestRoleInstances(t *testing.T) {
+	results := []map[string]interface{}{
+		{"role_instances": []interface{}{"api-1", "api-2"}},
+		{"role_instances": `["api-2","api-3"]`},
+	}
+	instances := roleInstances(results, &TraceTimeline{Items: []TraceItem{{Instance: "worker-0"}}})
+	if strings.Join(instances, ",") != "api-1,api-2,api-3,worker-0" {
+		t.Errorf("Unexpected instances: %v", instances)
+	}
+}
@@ -0,0 +1,145 @@
+package monitor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Metric catalog categories
+const (
+	MetricCategoryNode       = "node"
+	MetricCategoryPod        = "pod"
+	MetricCategoryAPIServer  = "apiserver"
+	MetricCategoryEtcd       = "etcd"
+	MetricCategoryAutoscaler = "autoscaler"
+)
+
+// Metric sources. Platform metrics are read with the metrics operation; Prometheus metrics
+// are only available from Azure Monitor managed service for Prometheus.
+const (
+	MetricSourcePlatform   = "platform"
+	MetricSourcePrometheus = "prometheus"
+)
+
+// MetricDefinition describes an AKS metric in the built-in catalog
+type MetricDefinition struct {
+	Name               string   `json:"name"`
+	Category           string   `json:"category"`
+	Description        string   `json:"description"`
+	Unit               string   `json:"unit"`
+	DefaultAggregation string   `json:"default_aggregation,omitempty"`
+	Dimensions         []string `json:"dimensions,omitempty"`
+	Source             string   `json:"source"`
+	// Query is the PromQL expression for Prometheus metrics
+	Query string `json:"query,omitempty"`
+}
+
+// metricCatalog lists the AKS metrics most useful for troubleshooting. Platform metric names
+// are those of the Microsoft.ContainerService/managedClusters metric namespace.
+var metricCatalog = []MetricDefinition{
+	{Name: "node_cpu_usage_percentage", Category: MetricCategoryNode, Unit: "Percent", DefaultAggregation: "Average", Dimensions: []string{"node", "nodepool"}, Source: MetricSourcePlatform,
+		Description: "CPU used by each node as a percentage of its allocatable CPU"},
+	{Name: "node_cpu_usage_millicores", Category: MetricCategoryNode, Unit: "MilliCores", DefaultAggregation: "Average", Dimensions: []string{"node", "nodepool"}, Source: MetricSourcePlatform,
+		Description: "CPU used by each node in millicores"},
+	{Name: "node_memory_working_set_percentage", Category: MetricCategoryNode, Unit: "Percent", DefaultAggregation: "Average", Dimensions: []string{"node", "nodepool"}, Source: MetricSourcePlatform,
+		Description: "Working set memory of each node as a percentage of allocatable memory; the kubelet evicts pods based on the working set"},
+	{Name: "node_memory_rss_percentage", Category: MetricCategoryNode, Unit: "Percent", DefaultAggregation: "Average", Dimensions: []string{"node", "nodepool"}, Source: MetricSourcePlatform,
+		Description: "Resident set memory of each node as a percentage of allocatable memory"},
+	{Name: "node_disk_usage_percentage", Category: MetricCategoryNode, Unit: "Percent", DefaultAggregation: "Average", Dimensions: []string{"node", "nodepool", "device"}, Source: MetricSourcePlatform,
+		Description: "Disk space used on each node device; the kubelet starts image garbage collection and evictions as disks fill up"},
+	{Name: "node_network_in_bytes", Category: MetricCategoryNode, Unit: "Bytes", DefaultAggregation: "Average", Dimensions: []string{"node", "nodepool"}, Source: MetricSourcePlatform,
+		Description: "Network bytes received by each node"},
+	{Name: "node_network_out_bytes", Category: MetricCategoryNode, Unit: "Bytes", DefaultAggregation: "Average", Dimensions: []string{"node", "nodepool"}, Source: MetricSourcePlatform,
+		Description: "Network bytes sent by each node"},
+	{Name: "kube_node_status_condition", Category: MetricCategoryNode, Unit: "Count", DefaultAggregation: "Average", Dimensions: []string{"node", "condition", "status", "status2"}, Source: MetricSourcePlatform,
+		Description: "Node conditions such as Ready, MemoryPressure and DiskPressure reported by the kubelet"},
+	{Name: "kube_node_status_allocatable_cpu_cores", Category: MetricCategoryNode, Unit: "Count", DefaultAggregation: "Average", Source: MetricSourcePlatform,
+		Description: "Total CPU cores that can be allocated to pods across the cluster"},
+	{Name: "kube_node_status_allocatable_memory_bytes", Category: MetricCategoryNode, Unit: "Bytes", DefaultAggregation: "Average", Source: MetricSourcePlatform,
+		Description: "Total memory that can be allocated to pods across the cluster"},
+	{Name: "kube_pod_status_ready", Category: MetricCategoryPod, Unit: "Count", DefaultAggregation: "Average", Dimensions: []string{"namespace", "pod", "condition"}, Source: MetricSourcePlatform,
+		Description: "Number of pods in the Ready state"},
+	{Name: "kube_pod_status_phase", Category: MetricCategoryPod, Unit: "Count", DefaultAggregation: "Average", Dimensions: []string{"phase", "namespace", "pod"}, Source: MetricSourcePlatform,
+		Description: "Number of pods by phase (Pending, Running, Succeeded, Failed, Unknown)"},
+	{Name: "apiserver_current_inflight_requests", Category: MetricCategoryAPIServer, Unit: "Count", DefaultAggregation: "Maximum", Dimensions: []string{"requestKind"}, Source: MetricSourcePlatform,
+		Description: "Requests the API server is currently processing, by mutating and read-only request kind"},
+	{Name: "apiserver_cpu_usage_percentage", Category: MetricCategoryAPIServer, Unit: "Percent", DefaultAggregation: "Maximum", Source: MetricSourcePlatform,
+		Description: "CPU used by the API server pods as a percentage of their limit (control plane metrics)"},
+	{Name: "apiserver_memory_usage_percentage", Category: MetricCategoryAPIServer, Unit: "Percent", DefaultAggregation: "Maximum", Source: MetricSourcePlatform,
+		Description: "Memory used by the API server pods as a percentage of their limit (control plane metrics)"},
+	{Name: "apiserver_request_duration_seconds", Category: MetricCategoryAPIServer, Unit: "Seconds", Source: MetricSourcePrometheus,
+		Query:       `histogram_quantile(0.99, sum by (le, verb) (rate(apiserver_request_duration_seconds_bucket{verb!~"WATCH|CONNECT"}[5m])))`,
+		Description: "API server request latency; not a platform metric, requires control plane metrics in Azure Monitor managed service for Prometheus and is read with the promql operation"},
+	{Name: "etcd_cpu_usage_percentage", Category: MetricCategoryEtcd, Unit: "Percent", DefaultAggregation: "Maximum", Source: MetricSourcePlatform,
+		Description: "CPU used by etcd as a percentage of its limit (control plane metrics)"},
+	{Name: "etcd_memory_usage_percentage", Category: MetricCategoryEtcd, Unit: "Percent", DefaultAggregation: "Maximum", Source: MetricSourcePlatform,
+		Description: "Memory used by etcd as a percentage of its limit (control plane metrics)"},
+	{Name: "etcd_database_usage_percentage", Category: MetricCategoryEtcd, Unit: "Percent", DefaultAggregation: "Maximum", Source: MetricSourcePlatform,
+		Description: "Size of the etcd database as a percentage of its quota; writes fail when the quota is reached (control plane metrics)"},
+	{Name: "cluster_autoscaler_cluster_safe_to_autoscale", Category: MetricCategoryAutoscaler, Unit: "Count", DefaultAggregation: "Average", Source: MetricSourcePlatform,
+		Description: "1 when the cluster autoscaler considers the cluster healthy enough to scale, 0 otherwise"},
+	{Name: "cluster_autoscaler_scale_down_in_cooldown", Category: MetricCategoryAutoscaler, Unit: "Count", DefaultAggregation: "Average", Source: MetricSourcePlatform,
+		Description: "1 while scale down is in cooldown after a scale up or node deletion"},
+	{Name: "cluster_autoscaler_unneeded_nodes_count", Category: MetricCategoryAutoscaler, Unit: "Count", DefaultAggregation: "Average", Source: MetricSourcePlatform,
+		Description: "Nodes the cluster autoscaler marked as candidates for scale down"},
+	{Name: "cluster_autoscaler_unschedulable_pods_count", Category: MetricCategoryAutoscaler, Unit: "Count", DefaultAggregation: "Average", Source: MetricSourcePlatform,
+		Description: "Pods that could not be scheduled and are waiting for the autoscaler to add nodes"},
+}
+
+// GetMetricCatalog returns the catalog entries of a category, or all entries when category is empty
+func GetMetricCatalog(category string) ([]MetricDefinition, error) {
+	if category == "" {
+		return append([]MetricDefinition{}, metricCatalog...), nil
+	}
+
+	var definitions []MetricDefinition
+	for _, definition := range metricCatalog {
+		if strings.EqualFold(definition.Category, category) {
+			definitions = append(definitions, definition)
+		}
+	}
+	if definitions == nil {
+		return nil, fmt.Errorf("unknown metric category '%s': use one of %s", category, strings.Join(metricCategories(), ", "))
+	}
+	return definitions, nil
+}
+
+// LookupMetric returns the catalog entry of a metric
+func LookupMetric(name string) (MetricDefinition, bool) {
+	for _, definition := range metricCatalog {
+		if definition.Name == name {
+			return definition, true
+		}
+	}
+	return MetricDefinition{}, false
+}
+
+// defaultAggregation returns the catalog default aggregation shared by all metrics, if any
+func defaultAggregation(metrics []string) string {
+	aggregation := ""
+	for _, name := range metrics {
+		definition, ok := LookupMetric(name)
+		if !ok || definition.DefaultAggregation == "" {
+			return ""
+		}
+		if aggregation != "" && aggregation != definition.DefaultAggregation {
+			return ""
+		}
+		aggregation = definition.DefaultAggregation
+	}
+	return aggregation
+}
+
+func metricCategories() []string {
+	seen := make(map[string]bool)
+	var categories []string
+	for _, definition := range metricCatalog {
+		if !seen[definition.Category] {
+			seen[definition.Category] = true
+			categories = append(categories, definition.Category)
+		}
+	}
+	sort.Strings(categories)
+	return categories
+}
@@ -0,0 +1,245 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Golden signals
+const (
+	SignalLatency    = "latency"
+	SignalTraffic    = "traffic"
+	SignalErrors     = "errors"
+	SignalSaturation = "saturation"
+)
+
+// Dashboard panel statuses, from best to worst. Unknown and unavailable panels do not
+// affect the overall status.
+const (
+	PanelStatusOK          = "ok"
+	PanelStatusWarning     = "warning"
+	PanelStatusCritical    = "critical"
+	PanelStatusUnknown     = "unknown"
+	PanelStatusUnavailable = "unavailable"
+)
+
+// Statistics a panel can be evaluated on
+const (
+	statisticAvg  = "avg"
+	statisticMax  = "max"
+	statisticP95  = "p95"
+	statisticLast = "last"
+)
+
+// DashboardSignal is a curated metric in the cluster health dashboard
+type DashboardSignal struct {
+	Signal      string
+	Title       string
+	Metric      string
+	Aggregation string
+	// Filter splits or narrows the series; split series are evaluated on the worst one
+	Filter    string
+	Statistic string
+	// Warning and Critical are upper thresholds on the statistic; zero means no threshold
+	Warning  float64
+	Critical float64
+}
+
+// dashboardSignals is the curated set of metrics in the cluster health dashboard
+var dashboardSignals = []DashboardSignal{
+	{Signal: SignalSaturation, Title: "Node CPU", Metric: "node_cpu_usage_percentage", Aggregation: "Average", Filter: "node eq '*'", Statistic: statisticP95, Warning: 80, Critical: 95},
+	{Signal: SignalSaturation, Title: "Node memory (working set)", Metric: "node_memory_working_set_percentage", Aggregation: "Average", Filter: "node eq '*'", Statistic: statisticP95, Warning: 80, Critical: 95},
+	{Signal: SignalSaturation, Title: "Node disk", Metric: "node_disk_usage_percentage", Aggregation: "Average", Filter: "node eq '*'", Statistic: statisticMax, Warning: 80, Critical: 90},
+	{Signal: SignalSaturation, Title: "etcd database", Metric: "etcd_database_usage_percentage", Aggregation: "Maximum", Statistic: statisticMax, Warning: 70, Critical: 90},
+	{Signal: SignalSaturation, Title: "API server CPU", Metric: "apiserver_cpu_usage_percentage", Aggregation: "Maximum", Statistic: statisticMax, Warning: 80, Critical: 95},
+	{Signal: SignalTraffic, Title: "API server inflight requests", Metric: "apiserver_current_inflight_requests", Aggregation: "Maximum", Statistic: statisticMax},
+	{Signal: SignalTraffic, Title: "Node network in", Metric: "node_network_in_bytes", Aggregation: "Average", Statistic: statisticAvg},
+	{Signal: SignalTraffic, Title: "Node network out", Metric: "node_network_out_bytes", Aggregation: "Average", Statistic: statisticAvg},
+	{Signal: SignalErrors, Title: "Pending pods", Metric: "kube_pod_status_phase", Aggregation: "Average", Filter: "phase eq 'Pending'", Statistic: statisticAvg, Warning: 1, Critical: 10},
+	{Signal: SignalErrors, Title: "Failed pods", Metric: "kube_pod_status_phase", Aggregation: "Average", Filter: "phase eq 'Failed'", Statistic: statisticLast, Warning: 1, Critical: 10},
+	{Signal: SignalErrors, Title: "Unschedulable pods", Metric: "cluster_autoscaler_unschedulable_pods_count", Aggregation: "Average", Statistic: statisticMax, Warning: 1, Critical: 10},
+}
+
+// DashboardThresholds are the thresholds a panel was evaluated against
+type DashboardThresholds struct {
+	Warning  float64 `json:"warning"`
+	Critical float64 `json:"critical"`
+}
+
+// DashboardPanel is the evaluated state of one dashboard metric
+type DashboardPanel struct {
+	Signal      string               `json:"signal"`
+	Title       string               `json:"title"`
+	Metric      string               `json:"metric"`
+	Filter      string               `json:"filter,omitempty"`
+	Statistic   string               `json:"statistic"`
+	Status      string               `json:"status"`
+	Value       *float64             `json:"value,omitempty"`
+	Unit        string               `json:"unit,omitempty"`
+	Thresholds  *DashboardThresholds `json:"thresholds,omitempty"`
+	Series      int                  `json:"series"`
+	Worst       map[string]string    `json:"worst_series,omitempty"`
+	Trend       string               `json:"trend,omitempty"`
+	Anomalies   int                  `json:"anomalies,omitempty"`
+	Message     string               `json:"message,omitempty"`
+	SourceQuery string               `json:"query,omitempty"`
+}
+
+// Dashboard is the golden-signals summary of a cluster
+type Dashboard struct {
+	ResourceID    string            `json:"resource_id"`
+	Timespan      string            `json:"timespan"`
+	Interval      string            `json:"interval"`
+	Status        string            `json:"status"`
+	GoldenSignals map[string]string `json:"golden_signals"`
+	Panels        []DashboardPanel  `json:"panels"`
+}
+
+// metricsQuerier runs a metrics query; it is QueryMetrics bound to an Azure client
+type metricsQuerier func(ctx context.Context, query *MetricsQuery) (*MetricsResult, error)
+
+// BuildClusterHealthDashboard queries the curated dashboard metrics in parallel and evaluates them
+func BuildClusterHealthDashboard(ctx context.Context, query metricsQuerier, resourceID string, window time.Duration, now time.Time) *Dashboard {
+	start := now.Add(-window)
+	interval := PickMetricsInterval(window)
+
+	panels := make([]DashboardPanel, len(dashboardSignals)+1)
+	var wg sync.WaitGroup
+	for i, signal := range dashboardSignals {
+		wg.Add(1)
+		go func(i int, signal DashboardSignal) {
+			defer wg.Done()
+			result, err := query(ctx, &MetricsQuery{
+				ResourceID:  resourceID,
+				Metrics:     []string{signal.Metric},
+				Aggregation: signal.Aggregation,
+				Start:       start,
+				End:         now,
+				Interval:    interval,
+				Filter:      signal.Filter,
+				Sigma:       DefaultAnomalySigma,
+			})
+			panels[i] = evaluateSignal(signal, result, err)
+		}(i, signal)
+	}
+	wg.Wait()
+
+	// API server latency is not a platform metric, so the panel points to the Prometheus query instead
+	latency, _ := LookupMetric("apiserver_request_duration_seconds")
+	panels[len(dashboardSignals)] = DashboardPanel{
+		Signal:      SignalLatency,
+		Title:       "API server request latency (p99)",
+		Metric:      latency.Name,
+		Statistic:   "p99",
+		Status:      PanelStatusUnavailable,
+		Unit:        latency.Unit,
+		Message:     "Request latency is not an Azure Monitor platform metric; query it from Azure Monitor managed service for Prometheus",
+		SourceQuery: latency.Query,
+	}
+
+	dashboard := &Dashboard{
+		ResourceID:    resourceID,
+		Timespan:      fmt.Sprintf("%s/%s", start.UTC().Format(time.RFC3339), now.UTC().Format(time.RFC3339)),
+		Interval:      interval,
+		Status:        PanelStatusUnknown,
+		GoldenSignals: map[string]string{},
+		Panels:        panels,
+	}
+	for _, panel := range panels {
+		current, ok := dashboard.GoldenSignals[panel.Signal]
+		if !ok || statusRank(panel.Status) > statusRank(current) {
+			dashboard.GoldenSignals[panel.Signal] = panel.Status
+		}
+		if statusRank(panel.Status) > statusRank(dashboard.Status) {
+			dashboard.Status = panel.Status
+		}
+	}
+	return dashboard
+}
+
+// evaluateSignal turns the metrics of one dashboard signal into a panel, using the worst series
+func evaluateSignal(signal DashboardSignal, result *MetricsResult, err error) DashboardPanel {
+	panel := DashboardPanel{
+		Signal:    signal.Signal,
+		Title:     signal.Title,
+		Metric:    signal.Metric,
+		Filter:    signal.Filter,
+		Statistic: signal.Statistic,
+		Status:    PanelStatusUnknown,
+	}
+	if signal.Critical > 0 {
+		panel.Thresholds = &DashboardThresholds{Warning: signal.Warning, Critical: signal.Critical}
+	}
+
+	switch {
+	case err != nil:
+		panel.Message = err.Error()
+		return panel
+	case len(result.Errors) > 0:
+		panel.Message = result.Errors[0]
+		return panel
+	}
+
+	var worst *SeriesSummary
+	for i := range result.Series {
+		series := &result.Series[i]
+		if series.Points == 0 {
+			continue
+		}
+		panel.Series++
+		panel.Anomalies += len(series.Anomalies)
+		if worst == nil || statisticOf(series, signal.Statistic) > statisticOf(worst, signal.Statistic) {
+			worst = series
+		}
+	}
+	if worst == nil {
+		panel.Message = "No data in the window; the metric may not be emitted by this cluster"
+		return panel
+	}
+
+	value := statisticOf(worst, signal.Statistic)
+	panel.Value = &value
+	panel.Unit = worst.Unit
+	panel.Trend = worst.Trend.Direction
+	if panel.Series > 1 {
+		panel.Worst = worst.Dimensions
+	}
+
+	switch {
+	case signal.Critical > 0 && value >= signal.Critical:
+		panel.Status = PanelStatusCritical
+	case signal.Warning > 0 && value >= signal.Warning:
+		panel.Status = PanelStatusWarning
+	default:
+		panel.Status = PanelStatusOK
+	}
+	return panel
+}
+
+func statisticOf(series *SeriesSummary, statistic string) float64 {
+	switch statistic {
+	case statisticMax:
+		return series.Max
+	case statisticP95:
+		return series.P95
+	case statisticLast:
+		return series.Last
+	default:
+		return series.Avg
+	}
+}
+
+func statusRank(status string) int {
+	switch status {
+	case PanelStatusCritical:
+		return 3
+	case PanelStatusWarning:
+		return 2
+	case PanelStatusOK:
+		return 1
+	default:
+		return 0
+	}
+}
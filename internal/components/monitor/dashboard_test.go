@@ -0,0 +1,150 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGetMetricCatalog(t *testing.T) {
+	all, err := GetMetricCatalog("")
+	if err != nil || len(all) != len(metricCatalog) {
+		t.Fatalf("expected the full catalog, got %d entries, %v", len(all), err)
+	}
+
+	etcd, err := GetMetricCatalog("ETCD")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, definition := range etcd {
+		if definition.Category != MetricCategoryEtcd {
+			t.Errorf("unexpected category %s for %s", definition.Category, definition.Name)
+		}
+	}
+
+	if _, err := GetMetricCatalog("storage"); err == nil || !strings.Contains(err.Error(), "autoscaler") {
+		t.Errorf("expected an error listing the categories, got %v", err)
+	}
+
+	for _, signal := range dashboardSignals {
+		if _, ok := LookupMetric(signal.Metric); !ok {
+			t.Errorf("dashboard metric %s is not in the catalog", signal.Metric)
+		}
+	}
+}
+
+func TestParseMetricsQuery_CatalogDefaultAggregation(t *testing.T) {
+	now := time.Now()
+	query, err := ParseMetricsQuery(map[string]interface{}{
+		"resource": testClusterResourceID,
+		"metrics":  "etcd_database_usage_percentage,apiserver_cpu_usage_percentage",
+	}, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if query.Aggregation != "Maximum" {
+		t.Errorf("expected the catalog default Maximum, got %s", query.Aggregation)
+	}
+
+	query, err = ParseMetricsQuery(map[string]interface{}{
+		"resource": testClusterResourceID,
+		"metrics":  "etcd_database_usage_percentage,node_cpu_usage_percentage",
+	}, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if query.Aggregation != "Average" {
+		t.Errorf("expected Average when catalog defaults differ, got %s", query.Aggregation)
+	}
+}
+
+func TestBuildClusterHealthDashboard(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	series := func(dimensions map[string]string, values ...float64) SeriesSummary {
+		summary := SeriesSummary{Dimensions: dimensions, Unit: "Percent"}
+		var points []MetricPoint
+		for i, value := range values {
+			points = append(points, MetricPoint{Time: now.Add(time.Duration(i-len(values)) * time.Minute), Value: value})
+		}
+		SummarizeSeries(&summary, points, DefaultAnomalySigma, 0)
+		return summary
+	}
+
+	var mu sync.Mutex
+	queried := map[string]*MetricsQuery{}
+	query := func(_ context.Context, q *MetricsQuery) (*MetricsResult, error) {
+		mu.Lock()
+		queried[q.Metrics[0]+q.Filter] = q
+		mu.Unlock()
+
+		switch q.Metrics[0] {
+		case "node_cpu_usage_percentage":
+			return &MetricsResult{Series: []SeriesSummary{
+				series(map[string]string{"node": "aks-nodepool1-0"}, 20, 25, 30),
+				series(map[string]string{"node": "aks-nodepool1-1"}, 85, 90, 88),
+			}}, nil
+		case "etcd_database_usage_percentage":
+			return &MetricsResult{Series: []SeriesSummary{series(nil, 92, 93)}}, nil
+		case "apiserver_cpu_usage_percentage":
+			return nil, fmt.Errorf("metric not supported")
+		case "cluster_autoscaler_unschedulable_pods_count":
+			return &MetricsResult{Series: []SeriesSummary{{}}}, nil
+		default:
+			return &MetricsResult{Series: []SeriesSummary{series(nil, 0, 0)}}, nil
+		}
+	}
+
+	dashboard := BuildClusterHealthDashboard(context.Background(), query, testClusterResourceID, 6*time.Hour, now)
+
+	if len(queried) != len(dashboardSignals) {
+		t.Errorf("expected %d queries, got %d", len(dashboardSignals), len(queried))
+	}
+	for _, q := range queried {
+		if q.Interval != "PT5M" || q.Timespan() != "2026-10-18T06:00:00Z/2026-10-18T12:00:00Z" {
+			t.Errorf("unexpected query window %s %s", q.Interval, q.Timespan())
+		}
+	}
+
+	panels := map[string]DashboardPanel{}
+	for _, panel := range dashboard.Panels {
+		panels[panel.Title] = panel
+	}
+
+	cpu := panels["Node CPU"]
+	if cpu.Status != PanelStatusWarning || cpu.Series != 2 || cpu.Worst["node"] != "aks-nodepool1-1" {
+		t.Errorf("expected the hottest node to drive a warning, got %+v", cpu)
+	}
+	if panels["etcd database"].Status != PanelStatusCritical {
+		t.Errorf("expected etcd database usage to be critical, got %+v", panels["etcd database"])
+	}
+	if apiserver := panels["API server CPU"]; apiserver.Status != PanelStatusUnknown || apiserver.Message != "metric not supported" {
+		t.Errorf("expected a query error to leave the panel unknown, got %+v", apiserver)
+	}
+	if unschedulable := panels["Unschedulable pods"]; unschedulable.Status != PanelStatusUnknown || !strings.Contains(unschedulable.Message, "No data") {
+		t.Errorf("expected a panel without data to be unknown, got %+v", unschedulable)
+	}
+	if panels["Pending pods"].Status != PanelStatusOK {
+		t.Errorf("expected no pending pods to be ok, got %+v", panels["Pending pods"])
+	}
+	if latency := panels["API server request latency (p99)"]; latency.Status != PanelStatusUnavailable || latency.SourceQuery == "" {
+		t.Errorf("expected the latency panel to point to Prometheus, got %+v", latency)
+	}
+
+	if dashboard.Status != PanelStatusCritical {
+		t.Errorf("expected the overall status to be critical, got %s", dashboard.Status)
+	}
+	want := map[string]string{
+		SignalSaturation: PanelStatusCritical,
+		SignalTraffic:    PanelStatusOK,
+		SignalErrors:     PanelStatusOK,
+		SignalLatency:    PanelStatusUnavailable,
+	}
+	for signal, status := range want {
+		if dashboard.GoldenSignals[signal] != status {
+			t.Errorf("expected %s to be %s, got %s", signal, status, dashboard.GoldenSignals[signal])
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package diagnostics
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Audit investigation questions
+const (
+	AuditQuestionActor       = "actor"        // All actions by a user or service account
+	AuditQuestionResource    = "resource"     // All changes to a resource
+	AuditQuestionDenied      = "denied"       // Requests rejected with 403 Forbidden
+	AuditQuestionExec        = "exec"         // exec, attach and port-forward into pods
+	AuditQuestionSecretReads = "secret_reads" // get, list and watch on secrets
+)
+
+// auditQuestions lists the supported questions and whether they need read requests, which
+// are only logged by the kube-audit category and not by kube-audit-admin
+var auditQuestions = map[string]bool{
+	AuditQuestionActor:       false,
+	AuditQuestionResource:    false,
+	AuditQuestionDenied:      false,
+	AuditQuestionExec:        false,
+	AuditQuestionSecretReads: true,
+}
+
+// mutatingVerbs are the audit verbs that change a resource
+var mutatingVerbs = []string{"create", "update", "patch", "delete", "deletecollection"}
+
+var (
+	// auditUserPattern matches user names, e-mail addresses, object IDs and service accounts
+	// such as system:serviceaccount:kube-system:default
+	auditUserPattern = regexp.MustCompile(`^[A-Za-z0-9@._:-]{1,256}$`)
+	// auditResourcePattern matches plural resource names such as deployments or clusterroles
+	auditResourcePattern = regexp.MustCompile(`^[a-z0-9.-]{1,63}$`)
+)
+
+// AuditFilters narrow an audit investigation
+type AuditFilters struct {
+	User      string // Username or service account that made the request
+	Resource  string // Plural resource type, e.g. deployments
+	Namespace string // Namespace of the object
+	Name      string // Name of the object
+	// AllowedNamespaces restricts results to these namespaces when no namespace is given
+	AllowedNamespaces []string
+}
+
+// AuditQueryBuilder builds summarized audit timeline queries for AKS clusters
+type AuditQueryBuilder struct {
+	question          string
+	filters           AuditFilters
+	maxRecords        int
+	clusterResourceID string
+	tableMode         TableMode
+	bucket            time.Duration
+}
+
+// NewAuditQueryBuilder creates a new audit query builder instance
+func NewAuditQueryBuilder(question string, filters AuditFilters, maxRecords int, clusterResourceID string, tableMode TableMode, bucket time.Duration) (*AuditQueryBuilder, error) {
+	if err := ValidateAuditQueryParams(question, filters, maxRecords, clusterResourceID, tableMode); err != nil {
+		return nil, fmt.Errorf("invalid audit query parameters: %w", err)
+	}
+	if bucket < time.Minute {
+		bucket = time.Minute
+	}
+
+	return &AuditQueryBuilder{
+		question:          question,
+		filters:           filters,
+		maxRecords:        maxRecords,
+		clusterResourceID: clusterResourceID,
+		tableMode:         tableMode,
+		bucket:            bucket,
+	}, nil
+}
+
+// ValidateAuditQueryParams validates all parameters for the audit query builder
+func ValidateAuditQueryParams(question string, filters AuditFilters, maxRecords int, clusterResourceID string, tableMode TableMode) error {
+	if _, ok := auditQuestions[question]; !ok {
+		return fmt.Errorf("unsupported question '%s'. Supported questions: %s", question, strings.Join(GetSupportedAuditQuestions(), ", "))
+	}
+	switch question {
+	case AuditQuestionActor:
+		if filters.User == "" {
+			return fmt.Errorf("question '%s' requires a user", question)
+		}
+	case AuditQuestionResource:
+		if filters.Resource == "" {
+			return fmt.Errorf("question '%s' requires a resource type", question)
+		}
+	}
+
+	if filters.User != "" && !auditUserPattern.MatchString(filters.User) {
+		return fmt.Errorf("invalid user '%s'", filters.User)
+	}
+	if filters.Resource != "" && !auditResourcePattern.MatchString(filters.Resource) {
+		return fmt.Errorf("invalid resource type '%s': use the plural lowercase name, e.g. deployments", filters.Resource)
+	}
+	if filters.Namespace != "" && !kubernetesNamePattern.MatchString(filters.Namespace) {
+		return fmt.Errorf("invalid namespace '%s': must be a valid Kubernetes name", filters.Namespace)
+	}
+	if filters.Name != "" && !kubernetesNamePattern.MatchString(filters.Name) {
+		return fmt.Errorf("invalid name '%s': must be a valid Kubernetes name", filters.Name)
+	}
+	for _, namespace := range filters.AllowedNamespaces {
+		if !kubernetesNamePattern.MatchString(namespace) {
+			return fmt.Errorf("invalid allowed namespace '%s': must be a valid Kubernetes name", namespace)
+		}
+	}
+
+	if maxRecords < MinMaxRecords || maxRecords > MaxMaxRecords {
+		return fmt.Errorf("maxRecords must be between %d and %d, got %d", MinMaxRecords, MaxMaxRecords, maxRecords)
+	}
+	if clusterResourceID == "" {
+		return fmt.Errorf("clusterResourceID cannot be empty")
+	}
+	if !azureResourceIDPattern.MatchString(clusterResourceID) {
+		return fmt.Errorf("invalid clusterResourceID format. Expected format: /subscriptions/{subscription-id}/resourceGroups/{resource-group}/providers/Microsoft.ContainerService/managedClusters/{cluster-name}")
+	}
+	if tableMode != AzureDiagnosticsMode && tableMode != ResourceSpecificMode {
+		return fmt.Errorf("invalid tableMode. Must be AzureDiagnosticsMode (%d) or ResourceSpecificMode (%d)", AzureDiagnosticsMode, ResourceSpecificMode)
+	}
+	return nil
+}
+
+// buildNormalizedEvents selects completed audit events of the cluster and extracts the same
+// columns from both table modes
+func (q *AuditQueryBuilder) buildNormalizedEvents(category string) string {
+	if q.tableMode == ResourceSpecificMode {
+		table := resourceSpecificTableMapping[category]
+		return fmt.Sprintf("%s | where _ResourceId == '%s' | where Stage == 'ResponseComplete'", table, strings.ToLower(q.clusterResourceID)) +
+			" | extend Actor = tostring(User.username), Action = tostring(Verb), Resource = tostring(ObjectRef.resource)," +
+			" Subresource = tostring(ObjectRef.subresource), Namespace = tostring(ObjectRef.namespace), Name = tostring(ObjectRef.name)," +
+			" Code = toint(ResponseStatus.code), SourceIP = tostring(SourceIps[0])"
+	}
+	return fmt.Sprintf("AzureDiagnostics | where Category == '%s' and ResourceId == '%s'", category, strings.ToUpper(q.clusterResourceID)) +
+		" | extend event = parse_json(log_s) | where tostring(event.stage) == 'ResponseComplete'" +
+		" | extend Actor = tostring(event.user.username), Action = tostring(event.verb), Resource = tostring(event.objectRef.resource)," +
+		" Subresource = tostring(event.objectRef.subresource), Namespace = tostring(event.objectRef.namespace), Name = tostring(event.objectRef.name)," +
+		" Code = toint(event.responseStatus.code), SourceIP = tostring(event.sourceIPs[0])"
+}
+
+// addQuestionFilter narrows the events to those answering the question
+func (q *AuditQueryBuilder) addQuestionFilter(query string) string {
+	switch q.question {
+	case AuditQuestionResource:
+		query += fmt.Sprintf(" | where Action in (%s)", quoteList(mutatingVerbs))
+	case AuditQuestionDenied:
+		query += " | where Code == 403"
+	case AuditQuestionExec:
+		query += " | where Resource == 'pods' and Subresource in ('exec', 'attach', 'portforward')"
+	case AuditQuestionSecretReads:
+		query += " | where Resource == 'secrets' and Action in ('get', 'list', 'watch')"
+	}
+	return query
+}
+
+// addFilters applies the optional user, object and namespace filters
+func (q *AuditQueryBuilder) addFilters(query string) string {
+	f := q.filters
+	if f.User != "" {
+		query += fmt.Sprintf(" | where Actor == '%s'", f.User)
+	}
+	if f.Resource != "" {
+		query += fmt.Sprintf(" | where Resource == '%s'", f.Resource)
+	}
+	switch {
+	case f.Namespace != "":
+		query += fmt.Sprintf(" | where Namespace == '%s'", f.Namespace)
+	case len(f.AllowedNamespaces) > 0:
+		query += fmt.Sprintf(" | where Namespace in (%s)", quoteList(f.AllowedNamespaces))
+	}
+	if f.Name != "" {
+		query += fmt.Sprintf(" | where Name == '%s'", f.Name)
+	}
+	return query
+}
+
+// addTimeline groups the events into a timeline of distinct actions per time bucket, keeping
+// the most recent rows and returning them in chronological order
+func (q *AuditQueryBuilder) addTimeline(query string) string {
+	query += fmt.Sprintf(" | summarize Requests = count(), FirstSeen = min(TimeGenerated), LastSeen = max(TimeGenerated),"+
+		" Codes = make_set(Code, 5), SourceIPs = make_set(SourceIP, 5)"+
+		" by TimeBucket = bin(TimeGenerated, %dm), Actor, Action, Resource, Subresource, Namespace, Name", int(q.bucket.Minutes()))
+	query += fmt.Sprintf(" | top %d by LastSeen desc", q.maxRecords)
+	query += " | order by TimeBucket asc, FirstSeen asc"
+	return query
+}
+
+// Build constructs the timeline query for an audit log category (kube-audit or kube-audit-admin)
+func (q *AuditQueryBuilder) Build(category string) (string, error) {
+	if !auditCategories[category] {
+		return "", fmt.Errorf("category '%s' is not an audit log category", category)
+	}
+	if auditQuestions[q.question] && category != "kube-audit" {
+		return "", fmt.Errorf("question '%s' needs read requests, which are only logged by the kube-audit category", q.question)
+	}
+
+	query := q.buildNormalizedEvents(category)
+	query = q.addQuestionFilter(query)
+	query = q.addFilters(query)
+	query = q.addTimeline(query)
+	return query, nil
+}
+
+// AuditQuestionNeedsReads reports whether a question can only be answered from kube-audit
+func AuditQuestionNeedsReads(question string) bool {
+	return auditQuestions[question]
+}
+
+// GetSupportedAuditQuestions returns the supported audit investigation questions
+func GetSupportedAuditQuestions() []string {
+	return []string{AuditQuestionActor, AuditQuestionResource, AuditQuestionDenied, AuditQuestionExec, AuditQuestionSecretReads}
+}
+
+// AuditTimelineBucket picks the timeline bucket size for a query range
+func AuditTimelineBucket(queryRange time.Duration) time.Duration {
+	switch {
+	case queryRange <= time.Hour:
+		return 5 * time.Minute
+	case queryRange <= 6*time.Hour:
+		return 15 * time.Minute
+	default:
+		return time.Hour
+	}
+}
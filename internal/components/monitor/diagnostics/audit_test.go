@@ -0,0 +1,149 @@
+package diagnostics
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuditQueryBuilder_Build(t *testing.T) {
+	tests := []struct {
+		name             string
+		question         string
+		filters          AuditFilters
+		tableMode        TableMode
+		category         string
+		expectedContains []string
+		notExpected      []string
+	}{
+		{
+			name:      "actions by a service account in resource-specific tables",
+			question:  AuditQuestionActor,
+			filters:   AuditFilters{User: "system:serviceaccount:ci:deployer"},
+			tableMode: ResourceSpecificMode,
+			category:  "kube-audit",
+			expectedContains: []string{
+				"AKSAudit | where _ResourceId == '" + strings.ToLower(testClusterResourceID) + "'",
+				"where Stage == 'ResponseComplete'",
+				"Actor = tostring(User.username)",
+				"where Actor == 'system:serviceaccount:ci:deployer'",
+				"by TimeBucket = bin(TimeGenerated, 15m)",
+				"top 100 by LastSeen desc",
+				"order by TimeBucket asc",
+			},
+		},
+		{
+			name:      "changes to a deployment in AzureDiagnostics",
+			question:  AuditQuestionResource,
+			filters:   AuditFilters{Resource: "deployments", Namespace: "shop", Name: "api"},
+			tableMode: AzureDiagnosticsMode,
+			category:  "kube-audit-admin",
+			expectedContains: []string{
+				"AzureDiagnostics | where Category == 'kube-audit-admin' and ResourceId == '" + strings.ToUpper(testClusterResourceID) + "'",
+				"extend event = parse_json(log_s)",
+				"where Action in ('create', 'update', 'patch', 'delete', 'deletecollection')",
+				"where Resource == 'deployments'",
+				"where Namespace == 'shop'",
+				"where Name == 'api'",
+			},
+		},
+		{
+			name:             "denied requests",
+			question:         AuditQuestionDenied,
+			tableMode:        ResourceSpecificMode,
+			category:         "kube-audit",
+			expectedContains: []string{"where Code == 403"},
+			notExpected:      []string{"where Actor =="},
+		},
+		{
+			name:             "exec into pods limited to allowed namespaces",
+			question:         AuditQuestionExec,
+			filters:          AuditFilters{AllowedNamespaces: []string{"shop"}},
+			tableMode:        ResourceSpecificMode,
+			category:         "kube-audit",
+			expectedContains: []string{"Subresource in ('exec', 'attach', 'portforward')", "where Namespace in ('shop')"},
+		},
+		{
+			name:             "secret reads",
+			question:         AuditQuestionSecretReads,
+			tableMode:        ResourceSpecificMode,
+			category:         "kube-audit",
+			expectedContains: []string{"where Resource == 'secrets' and Action in ('get', 'list', 'watch')"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder, err := NewAuditQueryBuilder(tt.question, tt.filters, 100, testClusterResourceID, tt.tableMode, 15*time.Minute)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			query, err := builder.Build(tt.category)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, expected := range tt.expectedContains {
+				if !strings.Contains(query, expected) {
+					t.Errorf("expected query to contain %q, got: %s", expected, query)
+				}
+			}
+			for _, unexpected := range tt.notExpected {
+				if strings.Contains(query, unexpected) {
+					t.Errorf("expected query not to contain %q, got: %s", unexpected, query)
+				}
+			}
+			if strings.Contains(query, `"`) {
+				t.Errorf("query must not contain double quotes: %s", query)
+			}
+		})
+	}
+}
+
+func TestAuditQueryBuilder_Validation(t *testing.T) {
+	tests := []struct {
+		name     string
+		question string
+		filters  AuditFilters
+		wantErr  string
+	}{
+		{"unknown question", "everything", AuditFilters{}, "unsupported question"},
+		{"actor without user", AuditQuestionActor, AuditFilters{}, "requires a user"},
+		{"resource without type", AuditQuestionResource, AuditFilters{}, "requires a resource type"},
+		{"injection in user", AuditQuestionActor, AuditFilters{User: "x' or 1==1 //"}, "invalid user"},
+		{"uppercase resource", AuditQuestionResource, AuditFilters{Resource: "Deployments"}, "invalid resource type"},
+		{"invalid namespace", AuditQuestionDenied, AuditFilters{Namespace: "Shop"}, "invalid namespace"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewAuditQueryBuilder(tt.question, tt.filters, 100, testClusterResourceID, ResourceSpecificMode, time.Hour)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	builder, err := NewAuditQueryBuilder(AuditQuestionSecretReads, AuditFilters{}, 100, testClusterResourceID, ResourceSpecificMode, time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := builder.Build("kube-audit-admin"); err == nil {
+		t.Error("expected secret reads to require the kube-audit category")
+	}
+	if _, err := builder.Build("kube-apiserver"); err == nil {
+		t.Error("expected a non-audit category to be rejected")
+	}
+}
+
+func TestAuditTimelineBucket(t *testing.T) {
+	tests := map[time.Duration]time.Duration{
+		30 * time.Minute: 5 * time.Minute,
+		6 * time.Hour:    15 * time.Minute,
+		24 * time.Hour:   time.Hour,
+	}
+	for queryRange, expected := range tests {
+		if got := AuditTimelineBucket(queryRange); got != expected {
+			t.Errorf("AuditTimelineBucket(%v) = %v, want %v", queryRange, got, expected)
+		}
+	}
+}
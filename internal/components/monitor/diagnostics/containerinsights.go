@@ -0,0 +1,327 @@
+package diagnostics
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Container Insights tables
+const (
+	TableContainerLogV2    = "ContainerLogV2"
+	TableKubePodInventory  = "KubePodInventory"
+	TableKubeEvents        = "KubeEvents"
+	TableKubeNodeInventory = "KubeNodeInventory"
+	TableInsightsMetrics   = "InsightsMetrics"
+)
+
+// MaxSearchTextLength limits the length of the free-text search filter
+const MaxSearchTextLength = 200
+
+// ContainerInsightsFilters are the optional filters of a Container Insights query. Every
+// value is validated before it is placed in the query, so no user input reaches KQL unchecked.
+type ContainerInsightsFilters struct {
+	Namespace string // Kubernetes namespace
+	Pod       string // Pod name, or involved object name for KubeEvents
+	Container string // Container name
+	Node      string // Node (Computer) name
+	Severity  string // Log level for ContainerLogV2, event type for KubeEvents
+	Status    string // Pod phase for KubePodInventory, node status for KubeNodeInventory
+	Search    string // Free-text search in log messages and event messages
+	Metric    string // Metric name for InsightsMetrics
+	// AllowedNamespaces restricts namespaced tables to these namespaces when no namespace is given
+	AllowedNamespaces []string
+}
+
+// containerInsightsTable describes which filters a table supports and how they map to columns
+type containerInsightsTable struct {
+	namespaceColumn string
+	podColumn       string
+	nodeColumn      string
+	// latestBy, when set, keeps only the latest inventory record per key
+	latestBy   string
+	projection string
+}
+
+// containerInsightsTables maps each supported table to its columns. Tables without a
+// namespace column are cluster scoped and reject namespace filters.
+var containerInsightsTables = map[string]containerInsightsTable{
+	TableContainerLogV2: {
+		namespaceColumn: "PodNamespace",
+		podColumn:       "PodName",
+		nodeColumn:      "Computer",
+		projection:      "TimeGenerated, PodNamespace, PodName, ContainerName, LogLevel, LogMessage, Computer",
+	},
+	TableKubePodInventory: {
+		namespaceColumn: "Namespace",
+		podColumn:       "Name",
+		nodeColumn:      "Computer",
+		latestBy:        "Namespace, Name, ContainerName",
+		projection:      "TimeGenerated, Namespace, Name, PodStatus, ContainerName, ContainerStatus, ContainerStatusReason, ContainerRestartCount, Computer",
+	},
+	TableKubeEvents: {
+		namespaceColumn: "Namespace",
+		podColumn:       "Name",
+		nodeColumn:      "Computer",
+		projection:      "TimeGenerated, Namespace, ObjectKind, Name, Reason, Message, KubeEventType, Count, FirstSeen, LastSeen",
+	},
+	TableKubeNodeInventory: {
+		nodeColumn: "Computer",
+		latestBy:   "Computer",
+		projection: "TimeGenerated, Computer, Status, KubeletVersion, KubeProxyVersion, Labels",
+	},
+	TableInsightsMetrics: {
+		nodeColumn: "Computer",
+		projection: "TimeGenerated, Computer, Namespace, Name, Val, Tags",
+	},
+}
+
+// validContainerLogLevels are the LogLevel values of ContainerLogV2
+var validContainerLogLevels = []string{"critical", "error", "warning", "info", "debug", "trace", "unknown"}
+
+// validKubeEventTypes are the KubeEventType values of KubeEvents
+var validKubeEventTypes = []string{"Normal", "Warning"}
+
+// validPodStatuses are the PodStatus values of KubePodInventory
+var validPodStatuses = []string{"Pending", "Running", "Succeeded", "Failed", "Unknown"}
+
+// validNodeStatuses are the Status values of KubeNodeInventory
+var validNodeStatuses = []string{"Ready", "NotReady", "Unknown"}
+
+var (
+	// kubernetesNamePattern matches DNS subdomain names used for namespaces, pods, containers and nodes
+	kubernetesNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9.]{0,251}[a-z0-9])?$`)
+	// metricNamePattern matches InsightsMetrics metric names such as cpuUsageNanoCores
+	metricNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.:/-]{1,128}$`)
+	// searchTextPattern excludes quotes, backslashes and shell metacharacters from text searches
+	searchTextPattern = regexp.MustCompile(`^[A-Za-z0-9 ._:/@=,#%+()\[\]-]+$`)
+)
+
+// ContainerInsightsQueryBuilder builds KQL queries for Container Insights tables
+type ContainerInsightsQueryBuilder struct {
+	table             string
+	filters           ContainerInsightsFilters
+	maxRecords        int
+	clusterResourceID string
+}
+
+// NewContainerInsightsQueryBuilder validates the table, filters and scope of a Container Insights query
+func NewContainerInsightsQueryBuilder(table string, filters ContainerInsightsFilters, maxRecords int, clusterResourceID string) (*ContainerInsightsQueryBuilder, error) {
+	if err := ValidateContainerInsightsParams(table, filters, maxRecords, clusterResourceID); err != nil {
+		return nil, fmt.Errorf("invalid Container Insights query parameters: %w", err)
+	}
+
+	return &ContainerInsightsQueryBuilder{
+		table:             table,
+		filters:           filters,
+		maxRecords:        maxRecords,
+		clusterResourceID: clusterResourceID,
+	}, nil
+}
+
+// ValidateContainerInsightsParams validates all parameters for the Container Insights query builder
+func ValidateContainerInsightsParams(table string, filters ContainerInsightsFilters, maxRecords int, clusterResourceID string) error {
+	spec, ok := containerInsightsTables[table]
+	if !ok {
+		return fmt.Errorf("unsupported table '%s'. Supported tables: %s", table, strings.Join(GetSupportedContainerInsightsTables(), ", "))
+	}
+
+	if maxRecords < MinMaxRecords || maxRecords > MaxMaxRecords {
+		return fmt.Errorf("maxRecords must be between %d and %d, got %d", MinMaxRecords, MaxMaxRecords, maxRecords)
+	}
+
+	if clusterResourceID == "" {
+		return fmt.Errorf("clusterResourceID cannot be empty")
+	}
+	if !azureResourceIDPattern.MatchString(clusterResourceID) {
+		return fmt.Errorf("invalid clusterResourceID format. Expected format: /subscriptions/{subscription-id}/resourceGroups/{resource-group}/providers/Microsoft.ContainerService/managedClusters/{cluster-name}")
+	}
+
+	names := map[string]string{
+		"namespace": filters.Namespace,
+		"pod":       filters.Pod,
+		"container": filters.Container,
+		"node":      filters.Node,
+	}
+	for name, value := range names {
+		if value != "" && !kubernetesNamePattern.MatchString(value) {
+			return fmt.Errorf("invalid %s '%s': must be a valid Kubernetes name", name, value)
+		}
+	}
+	for _, namespace := range filters.AllowedNamespaces {
+		if !kubernetesNamePattern.MatchString(namespace) {
+			return fmt.Errorf("invalid allowed namespace '%s': must be a valid Kubernetes name", namespace)
+		}
+	}
+
+	if spec.namespaceColumn == "" && filters.Namespace != "" {
+		return fmt.Errorf("table %s is not namespaced and does not support a namespace filter", table)
+	}
+	if filters.Pod != "" && spec.podColumn == "" {
+		return fmt.Errorf("table %s does not support a pod filter", table)
+	}
+	if filters.Container != "" && table != TableContainerLogV2 && table != TableKubePodInventory {
+		return fmt.Errorf("table %s does not support a container filter", table)
+	}
+
+	if filters.Severity != "" {
+		switch table {
+		case TableContainerLogV2:
+			if !slices.Contains(validContainerLogLevels, strings.ToLower(filters.Severity)) {
+				return fmt.Errorf("invalid severity '%s'. Valid levels: %s", filters.Severity, strings.Join(validContainerLogLevels, ", "))
+			}
+		case TableKubeEvents:
+			if !containsFold(validKubeEventTypes, filters.Severity) {
+				return fmt.Errorf("invalid severity '%s'. Valid event types: %s", filters.Severity, strings.Join(validKubeEventTypes, ", "))
+			}
+		default:
+			return fmt.Errorf("table %s does not support a severity filter", table)
+		}
+	}
+
+	if filters.Status != "" {
+		switch table {
+		case TableKubePodInventory:
+			if !containsFold(validPodStatuses, filters.Status) {
+				return fmt.Errorf("invalid status '%s'. Valid pod statuses: %s", filters.Status, strings.Join(validPodStatuses, ", "))
+			}
+		case TableKubeNodeInventory:
+			if !containsFold(validNodeStatuses, filters.Status) {
+				return fmt.Errorf("invalid status '%s'. Valid node statuses: %s", filters.Status, strings.Join(validNodeStatuses, ", "))
+			}
+		default:
+			return fmt.Errorf("table %s does not support a status filter", table)
+		}
+	}
+
+	if filters.Search != "" {
+		if table != TableContainerLogV2 && table != TableKubeEvents {
+			return fmt.Errorf("table %s does not support a text search", table)
+		}
+		if len(filters.Search) > MaxSearchTextLength {
+			return fmt.Errorf("search text cannot exceed %d characters", MaxSearchTextLength)
+		}
+		if !searchTextPattern.MatchString(filters.Search) {
+			return fmt.Errorf("search text may only contain letters, digits, spaces and ._:/@=,#%%+()[]- characters")
+		}
+	}
+
+	if filters.Metric != "" {
+		if table != TableInsightsMetrics {
+			return fmt.Errorf("table %s does not support a metric filter", table)
+		}
+		if !metricNamePattern.MatchString(filters.Metric) {
+			return fmt.Errorf("invalid metric name '%s'", filters.Metric)
+		}
+	}
+
+	return nil
+}
+
+// Build constructs the complete KQL query
+func (q *ContainerInsightsQueryBuilder) Build() string {
+	spec := containerInsightsTables[q.table]
+	f := q.filters
+
+	// Container Insights stores the cluster resource ID with varying case, so compare case-insensitively
+	query := fmt.Sprintf("%s | where _ResourceId =~ '%s'", q.table, q.clusterResourceID)
+
+	if spec.namespaceColumn != "" {
+		switch {
+		case f.Namespace != "":
+			query += fmt.Sprintf(" | where %s == '%s'", spec.namespaceColumn, f.Namespace)
+		case len(f.AllowedNamespaces) > 0:
+			query += fmt.Sprintf(" | where %s in (%s)", spec.namespaceColumn, quoteList(f.AllowedNamespaces))
+		}
+	}
+	if f.Pod != "" {
+		query += fmt.Sprintf(" | where %s == '%s'", spec.podColumn, f.Pod)
+	}
+	if f.Node != "" {
+		query += fmt.Sprintf(" | where %s == '%s'", spec.nodeColumn, f.Node)
+	}
+
+	switch q.table {
+	case TableContainerLogV2:
+		if f.Container != "" {
+			query += fmt.Sprintf(" | where ContainerName == '%s'", f.Container)
+		}
+		if f.Severity != "" {
+			query += fmt.Sprintf(" | where LogLevel =~ '%s'", strings.ToLower(f.Severity))
+		}
+		if f.Search != "" {
+			query += fmt.Sprintf(" | where tostring(LogMessage) contains '%s'", f.Search)
+		}
+	case TableKubePodInventory:
+		// ContainerName is stored as {pod-uid}/{container-name}
+		if f.Container != "" {
+			query += fmt.Sprintf(" | where ContainerName endswith '/%s'", f.Container)
+		}
+		if f.Status != "" {
+			query += fmt.Sprintf(" | where PodStatus =~ '%s'", f.Status)
+		}
+	case TableKubeEvents:
+		if f.Severity != "" {
+			query += fmt.Sprintf(" | where KubeEventType =~ '%s'", f.Severity)
+		}
+		if f.Search != "" {
+			query += fmt.Sprintf(" | where Message contains '%s'", f.Search)
+		}
+	case TableKubeNodeInventory:
+		if f.Status != "" {
+			query += fmt.Sprintf(" | where Status =~ '%s'", f.Status)
+		}
+	case TableInsightsMetrics:
+		if f.Metric != "" {
+			query += fmt.Sprintf(" | where Name == '%s'", f.Metric)
+		}
+	}
+
+	// Inventory tables record the state every minute; keep only the latest record per object
+	if spec.latestBy != "" {
+		query += fmt.Sprintf(" | summarize arg_max(TimeGenerated, *) by %s", spec.latestBy)
+	}
+
+	query += " | order by TimeGenerated desc"
+	query += fmt.Sprintf(" | limit %d", q.maxRecords)
+	query += " | project " + spec.projection
+	return query
+}
+
+// BuildContainerInsightsQuery builds a pre-validated KQL query against a Container Insights
+// table, scoped to a specific AKS cluster
+func BuildContainerInsightsQuery(table string, filters ContainerInsightsFilters, maxRecords int, clusterResourceID string) (string, error) {
+	builder, err := NewContainerInsightsQueryBuilder(table, filters, maxRecords, clusterResourceID)
+	if err != nil {
+		return "", err
+	}
+	return builder.Build(), nil
+}
+
+// GetSupportedContainerInsightsTables returns the tables supported by the query builder
+func GetSupportedContainerInsightsTables() []string {
+	return []string{TableContainerLogV2, TableKubePodInventory, TableKubeEvents, TableKubeNodeInventory, TableInsightsMetrics}
+}
+
+// IsNamespacedContainerInsightsTable reports whether a table holds namespaced data
+func IsNamespacedContainerInsightsTable(table string) bool {
+	return containerInsightsTables[table].namespaceColumn != ""
+}
+
+func quoteList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = "'" + value + "'"
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
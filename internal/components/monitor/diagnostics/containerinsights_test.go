@@ -0,0 +1,184 @@
+package diagnostics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/security"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+)
+
+const testClusterResourceID = "/subscriptions/test/resourceGroups/rg/providers/Microsoft.ContainerService/managedClusters/cluster"
+
+func TestBuildContainerInsightsQuery(t *testing.T) {
+	tests := []struct {
+		name             string
+		table            string
+		filters          ContainerInsightsFilters
+		expectedContains []string
+		notExpected      []string
+	}{
+		{
+			name:  "container logs with all filters",
+			table: TableContainerLogV2,
+			filters: ContainerInsightsFilters{
+				Namespace: "shop", Pod: "api-7d9f", Container: "api", Severity: "Error", Search: "connection refused",
+			},
+			expectedContains: []string{
+				"ContainerLogV2 | where _ResourceId =~ '" + testClusterResourceID + "'",
+				"where PodNamespace == 'shop'",
+				"where PodName == 'api-7d9f'",
+				"where ContainerName == 'api'",
+				"where LogLevel =~ 'error'",
+				"where tostring(LogMessage) contains 'connection refused'",
+				"| limit 50",
+				"| project TimeGenerated, PodNamespace, PodName, ContainerName, LogLevel, LogMessage, Computer",
+			},
+		},
+		{
+			name:    "pod inventory keeps the latest record",
+			table:   TableKubePodInventory,
+			filters: ContainerInsightsFilters{Container: "api", Status: "pending"},
+			expectedContains: []string{
+				"where ContainerName endswith '/api'",
+				"where PodStatus =~ 'pending'",
+				"summarize arg_max(TimeGenerated, *) by Namespace, Name, ContainerName",
+			},
+			notExpected: []string{"where Namespace"},
+		},
+		{
+			name:    "namespaced table restricted to allowed namespaces",
+			table:   TableKubeEvents,
+			filters: ContainerInsightsFilters{Severity: "Warning", AllowedNamespaces: []string{"shop", "default"}},
+			expectedContains: []string{
+				"where Namespace in ('shop', 'default')",
+				"where KubeEventType =~ 'Warning'",
+			},
+		},
+		{
+			name:             "explicit namespace takes precedence over the allow list",
+			table:            TableKubeEvents,
+			filters:          ContainerInsightsFilters{Namespace: "shop", AllowedNamespaces: []string{"shop", "default"}},
+			expectedContains: []string{"where Namespace == 'shop'"},
+			notExpected:      []string{" in ("},
+		},
+		{
+			name:             "node inventory ignores the allow list",
+			table:            TableKubeNodeInventory,
+			filters:          ContainerInsightsFilters{Node: "aks-nodepool1-123-vmss000000", AllowedNamespaces: []string{"shop"}},
+			expectedContains: []string{"where Computer == 'aks-nodepool1-123-vmss000000'", "summarize arg_max(TimeGenerated, *) by Computer"},
+			notExpected:      []string{"Namespace"},
+		},
+		{
+			name:             "insights metrics by name",
+			table:            TableInsightsMetrics,
+			filters:          ContainerInsightsFilters{Metric: "cpuUsageNanoCores"},
+			expectedContains: []string{"where Name == 'cpuUsageNanoCores'", "project TimeGenerated, Computer, Namespace, Name, Val, Tags"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := BuildContainerInsightsQuery(tt.table, tt.filters, 50, testClusterResourceID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, expected := range tt.expectedContains {
+				if !strings.Contains(query, expected) {
+					t.Errorf("expected query to contain %q, got: %s", expected, query)
+				}
+			}
+			for _, unexpected := range tt.notExpected {
+				if strings.Contains(query, unexpected) {
+					t.Errorf("expected query not to contain %q, got: %s", unexpected, query)
+				}
+			}
+			if strings.Contains(query, `"`) {
+				t.Errorf("query must not contain double quotes: %s", query)
+			}
+		})
+	}
+}
+
+func TestBuildContainerInsightsQuery_RejectsUnsafeInput(t *testing.T) {
+	tests := []struct {
+		name    string
+		table   string
+		filters ContainerInsightsFilters
+		wantErr string
+	}{
+		{"unknown table", "SecurityEvent", ContainerInsightsFilters{}, "unsupported table"},
+		{"quote in namespace", TableContainerLogV2, ContainerInsightsFilters{Namespace: "shop' or 1==1"}, "invalid namespace"},
+		{"quote in search", TableContainerLogV2, ContainerInsightsFilters{Search: "x' | take 1"}, "search text"},
+		{"double quote in search", TableKubeEvents, ContainerInsightsFilters{Search: `say "hi"`}, "search text"},
+		{"search too long", TableContainerLogV2, ContainerInsightsFilters{Search: strings.Repeat("a", MaxSearchTextLength+1)}, "cannot exceed"},
+		{"namespace on cluster scoped table", TableKubeNodeInventory, ContainerInsightsFilters{Namespace: "shop"}, "not namespaced"},
+		{"invalid severity", TableContainerLogV2, ContainerInsightsFilters{Severity: "fatal"}, "invalid severity"},
+		{"severity on inventory", TableKubePodInventory, ContainerInsightsFilters{Severity: "error"}, "does not support a severity filter"},
+		{"invalid pod status", TableKubePodInventory, ContainerInsightsFilters{Status: "Crashing"}, "invalid status"},
+		{"metric on logs", TableContainerLogV2, ContainerInsightsFilters{Metric: "cpu"}, "does not support a metric filter"},
+		{"invalid allowed namespace", TableKubeEvents, ContainerInsightsFilters{AllowedNamespaces: []string{"a'b"}}, "invalid allowed namespace"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := BuildContainerInsightsQuery(tt.table, tt.filters, 50, testClusterResourceID)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	if _, err := BuildContainerInsightsQuery(TableContainerLogV2, ContainerInsightsFilters{}, MaxMaxRecords+1, testClusterResourceID); err == nil {
+		t.Error("expected error for maxRecords above the limit")
+	}
+	if _, err := BuildContainerInsightsQuery(TableContainerLogV2, ContainerInsightsFilters{}, 50, "/subscriptions/test"); err == nil {
+		t.Error("expected error for an invalid cluster resource ID")
+	}
+}
+
+func TestHandleContainerInsightsQuery_EnforcesAllowedNamespaces(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.SecurityConfig = &security.SecurityConfig{AccessLevel: "readonly", AllowedNamespaces: "shop,default"}
+
+	params := map[string]interface{}{
+		"subscription_id": "test",
+		"resource_group":  "rg",
+		"cluster_name":    "cluster",
+		"namespace":       "kube-system",
+	}
+	_, err := HandleContainerInsightsQuery(TableContainerLogV2, params, nil, cfg)
+	if err == nil || !strings.Contains(err.Error(), "not allowed") {
+		t.Errorf("expected namespace to be rejected, got %v", err)
+	}
+
+	delete(params, "namespace")
+	_, err = HandleContainerInsightsQuery(TableInsightsMetrics, params, nil, cfg)
+	if err == nil || !strings.Contains(err.Error(), "has no namespace column") {
+		t.Errorf("expected InsightsMetrics to be rejected, got %v", err)
+	}
+}
+
+func TestMonitoringAddonWorkspace(t *testing.T) {
+	workspaceID := "/subscriptions/test/resourceGroups/rg/providers/Microsoft.OperationalInsights/workspaces/ws"
+	cluster := &armcontainerservice.ManagedCluster{
+		Properties: &armcontainerservice.ManagedClusterProperties{
+			AddonProfiles: map[string]*armcontainerservice.ManagedClusterAddonProfile{
+				"omsAgent": {
+					Enabled: to.Ptr(true),
+					Config:  map[string]*string{"logAnalyticsWorkspaceResourceID": to.Ptr(workspaceID)},
+				},
+			},
+		},
+	}
+	if got := MonitoringAddonWorkspace(cluster); got != workspaceID {
+		t.Errorf("expected %s, got %q", workspaceID, got)
+	}
+
+	cluster.Properties.AddonProfiles["omsAgent"].Enabled = to.Ptr(false)
+	if got := MonitoringAddonWorkspace(cluster); got != "" {
+		t.Errorf("expected no workspace for a disabled addon, got %q", got)
+	}
+}
@@ -0,0 +1,137 @@
+package diagnostics
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/config"
+)
+
+// MaxDeprecatedAPIUsageRows limits the number of API/client combinations returned
+const MaxDeprecatedAPIUsageRows = 200
+
+// DeprecatedAPIUsage is a deprecated Kubernetes API called by a client, summarized from kube-audit logs
+type DeprecatedAPIUsage struct {
+	APIGroup       string `json:"api_group,omitempty"`
+	APIVersion     string `json:"api_version"`
+	Resource       string `json:"resource"`
+	RemovedRelease string `json:"removed_release,omitempty"`
+	UserAgent      string `json:"user_agent,omitempty"`
+	Count          int    `json:"count"`
+	LastSeen       string `json:"last_seen,omitempty"`
+}
+
+// GroupVersion returns the API group and version in apiVersion form, e.g. "policy/v1beta1"
+func (u DeprecatedAPIUsage) GroupVersion() string {
+	if u.APIGroup == "" {
+		return u.APIVersion
+	}
+	return u.APIGroup + "/" + u.APIVersion
+}
+
+// BuildDeprecatedAPIUsageQuery builds a KQL query that summarizes requests the API server
+// annotated with k8s.io/deprecated, scoped to a single AKS cluster
+func BuildDeprecatedAPIUsageQuery(category, clusterResourceID string, isResourceSpecific bool) (string, error) {
+	if !auditCategories[category] {
+		return "", fmt.Errorf("category '%s' is not an audit log category", category)
+	}
+	if !azureResourceIDPattern.MatchString(clusterResourceID) {
+		return "", fmt.Errorf("invalid clusterResourceID format: %s", clusterResourceID)
+	}
+
+	var query string
+	if isResourceSpecific {
+		query = fmt.Sprintf("%s | where _ResourceId == '%s'", resourceSpecificTableMapping[category], strings.ToLower(clusterResourceID)) +
+			" | where tostring(Annotations['k8s.io/deprecated']) == 'true'" +
+			" | extend ApiGroup = tostring(ObjectRef.apiGroup), ApiVersion = tostring(ObjectRef.apiVersion), Resource = tostring(ObjectRef.resource)," +
+			" RemovedRelease = tostring(Annotations['k8s.io/removed-release'])"
+	} else {
+		query = fmt.Sprintf("AzureDiagnostics | where Category == '%s' and ResourceId == '%s'", category, strings.ToUpper(clusterResourceID)) +
+			" | where log_s has 'k8s.io/deprecated'" +
+			" | extend Event = parse_json(log_s)" +
+			" | where tostring(Event.annotations['k8s.io/deprecated']) == 'true'" +
+			" | extend ApiGroup = tostring(Event.objectRef.apiGroup), ApiVersion = tostring(Event.objectRef.apiVersion), Resource = tostring(Event.objectRef.resource)," +
+			" RemovedRelease = tostring(Event.annotations['k8s.io/removed-release']), UserAgent = tostring(Event.userAgent)"
+	}
+
+	query += " | summarize Count = count(), LastSeen = max(TimeGenerated) by ApiGroup, ApiVersion, Resource, RemovedRelease, UserAgent" +
+		" | order by Count desc" +
+		fmt.Sprintf(" | limit %d", MaxDeprecatedAPIUsageRows)
+	return query, nil
+}
+
+// QueryDeprecatedAPIUsage reads deprecated API usage from the cluster's audit logs in Log Analytics.
+// It uses kube-audit when it is exported and falls back to kube-audit-admin, which does not
+// include read requests. The category that was queried is returned with the results.
+func QueryDeprecatedAPIUsage(subscriptionID, resourceGroup, clusterName string, lookback time.Duration, azClient *azureclient.AzureClient, cfg *config.ConfigData) ([]DeprecatedAPIUsage, string, error) {
+	var (
+		category            string
+		workspaceResourceID string
+		isResourceSpecific  bool
+		lastErr             error
+	)
+	for _, candidate := range []string{"kube-audit", "kube-audit-admin"} {
+		workspaceResourceID, isResourceSpecific, lastErr = FindDiagnosticSettingForCategory(subscriptionID, resourceGroup, clusterName, candidate, azClient, cfg)
+		if lastErr == nil {
+			category = candidate
+			break
+		}
+	}
+	if category == "" {
+		return nil, "", fmt.Errorf("no diagnostic setting exports audit logs to Log Analytics: %w", lastErr)
+	}
+
+	kqlQuery, err := BuildDeprecatedAPIUsageQuery(category, buildClusterResourceID(subscriptionID, resourceGroup, clusterName), isResourceSpecific)
+	if err != nil {
+		return nil, category, err
+	}
+
+	end := time.Now().UTC()
+	timespan := fmt.Sprintf("%s/%s", end.Add(-lookback).Format(time.RFC3339), end.Format(time.RFC3339))
+
+	result, err := queryWorkspace(azClient, workspaceResourceID, kqlQuery, timespan)
+	if err != nil {
+		return nil, category, fmt.Errorf("failed to query %s logs: %w", category, err)
+	}
+
+	return parseDeprecatedAPIUsage(azureclient.LogsRowMaps(azureclient.PrimaryLogsTable(result))), category, nil
+}
+
+// parseDeprecatedAPIUsage converts the rows of the deprecated API usage query
+func parseDeprecatedAPIUsage(rows []map[string]interface{}) []DeprecatedAPIUsage {
+	usage := make([]DeprecatedAPIUsage, 0, len(rows))
+	for _, row := range rows {
+		usage = append(usage, DeprecatedAPIUsage{
+			APIGroup:       rowString(row, "ApiGroup"),
+			APIVersion:     rowString(row, "ApiVersion"),
+			Resource:       rowString(row, "Resource"),
+			RemovedRelease: rowString(row, "RemovedRelease"),
+			UserAgent:      rowString(row, "UserAgent"),
+			Count:          rowInt(row, "Count"),
+			LastSeen:       rowString(row, "LastSeen"),
+		})
+	}
+	return usage
+}
+
+func rowString(row map[string]interface{}, column string) string {
+	if value, ok := row[column]; ok && value != nil {
+		return fmt.Sprint(value)
+	}
+	return ""
+}
+
+// rowInt reads a numeric column, which may be returned as a number or a string
+func rowInt(row map[string]interface{}, column string) int {
+	switch v := row[column].(type) {
+	case float64:
+		return int(v)
+	case string:
+		n, _ := strconv.Atoi(v)
+		return n
+	}
+	return 0
+}
@@ -0,0 +1,51 @@
+package diagnostics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildDeprecatedAPIUsageQuery(t *testing.T) {
+	clusterID := "/subscriptions/sub-1/resourceGroups/rg-1/providers/Microsoft.ContainerService/managedClusters/aks-1"
+
+	query, err := BuildDeprecatedAPIUsageQuery("kube-audit", clusterID, true)
+	if err != nil {
+		t.Fatalf("BuildDeprecatedAPIUsageQuery returned error: %v", err)
+	}
+	for _, want := range []string{"AKSAudit | where _ResourceId == '" + strings.ToLower(clusterID) + "'", "Annotations['k8s.io/deprecated']", "summarize Count = count()"} {
+		if !strings.Contains(query, want) {
+			t.Errorf("Expected resource-specific query to contain %q, got %s", want, query)
+		}
+	}
+
+	query, err = BuildDeprecatedAPIUsageQuery("kube-audit-admin", clusterID, false)
+	if err != nil {
+		t.Fatalf("BuildDeprecatedAPIUsageQuery returned error: %v", err)
+	}
+	if !strings.HasPrefix(query, "AzureDiagnostics | where Category == 'kube-audit-admin' and ResourceId == '"+strings.ToUpper(clusterID)+"'") {
+		t.Errorf("Unexpected AzureDiagnostics query: %s", query)
+	}
+
+	if _, err := BuildDeprecatedAPIUsageQuery("kube-apiserver", clusterID, true); err == nil {
+		t.Error("Expected an error for a non-audit category")
+	}
+	if _, err := BuildDeprecatedAPIUsageQuery("kube-audit", "not-a-cluster", true); err == nil {
+		t.Error("Expected an error for an invalid cluster ID")
+	}
+}
+
+func TestParseDeprecatedAPIUsage(t *testing.T) {
+	rows := []map[string]interface{}{{
+		"ApiGroup": "policy", "ApiVersion": "v1beta1", "Resource": "poddisruptionbudgets", "RemovedRelease": "1.25",
+		"UserAgent": "helm/v3", "Count": float64(42), "LastSeen": "2024-05-01T10:00:00Z",
+	}}
+
+	usage := parseDeprecatedAPIUsage(rows)
+	if len(usage) != 1 || usage[0].Count != 42 || usage[0].GroupVersion() != "policy/v1beta1" || usage[0].UserAgent != "helm/v3" {
+		t.Errorf("Unexpected usage: %+v", usage)
+	}
+
+	if usage := parseDeprecatedAPIUsage(nil); len(usage) != 0 {
+		t.Errorf("Expected no usage for an empty result, got %+v", usage)
+	}
+}
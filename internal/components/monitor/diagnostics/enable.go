@@ -0,0 +1,475 @@
+package diagnostics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/dryrun"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+)
+
+const (
+	// DefaultDiagnosticSettingName names the diagnostic setting created by diagnostics_enable
+	DefaultDiagnosticSettingName = "aks-mcp-control-plane-logs"
+	// maxDiagnosticSettingsPerResource is the Azure Monitor limit of diagnostic settings per resource
+	maxDiagnosticSettingsPerResource = 5
+	// dedicatedDestinationType sends logs to resource-specific tables such as AKSAudit
+	dedicatedDestinationType = "Dedicated"
+)
+
+// categoryGroupMembers lists the categories covered by the diagnostic setting category groups
+var categoryGroupMembers = map[string][]string{
+	"audit": {"kube-audit", "kube-audit-admin", "guard"},
+}
+
+// ingestionEstimate is a rough daily ingestion volume of a log category for a 10-node cluster
+type ingestionEstimate struct {
+	gbPerDay float64
+	volume   string
+	note     string
+}
+
+// categoryIngestionEstimates are rule-of-thumb volumes; actual ingestion depends on API traffic
+var categoryIngestionEstimates = map[string]ingestionEstimate{
+	"kube-audit":                          {3.0, "high", "Logs every request including get, list and watch; kube-audit-admin is usually enough unless reads matter"},
+	"kube-audit-admin":                    {0.6, "medium", "Leaves out read requests"},
+	"kube-apiserver":                      {0.8, "medium", ""},
+	"kube-controller-manager":             {0.2, "low", ""},
+	"kube-scheduler":                      {0.05, "low", ""},
+	"cluster-autoscaler":                  {0.1, "low", ""},
+	"cloud-controller-manager":            {0.1, "low", ""},
+	"guard":                               {0.2, "low", "Only logs when Microsoft Entra ID integration is enabled"},
+	"csi-azuredisk-controller":            {0.02, "low", ""},
+	"csi-azurefile-controller":            {0.02, "low", ""},
+	"csi-snapshot-controller":             {0.02, "low", ""},
+	"fleet-member-agent":                  {0.02, "low", "Only logs on Fleet member clusters"},
+	"fleet-member-net-controller-manager": {0.02, "low", "Only logs on Fleet member clusters"},
+	"fleet-mcs-controller-manager":        {0.02, "low", "Only logs on Fleet member clusters"},
+}
+
+// CategoryIngestionImpact is the estimated ingestion of a newly enabled log category
+type CategoryIngestionImpact struct {
+	Category          string  `json:"category"`
+	Table             string  `json:"table"`
+	Volume            string  `json:"volume"`
+	EstimatedGBPerDay float64 `json:"estimated_gb_per_day"`
+	Note              string  `json:"note,omitempty"`
+}
+
+// DiagnosticSettingPlan is the diagnostic setting change that enables log categories
+type DiagnosticSettingPlan struct {
+	SettingName        string
+	Create             bool
+	Setting            armmonitor.DiagnosticSettingsResource
+	CurrentCategories  []string
+	EnabledCategories  []string
+	AlreadyEnabled     []string
+	CurrentDestination string
+	// CurrentWorkspace is the workspace an updated setting sends to today
+	CurrentWorkspace    string
+	Impact              []CategoryIngestionImpact
+	Notes               []string
+	WorkspaceResourceID string
+	// Conversions describe how updating an existing setting changes where its logs already
+	// go. Queries and alerts reading them there stop seeing new data.
+	Conversions []string
+}
+
+// MovesWorkspace reports whether the plan sends an existing setting's logs to another workspace
+func (p *DiagnosticSettingPlan) MovesWorkspace() bool {
+	return p.CurrentWorkspace != "" && !strings.EqualFold(p.CurrentWorkspace, p.WorkspaceResourceID)
+}
+
+// ConvertsDestination reports whether the plan moves logs a setting sends to a workspace from
+// the AzureDiagnostics table to resource-specific tables
+func (p *DiagnosticSettingPlan) ConvertsDestination() bool {
+	return p.CurrentWorkspace != "" && len(p.CurrentCategories) > 0 && !strings.EqualFold(p.CurrentDestination, dedicatedDestinationType)
+}
+
+// CheckConversion refuses a plan that converts an existing setting unless convertExisting is set
+func (p *DiagnosticSettingPlan) CheckConversion(convertExisting bool) error {
+	if len(p.Conversions) == 0 || convertExisting {
+		return nil
+	}
+	msg := fmt.Sprintf("enabling the categories would convert diagnostic setting '%s': %s. Pass convert_existing=true to convert it",
+		p.SettingName, strings.Join(p.Conversions, "; "))
+	if p.MovesWorkspace() {
+		msg += ", or a different setting_name to create a separate setting for the new categories"
+	}
+	return fmt.Errorf("%s", msg)
+}
+
+// Changed reports whether the plan changes the diagnostic setting
+func (p *DiagnosticSettingPlan) Changed() bool {
+	return p.Create || len(p.EnabledCategories) > 0 || !strings.EqualFold(p.CurrentDestination, dedicatedDestinationType)
+}
+
+// PlanDiagnosticSetting works out the diagnostic setting that routes the categories to the
+// workspace in resource-specific mode. A setting that already sends to the workspace is
+// updated, since Azure rejects two settings sending a category to the same workspace;
+// otherwise the setting named settingName is updated or created. Updating a setting that
+// sends to the AzureDiagnostics table or to another workspace is recorded in Conversions;
+// see CheckConversion. nodeCount scales the ingestion estimates and may be zero when unknown.
+func PlanDiagnosticSetting(existing []*armmonitor.DiagnosticSettingsResource, categories []string, workspaceResourceID, settingName string, nodeCount int) (*DiagnosticSettingPlan, error) {
+	if len(categories) == 0 {
+		return nil, fmt.Errorf("at least one log category is required. Valid categories: %s", strings.Join(controlPlaneLogCategories, ", "))
+	}
+	for _, category := range categories {
+		if !slices.Contains(controlPlaneLogCategories, category) {
+			return nil, fmt.Errorf("invalid log category: %s. Valid categories: %s", category, strings.Join(controlPlaneLogCategories, ", "))
+		}
+	}
+	if err := validateWorkspaceResourceID(workspaceResourceID); err != nil {
+		return nil, err
+	}
+	if settingName == "" {
+		settingName = DefaultDiagnosticSettingName
+	}
+
+	plan := &DiagnosticSettingPlan{SettingName: settingName, WorkspaceResourceID: workspaceResourceID}
+
+	target := findSettingForWorkspace(existing, workspaceResourceID)
+	if target == nil {
+		for _, setting := range existing {
+			if setting != nil && setting.Name != nil && strings.EqualFold(*setting.Name, settingName) {
+				target = setting
+				break
+			}
+		}
+	}
+
+	var properties armmonitor.DiagnosticSettings
+	if target == nil {
+		if len(existing) >= maxDiagnosticSettingsPerResource {
+			return nil, fmt.Errorf("the cluster already has %d diagnostic settings, the most Azure Monitor allows; pass setting_name to update one of them", len(existing))
+		}
+		plan.Create = true
+	} else {
+		plan.SettingName = *target.Name
+		if target.Properties != nil {
+			properties = *target.Properties
+			properties.Logs = slices.Clone(target.Properties.Logs)
+		}
+		if properties.WorkspaceID != nil {
+			plan.CurrentWorkspace = *properties.WorkspaceID
+		}
+		if properties.LogAnalyticsDestinationType != nil {
+			plan.CurrentDestination = *properties.LogAnalyticsDestinationType
+		}
+	}
+	plan.CurrentCategories = enabledCategories(properties.Logs)
+	if plan.MovesWorkspace() {
+		plan.Conversions = append(plan.Conversions, fmt.Sprintf("it sends to workspace %s today and all of its categories would move to %s", plan.CurrentWorkspace, workspaceResourceID))
+	}
+	if plan.ConvertsDestination() {
+		plan.Conversions = append(plan.Conversions, "its categories would switch from the AzureDiagnostics table to resource-specific tables, while data already collected stays in AzureDiagnostics")
+	}
+
+	scale := float64(nodeCount) / 10
+	if nodeCount <= 0 {
+		scale = 1
+		plan.Notes = append(plan.Notes, "The node count is unknown, so ingestion is estimated for a 10-node cluster")
+	}
+
+	for _, category := range categories {
+		if slices.Contains(plan.CurrentCategories, category) {
+			if !slices.Contains(plan.AlreadyEnabled, category) {
+				plan.AlreadyEnabled = append(plan.AlreadyEnabled, category)
+			}
+			continue
+		}
+		if slices.Contains(plan.EnabledCategories, category) {
+			continue
+		}
+		properties.Logs = enableCategory(properties.Logs, category)
+		plan.EnabledCategories = append(plan.EnabledCategories, category)
+
+		estimate := categoryIngestionEstimates[category]
+		plan.Impact = append(plan.Impact, CategoryIngestionImpact{
+			Category:          category,
+			Table:             resourceSpecificTable(category),
+			Volume:            estimate.volume,
+			EstimatedGBPerDay: math.Round(estimate.gbPerDay*scale*100) / 100,
+			Note:              estimate.note,
+		})
+	}
+
+	properties.WorkspaceID = to.Ptr(workspaceResourceID)
+	properties.LogAnalyticsDestinationType = to.Ptr(dedicatedDestinationType)
+	plan.Setting = armmonitor.DiagnosticSettingsResource{Properties: &properties}
+	return plan, nil
+}
+
+// findSettingForWorkspace returns the diagnostic setting that sends to the workspace
+func findSettingForWorkspace(settings []*armmonitor.DiagnosticSettingsResource, workspaceResourceID string) *armmonitor.DiagnosticSettingsResource {
+	for _, setting := range settings {
+		if setting == nil || setting.Name == nil || setting.Properties == nil || setting.Properties.WorkspaceID == nil {
+			continue
+		}
+		if strings.EqualFold(*setting.Properties.WorkspaceID, workspaceResourceID) {
+			return setting
+		}
+	}
+	return nil
+}
+
+// enabledCategories returns the sorted categories a setting has enabled, expanding category groups
+func enabledCategories(logs []*armmonitor.LogSettings) []string {
+	var categories []string
+	add := func(category string) {
+		if !slices.Contains(categories, category) {
+			categories = append(categories, category)
+		}
+	}
+	for _, logSetting := range logs {
+		if logSetting == nil || logSetting.Enabled == nil || !*logSetting.Enabled {
+			continue
+		}
+		switch {
+		case logSetting.Category != nil:
+			add(*logSetting.Category)
+		case logSetting.CategoryGroup != nil && strings.EqualFold(*logSetting.CategoryGroup, "allLogs"):
+			for _, category := range controlPlaneLogCategories {
+				add(category)
+			}
+		case logSetting.CategoryGroup != nil:
+			for _, category := range categoryGroupMembers[strings.ToLower(*logSetting.CategoryGroup)] {
+				add(category)
+			}
+		}
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+// enableCategory turns on a category, reusing a disabled entry for it when there is one
+func enableCategory(logs []*armmonitor.LogSettings, category string) []*armmonitor.LogSettings {
+	for i, logSetting := range logs {
+		if logSetting != nil && logSetting.Category != nil && *logSetting.Category == category {
+			updated := *logSetting
+			updated.Enabled = to.Ptr(true)
+			logs[i] = &updated
+			return logs
+		}
+	}
+	return append(logs, &armmonitor.LogSettings{Category: to.Ptr(category), Enabled: to.Ptr(true)})
+}
+
+// resourceSpecificTable returns the table a category is written to in resource-specific mode
+func resourceSpecificTable(category string) string {
+	if table, ok := resourceSpecificTableMapping[category]; ok {
+		return table
+	}
+	return "AKSControlPlane"
+}
+
+// DiagnosticsEnableResult reports the diagnostic setting change made by diagnostics_enable
+type DiagnosticsEnableResult struct {
+	SettingName         string                    `json:"setting_name"`
+	Created             bool                      `json:"created"`
+	Changed             bool                      `json:"changed"`
+	WorkspaceResourceID string                    `json:"workspace_resource_id"`
+	DestinationType     string                    `json:"destination_type"`
+	EnabledCategories   []string                  `json:"enabled_categories"`
+	AlreadyEnabled      []string                  `json:"already_enabled,omitempty"`
+	Impact              []CategoryIngestionImpact `json:"ingestion_impact,omitempty"`
+	Notes               []string                  `json:"notes,omitempty"`
+}
+
+// HandleDiagnosticsEnable creates or updates a diagnostic setting on an AKS cluster so the
+// requested control plane log categories are sent to a workspace in resource-specific mode.
+// It needs readwrite access unless dry_run is set, which only previews the change.
+func HandleDiagnosticsEnable(params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
+	subscriptionID, resourceGroup, clusterName, err := common.ExtractAKSParameters(params)
+	if err != nil {
+		return "", err
+	}
+
+	categories := parseCategories(params["categories"])
+	workspaceResourceID, _ := params["workspace_resource_id"].(string)
+	workspaceResourceID = strings.TrimSpace(workspaceResourceID)
+	settingName, _ := params["setting_name"].(string)
+	settingName = strings.TrimSpace(settingName)
+	convertExisting, _ := params["convert_existing"].(bool)
+	if workspaceResourceID == "" {
+		return "", fmt.Errorf("missing or invalid workspace_resource_id parameter")
+	}
+
+	accessLevel := ""
+	if cfg != nil {
+		accessLevel = cfg.AccessLevel
+	}
+	var accessErr error
+	if accessLevel != "readwrite" && accessLevel != "admin" {
+		accessErr = fmt.Errorf("operation 'diagnostics_enable' requires readwrite or admin access level")
+	}
+	dryRun := dryrun.Requested(params)
+	if accessErr != nil && !dryRun {
+		return "", accessErr
+	}
+
+	if azClient == nil {
+		return "", fmt.Errorf("azure client is required but not provided")
+	}
+
+	ctx := context.Background()
+	clusterResourceID := buildClusterResourceID(subscriptionID, resourceGroup, clusterName)
+	existing, err := azClient.GetDiagnosticSettings(ctx, subscriptionID, clusterResourceID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get diagnostic settings for cluster %s: %w", clusterName, err)
+	}
+
+	nodeCount := 0
+	if cluster, err := azClient.GetAKSCluster(ctx, subscriptionID, resourceGroup, clusterName); err == nil {
+		nodeCount = clusterNodeCount(cluster)
+	}
+
+	plan, err := PlanDiagnosticSetting(existing, categories, workspaceResourceID, settingName, nodeCount)
+	if err != nil {
+		return "", err
+	}
+
+	conversionErr := plan.CheckConversion(convertExisting)
+	if dryRun {
+		policyErr := accessErr
+		if policyErr == nil {
+			policyErr = conversionErr
+		}
+		return previewDiagnosticSetting(plan, clusterResourceID, dryrun.NewPolicy(accessLevel, policyErr)).JSON()
+	}
+	if conversionErr != nil {
+		return "", conversionErr
+	}
+
+	result := &DiagnosticsEnableResult{
+		SettingName:         plan.SettingName,
+		Created:             plan.Create,
+		Changed:             plan.Changed(),
+		WorkspaceResourceID: workspaceResourceID,
+		DestinationType:     dedicatedDestinationType,
+		EnabledCategories:   plan.EnabledCategories,
+		AlreadyEnabled:      plan.AlreadyEnabled,
+		Impact:              plan.Impact,
+		Notes:               append(slices.Clone(plan.Notes), plan.Conversions...),
+	}
+	if result.EnabledCategories == nil {
+		result.EnabledCategories = []string{}
+	}
+
+	if plan.Changed() {
+		if _, err := azClient.CreateOrUpdateDiagnosticSetting(ctx, subscriptionID, clusterResourceID, plan.SettingName, plan.Setting); err != nil {
+			return "", fmt.Errorf("failed to update diagnostic settings for cluster %s: %w", clusterName, err)
+		}
+		result.Notes = append(result.Notes, "New logs can take 5-15 minutes to appear in the workspace")
+	}
+
+	out, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal diagnostics_enable result: %w", err)
+	}
+	return string(out), nil
+}
+
+// previewDiagnosticSetting renders a plan as a dry-run preview. Converting an existing setting
+// is shown as breaking changes of its workspace and destination type.
+func previewDiagnosticSetting(plan *DiagnosticSettingPlan, clusterResourceID string, policy dryrun.Policy) *dryrun.Preview {
+	preview := dryrun.New("az_monitoring", "diagnostics_enable", policy)
+	preview.Request = &dryrun.Request{
+		Method:     "PUT",
+		API:        "DiagnosticSettingsClient.CreateOrUpdate",
+		ResourceID: clusterResourceID + "/providers/Microsoft.Insights/diagnosticSettings/" + plan.SettingName,
+		Body:       plan.Setting,
+	}
+	preview.AddChange("enabled categories", plan.CurrentCategories, mergeCategories(plan.CurrentCategories, plan.EnabledCategories))
+	if plan.MovesWorkspace() {
+		preview.AddBreakingChange("workspace", plan.CurrentWorkspace, plan.WorkspaceResourceID)
+	}
+	currentDestination := destinationTypeName(plan.CurrentDestination, plan.Create)
+	if plan.ConvertsDestination() {
+		preview.AddBreakingChange("destination type", currentDestination, dedicatedDestinationType)
+	} else {
+		preview.AddChange("destination type", currentDestination, dedicatedDestinationType)
+	}
+	for _, impact := range plan.Impact {
+		preview.AddNote(fmt.Sprintf("%s -> %s: about %.2f GB/day (%s volume)", impact.Category, impact.Table, impact.EstimatedGBPerDay, impact.Volume))
+	}
+	for _, note := range plan.Notes {
+		preview.AddNote(note)
+	}
+	for _, conversion := range plan.Conversions {
+		preview.AddNote("Breaking: " + conversion)
+	}
+	return preview
+}
+
+// parseCategories accepts categories as a comma-separated string or a JSON array
+func parseCategories(value interface{}) []string {
+	var raw []string
+	switch v := value.(type) {
+	case string:
+		raw = strings.Split(v, ",")
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				raw = append(raw, s)
+			}
+		}
+	case []string:
+		raw = v
+	}
+
+	var categories []string
+	for _, category := range raw {
+		if category = strings.TrimSpace(category); category != "" && !slices.Contains(categories, category) {
+			categories = append(categories, category)
+		}
+	}
+	return categories
+}
+
+// clusterNodeCount sums the node counts of the cluster's agent pools
+func clusterNodeCount(cluster *armcontainerservice.ManagedCluster) int {
+	if cluster == nil || cluster.Properties == nil {
+		return 0
+	}
+	count := 0
+	for _, pool := range cluster.Properties.AgentPoolProfiles {
+		if pool != nil && pool.Count != nil {
+			count += int(*pool.Count)
+		}
+	}
+	return count
+}
+
+// mergeCategories returns the sorted union of two category lists
+func mergeCategories(current, added []string) []string {
+	merged := slices.Clone(current)
+	for _, category := range added {
+		if !slices.Contains(merged, category) {
+			merged = append(merged, category)
+		}
+	}
+	sort.Strings(merged)
+	return merged
+}
+
+// destinationTypeName describes the current Log Analytics destination type of a setting
+func destinationTypeName(current string, create bool) string {
+	switch {
+	case create:
+		return "none"
+	case current == "":
+		return "AzureDiagnostics"
+	}
+	return current
+}
@@ -0,0 +1,209 @@
+package diagnostics
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/dryrun"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+)
+
+const testWorkspaceResourceID = "/subscriptions/sub-1/resourceGroups/rg-1/providers/Microsoft.OperationalInsights/workspaces/ws-1"
+
+func TestPlanDiagnosticSetting_CreatesSetting(t *testing.T) {
+	plan, err := PlanDiagnosticSetting(nil, []string{"kube-audit-admin", "guard"}, testWorkspaceResourceID, "", 20)
+	if err != nil {
+		t.Fatalf("PlanDiagnosticSetting returned error: %v", err)
+	}
+	if !plan.Create || plan.SettingName != DefaultDiagnosticSettingName || !plan.Changed() {
+		t.Errorf("Expected a new setting named %s, got %+v", DefaultDiagnosticSettingName, plan)
+	}
+	props := plan.Setting.Properties
+	if *props.WorkspaceID != testWorkspaceResourceID || *props.LogAnalyticsDestinationType != "Dedicated" || len(props.Logs) != 2 {
+		t.Errorf("Unexpected setting properties: %+v", props)
+	}
+	if len(plan.Impact) != 2 || plan.Impact[0].Table != "AKSAuditAdmin" || plan.Impact[0].EstimatedGBPerDay != 1.2 {
+		t.Errorf("Expected ingestion impact scaled to 20 nodes, got %+v", plan.Impact)
+	}
+}
+
+func TestPlanDiagnosticSetting_UpdatesSettingForWorkspace(t *testing.T) {
+	existing := []*armmonitor.DiagnosticSettingsResource{
+		{
+			Name: to.Ptr("other"),
+			Properties: &armmonitor.DiagnosticSettings{
+				StorageAccountID: to.Ptr("/subscriptions/sub-1/resourceGroups/rg-1/providers/Microsoft.Storage/storageAccounts/sa"),
+				Logs:             []*armmonitor.LogSettings{{Category: to.Ptr("kube-audit"), Enabled: to.Ptr(true)}},
+			},
+		},
+		{
+			Name: to.Ptr("to-workspace"),
+			Properties: &armmonitor.DiagnosticSettings{
+				WorkspaceID: to.Ptr(strings.ToLower(testWorkspaceResourceID)),
+				Logs: []*armmonitor.LogSettings{
+					{Category: to.Ptr("kube-apiserver"), Enabled: to.Ptr(true)},
+					{Category: to.Ptr("kube-audit-admin"), Enabled: to.Ptr(false)},
+				},
+				Metrics: []*armmonitor.MetricSettings{{Category: to.Ptr("AllMetrics"), Enabled: to.Ptr(true)}},
+			},
+		},
+	}
+
+	plan, err := PlanDiagnosticSetting(existing, []string{"kube-apiserver", "kube-audit-admin"}, testWorkspaceResourceID, "", 0)
+	if err != nil {
+		t.Fatalf("PlanDiagnosticSetting returned error: %v", err)
+	}
+	if plan.Create || plan.SettingName != "to-workspace" {
+		t.Fatalf("Expected the setting sending to the workspace to be updated, got %+v", plan)
+	}
+	if !slices.Equal(plan.EnabledCategories, []string{"kube-audit-admin"}) || !slices.Equal(plan.AlreadyEnabled, []string{"kube-apiserver"}) {
+		t.Errorf("Unexpected categories: enabled %v, already enabled %v", plan.EnabledCategories, plan.AlreadyEnabled)
+	}
+	props := plan.Setting.Properties
+	if len(props.Logs) != 2 || !*props.Logs[1].Enabled || len(props.Metrics) != 1 {
+		t.Errorf("Expected the disabled entry to be enabled and metrics kept, got %+v", props)
+	}
+	if *existing[1].Properties.Logs[1].Enabled {
+		t.Error("Planning must not modify the existing setting")
+	}
+	if !containsNote(plan.Conversions, "switch from the AzureDiagnostics table") || !containsNote(plan.Notes, "node count is unknown") {
+		t.Errorf("Expected the table switch as a conversion and a note on the node count, got %v / %v", plan.Conversions, plan.Notes)
+	}
+	if err := plan.CheckConversion(false); err == nil || !strings.Contains(err.Error(), "convert_existing=true") {
+		t.Errorf("Expected the conversion to need convert_existing, got %v", err)
+	}
+	if err := plan.CheckConversion(true); err != nil {
+		t.Errorf("Expected convert_existing to allow the conversion, got %v", err)
+	}
+}
+
+func TestPlanDiagnosticSetting_ConvertsSettingForOtherWorkspace(t *testing.T) {
+	otherWorkspace := "/subscriptions/sub-1/resourceGroups/rg-1/providers/Microsoft.OperationalInsights/workspaces/ws-2"
+	existing := []*armmonitor.DiagnosticSettingsResource{{
+		Name: to.Ptr(DefaultDiagnosticSettingName),
+		Properties: &armmonitor.DiagnosticSettings{
+			WorkspaceID: to.Ptr(otherWorkspace),
+			Logs:        []*armmonitor.LogSettings{{Category: to.Ptr("kube-apiserver"), Enabled: to.Ptr(true)}},
+		},
+	}}
+
+	plan, err := PlanDiagnosticSetting(existing, []string{"guard"}, testWorkspaceResourceID, "", 10)
+	if err != nil {
+		t.Fatalf("PlanDiagnosticSetting returned error: %v", err)
+	}
+	if !plan.MovesWorkspace() || !plan.ConvertsDestination() || len(plan.Conversions) != 2 {
+		t.Fatalf("Expected the workspace move and the table switch as conversions, got %+v", plan)
+	}
+	err = plan.CheckConversion(false)
+	if err == nil || !strings.Contains(err.Error(), otherWorkspace) || !strings.Contains(err.Error(), "different setting_name") {
+		t.Errorf("Expected the workspace move to be refused, got %v", err)
+	}
+
+	preview := previewDiagnosticSetting(plan, "/subscriptions/sub-1/resourceGroups/rg-1/providers/Microsoft.ContainerService/managedClusters/aks-1", dryrun.NewPolicy("readwrite", err))
+	breaking := map[string]bool{}
+	for _, change := range preview.Changes {
+		if change.Breaking {
+			breaking[change.Field] = true
+		}
+	}
+	if !breaking["workspace"] || !breaking["destination type"] || breaking["enabled categories"] {
+		t.Errorf("Expected the workspace and destination type changes to be breaking, got %+v", preview.Changes)
+	}
+	if preview.Policy.Allowed {
+		t.Error("Expected the preview to report that the conversion is refused")
+	}
+}
+
+func TestPlanDiagnosticSetting_NoChange(t *testing.T) {
+	existing := []*armmonitor.DiagnosticSettingsResource{{
+		Name: to.Ptr("audit"),
+		Properties: &armmonitor.DiagnosticSettings{
+			WorkspaceID:                 to.Ptr(testWorkspaceResourceID),
+			LogAnalyticsDestinationType: to.Ptr("Dedicated"),
+			Logs:                        []*armmonitor.LogSettings{{CategoryGroup: to.Ptr("audit"), Enabled: to.Ptr(true)}},
+		},
+	}}
+
+	plan, err := PlanDiagnosticSetting(existing, []string{"kube-audit", "guard"}, testWorkspaceResourceID, "", 10)
+	if err != nil {
+		t.Fatalf("PlanDiagnosticSetting returned error: %v", err)
+	}
+	if plan.Changed() || len(plan.AlreadyEnabled) != 2 || len(plan.Impact) != 0 {
+		t.Errorf("Expected the audit category group to cover the categories, got %+v", plan)
+	}
+}
+
+func TestPlanDiagnosticSetting_Errors(t *testing.T) {
+	full := make([]*armmonitor.DiagnosticSettingsResource, 0, maxDiagnosticSettingsPerResource)
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		full = append(full, &armmonitor.DiagnosticSettingsResource{Name: to.Ptr(name), Properties: &armmonitor.DiagnosticSettings{}})
+	}
+
+	tests := []struct {
+		name       string
+		existing   []*armmonitor.DiagnosticSettingsResource
+		categories []string
+		workspace  string
+		wantErr    string
+	}{
+		{"no categories", nil, nil, testWorkspaceResourceID, "at least one log category"},
+		{"unknown category", nil, []string{"kube-proxy"}, testWorkspaceResourceID, "invalid log category"},
+		{"invalid workspace", nil, []string{"guard"}, "ws-guid", "invalid workspace resource ID"},
+		{"setting limit", full, []string{"guard"}, testWorkspaceResourceID, "already has 5 diagnostic settings"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := PlanDiagnosticSetting(tt.existing, tt.categories, tt.workspace, "", 10)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestParseCategories(t *testing.T) {
+	var fromJSON interface{}
+	_ = json.Unmarshal([]byte(`["guard", "kube-audit", "guard"]`), &fromJSON)
+
+	if got := parseCategories(" kube-audit, guard ,,kube-audit"); !slices.Equal(got, []string{"kube-audit", "guard"}) {
+		t.Errorf("Unexpected categories from a string: %v", got)
+	}
+	if got := parseCategories(fromJSON); !slices.Equal(got, []string{"guard", "kube-audit"}) {
+		t.Errorf("Unexpected categories from an array: %v", got)
+	}
+}
+
+func TestHandleDiagnosticsEnable_RequiresReadWrite(t *testing.T) {
+	params := map[string]interface{}{
+		"subscription_id":       "sub-1",
+		"resource_group":        "rg-1",
+		"cluster_name":          "aks-1",
+		"categories":            "guard",
+		"workspace_resource_id": testWorkspaceResourceID,
+	}
+
+	_, err := HandleDiagnosticsEnable(params, nil, &config.ConfigData{AccessLevel: "readonly"})
+	if err == nil || !strings.Contains(err.Error(), "requires readwrite or admin access level") {
+		t.Errorf("Expected an access level error, got %v", err)
+	}
+
+	// A dry run is allowed with readonly access and gets as far as the Azure client
+	params["dry_run"] = true
+	_, err = HandleDiagnosticsEnable(params, nil, &config.ConfigData{AccessLevel: "readonly"})
+	if err == nil || !strings.Contains(err.Error(), "azure client is required") {
+		t.Errorf("Expected an Azure client error for the dry run, got %v", err)
+	}
+}
+
+func containsNote(notes []string, text string) bool {
+	for _, note := range notes {
+		if strings.Contains(note, text) {
+			return true
+		}
+	}
+	return false
+}
@@ -5,12 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
+	"time"
 
-	"github.com/Azure/aks-mcp/internal/azcli"
 	"github.com/Azure/aks-mcp/internal/azureclient"
 	"github.com/Azure/aks-mcp/internal/components/common"
 	"github.com/Azure/aks-mcp/internal/config"
 	"github.com/Azure/aks-mcp/internal/tools"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
 )
 
 // buildClusterResourceID constructs the Azure resource ID for an AKS cluster
@@ -78,12 +80,6 @@ func HandleControlPlaneLogs(params map[string]interface{}, azClient *azureclient
 		return "", fmt.Errorf("failed to find diagnostic setting for log category %s in cluster %s: %w", logCategory, clusterName, err)
 	}
 
-	// Get workspace GUID from the workspace resource ID
-	workspaceGUID, err := getWorkspaceGUID(workspaceResourceID, cfg)
-	if err != nil {
-		return "", fmt.Errorf("failed to get workspace GUID for cluster %s: %w", clusterName, err)
-	}
-
 	// Build cluster resource ID for scoping using utility function
 	clusterResourceID := buildClusterResourceID(subscriptionID, resourceGroup, clusterName)
 
@@ -99,27 +95,18 @@ func HandleControlPlaneLogs(params map[string]interface{}, azClient *azureclient
 		return "", fmt.Errorf("failed to calculate timespan: %w", err)
 	}
 
-	// Execute log query with properly quoted KQL
-	executor := azcli.NewExecutor()
-
-	// Build command string with proper quoting for the KQL query
-	cmd := fmt.Sprintf("az monitor log-analytics query --workspace %s --analytics-query \"%s\" --timespan %s --output json",
-		workspaceGUID, kqlQuery, timespan)
-
-	// Log the query command for debugging
-	log.Printf("Executing KQL query command: %s", cmd)
-
-	cmdParams := map[string]interface{}{
-		"command": cmd,
-	}
-
-	result, err := executor.Execute(cmdParams, cfg)
+	// Query the workspace through the Azure SDK; the query is sent as is, without shell quoting
+	result, err := queryWorkspace(azClient, workspaceResourceID, kqlQuery, timespan)
 	if err != nil {
 		return "", fmt.Errorf("failed to query control plane logs for category %s in cluster %s: %w", logCategory, clusterName, err)
 	}
 
-	// Return raw JSON result from Azure CLI
-	return result, nil
+	// Return the result tables with their column metadata
+	out, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal control plane logs: %w", err)
+	}
+	return string(out), nil
 }
 
 // Resource handler functions for control plane diagnostics tools
@@ -137,3 +124,254 @@ func GetControlPlaneLogsHandler(azClient *azureclient.AzureClient, cfg *config.C
 		return HandleControlPlaneLogs(params, azClient, cfg)
 	})
 }
+
+// containerInsightsWorkspaceConfigKey is the monitoring addon setting that holds the Container Insights workspace
+const containerInsightsWorkspaceConfigKey = "logAnalyticsWorkspaceResourceID"
+
+// HandleContainerInsightsQuery queries a Container Insights table for an AKS cluster. The
+// workspace is taken from the monitoring addon, falling back to the diagnostic settings.
+func HandleContainerInsightsQuery(table string, params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
+	subscriptionID, resourceGroup, clusterName, err := common.ExtractAKSParameters(params)
+	if err != nil {
+		return "", err
+	}
+
+	filters := ContainerInsightsFilters{}
+	for key, target := range map[string]*string{
+		"namespace": &filters.Namespace,
+		"pod":       &filters.Pod,
+		"container": &filters.Container,
+		"node":      &filters.Node,
+		"severity":  &filters.Severity,
+		"status":    &filters.Status,
+		"search":    &filters.Search,
+		"metric":    &filters.Metric,
+	} {
+		value, _ := params[key].(string)
+		*target = strings.TrimSpace(value)
+	}
+
+	// Enforce the namespace restrictions of the server on namespaced tables
+	if cfg != nil && cfg.SecurityConfig != nil && IsNamespacedContainerInsightsTable(table) {
+		if filters.Namespace != "" {
+			if !cfg.SecurityConfig.IsNamespaceAllowed(filters.Namespace) {
+				return "", fmt.Errorf("namespace '%s' is not allowed by the server configuration", filters.Namespace)
+			}
+		} else {
+			filters.AllowedNamespaces = allowedNamespaces(cfg.SecurityConfig.AllowedNamespaces)
+		}
+	}
+	// InsightsMetrics holds pod metrics without a namespace column, so the restrictions cannot be applied to it
+	if cfg != nil && cfg.SecurityConfig != nil && table == TableInsightsMetrics && len(allowedNamespaces(cfg.SecurityConfig.AllowedNamespaces)) > 0 {
+		return "", fmt.Errorf("table %s has no namespace column and is not available when the server restricts namespaces", table)
+	}
+
+	startTime, _ := params["start_time"].(string)
+	endTime, _ := params["end_time"].(string)
+	if startTime == "" {
+		if endTime != "" {
+			return "", fmt.Errorf("end_time requires start_time")
+		}
+		startTime = time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	}
+	if err := ValidateQueryWindow(startTime, params); err != nil {
+		return "", err
+	}
+
+	clusterResourceID := buildClusterResourceID(subscriptionID, resourceGroup, clusterName)
+	kqlQuery, err := BuildContainerInsightsQuery(table, filters, GetMaxRecords(params), clusterResourceID)
+	if err != nil {
+		return "", fmt.Errorf("failed to build KQL query for cluster %s: %w", clusterName, err)
+	}
+
+	workspaceResourceID, err := findContainerInsightsWorkspace(subscriptionID, resourceGroup, clusterName, azClient, cfg)
+	if err != nil {
+		return "", fmt.Errorf("failed to find the Container Insights workspace for cluster %s: %w", clusterName, err)
+	}
+
+	timespan, err := CalculateTimespan(startTime, endTime)
+	if err != nil {
+		return "", fmt.Errorf("failed to calculate timespan: %w", err)
+	}
+
+	result, err := queryWorkspace(azClient, workspaceResourceID, kqlQuery, timespan)
+	if err != nil {
+		return "", fmt.Errorf("failed to query %s in cluster %s: %w", table, clusterName, err)
+	}
+	out, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal %s query result: %w", table, err)
+	}
+	return string(out), nil
+}
+
+// findContainerInsightsWorkspace returns the resource ID of the workspace Container Insights sends data to
+func findContainerInsightsWorkspace(subscriptionID, resourceGroup, clusterName string, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
+	if azClient == nil {
+		return "", fmt.Errorf("azure client is required but not provided")
+	}
+
+	cluster, err := azClient.GetAKSCluster(context.Background(), subscriptionID, resourceGroup, clusterName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get AKS cluster: %w", err)
+	}
+	if workspaceResourceID := MonitoringAddonWorkspace(cluster); workspaceResourceID != "" {
+		return workspaceResourceID, validateWorkspaceResourceID(workspaceResourceID)
+	}
+
+	log.Printf("Monitoring addon is not enabled on cluster '%s', falling back to the diagnostic settings workspace", clusterName)
+	return ExtractWorkspaceFromDiagnosticSettings(subscriptionID, resourceGroup, clusterName, azClient, cfg)
+}
+
+// MonitoringAddonWorkspace returns the workspace resource ID of the enabled monitoring (omsagent) addon
+func MonitoringAddonWorkspace(cluster *armcontainerservice.ManagedCluster) string {
+	if cluster == nil || cluster.Properties == nil {
+		return ""
+	}
+	for name, addon := range cluster.Properties.AddonProfiles {
+		if !strings.EqualFold(name, "omsagent") || addon == nil || addon.Enabled == nil || !*addon.Enabled {
+			continue
+		}
+		for key, value := range addon.Config {
+			if strings.EqualFold(key, containerInsightsWorkspaceConfigKey) && value != nil {
+				return *value
+			}
+		}
+	}
+	return ""
+}
+
+// allowedNamespaces splits the comma-separated namespace allow list, which is empty when unrestricted
+func allowedNamespaces(list string) []string {
+	var namespaces []string
+	for _, namespace := range strings.Split(list, ",") {
+		if namespace = strings.TrimSpace(namespace); namespace != "" {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	return namespaces
+}
+
+// AuditInvestigation is the summarized answer to an audit investigation question
+type AuditInvestigation struct {
+	Question string        `json:"question"`
+	Category string        `json:"category"`
+	Timespan string        `json:"timespan"`
+	Bucket   string        `json:"bucket"`
+	Timeline []interface{} `json:"timeline"`
+	Notes    []string      `json:"notes,omitempty"`
+	Query    string        `json:"query"`
+}
+
+// HandleAuditInvestigation answers a structured question about the audit log of an AKS
+// cluster with a timeline of distinct actions instead of raw audit events
+func HandleAuditInvestigation(params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
+	subscriptionID, resourceGroup, clusterName, err := common.ExtractAKSParameters(params)
+	if err != nil {
+		return "", err
+	}
+
+	question, _ := params["question"].(string)
+	filters := AuditFilters{}
+	for key, target := range map[string]*string{
+		"user":      &filters.User,
+		"resource":  &filters.Resource,
+		"namespace": &filters.Namespace,
+		"name":      &filters.Name,
+	} {
+		value, _ := params[key].(string)
+		*target = strings.TrimSpace(value)
+	}
+
+	// Enforce the namespace restrictions of the server
+	if cfg != nil && cfg.SecurityConfig != nil {
+		if filters.Namespace != "" {
+			if !cfg.SecurityConfig.IsNamespaceAllowed(filters.Namespace) {
+				return "", fmt.Errorf("namespace '%s' is not allowed by the server configuration", filters.Namespace)
+			}
+		} else {
+			filters.AllowedNamespaces = allowedNamespaces(cfg.SecurityConfig.AllowedNamespaces)
+		}
+	}
+
+	// Default to the longest range allowed so investigations cover the last day
+	now := time.Now()
+	startTime, _ := params["start_time"].(string)
+	endTime, _ := params["end_time"].(string)
+	if startTime == "" {
+		if endTime != "" {
+			return "", fmt.Errorf("end_time requires start_time")
+		}
+		startTime = now.Add(-MaxQueryRangeDuration).UTC().Format(time.RFC3339)
+	}
+	if err := ValidateTimeRange(startTime, params); err != nil {
+		return "", err
+	}
+	start, _ := time.Parse(time.RFC3339, startTime)
+	end := now
+	if endTime != "" {
+		end, _ = time.Parse(time.RFC3339, endTime)
+	}
+	bucket := AuditTimelineBucket(end.Sub(start))
+
+	clusterResourceID := buildClusterResourceID(subscriptionID, resourceGroup, clusterName)
+	if err := ValidateAuditQueryParams(question, filters, GetMaxRecords(params), clusterResourceID, AzureDiagnosticsMode); err != nil {
+		return "", err
+	}
+
+	// kube-audit has every request; kube-audit-admin leaves out reads but answers the other questions
+	investigation := &AuditInvestigation{Question: question, Bucket: bucket.String(), Timeline: []interface{}{}}
+	category := "kube-audit"
+	workspaceResourceID, isResourceSpecific, err := FindDiagnosticSettingForCategory(subscriptionID, resourceGroup, clusterName, category, azClient, cfg)
+	if err != nil && !AuditQuestionNeedsReads(question) {
+		category = "kube-audit-admin"
+		workspaceResourceID, isResourceSpecific, err = FindDiagnosticSettingForCategory(subscriptionID, resourceGroup, clusterName, category, azClient, cfg)
+		if err == nil {
+			investigation.Notes = append(investigation.Notes, "Only kube-audit-admin is enabled, so read-only requests (get, list, watch) are not included")
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to find an audit log diagnostic setting for cluster %s: %w", clusterName, err)
+	}
+	investigation.Category = category
+
+	tableMode := AzureDiagnosticsMode
+	if isResourceSpecific {
+		tableMode = ResourceSpecificMode
+	}
+	builder, err := NewAuditQueryBuilder(question, filters, GetMaxRecords(params), clusterResourceID, tableMode, bucket)
+	if err != nil {
+		return "", err
+	}
+	kqlQuery, err := builder.Build(category)
+	if err != nil {
+		return "", err
+	}
+	investigation.Query = kqlQuery
+
+	timespan, err := CalculateTimespan(startTime, endTime)
+	if err != nil {
+		return "", fmt.Errorf("failed to calculate timespan: %w", err)
+	}
+	investigation.Timespan = timespan
+
+	result, err := queryWorkspace(azClient, workspaceResourceID, kqlQuery, timespan)
+	if err != nil {
+		return "", fmt.Errorf("failed to query audit logs in cluster %s: %w", clusterName, err)
+	}
+	for _, row := range azureclient.LogsRowMaps(azureclient.PrimaryLogsTable(result)) {
+		investigation.Timeline = append(investigation.Timeline, row)
+	}
+	if note := partialResultNote(result); note != "" {
+		investigation.Notes = append(investigation.Notes, note)
+	}
+	if len(investigation.Timeline) == GetMaxRecords(params) {
+		investigation.Notes = append(investigation.Notes, "The timeline was truncated to the most recent entries; narrow the time range or raise max_records")
+	}
+
+	out, err := json.MarshalIndent(investigation, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal audit investigation: %w", err)
+	}
+	return string(out), nil
+}
@@ -0,0 +1,164 @@
+package diagnostics
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/security"
+	"github.com/Azure/azure-sdk-for-go/sdk/monitor/azquery"
+)
+
+// logAnalyticsTables are the tables free-form queries may read. Every one of them carries the
+// cluster resource ID in _ResourceId, which the query is scoped to.
+var logAnalyticsTables = []string{
+	TableContainerLogV2, "ContainerLog", "ContainerInventory", "ContainerNodeInventory",
+	TableKubePodInventory, TableKubeNodeInventory, TableKubeEvents, "KubeServices", "KubePVInventory",
+	"KubeMonAgentEvents", TableInsightsMetrics, "Perf",
+	"AKSAudit", "AKSAuditAdmin", "AKSControlPlane", "AzureDiagnostics", "AzureMetrics",
+}
+
+// logAnalyticsNamespaceColumns are the namespace columns of the namespaced tables in logAnalyticsTables
+var logAnalyticsNamespaceColumns = map[string]string{
+	TableContainerLogV2:   "PodNamespace",
+	TableKubePodInventory: "Namespace",
+	TableKubeEvents:       "Namespace",
+	"KubeServices":        "Namespace",
+	"KubePVInventory":     "PVCNamespace",
+}
+
+// logAnalyticsUnfilterableTables hold namespaced data without a namespace column, so they are
+// rejected when the server restricts namespaces
+var logAnalyticsUnfilterableTables = []string{
+	"ContainerLog", "ContainerInventory", TableInsightsMetrics, "Perf",
+	"AKSAudit", "AKSAuditAdmin", "AKSControlPlane", "AzureDiagnostics",
+}
+
+// LogAnalyticsQueryResult is the result of a free-form Log Analytics query
+type LogAnalyticsQueryResult struct {
+	Query    string            `json:"query"`
+	Tables   []string          `json:"tables"`
+	Timespan string            `json:"timespan"`
+	Columns  []*azquery.Column `json:"columns"`
+	Rows     []azquery.Row     `json:"rows"`
+	Notes    []string          `json:"notes,omitempty"`
+}
+
+// BuildScopedLogAnalyticsQuery checks a user-supplied KQL query against the guardrails and
+// returns it scoped to the cluster and capped to maxRecords rows. When allowedNamespaces is not
+// empty, namespaced tables are filtered to those namespaces.
+func BuildScopedLogAnalyticsQuery(query string, maxRecords int, clusterResourceID string, allowedNamespaces []string) (*security.KQLAnalysis, error) {
+	if !azureResourceIDPattern.MatchString(clusterResourceID) {
+		return nil, fmt.Errorf("invalid clusterResourceID format. Expected format: /subscriptions/{subscription-id}/resourceGroups/{resource-group}/providers/Microsoft.ContainerService/managedClusters/{cluster-name}")
+	}
+	guardrails := security.KQLGuardrails{
+		ScopeResourceID: clusterResourceID,
+		AllowedTables:   logAnalyticsTables,
+		MaxRecords:      maxRecords,
+	}
+	if len(allowedNamespaces) > 0 {
+		for _, namespace := range allowedNamespaces {
+			if !kubernetesNamePattern.MatchString(namespace) {
+				return nil, fmt.Errorf("invalid allowed namespace '%s': must be a valid Kubernetes name", namespace)
+			}
+		}
+		guardrails.TableFilters = make(map[string]string)
+		for table, column := range logAnalyticsNamespaceColumns {
+			guardrails.TableFilters[table] = fmt.Sprintf("%s in (%s)", column, quoteList(allowedNamespaces))
+		}
+	}
+
+	analysis, err := security.AnalyzeKQL(query, guardrails)
+	if err != nil {
+		return nil, fmt.Errorf("query rejected: %w", err)
+	}
+	if len(analysis.Tables) == 0 {
+		return nil, fmt.Errorf("query rejected: it must read at least one of the tables %s", strings.Join(logAnalyticsTables, ", "))
+	}
+	if len(allowedNamespaces) > 0 {
+		for _, table := range analysis.Tables {
+			if slices.Contains(logAnalyticsUnfilterableTables, table) {
+				return nil, fmt.Errorf("query rejected: table '%s' cannot be restricted to the allowed namespaces of the server", table)
+			}
+		}
+	}
+	return analysis, nil
+}
+
+// HandleLogAnalyticsQuery runs a user-supplied KQL query against the cluster's Log Analytics
+// workspace after scoping it to the cluster, capping the rows and limiting the time range
+func HandleLogAnalyticsQuery(params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
+	subscriptionID, resourceGroup, clusterName, err := common.ExtractAKSParameters(params)
+	if err != nil {
+		return "", err
+	}
+
+	query, _ := params["query"].(string)
+	if strings.TrimSpace(query) == "" {
+		return "", fmt.Errorf("missing or invalid query parameter")
+	}
+
+	// The timespan bounds the data the query can read, whatever time filters the query has
+	startTime, _ := params["start_time"].(string)
+	endTime, _ := params["end_time"].(string)
+	if startTime == "" {
+		if endTime != "" {
+			return "", fmt.Errorf("end_time requires start_time")
+		}
+		startTime = time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	}
+	if err := ValidateQueryWindow(startTime, params); err != nil {
+		return "", err
+	}
+
+	maxRecords := GetMaxRecords(params)
+	clusterResourceID := buildClusterResourceID(subscriptionID, resourceGroup, clusterName)
+	var namespaces []string
+	if cfg != nil && cfg.SecurityConfig != nil {
+		namespaces = allowedNamespaces(cfg.SecurityConfig.AllowedNamespaces)
+	}
+	analysis, err := BuildScopedLogAnalyticsQuery(query, maxRecords, clusterResourceID, namespaces)
+	if err != nil {
+		return "", err
+	}
+
+	result := &LogAnalyticsQueryResult{Query: analysis.Query, Tables: analysis.Tables}
+	for _, limit := range analysis.Limits {
+		if limit > maxRecords {
+			result.Notes = append(result.Notes, fmt.Sprintf("Results are capped at %d rows; raise max_records (at most %d) to see more", maxRecords, MaxAllowedRecords))
+			break
+		}
+	}
+
+	workspaceResourceID, err := findContainerInsightsWorkspace(subscriptionID, resourceGroup, clusterName, azClient, cfg)
+	if err != nil {
+		return "", fmt.Errorf("failed to find the Log Analytics workspace for cluster %s: %w", clusterName, err)
+	}
+	timespan, err := CalculateTimespan(startTime, endTime)
+	if err != nil {
+		return "", fmt.Errorf("failed to calculate timespan: %w", err)
+	}
+	result.Timespan = timespan
+
+	queryResult, err := queryWorkspace(azClient, workspaceResourceID, analysis.Query, timespan)
+	if err != nil {
+		return "", fmt.Errorf("failed to run Log Analytics query for cluster %s: %w", clusterName, err)
+	}
+	table := azureclient.PrimaryLogsTable(queryResult)
+	result.Columns = table.Columns
+	result.Rows = table.Rows
+	if note := partialResultNote(queryResult); note != "" {
+		result.Notes = append(result.Notes, note)
+	}
+
+	out, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal Log Analytics query result: %w", err)
+	}
+	return string(out), nil
+}
@@ -0,0 +1,63 @@
+package diagnostics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildScopedLogAnalyticsQuery(t *testing.T) {
+	analysis, err := BuildScopedLogAnalyticsQuery("KubePodInventory | where PodStatus == 'Pending' | summarize count() by Namespace", 50, testClusterResourceID, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "(KubePodInventory | where _ResourceId =~ '" + testClusterResourceID + "') | where PodStatus == 'Pending' | summarize count() by Namespace | take 50"
+	if analysis.Query != expected {
+		t.Errorf("expected %s, got %s", expected, analysis.Query)
+	}
+
+	// Queries are sent through the Azure SDK, so double-quoted strings are fine
+	if _, err := BuildScopedLogAnalyticsQuery(`KubeEvents | where Reason == "BackOff"`, 50, testClusterResourceID, nil); err != nil {
+		t.Errorf("unexpected error for a double-quoted string: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		wantErr string
+	}{
+		{"no table", "print x = 1", "at least one of the tables"},
+		{"table outside the allow list", "SigninLogs | take 1", "not allowed"},
+		{"cross workspace", "workspace('other').KubeEvents", "not allowed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := BuildScopedLogAnalyticsQuery(tt.query, 50, testClusterResourceID, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestBuildScopedLogAnalyticsQuery_AllowedNamespaces(t *testing.T) {
+	namespaces := []string{"shop", "default"}
+	analysis, err := BuildScopedLogAnalyticsQuery("ContainerLogV2 | join (KubeNodeInventory) on Computer", 50, testClusterResourceID, namespaces)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "(ContainerLogV2 | where _ResourceId =~ '" + testClusterResourceID + "' | where PodNamespace in ('shop', 'default')) | join ((KubeNodeInventory | where _ResourceId =~ '" + testClusterResourceID + "')) on Computer | take 50"
+	if analysis.Query != expected {
+		t.Errorf("expected %s, got %s", expected, analysis.Query)
+	}
+
+	for _, query := range []string{"InsightsMetrics | take 1", "KubeEvents | union AKSAudit"} {
+		_, err := BuildScopedLogAnalyticsQuery(query, 50, testClusterResourceID, namespaces)
+		if err == nil || !strings.Contains(err.Error(), "cannot be restricted to the allowed namespaces") {
+			t.Errorf("expected %q to be rejected, got %v", query, err)
+		}
+	}
+
+	if _, err := BuildScopedLogAnalyticsQuery("KubeEvents", 50, testClusterResourceID, []string{"a'b"}); err == nil {
+		t.Error("expected an invalid allowed namespace to be rejected")
+	}
+}
@@ -0,0 +1,97 @@
+package diagnostics
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/config"
+)
+
+// MaxPodLookupNames limits how many pod names one lookup resolves
+const MaxPodLookupNames = 50
+
+// PodLocation is the latest KubePodInventory record of a pod
+type PodLocation struct {
+	Pod            string `json:"pod"`
+	Namespace      string `json:"namespace"`
+	Node           string `json:"node,omitempty"`
+	Status         string `json:"status,omitempty"`
+	ControllerKind string `json:"controller_kind,omitempty"`
+	ControllerName string `json:"controller_name,omitempty"`
+	LastSeen       string `json:"last_seen,omitempty"`
+}
+
+// BuildPodLookupQuery builds a KubePodInventory query returning the latest record of each named
+// pod. Names that are not valid Kubernetes names are skipped, as they cannot be pods.
+func BuildPodLookupQuery(podNames []string, clusterResourceID string, allowed []string) (string, error) {
+	if !azureResourceIDPattern.MatchString(clusterResourceID) {
+		return "", fmt.Errorf("invalid clusterResourceID format. Expected format: /subscriptions/{subscription-id}/resourceGroups/{resource-group}/providers/Microsoft.ContainerService/managedClusters/{cluster-name}")
+	}
+	for _, namespace := range allowed {
+		if !kubernetesNamePattern.MatchString(namespace) {
+			return "", fmt.Errorf("invalid allowed namespace '%s': must be a valid Kubernetes name", namespace)
+		}
+	}
+
+	var names []string
+	for _, name := range podNames {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if kubernetesNamePattern.MatchString(name) && !containsFold(names, name) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "", fmt.Errorf("no valid pod names to look up")
+	}
+	if len(names) > MaxPodLookupNames {
+		names = names[:MaxPodLookupNames]
+	}
+
+	query := fmt.Sprintf("%s | where _ResourceId =~ '%s' | where Name in~ (%s)", TableKubePodInventory, clusterResourceID, quoteList(names))
+	if len(allowed) > 0 {
+		query += fmt.Sprintf(" | where Namespace in (%s)", quoteList(allowed))
+	}
+	query += " | summarize arg_max(TimeGenerated, Namespace, Computer, PodStatus, ControllerKind, ControllerName) by Name"
+	return query, nil
+}
+
+// LookupPods finds where the named pods ran from the cluster's Container Insights data. Pods in
+// namespaces the server does not allow are left out.
+func LookupPods(subscriptionID, resourceGroup, clusterName string, podNames []string, timespan string, azClient *azureclient.AzureClient, cfg *config.ConfigData) ([]PodLocation, error) {
+	var allowed []string
+	if cfg != nil && cfg.SecurityConfig != nil {
+		allowed = allowedNamespaces(cfg.SecurityConfig.AllowedNamespaces)
+	}
+	query, err := BuildPodLookupQuery(podNames, buildClusterResourceID(subscriptionID, resourceGroup, clusterName), allowed)
+	if err != nil {
+		return nil, err
+	}
+
+	workspaceResourceID, err := findContainerInsightsWorkspace(subscriptionID, resourceGroup, clusterName, azClient, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the Container Insights workspace for cluster %s: %w", clusterName, err)
+	}
+	result, err := queryWorkspace(azClient, workspaceResourceID, query, timespan)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up pods in cluster %s: %w", clusterName, err)
+	}
+
+	locations := []PodLocation{}
+	for _, row := range azureclient.LogsRowMaps(azureclient.PrimaryLogsTable(result)) {
+		text := func(column string) string {
+			value, _ := row[column].(string)
+			return value
+		}
+		locations = append(locations, PodLocation{
+			Pod:            text("Name"),
+			Namespace:      text("Namespace"),
+			Node:           text("Computer"),
+			Status:         text("PodStatus"),
+			ControllerKind: text("ControllerKind"),
+			ControllerName: text("ControllerName"),
+			LastSeen:       text("TimeGenerated"),
+		})
+	}
+	return locations, nil
+}
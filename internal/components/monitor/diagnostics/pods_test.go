@@ -0,0 +1,46 @@
+package diagnostics
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-mcp/internal/security"
+)
+
+func TestBuildPodLookupQuery(t *testing.T) {
+	query, err := BuildPodLookupQuery([]string{"orders-api-7d9f8b6c5d-x2x4k", "Redis-0", "redis-0", "VM_01"}, testClusterResourceID, []string{"shop"})
+	if err != nil {
+		t.Fatalf("BuildPodLookupQuery returned error: %v", err)
+	}
+	expected := "KubePodInventory | where _ResourceId =~ '" + testClusterResourceID + "' | where Name in~ ('orders-api-7d9f8b6c5d-x2x4k', 'redis-0')" +
+		" | where Namespace in ('shop') | summarize arg_max(TimeGenerated, Namespace, Computer, PodStatus, ControllerKind, ControllerName) by Name"
+	if query != expected {
+		t.Errorf("Unexpected query:\n got: %s\nwant: %s", query, expected)
+	}
+	if err := security.ValidateKQLReadOnly(query); err != nil {
+		t.Errorf("Built query should be read-only: %v", err)
+	}
+
+	many := make([]string, MaxPodLookupNames+5)
+	for i := range many {
+		many[i] = fmt.Sprintf("pod-%d", i)
+	}
+	query, err = BuildPodLookupQuery(many, testClusterResourceID, nil)
+	if err != nil {
+		t.Fatalf("BuildPodLookupQuery returned error: %v", err)
+	}
+	if strings.Contains(query, fmt.Sprintf("'pod-%d'", MaxPodLookupNames)) || strings.Contains(query, "Namespace in") {
+		t.Errorf("Expected at most %d names and no namespace filter, got %s", MaxPodLookupNames, query)
+	}
+
+	if _, err := BuildPodLookupQuery([]string{"VM_01"}, testClusterResourceID, nil); err == nil {
+		t.Error("Expected an error when no name is a valid pod name")
+	}
+	if _, err := BuildPodLookupQuery([]string{"redis-0"}, "not-a-resource-id", nil); err == nil {
+		t.Error("Expected an error for an invalid cluster resource ID")
+	}
+	if _, err := BuildPodLookupQuery([]string{"redis-0"}, testClusterResourceID, []string{"shop'"}); err == nil {
+		t.Error("Expected an error for an invalid allowed namespace")
+	}
+}
@@ -17,6 +17,24 @@ const (
 	MaxAllowedRecords     = 1000
 )
 
+// controlPlaneLogCategories are the AKS control plane log categories of diagnostic settings
+var controlPlaneLogCategories = []string{
+	"kube-apiserver",
+	"kube-audit",
+	"kube-audit-admin",
+	"kube-controller-manager",
+	"kube-scheduler",
+	"cluster-autoscaler",
+	"cloud-controller-manager",
+	"guard",
+	"csi-azuredisk-controller",
+	"csi-azurefile-controller",
+	"csi-snapshot-controller",
+	"fleet-member-agent",
+	"fleet-member-net-controller-manager",
+	"fleet-mcs-controller-manager",
+}
+
 // ValidateControlPlaneLogsParams validates all parameters for control plane logs query
 func ValidateControlPlaneLogsParams(params map[string]interface{}) error {
 	// Validate AKS parameters using common helper
@@ -35,32 +53,16 @@ func ValidateControlPlaneLogsParams(params map[string]interface{}) error {
 
 	// Validate log category
 	logCategory := params["log_category"].(string)
-	validCategories := []string{
-		"kube-apiserver",
-		"kube-audit",
-		"kube-audit-admin",
-		"kube-controller-manager",
-		"kube-scheduler",
-		"cluster-autoscaler",
-		"cloud-controller-manager",
-		"guard",
-		"csi-azuredisk-controller",
-		"csi-azurefile-controller",
-		"csi-snapshot-controller",
-		"fleet-member-agent",
-		"fleet-member-net-controller-manager",
-		"fleet-mcs-controller-manager",
-	}
 
 	valid := false
-	for _, validCat := range validCategories {
+	for _, validCat := range controlPlaneLogCategories {
 		if logCategory == validCat {
 			valid = true
 			break
 		}
 	}
 	if !valid {
-		return fmt.Errorf("invalid log category: %s. Valid categories: %s", logCategory, strings.Join(validCategories, ", "))
+		return fmt.Errorf("invalid log category: %s. Valid categories: %s", logCategory, strings.Join(controlPlaneLogCategories, ", "))
 	}
 
 	// Validate time range
@@ -129,6 +131,22 @@ func ValidateTimeRange(startTime string, params map[string]interface{}) error {
 	return nil
 }
 
+// ValidateQueryWindow validates the time range of a query like ValidateTimeRange and, when
+// end_time is not set, also limits the range from start_time up to now
+func ValidateQueryWindow(startTime string, params map[string]interface{}) error {
+	if err := ValidateTimeRange(startTime, params); err != nil {
+		return err
+	}
+	if endTime, _ := params["end_time"].(string); endTime != "" {
+		return nil
+	}
+	start, _ := time.Parse(time.RFC3339, startTime)
+	if time.Since(start) > MaxQueryRangeDuration {
+		return fmt.Errorf("time range cannot exceed %v: start_time without end_time queries up to now", MaxQueryRangeDuration)
+	}
+	return nil
+}
+
 // GetMaxRecords extracts and validates the max_records parameter
 func GetMaxRecords(params map[string]interface{}) int {
 	if val, ok := params["max_records"].(string); ok && val != "" {
@@ -450,3 +450,23 @@ func TestValidateTimeRange_EdgeCases(t *testing.T) {
 		t.Errorf("Expected exactly 24-hour range to be valid, got error: %v", err)
 	}
 }
+
+func TestValidateQueryWindow(t *testing.T) {
+	// Without end_time the range runs up to now and must stay within the maximum
+	err := ValidateQueryWindow(time.Now().Add(-90*24*time.Hour).Format(time.RFC3339), map[string]interface{}{})
+	if err == nil || !strings.Contains(err.Error(), "time range cannot exceed") {
+		t.Errorf("Expected a start_time 90 days ago without end_time to be rejected, got %v", err)
+	}
+
+	if err := ValidateQueryWindow(time.Now().Add(-23*time.Hour).Format(time.RFC3339), map[string]interface{}{}); err != nil {
+		t.Errorf("Expected a 23 hour window to be valid, got %v", err)
+	}
+
+	start := time.Now().Add(-90 * 24 * time.Hour)
+	err = ValidateQueryWindow(start.Format(time.RFC3339), map[string]interface{}{
+		"end_time": start.Add(time.Hour).Format(time.RFC3339),
+	})
+	if err != nil {
+		t.Errorf("Expected an old window with end_time to be valid, got %v", err)
+	}
+}
@@ -5,14 +5,19 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
-	"github.com/Azure/aks-mcp/internal/azcli"
 	"github.com/Azure/aks-mcp/internal/azureclient"
 	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/azure-sdk-for-go/sdk/monitor/azquery"
 )
 
-// ExtractWorkspaceGUIDFromDiagnosticSettings extracts workspace GUID from diagnostic settings
-func ExtractWorkspaceGUIDFromDiagnosticSettings(subscriptionID, resourceGroup, clusterName string, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
+// logsQueryTimeout bounds a single Log Analytics query
+const logsQueryTimeout = 3 * time.Minute
+
+// ExtractWorkspaceFromDiagnosticSettings returns the resource ID of the Log Analytics workspace
+// of the cluster's first diagnostic setting
+func ExtractWorkspaceFromDiagnosticSettings(subscriptionID, resourceGroup, clusterName string, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
 	// Build cluster resource ID
 	clusterResourceID := buildClusterResourceID(subscriptionID, resourceGroup, clusterName)
 
@@ -32,21 +37,20 @@ func ExtractWorkspaceGUIDFromDiagnosticSettings(subscriptionID, resourceGroup, c
 	if len(diagnosticSettings) > 0 {
 		setting := diagnosticSettings[0]
 		if setting.Properties != nil && setting.Properties.WorkspaceID != nil && *setting.Properties.WorkspaceID != "" {
-			// Extract workspace GUID from the workspace resource ID
-			return getWorkspaceGUID(*setting.Properties.WorkspaceID, cfg)
+			workspaceResourceID := *setting.Properties.WorkspaceID
+			return workspaceResourceID, validateWorkspaceResourceID(workspaceResourceID)
 		}
 	}
 
 	return "", fmt.Errorf("no Log Analytics workspace found in diagnostic settings")
 }
 
-// getWorkspaceGUID extracts the workspace GUID from a workspace resource ID
-func getWorkspaceGUID(workspaceResourceID string, cfg *config.ConfigData) (string, error) {
-	// Parse the workspace resource ID to extract resource group and workspace name
-	// Format: /subscriptions/{sub}/resourcegroups/{rg}/providers/microsoft.operationalinsights/workspaces/{workspace-name}
+// validateWorkspaceResourceID checks that a workspace resource ID names a resource group and workspace
+// Format: /subscriptions/{sub}/resourcegroups/{rg}/providers/microsoft.operationalinsights/workspaces/{workspace-name}
+func validateWorkspaceResourceID(workspaceResourceID string) error {
 	parts := strings.Split(workspaceResourceID, "/")
 	if len(parts) < 8 {
-		return "", fmt.Errorf("invalid workspace resource ID format: %s", workspaceResourceID)
+		return fmt.Errorf("invalid workspace resource ID format: %s", workspaceResourceID)
 	}
 
 	var resourceGroup, workspaceName string
@@ -60,29 +64,42 @@ func getWorkspaceGUID(workspaceResourceID string, cfg *config.ConfigData) (strin
 	}
 
 	if resourceGroup == "" || workspaceName == "" {
-		return "", fmt.Errorf("could not extract resource group and workspace name from: %s", workspaceResourceID)
+		return fmt.Errorf("could not extract resource group and workspace name from: %s", workspaceResourceID)
 	}
+	return nil
+}
 
-	// Query the workspace to get its GUID (customerId)
-	executor := azcli.NewExecutor()
-	cmd := fmt.Sprintf("az monitor log-analytics workspace show --resource-group %s --workspace-name %s --query customerId --output tsv", resourceGroup, workspaceName)
-
-	cmdParams := map[string]interface{}{
-		"command": cmd,
+// queryWorkspace runs a KQL query against a Log Analytics workspace through the Azure SDK.
+// Partial results are returned with Error set; throttling is returned as an *azcore.ResponseError
+// with status 429 once the pipeline has stopped retrying.
+func queryWorkspace(azClient *azureclient.AzureClient, workspaceResourceID, query, timespan string) (azquery.Results, error) {
+	if azClient == nil {
+		return azquery.Results{}, fmt.Errorf("azure client is required but not provided")
+	}
+	if err := validateWorkspaceResourceID(workspaceResourceID); err != nil {
+		return azquery.Results{}, err
 	}
 
-	result, err := executor.Execute(cmdParams, cfg)
+	log.Printf("Executing KQL query against workspace %s: %s", workspaceResourceID, query)
+
+	ctx, cancel := context.WithTimeout(context.Background(), logsQueryTimeout)
+	defer cancel()
+	result, err := azClient.QueryLogs(ctx, workspaceResourceID, query, timespan)
 	if err != nil {
-		return "", fmt.Errorf("failed to get workspace GUID: %w", err)
+		return azquery.Results{}, err
 	}
-
-	// The result should be the workspace GUID, trim any whitespace
-	workspaceGUID := strings.TrimSpace(result)
-	if workspaceGUID == "" {
-		return "", fmt.Errorf("empty workspace GUID returned for workspace: %s", workspaceName)
+	if result.Error != nil {
+		log.Printf("KQL query against workspace %s returned partial results: %v", workspaceResourceID, result.Error)
 	}
+	return result, nil
+}
 
-	return workspaceGUID, nil
+// partialResultNote describes a partial query result, or returns "" when the result is complete
+func partialResultNote(result azquery.Results) string {
+	if result.Error == nil {
+		return ""
+	}
+	return fmt.Sprintf("The query returned partial results: %v", result.Error)
 }
 
 // FindDiagnosticSettingForCategory finds the first diagnostic setting that has the specified log category enabled
@@ -1,6 +1,7 @@
 package monitor
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/Azure/aks-mcp/internal/azcli"
 	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
 	"github.com/Azure/aks-mcp/internal/components/monitor/diagnostics"
 	"github.com/Azure/aks-mcp/internal/config"
 	"github.com/Azure/aks-mcp/internal/tools"
@@ -38,72 +40,41 @@ func mergeMonitoringParams(params map[string]interface{}) (map[string]interface{
 	return merged, nil
 }
 
-// HandleResourceHealthQuery handles the resource health query for AKS clusters
-func HandleResourceHealthQuery(params map[string]interface{}, cfg *config.ConfigData) (string, error) {
-	// Extract and validate parameters
-	subscriptionID, ok := params["subscription_id"].(string)
-	if !ok || subscriptionID == "" {
-		return "", fmt.Errorf("missing or invalid subscription_id parameter")
-	}
-
-	resourceGroup, ok := params["resource_group"].(string)
-	if !ok || resourceGroup == "" {
-		return "", fmt.Errorf("missing or invalid resource_group parameter")
-	}
-
-	clusterName, ok := params["cluster_name"].(string)
-	if !ok || clusterName == "" {
-		return "", fmt.Errorf("missing or invalid cluster_name parameter")
-	}
-
-	startTime, ok := params["start_time"].(string)
-	if !ok || startTime == "" {
-		return "", fmt.Errorf("missing or invalid start_time parameter")
-	}
-
+// HandleResourceHealthQuery reports the availability timeline of an AKS cluster and its VMSS and
+// load balancers from the Resource Health API, along with active service incidents in its region
+func HandleResourceHealthQuery(params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
 	// Validate parameters
 	if err := validateResourceHealthParams(params); err != nil {
 		return "", err
 	}
+	subscriptionID := params["subscription_id"].(string)
+	resourceGroup := params["resource_group"].(string)
+	clusterName := params["cluster_name"].(string)
 
-	// Build resource ID
-	resourceID := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ContainerService/managedClusters/%s",
-		subscriptionID, resourceGroup, clusterName)
-
-	// Build Azure CLI command
-	executor := azcli.NewExecutor()
-	args := []string{
-		"monitor", "activity-log", "list",
-		"--resource-id", resourceID,
-		"--start-time", startTime,
-		"--query", "[?category.value=='ResourceHealth']",
-		"--output", "json",
-	}
-
-	// Add end time if provided
+	start, _ := time.Parse(time.RFC3339, params["start_time"].(string))
+	end := time.Now().UTC()
 	if endTime, ok := params["end_time"].(string); ok && endTime != "" {
-		args = append(args, "--end-time", endTime)
+		end, _ = time.Parse(time.RFC3339, endTime)
 	}
-
-	// Add status filter if provided
-	if status, ok := params["status"].(string); ok && status != "" {
-		// Apply status filter in the query
-		statusFilter := fmt.Sprintf("[?category.value=='ResourceHealth' && properties.currentHealthStatus=='%s']", status)
-		args[len(args)-3] = statusFilter // Replace the query parameter
+	if !end.After(start) {
+		return "", fmt.Errorf("end_time must be after start_time")
 	}
+	status, _ := params["status"].(string)
 
-	// Execute command
-	cmdParams := map[string]interface{}{
-		"command": "az " + strings.Join(args, " "),
+	if azClient == nil {
+		return "", fmt.Errorf("azure client is required but not provided")
 	}
 
-	result, err := executor.Execute(cmdParams, cfg)
+	report, err := QueryResourceHealth(context.Background(), azClient, subscriptionID, resourceGroup, clusterName, start, end, status)
 	if err != nil {
-		return "", fmt.Errorf("failed to execute resource health query: %w", err)
+		return "", fmt.Errorf("failed to query resource health: %w", err)
 	}
 
-	// Return the raw JSON result from Azure CLI
-	return result, nil
+	out, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal resource health report: %w", err)
+	}
+	return string(out), nil
 }
 
 // validateResourceHealthParams validates the parameters for resource health queries
@@ -148,14 +119,14 @@ func validateResourceHealthParams(params map[string]interface{}) error {
 }
 
 // GetResourceHealthHandler returns a ResourceHandler for the resource health tool
-func GetResourceHealthHandler(cfg *config.ConfigData) tools.ResourceHandler {
+func GetResourceHealthHandler(azClient *azureclient.AzureClient, cfg *config.ConfigData) tools.ResourceHandler {
 	return tools.ResourceHandlerFunc(func(params map[string]interface{}, _ *config.ConfigData) (string, error) {
-		return HandleResourceHealthQuery(params, cfg)
+		return HandleResourceHealthQuery(params, azClient, cfg)
 	})
 }
 
 // HandleAppInsightsQuery handles Application Insights telemetry queries for AKS clusters
-func HandleAppInsightsQuery(params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+func HandleAppInsightsQuery(params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
 	// Extract and validate parameters
 	subscriptionID, ok := params["subscription_id"].(string)
 	if !ok || subscriptionID == "" {
@@ -172,6 +143,11 @@ func HandleAppInsightsQuery(params map[string]interface{}, cfg *config.ConfigDat
 		return "", fmt.Errorf("missing or invalid app_insights_name parameter")
 	}
 
+	// Structured analyses build their own query
+	if analysis, _ := params["analysis"].(string); analysis != "" {
+		return HandleAppInsightsAnalysis(params, azClient, cfg)
+	}
+
 	query, ok := params["query"].(string)
 	if !ok || query == "" {
 		return "", fmt.Errorf("missing or invalid query parameter")
@@ -186,48 +162,27 @@ func HandleAppInsightsQuery(params map[string]interface{}, cfg *config.ConfigDat
 	appResourceID := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Insights/components/%s",
 		subscriptionID, resourceGroup, appInsightsName)
 
-	// Build Azure CLI command
-	executor := azcli.NewExecutor()
-	args := []string{
-		"monitor", "app-insights", "query",
-		"--app", appResourceID,
-		"--analytics-query", query,
-		"--output", "json",
-	}
-
-	// Add start time if provided
-	if startTime, ok := params["start_time"].(string); ok && startTime != "" {
-		args = append(args, "--start-time", startTime)
-	}
-
-	// Add end time if provided
-	if endTime, ok := params["end_time"].(string); ok && endTime != "" {
-		args = append(args, "--end-time", endTime)
-	}
-
-	// Add timespan if provided
-	if timespan, ok := params["timespan"].(string); ok && timespan != "" {
-		args = append(args, "--timespan", timespan)
-	}
-
-	// Execute command
-	cmdParams := map[string]interface{}{
-		"command": "az " + strings.Join(args, " "),
+	result, err := runAppInsightsQuery(azClient, appResourceID, query, params, "")
+	if err != nil {
+		return "", err
 	}
-
-	result, err := executor.Execute(cmdParams, cfg)
+	out, err := json.MarshalIndent(result, "", "  ")
 	if err != nil {
-		return "", fmt.Errorf("failed to execute Application Insights query: %w", err)
+		return "", fmt.Errorf("failed to marshal Application Insights query result: %w", err)
 	}
-
-	// Return the raw JSON result from Azure CLI
-	return result, nil
+	return string(out), nil
 }
 
 // validateAppInsightsParams validates the parameters for Application Insights queries
 func validateAppInsightsParams(params map[string]interface{}) error {
-	// Validate required parameters
-	required := []string{"subscription_id", "resource_group", "app_insights_name", "query"}
+	// Validate required parameters; analyses build the query themselves
+	required := []string{"subscription_id", "resource_group", "app_insights_name"}
+	analysis, _ := params["analysis"].(string)
+	if analysis == "" {
+		required = append(required, "query")
+	} else if query, _ := params["query"].(string); query != "" {
+		return fmt.Errorf("use either query or analysis, not both")
+	}
 	for _, param := range required {
 		if value, ok := params[param].(string); !ok || value == "" {
 			return fmt.Errorf("missing or invalid %s parameter", param)
@@ -259,9 +214,9 @@ func validateAppInsightsParams(params map[string]interface{}) error {
 }
 
 // GetAppInsightsHandler returns a ResourceHandler for the Application Insights tool
-func GetAppInsightsHandler(cfg *config.ConfigData) tools.ResourceHandler {
+func GetAppInsightsHandler(azClient *azureclient.AzureClient, cfg *config.ConfigData) tools.ResourceHandler {
 	return tools.ResourceHandlerFunc(func(params map[string]interface{}, _ *config.ConfigData) (string, error) {
-		return HandleAppInsightsQuery(params, cfg)
+		return HandleAppInsightsQuery(params, azClient, cfg)
 	})
 }
 
@@ -283,15 +238,35 @@ func GetAzMonitoringHandler(azClient *azureclient.AzureClient, cfg *config.Confi
 		// Handle different operations
 		switch operation {
 		case string(OpMetrics):
-			return handleMetricsOperation(params, cfg)
+			return handleMetricsOperation(params, azClient, cfg)
 		case string(OpResourceHealth):
-			return handleResourceHealthOperation(params, cfg)
+			return handleResourceHealthOperation(params, azClient, cfg)
 		case string(OpAppInsights):
-			return handleAppInsightsOperation(params, cfg)
+			return handleAppInsightsOperation(params, azClient, cfg)
 		case string(OpDiagnostics):
 			return handleDiagnosticsOperation(params, azClient, cfg)
 		case string(OpControlPlaneLogs):
 			return handleLogsOperation(params, azClient, cfg)
+		case string(OpClusterHealthDashboard):
+			return handleHealthDashboardOperation(params, azClient)
+		case string(OpContainerLogs), string(OpPodInventory), string(OpKubeEvents), string(OpNodeInventory), string(OpInsightsMetrics):
+			return handleContainerInsightsOperation(MonitoringOperationType(operation), params, azClient, cfg)
+		case string(OpAuditInvestigation):
+			return handleAuditInvestigationOperation(params, azClient, cfg)
+		case string(OpLogAnalyticsQuery):
+			return handleLogAnalyticsQueryOperation(params, azClient, cfg)
+		case string(OpActivityLog):
+			return handleActivityLogOperation(params, azClient)
+		case string(OpPromQL):
+			return handlePromQLOperation(params, azClient)
+		case string(OpDiagnosticsEnable):
+			return handleDiagnosticsEnableOperation(params, azClient, cfg)
+		case string(OpAlertsRules):
+			return handleAlertsRulesOperation(params, azClient)
+		case string(OpAlertsFired):
+			return handleAlertsFiredOperation(params, azClient)
+		case string(OpAlertsCreateBaseline):
+			return handleAlertsCreateBaselineOperation(params, azClient, cfg)
 		default:
 			return "", fmt.Errorf("operation '%s' not implemented", operation)
 		}
@@ -300,14 +275,14 @@ func GetAzMonitoringHandler(azClient *azureclient.AzureClient, cfg *config.Confi
 
 // Helper functions for different monitoring operations
 
-func handleMetricsOperation(params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+func handleMetricsOperation(params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
 	queryType, ok := params["query_type"].(string)
 	if !ok {
 		return "", fmt.Errorf("missing or invalid 'query_type' parameter for metrics operation")
 	}
 
 	if !ValidateMetricsQueryType(queryType) {
-		return "", fmt.Errorf("invalid query_type: %s. Supported types: list, list-definitions, list-namespaces", queryType)
+		return "", fmt.Errorf("invalid query_type: %s. Supported types: list, list-definitions, list-namespaces, catalog", queryType)
 	}
 
 	// Extract parameters from JSON string
@@ -322,6 +297,14 @@ func handleMetricsOperation(params map[string]interface{}, cfg *config.ConfigDat
 		return "", fmt.Errorf("failed to parse parameters JSON: %w", err)
 	}
 
+	// Metric values are read through the SDK and summarized; definitions and namespaces still use the CLI
+	switch queryType {
+	case "list":
+		return handleMetricsList(jsonParams, azClient)
+	case "catalog":
+		return handleMetricsCatalog(jsonParams)
+	}
+
 	// Convert JSON parameters to command-line argument format [--key1 value1 --key2 value2]
 	var args []string
 	for key, value := range jsonParams {
@@ -344,7 +327,76 @@ func handleMetricsOperation(params map[string]interface{}, cfg *config.ConfigDat
 	return executor.Execute(cmdParams, cfg)
 }
 
-func handleResourceHealthOperation(params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+// handleMetricsList queries metric values and returns a summary per time series
+func handleMetricsList(jsonParams map[string]interface{}, azClient *azureclient.AzureClient) (string, error) {
+	query, err := ParseMetricsQuery(jsonParams, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	result, err := QueryMetrics(context.Background(), azClient, query)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal metrics summary: %w", err)
+	}
+	return string(out), nil
+}
+
+// handleMetricsCatalog returns the built-in AKS metric catalog
+func handleMetricsCatalog(jsonParams map[string]interface{}) (string, error) {
+	category, _ := jsonParams["category"].(string)
+	definitions, err := GetMetricCatalog(category)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := json.MarshalIndent(definitions, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal metric catalog: %w", err)
+	}
+	return string(out), nil
+}
+
+func handleHealthDashboardOperation(params map[string]interface{}, azClient *azureclient.AzureClient) (string, error) {
+	mergedParams, err := mergeMonitoringParams(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to merge parameters: %w", err)
+	}
+
+	subscriptionID, resourceGroup, clusterName, err := common.ExtractAKSParameters(mergedParams)
+	if err != nil {
+		return "", err
+	}
+	if azClient == nil {
+		return "", fmt.Errorf("azure client is required but not provided")
+	}
+
+	window := DefaultMetricsWindow
+	if value, ok := mergedParams["window"].(string); ok && value != "" {
+		if window, err = ParseMetricsWindow(value); err != nil {
+			return "", err
+		}
+	}
+
+	resourceID := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ContainerService/managedClusters/%s",
+		subscriptionID, resourceGroup, clusterName)
+	query := func(ctx context.Context, q *MetricsQuery) (*MetricsResult, error) {
+		return QueryMetrics(ctx, azClient, q)
+	}
+	dashboard := BuildClusterHealthDashboard(context.Background(), query, resourceID, window, time.Now())
+
+	out, err := json.MarshalIndent(dashboard, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal cluster health dashboard: %w", err)
+	}
+	return string(out), nil
+}
+
+func handleResourceHealthOperation(params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
 	// Merge parameters from top-level and nested JSON
 	mergedParams, err := mergeMonitoringParams(params)
 	if err != nil {
@@ -352,18 +404,23 @@ func handleResourceHealthOperation(params map[string]interface{}, cfg *config.Co
 	}
 
 	// Use existing resource health handler
-	return GetResourceHealthHandler(cfg).Handle(mergedParams, cfg)
+	return GetResourceHealthHandler(azClient, cfg).Handle(mergedParams, cfg)
 }
 
-func handleAppInsightsOperation(params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+func handleAppInsightsOperation(params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
 	// Merge parameters from top-level and nested JSON
 	mergedParams, err := mergeMonitoringParams(params)
 	if err != nil {
 		return "", fmt.Errorf("failed to merge parameters: %w", err)
 	}
 
+	// Analyses can map role instances to the cluster's pods, which needs the Azure client
+	if analysis, _ := mergedParams["analysis"].(string); analysis != "" {
+		return HandleAppInsightsAnalysis(mergedParams, azClient, cfg)
+	}
+
 	// Use existing app insights handler
-	return GetAppInsightsHandler(cfg).Handle(mergedParams, cfg)
+	return GetAppInsightsHandler(azClient, cfg).Handle(mergedParams, cfg)
 }
 
 func handleDiagnosticsOperation(params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
@@ -387,3 +444,227 @@ func handleLogsOperation(params map[string]interface{}, azClient *azureclient.Az
 	// Use existing control plane logs handler
 	return diagnostics.GetControlPlaneLogsHandler(azClient, cfg).Handle(mergedParams, cfg)
 }
+
+func handleContainerInsightsOperation(operation MonitoringOperationType, params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
+	// Merge parameters from top-level and nested JSON
+	mergedParams, err := mergeMonitoringParams(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to merge parameters: %w", err)
+	}
+
+	return diagnostics.HandleContainerInsightsQuery(containerInsightsTables[operation], mergedParams, azClient, cfg)
+}
+
+func handleAuditInvestigationOperation(params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
+	// Merge parameters from top-level and nested JSON
+	mergedParams, err := mergeMonitoringParams(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to merge parameters: %w", err)
+	}
+
+	return diagnostics.HandleAuditInvestigation(mergedParams, azClient, cfg)
+}
+
+func handleLogAnalyticsQueryOperation(params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
+	// Merge parameters from top-level and nested JSON
+	mergedParams, err := mergeMonitoringParams(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to merge parameters: %w", err)
+	}
+
+	return diagnostics.HandleLogAnalyticsQuery(mergedParams, azClient, cfg)
+}
+
+func handleDiagnosticsEnableOperation(params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
+	// Merge parameters from top-level and nested JSON
+	mergedParams, err := mergeMonitoringParams(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to merge parameters: %w", err)
+	}
+
+	return diagnostics.HandleDiagnosticsEnable(mergedParams, azClient, cfg)
+}
+
+func handleActivityLogOperation(params map[string]interface{}, azClient *azureclient.AzureClient) (string, error) {
+	// Merge parameters from top-level and nested JSON
+	mergedParams, err := mergeMonitoringParams(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to merge parameters: %w", err)
+	}
+
+	subscriptionID, resourceGroup, clusterName, err := common.ExtractAKSParameters(mergedParams)
+	if err != nil {
+		return "", err
+	}
+	start, end, err := parseActivityLogWindow(mergedParams, time.Now().UTC())
+	if err != nil {
+		return "", err
+	}
+	if azClient == nil {
+		return "", fmt.Errorf("azure client is required but not provided")
+	}
+
+	report, err := QueryActivityLog(context.Background(), azClient, subscriptionID, resourceGroup, clusterName, start, end)
+	if err != nil {
+		return "", fmt.Errorf("failed to query activity log for cluster %s: %w", clusterName, err)
+	}
+
+	out, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal activity log report: %w", err)
+	}
+	return string(out), nil
+}
+
+func handlePromQLOperation(params map[string]interface{}, azClient *azureclient.AzureClient) (string, error) {
+	// The query is validated from the nested parameters only; the cluster may be given at either level
+	jsonParams := map[string]interface{}{}
+	if parametersStr, ok := params["parameters"].(string); ok && parametersStr != "" {
+		if err := json.Unmarshal([]byte(parametersStr), &jsonParams); err != nil {
+			return "", fmt.Errorf("failed to parse parameters JSON: %w", err)
+		}
+	}
+	query, err := ParsePromQLQuery(jsonParams, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	var result interface{} = promqlLibrary
+	if query.Mode != PromQLModeLibrary {
+		mergedParams, err := mergeMonitoringParams(params)
+		if err != nil {
+			return "", fmt.Errorf("failed to merge parameters: %w", err)
+		}
+		subscriptionID, resourceGroup, clusterName, err := common.ExtractAKSParameters(mergedParams)
+		if err != nil {
+			return "", err
+		}
+		if azClient == nil {
+			return "", fmt.Errorf("azure client is required but not provided")
+		}
+		if result, err = QueryPromQL(context.Background(), azClient, subscriptionID, resourceGroup, clusterName, query); err != nil {
+			return "", err
+		}
+	}
+
+	out, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal PromQL result: %w", err)
+	}
+	return string(out), nil
+}
+
+func handleAlertsRulesOperation(params map[string]interface{}, azClient *azureclient.AzureClient) (string, error) {
+	// Merge parameters from top-level and nested JSON
+	mergedParams, err := mergeMonitoringParams(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to merge parameters: %w", err)
+	}
+
+	subscriptionID, resourceGroup, clusterName, err := common.ExtractAKSParameters(mergedParams)
+	if err != nil {
+		return "", err
+	}
+	if azClient == nil {
+		return "", fmt.Errorf("azure client is required but not provided")
+	}
+
+	report, err := ListClusterAlertRules(context.Background(), azClient, subscriptionID, resourceGroup, clusterName)
+	if err != nil {
+		return "", fmt.Errorf("failed to list alert rules for cluster %s: %w", clusterName, err)
+	}
+
+	out, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal alert rules report: %w", err)
+	}
+	return string(out), nil
+}
+
+func handleAlertsFiredOperation(params map[string]interface{}, azClient *azureclient.AzureClient) (string, error) {
+	// Merge parameters from top-level and nested JSON
+	mergedParams, err := mergeMonitoringParams(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to merge parameters: %w", err)
+	}
+
+	subscriptionID, resourceGroup, clusterName, err := common.ExtractAKSParameters(mergedParams)
+	if err != nil {
+		return "", err
+	}
+	start, end, err := parseTimeWindow(mergedParams, time.Now().UTC(), DefaultFiredAlertsWindow, azureclient.MaxFiredAlertsWindow, "fired alerts are kept")
+	if err != nil {
+		return "", err
+	}
+	state, _ := mergedParams["state"].(string)
+	monitorCondition, _ := mergedParams["monitor_condition"].(string)
+	if err := validateFiredAlertFilters(state, monitorCondition); err != nil {
+		return "", err
+	}
+	if azClient == nil {
+		return "", fmt.Errorf("azure client is required but not provided")
+	}
+
+	clusterResourceID := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ContainerService/managedClusters/%s",
+		subscriptionID, resourceGroup, clusterName)
+	alerts, err := azClient.ListFiredAlerts(context.Background(), subscriptionID, clusterResourceID, start, end)
+	if err != nil {
+		return "", fmt.Errorf("failed to list fired alerts for cluster %s: %w", clusterName, err)
+	}
+
+	report := SummarizeFiredAlerts(alerts, state, monitorCondition)
+	report.ClusterResourceID = clusterResourceID
+	report.Start = start.UTC().Format(time.RFC3339)
+	report.End = end.UTC().Format(time.RFC3339)
+
+	out, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal fired alerts report: %w", err)
+	}
+	return string(out), nil
+}
+
+func handleAlertsCreateBaselineOperation(params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
+	// Merge parameters from top-level and nested JSON
+	mergedParams, err := mergeMonitoringParams(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to merge parameters: %w", err)
+	}
+
+	return HandleCreateBaselineAlerts(mergedParams, azClient, cfg)
+}
+
+// parseActivityLogWindow reads start_time and end_time, defaulting to the last 7 days and
+// keeping the window within the 90 days of activity log retention
+func parseActivityLogWindow(params map[string]interface{}, now time.Time) (time.Time, time.Time, error) {
+	return parseTimeWindow(params, now, DefaultActivityLogWindow, MaxActivityLogWindow, "the activity log is kept")
+}
+
+// parseTimeWindow reads start_time and end_time as RFC3339 times. Without start_time the
+// window is the last defaultWindow; a start_time older than retention is rejected, with
+// kept describing the data for the error message.
+func parseTimeWindow(params map[string]interface{}, now time.Time, defaultWindow, retention time.Duration, kept string) (time.Time, time.Time, error) {
+	start := now.Add(-defaultWindow)
+	end := now
+	if value, ok := params["start_time"].(string); ok && value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return start, end, fmt.Errorf("invalid start_time format, expected RFC3339 (ISO 8601): %w", err)
+		}
+		start = parsed
+	}
+	if value, ok := params["end_time"].(string); ok && value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return start, end, fmt.Errorf("invalid end_time format, expected RFC3339 (ISO 8601): %w", err)
+		}
+		end = parsed
+	}
+	if !end.After(start) {
+		return start, end, fmt.Errorf("end_time must be after start_time")
+	}
+	if start.Before(now.Add(-retention)) {
+		return start, end, fmt.Errorf("start_time is older than the %d days %s", int(retention.Hours()/24), kept)
+	}
+	return start, end, nil
+}
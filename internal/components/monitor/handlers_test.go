@@ -57,6 +57,16 @@ func TestHandleAppInsightsQuery_MissingParameters(t *testing.T) {
 				"app_insights_name": "test-ai",
 			},
 		},
+		{
+			name: "query and analysis",
+			params: map[string]interface{}{
+				"subscription_id":   "test-sub",
+				"resource_group":    "test-rg",
+				"app_insights_name": "test-ai",
+				"query":             "requests | limit 10",
+				"analysis":          "failed_requests",
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -69,6 +79,18 @@ func TestHandleAppInsightsQuery_MissingParameters(t *testing.T) {
 	}
 }
 
+func TestValidateAppInsightsParams_Analysis(t *testing.T) {
+	params := map[string]interface{}{
+		"subscription_id":   "test-sub",
+		"resource_group":    "test-rg",
+		"app_insights_name": "test-ai",
+		"analysis":          "top_exceptions",
+	}
+	if err := validateAppInsightsParams(params); err != nil {
+		t.Errorf("Expected an analysis to stand in for query, got %v", err)
+	}
+}
+
 func TestValidateAppInsightsParams_TimeValidation(t *testing.T) {
 	testCases := []struct {
 		name        string
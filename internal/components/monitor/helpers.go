@@ -3,12 +3,27 @@ package monitor
 import (
 	"fmt"
 	"slices"
+
+	"github.com/Azure/aks-mcp/internal/components/monitor/diagnostics"
 )
 
 // supportedMonitoringOperations defines all supported monitoring operations
 var supportedMonitoringOperations = []string{
 	string(OpMetrics), string(OpResourceHealth), string(OpAppInsights),
-	string(OpDiagnostics), string(OpControlPlaneLogs),
+	string(OpDiagnostics), string(OpControlPlaneLogs), string(OpClusterHealthDashboard),
+	string(OpContainerLogs), string(OpPodInventory), string(OpKubeEvents),
+	string(OpNodeInventory), string(OpInsightsMetrics), string(OpAuditInvestigation),
+	string(OpLogAnalyticsQuery), string(OpActivityLog), string(OpPromQL), string(OpDiagnosticsEnable),
+	string(OpAlertsRules), string(OpAlertsFired), string(OpAlertsCreateBaseline),
+}
+
+// containerInsightsTables maps each Container Insights operation to the table it queries
+var containerInsightsTables = map[MonitoringOperationType]string{
+	OpContainerLogs:   diagnostics.TableContainerLogV2,
+	OpPodInventory:    diagnostics.TableKubePodInventory,
+	OpKubeEvents:      diagnostics.TableKubeEvents,
+	OpNodeInventory:   diagnostics.TableKubeNodeInventory,
+	OpInsightsMetrics: diagnostics.TableInsightsMetrics,
 }
 
 // ValidateMonitoringOperation checks if the monitoring operation is supported
@@ -16,6 +31,11 @@ func ValidateMonitoringOperation(operation string) bool {
 	return slices.Contains(supportedMonitoringOperations, operation)
 }
 
+// IsReadOnlyMonitoringOperation reports whether the operation only reads data
+func IsReadOnlyMonitoringOperation(operation string) bool {
+	return ValidateMonitoringOperation(operation) && operation != string(OpDiagnosticsEnable) && operation != string(OpAlertsCreateBaseline)
+}
+
 // GetSupportedMonitoringOperations returns all supported monitoring operations
 func GetSupportedMonitoringOperations() []string {
 	return supportedMonitoringOperations
@@ -23,7 +43,7 @@ func GetSupportedMonitoringOperations() []string {
 
 // ValidateMetricsQueryType checks if the metrics query type is supported
 func ValidateMetricsQueryType(queryType string) bool {
-	supportedTypes := []string{"list", "list-definitions", "list-namespaces"}
+	supportedTypes := []string{"list", "list-definitions", "list-namespaces", "catalog"}
 	return slices.Contains(supportedTypes, queryType)
 }
 
@@ -0,0 +1,336 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+)
+
+// MaxMetricSeries limits the number of series summarized from one query
+const MaxMetricSeries = 50
+
+// metricsAggregations are the aggregations a series can be summarized on
+var metricsAggregations = []string{"Average", "Minimum", "Maximum", "Total", "Count"}
+
+// metricsListParams are the parameters accepted by the metrics 'list' query
+var metricsListParams = []string{
+	"resource", "metrics", "aggregation", "window", "start-time", "end-time", "interval",
+	"filter", "namespace", "sigma", "include_points", "max_points",
+}
+
+// MetricsQuery is a validated metrics 'list' request
+type MetricsQuery struct {
+	ResourceID  string
+	Metrics     []string
+	Aggregation string
+	Start       time.Time
+	End         time.Time
+	Interval    string
+	// IntervalAutoSelected is true when the interval was picked from the window length
+	IntervalAutoSelected bool
+	Filter               string
+	Namespace            string
+	Sigma                float64
+	// MaxPoints is the number of downsampled points returned per series, 0 for none
+	MaxPoints int
+}
+
+// MetricsResult is the summarized response of a metrics 'list' query
+type MetricsResult struct {
+	Resource    string          `json:"resource"`
+	Timespan    string          `json:"timespan"`
+	Interval    string          `json:"interval"`
+	Aggregation string          `json:"aggregation"`
+	Series      []SeriesSummary `json:"series"`
+	Errors      []string        `json:"errors,omitempty"`
+	Notes       []string        `json:"notes,omitempty"`
+}
+
+// ParseMetricsQuery validates the JSON parameters of a metrics 'list' query. A relative
+// window ("last 6h") and an explicit start-time/end-time are mutually exclusive; without
+// either the last hour is queried.
+func ParseMetricsQuery(params map[string]interface{}, now time.Time) (*MetricsQuery, error) {
+	for key := range params {
+		if !slices.Contains(metricsListParams, key) {
+			return nil, fmt.Errorf("unsupported metrics parameter '%s'. Supported parameters: %s", key, strings.Join(metricsListParams, ", "))
+		}
+	}
+
+	query := &MetricsQuery{Sigma: DefaultAnomalySigma}
+
+	query.ResourceID = stringValue(params, "resource")
+	if query.ResourceID == "" {
+		return nil, fmt.Errorf("missing 'resource' parameter for metrics list")
+	}
+	if _, err := arm.ParseResourceID(query.ResourceID); err != nil {
+		return nil, fmt.Errorf("invalid resource ID '%s': %v", query.ResourceID, err)
+	}
+
+	// The Azure CLI accepts space separated metric names, the REST API comma separated ones
+	for _, name := range strings.FieldsFunc(stringValue(params, "metrics"), func(r rune) bool { return r == ',' || r == ' ' }) {
+		query.Metrics = append(query.Metrics, name)
+	}
+	if len(query.Metrics) == 0 {
+		return nil, fmt.Errorf("missing 'metrics' parameter for metrics list")
+	}
+
+	// Without an explicit aggregation, use the catalog default when all metrics share one
+	query.Aggregation = defaultAggregation(query.Metrics)
+	if query.Aggregation == "" {
+		query.Aggregation = "Average"
+	}
+	if aggregation := stringValue(params, "aggregation"); aggregation != "" {
+		query.Aggregation = ""
+		for _, supported := range metricsAggregations {
+			if strings.EqualFold(aggregation, supported) {
+				query.Aggregation = supported
+			}
+		}
+		if query.Aggregation == "" {
+			return nil, fmt.Errorf("unsupported aggregation '%s': use one of %s", aggregation, strings.Join(metricsAggregations, ", "))
+		}
+	}
+
+	window := stringValue(params, "window")
+	startTime, endTime := stringValue(params, "start-time"), stringValue(params, "end-time")
+	switch {
+	case window != "" && (startTime != "" || endTime != ""):
+		return nil, fmt.Errorf("use either 'window' or 'start-time'/'end-time', not both")
+	case startTime != "":
+		start, err := time.Parse(time.RFC3339, startTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start-time format, expected RFC3339: %w", err)
+		}
+		end := now
+		if endTime != "" {
+			if end, err = time.Parse(time.RFC3339, endTime); err != nil {
+				return nil, fmt.Errorf("invalid end-time format, expected RFC3339: %w", err)
+			}
+		}
+		if !end.After(start) {
+			return nil, fmt.Errorf("end-time must be after start-time")
+		}
+		query.Start, query.End = start, end
+	case endTime != "":
+		return nil, fmt.Errorf("end-time requires start-time")
+	default:
+		duration := DefaultMetricsWindow
+		if window != "" {
+			var err error
+			if duration, err = ParseMetricsWindow(window); err != nil {
+				return nil, err
+			}
+		}
+		query.Start, query.End = now.Add(-duration), now
+	}
+
+	if interval := stringValue(params, "interval"); interval != "" {
+		normalized, err := normalizeMetricsInterval(interval)
+		if err != nil {
+			return nil, err
+		}
+		query.Interval = normalized
+	} else {
+		query.Interval = PickMetricsInterval(query.End.Sub(query.Start))
+		query.IntervalAutoSelected = true
+	}
+
+	query.Filter = stringValue(params, "filter")
+	query.Namespace = stringValue(params, "namespace")
+
+	if sigma, ok := params["sigma"].(float64); ok {
+		if sigma <= 0 {
+			return nil, fmt.Errorf("sigma must be positive")
+		}
+		query.Sigma = sigma
+	}
+
+	if includePoints, _ := params["include_points"].(bool); includePoints {
+		query.MaxPoints = DefaultMetricsMaxPoints
+		if maxPoints, ok := params["max_points"].(float64); ok && maxPoints > 0 {
+			query.MaxPoints = min(int(maxPoints), MaxMetricsMaxPoints)
+		}
+	}
+
+	return query, nil
+}
+
+// Timespan returns the query window in the format used by the metrics API
+func (q *MetricsQuery) Timespan() string {
+	return fmt.Sprintf("%s/%s", q.Start.UTC().Format(time.RFC3339), q.End.UTC().Format(time.RFC3339))
+}
+
+// QueryMetrics reads metric values with the Azure Monitor metrics API and summarizes them
+func QueryMetrics(ctx context.Context, azClient *azureclient.AzureClient, query *MetricsQuery) (*MetricsResult, error) {
+	if azClient == nil {
+		return nil, fmt.Errorf("azure client is required but not provided")
+	}
+	resourceID, err := arm.ParseResourceID(query.ResourceID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid resource ID '%s': %v", query.ResourceID, err)
+	}
+	clients, err := azClient.GetOrCreateClientsForSubscription(resourceID.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	options := &armmonitor.MetricsClientListOptions{
+		Metricnames: to.Ptr(strings.Join(query.Metrics, ",")),
+		Aggregation: to.Ptr(query.Aggregation),
+		Timespan:    to.Ptr(query.Timespan()),
+		Interval:    to.Ptr(query.Interval),
+		// Let the API coarsen the interval instead of failing when a metric does not support it
+		AutoAdjustTimegrain: to.Ptr(true),
+	}
+	if query.Filter != "" {
+		options.Filter = to.Ptr(query.Filter)
+		options.Top = to.Ptr(int32(MaxMetricSeries))
+	}
+	if query.Namespace != "" {
+		options.Metricnamespace = to.Ptr(query.Namespace)
+	}
+
+	resp, err := clients.MetricsClient.List(ctx, strings.TrimPrefix(query.ResourceID, "/"), options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query metrics: %w", err)
+	}
+	return SummarizeMetricsResponse(resp.Response, query), nil
+}
+
+// SummarizeMetricsResponse converts a metrics API response into one summary per time series
+func SummarizeMetricsResponse(resp armmonitor.Response, query *MetricsQuery) *MetricsResult {
+	result := &MetricsResult{
+		Resource:    query.ResourceID,
+		Timespan:    query.Timespan(),
+		Interval:    query.Interval,
+		Aggregation: query.Aggregation,
+		Series:      []SeriesSummary{},
+	}
+	if resp.Timespan != nil {
+		result.Timespan = *resp.Timespan
+	}
+	if resp.Interval != nil {
+		result.Interval = *resp.Interval
+		if result.Interval != query.Interval {
+			result.Notes = append(result.Notes, fmt.Sprintf("Azure Monitor adjusted the interval from %s to %s", query.Interval, result.Interval))
+		}
+	}
+	if query.IntervalAutoSelected {
+		result.Notes = append(result.Notes, fmt.Sprintf("Interval %s was selected for the window; pass 'interval' to override it", query.Interval))
+	}
+
+	truncated := false
+	for _, metric := range resp.Value {
+		if metric == nil {
+			continue
+		}
+		name := ""
+		if metric.Name != nil && metric.Name.Value != nil {
+			name = *metric.Name.Value
+		}
+		if metric.ErrorCode != nil && *metric.ErrorCode != "" && *metric.ErrorCode != "Success" {
+			message := *metric.ErrorCode
+			if metric.ErrorMessage != nil {
+				message = *metric.ErrorMessage
+			}
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %s", name, message))
+			continue
+		}
+
+		for _, series := range metric.Timeseries {
+			if series == nil {
+				continue
+			}
+			if len(result.Series) == MaxMetricSeries {
+				truncated = true
+				break
+			}
+			summary := SeriesSummary{
+				Metric:      name,
+				Aggregation: query.Aggregation,
+				Dimensions:  seriesDimensions(series),
+			}
+			if metric.Unit != nil {
+				summary.Unit = string(*metric.Unit)
+			}
+			points, missing := seriesPoints(series, query.Aggregation)
+			SummarizeSeries(&summary, points, query.Sigma, query.MaxPoints)
+			summary.Missing = missing
+			result.Series = append(result.Series, summary)
+		}
+	}
+	if truncated {
+		result.Notes = append(result.Notes, fmt.Sprintf("Only the first %d series are summarized; narrow the filter to see the rest", MaxMetricSeries))
+	}
+	return result
+}
+
+// seriesPoints extracts the aggregated values of a series ordered by time
+func seriesPoints(series *armmonitor.TimeSeriesElement, aggregation string) ([]MetricPoint, int) {
+	points := make([]MetricPoint, 0, len(series.Data))
+	missing := 0
+	for _, value := range series.Data {
+		if value == nil || value.TimeStamp == nil {
+			continue
+		}
+		var v *float64
+		switch aggregation {
+		case "Minimum":
+			v = value.Minimum
+		case "Maximum":
+			v = value.Maximum
+		case "Total":
+			v = value.Total
+		case "Count":
+			v = value.Count
+		default:
+			v = value.Average
+		}
+		if v == nil {
+			missing++
+			continue
+		}
+		points = append(points, MetricPoint{Time: *value.TimeStamp, Value: *v})
+	}
+	sort.SliceStable(points, func(i, j int) bool { return points[i].Time.Before(points[j].Time) })
+	return points, missing
+}
+
+// seriesDimensions returns the dimension values of a series split by a filter
+func seriesDimensions(series *armmonitor.TimeSeriesElement) map[string]string {
+	if len(series.Metadatavalues) == 0 {
+		return nil
+	}
+	dimensions := make(map[string]string, len(series.Metadatavalues))
+	for _, metadata := range series.Metadatavalues {
+		if metadata == nil || metadata.Name == nil || metadata.Name.Value == nil {
+			continue
+		}
+		value := ""
+		if metadata.Value != nil {
+			value = *metadata.Value
+		}
+		dimensions[*metadata.Name.Value] = value
+	}
+	return dimensions
+}
+
+// stringValue reads a parameter that may have been passed as a string or a number
+func stringValue(params map[string]interface{}, key string) string {
+	switch v := params[key].(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
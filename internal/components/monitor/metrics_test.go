@@ -0,0 +1,201 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+)
+
+const testClusterResourceID = "/subscriptions/sub-1/resourceGroups/rg-1/providers/Microsoft.ContainerService/managedClusters/aks-1"
+
+func TestParseMetricsWindow(t *testing.T) {
+	tests := []struct {
+		window  string
+		want    time.Duration
+		wantErr bool
+	}{
+		{window: "last 6h", want: 6 * time.Hour},
+		{window: "30m", want: 30 * time.Minute},
+		{window: "Last 7 days", want: 7 * 24 * time.Hour},
+		{window: "last 90 minutes", want: 90 * time.Minute},
+		{window: "last 0h", wantErr: true},
+		{window: "last 100d", wantErr: true},
+		{window: "yesterday", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.window, func(t *testing.T) {
+			got, err := ParseMetricsWindow(tt.window)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPickMetricsInterval(t *testing.T) {
+	tests := map[time.Duration]string{
+		time.Hour:           "PT1M",
+		6 * time.Hour:       "PT5M",
+		24 * time.Hour:      "PT5M",
+		7 * 24 * time.Hour:  "PT1H",
+		30 * 24 * time.Hour: "PT6H",
+		93 * 24 * time.Hour: "PT12H",
+	}
+	for window, want := range tests {
+		if got := PickMetricsInterval(window); got != want {
+			t.Errorf("window %v: expected %s, got %s", window, want, got)
+		}
+	}
+}
+
+func TestParseMetricsQuery(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	query, err := ParseMetricsQuery(map[string]interface{}{
+		"resource":       testClusterResourceID,
+		"metrics":        "node_cpu_usage_percentage node_memory_working_set_percentage",
+		"aggregation":    "maximum",
+		"window":         "last 6h",
+		"include_points": true,
+		"max_points":     float64(1000),
+	}, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(query.Metrics) != 2 || query.Aggregation != "Maximum" {
+		t.Errorf("unexpected metrics or aggregation: %v %s", query.Metrics, query.Aggregation)
+	}
+	if query.Interval != "PT5M" || !query.IntervalAutoSelected {
+		t.Errorf("expected the interval to be picked automatically, got %s", query.Interval)
+	}
+	if query.Timespan() != "2026-10-18T06:00:00Z/2026-10-18T12:00:00Z" {
+		t.Errorf("unexpected timespan %s", query.Timespan())
+	}
+	if query.MaxPoints != MaxMetricsMaxPoints {
+		t.Errorf("expected max_points to be capped at %d, got %d", MaxMetricsMaxPoints, query.MaxPoints)
+	}
+
+	errorCases := []struct {
+		name    string
+		params  map[string]interface{}
+		wantErr string
+	}{
+		{"missing resource", map[string]interface{}{"metrics": "cpu"}, "missing 'resource'"},
+		{"missing metrics", map[string]interface{}{"resource": testClusterResourceID}, "missing 'metrics'"},
+		{"unknown parameter", map[string]interface{}{"resource": testClusterResourceID, "metrics": "cpu", "output": "table"}, "unsupported metrics parameter 'output'"},
+		{"window and start-time", map[string]interface{}{"resource": testClusterResourceID, "metrics": "cpu", "window": "1h", "start-time": "2026-10-18T00:00:00Z"}, "not both"},
+		{"bad aggregation", map[string]interface{}{"resource": testClusterResourceID, "metrics": "cpu", "aggregation": "p99"}, "unsupported aggregation"},
+		{"bad interval", map[string]interface{}{"resource": testClusterResourceID, "metrics": "cpu", "interval": "PT2M"}, "unsupported interval"},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseMetricsQuery(tc.params, now)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSummarizeSeries(t *testing.T) {
+	start := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	var points []MetricPoint
+	for i := 0; i < 60; i++ {
+		value := 10 + float64(i)*0.5
+		if i == 30 {
+			value = 500
+		}
+		points = append(points, MetricPoint{Time: start.Add(time.Duration(i) * time.Minute), Value: value})
+	}
+
+	summary := SeriesSummary{Metric: "cpu"}
+	SummarizeSeries(&summary, points, 3, 12)
+
+	if summary.Points != 60 || summary.Min != 10 || summary.Max != 500 || summary.Last != 39.5 {
+		t.Errorf("unexpected statistics: %+v", summary)
+	}
+	if summary.P95 < 37 || summary.P95 > 40 {
+		t.Errorf("expected p95 within the regular values, got %v", summary.P95)
+	}
+	if summary.Trend.Direction != TrendRising {
+		t.Errorf("expected a rising trend, got %+v", summary.Trend)
+	}
+	if len(summary.Anomalies) != 1 || summary.Anomalies[0].Value != 500 {
+		t.Errorf("expected the spike as the only anomaly, got %+v", summary.Anomalies)
+	}
+	if len(summary.Samples) != 12 {
+		t.Errorf("expected 12 samples, got %d", len(summary.Samples))
+	}
+
+	flat := SeriesSummary{}
+	SummarizeSeries(&flat, []MetricPoint{{start, 5}, {start.Add(time.Minute), 5}, {start.Add(2 * time.Minute), 5}}, 3, 0)
+	if flat.Trend.Direction != TrendFlat || len(flat.Anomalies) != 0 || flat.Samples != nil {
+		t.Errorf("unexpected summary of a constant series: %+v", flat)
+	}
+}
+
+func TestSummarizeMetricsResponse(t *testing.T) {
+	start := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	query := &MetricsQuery{
+		ResourceID:  testClusterResourceID,
+		Aggregation: "Average",
+		Start:       start,
+		End:         start.Add(time.Hour),
+		Interval:    "PT1M",
+		Sigma:       DefaultAnomalySigma,
+	}
+	unit := armmonitor.UnitPercent
+	resp := armmonitor.Response{
+		Interval: to.Ptr("PT5M"),
+		Value: []*armmonitor.Metric{
+			{
+				Name: &armmonitor.LocalizableString{Value: to.Ptr("node_cpu_usage_percentage")},
+				Unit: &unit,
+				Timeseries: []*armmonitor.TimeSeriesElement{{
+					Metadatavalues: []*armmonitor.MetadataValue{{Name: &armmonitor.LocalizableString{Value: to.Ptr("node")}, Value: to.Ptr("aks-nodepool1-0")}},
+					Data: []*armmonitor.MetricValue{
+						{TimeStamp: to.Ptr(start.Add(5 * time.Minute)), Average: to.Ptr(20.0)},
+						{TimeStamp: to.Ptr(start), Average: to.Ptr(10.0)},
+						{TimeStamp: to.Ptr(start.Add(10 * time.Minute))},
+					},
+				}},
+			},
+			{
+				Name:         &armmonitor.LocalizableString{Value: to.Ptr("missing_metric")},
+				ErrorCode:    to.Ptr("BadRequest"),
+				ErrorMessage: to.Ptr("metric not found"),
+			},
+		},
+	}
+
+	result := SummarizeMetricsResponse(resp, query)
+
+	if len(result.Series) != 1 {
+		t.Fatalf("expected one series, got %d", len(result.Series))
+	}
+	series := result.Series[0]
+	if series.Unit != "Percent" || series.Dimensions["node"] != "aks-nodepool1-0" {
+		t.Errorf("unexpected series metadata: %+v", series)
+	}
+	if series.Points != 2 || series.Missing != 1 || series.Last != 20 || series.Avg != 15 {
+		t.Errorf("unexpected series statistics: %+v", series)
+	}
+	if result.Interval != "PT5M" || len(result.Notes) != 1 {
+		t.Errorf("expected a note about the adjusted interval, got %s %v", result.Interval, result.Notes)
+	}
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0], "metric not found") {
+		t.Errorf("expected the metric error to be reported, got %v", result.Errors)
+	}
+}
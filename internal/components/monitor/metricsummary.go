@@ -0,0 +1,286 @@
+package monitor
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Metrics summarization defaults
+const (
+	DefaultMetricsWindow      = time.Hour
+	MaxMetricsWindow          = 93 * 24 * time.Hour // Azure Monitor platform metric retention
+	DefaultAnomalySigma       = 3.0
+	DefaultMetricsMaxPoints   = 60
+	MaxMetricsMaxPoints       = 500
+	MaxAnomaliesPerSeries     = 10
+	targetPointsPerSeries     = 300
+	flatTrendThresholdPercent = 10.0
+)
+
+// Trend directions
+const (
+	TrendRising  = "rising"
+	TrendFalling = "falling"
+	TrendFlat    = "flat"
+)
+
+// metricsGrains are the time grains supported by Azure Monitor platform metrics, smallest first
+var metricsGrains = []struct {
+	duration time.Duration
+	iso      string
+}{
+	{time.Minute, "PT1M"},
+	{5 * time.Minute, "PT5M"},
+	{15 * time.Minute, "PT15M"},
+	{30 * time.Minute, "PT30M"},
+	{time.Hour, "PT1H"},
+	{6 * time.Hour, "PT6H"},
+	{12 * time.Hour, "PT12H"},
+	{24 * time.Hour, "P1D"},
+}
+
+var relativeWindowPattern = regexp.MustCompile(`^(?i)(?:last\s+)?(\d+)\s*(m|mins?|minutes?|h|hrs?|hours?|d|days?)$`)
+
+// MetricPoint is a single value of a metric time series
+type MetricPoint struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// MetricTrend describes the direction of a series, from a least-squares fit over the window
+type MetricTrend struct {
+	Direction string `json:"direction"`
+	// ChangePercent is the change of the fitted line over the window, relative to the mean
+	ChangePercent float64 `json:"change_percent"`
+	SlopePerHour  float64 `json:"slope_per_hour"`
+}
+
+// MetricAnomaly is a point more than the configured number of standard deviations from the mean
+type MetricAnomaly struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+	Sigma float64   `json:"sigma"`
+}
+
+// SeriesSummary is the summarized view of one metric time series
+type SeriesSummary struct {
+	Metric      string            `json:"metric"`
+	Unit        string            `json:"unit,omitempty"`
+	Aggregation string            `json:"aggregation"`
+	Dimensions  map[string]string `json:"dimensions,omitempty"`
+	Points      int               `json:"points"`
+	// Missing counts intervals without a value for the aggregation
+	Missing   int             `json:"missing,omitempty"`
+	Min       float64         `json:"min"`
+	Max       float64         `json:"max"`
+	Avg       float64         `json:"avg"`
+	P95       float64         `json:"p95"`
+	Last      float64         `json:"last"`
+	Trend     MetricTrend     `json:"trend"`
+	Anomalies []MetricAnomaly `json:"anomalies"`
+	Samples   []MetricPoint   `json:"samples,omitempty"`
+}
+
+// ParseMetricsWindow parses a relative window such as "last 6h", "30m" or "last 7 days"
+func ParseMetricsWindow(window string) (time.Duration, error) {
+	match := relativeWindowPattern.FindStringSubmatch(strings.TrimSpace(window))
+	if match == nil {
+		return 0, fmt.Errorf("invalid window '%s': expected a relative window such as 'last 30m', 'last 6h' or 'last 7d'", window)
+	}
+	n, err := strconv.Atoi(match[1])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid window '%s': the length must be a positive number", window)
+	}
+
+	var unit time.Duration
+	switch strings.ToLower(match[2])[0] {
+	case 'm':
+		unit = time.Minute
+	case 'h':
+		unit = time.Hour
+	default:
+		unit = 24 * time.Hour
+	}
+	duration := time.Duration(n) * unit
+	if duration > MaxMetricsWindow {
+		return 0, fmt.Errorf("invalid window '%s': platform metrics are only retained for 93 days", window)
+	}
+	return duration, nil
+}
+
+// PickMetricsInterval returns the smallest supported time grain that keeps a window
+// under about 300 points per series
+func PickMetricsInterval(window time.Duration) string {
+	for _, grain := range metricsGrains {
+		if window/grain.duration <= targetPointsPerSeries {
+			return grain.iso
+		}
+	}
+	return metricsGrains[len(metricsGrains)-1].iso
+}
+
+// normalizeMetricsInterval accepts a supported ISO 8601 time grain or a Go duration such as "5m"
+func normalizeMetricsInterval(interval string) (string, error) {
+	for _, grain := range metricsGrains {
+		if strings.EqualFold(interval, grain.iso) {
+			return grain.iso, nil
+		}
+	}
+	if duration, err := time.ParseDuration(interval); err == nil {
+		for _, grain := range metricsGrains {
+			if duration == grain.duration {
+				return grain.iso, nil
+			}
+		}
+	}
+
+	supported := make([]string, 0, len(metricsGrains))
+	for _, grain := range metricsGrains {
+		supported = append(supported, grain.iso)
+	}
+	return "", fmt.Errorf("unsupported interval '%s': use one of %s", interval, strings.Join(supported, ", "))
+}
+
+// SummarizeSeries computes statistics, the trend and anomalies of a series ordered by time.
+// When maxPoints is positive, the series is also returned downsampled to at most maxPoints samples.
+func SummarizeSeries(summary *SeriesSummary, points []MetricPoint, sigma float64, maxPoints int) {
+	summary.Points = len(points)
+	summary.Anomalies = []MetricAnomaly{}
+	if len(points) == 0 {
+		summary.Trend = MetricTrend{Direction: TrendFlat}
+		return
+	}
+
+	values := make([]float64, len(points))
+	sum := 0.0
+	summary.Min, summary.Max = math.Inf(1), math.Inf(-1)
+	for i, point := range points {
+		values[i] = point.Value
+		sum += point.Value
+		summary.Min = math.Min(summary.Min, point.Value)
+		summary.Max = math.Max(summary.Max, point.Value)
+	}
+	mean := sum / float64(len(values))
+	summary.Avg = round(mean)
+	summary.Last = points[len(points)-1].Value
+	summary.P95 = percentile(values, 95)
+	summary.Trend = trendOf(points, mean)
+	summary.Anomalies = anomaliesOf(points, mean, sigma)
+	if maxPoints > 0 {
+		summary.Samples = Downsample(points, maxPoints)
+	}
+}
+
+// percentile returns the nearest-rank percentile of values
+func percentile(values []float64, p float64) float64 {
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// trendOf fits a line through the series and classifies its change over the window
+func trendOf(points []MetricPoint, mean float64) MetricTrend {
+	trend := MetricTrend{Direction: TrendFlat}
+	if len(points) < 2 {
+		return trend
+	}
+
+	start := points[0].Time
+	var sumX, sumY, sumXY, sumXX float64
+	for _, point := range points {
+		x := point.Time.Sub(start).Hours()
+		sumX += x
+		sumY += point.Value
+		sumXY += x * point.Value
+		sumXX += x * x
+	}
+	n := float64(len(points))
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return trend
+	}
+	slope := (n*sumXY - sumX*sumY) / denominator
+	trend.SlopePerHour = round(slope)
+
+	if mean == 0 {
+		return trend
+	}
+	span := points[len(points)-1].Time.Sub(start).Hours()
+	trend.ChangePercent = round(slope * span / math.Abs(mean) * 100)
+	switch {
+	case trend.ChangePercent >= flatTrendThresholdPercent:
+		trend.Direction = TrendRising
+	case trend.ChangePercent <= -flatTrendThresholdPercent:
+		trend.Direction = TrendFalling
+	}
+	return trend
+}
+
+// anomaliesOf returns the points beyond sigma standard deviations from the mean, keeping the
+// most extreme ones when there are more than MaxAnomaliesPerSeries, in time order
+func anomaliesOf(points []MetricPoint, mean, sigma float64) []MetricAnomaly {
+	anomalies := []MetricAnomaly{}
+	if len(points) < 3 || sigma <= 0 {
+		return anomalies
+	}
+
+	variance := 0.0
+	for _, point := range points {
+		variance += (point.Value - mean) * (point.Value - mean)
+	}
+	stddev := math.Sqrt(variance / float64(len(points)))
+	if stddev == 0 {
+		return anomalies
+	}
+
+	for _, point := range points {
+		z := (point.Value - mean) / stddev
+		if math.Abs(z) > sigma {
+			anomalies = append(anomalies, MetricAnomaly{Time: point.Time, Value: point.Value, Sigma: round(z)})
+		}
+	}
+	if len(anomalies) > MaxAnomaliesPerSeries {
+		sort.SliceStable(anomalies, func(i, j int) bool {
+			return math.Abs(anomalies[i].Sigma) > math.Abs(anomalies[j].Sigma)
+		})
+		anomalies = anomalies[:MaxAnomaliesPerSeries]
+		sort.SliceStable(anomalies, func(i, j int) bool {
+			return anomalies[i].Time.Before(anomalies[j].Time)
+		})
+	}
+	return anomalies
+}
+
+// Downsample reduces a series to at most maxPoints by averaging consecutive buckets.
+// Each sample is stamped with the time of the first point in its bucket.
+func Downsample(points []MetricPoint, maxPoints int) []MetricPoint {
+	if maxPoints <= 0 || len(points) <= maxPoints {
+		return append([]MetricPoint{}, points...)
+	}
+
+	bucketSize := int(math.Ceil(float64(len(points)) / float64(maxPoints)))
+	samples := make([]MetricPoint, 0, maxPoints)
+	for start := 0; start < len(points); start += bucketSize {
+		end := min(start+bucketSize, len(points))
+		sum := 0.0
+		for _, point := range points[start:end] {
+			sum += point.Value
+		}
+		samples = append(samples, MetricPoint{Time: points[start].Time, Value: round(sum / float64(end-start))})
+	}
+	return samples
+}
+
+// round keeps four decimal places so summaries stay readable
+func round(value float64) float64 {
+	return math.Round(value*1e4) / 1e4
+}
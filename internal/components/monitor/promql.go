@@ -0,0 +1,465 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+)
+
+// PromQL query modes
+const (
+	PromQLModeInstant = "instant"
+	PromQLModeRange   = "range"
+	PromQLModeLibrary = "library"
+)
+
+// MaxPromQLRangeWindow is the longest range an Azure Monitor workspace serves in one query
+const MaxPromQLRangeWindow = 32 * 24 * time.Hour
+
+// promqlSteps are the resolutions picked for range queries, smallest first
+var promqlSteps = []time.Duration{
+	15 * time.Second, 30 * time.Second, time.Minute, 5 * time.Minute, 15 * time.Minute,
+	30 * time.Minute, time.Hour, 3 * time.Hour, 6 * time.Hour, 12 * time.Hour, 24 * time.Hour,
+}
+
+// promqlParams are the parameters accepted by the promql operation
+var promqlParams = []string{
+	"subscription_id", "resource_group", "cluster_name", "mode", "query", "query_name",
+	"time", "window", "start_time", "end_time", "step", "sigma", "include_points", "max_points", "workspace",
+}
+
+// PromQLQueryDefinition is a query of the built-in AKS PromQL library
+type PromQLQueryDefinition struct {
+	Name        string `json:"name"`
+	Category    string `json:"category"`
+	Description string `json:"description"`
+	Query       string `json:"query"`
+}
+
+// promqlClusterPlaceholder is replaced with the cluster name in library queries. Managed Prometheus
+// labels every series with the cluster it came from, and a workspace can receive several clusters.
+const promqlClusterPlaceholder = "$cluster"
+
+// promqlLibrary holds PromQL queries for the metrics collected by the AKS managed Prometheus addon
+var promqlLibrary = []PromQLQueryDefinition{
+	{Name: "node_cpu_utilization", Category: MetricCategoryNode,
+		Description: "Fraction of CPU time each node spent busy",
+		Query:       `1 - avg by (instance) (rate(node_cpu_seconds_total{cluster="$cluster",mode="idle"}[5m]))`},
+	{Name: "node_memory_utilization", Category: MetricCategoryNode,
+		Description: "Fraction of memory in use on each node",
+		Query:       `1 - sum by (instance) (node_memory_MemAvailable_bytes{cluster="$cluster"}) / sum by (instance) (node_memory_MemTotal_bytes{cluster="$cluster"})`},
+	{Name: "node_disk_utilization", Category: MetricCategoryNode,
+		Description: "Fraction of filesystem space used on each node device",
+		Query:       `1 - sum by (instance, device) (node_filesystem_avail_bytes{cluster="$cluster",fstype!~"tmpfs|overlay"}) / sum by (instance, device) (node_filesystem_size_bytes{cluster="$cluster",fstype!~"tmpfs|overlay"})`},
+	{Name: "nodes_not_ready", Category: MetricCategoryNode,
+		Description: "Nodes whose Ready condition is not true",
+		Query:       `sum by (node) (kube_node_status_condition{cluster="$cluster",condition="Ready",status!="true"}) > 0`},
+	{Name: "pod_restarts", Category: MetricCategoryPod,
+		Description: "Container restarts per pod over the last hour",
+		Query:       `sum by (namespace, pod) (increase(kube_pod_container_status_restarts_total{cluster="$cluster"}[1h])) > 0`},
+	{Name: "pods_not_ready", Category: MetricCategoryPod,
+		Description: "Pods that are not Ready, per namespace",
+		Query:       `sum by (namespace) (kube_pod_status_ready{cluster="$cluster",condition="false"}) > 0`},
+	{Name: "pods_pending", Category: MetricCategoryPod,
+		Description: "Pods stuck in the Pending phase, per namespace",
+		Query:       `sum by (namespace) (kube_pod_status_phase{cluster="$cluster",phase="Pending"}) > 0`},
+	{Name: "container_oom_killed", Category: MetricCategoryPod,
+		Description: "Containers whose last termination was an OOM kill",
+		Query:       `sum by (namespace, pod, container) (kube_pod_container_status_last_terminated_reason{cluster="$cluster",reason="OOMKilled"}) > 0`},
+	{Name: "container_cpu_throttling", Category: MetricCategoryPod,
+		Description: "Fraction of CFS periods in which each pod was throttled by its CPU limit",
+		Query:       `sum by (namespace, pod) (rate(container_cpu_cfs_throttled_periods_total{cluster="$cluster"}[5m])) / sum by (namespace, pod) (rate(container_cpu_cfs_periods_total{cluster="$cluster"}[5m])) > 0.1`},
+	{Name: "container_memory_working_set", Category: MetricCategoryPod,
+		Description: "Working set memory per pod in bytes, the value limits and evictions are compared against",
+		Query:       `sum by (namespace, pod) (container_memory_working_set_bytes{cluster="$cluster",container!=""})`},
+	{Name: "apiserver_request_latency_p99", Category: MetricCategoryAPIServer,
+		Description: "99th percentile API server request latency by verb; requires control plane metrics",
+		Query:       `histogram_quantile(0.99, sum by (le, verb) (rate(apiserver_request_duration_seconds_bucket{cluster="$cluster",verb!~"WATCH|CONNECT"}[5m])))`},
+	{Name: "apiserver_error_rate", Category: MetricCategoryAPIServer,
+		Description: "Fraction of API server requests answered with a 5xx code; requires control plane metrics",
+		Query:       `sum(rate(apiserver_request_total{cluster="$cluster",code=~"5.."}[5m])) / sum(rate(apiserver_request_total{cluster="$cluster"}[5m]))`},
+	{Name: "coredns_request_latency_p99", Category: "dns",
+		Description: "99th percentile latency of DNS requests served by CoreDNS",
+		Query:       `histogram_quantile(0.99, sum by (le) (rate(coredns_dns_request_duration_seconds_bucket{cluster="$cluster"}[5m])))`},
+	{Name: "coredns_servfail_rate", Category: "dns",
+		Description: "SERVFAIL responses per second returned by CoreDNS",
+		Query:       `sum(rate(coredns_dns_responses_total{cluster="$cluster",rcode="SERVFAIL"}[5m]))`},
+}
+
+// LookupPromQLQuery returns the library query with the given name
+func LookupPromQLQuery(name string) (PromQLQueryDefinition, bool) {
+	for _, definition := range promqlLibrary {
+		if definition.Name == name {
+			return definition, true
+		}
+	}
+	return PromQLQueryDefinition{}, false
+}
+
+// PromQLQuery is a validated promql request
+type PromQLQuery struct {
+	Mode      string
+	Query     string
+	QueryName string
+	Time      time.Time
+	Start     time.Time
+	End       time.Time
+	Step      time.Duration
+	// StepAutoSelected is true when the step was picked from the window length
+	StepAutoSelected bool
+	Sigma            float64
+	// MaxPoints is the number of downsampled points returned per series, 0 for none
+	MaxPoints int
+	// Workspace is the resource ID or name of the Azure Monitor workspace to query
+	Workspace string
+}
+
+// PromQLSample is a series value of an instant query
+type PromQLSample struct {
+	Labels map[string]string `json:"labels"`
+	Value  float64           `json:"value"`
+}
+
+// PromQLResult is the summarized result of a promql query
+type PromQLResult struct {
+	Workspace  string `json:"workspace,omitempty"`
+	Query      string `json:"query"`
+	QueryName  string `json:"query_name,omitempty"`
+	Mode       string `json:"mode"`
+	ResultType string `json:"result_type"`
+	Time       string `json:"time,omitempty"`
+	Start      string `json:"start,omitempty"`
+	End        string `json:"end,omitempty"`
+	Step       string `json:"step,omitempty"`
+	// Samples are the values of an instant query, highest first
+	Samples []PromQLSample `json:"samples,omitempty"`
+	// Series are the summarized series of a range query
+	Series   []SeriesSummary `json:"series,omitempty"`
+	Warnings []string        `json:"warnings,omitempty"`
+	Notes    []string        `json:"notes,omitempty"`
+}
+
+// ParsePromQLQuery validates the parameters of a promql query. Either query or query_name
+// is required. Range queries take a relative window ("last 6h") or start_time/end_time and
+// default to the last hour.
+func ParsePromQLQuery(params map[string]interface{}, now time.Time) (*PromQLQuery, error) {
+	for key := range params {
+		if !slices.Contains(promqlParams, key) {
+			return nil, fmt.Errorf("unsupported promql parameter '%s'. Supported parameters: %s", key, strings.Join(promqlParams, ", "))
+		}
+	}
+
+	query := &PromQLQuery{Mode: PromQLModeInstant, Sigma: DefaultAnomalySigma}
+	if mode := stringValue(params, "mode"); mode != "" {
+		query.Mode = strings.ToLower(mode)
+	}
+	switch query.Mode {
+	case PromQLModeLibrary:
+		return query, nil
+	case PromQLModeInstant, PromQLModeRange:
+	default:
+		return nil, fmt.Errorf("unsupported mode '%s': use one of %s, %s, %s", query.Mode, PromQLModeInstant, PromQLModeRange, PromQLModeLibrary)
+	}
+
+	query.Query = stringValue(params, "query")
+	query.Workspace = stringValue(params, "workspace")
+	query.QueryName = stringValue(params, "query_name")
+	switch {
+	case query.Query != "" && query.QueryName != "":
+		return nil, fmt.Errorf("use either 'query' or 'query_name', not both")
+	case query.QueryName != "":
+		definition, ok := LookupPromQLQuery(query.QueryName)
+		if !ok {
+			return nil, fmt.Errorf("unknown query_name '%s': use mode 'library' to list the built-in queries", query.QueryName)
+		}
+		query.Query = definition.Query
+	case query.Query == "":
+		return nil, fmt.Errorf("missing 'query' or 'query_name' parameter for promql")
+	}
+
+	if query.Mode == PromQLModeInstant {
+		for _, key := range []string{"window", "start_time", "end_time", "step"} {
+			if stringValue(params, key) != "" {
+				return nil, fmt.Errorf("'%s' only applies to range queries; set mode to 'range'", key)
+			}
+		}
+		query.Time = now
+		if value := stringValue(params, "time"); value != "" {
+			at, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid time format, expected RFC3339: %w", err)
+			}
+			query.Time = at
+		}
+		return query, nil
+	}
+
+	if stringValue(params, "time") != "" {
+		return nil, fmt.Errorf("'time' only applies to instant queries; use 'window' or 'start_time'/'end_time' for range queries")
+	}
+	window := stringValue(params, "window")
+	startTime, endTime := stringValue(params, "start_time"), stringValue(params, "end_time")
+	switch {
+	case window != "" && (startTime != "" || endTime != ""):
+		return nil, fmt.Errorf("use either 'window' or 'start_time'/'end_time', not both")
+	case startTime != "":
+		start, err := time.Parse(time.RFC3339, startTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start_time format, expected RFC3339: %w", err)
+		}
+		end := now
+		if endTime != "" {
+			if end, err = time.Parse(time.RFC3339, endTime); err != nil {
+				return nil, fmt.Errorf("invalid end_time format, expected RFC3339: %w", err)
+			}
+		}
+		if !end.After(start) {
+			return nil, fmt.Errorf("end_time must be after start_time")
+		}
+		query.Start, query.End = start, end
+	case endTime != "":
+		return nil, fmt.Errorf("end_time requires start_time")
+	default:
+		duration := DefaultMetricsWindow
+		if window != "" {
+			var err error
+			if duration, err = ParseMetricsWindow(window); err != nil {
+				return nil, err
+			}
+		}
+		query.Start, query.End = now.Add(-duration), now
+	}
+	if query.End.Sub(query.Start) > MaxPromQLRangeWindow {
+		return nil, fmt.Errorf("the range is longer than the 32 days an Azure Monitor workspace serves in one query")
+	}
+
+	if step := stringValue(params, "step"); step != "" {
+		duration, err := time.ParseDuration(step)
+		if err != nil || duration < time.Second {
+			return nil, fmt.Errorf("invalid step '%s': expected a duration of at least 1s such as '30s' or '5m'", step)
+		}
+		if query.End.Sub(query.Start)/duration > maxPromQLPointsPerSeries {
+			return nil, fmt.Errorf("step '%s' is too small for the range: at most %d points per series are returned", step, maxPromQLPointsPerSeries)
+		}
+		query.Step = duration
+	} else {
+		query.Step = PickPromQLStep(query.End.Sub(query.Start))
+		query.StepAutoSelected = true
+	}
+
+	if sigma, ok := params["sigma"].(float64); ok {
+		if sigma <= 0 {
+			return nil, fmt.Errorf("sigma must be positive")
+		}
+		query.Sigma = sigma
+	}
+	if includePoints, _ := params["include_points"].(bool); includePoints {
+		query.MaxPoints = DefaultMetricsMaxPoints
+		if maxPoints, ok := params["max_points"].(float64); ok && maxPoints > 0 {
+			query.MaxPoints = min(int(maxPoints), MaxMetricsMaxPoints)
+		}
+	}
+	return query, nil
+}
+
+// maxPromQLPointsPerSeries is the resolution limit of the Prometheus query API
+const maxPromQLPointsPerSeries = 11000
+
+// PickPromQLStep returns the smallest step that keeps a range under about 300 points per series
+func PickPromQLStep(window time.Duration) time.Duration {
+	for _, step := range promqlSteps {
+		if window/step <= targetPointsPerSeries {
+			return step
+		}
+	}
+	return promqlSteps[len(promqlSteps)-1]
+}
+
+// SummarizePrometheusResult converts a PromQL result into samples for instant queries and
+// series summaries for range queries
+func SummarizePrometheusResult(raw *azureclient.PrometheusResult, query *PromQLQuery) *PromQLResult {
+	result := &PromQLResult{
+		Query:      query.Query,
+		QueryName:  query.QueryName,
+		Mode:       query.Mode,
+		ResultType: raw.ResultType,
+		Warnings:   raw.Warnings,
+	}
+
+	if query.Mode == PromQLModeInstant {
+		result.Time = query.Time.UTC().Format(time.RFC3339)
+		result.Samples = []PromQLSample{}
+		for _, series := range raw.Series {
+			if len(series.Points) == 0 {
+				continue
+			}
+			result.Samples = append(result.Samples, PromQLSample{Labels: series.Labels, Value: series.Points[len(series.Points)-1].Value})
+		}
+		sort.SliceStable(result.Samples, func(i, j int) bool {
+			return sampleRank(result.Samples[i].Value) > sampleRank(result.Samples[j].Value)
+		})
+		if len(result.Samples) > MaxMetricSeries {
+			result.Notes = append(result.Notes, fmt.Sprintf("Only the %d highest of %d samples are returned; aggregate or filter the query to see the rest", MaxMetricSeries, len(result.Samples)))
+			result.Samples = result.Samples[:MaxMetricSeries]
+		}
+		return result
+	}
+
+	result.Start = query.Start.UTC().Format(time.RFC3339)
+	result.End = query.End.UTC().Format(time.RFC3339)
+	result.Step = query.Step.String()
+	if query.StepAutoSelected {
+		result.Notes = append(result.Notes, fmt.Sprintf("Step %s was selected for the range; pass 'step' to override it", query.Step))
+	}
+	result.Series = []SeriesSummary{}
+	for _, series := range raw.Series {
+		if len(result.Series) == MaxMetricSeries {
+			result.Notes = append(result.Notes, fmt.Sprintf("Only the first %d of %d series are summarized; aggregate or filter the query to see the rest", MaxMetricSeries, len(raw.Series)))
+			break
+		}
+		labels := make(map[string]string, len(series.Labels))
+		for name, value := range series.Labels {
+			if name != "__name__" {
+				labels[name] = value
+			}
+		}
+		summary := SeriesSummary{Metric: series.Labels["__name__"], Dimensions: labels}
+		if len(labels) == 0 {
+			summary.Dimensions = nil
+		}
+
+		// NaN and infinite values, e.g. from a division by zero, cannot be summarized
+		points := make([]MetricPoint, 0, len(series.Points))
+		for _, point := range series.Points {
+			if math.IsNaN(point.Value) || math.IsInf(point.Value, 0) {
+				summary.Missing++
+				continue
+			}
+			points = append(points, MetricPoint{Time: point.Time, Value: point.Value})
+		}
+		SummarizeSeries(&summary, points, query.Sigma, query.MaxPoints)
+		result.Series = append(result.Series, summary)
+	}
+	return result
+}
+
+// sampleRank orders NaN samples last when sorting by value
+func sampleRank(value float64) float64 {
+	if math.IsNaN(value) {
+		return math.Inf(-1)
+	}
+	return value
+}
+
+// QueryPromQL finds the Azure Monitor workspace of a cluster and runs the query against it.
+// Library queries are restricted to the cluster's series.
+func QueryPromQL(ctx context.Context, azClient *azureclient.AzureClient, subscriptionID, resourceGroup, clusterName string, query *PromQLQuery) (*PromQLResult, error) {
+	cluster, err := azClient.GetAKSCluster(ctx, subscriptionID, resourceGroup, clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get AKS cluster: %w", err)
+	}
+	if cluster.Properties == nil || cluster.Properties.AzureMonitorProfile == nil || cluster.Properties.AzureMonitorProfile.Metrics == nil ||
+		cluster.Properties.AzureMonitorProfile.Metrics.Enabled == nil || !*cluster.Properties.AzureMonitorProfile.Metrics.Enabled {
+		return nil, fmt.Errorf("managed Prometheus (Azure Monitor metrics) is not enabled on cluster %s; enable it with 'az aks update --enable-azure-monitor-metrics'", clusterName)
+	}
+
+	clusterResourceID := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ContainerService/managedClusters/%s",
+		subscriptionID, resourceGroup, clusterName)
+	workspaces, err := azClient.FindMonitorWorkspaces(ctx, clusterResourceID)
+	if err != nil {
+		return nil, err
+	}
+	workspace, workspaceNote, err := selectMonitorWorkspace(workspaces, query.Workspace, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := azClient.GetPrometheusClient(workspace.QueryEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	scoped := *query
+	var note string
+	scoped.Query, note = scopePromQLQuery(query, clusterName)
+	result, err := runPromQL(ctx, client, &scoped)
+	if err != nil {
+		return nil, err
+	}
+	result.Workspace = workspace.ResourceID
+	for _, n := range []string{workspaceNote, note} {
+		if n != "" {
+			result.Notes = append(result.Notes, n)
+		}
+	}
+	return result, nil
+}
+
+// scopePromQLQuery fills the cluster into a library query. User queries are run as written, with a
+// note when they do not filter on the cluster label.
+func scopePromQLQuery(query *PromQLQuery, clusterName string) (string, string) {
+	if query.QueryName != "" {
+		return strings.ReplaceAll(query.Query, promqlClusterPlaceholder, clusterName), ""
+	}
+	if strings.Contains(strings.ReplaceAll(query.Query, " ", ""), "cluster=") {
+		return query.Query, ""
+	}
+	return query.Query, fmt.Sprintf("The query does not filter on the cluster label; if the workspace receives metrics from other clusters, add cluster=%q to each selector", clusterName)
+}
+
+// selectMonitorWorkspace picks the workspace to query: the requested one, or the only workspace
+// with a query endpoint. When several qualify, the first by resource ID is queried and the note
+// lists the others.
+func selectMonitorWorkspace(workspaces []azureclient.MonitorWorkspace, requested, clusterName string) (*azureclient.MonitorWorkspace, string, error) {
+	var candidates []azureclient.MonitorWorkspace
+	for _, workspace := range workspaces {
+		if workspace.QueryEndpoint != "" {
+			candidates = append(candidates, workspace)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, "", fmt.Errorf("no Azure Monitor workspace with a query endpoint is linked to cluster %s through a data collection rule", clusterName)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return strings.ToLower(candidates[i].ResourceID) < strings.ToLower(candidates[j].ResourceID)
+	})
+	ids := make([]string, 0, len(candidates))
+	for _, workspace := range candidates {
+		ids = append(ids, workspace.ResourceID)
+	}
+
+	if requested != "" {
+		for i, workspace := range candidates {
+			name := workspace.ResourceID[strings.LastIndex(workspace.ResourceID, "/")+1:]
+			if strings.EqualFold(workspace.ResourceID, requested) || strings.EqualFold(name, requested) {
+				return &candidates[i], "", nil
+			}
+		}
+		return nil, "", fmt.Errorf("workspace '%s' does not receive metrics from cluster %s. Workspaces: %s", requested, clusterName, strings.Join(ids, ", "))
+	}
+	if len(candidates) == 1 {
+		return &candidates[0], "", nil
+	}
+	return &candidates[0], fmt.Sprintf("The cluster sends metrics to %d Azure Monitor workspaces (%s); %s was queried. Pass 'workspace' to query another one",
+		len(candidates), strings.Join(ids, ", "), candidates[0].ResourceID), nil
+}
+
+// runPromQL runs an instant or range query and summarizes the result
+func runPromQL(ctx context.Context, client *azureclient.PrometheusClient, query *PromQLQuery) (*PromQLResult, error) {
+	var raw *azureclient.PrometheusResult
+	var err error
+	if query.Mode == PromQLModeRange {
+		raw, err = client.QueryRange(ctx, query.Query, query.Start, query.End, query.Step)
+	} else {
+		raw, err = client.Query(ctx, query.Query, query.Time)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return SummarizePrometheusResult(raw, query), nil
+}
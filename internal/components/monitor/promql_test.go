@@ -0,0 +1,176 @@
+package monitor
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+func TestParsePromQLQuery(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	query, err := ParsePromQLQuery(map[string]interface{}{"query_name": "pod_restarts"}, now)
+	if err != nil {
+		t.Fatalf("ParsePromQLQuery returned error: %v", err)
+	}
+	if query.Mode != PromQLModeInstant || !query.Time.Equal(now) || !strings.Contains(query.Query, "kube_pod_container_status_restarts_total") {
+		t.Errorf("Unexpected instant query: %+v", query)
+	}
+
+	query, err = ParsePromQLQuery(map[string]interface{}{"mode": "range", "query": "up", "window": "last 6h"}, now)
+	if err != nil {
+		t.Fatalf("ParsePromQLQuery returned error: %v", err)
+	}
+	if !query.Start.Equal(now.Add(-6*time.Hour)) || query.Step != 5*time.Minute || !query.StepAutoSelected {
+		t.Errorf("Unexpected range query: %+v", query)
+	}
+
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+	}{
+		{"unknown parameter", map[string]interface{}{"query": "up", "timeout": "1m"}},
+		{"unknown mode", map[string]interface{}{"mode": "stream", "query": "up"}},
+		{"missing query", map[string]interface{}{}},
+		{"query and query_name", map[string]interface{}{"query": "up", "query_name": "pod_restarts"}},
+		{"unknown query_name", map[string]interface{}{"query_name": "not_a_query"}},
+		{"window on instant", map[string]interface{}{"query": "up", "window": "last 1h"}},
+		{"time on range", map[string]interface{}{"mode": "range", "query": "up", "time": "2025-03-01T00:00:00Z"}},
+		{"range too long", map[string]interface{}{"mode": "range", "query": "up", "window": "last 40d"}},
+		{"step too small", map[string]interface{}{"mode": "range", "query": "up", "window": "last 7d", "step": "15s"}},
+		{"invalid step", map[string]interface{}{"mode": "range", "query": "up", "step": "soon"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParsePromQLQuery(tt.params, now); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+func TestScopePromQLQuery(t *testing.T) {
+	metricNamePattern := regexp.MustCompile(`\b(node|kube|container|apiserver|coredns)_[a-zA-Z_]+`)
+	for _, definition := range promqlLibrary {
+		query, note := scopePromQLQuery(&PromQLQuery{Query: definition.Query, QueryName: definition.Name}, "aks-1")
+		if strings.Count(query, `cluster="aks-1"`) != len(metricNamePattern.FindAllString(query, -1)) ||
+			strings.Contains(query, promqlClusterPlaceholder) || note != "" {
+			t.Errorf("Expected every selector of %s to match the cluster, got %s", definition.Name, query)
+		}
+	}
+
+	query, note := scopePromQLQuery(&PromQLQuery{Query: "up"}, "aks-1")
+	if query != "up" || !strings.Contains(note, `cluster="aks-1"`) {
+		t.Errorf("Expected a note for an unscoped user query, got %q", note)
+	}
+	if _, note := scopePromQLQuery(&PromQLQuery{Query: `up{cluster = "aks-1"}`}, "aks-1"); note != "" {
+		t.Errorf("Expected no note for a scoped user query, got %q", note)
+	}
+}
+
+func TestSelectMonitorWorkspace(t *testing.T) {
+	const prefix = "/subscriptions/sub-1/resourceGroups/rg-1/providers/Microsoft.Monitor/accounts/"
+	workspaces := []azureclient.MonitorWorkspace{
+		{ResourceID: prefix + "amw-weu", QueryEndpoint: "https://amw-weu.prometheus.monitor.azure.com"},
+		{ResourceID: prefix + "amw-central", QueryEndpoint: "https://amw-central.prometheus.monitor.azure.com"},
+		{ResourceID: prefix + "amw-no-endpoint"},
+	}
+
+	workspace, note, err := selectMonitorWorkspace(workspaces, "", "aks-1")
+	if err != nil || workspace.ResourceID != prefix+"amw-central" || !strings.Contains(note, "2 Azure Monitor workspaces") {
+		t.Errorf("Expected the first workspace by ID with a note, got %+v, %q, %v", workspace, note, err)
+	}
+	if workspace, note, err := selectMonitorWorkspace(workspaces, "AMW-WEU", "aks-1"); err != nil || workspace.ResourceID != prefix+"amw-weu" || note != "" {
+		t.Errorf("Expected the requested workspace, got %+v, %q, %v", workspace, note, err)
+	}
+	if _, _, err := selectMonitorWorkspace(workspaces, "amw-no-endpoint", "aks-1"); err == nil {
+		t.Error("Expected an error for a workspace without a query endpoint")
+	}
+	if _, note, err := selectMonitorWorkspace(workspaces[:1], "", "aks-1"); err != nil || note != "" {
+		t.Errorf("Expected no note for a single workspace, got %q, %v", note, err)
+	}
+}
+
+func TestPickPromQLStep(t *testing.T) {
+	tests := []struct {
+		window time.Duration
+		want   time.Duration
+	}{
+		{time.Hour, 15 * time.Second},
+		{6 * time.Hour, 5 * time.Minute},
+		{7 * 24 * time.Hour, time.Hour},
+		{365 * 24 * time.Hour, 24 * time.Hour},
+	}
+	for _, tt := range tests {
+		if got := PickPromQLStep(tt.window); got != tt.want {
+			t.Errorf("PickPromQLStep(%s) = %s, want %s", tt.window, got, tt.want)
+		}
+	}
+}
+
+func TestSummarizePrometheusResultInstant(t *testing.T) {
+	at := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	raw := &azureclient.PrometheusResult{ResultType: azureclient.PrometheusResultVector}
+	for i, value := range []float64{2, math.NaN(), 7} {
+		raw.Series = append(raw.Series, azureclient.PrometheusSeries{
+			Labels: map[string]string{"pod": string(rune('a' + i))},
+			Points: []azureclient.PrometheusPoint{{Time: at, Value: value}},
+		})
+	}
+
+	result := SummarizePrometheusResult(raw, &PromQLQuery{Mode: PromQLModeInstant, Query: "x", Time: at})
+	if len(result.Samples) != 3 || result.Samples[0].Value != 7 || !math.IsNaN(result.Samples[2].Value) {
+		t.Errorf("Expected samples ordered by value with NaN last, got %+v", result.Samples)
+	}
+	if result.Series != nil {
+		t.Errorf("Instant results should not have series, got %+v", result.Series)
+	}
+}
+
+func TestRunPromQLRangeAgainstPrometheus(t *testing.T) {
+	start := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.URL.Path != "/api/v1/query_range" || r.PostForm.Get("step") != "15" {
+			t.Errorf("Unexpected request %s %v", r.URL.Path, r.PostForm)
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[
+			{"metric":{"__name__":"node_load1","instance":"node-1"},"values":[[1740823200,"1"],[1740823215,"2"],[1740823230,"NaN"],[1740823245,"3"]]}]}}`))
+	}))
+	defer server.Close()
+
+	client, err := azureclient.NewPrometheusClient(server.URL, nil, &policy.ClientOptions{Transport: server.Client()})
+	if err != nil {
+		t.Fatalf("NewPrometheusClient returned error: %v", err)
+	}
+	query, err := ParsePromQLQuery(map[string]interface{}{
+		"mode": "range", "query": "node_load1", "start_time": start.Format(time.RFC3339), "end_time": start.Add(time.Hour).Format(time.RFC3339),
+		"include_points": true,
+	}, start.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("ParsePromQLQuery returned error: %v", err)
+	}
+
+	result, err := runPromQL(context.Background(), client, query)
+	if err != nil {
+		t.Fatalf("runPromQL returned error: %v", err)
+	}
+	if result.Step != "15s" || len(result.Notes) != 1 || len(result.Series) != 1 {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+	series := result.Series[0]
+	if series.Metric != "node_load1" || series.Dimensions["instance"] != "node-1" || series.Dimensions["__name__"] != "" {
+		t.Errorf("Unexpected series labels: %+v", series)
+	}
+	if series.Points != 3 || series.Missing != 1 || series.Max != 3 || series.Last != 3 || series.Trend.Direction != TrendRising || len(series.Samples) != 3 {
+		t.Errorf("Unexpected series summary: %+v", series)
+	}
+}
@@ -1,6 +1,7 @@
 package monitor
 
 import (
+	"github.com/Azure/aks-mcp/internal/dryrun"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
@@ -13,6 +14,28 @@ const (
 	OpAppInsights      MonitoringOperationType = "app_insights"
 	OpDiagnostics      MonitoringOperationType = "diagnostics"
 	OpControlPlaneLogs MonitoringOperationType = "control_plane_logs"
+
+	OpClusterHealthDashboard MonitoringOperationType = "cluster_health_dashboard"
+
+	// Container Insights operations
+	OpContainerLogs   MonitoringOperationType = "container_logs"
+	OpPodInventory    MonitoringOperationType = "pod_inventory"
+	OpKubeEvents      MonitoringOperationType = "kube_events"
+	OpNodeInventory   MonitoringOperationType = "node_inventory"
+	OpInsightsMetrics MonitoringOperationType = "insights_metrics"
+
+	OpAuditInvestigation MonitoringOperationType = "audit_investigation"
+	OpLogAnalyticsQuery  MonitoringOperationType = "log_analytics_query"
+	OpActivityLog        MonitoringOperationType = "activity_log"
+	OpPromQL             MonitoringOperationType = "promql"
+
+	// Alerting operations
+	OpAlertsRules          MonitoringOperationType = "alerts_rules"
+	OpAlertsFired          MonitoringOperationType = "alerts_fired"
+	OpAlertsCreateBaseline MonitoringOperationType = "alerts_create_baseline"
+
+	// Remediation operations that change Azure resources
+	OpDiagnosticsEnable MonitoringOperationType = "diagnostics_enable"
 )
 
 // RegisterAzMonitoring registers the monitoring tool
@@ -25,21 +48,41 @@ Supported Operations:
    - list: Get metric values for specific metrics
    - list-definitions: Get available metrics for a resource
    - list-namespaces: Get metric namespaces for a resource
+   - catalog: List the built-in catalog of AKS metrics with descriptions and default aggregations (optional category: node, pod, apiserver, etcd, autoscaler)
    
    Use for: CPU usage, memory consumption, network traffic, pod counts, node health
    Required parameters: resource (Azure resource ID)
-   Additional for 'list': metrics (metric names)
-   Optional: aggregation, start-time, end-time, interval, filter
+   Additional for 'list': metrics (comma separated metric names)
+   Optional for 'list':
+   - window: relative window such as "last 6h" or "last 7d" (default last 1h), or start-time/end-time (RFC3339)
+   - interval: time grain such as PT5M (picked from the window when omitted)
+   - aggregation: Average (default), Minimum, Maximum, Total or Count
+   - filter: dimension filter, e.g. "node eq '*'" to split by node
+   - namespace: metric namespace
+   - sigma: standard deviations from the mean that count as an anomaly (default 3)
+   - include_points: also return the series downsampled to max_points samples (default 60)
+   'list' returns a summary per time series (min/max/avg/p95/last, trend and anomalies) instead of raw values.
 
-2. Resource Health - Get Azure Resource Health events for AKS clusters
+2. Resource Health - Availability timeline of an AKS cluster from the Resource Health API
+   Returns state transitions with durations and root-cause text for the cluster and its dependent resources
+   (node VMSS and Kubernetes load balancers), and the active service incidents in the cluster's region.
    Use for: Cluster availability issues, platform problems, service health events
    Required parameters: subscription_id, resource_group, cluster_name, start_time
-   Optional: end_time, status (Available, Unavailable, Degraded, Unknown)
+   Optional: end_time, status (Available, Unavailable, Degraded, Unknown) to only return transitions into that state
 
-3. Application Insights - Execute KQL queries against Application Insights telemetry
+3. Application Insights - Execute KQL queries or built-in analyses against Application Insights telemetry
+   Analyses (set analysis instead of query):
+   - failed_requests: failing requests by operation name with result codes and sample operation IDs
+   - dependency_failures: failing dependency calls by type, target and result code
+   - top_exceptions: the most frequent exceptions by type and problem ID
+   - trace: the end-to-end trace of one operation_id, with requests, dependencies, exceptions and
+     traces stitched into one ordered timeline
+   Role instances in the results are correlated to AKS pods (namespace, node, workload) when
+   cluster_name is given, falling back to controller pod-name patterns.
    Use for: Application performance monitoring, custom telemetry analysis, trace correlation
-   Required parameters: subscription_id, resource_group, app_insights_name, query
-   Optional: start_time + end_time OR timespan (not both)
+   Required parameters: subscription_id, resource_group, app_insights_name, query OR analysis
+   Optional: start_time + end_time OR timespan (not both), operation_id (required for trace), role_name,
+   operation_name (failed_requests), top (default 10, max 50), cluster_name, cluster_resource_group
 
 4. Diagnostics - Check AKS cluster diagnostic settings configuration
    Use for: Verify logging is enabled, check log retention, validate diagnostic configuration
@@ -63,6 +106,94 @@ Supported Operations:
    - fleet-mcs-controller-manager
    PLEASE NOTE: you need to check if the category is enabled in your cluster's diagnostic settings by using the diagnostics tool.
 
+6. Cluster Health Dashboard - Golden-signals summary of an AKS cluster
+   Fetches a curated set of platform metrics (node CPU/memory/disk, etcd, API server, pod phases, autoscaler) in parallel
+   and reports each with a status against built-in thresholds.
+   Required parameters: subscription_id, resource_group, cluster_name
+   Optional: window (default "last 1h")
+
+7. Container Insights - Query workload data collected by the monitoring addon
+   - container_logs: container stdout/stderr from ContainerLogV2
+   - pod_inventory: latest pod and container state from KubePodInventory
+   - kube_events: Kubernetes events from KubeEvents
+   - node_inventory: latest node state from KubeNodeInventory
+   - insights_metrics: metrics from InsightsMetrics (not available when the server restricts namespaces)
+   Required parameters: subscription_id, resource_group, cluster_name
+   Optional: start_time/end_time (RFC3339, default last 1h, at most 24h), max_records (default 100, at most 1000) and filters:
+   - namespace (container_logs, pod_inventory, kube_events); restricted to the server's allowed namespaces
+   - pod (container_logs, pod_inventory, kube_events), container (container_logs, pod_inventory), node (all)
+   - severity: log level for container_logs (critical, error, warning, info, debug, trace, unknown), Normal or Warning for kube_events
+   - status: pod phase for pod_inventory, Ready/NotReady for node_inventory
+   - search: text contained in the message (container_logs, kube_events)
+   - metric: metric name (insights_metrics)
+   The workspace is read from the monitoring addon, or from the diagnostic settings when the addon is disabled.
+
+8. Audit Investigation - Answer who-did-what questions from the kube-audit logs
+   Returns a timeline of distinct actions (actor, verb, resource, namespace, name, response codes, source IPs)
+   per time bucket instead of raw audit events.
+   Required parameters: subscription_id, resource_group, cluster_name, question
+   Questions:
+   - actor: all actions by a user or service account (requires user)
+   - resource: all changes to a resource type (requires resource, optional namespace and name)
+   - denied: requests rejected with 403 Forbidden
+   - exec: exec, attach and port-forward into pods
+   - secret_reads: get, list and watch on secrets (requires the kube-audit category)
+   Optional: user, resource, namespace, name, start_time/end_time (default last 24h), max_records (timeline rows, default 100)
+   Falls back to kube-audit-admin when kube-audit is not enabled; read requests are then missing.
+
+9. Log Analytics Query - Run your own KQL against the cluster's Log Analytics workspace
+   The query is parsed and checked before it runs:
+   - only read-only operators and let statements; no control commands, set statements or render
+   - only Container Insights, AKS diagnostics and AzureMetrics tables; every table reference is scoped to the cluster's _ResourceId
+   - no workspace(), app(), cluster(), database(), table() or externaldata
+   - with allowed namespaces configured, namespaced tables are filtered to them and tables without a namespace column are rejected
+   - a take of max_records (default 100, at most 1000) is appended
+   - the data read is limited to start_time/end_time (default last 1h, at most 24h)
+   Required parameters: subscription_id, resource_group, cluster_name, query
+
+10. Activity Log - Change history of an AKS cluster from the Azure activity log
+   Covers the managed cluster, its agent pools and every resource in the node resource group.
+   Merges the Started/Succeeded/Failed events of each write, delete and action into one operation, groups operations
+   by caller and operation name, and lists the provisioning state transitions of the cluster and agent pools.
+   Node resource group changes are correlated with the cluster operation running at the time; changes outside any
+   cluster operation are counted separately.
+   Required parameters: subscription_id, resource_group, cluster_name
+   Optional: start_time/end_time (RFC3339, default last 7 days, at most 90 days back)
+
+11. PromQL - Query Azure Monitor managed service for Prometheus
+   The Azure Monitor workspace is found from the cluster's data collection rule associations (managed Prometheus must be enabled
+   in the cluster's azureMonitorProfile). Instant queries return the sample of each series, highest first; range queries return
+   a summary per series (min/max/avg/p95/last, trend and anomalies) like metrics 'list'.
+   Required parameters: subscription_id, resource_group, cluster_name, and query (PromQL) or query_name (built-in AKS query)
+   Optional:
+   - mode: instant (default), range, or library to list the built-in queries
+   - time: evaluation time of an instant query (RFC3339, default now)
+   - window ("last 6h", default last 1h) or start_time/end_time for range queries, at most 32 days
+   - step: resolution of a range query such as 30s or 5m (picked from the range when omitted)
+   - sigma, include_points, max_points: as for metrics 'list'
+
+12. Diagnostics Enable - Send missing control plane log categories to a Log Analytics workspace (requires readwrite access)
+   Creates or updates a diagnostic setting on the cluster in resource-specific mode (AKSAudit, AKSAuditAdmin, AKSControlPlane tables).
+   The setting that already sends to the workspace is updated; otherwise setting_name (default "aks-mcp-control-plane-logs") is updated or created.
+   Updating a setting that sends to the AzureDiagnostics table or to another workspace converts it, which breaks queries reading the logs there;
+   this is refused unless convert_existing=true. The dry_run preview marks these changes as breaking.
+   Reports the estimated ingestion per newly enabled category. Use dry_run=true to preview the change, also with readonly access.
+   Required parameters: subscription_id, resource_group, cluster_name, categories (comma separated), workspace_resource_id
+   Optional: setting_name, convert_existing (true to convert an existing setting)
+
+13. Alerts - Alert rules, fired alerts and baseline alerts of an AKS cluster
+   - alerts_rules: Metric, log search and Prometheus alert rules covering the cluster, whether scoped to the cluster,
+     its resource group or subscription (when targeting AKS clusters) or to the workspaces it sends data to,
+     with the action groups they notify and the baseline templates no rule covers
+   - alerts_fired: Alerts fired on the cluster in a window (default last 24h, at most 30 days back), grouped by rule
+     and severity, newest first. Optional: start_time, end_time, state (New, Acknowledged, Closed),
+     monitor_condition (Fired, Resolved)
+   - alerts_create_baseline: Create the recommended metric alerts in the cluster's resource group (requires readwrite access).
+     Templates: node-cpu-high, node-memory-high, node-disk-high, nodes-not-ready, pods-failed. Templates already covered
+     by an enabled rule on the same metric are skipped. Use dry_run=true to preview the rules, also with readonly access.
+     Optional: templates (comma separated, default all), action_group_id
+   Required parameters: subscription_id, resource_group, cluster_name
+
 Use This Tool When You Need To:
 - Monitor cluster or other azure resource performance and usage (use metrics)
 - Check cluster availability and platform health (use resource_health)
@@ -74,12 +205,38 @@ Use This Tool When You Need To:
 - Check storage-related problems (use control_plane_logs with csi-azuredisk-controller, csi-azurefile-controller)
 - Analyze cluster scaling behavior (use control_plane_logs with cluster-autoscaler)
 - Review security audit events (use control_plane_logs with kube-audit, kube-audit-admin)
+- Get a quick health overview of a cluster (use cluster_health_dashboard)
+- Read application logs, pod restarts or Kubernetes events (use container_logs, pod_inventory, kube_events)
+- Find out who changed, deleted or accessed something in the cluster (use audit_investigation)
+- Ask a question the fixed operations cannot answer (use log_analytics_query)
+- Find out who changed the cluster, its node pools or its node resource group and when (use activity_log)
+- Query Prometheus metrics collected by Azure Monitor managed Prometheus (use promql)
+- Turn on a control plane log category that control_plane_logs reports as not enabled (use diagnostics_enable)
+- See which alert rules cover a cluster and what fired recently (use alerts_rules, alerts_fired)
+- Add the recommended alerts to a cluster that has none (use alerts_create_baseline)
 
 Examples:
 
 metrics:
-- Get CPU usage: operation="metrics", query_type="list", parameters="{\"resource\":\"/subscriptions/sub-id/resourceGroups/rg/providers/Microsoft.ContainerService/managedClusters/cluster\", \"metrics\":\"node_cpu_usage_percentage\", \"aggregation\":\"Average\", \"start-time\":\"<start-time>\", \"end-time\":\"<end-time>\"}"
+- Get CPU usage: operation="metrics", query_type="list", parameters="{\"resource\":\"/subscriptions/sub-id/resourceGroups/rg/providers/Microsoft.ContainerService/managedClusters/cluster\", \"metrics\":\"node_cpu_usage_percentage\", \"aggregation\":\"Average\", \"window\":\"last 6h\"}"
 - List available metrics: operation="metrics", query_type="list-definitions", parameters="{\"resource\":\"/subscriptions/sub-id/resourceGroups/rg/providers/Microsoft.ContainerService/managedClusters/cluster\"}"
+- Browse the AKS metric catalog: operation="metrics", query_type="catalog", parameters="{\"category\":\"node\"}"
+
+cluster_health_dashboard:
+- Golden signals for the last 6 hours: operation="cluster_health_dashboard", subscription_id="<subscription-id>", resource_group="<resource-group>", cluster_name="<cluster-name>", parameters="{\"window\":\"last 6h\"}"
+
+container_logs:
+- Errors from a deployment: operation="container_logs", subscription_id="<subscription-id>", resource_group="<resource-group>", cluster_name="<cluster-name>", parameters="{\"namespace\":\"shop\", \"container\":\"api\", \"severity\":\"error\", \"search\":\"timeout\"}"
+
+kube_events:
+- Warning events in a namespace: operation="kube_events", subscription_id="<subscription-id>", resource_group="<resource-group>", cluster_name="<cluster-name>", parameters="{\"namespace\":\"shop\", \"severity\":\"Warning\", \"start_time\":\"<start-time>\"}"
+
+audit_investigation:
+- Who deleted a deployment: operation="audit_investigation", subscription_id="<subscription-id>", resource_group="<resource-group>", cluster_name="<cluster-name>", parameters="{\"question\":\"resource\", \"resource\":\"deployments\", \"namespace\":\"shop\", \"name\":\"api\"}"
+- Actions of a service account: operation="audit_investigation", subscription_id="<subscription-id>", resource_group="<resource-group>", cluster_name="<cluster-name>", parameters="{\"question\":\"actor\", \"user\":\"system:serviceaccount:ci:deployer\", \"start_time\":\"<start-time>\"}"
+
+log_analytics_query:
+- Restarts per container: operation="log_analytics_query", subscription_id="<subscription-id>", resource_group="<resource-group>", cluster_name="<cluster-name>", parameters="{\"query\":\"KubePodInventory | summarize Restarts = max(ContainerRestartCount) by Namespace, Name | top 10 by Restarts\", \"start_time\":\"<start-time>\"}"
 
 resource_health:
 - Check recent cluster health: operation="resource_health", subscription_id="<subscription-id>", resource_group="<resource-group>", cluster_name="<cluster-name>", parameters="{\"start_time\":\"<start-time>\"}"
@@ -88,10 +245,29 @@ app_insights:
 - Query request telemetry: operation="app_insights", subscription_id="<subscription-id>", resource_group="<resource-group>", parameters="{\"app_insights_name\":\"myapp-insights\", \"query\":\"requests | where timestamp > ago(1h) | summarize count() by bin(timestamp, 5m)\"}"
 - Analyze exceptions: operation="app_insights", subscription_id="<subscription-id>", resource_group="<resource-group>", parameters="{\"app_insights_name\":\"myapp-insights\", \"query\":\"exceptions | where timestamp > ago(24h) | summarize count() by type, bin(timestamp, 1h)\"}"
 - Performance with timespan: operation="app_insights", subscription_id="<subscription-id>", resource_group="<resource-group>", parameters="{\"app_insights_name\":\"myapp-insights\", \"query\":\"performanceCounters | where category == 'Processor' | summarize avg(value) by bin(timestamp, 5m)\", \"timespan\":\"PT1H\"}"
+- Failing requests of one role: operation="app_insights", subscription_id="<subscription-id>", resource_group="<resource-group>", parameters="{\"app_insights_name\":\"myapp-insights\", \"analysis\":\"failed_requests\", \"role_name\":\"orders-api\", \"cluster_name\":\"myakscluster\"}"
+- End-to-end trace: operation="app_insights", subscription_id="<subscription-id>", resource_group="<resource-group>", parameters="{\"app_insights_name\":\"myapp-insights\", \"analysis\":\"trace\", \"operation_id\":\"4bf92f3577b34da6a3ce929d0e0e4736\"}"
 
 diagnostics:
 - Verify diagnostic settings: operation="diagnostics", subscription_id="<subscription-id>", resource_group="<resource-group>", cluster_name="<cluster-name>", parameters="{}"
 
+activity_log:
+- Changes in the last 3 days: operation="activity_log", subscription_id="<subscription-id>", resource_group="<resource-group>", cluster_name="<cluster-name>", parameters="{\"start_time\":\"<start-time>\"}"
+
+promql:
+- List the built-in queries: operation="promql", parameters="{\"mode\":\"library\"}"
+- Pods restarting now: operation="promql", subscription_id="<subscription-id>", resource_group="<resource-group>", cluster_name="<cluster-name>", parameters="{\"query_name\":\"pod_restarts\"}"
+- Node CPU over a day: operation="promql", subscription_id="<subscription-id>", resource_group="<resource-group>", cluster_name="<cluster-name>", parameters="{\"mode\":\"range\", \"query_name\":\"node_cpu_utilization\", \"window\":\"last 24h\"}"
+- Custom query: operation="promql", subscription_id="<subscription-id>", resource_group="<resource-group>", cluster_name="<cluster-name>", parameters="{\"query\":\"topk(5, sum by (namespace) (kube_pod_container_status_restarts_total{cluster=\\\"<cluster-name>\\\"}))\"}"
+
+diagnostics_enable:
+- Preview enabling audit logs: operation="diagnostics_enable", subscription_id="<subscription-id>", resource_group="<resource-group>", cluster_name="<cluster-name>", dry_run=true, parameters="{\"categories\":\"kube-audit-admin,guard\", \"workspace_resource_id\":\"/subscriptions/<subscription-id>/resourceGroups/<resource-group>/providers/Microsoft.OperationalInsights/workspaces/<workspace>\"}"
+
+alerts:
+- Rules covering a cluster: operation="alerts_rules", subscription_id="<subscription-id>", resource_group="<resource-group>", cluster_name="<cluster-name>", parameters="{}"
+- Alerts still firing: operation="alerts_fired", subscription_id="<subscription-id>", resource_group="<resource-group>", cluster_name="<cluster-name>", parameters="{\"monitor_condition\":\"Fired\", \"start_time\":\"<start-time>\"}"
+- Preview baseline alerts: operation="alerts_create_baseline", subscription_id="<subscription-id>", resource_group="<resource-group>", cluster_name="<cluster-name>", dry_run=true, parameters="{\"action_group_id\":\"/subscriptions/<subscription-id>/resourceGroups/<resource-group>/providers/Microsoft.Insights/actionGroups/<action-group>\"}"
+
 control_plane_logs:
 - Query API server logs: operation="control_plane_logs", subscription_id="<subscription-id>", resource_group="<resource-group>", cluster_name="<cluster-name>", parameters="{\"log_category\":\"kube-apiserver\", \"start_time\":\"<start-time>\", \"end_time\":\"<end-time>\", \"max_records\":\"50\"}"
 - Debug authentication issues: operation="control_plane_logs", subscription_id="<subscription-id>", resource_group="<resource-group>", cluster_name="<cluster-name>", parameters="{\"log_category\":\"guard\", \"start_time\":\"<start-time>\", \"end_time\":\"<end-time>\", \"max_records\":\"100\"}"
@@ -102,23 +278,26 @@ control_plane_logs:
 		mcp.WithDescription(description),
 		mcp.WithString("operation",
 			mcp.Required(),
-			mcp.Description("The monitoring operation to perform: 'metrics' (CPU/memory/network), 'resource_health' (cluster availability), 'app_insights' (telemetry analysis), 'diagnostics' (logging config), 'control_plane_logs' (Kubernetes logs like kube-apiserver, kube-audit, guard, etc.)"),
+			mcp.Description("The monitoring operation to perform: 'metrics' (CPU/memory/network), 'resource_health' (cluster availability), 'app_insights' (telemetry analysis), 'diagnostics' (logging config), 'control_plane_logs' (Kubernetes logs like kube-apiserver, kube-audit, guard, etc.), 'cluster_health_dashboard' (golden-signals summary), 'container_logs'/'pod_inventory'/'kube_events'/'node_inventory'/'insights_metrics' (Container Insights), 'audit_investigation' (who did what from audit logs), 'log_analytics_query' (guarded free-form KQL), 'activity_log' (change history), 'promql' (managed Prometheus), 'diagnostics_enable' (enable control plane log categories, readwrite), 'alerts_rules'/'alerts_fired' (alert rules and fired alerts), 'alerts_create_baseline' (recommended metric alerts, readwrite)"),
 		),
 		mcp.WithString("query_type",
-			mcp.Description("For metrics operations only: 'list' (get metric values), 'list-definitions' (available metrics), 'list-namespaces' (metric categories)"),
+			mcp.Description("For metrics operations only: 'list' (get metric values), 'list-definitions' (available metrics), 'list-namespaces' (metric categories), 'catalog' (curated AKS metrics)"),
 		),
 		mcp.WithString("parameters",
 			mcp.Required(),
-			mcp.Description("JSON string with operation parameters. metrics: resource (required), metrics (required for 'list' query_type), aggregation/start-time/end-time/interval/filter (optional). resource_health: start_time, end_time, status. app_insights: app_insights_name, query, start_time/end_time OR timespan (optional). diagnostics: none required. control_plane_logs: log_category (kube-apiserver/kube-audit/guard/etc), start_time, end_time, max_records, log_level"),
+			mcp.Description("JSON string with operation parameters. metrics: resource (required), metrics (required for 'list' query_type), window or start-time/end-time, aggregation/interval/filter/namespace/sigma/include_points/max_points (optional). resource_health: start_time, end_time, status. app_insights: app_insights_name, query OR analysis (failed_requests/dependency_failures/top_exceptions/trace), operation_id, role_name, operation_name, top, cluster_name, cluster_resource_group, start_time/end_time OR timespan (optional). diagnostics: none required. control_plane_logs: log_category (kube-apiserver/kube-audit/guard/etc), start_time, end_time, max_records, log_level. cluster_health_dashboard: window (optional). Container Insights operations: start_time, end_time, max_records, namespace, pod, container, node, severity, status, search, metric (all optional). audit_investigation: question (actor/resource/denied/exec/secret_reads), user, resource, namespace, name, start_time, end_time, max_records. log_analytics_query: query (required), start_time, end_time, max_records. activity_log: start_time, end_time. promql: query or query_name, mode (instant/range/library), time, window or start_time/end_time, step, sigma, include_points, max_points, workspace. diagnostics_enable: categories (required, comma separated), workspace_resource_id (required), setting_name, convert_existing. alerts_rules: none. alerts_fired: start_time, end_time, state, monitor_condition. alerts_create_baseline: templates, action_group_id"),
 		),
 		mcp.WithString("subscription_id",
-			mcp.Description("Azure subscription ID (required for resource_health, app_insights, diagnostics, control_plane_logs)"),
+			mcp.Description("Azure subscription ID (required for resource_health, app_insights, diagnostics, control_plane_logs, cluster_health_dashboard, Container Insights operations, audit_investigation, log_analytics_query, activity_log, promql, diagnostics_enable, alerts operations)"),
 		),
 		mcp.WithString("resource_group",
-			mcp.Description("Resource group name (required for resource_health, app_insights, diagnostics, control_plane_logs)"),
+			mcp.Description("Resource group name (required for resource_health, app_insights, diagnostics, control_plane_logs, cluster_health_dashboard, Container Insights operations, audit_investigation, log_analytics_query, activity_log, promql, diagnostics_enable, alerts operations)"),
 		),
 		mcp.WithString("cluster_name",
-			mcp.Description("AKS cluster name (required for resource_health, diagnostics, control_plane_logs)"),
+			mcp.Description("AKS cluster name (required for resource_health, diagnostics, control_plane_logs, cluster_health_dashboard, Container Insights operations, audit_investigation, log_analytics_query, activity_log, promql, diagnostics_enable, alerts operations)"),
+		),
+		mcp.WithBoolean(dryrun.ParamName,
+			mcp.Description(dryrun.ParamDescription+" (diagnostics_enable and alerts_create_baseline only)"),
 		),
 	)
 }
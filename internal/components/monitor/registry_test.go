@@ -74,3 +74,16 @@ func TestValidateMetricsQueryType_ChecksValidTypes(t *testing.T) {
 		}
 	}
 }
+
+func TestIsReadOnlyMonitoringOperation(t *testing.T) {
+	for _, op := range []string{"metrics", "control_plane_logs", "log_analytics_query"} {
+		if !IsReadOnlyMonitoringOperation(op) {
+			t.Errorf("Expected operation '%s' to be read-only", op)
+		}
+	}
+	for _, op := range []string{"diagnostics_enable", "invalid"} {
+		if IsReadOnlyMonitoringOperation(op) {
+			t.Errorf("Expected operation '%s' not to be read-only", op)
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+)
+
+// AvailabilityTransition is a period a resource spent in one availability state
+type AvailabilityTransition struct {
+	State    string `json:"state"`
+	Start    string `json:"start"`
+	End      string `json:"end,omitempty"`
+	Duration string `json:"duration"`
+	Ongoing  bool   `json:"ongoing,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Summary  string `json:"summary,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+	Cause    string `json:"cause,omitempty"`
+	// RootCause is the root-cause analysis text, set once Azure has attributed the event
+	RootCause string `json:"root_cause,omitempty"`
+}
+
+// ResourceAvailability is the availability timeline of one resource
+type ResourceAvailability struct {
+	ResourceID   string                   `json:"resource_id"`
+	ResourceType string                   `json:"resource_type"`
+	CurrentState string                   `json:"current_state,omitempty"`
+	Timeline     []AvailabilityTransition `json:"timeline"`
+	Error        string                   `json:"error,omitempty"`
+}
+
+// RegionIncident is an active service health incident impacting the cluster's region
+type RegionIncident struct {
+	TrackingID  string   `json:"tracking_id"`
+	Title       string   `json:"title"`
+	Level       string   `json:"level,omitempty"`
+	ImpactStart string   `json:"impact_start,omitempty"`
+	LastUpdate  string   `json:"last_update,omitempty"`
+	Services    []string `json:"services,omitempty"`
+}
+
+// ResourceHealthReport is the availability of a cluster and its dependent resources
+type ResourceHealthReport struct {
+	ClusterResourceID string                 `json:"cluster_resource_id"`
+	Region            string                 `json:"region,omitempty"`
+	Start             string                 `json:"start"`
+	End               string                 `json:"end"`
+	Resources         []ResourceAvailability `json:"resources"`
+	ActiveIncidents   []RegionIncident       `json:"active_incidents"`
+	Notes             []string               `json:"notes,omitempty"`
+}
+
+// availabilityPoint is a state reported at a point in time
+type availabilityPoint struct {
+	at     time.Time
+	status azureclient.AvailabilityStatusProperties
+}
+
+// parseHealthTime parses the ISO 8601 times returned by the Resource Health API
+func parseHealthTime(value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.9999999", "2006-01-02T15:04:05"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// BuildAvailabilityTimeline turns availability statuses into state transitions that overlap
+// [start, end]. Consecutive statuses in the same state are merged. When state is set, only
+// transitions into that state are returned. The current state is the state of the latest status.
+func BuildAvailabilityTimeline(statuses []azureclient.AvailabilityStatus, start, end time.Time, state string) (string, []AvailabilityTransition) {
+	var points []availabilityPoint
+	for _, status := range statuses {
+		at, ok := parseHealthTime(status.Properties.OccurredTime)
+		if !ok {
+			at, ok = parseHealthTime(status.Properties.ReportedTime)
+		}
+		if !ok {
+			continue
+		}
+		points = append(points, availabilityPoint{at: at, status: status.Properties})
+
+		// The current status reports a recently resolved outage that may have no status of its own
+		if resolved := status.Properties.RecentlyResolved; resolved != nil {
+			from, okFrom := parseHealthTime(resolved.UnavailableOccurredTime)
+			to, okTo := parseHealthTime(resolved.ResolvedTime)
+			if okFrom && okTo && to.After(from) {
+				points = append(points,
+					availabilityPoint{at: from, status: azureclient.AvailabilityStatusProperties{AvailabilityState: "Unavailable", Summary: resolved.UnavailableSummary}},
+					availabilityPoint{at: to, status: azureclient.AvailabilityStatusProperties{AvailabilityState: "Available"}})
+			}
+		}
+	}
+	if len(points) == 0 {
+		return "", []AvailabilityTransition{}
+	}
+	sort.SliceStable(points, func(i, j int) bool { return points[i].at.Before(points[j].at) })
+	current := points[len(points)-1].status.AvailabilityState
+
+	// Merge consecutive points in the same state, keeping the details of the first one
+	var merged []availabilityPoint
+	for _, point := range points {
+		if n := len(merged); n > 0 && strings.EqualFold(merged[n-1].status.AvailabilityState, point.status.AvailabilityState) {
+			if merged[n-1].status.RootCauseAttributionTime == "" && point.status.RootCauseAttributionTime != "" {
+				merged[n-1].status.Summary = point.status.Summary
+				merged[n-1].status.RootCauseAttributionTime = point.status.RootCauseAttributionTime
+			}
+			continue
+		}
+		merged = append(merged, point)
+	}
+
+	timeline := []AvailabilityTransition{}
+	for i, point := range merged {
+		periodEnd := end
+		ongoing := i == len(merged)-1
+		if !ongoing {
+			periodEnd = merged[i+1].at
+		}
+		if periodEnd.Before(start) || point.at.After(end) {
+			continue
+		}
+		if state != "" && !strings.EqualFold(point.status.AvailabilityState, state) {
+			continue
+		}
+
+		transition := AvailabilityTransition{
+			State:    point.status.AvailabilityState,
+			Start:    point.at.Format(time.RFC3339),
+			Duration: periodEnd.Sub(point.at).Round(time.Second).String(),
+			Ongoing:  ongoing,
+			Title:    point.status.Title,
+			Summary:  point.status.Summary,
+			Reason:   point.status.ReasonType,
+			Cause:    point.status.HealthEventCause,
+		}
+		if !ongoing {
+			transition.End = periodEnd.Format(time.RFC3339)
+		}
+		if point.status.RootCauseAttributionTime != "" {
+			transition.RootCause = point.status.Summary
+		}
+		timeline = append(timeline, transition)
+	}
+	return current, timeline
+}
+
+// normalizeRegion makes "East US" and "eastus" comparable
+func normalizeRegion(region string) string {
+	return strings.ToLower(strings.ReplaceAll(region, " ", ""))
+}
+
+// ActiveRegionIncidents returns the active service issues that impact the region
+func ActiveRegionIncidents(events []azureclient.ServiceHealthEvent, region string) []RegionIncident {
+	incidents := []RegionIncident{}
+	target := normalizeRegion(region)
+	for _, event := range events {
+		props := event.Properties
+		if !strings.EqualFold(props.Status, "Active") || !strings.EqualFold(props.EventType, "ServiceIssue") {
+			continue
+		}
+
+		var services []string
+		for _, impact := range props.Impact {
+			for _, impacted := range impact.ImpactedRegions {
+				if normalizeRegion(impacted.ImpactedRegion) == target && !strings.EqualFold(impacted.Status, "Resolved") {
+					services = append(services, impact.ImpactedService)
+					break
+				}
+			}
+		}
+		if len(services) == 0 {
+			continue
+		}
+
+		level := props.EventLevel
+		if level == "" {
+			level = props.Level
+		}
+		incidents = append(incidents, RegionIncident{
+			TrackingID:  event.Name,
+			Title:       props.Title,
+			Level:       level,
+			ImpactStart: props.ImpactStartTime,
+			LastUpdate:  props.LastUpdateTime,
+			Services:    services,
+		})
+	}
+	return incidents
+}
+
+// clusterDependentResources lists the VMSS and Kubernetes load balancers in the node resource group
+func clusterDependentResources(ctx context.Context, clients *azureclient.SubscriptionClients, nodeResourceGroup string) ([]string, error) {
+	var resourceIDs []string
+
+	vmssPager := clients.VMSSClient.NewListPager(nodeResourceGroup, nil)
+	for vmssPager.More() {
+		page, err := vmssPager.NextPage(ctx)
+		if err != nil {
+			return resourceIDs, fmt.Errorf("failed to list VMSS in %s: %v", nodeResourceGroup, err)
+		}
+		for _, vmss := range page.Value {
+			if vmss != nil && vmss.ID != nil {
+				resourceIDs = append(resourceIDs, *vmss.ID)
+			}
+		}
+	}
+
+	lbPager := clients.LoadBalancerClient.NewListPager(nodeResourceGroup, nil)
+	for lbPager.More() {
+		page, err := lbPager.NextPage(ctx)
+		if err != nil {
+			return resourceIDs, fmt.Errorf("failed to list load balancers in %s: %v", nodeResourceGroup, err)
+		}
+		for _, lb := range page.Value {
+			if lb != nil && lb.ID != nil && lb.Name != nil && strings.HasPrefix(*lb.Name, "kubernetes") {
+				resourceIDs = append(resourceIDs, *lb.ID)
+			}
+		}
+	}
+	return resourceIDs, nil
+}
+
+// QueryResourceHealth builds the availability report of a cluster and its dependent resources
+func QueryResourceHealth(ctx context.Context, azClient *azureclient.AzureClient, subscriptionID, resourceGroup, clusterName string, start, end time.Time, state string) (*ResourceHealthReport, error) {
+	cluster, err := azClient.GetAKSCluster(ctx, subscriptionID, resourceGroup, clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get AKS cluster: %w", err)
+	}
+
+	clusterResourceID := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ContainerService/managedClusters/%s",
+		subscriptionID, resourceGroup, clusterName)
+	report := &ResourceHealthReport{
+		ClusterResourceID: clusterResourceID,
+		Start:             start.UTC().Format(time.RFC3339),
+		End:               end.UTC().Format(time.RFC3339),
+		ActiveIncidents:   []RegionIncident{},
+	}
+	if cluster.Location != nil {
+		report.Region = *cluster.Location
+	}
+
+	resourceIDs := []string{clusterResourceID}
+	if cluster.Properties != nil && cluster.Properties.NodeResourceGroup != nil {
+		clients, err := azClient.GetOrCreateClientsForSubscription(subscriptionID)
+		if err != nil {
+			return nil, err
+		}
+		dependents, err := clusterDependentResources(ctx, clients, *cluster.Properties.NodeResourceGroup)
+		if err != nil {
+			report.Notes = append(report.Notes, fmt.Sprintf("Some dependent resources could not be listed: %v", err))
+		}
+		resourceIDs = append(resourceIDs, dependents...)
+	}
+
+	// Query the resources in parallel, keeping them in the listed order
+	report.Resources = make([]ResourceAvailability, len(resourceIDs))
+	var wg sync.WaitGroup
+	for i, resourceID := range resourceIDs {
+		wg.Add(1)
+		go func(i int, resourceID string) {
+			defer wg.Done()
+			availability := ResourceAvailability{ResourceID: resourceID, Timeline: []AvailabilityTransition{}}
+			if parsed, err := arm.ParseResourceID(resourceID); err == nil {
+				availability.ResourceType = parsed.ResourceType.String()
+			}
+			statuses, err := azClient.ListAvailabilityStatuses(ctx, resourceID)
+			if err != nil {
+				availability.Error = err.Error()
+			} else {
+				availability.CurrentState, availability.Timeline = BuildAvailabilityTimeline(statuses, start, end, state)
+			}
+			report.Resources[i] = availability
+		}(i, resourceID)
+	}
+	wg.Wait()
+
+	if report.Region != "" {
+		events, err := azClient.ListServiceHealthEvents(ctx, subscriptionID, start)
+		if err != nil {
+			report.Notes = append(report.Notes, fmt.Sprintf("Service health incidents could not be checked: %v", err))
+		} else {
+			report.ActiveIncidents = ActiveRegionIncidents(events, report.Region)
+		}
+	}
+	if len(report.ActiveIncidents) > 0 {
+		report.Notes = append(report.Notes, fmt.Sprintf("%d active service incident(s) impact %s", len(report.ActiveIncidents), report.Region))
+	}
+	return report, nil
+}
@@ -0,0 +1,129 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+)
+
+func availabilityStatus(state, occurred, summary, rootCauseTime string) azureclient.AvailabilityStatus {
+	return azureclient.AvailabilityStatus{Properties: azureclient.AvailabilityStatusProperties{
+		AvailabilityState:        state,
+		OccurredTime:             occurred,
+		Summary:                  summary,
+		RootCauseAttributionTime: rootCauseTime,
+	}}
+}
+
+func TestBuildAvailabilityTimeline(t *testing.T) {
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	statuses := []azureclient.AvailabilityStatus{
+		availabilityStatus("Available", "2025-03-01T14:30:00Z", "Recovered", ""),
+		availabilityStatus("Unavailable", "2025-03-01T12:00:00Z", "Platform issue", ""),
+		availabilityStatus("Unavailable", "2025-03-01T12:10:00Z", "A storage outage in the region", "2025-03-02T09:00:00Z"),
+		availabilityStatus("Available", "2025-02-27T00:00:00Z", "", ""),
+		availabilityStatus("Unknown", "not a time", "", ""),
+	}
+
+	current, timeline := BuildAvailabilityTimeline(statuses, start, end, "")
+	if current != "Available" {
+		t.Errorf("Expected current state Available, got %s", current)
+	}
+	if len(timeline) != 3 {
+		t.Fatalf("Expected 3 transitions, got %+v", timeline)
+	}
+	outage := timeline[1]
+	if outage.State != "Unavailable" || outage.Duration != "2h30m0s" || outage.End != "2025-03-01T14:30:00Z" {
+		t.Errorf("Unexpected outage transition: %+v", outage)
+	}
+	if outage.RootCause != "A storage outage in the region" {
+		t.Errorf("Expected the attributed root cause to be kept, got %q", outage.RootCause)
+	}
+	if last := timeline[2]; !last.Ongoing || last.End != "" || last.Duration != "9h30m0s" {
+		t.Errorf("Expected the last transition to be ongoing until the end of the window, got %+v", last)
+	}
+
+	_, filtered := BuildAvailabilityTimeline(statuses, start, end, "unavailable")
+	if len(filtered) != 1 || filtered[0].State != "Unavailable" {
+		t.Errorf("Expected only the Unavailable transition, got %+v", filtered)
+	}
+
+	if current, timeline := BuildAvailabilityTimeline(nil, start, end, ""); current != "" || len(timeline) != 0 {
+		t.Errorf("Expected an empty timeline, got %s %+v", current, timeline)
+	}
+}
+
+func TestBuildAvailabilityTimeline_RecentlyResolved(t *testing.T) {
+	status := availabilityStatus("Available", "2025-03-01T10:00:00Z", "", "")
+	status.Properties.RecentlyResolved = &struct {
+		UnavailableOccurredTime string `json:"unavailableOccuredTime"`
+		ResolvedTime            string `json:"resolvedTime"`
+		UnavailableSummary      string `json:"unavailableSummary"`
+	}{"2025-03-01T11:00:00Z", "2025-03-01T11:20:00Z", "Node pool reboot"}
+
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	_, timeline := BuildAvailabilityTimeline([]azureclient.AvailabilityStatus{status}, start, start.Add(24*time.Hour), "")
+	if len(timeline) != 3 || timeline[1].State != "Unavailable" || timeline[1].Summary != "Node pool reboot" || timeline[1].Duration != "20m0s" {
+		t.Errorf("Expected the recently resolved outage in the timeline, got %+v", timeline)
+	}
+}
+
+func TestActiveRegionIncidents(t *testing.T) {
+	event := func(name, status, eventType, region, regionStatus string) azureclient.ServiceHealthEvent {
+		e := azureclient.ServiceHealthEvent{Name: name}
+		e.Properties.Status = status
+		e.Properties.EventType = eventType
+		e.Properties.Title = name
+		e.Properties.Impact = append(e.Properties.Impact, struct {
+			ImpactedService string `json:"impactedService"`
+			ImpactedRegions []struct {
+				ImpactedRegion string `json:"impactedRegion"`
+				Status         string `json:"status"`
+			} `json:"impactedRegions"`
+		}{ImpactedService: "Azure Kubernetes Service"})
+		e.Properties.Impact[0].ImpactedRegions = append(e.Properties.Impact[0].ImpactedRegions, struct {
+			ImpactedRegion string `json:"impactedRegion"`
+			Status         string `json:"status"`
+		}{region, regionStatus})
+		return e
+	}
+
+	incidents := ActiveRegionIncidents([]azureclient.ServiceHealthEvent{
+		event("IN-1", "Active", "ServiceIssue", "East US", "Active"),
+		event("IN-2", "Resolved", "ServiceIssue", "East US", "Resolved"),
+		event("IN-3", "Active", "ServiceIssue", "West Europe", "Active"),
+		event("PM-1", "Active", "PlannedMaintenance", "East US", "Active"),
+		event("IN-4", "Active", "ServiceIssue", "East US", "Resolved"),
+	}, "eastus")
+
+	if len(incidents) != 1 || incidents[0].TrackingID != "IN-1" || incidents[0].Services[0] != "Azure Kubernetes Service" {
+		t.Errorf("Expected only IN-1, got %+v", incidents)
+	}
+}
+
+func TestHandleResourceHealthQuery_Validation(t *testing.T) {
+	params := map[string]interface{}{
+		"subscription_id": "sub-1",
+		"resource_group":  "rg-1",
+		"cluster_name":    "aks-1",
+		"start_time":      "2025-03-01T00:00:00Z",
+		"status":          "Broken",
+	}
+	if _, err := HandleResourceHealthQuery(params, nil, nil); err == nil || !strings.Contains(err.Error(), "invalid status") {
+		t.Errorf("Expected an invalid status error, got %v", err)
+	}
+
+	params["status"] = "Unavailable"
+	params["end_time"] = "2025-02-01T00:00:00Z"
+	if _, err := HandleResourceHealthQuery(params, nil, nil); err == nil || !strings.Contains(err.Error(), "end_time must be after start_time") {
+		t.Errorf("Expected a time range error, got %v", err)
+	}
+
+	delete(params, "end_time")
+	if _, err := HandleResourceHealthQuery(params, nil, nil); err == nil || !strings.Contains(err.Error(), "azure client is required") {
+		t.Errorf("Expected an Azure client error, got %v", err)
+	}
+}
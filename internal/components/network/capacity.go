@@ -0,0 +1,442 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/tools"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
+)
+
+// Network plugin modes, which decide how many subnet IPs a node takes
+const (
+	PluginModeKubenet      = "kubenet"
+	PluginModeAzureCNI     = "azure-cni"
+	PluginModeCNIOverlay   = "azure-cni-overlay"
+	PluginModeCNIPodSubnet = "azure-cni-pod-subnet"
+	PluginModeNone         = "none"
+)
+
+// azureReservedIPs is the number of addresses Azure reserves in every subnet
+const azureReservedIPs = 5
+
+// subnetUsageWarnPercent is the subnet usage above which scale-outs are flagged
+const subnetUsageWarnPercent = 80
+
+// Default max pods per node when the node pool does not report it
+const (
+	defaultMaxPodsAzureCNI = 30
+	defaultMaxPodsKubenet  = 110
+	defaultMaxPodsOverlay  = 250
+)
+
+// SubnetUsage is the IP usage of a node or pod subnet
+type SubnetUsage struct {
+	ID            string   `json:"id"`
+	Role          string   `json:"role"`
+	AddressPrefix string   `json:"address_prefix"`
+	UsableIPs     int      `json:"usable_ips"`
+	UsedIPs       int      `json:"used_ips"`
+	FreeIPs       int      `json:"free_ips"`
+	UsagePercent  float64  `json:"usage_percent"`
+	NodePools     []string `json:"node_pools"`
+}
+
+// NodePoolCapacity is the IP demand of a node pool and the scale its subnets allow
+type NodePoolCapacity struct {
+	Name            string `json:"name"`
+	PluginMode      string `json:"plugin_mode"`
+	Subnet          string `json:"subnet"`
+	PodSubnet       string `json:"pod_subnet,omitempty"`
+	NodeCount       int    `json:"node_count"`
+	Autoscaling     bool   `json:"autoscaling"`
+	MaxCount        int    `json:"max_count,omitempty"`
+	MaxPods         int    `json:"max_pods"`
+	NodeIPsPerNode  int    `json:"node_subnet_ips_per_node"`
+	PodIPsPerNode   int    `json:"pod_subnet_ips_per_node,omitempty"`
+	MaxSurge        string `json:"max_surge"`
+	SurgeNodes      int    `json:"surge_nodes"`
+	MaxNodes        int    `json:"max_nodes"`
+	MaxSafeNodes    int    `json:"max_safe_nodes"`
+	LimitedBy       string `json:"limited_by"`
+	ScaleTarget     int    `json:"scale_target"`
+	UpgradeHeadroom int    `json:"upgrade_headroom_nodes"`
+	// SharedSubnet is set when another node pool uses the same node or pod subnet. MaxNodes and
+	// MaxSafeNodes then count all free IPs for this pool, so they are upper bounds.
+	SharedSubnet bool `json:"shared_subnet,omitempty"`
+}
+
+// CapacityInput is the node pool and subnet state of a cluster
+type CapacityInput struct {
+	ClusterName   string
+	NetworkPlugin string
+	Overlay       bool
+	NodePools     []*armcontainerservice.ManagedClusterAgentPoolProfile
+	// NodeSubnetIDs maps each node pool name to its node subnet ID
+	NodeSubnetIDs map[string]string
+	// Subnets are the node and pod subnets, keyed by lower-cased resource ID
+	Subnets map[string]*armnetwork.Subnet
+}
+
+// SubnetCapacityReport is the subnet IP usage of a cluster and the safe scale of each node pool
+type SubnetCapacityReport struct {
+	ClusterName string             `json:"cluster_name"`
+	PluginMode  string             `json:"plugin_mode"`
+	Subnets     []SubnetUsage      `json:"subnets"`
+	NodePools   []NodePoolCapacity `json:"node_pools"`
+	Findings    []Finding          `json:"findings"`
+	Notes       []string           `json:"notes,omitempty"`
+}
+
+// clusterPluginMode returns the network plugin mode of a node pool
+func clusterPluginMode(networkPlugin string, overlay bool, pool *armcontainerservice.ManagedClusterAgentPoolProfile) string {
+	switch armcontainerservice.NetworkPlugin(networkPlugin) {
+	case armcontainerservice.NetworkPluginKubenet:
+		return PluginModeKubenet
+	case armcontainerservice.NetworkPluginNone:
+		return PluginModeNone
+	}
+	switch {
+	case derefString(pool.PodSubnetID) != "":
+		return PluginModeCNIPodSubnet
+	case overlay:
+		return PluginModeCNIOverlay
+	default:
+		return PluginModeAzureCNI
+	}
+}
+
+// ipsPerNode returns the IPs a node takes from its node subnet and from its pod subnet
+func ipsPerNode(mode string, maxPods int) (nodeIPs, podIPs int) {
+	switch mode {
+	case PluginModeAzureCNI:
+		// Azure CNI preallocates an IP for every pod the node can run
+		return 1 + maxPods, 0
+	case PluginModeCNIPodSubnet:
+		return 1, maxPods
+	default:
+		// Kubenet and overlay pods take their IPs from a separate pod CIDR
+		return 1, 0
+	}
+}
+
+// surgeNodes returns the extra nodes an upgrade adds for a max surge of an integer or a percentage
+func surgeNodes(maxSurge string, nodeCount int) (int, error) {
+	if maxSurge == "" {
+		return 1, nil
+	}
+	if percent, ok := strings.CutSuffix(maxSurge, "%"); ok {
+		value, err := strconv.ParseFloat(percent, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid max surge %q", maxSurge)
+		}
+		return int(math.Ceil(float64(nodeCount) * value / 100)), nil
+	}
+	value, err := strconv.Atoi(maxSurge)
+	if err != nil {
+		return 0, fmt.Errorf("invalid max surge %q", maxSurge)
+	}
+	return value, nil
+}
+
+// safeNodeCount returns the largest node count whose own upgrade surge still fits in maxNodes
+func safeNodeCount(maxNodes int, surgeFor func(nodeCount int) int) int {
+	// n + surge(n) grows with n, so search for the first count that no longer fits
+	fits := sort.Search(maxNodes+1, func(n int) bool { return n+surgeFor(n) > maxNodes })
+	return max(fits-1, 0)
+}
+
+// subnetUsage counts the usable and used IPv4 addresses of a subnet
+func subnetUsage(subnetID, role string, subnet *armnetwork.Subnet) SubnetUsage {
+	usage := SubnetUsage{ID: subnetID, Role: role}
+	if subnet == nil || subnet.Properties == nil {
+		return usage
+	}
+	var prefixes []string
+	for _, prefix := range parsePrefixes(append([]*string{subnet.Properties.AddressPrefix}, subnet.Properties.AddressPrefixes...)) {
+		if !prefix.Addr().Is4() {
+			continue
+		}
+		prefixes = append(prefixes, prefix.String())
+		if size := 1<<(32-prefix.Bits()) - azureReservedIPs; size > 0 {
+			usage.UsableIPs += size
+		}
+	}
+	usage.AddressPrefix = strings.Join(prefixes, ", ")
+	usage.UsedIPs = len(subnet.Properties.IPConfigurations)
+	usage.FreeIPs = max(usage.UsableIPs-usage.UsedIPs, 0)
+	if usage.UsableIPs > 0 {
+		usage.UsagePercent = math.Round(float64(usage.UsedIPs)/float64(usage.UsableIPs)*1000) / 10
+	}
+	return usage
+}
+
+// AnalyzeSubnetCapacity computes the subnet usage of a cluster and the largest scale of each node pool
+// that still leaves room for the surge nodes of an upgrade
+func AnalyzeSubnetCapacity(input CapacityInput) SubnetCapacityReport {
+	report := SubnetCapacityReport{ClusterName: input.ClusterName, Findings: []Finding{}}
+
+	usages := map[string]*SubnetUsage{}
+	var order []string
+	usageFor := func(subnetID, role, poolName string) *SubnetUsage {
+		key := strings.ToLower(subnetID)
+		usage, ok := usages[key]
+		if !ok {
+			u := subnetUsage(subnetID, role, input.Subnets[key])
+			usage = &u
+			usages[key] = usage
+			order = append(order, key)
+		}
+		// A pool whose pod subnet is also its node subnet is listed once
+		if n := len(usage.NodePools); n == 0 || usage.NodePools[n-1] != poolName {
+			usage.NodePools = append(usage.NodePools, poolName)
+		}
+		return usage
+	}
+
+	// extraIPs is the IP demand of every pool on a subnet scaling to its target, and surgeIPs the largest upgrade surge
+	extraIPs := map[string]int{}
+	surgeIPs := map[string]int{}
+	for _, pool := range input.NodePools {
+		if pool == nil || pool.Name == nil {
+			continue
+		}
+		capacity := NodePoolCapacity{
+			Name:        *pool.Name,
+			PluginMode:  clusterPluginMode(input.NetworkPlugin, input.Overlay, pool),
+			Subnet:      input.NodeSubnetIDs[*pool.Name],
+			PodSubnet:   derefString(pool.PodSubnetID),
+			NodeCount:   int(derefInt32(pool.Count)),
+			Autoscaling: pool.EnableAutoScaling != nil && *pool.EnableAutoScaling,
+			MaxPods:     int(derefInt32(pool.MaxPods)),
+		}
+		if report.PluginMode == "" {
+			report.PluginMode = capacity.PluginMode
+		}
+		if capacity.MaxPods == 0 {
+			switch capacity.PluginMode {
+			case PluginModeKubenet:
+				capacity.MaxPods = defaultMaxPodsKubenet
+			case PluginModeCNIOverlay:
+				capacity.MaxPods = defaultMaxPodsOverlay
+			default:
+				capacity.MaxPods = defaultMaxPodsAzureCNI
+			}
+		}
+		capacity.NodeIPsPerNode, capacity.PodIPsPerNode = ipsPerNode(capacity.PluginMode, capacity.MaxPods)
+
+		capacity.ScaleTarget = capacity.NodeCount
+		if capacity.Autoscaling {
+			capacity.MaxCount = int(derefInt32(pool.MaxCount))
+			capacity.ScaleTarget = max(capacity.MaxCount, capacity.NodeCount)
+		}
+		if pool.UpgradeSettings != nil {
+			capacity.MaxSurge = derefString(pool.UpgradeSettings.MaxSurge)
+		}
+		maxSurge := capacity.MaxSurge
+		if _, err := surgeNodes(maxSurge, capacity.ScaleTarget); err != nil {
+			report.Notes = append(report.Notes, fmt.Sprintf("Node pool %s: %v; assuming a surge of 1 node.", capacity.Name, err))
+			maxSurge = "1"
+		}
+		// surgeFor returns the surge of an upgrade at a node count, which differs by count for a percentage
+		surgeFor := func(nodeCount int) int {
+			surge, _ := surgeNodes(maxSurge, nodeCount)
+			return surge
+		}
+		if capacity.MaxSurge == "" {
+			capacity.MaxSurge = "1 (default)"
+		}
+		surge := surgeFor(capacity.ScaleTarget)
+		capacity.SurgeNodes = surge
+
+		nodeUsage := usageFor(capacity.Subnet, "node", capacity.Name)
+		capacity.MaxNodes = capacity.NodeCount + nodeUsage.FreeIPs/capacity.NodeIPsPerNode
+		capacity.LimitedBy = "node subnet"
+		key := strings.ToLower(capacity.Subnet)
+		extraIPs[key] += (capacity.ScaleTarget - capacity.NodeCount) * capacity.NodeIPsPerNode
+		surgeIPs[key] = max(surgeIPs[key], surge*capacity.NodeIPsPerNode)
+		if capacity.PodIPsPerNode > 0 {
+			podUsage := usageFor(capacity.PodSubnet, "pod", capacity.Name)
+			if podMax := capacity.NodeCount + podUsage.FreeIPs/capacity.PodIPsPerNode; podMax < capacity.MaxNodes {
+				capacity.MaxNodes = podMax
+				capacity.LimitedBy = "pod subnet"
+			}
+			podKey := strings.ToLower(capacity.PodSubnet)
+			extraIPs[podKey] += (capacity.ScaleTarget - capacity.NodeCount) * capacity.PodIPsPerNode
+			surgeIPs[podKey] = max(surgeIPs[podKey], surge*capacity.PodIPsPerNode)
+		}
+		capacity.MaxSafeNodes = safeNodeCount(capacity.MaxNodes, surgeFor)
+		capacity.UpgradeHeadroom = capacity.MaxNodes - capacity.ScaleTarget - surge
+
+		report.Findings = append(report.Findings, nodePoolCapacityFindings(capacity, surgeFor(capacity.NodeCount))...)
+		report.NodePools = append(report.NodePools, capacity)
+	}
+
+	for _, key := range order {
+		usage := usages[key]
+		report.Subnets = append(report.Subnets, *usage)
+		name := resourceName(usage.ID)
+		if usage.UsableIPs == 0 {
+			report.Notes = append(report.Notes, fmt.Sprintf("The address prefix of %s subnet %s could not be read.", usage.Role, name))
+			continue
+		}
+		if len(usage.NodePools) > 1 {
+			markSharedSubnet(report.NodePools, usage.NodePools)
+			report.Notes = append(report.Notes, fmt.Sprintf("Node pools %s share %s subnet %s. Their max_nodes and max_safe_nodes each count all %d free IPs, so they are upper bounds that only hold while the other pools do not grow.",
+				strings.Join(usage.NodePools, ", "), usage.Role, name, usage.FreeIPs))
+		}
+		if usage.UsagePercent >= subnetUsageWarnPercent {
+			report.Findings = append(report.Findings, Finding{
+				Severity: SeverityMedium,
+				Check:    "subnet_usage_high",
+				Message:  fmt.Sprintf("%s subnet %s (%s) uses %d of %d IPs (%.1f%%).", strings.ToUpper(usage.Role[:1])+usage.Role[1:], name, usage.AddressPrefix, usage.UsedIPs, usage.UsableIPs, usage.UsagePercent),
+			})
+		}
+		if len(usage.NodePools) > 1 && extraIPs[key]+surgeIPs[key] > usage.FreeIPs {
+			report.Findings = append(report.Findings, Finding{
+				Severity: SeverityMedium,
+				Check:    "shared_subnet_capacity",
+				Message: fmt.Sprintf("Node pools %s share %s subnet %s. Scaling them all to their maximum and upgrading needs %d more IPs, but only %d are free.",
+					strings.Join(usage.NodePools, ", "), usage.Role, name, extraIPs[key]+surgeIPs[key], usage.FreeIPs),
+				Remediation: "Lower the autoscaler maximums, move node pools to their own subnets, or expand the subnet.",
+			})
+		}
+	}
+	return report
+}
+
+// markSharedSubnet flags the capacity of the named node pools as sharing a subnet
+func markSharedSubnet(capacities []NodePoolCapacity, poolNames []string) {
+	for i := range capacities {
+		for _, name := range poolNames {
+			if capacities[i].Name == name {
+				capacities[i].SharedSubnet = true
+			}
+		}
+	}
+}
+
+// nodePoolCapacityFindings flags node pools whose scale target or upgrade surge does not fit their
+// subnets. currentSurge is the surge of an upgrade at the current node count.
+func nodePoolCapacityFindings(capacity NodePoolCapacity, currentSurge int) []Finding {
+	remediation := "Expand the subnet, lower maxCount or maxSurge, or add a node pool on another subnet."
+	switch capacity.PluginMode {
+	case PluginModeAzureCNI:
+		remediation += fmt.Sprintf(" Each node reserves %d IPs for maxPods=%d; a lower maxPods or Azure CNI overlay needs far fewer subnet IPs.", capacity.NodeIPsPerNode, capacity.MaxPods)
+	case PluginModeCNIPodSubnet:
+		remediation = "Expand the node or pod subnet, lower maxCount or maxSurge, or lower maxPods."
+	}
+
+	switch {
+	case capacity.ScaleTarget > capacity.MaxNodes:
+		return []Finding{{
+			Severity: SeverityHigh,
+			Check:    "scale_exceeds_subnet",
+			Message: fmt.Sprintf("Node pool %s can scale to %d nodes, but its %s only has IPs for %d nodes (%d now). Scale-outs beyond that fail.",
+				capacity.Name, capacity.ScaleTarget, capacity.LimitedBy, capacity.MaxNodes, capacity.NodeCount),
+			Remediation: remediation,
+		}}
+	case capacity.UpgradeHeadroom < 0:
+		severity := SeverityMedium
+		if capacity.NodeCount+currentSurge > capacity.MaxNodes {
+			severity = SeverityHigh
+		}
+		return []Finding{{
+			Severity: severity,
+			Check:    "upgrade_surge_exceeds_subnet",
+			Message: fmt.Sprintf("An upgrade of node pool %s at %d nodes adds %d surge nodes, but its %s only has IPs for %d nodes. Keep the pool at or below %d nodes to upgrade safely.",
+				capacity.Name, capacity.ScaleTarget, capacity.SurgeNodes, capacity.LimitedBy, capacity.MaxNodes, capacity.MaxSafeNodes),
+			Remediation: remediation,
+		}}
+	}
+	return nil
+}
+
+// =============================================================================
+// subnet_capacity handler
+// =============================================================================
+
+// GetSubnetCapacityHandler returns the handler for the subnet_capacity tool
+func GetSubnetCapacityHandler(client *azureclient.AzureClient, cfg *config.ConfigData) tools.ResourceHandler {
+	return tools.ResourceHandlerFunc(func(params map[string]interface{}, _ *config.ConfigData) (string, error) {
+		return HandleSubnetCapacity(params, client)
+	})
+}
+
+// HandleSubnetCapacity computes the subnet IP usage and the safe scale of each node pool of a cluster
+func HandleSubnetCapacity(params map[string]interface{}, client *azureclient.AzureClient) (string, error) {
+	subID, rg, clusterName, err := common.ExtractAKSParameters(params)
+	if err != nil {
+		return "", err
+	}
+	if client == nil {
+		return "", fmt.Errorf("azure client is required but not provided")
+	}
+
+	ctx := context.Background()
+	cluster, err := common.GetClusterDetails(ctx, client, subID, rg, clusterName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get cluster details: %v", err)
+	}
+	if cluster.Properties == nil || len(cluster.Properties.AgentPoolProfiles) == 0 {
+		return "", fmt.Errorf("cluster has no node pools")
+	}
+
+	input := CapacityInput{
+		ClusterName:   clusterName,
+		NetworkPlugin: string(armcontainerservice.NetworkPluginKubenet),
+		NodePools:     cluster.Properties.AgentPoolProfiles,
+		NodeSubnetIDs: map[string]string{},
+		Subnets:       map[string]*armnetwork.Subnet{},
+	}
+	var notes []string
+	if profile := cluster.Properties.NetworkProfile; profile != nil {
+		if profile.NetworkPlugin != nil {
+			input.NetworkPlugin = string(*profile.NetworkPlugin)
+		}
+		// Azure CNI only has a pod CIDR in overlay mode
+		if input.NetworkPlugin == string(armcontainerservice.NetworkPluginAzure) && (derefString(profile.PodCidr) != "" || len(profile.PodCidrs) > 0) {
+			input.Overlay = true
+			notes = append(notes, "Azure CNI overlay was inferred from the cluster pod CIDR.")
+		}
+	}
+
+	for _, pool := range input.NodePools {
+		if pool == nil || pool.Name == nil {
+			continue
+		}
+		subnetID, subnet, err := getNodePoolSubnet(ctx, client, cluster, pool)
+		if err != nil {
+			return "", fmt.Errorf("node pool %s: %v", *pool.Name, err)
+		}
+		input.NodeSubnetIDs[*pool.Name] = subnetID
+		input.Subnets[strings.ToLower(subnetID)] = subnet
+		if podSubnetID := derefString(pool.PodSubnetID); podSubnetID != "" {
+			if _, ok := input.Subnets[strings.ToLower(podSubnetID)]; !ok {
+				podSubnet, err := getSubnet(ctx, client, podSubnetID)
+				if err != nil {
+					return "", fmt.Errorf("node pool %s pod subnet: %v", *pool.Name, err)
+				}
+				input.Subnets[strings.ToLower(podSubnetID)] = podSubnet
+			}
+		}
+	}
+
+	report := AnalyzeSubnetCapacity(input)
+	report.Notes = append(notes, report.Notes...)
+
+	resultJSON, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal subnet capacity report to JSON: %v", err)
+	}
+	return string(resultJSON), nil
+}
@@ -0,0 +1,187 @@
+package network
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
+)
+
+const (
+	testNodeSubnetID = "/subscriptions/sub-1/resourceGroups/rg-1/providers/Microsoft.Network/virtualNetworks/vnet-1/subnets/nodes"
+	testPodSubnetID  = "/subscriptions/sub-1/resourceGroups/rg-1/providers/Microsoft.Network/virtualNetworks/vnet-1/subnets/pods"
+)
+
+func testSubnet(prefix string, used int) *armnetwork.Subnet {
+	subnet := &armnetwork.Subnet{Properties: &armnetwork.SubnetPropertiesFormat{AddressPrefix: to.Ptr(prefix)}}
+	for i := 0; i < used; i++ {
+		subnet.Properties.IPConfigurations = append(subnet.Properties.IPConfigurations, &armnetwork.IPConfiguration{ID: to.Ptr(fmt.Sprintf("ipconfig-%d", i))})
+	}
+	return subnet
+}
+
+func testAgentPool(name string, count, maxCount, maxPods int32, maxSurge string) *armcontainerservice.ManagedClusterAgentPoolProfile {
+	pool := &armcontainerservice.ManagedClusterAgentPoolProfile{
+		Name:    to.Ptr(name),
+		Count:   to.Ptr(count),
+		MaxPods: to.Ptr(maxPods),
+	}
+	if maxCount > 0 {
+		pool.EnableAutoScaling = to.Ptr(true)
+		pool.MaxCount = to.Ptr(maxCount)
+	}
+	if maxSurge != "" {
+		pool.UpgradeSettings = &armcontainerservice.AgentPoolUpgradeSettings{MaxSurge: to.Ptr(maxSurge)}
+	}
+	return pool
+}
+
+func testCapacityInput(plugin string, overlay bool, subnets map[string]*armnetwork.Subnet, pools ...*armcontainerservice.ManagedClusterAgentPoolProfile) CapacityInput {
+	input := CapacityInput{
+		ClusterName:   "cluster-1",
+		NetworkPlugin: plugin,
+		Overlay:       overlay,
+		NodePools:     pools,
+		NodeSubnetIDs: map[string]string{},
+		Subnets:       map[string]*armnetwork.Subnet{},
+	}
+	for _, pool := range pools {
+		input.NodeSubnetIDs[*pool.Name] = testNodeSubnetID
+	}
+	for id, subnet := range subnets {
+		input.Subnets[strings.ToLower(id)] = subnet
+	}
+	return input
+}
+
+func TestSurgeNodes(t *testing.T) {
+	tests := []struct {
+		maxSurge string
+		count    int
+		want     int
+		wantErr  bool
+	}{
+		{"", 10, 1, false},
+		{"3", 10, 3, false},
+		{"33%", 10, 4, false},
+		{"100%", 7, 7, false},
+		{"many", 10, 0, true},
+	}
+	for _, tt := range tests {
+		got, err := surgeNodes(tt.maxSurge, tt.count)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("surgeNodes(%q, %d) = %d, %v; want %d", tt.maxSurge, tt.count, got, err, tt.want)
+		}
+	}
+}
+
+func TestAnalyzeSubnetCapacity_PluginModes(t *testing.T) {
+	// A /24 has 251 usable IPs; 3 nodes with maxPods=30 use 93 of them with Azure CNI
+	tests := []struct {
+		name     string
+		plugin   string
+		overlay  bool
+		used     int
+		mode     string
+		perNode  int
+		maxNodes int
+	}{
+		{"azure cni", "azure", false, 93, PluginModeAzureCNI, 31, 8},
+		{"overlay", "azure", true, 3, PluginModeCNIOverlay, 1, 251},
+		{"kubenet", "kubenet", false, 3, PluginModeKubenet, 1, 251},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subnets := map[string]*armnetwork.Subnet{testNodeSubnetID: testSubnet("10.224.0.0/24", tt.used)}
+			report := AnalyzeSubnetCapacity(testCapacityInput(tt.plugin, tt.overlay, subnets, testAgentPool("nodepool1", 3, 0, 30, "")))
+			pool := report.NodePools[0]
+			if pool.PluginMode != tt.mode || pool.NodeIPsPerNode != tt.perNode || pool.MaxNodes != tt.maxNodes || pool.MaxSafeNodes != tt.maxNodes-1 {
+				t.Errorf("Unexpected capacity: %+v", pool)
+			}
+			if report.Subnets[0].UsableIPs != 251 || report.Subnets[0].FreeIPs != 251-tt.used {
+				t.Errorf("Unexpected subnet usage: %+v", report.Subnets[0])
+			}
+			if len(report.Findings) != 0 {
+				t.Errorf("Expected no findings, got %v", findingChecks(report.Findings))
+			}
+			if pool.SharedSubnet || len(report.Notes) != 0 {
+				t.Errorf("Expected a single pool not to share its subnet, got %+v with notes %v", pool, report.Notes)
+			}
+		})
+	}
+}
+
+func TestAnalyzeSubnetCapacity_ScaleAndSurge(t *testing.T) {
+	subnets := map[string]*armnetwork.Subnet{testNodeSubnetID: testSubnet("10.224.0.0/24", 93)}
+
+	// 158 free IPs fit 5 more nodes of 31 IPs, so at most 8 nodes
+	report := AnalyzeSubnetCapacity(testCapacityInput("azure", false, subnets, testAgentPool("nodepool1", 3, 10, 30, "")))
+	if checks := findingChecks(report.Findings); len(checks) != 1 || checks[0] != "scale_exceeds_subnet" {
+		t.Errorf("Expected scale_exceeds_subnet, got %v", checks)
+	}
+
+	report = AnalyzeSubnetCapacity(testCapacityInput("azure", false, subnets, testAgentPool("nodepool1", 3, 7, 30, "2")))
+	if checks := findingChecks(report.Findings); len(checks) != 1 || checks[0] != "upgrade_surge_exceeds_subnet" || report.Findings[0].Severity != SeverityMedium {
+		t.Errorf("Expected a medium upgrade_surge_exceeds_subnet, got %+v", report.Findings)
+	}
+	if pool := report.NodePools[0]; pool.MaxSafeNodes != 6 || pool.UpgradeHeadroom != -1 {
+		t.Errorf("Unexpected capacity: %+v", pool)
+	}
+
+	// A percentage surge shrinks with the node count: 5 nodes surge 3 and fit in 8, while 7 nodes surge 4
+	report = AnalyzeSubnetCapacity(testCapacityInput("azure", false, subnets, testAgentPool("nodepool1", 3, 7, 30, "50%")))
+	if pool := report.NodePools[0]; pool.SurgeNodes != 4 || pool.MaxSafeNodes != 5 || pool.UpgradeHeadroom != -3 {
+		t.Errorf("Unexpected capacity: %+v", pool)
+	}
+	if len(report.Findings) != 1 || report.Findings[0].Severity != SeverityMedium || !strings.Contains(report.Findings[0].Message, "at or below 5 nodes") {
+		t.Errorf("Expected a medium upgrade_surge_exceeds_subnet for 5 safe nodes, got %+v", report.Findings)
+	}
+
+	full := map[string]*armnetwork.Subnet{testNodeSubnetID: testSubnet("10.224.0.0/24", 240)}
+	report = AnalyzeSubnetCapacity(testCapacityInput("azure", false, full, testAgentPool("nodepool1", 3, 0, 30, "")))
+	checks := findingChecks(report.Findings)
+	if len(checks) != 2 || checks[0] != "upgrade_surge_exceeds_subnet" || report.Findings[0].Severity != SeverityHigh || checks[1] != "subnet_usage_high" {
+		t.Errorf("Expected a high upgrade_surge_exceeds_subnet and subnet_usage_high, got %+v", report.Findings)
+	}
+}
+
+func TestAnalyzeSubnetCapacity_PodSubnetAndSharedSubnet(t *testing.T) {
+	subnets := map[string]*armnetwork.Subnet{
+		testNodeSubnetID: testSubnet("10.224.0.0/24", 6),
+		testPodSubnetID:  testSubnet("10.225.0.0/24", 60),
+	}
+	system := testAgentPool("system", 3, 0, 30, "")
+	system.PodSubnetID = to.Ptr(testPodSubnetID)
+	user := testAgentPool("user", 3, 8, 30, "")
+	user.PodSubnetID = to.Ptr(testPodSubnetID)
+
+	report := AnalyzeSubnetCapacity(testCapacityInput("azure", false, subnets, system, user))
+	if len(report.Subnets) != 2 || report.Subnets[1].Role != "pod" || len(report.Subnets[1].NodePools) != 2 {
+		t.Fatalf("Unexpected subnets: %+v", report.Subnets)
+	}
+	// 191 free pod IPs fit 6 more nodes of 30 pod IPs
+	if pool := report.NodePools[1]; pool.PluginMode != PluginModeCNIPodSubnet || pool.LimitedBy != "pod subnet" || pool.MaxNodes != 9 {
+		t.Errorf("Unexpected capacity: %+v", pool)
+	}
+	// Scaling user to 8 nodes and surging 1 needs 180 pod IPs of 191 free, so the pod subnet fits
+	if len(report.Findings) != 0 {
+		t.Errorf("Expected no findings, got %v", findingChecks(report.Findings))
+	}
+	// Each pool counts all free IPs of the shared subnets, so its figures are upper bounds
+	if !report.NodePools[0].SharedSubnet || !report.NodePools[1].SharedSubnet {
+		t.Errorf("Expected both pools to be marked as sharing a subnet: %+v", report.NodePools)
+	}
+	if len(report.Notes) != 2 || !strings.Contains(report.Notes[1], "share pod subnet pods") || !strings.Contains(report.Notes[1], "upper bounds") {
+		t.Errorf("Expected notes for the shared node and pod subnets, got %v", report.Notes)
+	}
+
+	system.EnableAutoScaling = to.Ptr(true)
+	system.MaxCount = to.Ptr(int32(5))
+	report = AnalyzeSubnetCapacity(testCapacityInput("azure", false, subnets, system, user))
+	if checks := findingChecks(report.Findings); len(checks) != 1 || checks[0] != "shared_subnet_capacity" {
+		t.Errorf("Expected shared_subnet_capacity, got %v", checks)
+	}
+}
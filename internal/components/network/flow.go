@@ -0,0 +1,766 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/components/compute"
+	"github.com/Azure/aks-mcp/internal/components/network/resourcehelpers"
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/tools"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
+)
+
+// Verdicts of a flow check
+const (
+	VerdictAllow = "Allow"
+	VerdictDeny  = "Deny"
+)
+
+// NSG layers a flow passes through on an AKS node
+const (
+	LayerSubnet = "subnet"
+	LayerNIC    = "nic"
+)
+
+// azureLoadBalancerAddress is the host address the AzureLoadBalancer service tag stands for
+var azureLoadBalancerAddress = netip.MustParseAddr("168.63.129.16")
+
+// sharedAddressSpace is the carrier-grade NAT range, which the Internet service tag does not cover
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// Flow describes the traffic to check. A zero SourcePort means the source port is not known.
+type Flow struct {
+	SourceIP        netip.Addr `json:"source_ip"`
+	DestinationIP   netip.Addr `json:"destination_ip"`
+	SourcePort      int        `json:"source_port,omitempty"`
+	DestinationPort int        `json:"destination_port,omitempty"`
+	Protocol        string     `json:"protocol"`
+	Direction       string     `json:"direction"`
+}
+
+// FlowEnvironment holds the data used to resolve service tags and application security groups
+type FlowEnvironment struct {
+	// VNetPrefixes are the address spaces of the cluster VNet and its peerings
+	VNetPrefixes []netip.Prefix
+	// NodeASGs are the application security groups of the node NIC, keyed by lower-cased resource ID
+	NodeASGs map[string]bool
+	// NodeASGsKnown reports whether the node NIC configuration could be read
+	NodeASGsKnown bool
+}
+
+// RuleSummary identifies a security rule in a flow check result
+type RuleSummary struct {
+	Name             string   `json:"name"`
+	Priority         int32    `json:"priority"`
+	Access           string   `json:"access"`
+	Default          bool     `json:"default,omitempty"`
+	Protocol         string   `json:"protocol,omitempty"`
+	Sources          []string `json:"sources,omitempty"`
+	Destinations     []string `json:"destinations,omitempty"`
+	DestinationPorts []string `json:"destination_ports,omitempty"`
+	Reason           string   `json:"reason,omitempty"`
+}
+
+// NSGEvaluation is the result of evaluating one NSG against a flow
+type NSGEvaluation struct {
+	Layer           string        `json:"layer"`
+	NSGID           string        `json:"nsg_id,omitempty"`
+	Verdict         string        `json:"verdict"`
+	Conclusive      bool          `json:"conclusive"`
+	MatchedRule     *RuleSummary  `json:"matched_rule,omitempty"`
+	UnresolvedRules []RuleSummary `json:"unresolved_rules,omitempty"`
+	Note            string        `json:"note,omitempty"`
+}
+
+// FlowCheckResult is the verdict for a flow across the NSGs of a node pool
+type FlowCheckResult struct {
+	ClusterName string          `json:"cluster_name"`
+	NodePool    string          `json:"node_pool"`
+	SubnetID    string          `json:"subnet_id,omitempty"`
+	Flow        Flow            `json:"flow"`
+	Verdict     string          `json:"verdict"`
+	Conclusive  bool            `json:"conclusive"`
+	Evaluations []NSGEvaluation `json:"evaluations"`
+	Notes       []string        `json:"notes,omitempty"`
+}
+
+// matchResult is the outcome of matching a flow against part of a rule
+type matchResult int
+
+const (
+	noMatch matchResult = iota
+	match
+	unknownMatch
+)
+
+// defaultSecurityRules returns the rules Azure adds to every NSG, used when the NSG does not report them
+func defaultSecurityRules() []*armnetwork.SecurityRule {
+	rule := func(name string, priority int32, direction armnetwork.SecurityRuleDirection, access armnetwork.SecurityRuleAccess, source, destination string) *armnetwork.SecurityRule {
+		return &armnetwork.SecurityRule{
+			Name: to.Ptr(name),
+			Properties: &armnetwork.SecurityRulePropertiesFormat{
+				Priority:                 to.Ptr(priority),
+				Direction:                to.Ptr(direction),
+				Access:                   to.Ptr(access),
+				Protocol:                 to.Ptr(armnetwork.SecurityRuleProtocolAsterisk),
+				SourceAddressPrefix:      to.Ptr(source),
+				SourcePortRange:          to.Ptr("*"),
+				DestinationAddressPrefix: to.Ptr(destination),
+				DestinationPortRange:     to.Ptr("*"),
+			},
+		}
+	}
+	in, out := armnetwork.SecurityRuleDirectionInbound, armnetwork.SecurityRuleDirectionOutbound
+	allow, deny := armnetwork.SecurityRuleAccessAllow, armnetwork.SecurityRuleAccessDeny
+	return []*armnetwork.SecurityRule{
+		rule("AllowVnetInBound", 65000, in, allow, "VirtualNetwork", "VirtualNetwork"),
+		rule("AllowAzureLoadBalancerInBound", 65001, in, allow, "AzureLoadBalancer", "*"),
+		rule("DenyAllInBound", 65500, in, deny, "*", "*"),
+		rule("AllowVnetOutBound", 65000, out, allow, "VirtualNetwork", "VirtualNetwork"),
+		rule("AllowInternetOutBound", 65001, out, allow, "*", "Internet"),
+		rule("DenyAllOutBound", 65500, out, deny, "*", "*"),
+	}
+}
+
+// EvaluateNSG evaluates the rules of an NSG, default rules included, in priority order.
+// Rules that may match but reference data that cannot be resolved are reported and skipped;
+// the result is conclusive when none of them could change the verdict.
+func EvaluateNSG(nsg *armnetwork.SecurityGroup, flow Flow, env FlowEnvironment) NSGEvaluation {
+	evaluation := NSGEvaluation{NSGID: derefString(nsg.ID)}
+
+	type candidate struct {
+		rule      *armnetwork.SecurityRule
+		isDefault bool
+	}
+	var candidates []candidate
+	var customRules, defaultRules []*armnetwork.SecurityRule
+	if nsg.Properties != nil {
+		customRules = nsg.Properties.SecurityRules
+		defaultRules = nsg.Properties.DefaultSecurityRules
+	}
+	if len(defaultRules) == 0 {
+		defaultRules = defaultSecurityRules()
+	}
+	for _, rule := range customRules {
+		candidates = append(candidates, candidate{rule: rule})
+	}
+	for _, rule := range defaultRules {
+		candidates = append(candidates, candidate{rule: rule, isDefault: true})
+	}
+	candidates = slices.DeleteFunc(candidates, func(c candidate) bool {
+		if c.rule == nil {
+			return true
+		}
+		props := c.rule.Properties
+		return props == nil || props.Direction == nil || !strings.EqualFold(string(*props.Direction), flow.Direction)
+	})
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		return int(derefInt32(a.rule.Properties.Priority)) - int(derefInt32(b.rule.Properties.Priority))
+	})
+
+	for _, c := range candidates {
+		result, reason := matchRule(c.rule.Properties, flow, env)
+		if result == noMatch {
+			continue
+		}
+		summary := summarizeRule(c.rule, c.isDefault)
+		if result == unknownMatch {
+			summary.Reason = reason
+			evaluation.UnresolvedRules = append(evaluation.UnresolvedRules, summary)
+			continue
+		}
+		evaluation.MatchedRule = &summary
+		evaluation.Verdict = summary.Access
+		break
+	}
+
+	if evaluation.MatchedRule == nil {
+		// Unreachable with the default rules in place, but keep the Azure behavior explicit
+		evaluation.Verdict = VerdictDeny
+	}
+	evaluation.Conclusive = true
+	for _, rule := range evaluation.UnresolvedRules {
+		if rule.Access != evaluation.Verdict {
+			evaluation.Conclusive = false
+		}
+	}
+	return evaluation
+}
+
+// CheckFlow evaluates a flow against the subnet and NIC NSGs of a node in the order Azure applies them:
+// subnet then NIC for inbound traffic, NIC then subnet for outbound traffic. A nil NSG does not filter.
+func CheckFlow(flow Flow, env FlowEnvironment, subnetNSG, nicNSG *armnetwork.SecurityGroup) (verdict string, conclusive bool, evaluations []NSGEvaluation) {
+	layers := []struct {
+		name string
+		nsg  *armnetwork.SecurityGroup
+	}{{LayerSubnet, subnetNSG}, {LayerNIC, nicNSG}}
+	if strings.EqualFold(flow.Direction, string(armnetwork.SecurityRuleDirectionOutbound)) {
+		slices.Reverse(layers)
+	}
+
+	verdict, conclusive = VerdictAllow, true
+	denied := false
+	for _, layer := range layers {
+		var evaluation NSGEvaluation
+		if layer.nsg == nil {
+			evaluation = NSGEvaluation{Verdict: VerdictAllow, Conclusive: true, Note: "No NSG is associated at this layer, so it does not filter traffic"}
+		} else {
+			evaluation = EvaluateNSG(layer.nsg, flow, env)
+		}
+		evaluation.Layer = layer.name
+		evaluations = append(evaluations, evaluation)
+
+		if denied {
+			evaluation.Note = "Not reached: the traffic is denied by an earlier layer"
+			evaluations[len(evaluations)-1] = evaluation
+			continue
+		}
+		if !evaluation.Conclusive {
+			conclusive = false
+		}
+		if evaluation.Verdict == VerdictDeny {
+			verdict, denied = VerdictDeny, true
+			// A conclusive deny settles the flow whatever the earlier layers were unsure about
+			if evaluation.Conclusive {
+				conclusive = true
+			}
+		}
+	}
+	return verdict, conclusive, evaluations
+}
+
+// matchRule matches a flow against a rule and explains an unknown result
+func matchRule(props *armnetwork.SecurityRulePropertiesFormat, flow Flow, env FlowEnvironment) (matchResult, string) {
+	protocol := "*"
+	if props.Protocol != nil {
+		protocol = string(*props.Protocol)
+	}
+	if protocol != "*" && !strings.EqualFold(protocol, flow.Protocol) {
+		return noMatch, ""
+	}
+
+	inbound := strings.EqualFold(flow.Direction, string(armnetwork.SecurityRuleDirectionInbound))
+	checks := []func() (matchResult, string){
+		func() (matchResult, string) {
+			return matchAddresses(rulePrefixes(props.SourceAddressPrefix, props.SourceAddressPrefixes), props.SourceApplicationSecurityGroups, flow.SourceIP, !inbound, env)
+		},
+		func() (matchResult, string) {
+			return matchAddresses(rulePrefixes(props.DestinationAddressPrefix, props.DestinationAddressPrefixes), props.DestinationApplicationSecurityGroups, flow.DestinationIP, inbound, env)
+		},
+	}
+	// Ports do not apply to ICMP
+	if !strings.EqualFold(flow.Protocol, string(armnetwork.SecurityRuleProtocolIcmp)) {
+		checks = append(checks,
+			func() (matchResult, string) {
+				return matchPorts(rulePrefixes(props.SourcePortRange, props.SourcePortRanges), flow.SourcePort, "source")
+			},
+			func() (matchResult, string) {
+				return matchPorts(rulePrefixes(props.DestinationPortRange, props.DestinationPortRanges), flow.DestinationPort, "destination")
+			},
+		)
+	}
+
+	result, reasons := match, []string{}
+	for _, check := range checks {
+		r, reason := check()
+		if r == noMatch {
+			return noMatch, ""
+		}
+		if r == unknownMatch {
+			result = unknownMatch
+			reasons = append(reasons, reason)
+		}
+	}
+	return result, strings.Join(reasons, "; ")
+}
+
+// matchAddresses matches an address against the prefixes, service tags and ASGs of one side of a rule.
+// isNode reports whether the address belongs to the node whose NIC ASGs are known.
+func matchAddresses(prefixes []string, asgs []*armnetwork.ApplicationSecurityGroup, addr netip.Addr, isNode bool, env FlowEnvironment) (matchResult, string) {
+	result, reasons := noMatch, []string{}
+	for _, prefix := range prefixes {
+		r, reason := matchAddressPrefix(prefix, addr, env)
+		if r == match {
+			return match, ""
+		}
+		if r == unknownMatch {
+			result = unknownMatch
+			reasons = append(reasons, reason)
+		}
+	}
+	for _, asg := range asgs {
+		if asg == nil || asg.ID == nil {
+			continue
+		}
+		name := resourceName(*asg.ID)
+		switch {
+		case isNode && env.NodeASGsKnown && env.NodeASGs[strings.ToLower(*asg.ID)]:
+			return match, ""
+		case isNode && env.NodeASGsKnown:
+			// The node NIC is not a member of this ASG
+		case isNode:
+			result = unknownMatch
+			reasons = append(reasons, fmt.Sprintf("the node NIC configuration is not available to resolve ASG %s", name))
+		default:
+			result = unknownMatch
+			reasons = append(reasons, fmt.Sprintf("membership of %s in ASG %s cannot be resolved", addr, name))
+		}
+	}
+	return result, strings.Join(reasons, "; ")
+}
+
+// matchAddressPrefix matches an address against a CIDR, an IP address or a service tag
+func matchAddressPrefix(prefix string, addr netip.Addr, env FlowEnvironment) (matchResult, string) {
+	prefix = strings.TrimSpace(prefix)
+	switch strings.ToLower(prefix) {
+	case "*", "any", "0.0.0.0/0":
+		return match, ""
+	case "virtualnetwork":
+		if len(env.VNetPrefixes) == 0 {
+			return unknownMatch, "the VirtualNetwork service tag cannot be resolved without the VNet address space"
+		}
+		if inPrefixes(addr, env.VNetPrefixes) {
+			return match, ""
+		}
+		if !addr.IsPrivate() && !sharedAddressSpace.Contains(addr) {
+			return noMatch, ""
+		}
+		// The tag also covers peered and on-premises address spaces and UDR destinations such as the kubenet pod CIDR
+		return unknownMatch, fmt.Sprintf("%s is outside the VNet address space; the VirtualNetwork service tag may still include it through peering, a gateway or a user-defined route", addr)
+	case "azureloadbalancer":
+		return boolMatch(addr == azureLoadBalancerAddress), ""
+	case "internet":
+		if inPrefixes(addr, env.VNetPrefixes) {
+			return noMatch, ""
+		}
+		return boolMatch(!addr.IsPrivate() && !addr.IsLoopback() && !addr.IsLinkLocalUnicast() && !sharedAddressSpace.Contains(addr)), ""
+	}
+
+	if p, err := netip.ParsePrefix(prefix); err == nil {
+		return boolMatch(p.Contains(addr)), ""
+	}
+	if a, err := netip.ParseAddr(prefix); err == nil {
+		return boolMatch(a == addr), ""
+	}
+	return unknownMatch, fmt.Sprintf("service tag %s is not resolved", prefix)
+}
+
+// matchPorts matches a port against port ranges such as "*", "443" or "30000-32767".
+// A zero port is unknown and only matches "*".
+func matchPorts(ranges []string, port int, side string) (matchResult, string) {
+	for _, r := range ranges {
+		r = strings.TrimSpace(r)
+		if r == "*" {
+			return match, ""
+		}
+		if port == 0 {
+			continue
+		}
+		low, high, found := strings.Cut(r, "-")
+		if !found {
+			high = low
+		}
+		lowPort, errLow := strconv.Atoi(low)
+		highPort, errHigh := strconv.Atoi(high)
+		if errLow == nil && errHigh == nil && port >= lowPort && port <= highPort {
+			return match, ""
+		}
+	}
+	if port == 0 && len(ranges) > 0 {
+		return unknownMatch, fmt.Sprintf("the rule restricts %s ports but the %s port is not specified", side, side)
+	}
+	return noMatch, ""
+}
+
+// rulePrefixes merges the single and plural forms of a rule field
+func rulePrefixes(single *string, plural []*string) []string {
+	var values []string
+	if single != nil && *single != "" {
+		values = append(values, *single)
+	}
+	for _, value := range plural {
+		if value != nil && *value != "" {
+			values = append(values, *value)
+		}
+	}
+	return values
+}
+
+func summarizeRule(rule *armnetwork.SecurityRule, isDefault bool) RuleSummary {
+	props := rule.Properties
+	summary := RuleSummary{
+		Name:             derefString(rule.Name),
+		Priority:         derefInt32(props.Priority),
+		Default:          isDefault,
+		Sources:          rulePrefixes(props.SourceAddressPrefix, props.SourceAddressPrefixes),
+		Destinations:     rulePrefixes(props.DestinationAddressPrefix, props.DestinationAddressPrefixes),
+		DestinationPorts: rulePrefixes(props.DestinationPortRange, props.DestinationPortRanges),
+	}
+	if props.Access != nil {
+		summary.Access = string(*props.Access)
+	}
+	if props.Protocol != nil {
+		summary.Protocol = string(*props.Protocol)
+	}
+	for _, asg := range props.SourceApplicationSecurityGroups {
+		if asg != nil && asg.ID != nil {
+			summary.Sources = append(summary.Sources, "asg:"+resourceName(*asg.ID))
+		}
+	}
+	for _, asg := range props.DestinationApplicationSecurityGroups {
+		if asg != nil && asg.ID != nil {
+			summary.Destinations = append(summary.Destinations, "asg:"+resourceName(*asg.ID))
+		}
+	}
+	return summary
+}
+
+func boolMatch(matched bool) matchResult {
+	if matched {
+		return match
+	}
+	return noMatch
+}
+
+func inPrefixes(addr netip.Addr, prefixes []netip.Prefix) bool {
+	return slices.ContainsFunc(prefixes, func(p netip.Prefix) bool { return p.Contains(addr) })
+}
+
+// parsePrefixes parses address prefixes, skipping invalid entries
+func parsePrefixes(values []*string) []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, value := range values {
+		if value == nil {
+			continue
+		}
+		if p, err := netip.ParsePrefix(*value); err == nil {
+			prefixes = append(prefixes, p.Masked())
+		}
+	}
+	return prefixes
+}
+
+func resourceName(id string) string {
+	return id[strings.LastIndex(id, "/")+1:]
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func derefInt32(i *int32) int32 {
+	if i == nil {
+		return 0
+	}
+	return *i
+}
+
+// =============================================================================
+// network_check_flow handler
+// =============================================================================
+
+// GetCheckFlowHandler returns the handler for the network_check_flow tool
+func GetCheckFlowHandler(client *azureclient.AzureClient, cfg *config.ConfigData) tools.ResourceHandler {
+	return tools.ResourceHandlerFunc(func(params map[string]interface{}, _ *config.ConfigData) (string, error) {
+		return HandleCheckFlow(params, client)
+	})
+}
+
+// HandleCheckFlow evaluates a flow against the NSGs of a cluster node pool
+func HandleCheckFlow(params map[string]interface{}, client *azureclient.AzureClient) (string, error) {
+	subID, rg, clusterName, err := common.ExtractAKSParameters(params)
+	if err != nil {
+		return "", err
+	}
+	flow, err := parseFlowParams(params)
+	if err != nil {
+		return "", err
+	}
+	if client == nil {
+		return "", fmt.Errorf("azure client is required but not provided")
+	}
+
+	ctx := context.Background()
+	cluster, err := common.GetClusterDetails(ctx, client, subID, rg, clusterName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get cluster details: %v", err)
+	}
+	poolName, _ := params["node_pool"].(string)
+	pool, err := selectNodePool(cluster, poolName)
+	if err != nil {
+		return "", err
+	}
+
+	result := FlowCheckResult{ClusterName: clusterName, NodePool: derefString(pool.Name), Flow: flow}
+	env := FlowEnvironment{}
+
+	subnetID, subnet, err := getNodePoolSubnet(ctx, client, cluster, pool)
+	if err != nil {
+		return "", err
+	}
+	result.SubnetID = subnetID
+
+	var subnetNSG *armnetwork.SecurityGroup
+	if subnet.Properties != nil && subnet.Properties.NetworkSecurityGroup != nil && subnet.Properties.NetworkSecurityGroup.ID != nil {
+		if subnetNSG, err = getNSG(ctx, client, *subnet.Properties.NetworkSecurityGroup.ID); err != nil {
+			return "", err
+		}
+	}
+
+	if vnet, err := getSubnetVNet(ctx, client, subnetID); err != nil {
+		result.Notes = append(result.Notes, fmt.Sprintf("Could not read the VNet address space, so the VirtualNetwork service tag is unresolved: %v", err))
+	} else {
+		env.VNetPrefixes = vnetPrefixes(vnet)
+	}
+
+	nicNSG, nicNote := nodeNICSecurity(ctx, client, cluster, result.NodePool, &env)
+	if nicNote != "" {
+		result.Notes = append(result.Notes, nicNote)
+	}
+
+	nodeIP := flow.DestinationIP
+	if strings.EqualFold(flow.Direction, string(armnetwork.SecurityRuleDirectionOutbound)) {
+		nodeIP = flow.SourceIP
+	}
+	if subnet.Properties != nil && !inPrefixes(nodeIP, parsePrefixes(append([]*string{subnet.Properties.AddressPrefix}, subnet.Properties.AddressPrefixes...))) {
+		result.Notes = append(result.Notes, fmt.Sprintf("%s is not in the node subnet. NSGs only see node and VNet pod addresses; with kubenet or CNI overlay, pod traffic leaving the node uses the node IP", nodeIP))
+	}
+
+	result.Verdict, result.Conclusive, result.Evaluations = CheckFlow(flow, env, subnetNSG, nicNSG)
+	if !result.Conclusive {
+		result.Notes = append(result.Notes, "The verdict depends on rules whose service tags or ASG membership could not be resolved; see unresolved_rules")
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal flow check result to JSON: %v", err)
+	}
+	return string(resultJSON), nil
+}
+
+// parseFlowParams validates the flow parameters of the network_check_flow tool
+func parseFlowParams(params map[string]interface{}) (Flow, error) {
+	var flow Flow
+	for name, target := range map[string]*netip.Addr{"source_ip": &flow.SourceIP, "destination_ip": &flow.DestinationIP} {
+		value, _ := params[name].(string)
+		addr, err := netip.ParseAddr(strings.TrimSpace(value))
+		if err != nil {
+			return Flow{}, fmt.Errorf("missing or invalid %s parameter: %q is not an IP address", name, value)
+		}
+		*target = addr
+	}
+
+	direction, _ := params["direction"].(string)
+	switch strings.ToLower(direction) {
+	case "inbound":
+		flow.Direction = string(armnetwork.SecurityRuleDirectionInbound)
+	case "outbound":
+		flow.Direction = string(armnetwork.SecurityRuleDirectionOutbound)
+	default:
+		return Flow{}, fmt.Errorf("missing or invalid direction parameter: must be inbound or outbound")
+	}
+
+	protocol, _ := params["protocol"].(string)
+	switch strings.ToLower(protocol) {
+	case "", "tcp":
+		flow.Protocol = string(armnetwork.SecurityRuleProtocolTCP)
+	case "udp":
+		flow.Protocol = string(armnetwork.SecurityRuleProtocolUDP)
+	case "icmp":
+		flow.Protocol = string(armnetwork.SecurityRuleProtocolIcmp)
+	default:
+		return Flow{}, fmt.Errorf("invalid protocol %q: must be tcp, udp or icmp", protocol)
+	}
+
+	ports := map[string]*int{"destination_port": &flow.DestinationPort, "source_port": &flow.SourcePort}
+	for name, target := range ports {
+		value, ok := params[name].(float64)
+		if !ok {
+			continue
+		}
+		if value != float64(int(value)) || value < 1 || value > 65535 {
+			return Flow{}, fmt.Errorf("invalid %s %v: must be between 1 and 65535", name, value)
+		}
+		*target = int(value)
+	}
+	if flow.DestinationPort == 0 && flow.Protocol != string(armnetwork.SecurityRuleProtocolIcmp) {
+		return Flow{}, fmt.Errorf("missing destination_port parameter: required for tcp and udp")
+	}
+	return flow, nil
+}
+
+// selectNodePool returns the named node pool, or the first one when no name is given
+func selectNodePool(cluster *armcontainerservice.ManagedCluster, name string) (*armcontainerservice.ManagedClusterAgentPoolProfile, error) {
+	if cluster.Properties == nil || len(cluster.Properties.AgentPoolProfiles) == 0 {
+		return nil, fmt.Errorf("cluster has no node pools")
+	}
+	var names []string
+	for _, pool := range cluster.Properties.AgentPoolProfiles {
+		if pool == nil || pool.Name == nil {
+			continue
+		}
+		if name == "" || strings.EqualFold(*pool.Name, name) {
+			return pool, nil
+		}
+		names = append(names, *pool.Name)
+	}
+	return nil, fmt.Errorf("node pool %s not found. Node pools: %s", name, strings.Join(names, ", "))
+}
+
+func getNSG(ctx context.Context, client *azureclient.AzureClient, nsgID string) (*armnetwork.SecurityGroup, error) {
+	nsgInterface, err := client.GetResourceByID(ctx, nsgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get NSG details: %v", err)
+	}
+	nsg, ok := nsgInterface.(*armnetwork.SecurityGroup)
+	if !ok {
+		return nil, fmt.Errorf("unexpected resource type returned for NSG")
+	}
+	return nsg, nil
+}
+
+// getSubnetVNet returns the VNet that contains a subnet
+func getSubnetVNet(ctx context.Context, client *azureclient.AzureClient, subnetID string) (*armnetwork.VirtualNetwork, error) {
+	parsed, err := arm.ParseResourceID(subnetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse subnet ID: %v", err)
+	}
+	if parsed.Parent == nil {
+		return nil, fmt.Errorf("could not determine VNet name from subnet ID: %s", subnetID)
+	}
+	return client.GetVirtualNetwork(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Parent.Name)
+}
+
+// getNodePoolSubnet returns the subnet of a node pool, falling back to the cluster subnet
+func getNodePoolSubnet(ctx context.Context, client *azureclient.AzureClient, cluster *armcontainerservice.ManagedCluster, pool *armcontainerservice.ManagedClusterAgentPoolProfile) (string, *armnetwork.Subnet, error) {
+	subnetID := derefString(pool.VnetSubnetID)
+	if subnetID == "" {
+		var err error
+		if subnetID, err = resourcehelpers.GetSubnetIDFromAKS(ctx, cluster, client); err != nil {
+			return "", nil, fmt.Errorf("failed to get Subnet ID: %v", err)
+		}
+	}
+
+	subnet, err := getSubnet(ctx, client, subnetID)
+	if err != nil {
+		return "", nil, err
+	}
+	return subnetID, subnet, nil
+}
+
+func getSubnet(ctx context.Context, client *azureclient.AzureClient, subnetID string) (*armnetwork.Subnet, error) {
+	subnetInterface, err := client.GetResourceByID(ctx, subnetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Subnet details: %v", err)
+	}
+	subnet, ok := subnetInterface.(*armnetwork.Subnet)
+	if !ok {
+		return nil, fmt.Errorf("unexpected resource type returned for Subnet")
+	}
+	return subnet, nil
+}
+
+// vnetPrefixes returns the address space the VirtualNetwork service tag covers: the VNet and its peerings
+func vnetPrefixes(vnet *armnetwork.VirtualNetwork) []netip.Prefix {
+	return append(vnetAddressSpace(vnet), peeredAddressSpace(vnet)...)
+}
+
+// vnetAddressSpace returns the address prefixes of a VNet
+func vnetAddressSpace(vnet *armnetwork.VirtualNetwork) []netip.Prefix {
+	if vnet.Properties == nil || vnet.Properties.AddressSpace == nil {
+		return nil
+	}
+	return parsePrefixes(vnet.Properties.AddressSpace.AddressPrefixes)
+}
+
+// peeredAddressSpace returns the address prefixes of the VNets peered with a VNet
+func peeredAddressSpace(vnet *armnetwork.VirtualNetwork) []netip.Prefix {
+	if vnet.Properties == nil {
+		return nil
+	}
+	var prefixes []netip.Prefix
+	for _, peering := range vnet.Properties.VirtualNetworkPeerings {
+		if peering != nil && peering.Properties != nil && peering.Properties.RemoteAddressSpace != nil {
+			prefixes = append(prefixes, parsePrefixes(peering.Properties.RemoteAddressSpace.AddressPrefixes)...)
+		}
+	}
+	return prefixes
+}
+
+// nodeNICSecurity reads the NIC NSG and ASGs from the node pool's VMSS model and records the ASGs in env.
+// Problems are returned as a note because the subnet NSG can still be evaluated.
+func nodeNICSecurity(ctx context.Context, client *azureclient.AzureClient, cluster *armcontainerservice.ManagedCluster, poolName string, env *FlowEnvironment) (*armnetwork.SecurityGroup, string) {
+	vmssID, err := compute.GetVMSSIDFromNodePool(ctx, cluster, poolName, client)
+	if err != nil {
+		return nil, fmt.Sprintf("Could not find the VMSS of node pool %s, so the NIC NSG and ASGs are not evaluated: %v", poolName, err)
+	}
+	vmssInterface, err := client.GetResourceByID(ctx, vmssID)
+	if err != nil {
+		return nil, fmt.Sprintf("Could not read VMSS %s, so the NIC NSG and ASGs are not evaluated: %v", resourceName(vmssID), err)
+	}
+	vmss, ok := vmssInterface.(*armcompute.VirtualMachineScaleSet)
+	if !ok {
+		return nil, "Unexpected resource type returned for VMSS, so the NIC NSG and ASGs are not evaluated"
+	}
+
+	nicConfig := primaryNICConfiguration(vmss)
+	if nicConfig == nil {
+		return nil, fmt.Sprintf("VMSS %s has no network interface configuration", resourceName(vmssID))
+	}
+	env.NodeASGs = map[string]bool{}
+	env.NodeASGsKnown = true
+	for _, ipConfig := range nicConfig.IPConfigurations {
+		if ipConfig == nil || ipConfig.Properties == nil {
+			continue
+		}
+		for _, asg := range ipConfig.Properties.ApplicationSecurityGroups {
+			if asg != nil && asg.ID != nil {
+				env.NodeASGs[strings.ToLower(*asg.ID)] = true
+			}
+		}
+	}
+
+	if nicConfig.NetworkSecurityGroup == nil || nicConfig.NetworkSecurityGroup.ID == nil {
+		return nil, ""
+	}
+	nsg, err := getNSG(ctx, client, *nicConfig.NetworkSecurityGroup.ID)
+	if err != nil {
+		return nil, fmt.Sprintf("Could not read the NIC NSG, so it is not evaluated: %v", err)
+	}
+	return nsg, ""
+}
+
+// primaryNICConfiguration returns the primary network interface configuration of a VMSS model
+func primaryNICConfiguration(vmss *armcompute.VirtualMachineScaleSet) *armcompute.VirtualMachineScaleSetNetworkConfigurationProperties {
+	if vmss.Properties == nil || vmss.Properties.VirtualMachineProfile == nil || vmss.Properties.VirtualMachineProfile.NetworkProfile == nil {
+		return nil
+	}
+	var first *armcompute.VirtualMachineScaleSetNetworkConfigurationProperties
+	for _, nic := range vmss.Properties.VirtualMachineProfile.NetworkProfile.NetworkInterfaceConfigurations {
+		if nic == nil || nic.Properties == nil {
+			continue
+		}
+		if nic.Properties.Primary != nil && *nic.Properties.Primary {
+			return nic.Properties
+		}
+		if first == nil {
+			first = nic.Properties
+		}
+	}
+	return first
+}
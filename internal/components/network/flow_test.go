@@ -0,0 +1,246 @@
+package network
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
+)
+
+const testASGID = "/subscriptions/sub-1/resourceGroups/rg-1/providers/Microsoft.Network/applicationSecurityGroups/web"
+
+func testSecurityRule(name string, priority int32, direction armnetwork.SecurityRuleDirection, access armnetwork.SecurityRuleAccess, protocol armnetwork.SecurityRuleProtocol, source, destination, ports string) *armnetwork.SecurityRule {
+	return &armnetwork.SecurityRule{
+		Name: to.Ptr(name),
+		Properties: &armnetwork.SecurityRulePropertiesFormat{
+			Priority:                 to.Ptr(priority),
+			Direction:                to.Ptr(direction),
+			Access:                   to.Ptr(access),
+			Protocol:                 to.Ptr(protocol),
+			SourceAddressPrefix:      to.Ptr(source),
+			SourcePortRange:          to.Ptr("*"),
+			DestinationAddressPrefix: to.Ptr(destination),
+			DestinationPortRange:     to.Ptr(ports),
+		},
+	}
+}
+
+func testNSG(rules ...*armnetwork.SecurityRule) *armnetwork.SecurityGroup {
+	return &armnetwork.SecurityGroup{
+		ID:         to.Ptr("/subscriptions/sub-1/resourceGroups/rg-1/providers/Microsoft.Network/networkSecurityGroups/nsg-1"),
+		Properties: &armnetwork.SecurityGroupPropertiesFormat{SecurityRules: rules},
+	}
+}
+
+func testFlow(t *testing.T, source, destination string, port int, direction armnetwork.SecurityRuleDirection) Flow {
+	t.Helper()
+	return Flow{
+		SourceIP:        netip.MustParseAddr(source),
+		DestinationIP:   netip.MustParseAddr(destination),
+		DestinationPort: port,
+		Protocol:        string(armnetwork.SecurityRuleProtocolTCP),
+		Direction:       string(direction),
+	}
+}
+
+var testFlowEnv = FlowEnvironment{
+	VNetPrefixes:  []netip.Prefix{netip.MustParsePrefix("10.224.0.0/12")},
+	NodeASGs:      map[string]bool{},
+	NodeASGsKnown: true,
+}
+
+func TestEvaluateNSG_PriorityOrder(t *testing.T) {
+	in := armnetwork.SecurityRuleDirectionInbound
+	nsg := testNSG(
+		testSecurityRule("allow-https", 200, in, armnetwork.SecurityRuleAccessAllow, armnetwork.SecurityRuleProtocolTCP, "Internet", "*", "443"),
+		testSecurityRule("deny-range", 100, in, armnetwork.SecurityRuleAccessDeny, armnetwork.SecurityRuleProtocolTCP, "203.0.113.0/24", "*", "400-500"),
+	)
+
+	evaluation := EvaluateNSG(nsg, testFlow(t, "203.0.113.10", "10.224.0.4", 443, in), testFlowEnv)
+	if evaluation.Verdict != VerdictDeny || evaluation.MatchedRule == nil || evaluation.MatchedRule.Name != "deny-range" {
+		t.Fatalf("Expected deny-range to win on priority, got %+v", evaluation)
+	}
+
+	evaluation = EvaluateNSG(nsg, testFlow(t, "198.51.100.7", "10.224.0.4", 443, in), testFlowEnv)
+	if evaluation.Verdict != VerdictAllow || evaluation.MatchedRule.Name != "allow-https" || !evaluation.Conclusive {
+		t.Errorf("Expected allow-https, got %+v", evaluation)
+	}
+}
+
+func TestEvaluateNSG_DefaultRules(t *testing.T) {
+	in, out := armnetwork.SecurityRuleDirectionInbound, armnetwork.SecurityRuleDirectionOutbound
+	nsg := testNSG()
+
+	tests := []struct {
+		name   string
+		flow   Flow
+		rule   string
+		access string
+	}{
+		{"vnet inbound", testFlow(t, "10.224.0.5", "10.224.0.4", 22, in), "AllowVnetInBound", VerdictAllow},
+		{"load balancer probe", testFlow(t, "168.63.129.16", "10.224.0.4", 30080, in), "AllowAzureLoadBalancerInBound", VerdictAllow},
+		{"internet inbound", testFlow(t, "203.0.113.10", "10.224.0.4", 22, in), "DenyAllInBound", VerdictDeny},
+		{"internet outbound", testFlow(t, "10.224.0.4", "203.0.113.10", 443, out), "AllowInternetOutBound", VerdictAllow},
+		{"private outbound outside vnet", testFlow(t, "10.224.0.4", "192.168.1.10", 443, out), "DenyAllOutBound", VerdictDeny},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluation := EvaluateNSG(nsg, tt.flow, testFlowEnv)
+			if evaluation.Verdict != tt.access || evaluation.MatchedRule == nil || evaluation.MatchedRule.Name != tt.rule || !evaluation.MatchedRule.Default {
+				t.Errorf("Expected %s (%s), got %+v", tt.rule, tt.access, evaluation)
+			}
+		})
+	}
+}
+
+func TestEvaluateNSG_VirtualNetworkTagOutsideVNet(t *testing.T) {
+	out := armnetwork.SecurityRuleDirectionOutbound
+
+	// 192.168.1.10 may be a peered, on-premises or UDR prefix, so AllowVnetOutBound cannot be ruled out
+	evaluation := EvaluateNSG(testNSG(), testFlow(t, "10.224.0.4", "192.168.1.10", 443, out), testFlowEnv)
+	if evaluation.Conclusive || len(evaluation.UnresolvedRules) != 1 || evaluation.UnresolvedRules[0].Name != "AllowVnetOutBound" ||
+		!strings.Contains(evaluation.UnresolvedRules[0].Reason, "VirtualNetwork service tag") {
+		t.Errorf("Expected AllowVnetOutBound to be unresolved, got %+v", evaluation)
+	}
+
+	evaluation = EvaluateNSG(testNSG(), testFlow(t, "10.224.0.4", "10.224.1.10", 443, out), testFlowEnv)
+	if evaluation.Verdict != VerdictAllow || evaluation.MatchedRule.Name != "AllowVnetOutBound" || !evaluation.Conclusive {
+		t.Errorf("Expected AllowVnetOutBound inside the VNet, got %+v", evaluation)
+	}
+}
+
+func TestEvaluateNSG_UnresolvedServiceTag(t *testing.T) {
+	out := armnetwork.SecurityRuleDirectionOutbound
+	nsg := testNSG(
+		testSecurityRule("deny-storage", 100, out, armnetwork.SecurityRuleAccessDeny, armnetwork.SecurityRuleProtocolAsterisk, "*", "Storage", "*"),
+		testSecurityRule("allow-sql", 110, out, armnetwork.SecurityRuleAccessAllow, armnetwork.SecurityRuleProtocolAsterisk, "*", "Sql", "*"),
+	)
+
+	evaluation := EvaluateNSG(nsg, testFlow(t, "10.224.0.4", "20.60.1.1", 443, out), testFlowEnv)
+	if evaluation.Verdict != VerdictAllow || evaluation.MatchedRule.Name != "AllowInternetOutBound" {
+		t.Fatalf("Expected the default internet rule to match, got %+v", evaluation)
+	}
+	if evaluation.Conclusive {
+		t.Error("Expected an inconclusive verdict while deny-storage is unresolved")
+	}
+	if len(evaluation.UnresolvedRules) != 2 || !strings.Contains(evaluation.UnresolvedRules[0].Reason, "Storage") {
+		t.Errorf("Unexpected unresolved rules: %+v", evaluation.UnresolvedRules)
+	}
+}
+
+func TestEvaluateNSG_ApplicationSecurityGroups(t *testing.T) {
+	in := armnetwork.SecurityRuleDirectionInbound
+	rule := testSecurityRule("allow-web", 100, in, armnetwork.SecurityRuleAccessAllow, armnetwork.SecurityRuleProtocolTCP, "*", "", "80")
+	rule.Properties.DestinationAddressPrefix = nil
+	rule.Properties.DestinationApplicationSecurityGroups = []*armnetwork.ApplicationSecurityGroup{{ID: to.Ptr(testASGID)}}
+	nsg := testNSG(rule)
+	flow := testFlow(t, "203.0.113.10", "10.224.0.4", 80, in)
+
+	member := FlowEnvironment{VNetPrefixes: testFlowEnv.VNetPrefixes, NodeASGs: map[string]bool{strings.ToLower(testASGID): true}, NodeASGsKnown: true}
+	if evaluation := EvaluateNSG(nsg, flow, member); evaluation.Verdict != VerdictAllow || evaluation.MatchedRule.Name != "allow-web" {
+		t.Errorf("Expected allow-web for an ASG member, got %+v", evaluation)
+	}
+	if evaluation := EvaluateNSG(nsg, flow, testFlowEnv); evaluation.Verdict != VerdictDeny || len(evaluation.UnresolvedRules) != 0 {
+		t.Errorf("Expected DenyAllInBound for a non-member, got %+v", evaluation)
+	}
+
+	unknown := FlowEnvironment{VNetPrefixes: testFlowEnv.VNetPrefixes}
+	evaluation := EvaluateNSG(nsg, flow, unknown)
+	if evaluation.Conclusive || len(evaluation.UnresolvedRules) != 1 {
+		t.Errorf("Expected allow-web to be unresolved without the NIC configuration, got %+v", evaluation)
+	}
+}
+
+func TestEvaluateNSG_SourcePortAndPlurals(t *testing.T) {
+	in := armnetwork.SecurityRuleDirectionInbound
+	rule := testSecurityRule("allow-list", 100, in, armnetwork.SecurityRuleAccessAllow, armnetwork.SecurityRuleProtocolTCP, "", "*", "")
+	rule.Properties.SourceAddressPrefix = nil
+	rule.Properties.SourceAddressPrefixes = []*string{to.Ptr("198.51.100.0/24"), to.Ptr("203.0.113.10")}
+	rule.Properties.DestinationPortRange = nil
+	rule.Properties.DestinationPortRanges = []*string{to.Ptr("80"), to.Ptr("8000-8100")}
+	rule.Properties.SourcePortRange = to.Ptr("1024-65535")
+	nsg := testNSG(rule)
+
+	flow := testFlow(t, "203.0.113.10", "10.224.0.4", 8080, in)
+	if evaluation := EvaluateNSG(nsg, flow, testFlowEnv); evaluation.Conclusive || len(evaluation.UnresolvedRules) != 1 {
+		t.Errorf("Expected the source port restriction to be unresolved, got %+v", evaluation)
+	}
+
+	flow.SourcePort = 50000
+	if evaluation := EvaluateNSG(nsg, flow, testFlowEnv); evaluation.Verdict != VerdictAllow || evaluation.MatchedRule.Name != "allow-list" {
+		t.Errorf("Expected allow-list, got %+v", evaluation)
+	}
+
+	flow.DestinationPort = 8200
+	if evaluation := EvaluateNSG(nsg, flow, testFlowEnv); evaluation.Verdict != VerdictDeny {
+		t.Errorf("Expected port 8200 to be denied, got %+v", evaluation)
+	}
+}
+
+func TestCheckFlow_LayerOrder(t *testing.T) {
+	in, out := armnetwork.SecurityRuleDirectionInbound, armnetwork.SecurityRuleDirectionOutbound
+	subnetNSG := testNSG(testSecurityRule("allow-http", 100, in, armnetwork.SecurityRuleAccessAllow, armnetwork.SecurityRuleProtocolTCP, "*", "*", "80"))
+	nicNSG := testNSG(testSecurityRule("deny-egress", 100, out, armnetwork.SecurityRuleAccessDeny, armnetwork.SecurityRuleProtocolAsterisk, "*", "Internet", "*"))
+
+	verdict, conclusive, evaluations := CheckFlow(testFlow(t, "203.0.113.10", "10.224.0.4", 80, in), testFlowEnv, subnetNSG, nil)
+	if verdict != VerdictAllow || !conclusive || len(evaluations) != 2 || evaluations[0].Layer != LayerSubnet || evaluations[1].Note == "" {
+		t.Errorf("Unexpected inbound result: %s %v %+v", verdict, conclusive, evaluations)
+	}
+
+	verdict, conclusive, evaluations = CheckFlow(testFlow(t, "10.224.0.4", "203.0.113.10", 443, out), testFlowEnv, subnetNSG, nicNSG)
+	if verdict != VerdictDeny || !conclusive || evaluations[0].Layer != LayerNIC || evaluations[0].MatchedRule.Name != "deny-egress" {
+		t.Errorf("Unexpected outbound result: %s %v %+v", verdict, conclusive, evaluations)
+	}
+	if !strings.HasPrefix(evaluations[1].Note, "Not reached") {
+		t.Errorf("Expected the subnet NSG to be marked as not reached, got %+v", evaluations[1])
+	}
+}
+
+func TestParseFlowParams(t *testing.T) {
+	valid := map[string]interface{}{
+		"source_ip":        "203.0.113.10",
+		"destination_ip":   "10.224.0.4",
+		"destination_port": float64(443),
+		"direction":        "Inbound",
+	}
+	flow, err := parseFlowParams(valid)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if flow.Protocol != "Tcp" || flow.Direction != "Inbound" || flow.DestinationPort != 443 || flow.SourcePort != 0 {
+		t.Errorf("Unexpected flow: %+v", flow)
+	}
+
+	tests := []struct {
+		name     string
+		override map[string]interface{}
+		want     string
+	}{
+		{"invalid ip", map[string]interface{}{"source_ip": "10.0.0"}, "source_ip"},
+		{"invalid direction", map[string]interface{}{"direction": "both"}, "direction"},
+		{"invalid protocol", map[string]interface{}{"protocol": "sctp"}, "protocol"},
+		{"port out of range", map[string]interface{}{"destination_port": float64(70000)}, "destination_port"},
+		{"missing port", map[string]interface{}{"destination_port": nil}, "destination_port"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := map[string]interface{}{}
+			for k, v := range valid {
+				params[k] = v
+			}
+			for k, v := range tt.override {
+				params[k] = v
+			}
+			if _, err := parseFlowParams(params); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error mentioning %s, got %v", tt.want, err)
+			}
+		})
+	}
+
+	icmp := map[string]interface{}{"source_ip": "10.224.0.4", "destination_ip": "10.1.0.5", "direction": "outbound", "protocol": "icmp"}
+	if _, err := parseFlowParams(icmp); err != nil {
+		t.Errorf("Expected icmp without a port to be valid, got %v", err)
+	}
+}
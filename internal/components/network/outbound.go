@@ -0,0 +1,669 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/azaks"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/components/compute"
+	"github.com/Azure/aks-mcp/internal/components/network/resourcehelpers"
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/tools"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
+)
+
+// SNAT ports available per public IP
+const (
+	loadBalancerPortsPerIP = 64000
+	natGatewayPortsPerIP   = 64512
+)
+
+// mcrHost is the registry every node pulls system images from
+const mcrHost = "mcr.microsoft.com"
+
+// LoadBalancerEgress describes the outbound rule of the cluster load balancer
+type LoadBalancerEgress struct {
+	Name                   string `json:"name"`
+	OutboundRule           string `json:"outbound_rule,omitempty"`
+	FrontendIPs            int    `json:"frontend_ips"`
+	AllocatedOutboundPorts int32  `json:"allocated_outbound_ports"`
+	PortsPerNode           int32  `json:"ports_per_node"`
+	MaxNodes               int    `json:"max_nodes"`
+	IdleTimeoutMinutes     int32  `json:"idle_timeout_minutes,omitempty"`
+}
+
+// NATGatewayEgress describes the NAT gateway of the node subnet
+type NATGatewayEgress struct {
+	ID                 string `json:"id"`
+	PublicIPs          int    `json:"public_ips"`
+	PublicIPPrefixes   int    `json:"public_ip_prefixes"`
+	SNATPorts          int    `json:"snat_ports"`
+	IdleTimeoutMinutes int32  `json:"idle_timeout_minutes,omitempty"`
+	ProvisioningState  string `json:"provisioning_state,omitempty"`
+}
+
+// EgressTarget is a destination the nodes must reach
+type EgressTarget struct {
+	Name    string     `json:"name"`
+	Address netip.Addr `json:"address"`
+	Port    int        `json:"port"`
+}
+
+// EgressCheck is the route and NSG verdict for one egress target
+type EgressCheck struct {
+	EgressTarget
+	Route       *EffectiveRoute `json:"route,omitempty"`
+	NSGVerdict  string          `json:"nsg_verdict"`
+	Conclusive  bool            `json:"conclusive"`
+	Approximate bool            `json:"approximate,omitempty"`
+	Evaluations []NSGEvaluation `json:"evaluations"`
+}
+
+// CheckNetworkResult is the outcome of `az aks check-network outbound`
+type CheckNetworkResult struct {
+	Ran        bool     `json:"ran"`
+	Succeeded  bool     `json:"succeeded"`
+	Conclusive bool     `json:"conclusive"`
+	Failures   []string `json:"failures,omitempty"`
+	Output     string   `json:"output,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// OutboundInput is the egress configuration of a cluster
+type OutboundInput struct {
+	ClusterName  string
+	Routes       RouteInput
+	SubnetPrefix string
+	SubnetNSG    *armnetwork.SecurityGroup
+	NICNSG       *armnetwork.SecurityGroup
+	FlowEnv      FlowEnvironment
+	NodeIP       netip.Addr
+	// NodeIPApproximate is set when NodeIP is derived from the subnet instead of read from a node
+	NodeIPApproximate bool
+	Targets           []EgressTarget
+	NodeCount         int
+	MaxNodeCount      int
+	LoadBalancer      *armnetwork.LoadBalancer
+	NATGateway        *armnetwork.NatGateway
+	CheckNetwork      *CheckNetworkResult
+}
+
+// OutboundReport is the egress path of a cluster and its likely breakpoints
+type OutboundReport struct {
+	ClusterName  string              `json:"cluster_name"`
+	OutboundType string              `json:"outbound_type"`
+	NodeCount    int                 `json:"node_count"`
+	MaxNodeCount int                 `json:"max_node_count"`
+	EgressPath   []string            `json:"egress_path"`
+	LoadBalancer *LoadBalancerEgress `json:"load_balancer,omitempty"`
+	NATGateway   *NATGatewayEgress   `json:"nat_gateway,omitempty"`
+	DefaultRoute *EffectiveRoute     `json:"default_route,omitempty"`
+	EgressChecks []EgressCheck       `json:"egress_checks,omitempty"`
+	CheckNetwork *CheckNetworkResult `json:"check_network,omitempty"`
+	Findings     []Finding           `json:"findings"`
+	Notes        []string            `json:"notes,omitempty"`
+}
+
+// defaultSNATPorts returns the ports Azure Load Balancer gives each backend instance when the
+// outbound rule does not allocate them explicitly
+func defaultSNATPorts(backendInstances int) int32 {
+	switch {
+	case backendInstances <= 50:
+		return 1024
+	case backendInstances <= 100:
+		return 512
+	case backendInstances <= 200:
+		return 256
+	case backendInstances <= 400:
+		return 128
+	case backendInstances <= 800:
+		return 64
+	default:
+		return 32
+	}
+}
+
+// AnalyzeLoadBalancerEgress checks the outbound rule of the cluster load balancer and its SNAT capacity
+func AnalyzeLoadBalancerEgress(lb *armnetwork.LoadBalancer, nodeCount, maxNodeCount int) (*LoadBalancerEgress, []Finding) {
+	egress := &LoadBalancerEgress{Name: derefString(lb.Name)}
+	var rule *armnetwork.OutboundRule
+	if lb.Properties != nil {
+		for _, candidate := range lb.Properties.OutboundRules {
+			if candidate != nil && candidate.Properties != nil {
+				rule = candidate
+				break
+			}
+		}
+	}
+	if rule == nil {
+		return egress, []Finding{{
+			Severity:    SeverityHigh,
+			Check:       "lb_outbound_rule_missing",
+			Message:     fmt.Sprintf("Load balancer %s has no outbound rule, so nodes without a public IP have no outbound connectivity.", egress.Name),
+			Remediation: "Reconcile the cluster (az aks update) to restore the aksOutboundRule, or configure the load balancer profile outbound IPs.",
+		}}
+	}
+
+	egress.OutboundRule = derefString(rule.Name)
+	egress.FrontendIPs = len(rule.Properties.FrontendIPConfigurations)
+	egress.AllocatedOutboundPorts = derefInt32(rule.Properties.AllocatedOutboundPorts)
+	egress.IdleTimeoutMinutes = derefInt32(rule.Properties.IdleTimeoutInMinutes)
+	egress.PortsPerNode = egress.AllocatedOutboundPorts
+	if egress.PortsPerNode == 0 {
+		egress.PortsPerNode = defaultSNATPorts(nodeCount)
+	}
+	egress.MaxNodes = egress.FrontendIPs * loadBalancerPortsPerIP / int(egress.PortsPerNode)
+
+	var findings []Finding
+	switch {
+	case egress.FrontendIPs == 0:
+		findings = append(findings, Finding{
+			Severity: SeverityHigh,
+			Check:    "lb_outbound_ip_missing",
+			Message:  fmt.Sprintf("Outbound rule %s has no frontend IP, so it provides no SNAT ports.", egress.OutboundRule),
+		})
+	case egress.AllocatedOutboundPorts > 0 && nodeCount > egress.MaxNodes:
+		findings = append(findings, Finding{
+			Severity: SeverityHigh,
+			Check:    "snat_capacity_exceeded",
+			Message: fmt.Sprintf("%d nodes need %d ports each, but %d outbound IPs only provide ports for %d nodes. Nodes beyond that get no SNAT ports.",
+				nodeCount, egress.AllocatedOutboundPorts, egress.FrontendIPs, egress.MaxNodes),
+			Remediation: "Add managed outbound IPs or lower allocatedOutboundPorts in the load balancer profile.",
+		})
+	case egress.AllocatedOutboundPorts > 0 && maxNodeCount > egress.MaxNodes:
+		findings = append(findings, Finding{
+			Severity: SeverityMedium,
+			Check:    "snat_capacity_at_scale",
+			Message: fmt.Sprintf("The node pools can scale to %d nodes, but %d outbound IPs with %d ports per node only cover %d nodes, so scale-outs and upgrade surges beyond that fail.",
+				maxNodeCount, egress.FrontendIPs, egress.AllocatedOutboundPorts, egress.MaxNodes),
+			Remediation: "Add managed outbound IPs or lower allocatedOutboundPorts in the load balancer profile.",
+		})
+	case egress.AllocatedOutboundPorts == 0 && maxNodeCount > 50:
+		findings = append(findings, Finding{
+			Severity: SeverityMedium,
+			Check:    "snat_default_allocation",
+			Message: fmt.Sprintf("The outbound rule uses default port allocation, which drops to %d ports per node at %d nodes. Workloads with many outbound connections exhaust SNAT ports.",
+				defaultSNATPorts(maxNodeCount), maxNodeCount),
+			Remediation: "Set allocatedOutboundPorts and enough managed outbound IPs explicitly, or use a NAT gateway.",
+		})
+	}
+	return egress, findings
+}
+
+// AnalyzeNATGatewayEgress checks the NAT gateway of the node subnet
+func AnalyzeNATGatewayEgress(natGateway *armnetwork.NatGateway) (*NATGatewayEgress, []Finding) {
+	egress := &NATGatewayEgress{ID: derefString(natGateway.ID)}
+	if props := natGateway.Properties; props != nil {
+		egress.PublicIPs = len(props.PublicIPAddresses)
+		egress.PublicIPPrefixes = len(props.PublicIPPrefixes)
+		egress.IdleTimeoutMinutes = derefInt32(props.IdleTimeoutInMinutes)
+		if props.ProvisioningState != nil {
+			egress.ProvisioningState = string(*props.ProvisioningState)
+		}
+	}
+	egress.SNATPorts = egress.PublicIPs * natGatewayPortsPerIP
+
+	var findings []Finding
+	if egress.PublicIPs == 0 && egress.PublicIPPrefixes == 0 {
+		findings = append(findings, Finding{
+			Severity:    SeverityHigh,
+			Check:       "nat_gateway_no_public_ip",
+			Message:     fmt.Sprintf("NAT gateway %s has no public IP address or prefix, so it cannot translate outbound traffic.", resourceName(egress.ID)),
+			Remediation: "Associate a public IP address or prefix with the NAT gateway.",
+		})
+	}
+	if egress.ProvisioningState != "" && egress.ProvisioningState != string(armnetwork.ProvisioningStateSucceeded) {
+		findings = append(findings, Finding{
+			Severity: SeverityMedium,
+			Check:    "nat_gateway_not_succeeded",
+			Message:  fmt.Sprintf("NAT gateway %s is in provisioning state %s.", resourceName(egress.ID), egress.ProvisioningState),
+		})
+	}
+	return egress, findings
+}
+
+// BuildOutboundReport combines the egress path for the cluster outbound type with the route and
+// NSG checks of the required egress targets and the result of check-network
+func BuildOutboundReport(input OutboundInput) OutboundReport {
+	report := OutboundReport{
+		ClusterName:  input.ClusterName,
+		OutboundType: input.Routes.OutboundType,
+		NodeCount:    input.NodeCount,
+		MaxNodeCount: input.MaxNodeCount,
+		Findings:     []Finding{},
+	}
+
+	routes, _, _, routeFindings := AnalyzeRoutes(input.Routes)
+	report.Findings = append(report.Findings, routeFindings...)
+	for i := range routes {
+		if routes[i].State == RouteStateActive && routes[i].prefix == defaultRoute {
+			route := routes[i]
+			report.DefaultRoute = &route
+		}
+	}
+
+	report.EgressPath = append(report.EgressPath, fmt.Sprintf("node subnet %s", input.SubnetPrefix))
+	if input.NICNSG != nil {
+		report.EgressPath = append(report.EgressPath, fmt.Sprintf("NIC NSG %s", resourceName(derefString(input.NICNSG.ID))))
+	}
+	if input.SubnetNSG != nil {
+		report.EgressPath = append(report.EgressPath, fmt.Sprintf("subnet NSG %s", resourceName(derefString(input.SubnetNSG.ID))))
+	}
+
+	// A user route for 0.0.0.0/0 to anything but Internet takes traffic away from the load balancer or NAT gateway
+	if route := report.DefaultRoute; route != nil && route.Source == RouteSourceUser && route.NextHopType != string(armnetwork.RouteNextHopTypeInternet) {
+		hop := fmt.Sprintf("route %s (0.0.0.0/0) to %s", route.Name, route.NextHopType)
+		if route.NextHopIP != "" {
+			hop += " " + route.NextHopIP
+		}
+		report.EgressPath = append(report.EgressPath, hop)
+		if route.NextHopType == string(armnetwork.RouteNextHopTypeVirtualAppliance) {
+			report.EgressPath = append(report.EgressPath, "appliance egress (not inspected; it must allow the AKS required FQDNs and ports)")
+		}
+	}
+
+	switch armcontainerservice.OutboundType(input.Routes.OutboundType) {
+	case armcontainerservice.OutboundTypeLoadBalancer:
+		if input.LoadBalancer == nil {
+			report.Findings = append(report.Findings, Finding{
+				Severity: SeverityHigh,
+				Check:    "lb_missing",
+				Message:  "outboundType is loadBalancer but the kubernetes load balancer was not found in the node resource group.",
+			})
+			break
+		}
+		egress, findings := AnalyzeLoadBalancerEgress(input.LoadBalancer, input.NodeCount, input.MaxNodeCount)
+		report.LoadBalancer = egress
+		report.Findings = append(report.Findings, findings...)
+		if report.DefaultRoute == nil || report.DefaultRoute.NextHopType == string(armnetwork.RouteNextHopTypeInternet) {
+			report.EgressPath = append(report.EgressPath, fmt.Sprintf("load balancer %s outbound rule %s with %d frontend IPs", egress.Name, egress.OutboundRule, egress.FrontendIPs), "Internet")
+		}
+	case armcontainerservice.OutboundTypeManagedNATGateway, armcontainerservice.OutboundTypeUserAssignedNATGateway:
+		if input.NATGateway == nil {
+			report.Findings = append(report.Findings, Finding{
+				Severity:    SeverityHigh,
+				Check:       "nat_gateway_missing",
+				Message:     fmt.Sprintf("outboundType is %s but no NAT gateway is associated with the node subnet, so nodes have no outbound connectivity.", input.Routes.OutboundType),
+				Remediation: "Associate the NAT gateway with the node subnet.",
+			})
+			break
+		}
+		egress, findings := AnalyzeNATGatewayEgress(input.NATGateway)
+		report.NATGateway = egress
+		report.Findings = append(report.Findings, findings...)
+		if report.DefaultRoute == nil || report.DefaultRoute.NextHopType == string(armnetwork.RouteNextHopTypeInternet) {
+			report.EgressPath = append(report.EgressPath, fmt.Sprintf("NAT gateway %s with %d public IPs and %d prefixes", resourceName(egress.ID), egress.PublicIPs, egress.PublicIPPrefixes), "Internet")
+		}
+	case armcontainerservice.OutboundTypeUserDefinedRouting:
+		// The route findings above cover the user-defined routing path
+	default:
+		report.Notes = append(report.Notes, fmt.Sprintf("Outbound type %s is not analyzed beyond its routes and NSGs.", input.Routes.OutboundType))
+	}
+
+	if input.NodeIP.IsValid() {
+		for _, target := range input.Targets {
+			report.EgressChecks = append(report.EgressChecks, checkEgressTarget(input, routes, target, &report.Findings))
+		}
+	}
+
+	if result := input.CheckNetwork; result != nil {
+		report.CheckNetwork = result
+		switch {
+		case !result.Ran:
+			report.Notes = append(report.Notes, fmt.Sprintf("az aks check-network outbound did not run: %s", result.Error))
+		case !result.Conclusive:
+			report.Notes = append(report.Notes, "The output of az aks check-network outbound contains no recognizable result; see check_network.output.")
+		case !result.Succeeded:
+			failures := strings.Join(result.Failures, "; ")
+			if failures == "" {
+				failures = "see check_network.output"
+			}
+			report.Findings = append(report.Findings, Finding{
+				Severity:    SeverityHigh,
+				Check:       "check_network_failed",
+				Message:     fmt.Sprintf("az aks check-network outbound reported failures from a node: %s", failures),
+				Remediation: "Allow the failing endpoints on the egress path (NSG, firewall application rules or proxy).",
+			})
+		}
+	}
+	return report
+}
+
+// checkEgressTarget evaluates the route and the NSGs from a node to a required egress target
+func checkEgressTarget(input OutboundInput, routes []EffectiveRoute, target EgressTarget, findings *[]Finding) EgressCheck {
+	check := EgressCheck{EgressTarget: target, Approximate: input.NodeIPApproximate}
+	if lookup := LookupRoute(routes, target.Address); lookup.Route != nil {
+		check.Route = lookup.Route
+	}
+
+	flow := Flow{
+		SourceIP:        input.NodeIP,
+		DestinationIP:   target.Address,
+		DestinationPort: target.Port,
+		Protocol:        string(armnetwork.SecurityRuleProtocolTCP),
+		Direction:       string(armnetwork.SecurityRuleDirectionOutbound),
+	}
+	check.NSGVerdict, check.Conclusive, check.Evaluations = CheckFlow(flow, input.FlowEnv, input.SubnetNSG, input.NICNSG)
+	if check.NSGVerdict == VerdictDeny {
+		var rule string
+		for _, evaluation := range check.Evaluations {
+			if evaluation.Verdict == VerdictDeny && evaluation.MatchedRule != nil {
+				rule = fmt.Sprintf("rule %s of the %s NSG", evaluation.MatchedRule.Name, evaluation.Layer)
+				break
+			}
+		}
+		message := fmt.Sprintf("Traffic from the nodes to %s (%s:%d) is denied by %s.", target.Name, target.Address, target.Port, rule)
+		if input.NodeIPApproximate {
+			message += fmt.Sprintf(" The source %s is an assumed node address, not one read from a node.", input.NodeIP)
+		}
+		*findings = append(*findings, Finding{
+			Severity:    SeverityHigh,
+			Check:       "nsg_blocks_egress",
+			Message:     message,
+			Remediation: "Allow the traffic with a higher-priority outbound rule; use network_check_flow to verify.",
+		})
+	}
+	return check
+}
+
+// Result words of check-network output lines
+var (
+	checkNetworkFailWords = map[string]bool{"fail": true, "failed": true, "failure": true, "error": true, "unreachable": true, "timeout": true}
+	checkNetworkPassWords = map[string]bool{"ok": true, "pass": true, "passed": true, "success": true, "succeeded": true, "reachable": true}
+)
+
+// parseCheckNetworkOutput classifies check-network output line by line. The overall
+// "connectivity check succeeded/failed" line decides when present; otherwise the result of the
+// "<endpoint>: <result>" lines does. Words like "error" elsewhere in a line are not a failure.
+func parseCheckNetworkOutput(output string) *CheckNetworkResult {
+	result := &CheckNetworkResult{Ran: true, Output: output}
+	var summary string
+	var endpoints int
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		lower := strings.ToLower(line)
+		if strings.HasPrefix(lower, "error:") {
+			// az itself failed, e.g. missing permissions, so the check did not run
+			return &CheckNetworkResult{Output: output, Error: line}
+		}
+		if _, rest, ok := strings.Cut(lower, "connectivity check"); ok {
+			if word := firstWord(rest); checkNetworkFailWords[word] || checkNetworkPassWords[word] {
+				summary = word
+			}
+			continue
+		}
+		colon := strings.LastIndex(lower, ":")
+		if colon <= 0 {
+			continue
+		}
+		switch word := firstWord(lower[colon+1:]); {
+		case checkNetworkFailWords[word]:
+			endpoints++
+			result.Failures = append(result.Failures, line)
+		case checkNetworkPassWords[word]:
+			endpoints++
+		}
+	}
+
+	switch {
+	case summary != "":
+		result.Conclusive = true
+		result.Succeeded = checkNetworkPassWords[summary]
+	case endpoints > 0:
+		result.Conclusive = true
+		result.Succeeded = len(result.Failures) == 0
+	}
+	return result
+}
+
+// firstWord returns the first word of text without trailing punctuation
+func firstWord(text string) string {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.Trim(fields[0], ".!,;")
+}
+
+// =============================================================================
+// outbound_diagnose handler
+// =============================================================================
+
+// checkNetworkRunner runs `az aks check-network outbound` with the given parameters
+type checkNetworkRunner func(params map[string]interface{}) (string, error)
+
+// newCheckNetworkRunner runs check-network through az_aks_operations, which applies the access level and security settings
+func newCheckNetworkRunner(client *azureclient.AzureClient, cfg *config.ConfigData) checkNetworkRunner {
+	executor := azaks.NewAksOperationsExecutor(client)
+	return func(params map[string]interface{}) (string, error) {
+		return executor.Execute(params, cfg)
+	}
+}
+
+// GetOutboundDiagnoseHandler returns the handler for the outbound_diagnose tool
+func GetOutboundDiagnoseHandler(client *azureclient.AzureClient, cfg *config.ConfigData) tools.ResourceHandler {
+	return tools.ResourceHandlerFunc(func(params map[string]interface{}, _ *config.ConfigData) (string, error) {
+		return HandleOutboundDiagnose(params, client, newCheckNetworkRunner(client, cfg))
+	})
+}
+
+// HandleOutboundDiagnose inspects the egress path of a cluster for its outbound type
+func HandleOutboundDiagnose(params map[string]interface{}, client *azureclient.AzureClient, runCheckNetwork checkNetworkRunner) (string, error) {
+	subID, rg, clusterName, err := common.ExtractAKSParameters(params)
+	if err != nil {
+		return "", err
+	}
+	if client == nil {
+		return "", fmt.Errorf("azure client is required but not provided")
+	}
+
+	ctx := context.Background()
+	cluster, err := common.GetClusterDetails(ctx, client, subID, rg, clusterName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get cluster details: %v", err)
+	}
+	poolName, _ := params["node_pool"].(string)
+	pool, err := selectNodePool(cluster, poolName)
+	if err != nil {
+		return "", err
+	}
+	subnetID, subnet, err := getNodePoolSubnet(ctx, client, cluster, pool)
+	if err != nil {
+		return "", err
+	}
+
+	input := OutboundInput{ClusterName: clusterName}
+	var notes []string
+	input.Routes, _, notes, err = collectRouteInput(ctx, client, cluster, subnetID, subnet)
+	if err != nil {
+		return "", err
+	}
+	input.FlowEnv.VNetPrefixes = append(input.Routes.VNetPrefixes, input.Routes.PeeredPrefixes...)
+	input.NodeCount, input.MaxNodeCount = clusterNodeCounts(cluster)
+
+	if subnet.Properties != nil {
+		subnetPrefixes := parsePrefixes(append([]*string{subnet.Properties.AddressPrefix}, subnet.Properties.AddressPrefixes...))
+		if len(subnetPrefixes) > 0 {
+			input.SubnetPrefix = subnetPrefixes[0].String()
+		}
+		if nodeIP, err := nodePrivateIP(ctx, client, cluster, derefString(pool.Name)); err == nil {
+			input.NodeIP = nodeIP
+		} else if len(subnetPrefixes) > 0 {
+			// Azure reserves the first four addresses, so the first node usually gets the fifth
+			input.NodeIP = subnetPrefixes[0].Addr().Next().Next().Next().Next()
+			input.NodeIPApproximate = true
+			notes = append(notes, fmt.Sprintf("Could not read a node IP from the VMSS network interfaces (%v), so the NSG checks are approximate: they use %s, the first address Azure assigns in the node subnet.", err, input.NodeIP))
+		}
+		if subnet.Properties.NetworkSecurityGroup != nil && subnet.Properties.NetworkSecurityGroup.ID != nil {
+			if input.SubnetNSG, err = getNSG(ctx, client, *subnet.Properties.NetworkSecurityGroup.ID); err != nil {
+				return "", err
+			}
+		}
+		if subnet.Properties.NatGateway != nil && subnet.Properties.NatGateway.ID != nil {
+			natInterface, err := client.GetResourceByID(ctx, *subnet.Properties.NatGateway.ID)
+			if err != nil {
+				return "", fmt.Errorf("failed to get NAT gateway details: %v", err)
+			}
+			natGateway, ok := natInterface.(*armnetwork.NatGateway)
+			if !ok {
+				return "", fmt.Errorf("unexpected resource type returned for NAT gateway")
+			}
+			input.NATGateway = natGateway
+		}
+	}
+	var nicNote string
+	if input.NICNSG, nicNote = nodeNICSecurity(ctx, client, cluster, derefString(pool.Name), &input.FlowEnv); nicNote != "" {
+		notes = append(notes, nicNote)
+	}
+
+	if input.Routes.OutboundType == string(armcontainerservice.OutboundTypeLoadBalancer) {
+		lb, err := clusterLoadBalancer(ctx, client, cluster)
+		if err != nil {
+			notes = append(notes, fmt.Sprintf("Could not read the cluster load balancer: %v", err))
+		}
+		input.LoadBalancer = lb
+	}
+
+	if input.Routes.APIServer.IsValid() {
+		input.Targets = append(input.Targets, EgressTarget{Name: "API server", Address: input.Routes.APIServer, Port: 443})
+	}
+	if addrs, err := lookupHost(ctx, mcrHost); err != nil {
+		notes = append(notes, fmt.Sprintf("Could not resolve %s, so image pull egress is not checked: %v", mcrHost, err))
+	} else {
+		for _, value := range addrs {
+			if addr, err := netip.ParseAddr(value); err == nil && addr.Is4() {
+				input.Targets = append(input.Targets, EgressTarget{Name: mcrHost, Address: addr, Port: 443})
+				break
+			}
+		}
+	}
+
+	if include, ok := params["include_check_network"].(bool); !ok || include {
+		input.CheckNetwork = runClusterCheckNetwork(runCheckNetwork, subID, rg, clusterName, params)
+	}
+
+	report := BuildOutboundReport(input)
+	report.Notes = append(notes, report.Notes...)
+
+	resultJSON, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal outbound report to JSON: %v", err)
+	}
+	return string(resultJSON), nil
+}
+
+// runClusterCheckNetwork runs check-network from a node and classifies its output
+func runClusterCheckNetwork(run checkNetworkRunner, subID, rg, clusterName string, params map[string]interface{}) *CheckNetworkResult {
+	checkParams := map[string]interface{}{
+		"operation":       string(azaks.OpClusterCheckNetwork),
+		"subscription_id": subID,
+		"resource_group":  rg,
+		"cluster_name":    clusterName,
+	}
+	if nodeName, _ := params["node_name"].(string); nodeName != "" {
+		checkParams["node_name"] = nodeName
+	}
+	output, err := run(checkParams)
+	if err != nil {
+		return &CheckNetworkResult{Error: err.Error()}
+	}
+	return parseCheckNetworkOutput(output)
+}
+
+// nodePrivateIP returns the private IPv4 address of the primary network interface of a node in the node pool
+func nodePrivateIP(ctx context.Context, client *azureclient.AzureClient, cluster *armcontainerservice.ManagedCluster, poolName string) (netip.Addr, error) {
+	vmssID, err := compute.GetVMSSIDFromNodePool(ctx, cluster, poolName, client)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	id, err := arm.ParseResourceID(vmssID)
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("invalid VMSS ID %s: %v", vmssID, err)
+	}
+	clients, err := client.GetOrCreateClientsForSubscription(id.SubscriptionID)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+
+	pager := clients.InterfacesClient.NewListVirtualMachineScaleSetNetworkInterfacesPager(id.ResourceGroupName, id.Name, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return netip.Addr{}, fmt.Errorf("failed to list network interfaces of VMSS %s: %v", id.Name, err)
+		}
+		if addr, ok := primaryPrivateIP(page.Value); ok {
+			return addr, nil
+		}
+	}
+	return netip.Addr{}, fmt.Errorf("VMSS %s has no network interface with a private IPv4 address", id.Name)
+}
+
+// primaryPrivateIP returns the private IPv4 address of the primary IP configuration of the first primary network interface
+func primaryPrivateIP(nics []*armnetwork.Interface) (netip.Addr, bool) {
+	for _, nic := range nics {
+		if nic == nil || nic.Properties == nil || (nic.Properties.Primary != nil && !*nic.Properties.Primary) {
+			continue
+		}
+		for _, ipConfig := range nic.Properties.IPConfigurations {
+			if ipConfig == nil || ipConfig.Properties == nil || ipConfig.Properties.PrivateIPAddress == nil {
+				continue
+			}
+			if ipConfig.Properties.Primary != nil && !*ipConfig.Properties.Primary {
+				continue
+			}
+			if addr, err := netip.ParseAddr(*ipConfig.Properties.PrivateIPAddress); err == nil && addr.Is4() {
+				return addr, true
+			}
+		}
+	}
+	return netip.Addr{}, false
+}
+
+// clusterNodeCounts returns the current node count and the count the node pools can scale to
+func clusterNodeCounts(cluster *armcontainerservice.ManagedCluster) (current, max int) {
+	if cluster.Properties == nil {
+		return 0, 0
+	}
+	for _, pool := range cluster.Properties.AgentPoolProfiles {
+		if pool == nil {
+			continue
+		}
+		count := int(derefInt32(pool.Count))
+		current += count
+		if pool.EnableAutoScaling != nil && *pool.EnableAutoScaling && pool.MaxCount != nil {
+			count = int(*pool.MaxCount)
+		}
+		max += count
+	}
+	return current, max
+}
+
+// clusterLoadBalancer returns the public load balancer AKS uses for outbound traffic
+func clusterLoadBalancer(ctx context.Context, client *azureclient.AzureClient, cluster *armcontainerservice.ManagedCluster) (*armnetwork.LoadBalancer, error) {
+	lbIDs, err := resourcehelpers.GetLoadBalancerIDsFromAKS(ctx, cluster, client)
+	if err != nil {
+		return nil, err
+	}
+	for _, lbID := range lbIDs {
+		if resourceName(lbID) != "kubernetes" {
+			continue
+		}
+		lbInterface, err := client.GetResourceByID(ctx, lbID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Load Balancer details for %s: %v", lbID, err)
+		}
+		lb, ok := lbInterface.(*armnetwork.LoadBalancer)
+		if !ok {
+			return nil, fmt.Errorf("unexpected resource type returned for Load Balancer %s", lbID)
+		}
+		return lb, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,210 @@
+package network
+
+import (
+	"errors"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
+)
+
+func testLoadBalancer(allocatedPorts int32, frontends int) *armnetwork.LoadBalancer {
+	rule := &armnetwork.OutboundRule{
+		Name: to.Ptr("aksOutboundRule"),
+		Properties: &armnetwork.OutboundRulePropertiesFormat{
+			AllocatedOutboundPorts: to.Ptr(allocatedPorts),
+			IdleTimeoutInMinutes:   to.Ptr(int32(30)),
+		},
+	}
+	for i := 0; i < frontends; i++ {
+		rule.Properties.FrontendIPConfigurations = append(rule.Properties.FrontendIPConfigurations, &armnetwork.SubResource{ID: to.Ptr("frontend")})
+	}
+	return &armnetwork.LoadBalancer{
+		Name:       to.Ptr("kubernetes"),
+		Properties: &armnetwork.LoadBalancerPropertiesFormat{OutboundRules: []*armnetwork.OutboundRule{rule}},
+	}
+}
+
+func TestAnalyzeLoadBalancerEgress(t *testing.T) {
+	tests := []struct {
+		name      string
+		lb        *armnetwork.LoadBalancer
+		nodes     int
+		maxNodes  int
+		wantMax   int
+		wantCheck string
+	}{
+		{"no outbound rule", &armnetwork.LoadBalancer{Name: to.Ptr("kubernetes")}, 3, 3, 0, "lb_outbound_rule_missing"},
+		{"exceeded", testLoadBalancer(8000, 1), 10, 10, 8, "snat_capacity_exceeded"},
+		{"exceeded at scale", testLoadBalancer(8000, 2), 10, 20, 16, "snat_capacity_at_scale"},
+		{"default allocation", testLoadBalancer(0, 1), 10, 100, 62, "snat_default_allocation"},
+		{"enough capacity", testLoadBalancer(4000, 2), 10, 20, 32, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			egress, findings := AnalyzeLoadBalancerEgress(tt.lb, tt.nodes, tt.maxNodes)
+			if egress.MaxNodes != tt.wantMax {
+				t.Errorf("Expected max nodes %d, got %d", tt.wantMax, egress.MaxNodes)
+			}
+			checks := findingChecks(findings)
+			if tt.wantCheck == "" && len(checks) != 0 || tt.wantCheck != "" && (len(checks) != 1 || checks[0] != tt.wantCheck) {
+				t.Errorf("Expected %q, got %v", tt.wantCheck, checks)
+			}
+		})
+	}
+}
+
+func TestAnalyzeNATGatewayEgress(t *testing.T) {
+	natGateway := &armnetwork.NatGateway{
+		ID: to.Ptr("/subscriptions/sub-1/resourceGroups/rg-1/providers/Microsoft.Network/natGateways/nat-1"),
+		Properties: &armnetwork.NatGatewayPropertiesFormat{
+			ProvisioningState: to.Ptr(armnetwork.ProvisioningStateSucceeded),
+		},
+	}
+	_, findings := AnalyzeNATGatewayEgress(natGateway)
+	if checks := findingChecks(findings); len(checks) != 1 || checks[0] != "nat_gateway_no_public_ip" {
+		t.Errorf("Expected nat_gateway_no_public_ip, got %v", checks)
+	}
+
+	natGateway.Properties.PublicIPAddresses = []*armnetwork.SubResource{{ID: to.Ptr("pip-1")}, {ID: to.Ptr("pip-2")}}
+	egress, findings := AnalyzeNATGatewayEgress(natGateway)
+	if len(findings) != 0 || egress.SNATPorts != 2*natGatewayPortsPerIP {
+		t.Errorf("Unexpected NAT gateway egress %+v with findings %v", egress, findingChecks(findings))
+	}
+}
+
+func testOutboundInput(outboundType string, rt *armnetwork.RouteTable) OutboundInput {
+	return OutboundInput{
+		ClusterName:  "cluster-1",
+		Routes:       testRouteInput(outboundType, rt),
+		SubnetPrefix: "10.224.0.0/16",
+		FlowEnv:      testFlowEnv,
+		NodeIP:       netip.MustParseAddr("10.224.0.4"),
+		Targets:      []EgressTarget{{Name: mcrHost, Address: netip.MustParseAddr("20.61.99.68"), Port: 443}},
+		NodeCount:    3,
+		MaxNodeCount: 3,
+	}
+}
+
+func TestBuildOutboundReport_LoadBalancer(t *testing.T) {
+	input := testOutboundInput("loadBalancer", nil)
+	input.LoadBalancer = testLoadBalancer(1024, 1)
+	input.SubnetNSG = testNSG(testSecurityRule("deny-internet", 100, armnetwork.SecurityRuleDirectionOutbound,
+		armnetwork.SecurityRuleAccessDeny, armnetwork.SecurityRuleProtocolAsterisk, "*", "Internet", "*"))
+	input.CheckNetwork = parseCheckNetworkOutput("Outbound network connectivity check succeeded")
+
+	report := BuildOutboundReport(input)
+	if report.LoadBalancer == nil || report.EgressPath[len(report.EgressPath)-1] != "Internet" {
+		t.Errorf("Expected the path to leave through the load balancer, got %v", report.EgressPath)
+	}
+	if checks := findingChecks(report.Findings); len(checks) != 1 || checks[0] != "nsg_blocks_egress" {
+		t.Errorf("Expected nsg_blocks_egress, got %v", checks)
+	}
+	if !strings.Contains(report.Findings[0].Message, "deny-internet") {
+		t.Errorf("Expected the blocking rule in the message, got %q", report.Findings[0].Message)
+	}
+	if report.EgressChecks[0].Approximate || strings.Contains(report.Findings[0].Message, "assumed") {
+		t.Errorf("Expected a node IP read from a node not to be marked approximate, got %+v", report.EgressChecks[0])
+	}
+
+	input.NodeIPApproximate = true
+	report = BuildOutboundReport(input)
+	if !report.EgressChecks[0].Approximate || !strings.Contains(report.Findings[0].Message, "assumed node address") {
+		t.Errorf("Expected the NSG check to be marked approximate, got %+v: %q", report.EgressChecks[0], report.Findings[0].Message)
+	}
+}
+
+func TestParseCheckNetworkOutput(t *testing.T) {
+	tests := []struct {
+		name           string
+		output         string
+		wantRan        bool
+		wantConclusive bool
+		wantSucceeded  bool
+		wantFailures   int
+	}{
+		{"summary succeeded", "Checking error-reporting.example.com\nOutbound network connectivity check succeeded", true, true, true, 0},
+		{"summary failed", "Outbound network connectivity check failed, please check the output", true, true, false, 0},
+		{"endpoint results", "mcr.microsoft.com: ok\nlogin.microsoftonline.com: failed\npackages.aks.azure.com: timeout", true, true, false, 2},
+		{"error word in a passing line", "No errors found\nmcr.microsoft.com: ok", true, true, true, 0},
+		{"az error", "ERROR: (AuthorizationFailed) The client does not have authorization", false, false, false, 0},
+		{"unrecognized output", "Running the connectivity checker", true, false, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := parseCheckNetworkOutput(tt.output)
+			if result.Ran != tt.wantRan || result.Conclusive != tt.wantConclusive || result.Succeeded != tt.wantSucceeded || len(result.Failures) != tt.wantFailures {
+				t.Errorf("Unexpected result %+v", result)
+			}
+		})
+	}
+
+	input := testOutboundInput("loadBalancer", nil)
+	input.LoadBalancer = testLoadBalancer(1024, 1)
+	input.CheckNetwork = parseCheckNetworkOutput("done")
+	report := BuildOutboundReport(input)
+	if len(report.Findings) != 0 || len(report.Notes) != 1 || !strings.Contains(report.Notes[0], "no recognizable result") {
+		t.Errorf("Expected inconclusive output to be a note, got findings %v and notes %v", findingChecks(report.Findings), report.Notes)
+	}
+}
+
+func TestPrimaryPrivateIP(t *testing.T) {
+	nic := func(primary bool, addrs ...string) *armnetwork.Interface {
+		properties := &armnetwork.InterfacePropertiesFormat{Primary: to.Ptr(primary)}
+		for i, addr := range addrs {
+			properties.IPConfigurations = append(properties.IPConfigurations, &armnetwork.InterfaceIPConfiguration{
+				Properties: &armnetwork.InterfaceIPConfigurationPropertiesFormat{Primary: to.Ptr(i == 0), PrivateIPAddress: to.Ptr(addr)},
+			})
+		}
+		return &armnetwork.Interface{Properties: properties}
+	}
+
+	addr, ok := primaryPrivateIP([]*armnetwork.Interface{nil, nic(false, "10.1.0.9"), nic(true, "10.224.0.17", "10.224.0.18")})
+	if !ok || addr != netip.MustParseAddr("10.224.0.17") {
+		t.Errorf("Expected 10.224.0.17, got %s", addr)
+	}
+	if _, ok := primaryPrivateIP([]*armnetwork.Interface{nic(true)}); ok {
+		t.Error("Expected no address for a NIC without IP configurations")
+	}
+}
+
+func TestBuildOutboundReport_ApplianceOverridesNATGateway(t *testing.T) {
+	input := testOutboundInput("userAssignedNATGateway", testRouteTable(testRoute("egress", "0.0.0.0/0", armnetwork.RouteNextHopTypeVirtualAppliance, "10.0.1.4")))
+	input.NATGateway = &armnetwork.NatGateway{Properties: &armnetwork.NatGatewayPropertiesFormat{
+		PublicIPAddresses: []*armnetwork.SubResource{{ID: to.Ptr("pip-1")}},
+	}}
+	input.CheckNetwork = parseCheckNetworkOutput("mcr.microsoft.com: failed\nmanagement.azure.com: ok")
+
+	report := BuildOutboundReport(input)
+	path := strings.Join(report.EgressPath, " -> ")
+	if !strings.Contains(path, "VirtualAppliance 10.0.1.4") || strings.Contains(path, "NAT gateway") {
+		t.Errorf("Expected the path to leave through the appliance, got %s", path)
+	}
+	checks := findingChecks(report.Findings)
+	if len(checks) != 2 || checks[0] != "default_route_bypasses_outbound_type" || checks[1] != "check_network_failed" {
+		t.Errorf("Expected the bypass and check-network findings, got %v", checks)
+	}
+	if !strings.Contains(report.Findings[1].Message, "mcr.microsoft.com: failed") || strings.Contains(report.Findings[1].Message, "management") {
+		t.Errorf("Expected only the failing line, got %q", report.Findings[1].Message)
+	}
+}
+
+func TestBuildOutboundReport_CheckNetworkNotRun(t *testing.T) {
+	input := testOutboundInput("managedNATGateway", nil)
+	input.CheckNetwork = runClusterCheckNetwork(func(params map[string]interface{}) (string, error) {
+		if params["operation"] != "check-network" || params["cluster_name"] != "cluster-1" {
+			t.Errorf("Unexpected check-network parameters: %v", params)
+		}
+		return "", errors.New("az not found")
+	}, "sub-1", "rg-1", "cluster-1", map[string]interface{}{})
+
+	report := BuildOutboundReport(input)
+	if checks := findingChecks(report.Findings); len(checks) != 1 || checks[0] != "nat_gateway_missing" {
+		t.Errorf("Expected nat_gateway_missing, got %v", checks)
+	}
+	if len(report.Notes) != 1 || !strings.Contains(report.Notes[0], "az not found") {
+		t.Errorf("Expected a note that check-network did not run, got %v", report.Notes)
+	}
+}
@@ -44,16 +44,13 @@ Examples:
 			mcp.Description("The type of network resource to query"),
 		),
 		mcp.WithString("subscription_id",
-			mcp.Description("Azure Subscription ID"),
-			mcp.Required(),
+			mcp.Description("Azure Subscription ID (defaults to the active cluster context)"),
 		),
 		mcp.WithString("resource_group",
-			mcp.Description("Azure Resource Group containing the AKS cluster"),
-			mcp.Required(),
+			mcp.Description("Azure Resource Group containing the AKS cluster (defaults to the active cluster context)"),
 		),
 		mcp.WithString("cluster_name",
-			mcp.Description("Name of the AKS cluster"),
-			mcp.Required(),
+			mcp.Description("Name of the AKS cluster (defaults to the active cluster context)"),
 		),
 		mcp.WithString("filters",
 			mcp.Description("Optional filters for the query"),
@@ -80,3 +77,172 @@ func GetSupportedNetworkResourceTypes() []string {
 		string(ResourceTypeLoadBalancer), string(ResourceTypePrivateEndpoint),
 	}
 }
+
+// RegisterCheckFlowTool registers the network_check_flow tool
+func RegisterCheckFlowTool() mcp.Tool {
+	description := `Check whether traffic to or from an AKS node would be allowed by its network security groups.
+
+Evaluates the NSG on the node pool subnet and the NSG on the node NIC (from the VMSS model), default rules
+included, in priority order. Inbound traffic passes the subnet NSG then the NIC NSG; outbound traffic passes
+the NIC NSG then the subnet NSG, and both must allow it. Returns the matching rule and verdict for each NSG
+and the overall verdict.
+
+Service tags: VirtualNetwork (VNet and peered address space), AzureLoadBalancer and Internet are resolved.
+Application security groups are resolved for the node NIC. Rules that depend on other service tags or ASG
+membership are listed as unresolved, and the verdict is marked inconclusive when they could change it.
+
+Examples:
+- Can the load balancer reach NodePort 30080: source_ip="203.0.113.10", destination_ip="10.224.0.4", destination_port=30080, direction="inbound"
+- Can nodes reach a database: source_ip="10.224.0.4", destination_ip="10.1.0.5", destination_port=5432, direction="outbound"`
+
+	return mcp.NewTool("network_check_flow",
+		mcp.WithDescription(description),
+		mcp.WithString("subscription_id",
+			mcp.Description("Azure Subscription ID (defaults to the active cluster context)"),
+		),
+		mcp.WithString("resource_group",
+			mcp.Description("Azure Resource Group containing the AKS cluster (defaults to the active cluster context)"),
+		),
+		mcp.WithString("cluster_name",
+			mcp.Description("Name of the AKS cluster (defaults to the active cluster context)"),
+		),
+		mcp.WithString("source_ip",
+			mcp.Required(),
+			mcp.Description("Source IP address. For outbound traffic this is the node IP"),
+		),
+		mcp.WithString("destination_ip",
+			mcp.Required(),
+			mcp.Description("Destination IP address. For inbound traffic this is the node IP"),
+		),
+		mcp.WithNumber("destination_port",
+			mcp.Description("Destination port (required for tcp and udp)"),
+			mcp.Min(1),
+			mcp.Max(65535),
+		),
+		mcp.WithNumber("source_port",
+			mcp.Description("Source port. When omitted, only rules that allow any source port match"),
+			mcp.Min(1),
+			mcp.Max(65535),
+		),
+		mcp.WithString("protocol",
+			mcp.Description("Protocol: tcp (default), udp or icmp"),
+		),
+		mcp.WithString("direction",
+			mcp.Required(),
+			mcp.Description("Direction relative to the node: inbound or outbound"),
+		),
+		mcp.WithString("node_pool",
+			mcp.Description("Node pool whose subnet and NIC NSGs are evaluated (defaults to the first node pool)"),
+		),
+	)
+}
+
+// RegisterRouteAnalysisTool registers the network_route_analysis tool
+func RegisterRouteAnalysisTool() mcp.Tool {
+	description := `Analyze the effective routes of an AKS node pool subnet.
+
+Merges the user-defined routes of the subnet route table with the Azure system routes (VNet, peerings,
+Internet and the reserved ranges routed to None); a user route replaces the system route with the same prefix.
+When destination_ip is given, returns its effective next hop by longest prefix match and explains it.
+
+Also flags common misconfigurations, especially for outboundType=userDefinedRouting:
+- no route table or no 0.0.0.0/0 route, or 0.0.0.0/0 routed to Internet or None
+- 0.0.0.0/0 sent to an appliance while outboundType is loadBalancer or NAT gateway
+- virtual appliance next hops outside the VNet and its peerings
+- the path from the nodes to the API server: dropped, routed asymmetrically through an appliance for private
+  clusters, or leaving through an appliance whose egress IP must be in the authorized IP ranges
+
+Examples:
+- Check the subnet routing: (no parameters besides the cluster)
+- Where does traffic to an on-premises host go: destination_ip="192.168.10.5"`
+
+	return mcp.NewTool("network_route_analysis",
+		mcp.WithDescription(description),
+		mcp.WithString("subscription_id",
+			mcp.Description("Azure Subscription ID (defaults to the active cluster context)"),
+		),
+		mcp.WithString("resource_group",
+			mcp.Description("Azure Resource Group containing the AKS cluster (defaults to the active cluster context)"),
+		),
+		mcp.WithString("cluster_name",
+			mcp.Description("Name of the AKS cluster (defaults to the active cluster context)"),
+		),
+		mcp.WithString("destination_ip",
+			mcp.Description("Destination IP address to find the effective next hop for"),
+		),
+		mcp.WithString("node_pool",
+			mcp.Description("Node pool whose subnet is analyzed (defaults to the first node pool)"),
+		),
+	)
+}
+
+// RegisterOutboundDiagnoseTool registers the outbound_diagnose tool
+func RegisterOutboundDiagnoseTool() mcp.Tool {
+	description := `Diagnose outbound (egress) connectivity of an AKS cluster.
+
+Reads networkProfile.outboundType and inspects the matching egress path of a node pool:
+- loadBalancer: the outbound rule of the kubernetes load balancer, its frontend IPs and SNAT port allocation
+  compared with the current node count and the count the node pools can scale to
+- managedNATGateway / userAssignedNATGateway: the NAT gateway of the node subnet and its public IPs
+- userDefinedRouting: the 0.0.0.0/0 route of the subnet route table and its appliance next hop
+Checks the route and NSG verdict for TCP 443 from the nodes to the API server and mcr.microsoft.com, and
+runs 'az aks check-network outbound' from a node. Returns the egress path hop by hop with the likely breakpoints.
+
+Examples:
+- Diagnose egress: (no parameters besides the cluster)
+- Skip the node connectivity check: include_check_network=false`
+
+	return mcp.NewTool("outbound_diagnose",
+		mcp.WithDescription(description),
+		mcp.WithString("subscription_id",
+			mcp.Description("Azure Subscription ID (defaults to the active cluster context)"),
+		),
+		mcp.WithString("resource_group",
+			mcp.Description("Azure Resource Group containing the AKS cluster (defaults to the active cluster context)"),
+		),
+		mcp.WithString("cluster_name",
+			mcp.Description("Name of the AKS cluster (defaults to the active cluster context)"),
+		),
+		mcp.WithString("node_pool",
+			mcp.Description("Node pool whose egress path is analyzed (defaults to the first node pool)"),
+		),
+		mcp.WithBoolean("include_check_network",
+			mcp.Description("Run 'az aks check-network outbound' from a node (default: true)"),
+		),
+		mcp.WithString("node_name",
+			mcp.Description("Node to run check-network from (defaults to a node chosen by the CLI)"),
+		),
+	)
+}
+
+// RegisterSubnetCapacityTool registers the subnet_capacity tool
+func RegisterSubnetCapacityTool() mcp.Tool {
+	description := `Plan the subnet IP capacity of the node pools of an AKS cluster.
+
+Reads the node subnet of each node pool (and its pod subnet, if any), counts the usable and used IPs from the
+subnet address prefix and its IP configurations, and works out how many IPs each node takes for the network
+plugin mode:
+- Azure CNI: 1 + maxPods per node from the node subnet
+- Azure CNI with pod subnet: 1 per node from the node subnet and maxPods per node from the pod subnet
+- Azure CNI overlay and kubenet: 1 per node
+Returns the current usage and, for each node pool, the largest node count the subnets allow and the largest one
+that still leaves room for the surge nodes of an upgrade at that count. Flags pools whose autoscaler maxCount or
+upgrade surge would exhaust the subnet. Pools that share a subnet each count all its free IPs, so their figures are
+upper bounds; the combined demand of the pools is checked separately.
+
+Examples:
+- Check subnet capacity: (no parameters besides the cluster)`
+
+	return mcp.NewTool("subnet_capacity",
+		mcp.WithDescription(description),
+		mcp.WithString("subscription_id",
+			mcp.Description("Azure Subscription ID (defaults to the active cluster context)"),
+		),
+		mcp.WithString("resource_group",
+			mcp.Description("Azure Resource Group containing the AKS cluster (defaults to the active cluster context)"),
+		),
+		mcp.WithString("cluster_name",
+			mcp.Description("Name of the AKS cluster (defaults to the active cluster context)"),
+		),
+	)
+}
@@ -0,0 +1,494 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/components/network/resourcehelpers"
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/tools"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
+)
+
+// Severities of findings
+const (
+	SeverityHigh   = "high"
+	SeverityMedium = "medium"
+	SeverityLow    = "low"
+)
+
+// Route sources and states, named as in the Azure effective routes view
+const (
+	RouteSourceUser    = "User"
+	RouteSourceDefault = "Default"
+	RouteStateActive   = "Active"
+	RouteStateInvalid  = "Invalid"
+	nextHopVNetPeering = "VNetPeering"
+)
+
+// defaultRoute is the prefix of the route that carries egress traffic
+var defaultRoute = netip.MustParsePrefix("0.0.0.0/0")
+
+// droppedPrefixes are the address ranges Azure routes to None unless they are in the VNet address space
+var droppedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("10.0.0.0/8"),
+	netip.MustParsePrefix("172.16.0.0/12"),
+	netip.MustParsePrefix("192.168.0.0/16"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// lookupHost resolves the public API server FQDN; replaced in tests
+var lookupHost = net.DefaultResolver.LookupHost
+
+// EffectiveRoute is one entry of the effective route table of a subnet
+type EffectiveRoute struct {
+	Source        string `json:"source"`
+	Name          string `json:"name,omitempty"`
+	AddressPrefix string `json:"address_prefix"`
+	NextHopType   string `json:"next_hop_type"`
+	NextHopIP     string `json:"next_hop_ip,omitempty"`
+	State         string `json:"state"`
+	Note          string `json:"note,omitempty"`
+
+	prefix netip.Prefix
+}
+
+// RouteLookup is the effective next hop for one destination
+type RouteLookup struct {
+	Destination string          `json:"destination"`
+	Route       *EffectiveRoute `json:"route,omitempty"`
+	Explanation string          `json:"explanation"`
+}
+
+// Finding is a misconfiguration on a network path, with its explanation
+type Finding struct {
+	Severity    string `json:"severity"`
+	Check       string `json:"check"`
+	Message     string `json:"message"`
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// RouteInput is the routing state of a node pool subnet
+type RouteInput struct {
+	OutboundType       string
+	VNetPrefixes       []netip.Prefix
+	PeeredPrefixes     []netip.Prefix
+	RouteTable         *armnetwork.RouteTable
+	PodCIDRs           []netip.Prefix
+	Destination        netip.Addr
+	APIServer          netip.Addr
+	PrivateAPIServer   bool
+	AuthorizedIPRanges bool
+}
+
+// RouteAnalysis is the effective routing of a node pool subnet
+type RouteAnalysis struct {
+	ClusterName  string           `json:"cluster_name"`
+	NodePool     string           `json:"node_pool"`
+	SubnetID     string           `json:"subnet_id,omitempty"`
+	RouteTableID string           `json:"route_table_id,omitempty"`
+	OutboundType string           `json:"outbound_type"`
+	Routes       []EffectiveRoute `json:"routes"`
+	Lookup       *RouteLookup     `json:"lookup,omitempty"`
+	APIServer    *RouteLookup     `json:"api_server,omitempty"`
+	Findings     []Finding        `json:"findings"`
+	Notes        []string         `json:"notes,omitempty"`
+}
+
+// EffectiveRoutes merges the system routes of a subnet with the user-defined routes of its route table.
+// A user route replaces the system route with the same prefix, which is then reported as Invalid.
+func EffectiveRoutes(input RouteInput) []EffectiveRoute {
+	var routes []EffectiveRoute
+	userPrefixes := map[netip.Prefix]bool{}
+	if input.RouteTable != nil && input.RouteTable.Properties != nil {
+		for _, route := range input.RouteTable.Properties.Routes {
+			if route == nil || route.Properties == nil {
+				continue
+			}
+			effective := EffectiveRoute{
+				Source:        RouteSourceUser,
+				Name:          derefString(route.Name),
+				AddressPrefix: derefString(route.Properties.AddressPrefix),
+				NextHopIP:     derefString(route.Properties.NextHopIPAddress),
+				State:         RouteStateActive,
+			}
+			if route.Properties.NextHopType != nil {
+				effective.NextHopType = string(*route.Properties.NextHopType)
+			}
+			prefix, err := netip.ParsePrefix(effective.AddressPrefix)
+			if err != nil {
+				effective.Note = "Service tag prefix; the addresses it covers are not evaluated"
+			} else {
+				effective.prefix = prefix.Masked()
+				userPrefixes[effective.prefix] = true
+				podRoute := slices.ContainsFunc(input.PodCIDRs, func(p netip.Prefix) bool { return p.Overlaps(prefix) })
+				if podRoute && effective.NextHopType == string(armnetwork.RouteNextHopTypeVirtualAppliance) {
+					effective.Note = "Pod CIDR route managed by AKS for kubenet"
+				}
+			}
+			routes = append(routes, effective)
+		}
+	}
+
+	addSystem := func(prefix netip.Prefix, nextHop, name string) {
+		state := RouteStateActive
+		if userPrefixes[prefix] {
+			state = RouteStateInvalid
+		}
+		routes = append(routes, EffectiveRoute{Source: RouteSourceDefault, Name: name, AddressPrefix: prefix.String(), NextHopType: nextHop, State: state, prefix: prefix})
+	}
+	for _, prefix := range input.VNetPrefixes {
+		addSystem(prefix, string(armnetwork.RouteNextHopTypeVnetLocal), "VirtualNetwork")
+	}
+	for _, prefix := range input.PeeredPrefixes {
+		addSystem(prefix, nextHopVNetPeering, "VNetPeering")
+	}
+	addSystem(defaultRoute, string(armnetwork.RouteNextHopTypeInternet), "Internet")
+	for _, prefix := range droppedPrefixes {
+		// Ranges used by the VNet or a peering are routed there instead
+		if slices.Contains(input.VNetPrefixes, prefix) || slices.Contains(input.PeeredPrefixes, prefix) {
+			continue
+		}
+		addSystem(prefix, string(armnetwork.RouteNextHopTypeNone), "ReservedRange")
+	}
+	return routes
+}
+
+// LookupRoute returns the active route with the longest prefix that contains the destination
+func LookupRoute(routes []EffectiveRoute, destination netip.Addr) RouteLookup {
+	lookup := RouteLookup{Destination: destination.String()}
+	var best *EffectiveRoute
+	for i := range routes {
+		route := &routes[i]
+		if route.State != RouteStateActive || !route.prefix.IsValid() || !route.prefix.Contains(destination) {
+			continue
+		}
+		if best == nil || route.prefix.Bits() > best.prefix.Bits() {
+			best = route
+		}
+	}
+	if best == nil {
+		lookup.Explanation = fmt.Sprintf("No route matches %s, so the traffic is dropped", destination)
+		return lookup
+	}
+
+	route := *best
+	lookup.Route = &route
+	kind := "system route"
+	if route.Source == RouteSourceUser {
+		kind = fmt.Sprintf("user-defined route %s", route.Name)
+	}
+	nextHop := route.NextHopType
+	if route.NextHopIP != "" {
+		nextHop += " " + route.NextHopIP
+	}
+	lookup.Explanation = fmt.Sprintf("%s is longest-prefix matched by the %s for %s, next hop %s.", destination, kind, route.AddressPrefix, nextHop)
+	switch route.NextHopType {
+	case string(armnetwork.RouteNextHopTypeNone):
+		lookup.Explanation += " Traffic with next hop None is dropped."
+	case string(armnetwork.RouteNextHopTypeVirtualAppliance):
+		lookup.Explanation += " The appliance must have IP forwarding enabled and route the return traffic back."
+	case string(armnetwork.RouteNextHopTypeVirtualNetworkGateway):
+		lookup.Explanation += " The gateway forwards the traffic using its own (BGP or static) routes."
+	}
+	return lookup
+}
+
+// AnalyzeRoutes computes the effective routes of a subnet and flags common misconfigurations
+func AnalyzeRoutes(input RouteInput) (routes []EffectiveRoute, lookup, apiServer *RouteLookup, findings []Finding) {
+	routes = EffectiveRoutes(input)
+	if input.Destination.IsValid() {
+		l := LookupRoute(routes, input.Destination)
+		lookup = &l
+	}
+
+	udr := input.OutboundType == string(armcontainerservice.OutboundTypeUserDefinedRouting)
+	var userDefault *EffectiveRoute
+	for i := range routes {
+		if routes[i].Source == RouteSourceUser && routes[i].prefix == defaultRoute {
+			userDefault = &routes[i]
+		}
+	}
+
+	switch {
+	case udr && input.RouteTable == nil:
+		findings = append(findings, Finding{
+			Severity:    SeverityHigh,
+			Check:       "route_table_missing",
+			Message:     "outboundType is userDefinedRouting but no route table is associated with the node subnet, so egress uses the Internet system route without an AKS-managed public IP.",
+			Remediation: "Associate a route table with a 0.0.0.0/0 route to the firewall or network virtual appliance.",
+		})
+	case udr && userDefault == nil:
+		findings = append(findings, Finding{
+			Severity:    SeverityHigh,
+			Check:       "default_route_missing",
+			Message:     "outboundType is userDefinedRouting but the route table has no 0.0.0.0/0 route. AKS requires it, and egress falls back to the Internet system route.",
+			Remediation: "Add a 0.0.0.0/0 route with next hop VirtualAppliance (the firewall private IP) or VirtualNetworkGateway.",
+		})
+	case udr && userDefault.NextHopType == string(armnetwork.RouteNextHopTypeInternet):
+		findings = append(findings, Finding{
+			Severity:    SeverityMedium,
+			Check:       "default_route_internet",
+			Message:     fmt.Sprintf("Route %s sends 0.0.0.0/0 to Internet. With userDefinedRouting AKS creates no outbound public IP, so egress relies on default outbound access.", userDefault.Name),
+			Remediation: "Point 0.0.0.0/0 at the egress firewall, or use outboundType loadBalancer or a NAT gateway.",
+		})
+	case !udr && userDefault != nil && userDefault.NextHopType == string(armnetwork.RouteNextHopTypeVirtualAppliance):
+		findings = append(findings, Finding{
+			Severity: SeverityMedium,
+			Check:    "default_route_bypasses_outbound_type",
+			Message: fmt.Sprintf("Route %s sends 0.0.0.0/0 to appliance %s while outboundType is %s. Egress bypasses the %s, and replies to traffic arriving through a public load balancer leave through the appliance, which breaks them (asymmetric routing).",
+				userDefault.Name, userDefault.NextHopIP, input.OutboundType, input.OutboundType),
+			Remediation: "Switch outboundType to userDefinedRouting, or remove the 0.0.0.0/0 route.",
+		})
+	}
+	if userDefault != nil && userDefault.NextHopType == string(armnetwork.RouteNextHopTypeNone) {
+		findings = append(findings, Finding{
+			Severity:    SeverityHigh,
+			Check:       "default_route_dropped",
+			Message:     fmt.Sprintf("Route %s sends 0.0.0.0/0 to None, so all egress outside the VNet is dropped and nodes cannot reach the API server or pull images.", userDefault.Name),
+			Remediation: "Route 0.0.0.0/0 to the egress firewall or appliance.",
+		})
+	}
+
+	reachable := append(slices.Clone(input.VNetPrefixes), input.PeeredPrefixes...)
+	for _, route := range routes {
+		if route.Source != RouteSourceUser {
+			continue
+		}
+		if !route.prefix.IsValid() {
+			findings = append(findings, Finding{
+				Severity: SeverityLow,
+				Check:    "service_tag_route",
+				Message:  fmt.Sprintf("Route %s uses the service tag %s, whose addresses are not evaluated here.", route.Name, route.AddressPrefix),
+			})
+		}
+		if route.NextHopType != string(armnetwork.RouteNextHopTypeVirtualAppliance) {
+			continue
+		}
+		nextHop, err := netip.ParseAddr(route.NextHopIP)
+		switch {
+		case err != nil:
+			findings = append(findings, Finding{
+				Severity: SeverityHigh,
+				Check:    "appliance_next_hop_invalid",
+				Message:  fmt.Sprintf("Route %s has next hop VirtualAppliance but no valid next hop IP (%q), so the traffic is dropped.", route.Name, route.NextHopIP),
+			})
+		case len(reachable) > 0 && !inPrefixes(nextHop, reachable):
+			findings = append(findings, Finding{
+				Severity:    SeverityHigh,
+				Check:       "appliance_unreachable",
+				Message:     fmt.Sprintf("Route %s sends %s to appliance %s, which is outside the VNet and its peerings, so the traffic is dropped.", route.Name, route.AddressPrefix, nextHop),
+				Remediation: "Use the private IP of the appliance in this VNet or a peered hub VNet.",
+			})
+		}
+	}
+
+	if input.APIServer.IsValid() {
+		l := LookupRoute(routes, input.APIServer)
+		apiServer = &l
+		findings = append(findings, apiServerRouteFindings(input, l)...)
+	}
+	return routes, lookup, apiServer, findings
+}
+
+// apiServerRouteFindings checks the path from the nodes to the API server
+func apiServerRouteFindings(input RouteInput, lookup RouteLookup) []Finding {
+	if lookup.Route == nil || lookup.Route.NextHopType == string(armnetwork.RouteNextHopTypeNone) {
+		return []Finding{{
+			Severity:    SeverityHigh,
+			Check:       "api_server_unreachable",
+			Message:     fmt.Sprintf("Traffic from the nodes to the API server at %s is dropped by the subnet routes, so nodes cannot join or report status.", input.APIServer),
+			Remediation: "Add a route for the API server address to a next hop that can reach it.",
+		}}
+	}
+	if lookup.Route.NextHopType != string(armnetwork.RouteNextHopTypeVirtualAppliance) {
+		return nil
+	}
+	if input.PrivateAPIServer {
+		return []Finding{{
+			Severity: SeverityHigh,
+			Check:    "asymmetric_api_server_route",
+			Message: fmt.Sprintf("Traffic from the nodes to the private API server endpoint %s goes through appliance %s (route %s), but the endpoint replies directly over the VNet. Stateful firewalls drop the unmatched replies (asymmetric routing), so kubelet and konnectivity connections fail intermittently.",
+				input.APIServer, lookup.Route.NextHopIP, lookup.Route.AddressPrefix),
+			Remediation: "Add a more specific route for the API server subnet with next hop VnetLocal, or stop routing VNet-internal prefixes to the appliance.",
+		}}
+	}
+	if input.AuthorizedIPRanges {
+		return []Finding{{
+			Severity:    SeverityLow,
+			Check:       "api_server_authorized_ranges",
+			Message:     fmt.Sprintf("Traffic from the nodes to the API server leaves through appliance %s, and the API server has authorized IP ranges. The appliance's public egress IP must be in those ranges or nodes cannot reach the API server.", lookup.Route.NextHopIP),
+			Remediation: "Add the firewall public IP to apiServerAccessProfile.authorizedIPRanges.",
+		}}
+	}
+	return nil
+}
+
+// =============================================================================
+// network_route_analysis handler
+// =============================================================================
+
+// GetRouteAnalysisHandler returns the handler for the network_route_analysis tool
+func GetRouteAnalysisHandler(client *azureclient.AzureClient, cfg *config.ConfigData) tools.ResourceHandler {
+	return tools.ResourceHandlerFunc(func(params map[string]interface{}, _ *config.ConfigData) (string, error) {
+		return HandleRouteAnalysis(params, client)
+	})
+}
+
+// HandleRouteAnalysis computes the effective routes of a node pool subnet
+func HandleRouteAnalysis(params map[string]interface{}, client *azureclient.AzureClient) (string, error) {
+	subID, rg, clusterName, err := common.ExtractAKSParameters(params)
+	if err != nil {
+		return "", err
+	}
+	var destination netip.Addr
+	if value, _ := params["destination_ip"].(string); value != "" {
+		if destination, err = netip.ParseAddr(strings.TrimSpace(value)); err != nil {
+			return "", fmt.Errorf("invalid destination_ip parameter: %q is not an IP address", value)
+		}
+	}
+	if client == nil {
+		return "", fmt.Errorf("azure client is required but not provided")
+	}
+
+	ctx := context.Background()
+	cluster, err := common.GetClusterDetails(ctx, client, subID, rg, clusterName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get cluster details: %v", err)
+	}
+	poolName, _ := params["node_pool"].(string)
+	pool, err := selectNodePool(cluster, poolName)
+	if err != nil {
+		return "", err
+	}
+
+	analysis := RouteAnalysis{ClusterName: clusterName, NodePool: derefString(pool.Name)}
+	subnetID, subnet, err := getNodePoolSubnet(ctx, client, cluster, pool)
+	if err != nil {
+		return "", err
+	}
+	analysis.SubnetID = subnetID
+
+	input, routeTableID, notes, err := collectRouteInput(ctx, client, cluster, subnetID, subnet)
+	if err != nil {
+		return "", err
+	}
+	input.Destination = destination
+	analysis.OutboundType = input.OutboundType
+	analysis.RouteTableID = routeTableID
+	analysis.Notes = notes
+
+	analysis.Routes, analysis.Lookup, analysis.APIServer, analysis.Findings = AnalyzeRoutes(input)
+	if analysis.Findings == nil {
+		analysis.Findings = []Finding{}
+	}
+
+	resultJSON, err := json.MarshalIndent(analysis, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal route analysis to JSON: %v", err)
+	}
+	return string(resultJSON), nil
+}
+
+// collectRouteInput reads the routing state of a node pool subnet: outbound type, route table,
+// VNet and peering address space and the API server address. Missing optional data is returned as notes.
+func collectRouteInput(ctx context.Context, client *azureclient.AzureClient, cluster *armcontainerservice.ManagedCluster, subnetID string, subnet *armnetwork.Subnet) (input RouteInput, routeTableID string, notes []string, err error) {
+	if profile := cluster.Properties.NetworkProfile; profile != nil {
+		if profile.OutboundType != nil {
+			input.OutboundType = string(*profile.OutboundType)
+		}
+		if profile.NetworkPlugin != nil && *profile.NetworkPlugin == armcontainerservice.NetworkPluginKubenet {
+			input.PodCIDRs = parsePrefixes(append([]*string{profile.PodCidr}, profile.PodCidrs...))
+		}
+	}
+	if input.OutboundType == "" {
+		input.OutboundType = string(armcontainerservice.OutboundTypeLoadBalancer)
+	}
+
+	if subnet.Properties != nil && subnet.Properties.RouteTable != nil && subnet.Properties.RouteTable.ID != nil {
+		routeTableID = *subnet.Properties.RouteTable.ID
+		rtInterface, err := client.GetResourceByID(ctx, routeTableID)
+		if err != nil {
+			return RouteInput{}, "", nil, fmt.Errorf("failed to get RouteTable details: %v", err)
+		}
+		rt, ok := rtInterface.(*armnetwork.RouteTable)
+		if !ok {
+			return RouteInput{}, "", nil, fmt.Errorf("unexpected resource type returned for RouteTable")
+		}
+		input.RouteTable = rt
+		if rt.Properties != nil && (rt.Properties.DisableBgpRoutePropagation == nil || !*rt.Properties.DisableBgpRoutePropagation) {
+			notes = append(notes, "BGP route propagation is enabled on the route table, so routes learned by a VNet gateway may also apply; they are not visible here.")
+		}
+	}
+
+	if vnet, err := getSubnetVNet(ctx, client, subnetID); err != nil {
+		notes = append(notes, fmt.Sprintf("Could not read the VNet, so VNet and peering system routes are missing: %v", err))
+	} else {
+		input.VNetPrefixes = vnetAddressSpace(vnet)
+		input.PeeredPrefixes = peeredAddressSpace(vnet)
+	}
+
+	if note := resolveAPIServer(ctx, client, cluster, &input); note != "" {
+		notes = append(notes, note)
+	}
+
+	return input, routeTableID, notes, nil
+}
+
+// resolveAPIServer finds the address nodes use to reach the API server: the private endpoint of a
+// private cluster, or the resolved public FQDN. Problems are returned as a note.
+func resolveAPIServer(ctx context.Context, client *azureclient.AzureClient, cluster *armcontainerservice.ManagedCluster, input *RouteInput) string {
+	if access := cluster.Properties.APIServerAccessProfile; access != nil {
+		input.AuthorizedIPRanges = len(access.AuthorizedIPRanges) > 0
+	}
+
+	peID, err := resourcehelpers.GetPrivateEndpointIDFromAKS(ctx, cluster, client)
+	if err != nil {
+		return fmt.Sprintf("Could not find the API server private endpoint: %v", err)
+	}
+	if peID != "" {
+		input.PrivateAPIServer = true
+		pe, err := client.GetPrivateEndpointByID(ctx, peID)
+		if err != nil {
+			return fmt.Sprintf("Could not read the API server private endpoint: %v", err)
+		}
+		if pe.Properties != nil {
+			for _, dnsConfig := range pe.Properties.CustomDNSConfigs {
+				if dnsConfig == nil {
+					continue
+				}
+				for _, ip := range dnsConfig.IPAddresses {
+					if addr, err := netip.ParseAddr(derefString(ip)); err == nil {
+						input.APIServer = addr
+						return ""
+					}
+				}
+			}
+		}
+		return "The API server private endpoint has no IP address, so the API server path is not checked."
+	}
+
+	fqdn := derefString(cluster.Properties.Fqdn)
+	if fqdn == "" {
+		return "The cluster has no API server FQDN, so the API server path is not checked."
+	}
+	addrs, err := lookupHost(ctx, fqdn)
+	if err != nil {
+		return fmt.Sprintf("Could not resolve the API server FQDN %s, so the API server path is not checked: %v", fqdn, err)
+	}
+	for _, value := range addrs {
+		if addr, err := netip.ParseAddr(value); err == nil && addr.Is4() {
+			input.APIServer = addr
+			return ""
+		}
+	}
+	return fmt.Sprintf("The API server FQDN %s has no IPv4 address, so the API server path is not checked.", fqdn)
+}
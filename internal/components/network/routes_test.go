@@ -0,0 +1,157 @@
+package network
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
+)
+
+func testRoute(name, prefix string, nextHop armnetwork.RouteNextHopType, nextHopIP string) *armnetwork.Route {
+	route := &armnetwork.Route{
+		Name: to.Ptr(name),
+		Properties: &armnetwork.RoutePropertiesFormat{
+			AddressPrefix: to.Ptr(prefix),
+			NextHopType:   to.Ptr(nextHop),
+		},
+	}
+	if nextHopIP != "" {
+		route.Properties.NextHopIPAddress = to.Ptr(nextHopIP)
+	}
+	return route
+}
+
+func testRouteTable(routes ...*armnetwork.Route) *armnetwork.RouteTable {
+	return &armnetwork.RouteTable{Properties: &armnetwork.RouteTablePropertiesFormat{Routes: routes}}
+}
+
+func testRouteInput(outboundType string, rt *armnetwork.RouteTable) RouteInput {
+	return RouteInput{
+		OutboundType:   outboundType,
+		VNetPrefixes:   []netip.Prefix{netip.MustParsePrefix("10.224.0.0/12")},
+		PeeredPrefixes: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/16")},
+		RouteTable:     rt,
+	}
+}
+
+func findingChecks(findings []Finding) []string {
+	var checks []string
+	for _, finding := range findings {
+		checks = append(checks, finding.Check)
+	}
+	return checks
+}
+
+func TestLookupRoute_LongestPrefixMatch(t *testing.T) {
+	rt := testRouteTable(
+		testRoute("to-firewall", "0.0.0.0/0", armnetwork.RouteNextHopTypeVirtualAppliance, "10.0.1.4"),
+		testRoute("onprem", "192.168.0.0/16", armnetwork.RouteNextHopTypeVirtualNetworkGateway, ""),
+	)
+	routes := EffectiveRoutes(testRouteInput("userDefinedRouting", rt))
+
+	tests := []struct {
+		destination string
+		source      string
+		nextHop     string
+		prefix      string
+	}{
+		{"10.224.0.10", RouteSourceDefault, "VnetLocal", "10.224.0.0/12"},
+		{"10.0.1.4", RouteSourceDefault, nextHopVNetPeering, "10.0.0.0/16"},
+		{"10.50.0.1", RouteSourceDefault, "None", "10.0.0.0/8"},
+		{"192.168.1.1", RouteSourceUser, "VirtualNetworkGateway", "192.168.0.0/16"},
+		{"52.1.2.3", RouteSourceUser, "VirtualAppliance", "0.0.0.0/0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.destination, func(t *testing.T) {
+			lookup := LookupRoute(routes, netip.MustParseAddr(tt.destination))
+			if lookup.Route == nil || lookup.Route.Source != tt.source || lookup.Route.NextHopType != tt.nextHop || lookup.Route.AddressPrefix != tt.prefix {
+				t.Errorf("Unexpected route for %s: %+v", tt.destination, lookup.Route)
+			}
+			if lookup.Explanation == "" {
+				t.Error("Expected an explanation")
+			}
+		})
+	}
+
+	var invalidated []string
+	for _, route := range routes {
+		if route.State == RouteStateInvalid {
+			invalidated = append(invalidated, route.AddressPrefix)
+		}
+	}
+	if len(invalidated) != 2 || invalidated[0] != "0.0.0.0/0" || invalidated[1] != "192.168.0.0/16" {
+		t.Errorf("Expected the overridden system routes to be invalid, got %v", invalidated)
+	}
+}
+
+func TestAnalyzeRoutes_UserDefinedRouting(t *testing.T) {
+	tests := []struct {
+		name  string
+		input RouteInput
+		want  string
+	}{
+		{"no route table", testRouteInput("userDefinedRouting", nil), "route_table_missing"},
+		{"no default route", testRouteInput("userDefinedRouting", testRouteTable(testRoute("onprem", "192.168.0.0/16", armnetwork.RouteNextHopTypeVirtualNetworkGateway, ""))), "default_route_missing"},
+		{"default route to internet", testRouteInput("userDefinedRouting", testRouteTable(testRoute("egress", "0.0.0.0/0", armnetwork.RouteNextHopTypeInternet, ""))), "default_route_internet"},
+		{"default route dropped", testRouteInput("userDefinedRouting", testRouteTable(testRoute("egress", "0.0.0.0/0", armnetwork.RouteNextHopTypeNone, ""))), "default_route_dropped"},
+		{"appliance outside vnet", testRouteInput("userDefinedRouting", testRouteTable(testRoute("egress", "0.0.0.0/0", armnetwork.RouteNextHopTypeVirtualAppliance, "172.16.0.4"))), "appliance_unreachable"},
+		{"load balancer with appliance", testRouteInput("loadBalancer", testRouteTable(testRoute("egress", "0.0.0.0/0", armnetwork.RouteNextHopTypeVirtualAppliance, "10.0.1.4"))), "default_route_bypasses_outbound_type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, _, findings := AnalyzeRoutes(tt.input)
+			if checks := findingChecks(findings); len(checks) != 1 || checks[0] != tt.want {
+				t.Errorf("Expected only %s, got %v", tt.want, checks)
+			}
+		})
+	}
+
+	_, _, _, findings := AnalyzeRoutes(testRouteInput("userDefinedRouting", testRouteTable(testRoute("egress", "0.0.0.0/0", armnetwork.RouteNextHopTypeVirtualAppliance, "10.0.1.4"))))
+	if len(findings) != 0 {
+		t.Errorf("Expected no findings for a firewall default route, got %+v", findings)
+	}
+}
+
+func TestAnalyzeRoutes_APIServer(t *testing.T) {
+	rt := testRouteTable(
+		testRoute("egress", "0.0.0.0/0", armnetwork.RouteNextHopTypeVirtualAppliance, "10.0.1.4"),
+		testRoute("vnet-via-firewall", "10.224.0.0/12", armnetwork.RouteNextHopTypeVirtualAppliance, "10.0.1.4"),
+	)
+
+	private := testRouteInput("userDefinedRouting", rt)
+	private.APIServer = netip.MustParseAddr("10.224.1.4")
+	private.PrivateAPIServer = true
+	_, _, apiServer, findings := AnalyzeRoutes(private)
+	if apiServer == nil || apiServer.Route == nil || apiServer.Route.Name != "vnet-via-firewall" {
+		t.Fatalf("Unexpected API server route: %+v", apiServer)
+	}
+	if checks := findingChecks(findings); len(checks) != 1 || checks[0] != "asymmetric_api_server_route" {
+		t.Errorf("Expected asymmetric_api_server_route, got %v", checks)
+	}
+
+	public := testRouteInput("userDefinedRouting", testRouteTable(testRoute("egress", "0.0.0.0/0", armnetwork.RouteNextHopTypeVirtualAppliance, "10.0.1.4")))
+	public.APIServer = netip.MustParseAddr("20.1.2.3")
+	public.AuthorizedIPRanges = true
+	_, _, _, findings = AnalyzeRoutes(public)
+	if checks := findingChecks(findings); len(checks) != 1 || checks[0] != "api_server_authorized_ranges" {
+		t.Errorf("Expected api_server_authorized_ranges, got %v", checks)
+	}
+}
+
+func TestEffectiveRoutes_KubenetAndServiceTags(t *testing.T) {
+	input := testRouteInput("loadBalancer", testRouteTable(
+		testRoute("aks-nodepool1-vmss000000", "10.244.0.0/24", armnetwork.RouteNextHopTypeVirtualAppliance, "10.224.0.4"),
+		testRoute("storage", "Storage", armnetwork.RouteNextHopTypeInternet, ""),
+	))
+	input.PodCIDRs = []netip.Prefix{netip.MustParsePrefix("10.244.0.0/16")}
+
+	routes, _, _, findings := AnalyzeRoutes(input)
+	if !strings.Contains(routes[0].Note, "kubenet") || !strings.Contains(routes[1].Note, "Service tag") {
+		t.Errorf("Unexpected route notes: %q, %q", routes[0].Note, routes[1].Note)
+	}
+	if checks := findingChecks(findings); len(checks) != 1 || checks[0] != "service_tag_route" {
+		t.Errorf("Expected service_tag_route, got %v", checks)
+	}
+}
@@ -0,0 +1,195 @@
+package snapshot
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// DefaultMaxChanges limits the number of changes returned by a diff
+const DefaultMaxChanges = 500
+
+// Change kinds
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// Change is a single difference between two snapshots. An empty path means the whole resource.
+type Change struct {
+	Resource string      `json:"resource"`
+	Path     string      `json:"path,omitempty"`
+	Kind     string      `json:"kind"`
+	Before   interface{} `json:"before,omitempty"`
+	After    interface{} `json:"after,omitempty"`
+}
+
+// SnapshotRef identifies one side of a diff
+type SnapshotRef struct {
+	ID         string    `json:"id"`
+	CapturedAt time.Time `json:"captured_at"`
+}
+
+// DiffReport lists what changed between two snapshots of a cluster
+type DiffReport struct {
+	ClusterName      string         `json:"cluster_name"`
+	From             SnapshotRef    `json:"from"`
+	To               SnapshotRef    `json:"to"`
+	Changed          bool           `json:"changed"`
+	ResourcesChanged []string       `json:"resources_changed"`
+	Summary          map[string]int `json:"summary"`
+	Changes          []Change       `json:"changes"`
+	Truncated        bool           `json:"truncated,omitempty"`
+	Notes            []string       `json:"notes,omitempty"`
+}
+
+// Diff compares two snapshots field by field. Array elements that carry a unique "name" are
+// matched by name, so reordering node pools, rules or subnets is not reported as a change.
+func Diff(from, to *Snapshot, maxChanges int) *DiffReport {
+	if maxChanges <= 0 {
+		maxChanges = DefaultMaxChanges
+	}
+	report := &DiffReport{
+		ClusterName:      to.ClusterName,
+		From:             SnapshotRef{ID: from.ID, CapturedAt: from.CapturedAt},
+		To:               SnapshotRef{ID: to.ID, CapturedAt: to.CapturedAt},
+		ResourcesChanged: []string{},
+		Summary:          map[string]int{ChangeAdded: 0, ChangeRemoved: 0, ChangeChanged: 0},
+		Changes:          []Change{},
+	}
+
+	var changes []Change
+	for _, key := range unionKeys(from.Resources, to.Resources) {
+		before, inFrom := from.Resources[key]
+		after, inTo := to.Resources[key]
+		count := len(changes)
+		switch {
+		case !inFrom:
+			// A resource missing because it could not be read is not an addition
+			if _, failed := from.Errors[key]; failed {
+				continue
+			}
+			changes = append(changes, Change{Resource: key, Kind: ChangeAdded})
+		case !inTo:
+			if _, failed := to.Errors[key]; failed {
+				continue
+			}
+			changes = append(changes, Change{Resource: key, Kind: ChangeRemoved})
+		default:
+			diffValues(key, "", before, after, &changes)
+		}
+		if len(changes) > count {
+			report.ResourcesChanged = append(report.ResourcesChanged, key)
+		}
+	}
+
+	for _, change := range changes {
+		report.Summary[change.Kind]++
+	}
+	report.Changed = len(changes) > 0
+	if len(changes) > maxChanges {
+		changes = changes[:maxChanges]
+		report.Truncated = true
+	}
+	report.Changes = append(report.Changes, changes...)
+
+	for _, key := range unionKeys(from.Errors, to.Errors) {
+		if message, ok := from.Errors[key]; ok {
+			report.Notes = append(report.Notes, fmt.Sprintf("%s was not captured in snapshot %s: %s", key, from.ID, message))
+		}
+		if message, ok := to.Errors[key]; ok {
+			report.Notes = append(report.Notes, fmt.Sprintf("%s was not captured in snapshot %s: %s", key, to.ID, message))
+		}
+	}
+	return report
+}
+
+// diffValues appends the differences between two generic JSON values
+func diffValues(resource, path string, before, after interface{}, changes *[]Change) {
+	switch b := before.(type) {
+	case map[string]interface{}:
+		if a, ok := after.(map[string]interface{}); ok {
+			for _, key := range unionKeys(b, a) {
+				childPath := joinPath(path, key)
+				beforeChild, inBefore := b[key]
+				afterChild, inAfter := a[key]
+				switch {
+				case !inBefore:
+					*changes = append(*changes, Change{Resource: resource, Path: childPath, Kind: ChangeAdded, After: afterChild})
+				case !inAfter:
+					*changes = append(*changes, Change{Resource: resource, Path: childPath, Kind: ChangeRemoved, Before: beforeChild})
+				default:
+					diffValues(resource, childPath, beforeChild, afterChild, changes)
+				}
+			}
+			return
+		}
+	case []interface{}:
+		if a, ok := after.([]interface{}); ok {
+			beforeByName, namedBefore := indexByName(b)
+			afterByName, namedAfter := indexByName(a)
+			if namedBefore && namedAfter {
+				diffValues(resource, path, beforeByName, afterByName, changes)
+				return
+			}
+			if len(a) == len(b) {
+				for i := range b {
+					diffValues(resource, fmt.Sprintf("%s[%d]", path, i), b[i], a[i], changes)
+				}
+				return
+			}
+		}
+	}
+
+	if !reflect.DeepEqual(before, after) {
+		*changes = append(*changes, Change{Resource: resource, Path: path, Kind: ChangeChanged, Before: before, After: after})
+	}
+}
+
+// indexByName keys array elements by their name when every element is an object with a unique name.
+// The keys are wrapped in brackets so paths read like properties.agentPoolProfiles[nodepool1].count.
+func indexByName(values []interface{}) (map[string]interface{}, bool) {
+	if len(values) == 0 {
+		return nil, false
+	}
+	indexed := make(map[string]interface{}, len(values))
+	for _, value := range values {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		name, ok := object["name"].(string)
+		if !ok {
+			return nil, false
+		}
+		key := "[" + name + "]"
+		if _, duplicate := indexed[key]; duplicate {
+			return nil, false
+		}
+		indexed[key] = value
+	}
+	return indexed, true
+}
+
+func joinPath(path, key string) string {
+	if path == "" || key[0] == '[' {
+		return path + key
+	}
+	return path + "." + key
+}
+
+func unionKeys[V any](a, b map[string]V) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,126 @@
+package snapshot
+
+import (
+	"testing"
+	"time"
+)
+
+func testSnapshot(id string, resources map[string]interface{}) *Snapshot {
+	capturedAt, _ := time.Parse(idFormat, id)
+	snapshot := newSnapshot("sub-1", "rg-1", "aks-1", capturedAt)
+	for key, resource := range resources {
+		snapshot.add(key, resource, nil)
+	}
+	return snapshot
+}
+
+func TestDiff(t *testing.T) {
+	from := testSnapshot("20261011T090000.000Z", map[string]interface{}{
+		ResourceManagedCluster: map[string]interface{}{
+			"etag": "v1",
+			"properties": map[string]interface{}{
+				"kubernetesVersion": "1.29.4",
+				"agentPoolProfiles": []interface{}{
+					map[string]interface{}{"name": "system", "count": 3},
+					map[string]interface{}{"name": "user", "count": 2},
+				},
+				"apiServerAccessProfile": map[string]interface{}{"authorizedIPRanges": []interface{}{"203.0.113.0/24"}},
+			},
+		},
+		ResourceNSG: map[string]interface{}{"name": "nsg"},
+	})
+	to := testSnapshot("20261018T090000.000Z", map[string]interface{}{
+		ResourceManagedCluster: map[string]interface{}{
+			"etag": "v2",
+			"properties": map[string]interface{}{
+				"kubernetesVersion": "1.30.1",
+				"agentPoolProfiles": []interface{}{
+					map[string]interface{}{"name": "user", "count": 2},
+					map[string]interface{}{"name": "system", "count": 5},
+				},
+				"apiServerAccessProfile": map[string]interface{}{"authorizedIPRanges": []interface{}{"203.0.113.0/24", "198.51.100.0/24"}},
+				"disableLocalAccounts":   true,
+			},
+		},
+		ResourceLoadBalancer + "kubernetes": map[string]interface{}{"name": "kubernetes"},
+	})
+
+	report := Diff(from, to, 0)
+
+	if !report.Changed {
+		t.Fatal("expected changes")
+	}
+	want := map[string]Change{
+		"properties.kubernetesVersion":                         {Kind: ChangeChanged, Before: "1.29.4", After: "1.30.1"},
+		"properties.agentPoolProfiles[system].count":           {Kind: ChangeChanged, Before: float64(3), After: float64(5)},
+		"properties.apiServerAccessProfile.authorizedIPRanges": {Kind: ChangeChanged},
+		"properties.disableLocalAccounts":                      {Kind: ChangeAdded, After: true},
+	}
+	got := make(map[string]Change)
+	for _, change := range report.Changes {
+		if change.Resource == ResourceManagedCluster {
+			got[change.Path] = change
+		}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d managed cluster changes, got %+v", len(want), report.Changes)
+	}
+	for path, expected := range want {
+		change, ok := got[path]
+		if !ok {
+			t.Errorf("missing change at %s", path)
+			continue
+		}
+		if change.Kind != expected.Kind {
+			t.Errorf("%s: expected kind %s, got %s", path, expected.Kind, change.Kind)
+		}
+		if expected.Before != nil && change.Before != expected.Before {
+			t.Errorf("%s: expected before %v, got %v", path, expected.Before, change.Before)
+		}
+		if expected.After != nil && change.After != expected.After {
+			t.Errorf("%s: expected after %v, got %v", path, expected.After, change.After)
+		}
+	}
+
+	if report.Summary[ChangeAdded] != 2 || report.Summary[ChangeRemoved] != 1 || report.Summary[ChangeChanged] != 3 {
+		t.Errorf("unexpected summary: %v", report.Summary)
+	}
+	wantResources := []string{"load_balancers/kubernetes", ResourceManagedCluster, ResourceNSG}
+	if len(report.ResourcesChanged) != len(wantResources) {
+		t.Fatalf("expected resources %v, got %v", wantResources, report.ResourcesChanged)
+	}
+	for i, resource := range wantResources {
+		if report.ResourcesChanged[i] != resource {
+			t.Errorf("expected resources %v, got %v", wantResources, report.ResourcesChanged)
+		}
+	}
+}
+
+func TestDiff_IgnoresCaptureErrors(t *testing.T) {
+	from := testSnapshot("20261011T090000.000Z", map[string]interface{}{ResourceNSG: map[string]interface{}{"name": "nsg"}})
+	to := testSnapshot("20261018T090000.000Z", nil)
+	to.Errors[ResourceNSG] = "failed to get NSG"
+
+	report := Diff(from, to, 0)
+	if report.Changed {
+		t.Fatalf("expected no changes, got %+v", report.Changes)
+	}
+	if len(report.Notes) != 1 {
+		t.Errorf("expected a note about the capture error, got %v", report.Notes)
+	}
+}
+
+func TestDiff_Truncates(t *testing.T) {
+	before := map[string]interface{}{}
+	after := map[string]interface{}{}
+	for _, key := range []string{"a", "b", "c"} {
+		before[key] = 1
+		after[key] = 2
+	}
+	report := Diff(testSnapshot("20261011T090000.000Z", map[string]interface{}{ResourceVNet: before}),
+		testSnapshot("20261018T090000.000Z", map[string]interface{}{ResourceVNet: after}), 2)
+
+	if !report.Truncated || len(report.Changes) != 2 || report.Summary[ChangeChanged] != 3 {
+		t.Errorf("expected 2 of 3 changes with truncation, got %d (truncated=%t, summary=%v)", len(report.Changes), report.Truncated, report.Summary)
+	}
+}
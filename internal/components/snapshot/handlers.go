@@ -0,0 +1,151 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/tools"
+)
+
+// GetClusterSnapshotHandler returns the handler for the cluster_snapshot tool
+func GetClusterSnapshotHandler(azClient *azureclient.AzureClient, cfg *config.ConfigData) tools.ResourceHandler {
+	return tools.ResourceHandlerFunc(func(params map[string]interface{}, _ *config.ConfigData) (string, error) {
+		return HandleClusterSnapshot(params, azClient, cfg)
+	})
+}
+
+// HandleClusterSnapshot captures, lists, shows or diffs cluster snapshots
+func HandleClusterSnapshot(params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
+	operation, ok := params["operation"].(string)
+	if !ok || operation == "" {
+		return "", fmt.Errorf("missing or invalid operation parameter")
+	}
+
+	subscriptionID, resourceGroup, clusterName, err := common.ExtractAKSParameters(params)
+	if err != nil {
+		return "", err
+	}
+
+	dir := cfg.SnapshotDir
+	if dir == "" {
+		if dir, err = DefaultDir(); err != nil {
+			return "", err
+		}
+	}
+	store := NewStore(dir)
+
+	var result interface{}
+	switch operation {
+	case "capture":
+		snapshot, err := capture(azClient, subscriptionID, resourceGroup, clusterName)
+		if err != nil {
+			return "", err
+		}
+		path, err := store.Save(snapshot)
+		if err != nil {
+			return "", err
+		}
+		result = captureResult(snapshot, path)
+	case "list":
+		result, err = store.List(subscriptionID, resourceGroup, clusterName)
+	case "show":
+		ref, _ := params["snapshot_id"].(string)
+		if ref == "" {
+			ref = RefLatest
+		}
+		var id string
+		if id, err = store.Resolve(subscriptionID, resourceGroup, clusterName, ref); err == nil {
+			result, err = store.Load(subscriptionID, resourceGroup, clusterName, id)
+		}
+	case "diff":
+		result, err = diff(params, store, azClient, subscriptionID, resourceGroup, clusterName)
+	default:
+		return "", fmt.Errorf("invalid operation '%s': must be one of capture, list, show, diff", operation)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	out, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal snapshot result: %v", err)
+	}
+	return string(out), nil
+}
+
+// diff compares the snapshots named by the from and to parameters. "to" defaults to the
+// current cluster state, which is captured for the comparison but not saved.
+func diff(params map[string]interface{}, store *Store, azClient *azureclient.AzureClient, subscriptionID, resourceGroup, clusterName string) (*DiffReport, error) {
+	fromRef, _ := params["from"].(string)
+	if fromRef == "" {
+		fromRef = RefLatest
+	}
+	toRef, _ := params["to"].(string)
+	if toRef == "" {
+		toRef = RefCurrent
+	}
+
+	load := func(ref string) (*Snapshot, error) {
+		if ref == RefCurrent {
+			return capture(azClient, subscriptionID, resourceGroup, clusterName)
+		}
+		id, err := store.Resolve(subscriptionID, resourceGroup, clusterName, ref)
+		if err != nil {
+			return nil, err
+		}
+		return store.Load(subscriptionID, resourceGroup, clusterName, id)
+	}
+
+	from, err := load(fromRef)
+	if err != nil {
+		return nil, err
+	}
+	to, err := load(toRef)
+	if err != nil {
+		return nil, err
+	}
+
+	maxChanges := DefaultMaxChanges
+	if value, ok := params["max_changes"].(float64); ok && value > 0 {
+		maxChanges = int(value)
+	}
+
+	report := Diff(from, to, maxChanges)
+	if toRef == RefCurrent {
+		report.Notes = append(report.Notes, "The current cluster state was captured for this comparison and not saved; use the capture operation to keep it")
+	}
+	return report, nil
+}
+
+func capture(azClient *azureclient.AzureClient, subscriptionID, resourceGroup, clusterName string) (*Snapshot, error) {
+	if azClient == nil {
+		return nil, fmt.Errorf("azure client is required but not provided")
+	}
+	return Capture(context.Background(), azClient, subscriptionID, resourceGroup, clusterName, time.Now())
+}
+
+// captureResult summarizes a saved snapshot; use the show operation for the full document
+func captureResult(snapshot *Snapshot, path string) map[string]interface{} {
+	resources := make([]string, 0, len(snapshot.Resources))
+	for key := range snapshot.Resources {
+		resources = append(resources, key)
+	}
+	sort.Strings(resources)
+
+	result := map[string]interface{}{
+		"id":          snapshot.ID,
+		"captured_at": snapshot.CapturedAt,
+		"path":        path,
+		"resources":   resources,
+	}
+	if len(snapshot.Errors) > 0 {
+		result["errors"] = snapshot.Errors
+	}
+	return result
+}
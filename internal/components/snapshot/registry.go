@@ -0,0 +1,53 @@
+package snapshot
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// RegisterClusterSnapshotTool registers the cluster_snapshot tool
+func RegisterClusterSnapshotTool() mcp.Tool {
+	description := `Capture the Azure-side configuration of an AKS cluster into a JSON snapshot stored on the server, and diff snapshots over time.
+
+A snapshot contains the managed cluster, agent pools, VMSS models, VNet, subnet, NSG, route table, load balancers and diagnostic settings.
+
+Operations:
+- capture: take a snapshot and save it
+- list: list the saved snapshots of the cluster
+- show: return a saved snapshot (snapshot_id, default latest)
+- diff: compare two snapshots (from, default latest; to, default current)
+
+Snapshot references are a snapshot ID, "latest", "current" (the live cluster state, only for diff) or an age such as "7d" or "36h", which selects the newest snapshot at least that old.
+
+Example: {"operation": "diff", "from": "7d"} shows what changed in the last week.`
+
+	return mcp.NewTool("cluster_snapshot",
+		mcp.WithDescription(description),
+		mcp.WithString("operation",
+			mcp.Required(),
+			mcp.Description("Operation to perform"),
+			mcp.Enum("capture", "list", "show", "diff"),
+		),
+		mcp.WithString("subscription_id",
+			mcp.Description("Azure Subscription ID (defaults to the active cluster context)"),
+		),
+		mcp.WithString("resource_group",
+			mcp.Description("Azure Resource Group containing the AKS cluster (defaults to the active cluster context)"),
+		),
+		mcp.WithString("cluster_name",
+			mcp.Description("Name of the AKS cluster (defaults to the active cluster context)"),
+		),
+		mcp.WithString("snapshot_id",
+			mcp.Description("Snapshot to show: a snapshot ID, 'latest' or an age such as '7d'"),
+		),
+		mcp.WithString("from",
+			mcp.Description("Older side of a diff (default 'latest')"),
+		),
+		mcp.WithString("to",
+			mcp.Description("Newer side of a diff (default 'current')"),
+		),
+		mcp.WithNumber("max_changes",
+			mcp.Description("Maximum number of changes to return from a diff (default 500)"),
+			mcp.Min(1),
+		),
+	)
+}
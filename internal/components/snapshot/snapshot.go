@@ -0,0 +1,227 @@
+// Package snapshot captures the Azure-side configuration of an AKS cluster into a single
+// JSON document, stores snapshots on local disk and diffs them over time.
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/compute"
+	"github.com/Azure/aks-mcp/internal/components/network/resourcehelpers"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+)
+
+// Resource keys used in a snapshot. Keys ending in "/" are followed by the resource name.
+const (
+	ResourceManagedCluster     = "managed_cluster"
+	ResourceAgentPool          = "agent_pools/"
+	ResourceVMSS               = "vmss/"
+	ResourceVNet               = "vnet"
+	ResourceSubnet             = "subnet"
+	ResourceNSG                = "nsg"
+	ResourceRouteTable         = "route_table"
+	ResourceLoadBalancer       = "load_balancers/"
+	ResourceDiagnosticSettings = "diagnostic_settings/"
+)
+
+// idFormat is the layout of snapshot IDs, which are the UTC capture time
+const idFormat = "20060102T150405.000Z"
+
+// volatileFields are dropped from captured resources because they change without a configuration change
+var volatileFields = map[string]bool{
+	"etag":       true,
+	"systemData": true,
+}
+
+// Snapshot is the Azure-side configuration of a cluster at a point in time
+type Snapshot struct {
+	ID             string    `json:"id"`
+	CapturedAt     time.Time `json:"captured_at"`
+	SubscriptionID string    `json:"subscription_id"`
+	ResourceGroup  string    `json:"resource_group"`
+	ClusterName    string    `json:"cluster_name"`
+	ResourceID     string    `json:"resource_id"`
+	// Resources holds each captured resource as generic JSON, keyed by resource key
+	Resources map[string]interface{} `json:"resources"`
+	// Errors records resources that could not be captured, keyed by resource key
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// newSnapshot creates an empty snapshot captured at the given time
+func newSnapshot(subscriptionID, resourceGroup, clusterName string, capturedAt time.Time) *Snapshot {
+	capturedAt = capturedAt.UTC()
+	return &Snapshot{
+		ID:             capturedAt.Format(idFormat),
+		CapturedAt:     capturedAt,
+		SubscriptionID: subscriptionID,
+		ResourceGroup:  resourceGroup,
+		ClusterName:    clusterName,
+		Resources:      make(map[string]interface{}),
+		Errors:         make(map[string]string),
+	}
+}
+
+// add stores a resource in normalized form, or records why it could not be captured
+func (s *Snapshot) add(key string, resource interface{}, err error) {
+	if err != nil {
+		s.Errors[key] = err.Error()
+		return
+	}
+	normalized, err := normalize(resource)
+	if err != nil {
+		s.Errors[key] = err.Error()
+		return
+	}
+	s.Resources[key] = normalized
+}
+
+// normalize converts an SDK model to generic JSON without volatile fields
+func normalize(resource interface{}) (interface{}, error) {
+	encoded, err := json.Marshal(resource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal resource: %v", err)
+	}
+	var generic interface{}
+	if err := json.Unmarshal(encoded, &generic); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal resource: %v", err)
+	}
+	return stripVolatile(generic), nil
+}
+
+func stripVolatile(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if volatileFields[key] {
+				delete(v, key)
+				continue
+			}
+			v[key] = stripVolatile(child)
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = stripVolatile(child)
+		}
+	}
+	return value
+}
+
+// Capture reads the managed cluster, its agent pools, VMSS models, network resources and
+// diagnostic settings. Only a failure to read the managed cluster is fatal; other resources
+// that cannot be read are recorded in Snapshot.Errors.
+func Capture(ctx context.Context, client *azureclient.AzureClient, subscriptionID, resourceGroup, clusterName string, now time.Time) (*Snapshot, error) {
+	// Snapshots must reflect the current state rather than the resource cache
+	client.InvalidateAKSCluster(subscriptionID, resourceGroup, clusterName)
+	cluster, err := client.GetAKSCluster(ctx, subscriptionID, resourceGroup, clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get AKS cluster: %v", err)
+	}
+
+	snapshot := newSnapshot(subscriptionID, resourceGroup, clusterName, now)
+	if cluster.ID != nil {
+		snapshot.ResourceID = *cluster.ID
+	}
+	snapshot.add(ResourceManagedCluster, cluster, nil)
+
+	clients, err := client.GetOrCreateClientsForSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	pager := clients.AgentPoolsClient.NewListPager(resourceGroup, clusterName, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			snapshot.add(ResourceAgentPool+"*", nil, fmt.Errorf("failed to list agent pools: %v", err))
+			break
+		}
+		for _, pool := range page.Value {
+			if pool != nil && pool.Name != nil {
+				snapshot.add(ResourceAgentPool+*pool.Name, pool, nil)
+			}
+		}
+	}
+
+	nodePools, err := compute.GetNodePoolsFromAKS(ctx, cluster, client)
+	if err != nil {
+		snapshot.add(ResourceVMSS+"*", nil, err)
+	}
+	for _, pool := range nodePools {
+		if pool == nil || pool.Name == nil {
+			continue
+		}
+		vmssID, err := compute.GetVMSSIDFromNodePool(ctx, cluster, *pool.Name, client)
+		if err != nil {
+			snapshot.add(ResourceVMSS+*pool.Name, nil, err)
+			continue
+		}
+		snapshot.addByID(ctx, client, ResourceVMSS+*pool.Name, vmssID)
+	}
+
+	if vnetID, err := resourcehelpers.GetVNetIDFromAKS(ctx, cluster, client); err != nil {
+		snapshot.add(ResourceVNet, nil, err)
+	} else {
+		snapshot.addByID(ctx, client, ResourceVNet, vnetID)
+	}
+
+	if subnetID, err := resourcehelpers.GetSubnetIDFromAKS(ctx, cluster, client); err != nil {
+		snapshot.add(ResourceSubnet, nil, err)
+	} else {
+		snapshot.addByID(ctx, client, ResourceSubnet, subnetID)
+	}
+
+	if nsgID, err := resourcehelpers.GetNSGIDFromAKS(ctx, cluster, client); err != nil {
+		snapshot.add(ResourceNSG, nil, err)
+	} else {
+		snapshot.addByID(ctx, client, ResourceNSG, nsgID)
+	}
+
+	// An empty route table ID means no route table is attached, which is a valid state
+	if routeTableID, err := resourcehelpers.GetRouteTableIDFromAKS(ctx, cluster, client); err != nil {
+		snapshot.add(ResourceRouteTable, nil, err)
+	} else if routeTableID != "" {
+		snapshot.addByID(ctx, client, ResourceRouteTable, routeTableID)
+	}
+
+	if lbIDs, err := resourcehelpers.GetLoadBalancerIDsFromAKS(ctx, cluster, client); err != nil {
+		snapshot.add(ResourceLoadBalancer+"*", nil, err)
+	} else {
+		for _, lbID := range lbIDs {
+			key := ResourceLoadBalancer + resourceName(lbID)
+			snapshot.addByID(ctx, client, key, lbID)
+		}
+	}
+
+	if snapshot.ResourceID != "" {
+		settings, err := client.GetDiagnosticSettings(ctx, subscriptionID, snapshot.ResourceID)
+		if err != nil {
+			snapshot.add(ResourceDiagnosticSettings+"*", nil, err)
+		}
+		for _, setting := range settings {
+			if setting != nil && setting.Name != nil {
+				snapshot.add(ResourceDiagnosticSettings+*setting.Name, setting, nil)
+			}
+		}
+	}
+
+	return snapshot, nil
+}
+
+// addByID reads a resource by ID and adds it to the snapshot
+func (s *Snapshot) addByID(ctx context.Context, client *azureclient.AzureClient, key, resourceID string) {
+	resource, err := client.GetResourceByID(ctx, resourceID)
+	if err != nil {
+		err = fmt.Errorf("failed to get %s: %v", resourceID, err)
+	}
+	s.add(key, resource, err)
+}
+
+// resourceName returns the last segment of a resource ID
+func resourceName(resourceID string) string {
+	if parsed, err := arm.ParseResourceID(resourceID); err == nil {
+		return parsed.Name
+	}
+	return resourceID
+}
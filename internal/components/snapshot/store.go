@@ -0,0 +1,192 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Snapshot references accepted by Store.Resolve in addition to snapshot IDs
+const (
+	RefLatest  = "latest"
+	RefCurrent = "current"
+)
+
+var (
+	// pathSegmentPattern restricts the subscription, resource group and cluster names used as directories
+	pathSegmentPattern = regexp.MustCompile(`^[A-Za-z0-9._()-]+$`)
+	idPattern          = regexp.MustCompile(`^\d{8}T\d{6}\.\d{3}Z$`)
+	daysPattern        = regexp.MustCompile(`^(\d+)d$`)
+)
+
+// Summary describes a stored snapshot without loading it
+type Summary struct {
+	ID         string    `json:"id"`
+	CapturedAt time.Time `json:"captured_at"`
+	Path       string    `json:"path"`
+}
+
+// Store keeps snapshots as JSON files under <dir>/<subscription>/<resource group>/<cluster>/<id>.json
+type Store struct {
+	dir string
+	now func() time.Time
+}
+
+// NewStore creates a store rooted at dir
+func NewStore(dir string) *Store {
+	return &Store{dir: dir, now: time.Now}
+}
+
+// DefaultDir returns the directory used when --snapshot-dir is not set
+func DefaultDir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine snapshot directory, set --snapshot-dir: %v", err)
+	}
+	return filepath.Join(configDir, "aks-mcp", "snapshots"), nil
+}
+
+// clusterDir returns the directory holding a cluster's snapshots
+func (s *Store) clusterDir(subscriptionID, resourceGroup, clusterName string) (string, error) {
+	segments := []string{subscriptionID, resourceGroup, clusterName}
+	for _, segment := range segments {
+		if !pathSegmentPattern.MatchString(segment) || segment == "." || segment == ".." {
+			return "", fmt.Errorf("invalid name for a snapshot path: '%s'", segment)
+		}
+	}
+	return filepath.Join(s.dir, strings.ToLower(subscriptionID), strings.ToLower(resourceGroup), strings.ToLower(clusterName)), nil
+}
+
+// Save writes a snapshot and returns its path
+func (s *Store) Save(snapshot *Snapshot) (string, error) {
+	dir, err := s.clusterDir(snapshot.SubscriptionID, snapshot.ResourceGroup, snapshot.ClusterName)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create snapshot directory: %v", err)
+	}
+
+	encoded, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal snapshot: %v", err)
+	}
+	path := filepath.Join(dir, snapshot.ID+".json")
+	if err := os.WriteFile(path, encoded, 0o600); err != nil {
+		return "", fmt.Errorf("failed to write snapshot: %v", err)
+	}
+	return path, nil
+}
+
+// List returns the stored snapshots of a cluster, oldest first
+func (s *Store) List(subscriptionID, resourceGroup, clusterName string) ([]Summary, error) {
+	dir, err := s.clusterDir(subscriptionID, resourceGroup, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return []Summary{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot directory: %v", err)
+	}
+
+	summaries := []Summary{}
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if entry.IsDir() || !ok || !idPattern.MatchString(id) {
+			continue
+		}
+		capturedAt, err := time.Parse(idFormat, id)
+		if err != nil {
+			continue
+		}
+		summaries = append(summaries, Summary{ID: id, CapturedAt: capturedAt, Path: filepath.Join(dir, entry.Name())})
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].CapturedAt.Before(summaries[j].CapturedAt)
+	})
+	return summaries, nil
+}
+
+// Load reads a stored snapshot by ID
+func (s *Store) Load(subscriptionID, resourceGroup, clusterName, id string) (*Snapshot, error) {
+	if !idPattern.MatchString(id) {
+		return nil, fmt.Errorf("invalid snapshot ID '%s'", id)
+	}
+	dir, err := s.clusterDir(subscriptionID, resourceGroup, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := os.ReadFile(filepath.Join(dir, id+".json"))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("snapshot '%s' not found for cluster %s", id, clusterName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %v", err)
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(encoded, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot '%s': %v", id, err)
+	}
+	return &snapshot, nil
+}
+
+// Resolve finds the ID of a stored snapshot from a reference: a snapshot ID, "latest", or an
+// age such as "7d" or "36h", which selects the newest snapshot at least that old
+func (s *Store) Resolve(subscriptionID, resourceGroup, clusterName, ref string) (string, error) {
+	if idPattern.MatchString(ref) {
+		return ref, nil
+	}
+
+	summaries, err := s.List(subscriptionID, resourceGroup, clusterName)
+	if err != nil {
+		return "", err
+	}
+	if len(summaries) == 0 {
+		return "", fmt.Errorf("no snapshots stored for cluster %s; capture one first", clusterName)
+	}
+	if ref == RefLatest {
+		return summaries[len(summaries)-1].ID, nil
+	}
+
+	age, err := parseAge(ref)
+	if err != nil {
+		return "", fmt.Errorf("invalid snapshot reference '%s': use a snapshot ID, '%s' or an age such as '7d' or '36h'", ref, RefLatest)
+	}
+	cutoff := s.now().Add(-age)
+	for i := len(summaries) - 1; i >= 0; i-- {
+		if !summaries[i].CapturedAt.After(cutoff) {
+			return summaries[i].ID, nil
+		}
+	}
+	return "", fmt.Errorf("no snapshot of cluster %s is older than %s; the oldest was captured at %s",
+		clusterName, ref, summaries[0].CapturedAt.Format(time.RFC3339))
+}
+
+// parseAge parses a Go duration or a number of days such as "7d"
+func parseAge(ref string) (time.Duration, error) {
+	if match := daysPattern.FindStringSubmatch(ref); match != nil {
+		days, err := strconv.Atoi(match[1])
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	age, err := time.ParseDuration(ref)
+	if err != nil {
+		return 0, err
+	}
+	if age < 0 {
+		return 0, fmt.Errorf("age must not be negative")
+	}
+	return age, nil
+}
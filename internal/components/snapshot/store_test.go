@@ -0,0 +1,127 @@
+package snapshot
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStore_SaveListLoad(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	older := testSnapshot("20261004T090000.000Z", map[string]interface{}{ResourceVNet: map[string]interface{}{"name": "vnet"}})
+	newer := testSnapshot("20261017T090000.000Z", map[string]interface{}{ResourceVNet: map[string]interface{}{"name": "vnet"}})
+	for _, snapshot := range []*Snapshot{newer, older} {
+		path, err := store.Save(snapshot)
+		if err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("snapshot file missing: %v", err)
+		}
+		if info.Mode().Perm() != 0o600 {
+			t.Errorf("expected snapshot file mode 0600, got %v", info.Mode().Perm())
+		}
+	}
+
+	summaries, err := store.List("SUB-1", "rg-1", "aks-1")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(summaries) != 2 || summaries[0].ID != older.ID || summaries[1].ID != newer.ID {
+		t.Fatalf("expected snapshots oldest first, got %+v", summaries)
+	}
+
+	loaded, err := store.Load("sub-1", "rg-1", "aks-1", newer.ID)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded.ClusterName != "aks-1" || loaded.Resources[ResourceVNet] == nil {
+		t.Errorf("unexpected snapshot: %+v", loaded)
+	}
+
+	if summaries, err := store.List("sub-1", "rg-1", "other"); err != nil || len(summaries) != 0 {
+		t.Errorf("expected no snapshots for another cluster, got %v, %v", summaries, err)
+	}
+}
+
+func TestStore_Resolve(t *testing.T) {
+	store := NewStore(t.TempDir())
+	store.now = func() time.Time { return time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC) }
+
+	for _, id := range []string{"20261004T090000.000Z", "20261010T090000.000Z", "20261017T090000.000Z"} {
+		if _, err := store.Save(testSnapshot(id, nil)); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		ref     string
+		want    string
+		wantErr string
+	}{
+		{ref: "latest", want: "20261017T090000.000Z"},
+		{ref: "7d", want: "20261010T090000.000Z"},
+		{ref: "24h", want: "20261017T090000.000Z"},
+		{ref: "20261004T090000.000Z", want: "20261004T090000.000Z"},
+		{ref: "30d", wantErr: "no snapshot of cluster aks-1 is older than 30d"},
+		{ref: "last week", wantErr: "invalid snapshot reference"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, err := store.Resolve("sub-1", "rg-1", "aks-1", tt.ref)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestStore_RejectsPathTraversal(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	if _, err := store.List("sub-1", "..", "aks-1"); err == nil {
+		t.Error("expected an error for a resource group of '..'")
+	}
+	if _, err := store.Load("sub-1", "rg-1", "aks-1", "../../etc/passwd"); err == nil {
+		t.Error("expected an error for an invalid snapshot ID")
+	}
+	if _, err := store.Save(newSnapshot("sub-1", "rg/1", "aks-1", time.Now())); err == nil {
+		t.Error("expected an error for a resource group containing a path separator")
+	}
+}
+
+func TestNormalize_StripsVolatileFields(t *testing.T) {
+	normalized, err := normalize(map[string]interface{}{
+		"etag":       "W/1",
+		"systemData": map[string]interface{}{"lastModifiedAt": "2026-10-18"},
+		"properties": map[string]interface{}{
+			"subnets": []interface{}{map[string]interface{}{"name": "aks-subnet", "etag": "W/2"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("normalize failed: %v", err)
+	}
+	resource := normalized.(map[string]interface{})
+	if _, ok := resource["etag"]; ok {
+		t.Error("expected etag to be removed")
+	}
+	if _, ok := resource["systemData"]; ok {
+		t.Error("expected systemData to be removed")
+	}
+	subnet := resource["properties"].(map[string]interface{})["subnets"].([]interface{})[0].(map[string]interface{})
+	if _, ok := subnet["etag"]; ok {
+		t.Error("expected nested etag to be removed")
+	}
+}
@@ -0,0 +1,324 @@
+package upgrade
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/components/monitor/diagnostics"
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/k8s"
+	"github.com/Azure/aks-mcp/internal/tools"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	"github.com/Azure/mcp-kubernetes/pkg/kubectl"
+)
+
+// Audit log lookback limits, in days
+const (
+	DefaultAuditLookbackDays = 7
+	MaxAuditLookbackDays     = 30
+)
+
+// GetUpgradePlanHandler returns the handler for the aks_upgrade_plan tool
+func GetUpgradePlanHandler(azClient *azureclient.AzureClient, cfg *config.ConfigData) tools.ResourceHandler {
+	return tools.ResourceHandlerFunc(func(params map[string]interface{}, _ *config.ConfigData) (string, error) {
+		return HandleUpgradePlan(params, azClient, cfg)
+	})
+}
+
+// HandleUpgradePlan collects the cluster state relevant to an upgrade and returns the plan
+func HandleUpgradePlan(params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
+	subscriptionID, resourceGroup, clusterName, err := common.ExtractAKSParameters(params)
+	if err != nil {
+		return "", err
+	}
+	if azClient == nil {
+		return "", fmt.Errorf("azure client is required but not provided")
+	}
+
+	ctx := context.Background()
+	cluster, err := azClient.GetAKSCluster(ctx, subscriptionID, resourceGroup, clusterName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get AKS cluster: %w", err)
+	}
+	if cluster.Properties == nil {
+		return "", fmt.Errorf("cluster %s has no properties", clusterName)
+	}
+
+	clients, err := azClient.GetOrCreateClientsForSubscription(subscriptionID)
+	if err != nil {
+		return "", err
+	}
+
+	input := PlanInput{
+		SubscriptionID:   subscriptionID,
+		ResourceGroup:    resourceGroup,
+		ClusterName:      clusterName,
+		ResourceID:       derefString(cluster.ID),
+		CurrentVersion:   derefString(cluster.Properties.CurrentKubernetesVersion),
+		RequestedVersion: stringParam(params, "target_version"),
+	}
+
+	profile, err := clients.ContainerServiceClient.GetUpgradeProfile(ctx, resourceGroup, clusterName, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to get upgrade profile: %w", err)
+	}
+	input.AvailableUpgrades = availableUpgrades(profile.ManagedClusterUpgradeProfile)
+
+	for _, pool := range cluster.Properties.AgentPoolProfiles {
+		if pool == nil || pool.Name == nil {
+			continue
+		}
+		state := nodePoolState(pool)
+		poolProfile, err := clients.AgentPoolsClient.GetUpgradeProfile(ctx, resourceGroup, clusterName, state.Name, nil)
+		if err != nil {
+			input.Notes = append(input.Notes, fmt.Sprintf("Could not read the node image upgrade profile of %s: %v", state.Name, err))
+		} else if poolProfile.Properties != nil {
+			state.LatestNodeImageVersion = derefString(poolProfile.Properties.LatestNodeImageVersion)
+		}
+		input.NodePools = append(input.NodePools, state)
+	}
+
+	lookback := time.Duration(getAuditLookbackDays(params)) * 24 * time.Hour
+	usage, category, err := diagnostics.QueryDeprecatedAPIUsage(subscriptionID, resourceGroup, clusterName, lookback, azClient, cfg)
+	switch {
+	case err != nil:
+		input.Notes = append(input.Notes, fmt.Sprintf("Deprecated API usage was not checked: %v", err))
+	case category == "kube-audit-admin":
+		input.Notes = append(input.Notes, "Deprecated API usage comes from kube-audit-admin, which omits read requests. Enable kube-audit for complete results.")
+		input.DeprecatedAPIUsage = usage
+	default:
+		input.DeprecatedAPIUsage = usage
+	}
+
+	if includePDBs, ok := params["include_pdbs"].(bool); !ok || includePDBs {
+		run := newKubectlRunner(cfg)
+		kubeContext, err := clusterKubeContext(run, clusterFQDNs(cluster))
+		var pdbs []BlockingPDB
+		if err == nil {
+			pdbs, err = listBlockingPDBs(run, kubeContext)
+		}
+		if err != nil {
+			input.Notes = append(input.Notes, fmt.Sprintf("PodDisruptionBudgets were not checked: %v", err))
+		} else {
+			input.BlockingPDBs = pdbs
+			input.Notes = append(input.Notes, fmt.Sprintf("PodDisruptionBudgets were read from kubeconfig context %s, which points to the cluster's API server", kubeContext))
+		}
+	}
+
+	plan, err := BuildPlan(input)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal upgrade plan: %w", err)
+	}
+	return string(result), nil
+}
+
+// availableUpgrades returns the control plane upgrades from an upgrade profile
+func availableUpgrades(profile armcontainerservice.ManagedClusterUpgradeProfile) []AvailableUpgrade {
+	upgrades := []AvailableUpgrade{}
+	if profile.Properties == nil || profile.Properties.ControlPlaneProfile == nil {
+		return upgrades
+	}
+	for _, upgrade := range profile.Properties.ControlPlaneProfile.Upgrades {
+		if upgrade == nil || upgrade.KubernetesVersion == nil {
+			continue
+		}
+		upgrades = append(upgrades, AvailableUpgrade{
+			Version:   *upgrade.KubernetesVersion,
+			IsPreview: upgrade.IsPreview != nil && *upgrade.IsPreview,
+		})
+	}
+	return upgrades
+}
+
+// nodePoolState extracts the upgrade-relevant fields of a node pool
+func nodePoolState(pool *armcontainerservice.ManagedClusterAgentPoolProfile) NodePoolState {
+	state := NodePoolState{
+		Name:             derefString(pool.Name),
+		Version:          derefString(pool.CurrentOrchestratorVersion),
+		NodeImageVersion: derefString(pool.NodeImageVersion),
+	}
+	if state.Version == "" {
+		state.Version = derefString(pool.OrchestratorVersion)
+	}
+	if pool.Mode != nil {
+		state.Mode = string(*pool.Mode)
+	}
+	if pool.Count != nil {
+		state.NodeCount = *pool.Count
+	}
+	if pool.UpgradeSettings != nil {
+		state.MaxSurge = derefString(pool.UpgradeSettings.MaxSurge)
+	}
+	return state
+}
+
+// kubectlRunner runs a read-only kubectl command and returns its output
+type kubectlRunner func(command string) (string, error)
+
+// newKubectlRunner runs kubectl through the mcp-kubernetes executor, which applies the security settings
+func newKubectlRunner(cfg *config.ConfigData) kubectlRunner {
+	executor := k8s.WrapK8sExecutor(kubectl.NewExecutor())
+	return func(command string) (string, error) {
+		return executor.Execute(map[string]interface{}{"command": command}, cfg)
+	}
+}
+
+// podDisruptionBudgetList holds the fields of `kubectl get pdb -o json` used by the planner
+type podDisruptionBudgetList struct {
+	Items []struct {
+		Metadata struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"metadata"`
+		Spec struct {
+			MinAvailable   interface{} `json:"minAvailable"`
+			MaxUnavailable interface{} `json:"maxUnavailable"`
+		} `json:"spec"`
+		Status struct {
+			DisruptionsAllowed int32 `json:"disruptionsAllowed"`
+			CurrentHealthy     int32 `json:"currentHealthy"`
+			ExpectedPods       int32 `json:"expectedPods"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+// kubeconfigView holds the fields of `kubectl config view -o json` used to find the context of a cluster
+type kubeconfigView struct {
+	CurrentContext string `json:"current-context"`
+	Clusters       []struct {
+		Name    string `json:"name"`
+		Cluster struct {
+			Server string `json:"server"`
+		} `json:"cluster"`
+	} `json:"clusters"`
+	Contexts []struct {
+		Name    string `json:"name"`
+		Context struct {
+			Cluster string `json:"cluster"`
+		} `json:"context"`
+	} `json:"contexts"`
+}
+
+// clusterFQDNs returns the API server host names of a cluster
+func clusterFQDNs(cluster *armcontainerservice.ManagedCluster) []string {
+	var fqdns []string
+	for _, fqdn := range []*string{cluster.Properties.Fqdn, cluster.Properties.PrivateFQDN, cluster.Properties.AzurePortalFQDN} {
+		if value := derefString(fqdn); value != "" {
+			fqdns = append(fqdns, value)
+		}
+	}
+	return fqdns
+}
+
+// clusterKubeContext returns the kubeconfig context whose API server is the cluster, preferring the
+// current context, so kubectl never reads another cluster
+func clusterKubeContext(run kubectlRunner, fqdns []string) (string, error) {
+	if len(fqdns) == 0 {
+		return "", fmt.Errorf("the cluster has no API server FQDN to match a kubeconfig context against")
+	}
+	output, err := run("config view -o json")
+	if err != nil {
+		return "", fmt.Errorf("failed to read kubeconfig: %w", err)
+	}
+	var view kubeconfigView
+	if err := json.Unmarshal([]byte(output), &view); err != nil {
+		return "", fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+
+	matches := map[string]bool{}
+	for _, cluster := range view.Clusters {
+		server, err := url.Parse(cluster.Cluster.Server)
+		if err != nil {
+			continue
+		}
+		for _, fqdn := range fqdns {
+			if strings.EqualFold(server.Hostname(), fqdn) {
+				matches[cluster.Name] = true
+			}
+		}
+	}
+	var candidates []string
+	for _, context := range view.Contexts {
+		if !matches[context.Context.Cluster] || strings.ContainsAny(context.Name, " \t") {
+			continue
+		}
+		if context.Name == view.CurrentContext {
+			return context.Name, nil
+		}
+		candidates = append(candidates, context.Name)
+	}
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("no kubeconfig context points to the cluster API server (%s); current context is %q. Run az aks get-credentials for the cluster", strings.Join(fqdns, ", "), view.CurrentContext)
+	}
+	return candidates[0], nil
+}
+
+// listBlockingPDBs returns the PodDisruptionBudgets of a kubeconfig context that currently allow no disruptions
+func listBlockingPDBs(run kubectlRunner, kubeContext string) ([]BlockingPDB, error) {
+	output, err := run("get poddisruptionbudgets --all-namespaces -o json --context " + kubeContext)
+	if err != nil {
+		return nil, err
+	}
+
+	var list podDisruptionBudgetList
+	if err := json.Unmarshal([]byte(output), &list); err != nil {
+		return nil, fmt.Errorf("failed to parse PodDisruptionBudgets: %w", err)
+	}
+
+	blocking := []BlockingPDB{}
+	for _, item := range list.Items {
+		// Budgets that select no pods never block a drain
+		if item.Status.DisruptionsAllowed > 0 || item.Status.ExpectedPods == 0 {
+			continue
+		}
+		pdb := BlockingPDB{
+			Namespace:      item.Metadata.Namespace,
+			Name:           item.Metadata.Name,
+			CurrentHealthy: item.Status.CurrentHealthy,
+			ExpectedPods:   item.Status.ExpectedPods,
+		}
+		if item.Spec.MinAvailable != nil {
+			pdb.MinAvailable = fmt.Sprint(item.Spec.MinAvailable)
+		}
+		if item.Spec.MaxUnavailable != nil {
+			pdb.MaxUnavailable = fmt.Sprint(item.Spec.MaxUnavailable)
+		}
+		blocking = append(blocking, pdb)
+	}
+	return blocking, nil
+}
+
+// getAuditLookbackDays returns how many days of audit logs to search
+func getAuditLookbackDays(params map[string]interface{}) int {
+	days, ok := params["audit_lookback_days"].(float64)
+	if !ok || days < 1 {
+		return DefaultAuditLookbackDays
+	}
+	if days > MaxAuditLookbackDays {
+		return MaxAuditLookbackDays
+	}
+	return int(days)
+}
+
+func stringParam(params map[string]interface{}, name string) string {
+	value, _ := params[name].(string)
+	return value
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
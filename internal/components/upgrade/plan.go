@@ -0,0 +1,421 @@
+package upgrade
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/aks-mcp/internal/components/monitor/diagnostics"
+)
+
+// Risk severities
+const (
+	SeverityHigh   = "high"
+	SeverityMedium = "medium"
+	SeverityLow    = "low"
+)
+
+// AvailableUpgrade is a Kubernetes version the control plane can be upgraded to
+type AvailableUpgrade struct {
+	Version   string `json:"version"`
+	IsPreview bool   `json:"is_preview,omitempty"`
+}
+
+// NodePoolState is the upgrade-relevant state of a node pool
+type NodePoolState struct {
+	Name                   string `json:"name"`
+	Mode                   string `json:"mode"`
+	Version                string `json:"version"`
+	NodeCount              int32  `json:"node_count"`
+	MaxSurge               string `json:"max_surge,omitempty"`
+	NodeImageVersion       string `json:"node_image_version,omitempty"`
+	LatestNodeImageVersion string `json:"latest_node_image_version,omitempty"`
+}
+
+// NodeImageOutdated reports whether a newer node image is available for the pool
+func (p NodePoolState) NodeImageOutdated() bool {
+	return p.LatestNodeImageVersion != "" && p.NodeImageVersion != "" && p.NodeImageVersion != p.LatestNodeImageVersion
+}
+
+// BlockingPDB is a PodDisruptionBudget that currently allows no disruptions, so node drains stall on it
+type BlockingPDB struct {
+	Namespace      string `json:"namespace"`
+	Name           string `json:"name"`
+	MinAvailable   string `json:"min_available,omitempty"`
+	MaxUnavailable string `json:"max_unavailable,omitempty"`
+	CurrentHealthy int32  `json:"current_healthy"`
+	ExpectedPods   int32  `json:"expected_pods"`
+}
+
+// PlanInput is everything the planner knows about the cluster
+type PlanInput struct {
+	SubscriptionID    string
+	ResourceGroup     string
+	ClusterName       string
+	ResourceID        string
+	CurrentVersion    string
+	RequestedVersion  string
+	AvailableUpgrades []AvailableUpgrade
+	NodePools         []NodePoolState
+	// DeprecatedAPIUsage is nil when audit logs could not be read
+	DeprecatedAPIUsage []diagnostics.DeprecatedAPIUsage
+	// BlockingPDBs is nil when PodDisruptionBudgets could not be read
+	BlockingPDBs []BlockingPDB
+	Notes        []string
+}
+
+// Risk is something that can make the upgrade fail or disrupt workloads
+type Risk struct {
+	Severity    string `json:"severity"`
+	Category    string `json:"category"`
+	Resource    string `json:"resource,omitempty"`
+	Description string `json:"description"`
+}
+
+// Step is one action of the upgrade plan
+type Step struct {
+	Order       int                    `json:"order"`
+	Title       string                 `json:"title"`
+	Description string                 `json:"description"`
+	Tool        string                 `json:"tool,omitempty"`
+	Arguments   map[string]interface{} `json:"arguments,omitempty"`
+	Command     string                 `json:"command,omitempty"`
+	// Blocking steps must be completed before the following steps are safe to run
+	Blocking bool `json:"blocking,omitempty"`
+}
+
+// Plan is the result of aks_upgrade_plan
+type Plan struct {
+	ClusterName        string                           `json:"cluster_name"`
+	ResourceID         string                           `json:"resource_id"`
+	CurrentVersion     string                           `json:"current_version"`
+	TargetVersion      string                           `json:"target_version,omitempty"`
+	AvailableUpgrades  []AvailableUpgrade               `json:"available_upgrades"`
+	NodePools          []NodePoolState                  `json:"node_pools"`
+	DeprecatedAPIUsage []diagnostics.DeprecatedAPIUsage `json:"deprecated_api_usage,omitempty"`
+	BlockingPDBs       []BlockingPDB                    `json:"blocking_pdbs,omitempty"`
+	Risks              []Risk                           `json:"risks"`
+	Steps              []Step                           `json:"steps"`
+	Notes              []string                         `json:"notes,omitempty"`
+}
+
+// BuildPlan turns the collected cluster state into an ordered upgrade plan
+func BuildPlan(input PlanInput) (*Plan, error) {
+	plan := &Plan{
+		ClusterName:        input.ClusterName,
+		ResourceID:         input.ResourceID,
+		CurrentVersion:     input.CurrentVersion,
+		AvailableUpgrades:  input.AvailableUpgrades,
+		NodePools:          input.NodePools,
+		DeprecatedAPIUsage: input.DeprecatedAPIUsage,
+		BlockingPDBs:       input.BlockingPDBs,
+		Risks:              []Risk{},
+		Steps:              []Step{},
+		Notes:              input.Notes,
+	}
+
+	target, err := selectTargetVersion(input)
+	if err != nil {
+		return nil, err
+	}
+	plan.TargetVersion = target
+
+	if target == "" {
+		plan.Notes = append(plan.Notes, fmt.Sprintf("No Kubernetes upgrade is available from %s", input.CurrentVersion))
+		addNodeImageSteps(plan, input)
+		return plan, nil
+	}
+
+	addVersionRisks(plan, input, target)
+	addDeprecatedAPIRisks(plan, input, target)
+	addPDBRisks(plan, input)
+	addUpgradeSteps(plan, input, target)
+
+	if !isNextMinor(input.CurrentVersion, target) && compareVersions(target, input.CurrentVersion) > 0 {
+		plan.Notes = append(plan.Notes, "The target skips a minor version; AKS only allows this when upgrading from an unsupported version")
+	}
+	if latest := latestVersion(input.AvailableUpgrades, false); latest != "" && compareVersions(latest, target) > 0 {
+		plan.Notes = append(plan.Notes, fmt.Sprintf("Newer versions are available after this upgrade (up to %s). Run aks_upgrade_plan again once it completes.", latest))
+	}
+	return plan, nil
+}
+
+// selectTargetVersion returns the requested version, or the newest generally available upgrade
+func selectTargetVersion(input PlanInput) (string, error) {
+	if input.RequestedVersion == "" {
+		return latestVersion(input.AvailableUpgrades, false), nil
+	}
+
+	for _, upgrade := range input.AvailableUpgrades {
+		if upgrade.Version == input.RequestedVersion {
+			return upgrade.Version, nil
+		}
+	}
+
+	available := make([]string, 0, len(input.AvailableUpgrades))
+	for _, upgrade := range input.AvailableUpgrades {
+		available = append(available, upgrade.Version)
+	}
+	if len(available) == 0 {
+		return "", fmt.Errorf("cannot upgrade to %s: no upgrades are available from %s", input.RequestedVersion, input.CurrentVersion)
+	}
+	return "", fmt.Errorf("cannot upgrade from %s to %s. Available upgrades: %s", input.CurrentVersion, input.RequestedVersion, strings.Join(available, ", "))
+}
+
+// latestVersion returns the newest version, skipping previews unless includePreview is set
+func latestVersion(upgrades []AvailableUpgrade, includePreview bool) string {
+	latest := ""
+	for _, upgrade := range upgrades {
+		if upgrade.IsPreview && !includePreview {
+			continue
+		}
+		if latest == "" || compareVersions(upgrade.Version, latest) > 0 {
+			latest = upgrade.Version
+		}
+	}
+	return latest
+}
+
+func addVersionRisks(plan *Plan, input PlanInput, target string) {
+	for _, upgrade := range input.AvailableUpgrades {
+		if upgrade.Version == target && upgrade.IsPreview {
+			plan.Risks = append(plan.Risks, Risk{
+				Severity:    SeverityMedium,
+				Category:    "preview_version",
+				Description: fmt.Sprintf("%s is a preview version and is not covered by the AKS SLA", target),
+			})
+		}
+	}
+
+	for _, pool := range input.NodePools {
+		if pool.Version != "" && compareVersions(pool.Version, input.CurrentVersion) < 0 {
+			plan.Risks = append(plan.Risks, Risk{
+				Severity:    SeverityMedium,
+				Category:    "version_skew",
+				Resource:    pool.Name,
+				Description: fmt.Sprintf("Node pool %s runs %s, behind the control plane (%s). Upgrading the control plane widens the skew until the pool is upgraded.", pool.Name, pool.Version, input.CurrentVersion),
+			})
+		}
+		if pool.MaxSurge == "" && pool.NodeCount > 1 {
+			plan.Risks = append(plan.Risks, Risk{
+				Severity:    SeverityLow,
+				Category:    "surge",
+				Resource:    pool.Name,
+				Description: fmt.Sprintf("Node pool %s has no max surge configured, so nodes are upgraded one at a time", pool.Name),
+			})
+		}
+	}
+}
+
+func addDeprecatedAPIRisks(plan *Plan, input PlanInput, target string) {
+	if input.DeprecatedAPIUsage == nil {
+		plan.Risks = append(plan.Risks, Risk{
+			Severity:    SeverityMedium,
+			Category:    "deprecated_api",
+			Description: "Deprecated API usage could not be checked because audit logs are not available. Check clients for APIs removed in " + minorString(target) + " before upgrading.",
+		})
+		return
+	}
+
+	for _, usage := range input.DeprecatedAPIUsage {
+		resource := fmt.Sprintf("%s %s", usage.GroupVersion(), usage.Resource)
+		if usage.RemovedRelease != "" && compareVersions(target, usage.RemovedRelease) >= 0 {
+			plan.Risks = append(plan.Risks, Risk{
+				Severity: SeverityHigh,
+				Category: "removed_api",
+				Resource: resource,
+				Description: fmt.Sprintf("%s is removed in %s and was called %d times by %q. These requests will fail after the upgrade.",
+					resource, usage.RemovedRelease, usage.Count, usage.UserAgent),
+			})
+			continue
+		}
+		plan.Risks = append(plan.Risks, Risk{
+			Severity:    SeverityLow,
+			Category:    "deprecated_api",
+			Resource:    resource,
+			Description: fmt.Sprintf("%s is deprecated and was called %d times by %q", resource, usage.Count, usage.UserAgent),
+		})
+	}
+}
+
+func addPDBRisks(plan *Plan, input PlanInput) {
+	if input.BlockingPDBs == nil {
+		plan.Risks = append(plan.Risks, Risk{
+			Severity:    SeverityLow,
+			Category:    "pod_disruption_budget",
+			Description: "PodDisruptionBudgets could not be checked. A budget that allows no disruptions stalls node drains.",
+		})
+		return
+	}
+
+	for _, pdb := range input.BlockingPDBs {
+		plan.Risks = append(plan.Risks, Risk{
+			Severity: SeverityHigh,
+			Category: "pod_disruption_budget",
+			Resource: pdb.Namespace + "/" + pdb.Name,
+			Description: fmt.Sprintf("PodDisruptionBudget %s/%s allows no disruptions (%d of %d pods healthy), so node drains will stall",
+				pdb.Namespace, pdb.Name, pdb.CurrentHealthy, pdb.ExpectedPods),
+		})
+	}
+}
+
+func addUpgradeSteps(plan *Plan, input PlanInput, target string) {
+	var removed []string
+	for _, risk := range plan.Risks {
+		if risk.Category == "removed_api" {
+			removed = append(removed, risk.Resource)
+		}
+	}
+	if len(removed) > 0 {
+		addStep(plan, Step{
+			Title:       "Migrate clients off removed APIs",
+			Description: fmt.Sprintf("Update the manifests, charts and controllers that still call %s before upgrading to %s.", strings.Join(removed, ", "), target),
+			Blocking:    true,
+		})
+	}
+
+	if len(input.BlockingPDBs) > 0 {
+		names := make([]string, 0, len(input.BlockingPDBs))
+		for _, pdb := range input.BlockingPDBs {
+			names = append(names, pdb.Namespace+"/"+pdb.Name)
+		}
+		addStep(plan, Step{
+			Title:       "Relax PodDisruptionBudgets that block drains",
+			Description: fmt.Sprintf("Scale up the workloads or loosen %s so that at least one pod can be evicted.", strings.Join(names, ", ")),
+			Blocking:    true,
+		})
+	}
+
+	addStep(plan, Step{
+		Title:       "Upgrade the control plane",
+		Description: fmt.Sprintf("Upgrade the control plane from %s to %s without touching the node pools.", input.CurrentVersion, target),
+		Tool:        "az_aks_operations",
+		Arguments: clusterArguments(input, map[string]interface{}{
+			"operation":          "upgrade",
+			"kubernetes_version": target,
+			"control_plane_only": true,
+		}),
+	})
+
+	// System pools go first so cluster services move to the new version before user workloads
+	pools := append([]NodePoolState(nil), input.NodePools...)
+	sort.SliceStable(pools, func(i, j int) bool {
+		return strings.EqualFold(pools[i].Mode, "System") && !strings.EqualFold(pools[j].Mode, "System")
+	})
+	for _, pool := range pools {
+		if pool.Version == target {
+			continue
+		}
+		surge := pool.MaxSurge
+		if surge == "" {
+			surge = "1 (default)"
+		}
+		addStep(plan, Step{
+			Title: fmt.Sprintf("Upgrade %s node pool %s", strings.ToLower(pool.Mode), pool.Name),
+			Description: fmt.Sprintf("Upgrade %d nodes from %s to %s with max surge %s. The node image is updated as part of the upgrade.",
+				pool.NodeCount, pool.Version, target, surge),
+			Tool: "az_aks_operations",
+			Arguments: clusterArguments(input, map[string]interface{}{
+				"operation":          "nodepool-upgrade",
+				"nodepool_name":      pool.Name,
+				"kubernetes_version": target,
+			}),
+		})
+	}
+
+	addStep(plan, Step{
+		Title:       "Verify the cluster",
+		Description: "Check aks_operation_status for each operation, then confirm that all nodes are Ready and workloads are healthy.",
+		Tool:        "aks_operation_status",
+	})
+}
+
+// addNodeImageSteps plans node image upgrades when no Kubernetes upgrade is available
+func addNodeImageSteps(plan *Plan, input PlanInput) {
+	for _, pool := range input.NodePools {
+		if !pool.NodeImageOutdated() {
+			continue
+		}
+		addStep(plan, Step{
+			Title:       fmt.Sprintf("Upgrade the node image of node pool %s", pool.Name),
+			Description: fmt.Sprintf("Move node pool %s from node image %s to %s.", pool.Name, pool.NodeImageVersion, pool.LatestNodeImageVersion),
+			Command:     nodeImageUpgradeCommand(input, pool.Name),
+		})
+	}
+	if len(plan.Steps) == 0 {
+		plan.Notes = append(plan.Notes, "All node pools run the latest node image")
+	}
+}
+
+// clusterArguments adds the parameters identifying the cluster to the arguments of a tool step
+func clusterArguments(input PlanInput, arguments map[string]interface{}) map[string]interface{} {
+	arguments["resource_group"] = input.ResourceGroup
+	arguments["cluster_name"] = input.ClusterName
+	if input.SubscriptionID != "" {
+		arguments["subscription_id"] = input.SubscriptionID
+	}
+	return arguments
+}
+
+// nodeImageUpgradeCommand returns the az CLI command that upgrades the node image of a node pool
+func nodeImageUpgradeCommand(input PlanInput, poolName string) string {
+	command := fmt.Sprintf("az aks nodepool upgrade --resource-group %s --cluster-name %s --name %s --node-image-only",
+		input.ResourceGroup, input.ClusterName, poolName)
+	if input.SubscriptionID != "" {
+		command += " --subscription " + input.SubscriptionID
+	}
+	return command
+}
+
+func addStep(plan *Plan, step Step) {
+	step.Order = len(plan.Steps) + 1
+	plan.Steps = append(plan.Steps, step)
+}
+
+// parseVersion splits a Kubernetes version such as "1.29.4" or "v1.29" into numbers
+func parseVersion(version string) []int {
+	parts := strings.Split(strings.TrimPrefix(strings.TrimSpace(version), "v"), ".")
+	numbers := make([]int, 0, len(parts))
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			break
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers
+}
+
+// compareVersions compares two versions component by component. Missing components count
+// as equal, so "1.25" compares equal to "1.25.3".
+func compareVersions(a, b string) int {
+	va, vb := parseVersion(a), parseVersion(b)
+	for i := 0; i < len(va) && i < len(vb); i++ {
+		if va[i] != vb[i] {
+			if va[i] < vb[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// isNextMinor reports whether target is at most one minor version ahead of current
+func isNextMinor(current, target string) bool {
+	vc, vt := parseVersion(current), parseVersion(target)
+	if len(vc) < 2 || len(vt) < 2 {
+		return true
+	}
+	return vc[0] == vt[0] && vt[1]-vc[1] <= 1
+}
+
+// minorString returns the major.minor part of a version
+func minorString(version string) string {
+	v := parseVersion(version)
+	if len(v) < 2 {
+		return version
+	}
+	return fmt.Sprintf("%d.%d", v[0], v[1])
+}
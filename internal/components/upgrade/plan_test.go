@@ -0,0 +1,216 @@
+package upgrade
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-mcp/internal/components/monitor/diagnostics"
+)
+
+func basePlanInput() PlanInput {
+	return PlanInput{
+		SubscriptionID: "s",
+		ResourceGroup:  "rg",
+		ClusterName:    "aks-1",
+		ResourceID:     "/subscriptions/s/resourceGroups/rg/providers/Microsoft.ContainerService/managedClusters/aks-1",
+		CurrentVersion: "1.29.4",
+		AvailableUpgrades: []AvailableUpgrade{
+			{Version: "1.29.7"},
+			{Version: "1.30.3"},
+			{Version: "1.31.1", IsPreview: true},
+		},
+		NodePools: []NodePoolState{
+			{Name: "user1", Mode: "User", Version: "1.29.4", NodeCount: 5, MaxSurge: "33%"},
+			{Name: "system", Mode: "System", Version: "1.29.4", NodeCount: 3, MaxSurge: "1"},
+		},
+		DeprecatedAPIUsage: []diagnostics.DeprecatedAPIUsage{},
+		BlockingPDBs:       []BlockingPDB{},
+	}
+}
+
+func TestBuildPlan_DefaultTarget(t *testing.T) {
+	plan, err := BuildPlan(basePlanInput())
+	if err != nil {
+		t.Fatalf("BuildPlan returned error: %v", err)
+	}
+
+	if plan.TargetVersion != "1.30.3" {
+		t.Errorf("Expected the newest GA version 1.30.3, got %s", plan.TargetVersion)
+	}
+	if len(plan.Risks) != 0 {
+		t.Errorf("Expected no risks, got %+v", plan.Risks)
+	}
+
+	var titles []string
+	for _, step := range plan.Steps {
+		titles = append(titles, step.Title)
+	}
+	expected := []string{
+		"Upgrade the control plane",
+		"Upgrade system node pool system",
+		"Upgrade user node pool user1",
+		"Verify the cluster",
+	}
+	if strings.Join(titles, "|") != strings.Join(expected, "|") {
+		t.Errorf("Unexpected steps:\n got %v\nwant %v", titles, expected)
+	}
+	if plan.Steps[0].Arguments["control_plane_only"] != true || plan.Steps[1].Arguments["nodepool_name"] != "system" ||
+		plan.Steps[1].Arguments["resource_group"] != "rg" || plan.Steps[1].Arguments["cluster_name"] != "aks-1" || plan.Steps[1].Arguments["subscription_id"] != "s" {
+		t.Errorf("Unexpected step arguments: %+v", plan.Steps[:2])
+	}
+}
+
+func TestBuildPlan_Risks(t *testing.T) {
+	input := basePlanInput()
+	input.NodePools[0].Version = "1.28.9"
+	input.NodePools[0].MaxSurge = ""
+	input.DeprecatedAPIUsage = []diagnostics.DeprecatedAPIUsage{
+		{APIGroup: "flowcontrol.apiserver.k8s.io", APIVersion: "v1beta2", Resource: "flowschemas", RemovedRelease: "1.29", UserAgent: "legacy-operator", Count: 12},
+		{APIGroup: "flowcontrol.apiserver.k8s.io", APIVersion: "v1beta3", Resource: "flowschemas", RemovedRelease: "1.32", UserAgent: "kubectl", Count: 2},
+	}
+	input.BlockingPDBs = []BlockingPDB{{Namespace: "payments", Name: "api", CurrentHealthy: 2, ExpectedPods: 2}}
+
+	plan, err := BuildPlan(input)
+	if err != nil {
+		t.Fatalf("BuildPlan returned error: %v", err)
+	}
+
+	counts := map[string]int{}
+	for _, risk := range plan.Risks {
+		counts[risk.Category+"/"+risk.Severity]++
+	}
+	expected := map[string]int{
+		"version_skew/medium":        1,
+		"surge/low":                  1,
+		"removed_api/high":           1,
+		"deprecated_api/low":         1,
+		"pod_disruption_budget/high": 1,
+	}
+	if fmt.Sprint(counts) != fmt.Sprint(expected) {
+		t.Errorf("Unexpected risks: %v", counts)
+	}
+
+	if !plan.Steps[0].Blocking || !strings.Contains(plan.Steps[0].Description, "flowcontrol.apiserver.k8s.io/v1beta2 flowschemas") {
+		t.Errorf("Expected the API migration to come first, got %+v", plan.Steps[0])
+	}
+	if !plan.Steps[1].Blocking || !strings.Contains(plan.Steps[1].Description, "payments/api") {
+		t.Errorf("Expected the PDB step second, got %+v", plan.Steps[1])
+	}
+}
+
+func TestBuildPlan_UncheckedSources(t *testing.T) {
+	input := basePlanInput()
+	input.DeprecatedAPIUsage = nil
+	input.BlockingPDBs = nil
+
+	plan, err := BuildPlan(input)
+	if err != nil {
+		t.Fatalf("BuildPlan returned error: %v", err)
+	}
+	if len(plan.Risks) != 2 {
+		t.Errorf("Expected a risk for each source that could not be checked, got %+v", plan.Risks)
+	}
+}
+
+func TestBuildPlan_TargetVersion(t *testing.T) {
+	input := basePlanInput()
+	input.RequestedVersion = "1.31.1"
+	plan, err := BuildPlan(input)
+	if err != nil {
+		t.Fatalf("BuildPlan returned error: %v", err)
+	}
+	if plan.TargetVersion != "1.31.1" || len(plan.Risks) != 1 || plan.Risks[0].Category != "preview_version" {
+		t.Errorf("Expected a preview risk for 1.31.1, got %s %+v", plan.TargetVersion, plan.Risks)
+	}
+
+	input.RequestedVersion = "1.33.0"
+	if _, err := BuildPlan(input); err == nil || !strings.Contains(err.Error(), "Available upgrades: 1.29.7, 1.30.3, 1.31.1") {
+		t.Errorf("Expected unavailable version error, got %v", err)
+	}
+}
+
+func TestBuildPlan_NodeImageOnly(t *testing.T) {
+	input := basePlanInput()
+	input.AvailableUpgrades = nil
+	input.NodePools[0].NodeImageVersion = "AKSUbuntu-2204gen2containerd-202401.09.0"
+	input.NodePools[0].LatestNodeImageVersion = "AKSUbuntu-2204gen2containerd-202407.15.0"
+
+	plan, err := BuildPlan(input)
+	if err != nil {
+		t.Fatalf("BuildPlan returned error: %v", err)
+	}
+	command := "az aks nodepool upgrade --resource-group rg --cluster-name aks-1 --name user1 --node-image-only --subscription s"
+	if plan.TargetVersion != "" || len(plan.Steps) != 1 || plan.Steps[0].Command != command {
+		t.Errorf("Expected a single node image step, got %+v", plan.Steps)
+	}
+}
+
+func TestListBlockingPDBs(t *testing.T) {
+	output := `{"items":[
+		{"metadata":{"name":"api","namespace":"payments"},"spec":{"minAvailable":2},"status":{"disruptionsAllowed":0,"currentHealthy":2,"expectedPods":2}},
+		{"metadata":{"name":"web","namespace":"shop"},"spec":{"maxUnavailable":"25%"},"status":{"disruptionsAllowed":1,"currentHealthy":4,"expectedPods":4}},
+		{"metadata":{"name":"orphan","namespace":"shop"},"spec":{"minAvailable":1},"status":{"disruptionsAllowed":0,"currentHealthy":0,"expectedPods":0}}
+	]}`
+
+	pdbs, err := listBlockingPDBs(func(command string) (string, error) {
+		if !strings.HasPrefix(command, "get poddisruptionbudgets") || !strings.HasSuffix(command, "--context aks-prod") {
+			t.Errorf("Unexpected command: %s", command)
+		}
+		return output, nil
+	}, "aks-prod")
+	if err != nil {
+		t.Fatalf("listBlockingPDBs returned error: %v", err)
+	}
+	if len(pdbs) != 1 || pdbs[0].Name != "api" || pdbs[0].MinAvailable != "2" {
+		t.Errorf("Expected only payments/api to block drains, got %+v", pdbs)
+	}
+
+	if _, err := listBlockingPDBs(func(string) (string, error) { return "", fmt.Errorf("forbidden") }, "aks-prod"); err == nil {
+		t.Error("Expected kubectl errors to be returned")
+	}
+}
+
+func TestClusterKubeContext(t *testing.T) {
+	view := `{"current-context":"aks-dev","clusters":[
+		{"name":"aks-dev","cluster":{"server":"https://aks-dev-dns-1.hcp.westeurope.azmk8s.io:443"}},
+		{"name":"aks-prod","cluster":{"server":"https://aks-prod-dns-2.hcp.westeurope.azmk8s.io:443"}}
+	],"contexts":[
+		{"name":"aks-dev","context":{"cluster":"aks-dev"}},
+		{"name":"aks-prod","context":{"cluster":"aks-prod"}}
+	]}`
+	run := func(command string) (string, error) {
+		if command != "config view -o json" {
+			t.Errorf("Unexpected command: %s", command)
+		}
+		return view, nil
+	}
+
+	context, err := clusterKubeContext(run, []string{"AKS-PROD-DNS-2.hcp.westeurope.azmk8s.io"})
+	if err != nil || context != "aks-prod" {
+		t.Errorf("Expected the aks-prod context, got %q, %v", context, err)
+	}
+	if context, err := clusterKubeContext(run, []string{"aks-dev-dns-1.hcp.westeurope.azmk8s.io"}); err != nil || context != "aks-dev" {
+		t.Errorf("Expected the current context, got %q, %v", context, err)
+	}
+	if _, err := clusterKubeContext(run, []string{"other-dns.hcp.westeurope.azmk8s.io"}); err == nil || !strings.Contains(err.Error(), "no kubeconfig context") {
+		t.Errorf("Expected no context for another cluster, got %v", err)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.30.3", "1.29.7", 1},
+		{"1.29", "1.29.7", 0},
+		{"1.9.0", "1.10.0", -1},
+		{"v1.30", "1.30.1", 0},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%s, %s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
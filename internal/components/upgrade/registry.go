@@ -0,0 +1,42 @@
+package upgrade
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// RegisterUpgradePlanTool registers the aks_upgrade_plan tool
+func RegisterUpgradePlanTool() mcp.Tool {
+	description := `Build a step-by-step Kubernetes upgrade plan for an AKS cluster without changing it.
+
+The plan combines:
+- the control plane upgrades available from the AKS upgrade profile
+- the Kubernetes and node image version of every node pool
+- deprecated API calls recorded in the kube-audit logs, flagged when the API is removed in the target version
+- PodDisruptionBudgets that allow no disruptions and would stall node drains
+
+It returns the risks found and ordered steps that can be run with az_aks_operations. When target_version is omitted the newest generally available upgrade is planned.`
+
+	return mcp.NewTool("aks_upgrade_plan",
+		mcp.WithDescription(description),
+		mcp.WithString("subscription_id",
+			mcp.Description("Azure Subscription ID (defaults to the active cluster context)"),
+		),
+		mcp.WithString("resource_group",
+			mcp.Description("Azure Resource Group containing the AKS cluster (defaults to the active cluster context)"),
+		),
+		mcp.WithString("cluster_name",
+			mcp.Description("Name of the AKS cluster (defaults to the active cluster context)"),
+		),
+		mcp.WithString("target_version",
+			mcp.Description("Kubernetes version to upgrade to. Must be one of the available upgrades"),
+		),
+		mcp.WithNumber("audit_lookback_days",
+			mcp.Description("Days of kube-audit logs to search for deprecated API calls (default 7, max 30)"),
+			mcp.Min(1),
+			mcp.Max(MaxAuditLookbackDays),
+		),
+		mcp.WithBoolean("include_pdbs",
+			mcp.Description("Check PodDisruptionBudgets with kubectl, using the kubeconfig context that points to the cluster (default true)"),
+		),
+	)
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Azure/aks-mcp/internal/clustercontext"
 	"github.com/Azure/aks-mcp/internal/security"
 	"github.com/Azure/aks-mcp/internal/telemetry"
 	flag "github.com/spf13/pflag"
@@ -38,6 +39,17 @@ type ConfigData struct {
 	// OTLP endpoint for OpenTelemetry traces
 	OTLPEndpoint string
 
+	// Path to an optional configuration file (cluster context aliases)
+	ConfigFile string
+	// Named cluster contexts and the active cluster context
+	ClusterContexts *clustercontext.Registry
+
+	// Directory where cluster snapshots are stored (empty means the user configuration directory)
+	SnapshotDir string
+
+	// Where confirmation tokens for destructive operations are sent (response or stderr)
+	ConfirmationTokenDelivery string
+
 	// Telemetry service
 	TelemetryService *telemetry.Service
 }
@@ -53,6 +65,9 @@ func NewConfig() *ConfigData {
 		AccessLevel:     "readonly",
 		AdditionalTools: make(map[string]bool),
 		AllowNamespaces: "",
+		ClusterContexts: clustercontext.Default(),
+
+		ConfirmationTokenDelivery: "response",
 	}
 }
 
@@ -78,6 +93,16 @@ func (cfg *ConfigData) ParseFlags() {
 	// OTLP settings
 	flag.StringVar(&cfg.OTLPEndpoint, "otlp-endpoint", "", "OTLP endpoint for OpenTelemetry traces (e.g. localhost:4317)")
 
+	// Configuration file
+	flag.StringVar(&cfg.ConfigFile, "config", "", "Path to a YAML or JSON configuration file defining cluster context aliases")
+
+	// Cluster snapshots
+	flag.StringVar(&cfg.SnapshotDir, "snapshot-dir", "", "Directory where cluster snapshots are stored (default is aks-mcp/snapshots in the user configuration directory)")
+
+	// Confirmation of destructive operations
+	flag.StringVar(&cfg.ConfirmationTokenDelivery, "confirmation-token-delivery", "response",
+		"Where confirmation tokens for destructive operations are sent: response (in the tool result, the model can confirm by itself) or stderr (only a human reading the server output can confirm)")
+
 	flag.Parse()
 
 	// Update security config
@@ -93,6 +118,14 @@ func (cfg *ConfigData) ParseFlags() {
 	}
 }
 
+// LoadConfigFile loads the configuration file, if one was provided
+func (cfg *ConfigData) LoadConfigFile() error {
+	if cfg.ConfigFile == "" {
+		return nil
+	}
+	return cfg.ClusterContexts.LoadFile(cfg.ConfigFile)
+}
+
 // InitializeTelemetry initializes the telemetry service
 func (cfg *ConfigData) InitializeTelemetry(ctx context.Context, serviceName, serviceVersion string) {
 	// Create telemetry configuration
@@ -0,0 +1,248 @@
+// Package confirm implements the confirmation step for destructive operations.
+//
+// Destructive operations are listed in a central table. When a tool is asked to run
+// one, the gate issues a one-time confirmation token and the tool returns a
+// confirmation request describing the resolved target and impact instead of
+// running. The operation runs when the same call is repeated with the token.
+//
+// Where the token goes decides what the step guarantees. With DeliverInResponse the
+// token is part of the tool result, so the model can repeat the call on its own: it
+// is a speed bump that makes the model stop and restate the operation, not proof
+// that a human approved it. With DeliverToStderr the token is only written to the
+// server's stderr, so the operation runs once a human reads it there and hands it
+// to the model.
+//
+// MCP elicitation would let the server ask the user directly, but the MCP SDK used
+// by this server (mark3labs/mcp-go v0.37.0) has no server-to-client elicitation
+// request, so every client goes through the token flow.
+package confirm
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// ParamName is the tool argument that carries a confirmation token
+const ParamName = "confirmation_token"
+
+// ParamDescription documents the confirmation_token argument in tool schemas
+const ParamDescription = "Token that confirms a destructive operation, issued by a previous call to the same operation. Only pass it after the user has approved the operation"
+
+// TokenDelivery is where the gate sends confirmation tokens
+type TokenDelivery string
+
+const (
+	// DeliverInResponse returns the token in the tool result. This is a model-level speed
+	// bump: it does not force a human to approve the operation.
+	DeliverInResponse TokenDelivery = "response"
+	// DeliverToStderr writes the token to the server's stderr and leaves it out of the
+	// tool result, so only someone who can read the server output can confirm.
+	DeliverToStderr TokenDelivery = "stderr"
+)
+
+// DefaultTTL is how long a confirmation token stays valid
+const DefaultTTL = 5 * time.Minute
+
+// AnyOperation matches every operation of a tool in the classification table
+const AnyOperation = "*"
+
+// Rule classifies an operation as destructive
+type Rule struct {
+	Tool      string
+	Operation string
+	Impact    string
+}
+
+// destructiveOperations is the central table of operations that require confirmation
+var destructiveOperations = []Rule{
+	{Tool: "az_aks_operations", Operation: "delete", Impact: "Deletes the AKS cluster, its node pools and all workloads running on it. This cannot be undone."},
+	{Tool: "az_aks_operations", Operation: "nodepool-delete", Impact: "Deletes the node pool and its nodes. Pods running on it are evicted and must be rescheduled elsewhere."},
+	{Tool: "az_fleet", Operation: "delete", Impact: "Deletes the fleet resource. Deleting a fleet or member removes clusters from fleet management, and deleting a placement stops propagating its resources."},
+	{Tool: "az_vmss_run-command_invoke", Operation: AnyOperation, Impact: "Runs the script with root privileges on the selected scale set instances, which can change or break the nodes."},
+}
+
+// Classify returns the rule that makes an operation destructive, if any
+func Classify(tool, operation string) (Rule, bool) {
+	for _, rule := range destructiveOperations {
+		if rule.Tool == tool && (rule.Operation == AnyOperation || rule.Operation == operation) {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// Request describes a resolved operation that may need confirmation
+type Request struct {
+	Tool      string
+	Operation string
+	// Target is the resolved resource or command the operation acts on
+	Target string
+	// Args are the resolved arguments. A token is only accepted for the same arguments.
+	Args map[string]interface{}
+}
+
+// Confirmation is returned to the client instead of running a destructive operation
+type Confirmation struct {
+	ConfirmationRequired bool      `json:"confirmation_required"`
+	Tool                 string    `json:"tool"`
+	Operation            string    `json:"operation"`
+	Target               string    `json:"target"`
+	Impact               string    `json:"impact"`
+	Token                string    `json:"confirmation_token,omitempty"`
+	TokenDelivery        string    `json:"token_delivery"`
+	ExpiresAt            time.Time `json:"expires_at"`
+	Message              string    `json:"message"`
+}
+
+// JSON renders the confirmation request
+func (c *Confirmation) JSON() (string, error) {
+	out, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal confirmation request: %v", err)
+	}
+	return string(out), nil
+}
+
+// pendingConfirmation is an issued token waiting to be echoed back
+type pendingConfirmation struct {
+	fingerprint string
+	expiresAt   time.Time
+}
+
+// Gate issues and redeems one-time confirmation tokens
+type Gate struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	now      func() time.Time
+	pending  map[string]pendingConfirmation
+	delivery TokenDelivery
+	// stderr receives the tokens delivered with DeliverToStderr
+	stderr io.Writer
+}
+
+// NewGate creates a gate whose tokens expire after ttl and are returned in the tool result
+func NewGate(ttl time.Duration) *Gate {
+	return &Gate{
+		ttl:      ttl,
+		now:      time.Now,
+		pending:  make(map[string]pendingConfirmation),
+		delivery: DeliverInResponse,
+		stderr:   os.Stderr,
+	}
+}
+
+// SetDelivery changes where the gate sends the tokens it issues from now on
+func (g *Gate) SetDelivery(delivery TokenDelivery) error {
+	if delivery != DeliverInResponse && delivery != DeliverToStderr {
+		return fmt.Errorf("invalid confirmation token delivery '%s' (must be %s or %s)", delivery, DeliverInResponse, DeliverToStderr)
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.delivery = delivery
+	return nil
+}
+
+// defaultGate is shared by all tools so a token is redeemed only once per server
+var defaultGate = NewGate(DefaultTTL)
+
+// DefaultGate returns the process-wide confirmation gate
+func DefaultGate() *Gate {
+	return defaultGate
+}
+
+// Check decides whether a request may run. It returns nil when the operation is not
+// destructive or params carry a valid token for the same request, which is then used up.
+// Otherwise it returns the confirmation to send back to the client. An error is returned
+// for a token that is unknown, expired or issued for a different request.
+func (g *Gate) Check(req Request, params map[string]interface{}) (*Confirmation, error) {
+	rule, ok := Classify(req.Tool, req.Operation)
+	if !ok {
+		return nil, nil
+	}
+
+	fingerprint, err := fingerprintOf(req)
+	if err != nil {
+		return nil, err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.expireLocked()
+
+	if token, _ := params[ParamName].(string); token != "" {
+		pending, ok := g.pending[token]
+		if !ok {
+			return nil, fmt.Errorf("confirmation token is invalid or expired; call the tool again without %s to get a new one", ParamName)
+		}
+		if pending.fingerprint != fingerprint {
+			return nil, fmt.Errorf("confirmation token was issued for a different operation or target; call the tool again without %s to get a new one", ParamName)
+		}
+		delete(g.pending, token)
+		return nil, nil
+	}
+
+	token := newToken()
+	expiresAt := g.now().Add(g.ttl)
+	g.pending[token] = pendingConfirmation{fingerprint: fingerprint, expiresAt: expiresAt}
+
+	confirmation := &Confirmation{
+		ConfirmationRequired: true,
+		Tool:                 req.Tool,
+		Operation:            req.Operation,
+		Target:               req.Target,
+		Impact:               rule.Impact,
+		TokenDelivery:        string(g.delivery),
+		ExpiresAt:            expiresAt.UTC(),
+	}
+	if g.delivery == DeliverToStderr {
+		fmt.Fprintf(g.stderr, "Confirmation token for %s %s on %s (expires %s): %s\n",
+			req.Tool, req.Operation, req.Target, confirmation.ExpiresAt.Format(time.RFC3339), token)
+		confirmation.Message = fmt.Sprintf("This operation is destructive and has not been run. Show the target and impact to the user. "+
+			"The confirmation token was written to the server's stderr and is not available to you: if the user approves, "+
+			"ask them for it and repeat the call with the same arguments and %s set to the token they give you before it expires.", ParamName)
+		return confirmation, nil
+	}
+
+	confirmation.Token = token
+	confirmation.Message = fmt.Sprintf("This operation is destructive and has not been run. Show the target and impact to the user and, "+
+		"only if they approve, repeat the call with the same arguments and %s=%q before it expires.", ParamName, token)
+	return confirmation, nil
+}
+
+// expireLocked drops tokens that can no longer be redeemed
+func (g *Gate) expireLocked() {
+	now := g.now()
+	for token, pending := range g.pending {
+		if now.After(pending.expiresAt) {
+			delete(g.pending, token)
+		}
+	}
+}
+
+// fingerprintOf identifies a request so a token cannot be reused for another target
+func fingerprintOf(req Request) (string, error) {
+	// encoding/json sorts map keys, which makes the encoding stable
+	encoded, err := json.Marshal(struct {
+		Tool      string                 `json:"tool"`
+		Operation string                 `json:"operation"`
+		Target    string                 `json:"target"`
+		Args      map[string]interface{} `json:"args"`
+	}{req.Tool, req.Operation, req.Target, req.Args})
+	if err != nil {
+		return "", fmt.Errorf("failed to fingerprint operation: %v", err)
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// newToken returns a random confirmation token
+func newToken() string {
+	return "confirm-" + rand.Text()
+}
@@ -0,0 +1,120 @@
+package confirm
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		tool      string
+		operation string
+		want      bool
+	}{
+		{"az_aks_operations", "delete", true},
+		{"az_aks_operations", "nodepool-delete", true},
+		{"az_aks_operations", "scale", false},
+		{"az_fleet", "delete", true},
+		{"az_fleet", "create", false},
+		{"az_vmss_run-command_invoke", "az vmss run-command invoke", true},
+		{"kubectl", "delete", false},
+	}
+
+	for _, tt := range tests {
+		if _, got := Classify(tt.tool, tt.operation); got != tt.want {
+			t.Errorf("Classify(%s, %s) = %v, want %v", tt.tool, tt.operation, got, tt.want)
+		}
+	}
+}
+
+func TestGate_TokenFlow(t *testing.T) {
+	gate := NewGate(time.Minute)
+	req := Request{
+		Tool:      "az_aks_operations",
+		Operation: "delete",
+		Target:    "/subscriptions/s/resourceGroups/rg/providers/Microsoft.ContainerService/managedClusters/aks-1",
+		Args:      map[string]interface{}{"cluster_name": "aks-1"},
+	}
+
+	confirmation, err := gate.Check(req, map[string]interface{}{})
+	if err != nil || confirmation == nil {
+		t.Fatalf("Expected a confirmation request, got %v, %v", confirmation, err)
+	}
+	if !confirmation.ConfirmationRequired || confirmation.Target != req.Target || confirmation.Impact == "" {
+		t.Errorf("Unexpected confirmation: %+v", confirmation)
+	}
+
+	// A token is bound to the request it was issued for
+	other := req
+	other.Args = map[string]interface{}{"cluster_name": "aks-2"}
+	if _, err := gate.Check(other, map[string]interface{}{ParamName: confirmation.Token}); err == nil || !strings.Contains(err.Error(), "different operation") {
+		t.Errorf("Expected mismatch error, got %v", err)
+	}
+
+	if c, err := gate.Check(req, map[string]interface{}{ParamName: confirmation.Token}); err != nil || c != nil {
+		t.Fatalf("Expected the token to be accepted, got %v, %v", c, err)
+	}
+
+	// Tokens are single use
+	if _, err := gate.Check(req, map[string]interface{}{ParamName: confirmation.Token}); err == nil {
+		t.Error("Expected a used token to be rejected")
+	}
+}
+
+func TestGate_Expiry(t *testing.T) {
+	gate := NewGate(time.Minute)
+	now := time.Now()
+	gate.now = func() time.Time { return now }
+
+	req := Request{Tool: "az_fleet", Operation: "delete", Target: "az fleet member delete --name m1"}
+	confirmation, err := gate.Check(req, map[string]interface{}{})
+	if err != nil || confirmation == nil {
+		t.Fatalf("Expected a confirmation request, got %v, %v", confirmation, err)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, err := gate.Check(req, map[string]interface{}{ParamName: confirmation.Token}); err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Errorf("Expected expired token error, got %v", err)
+	}
+}
+
+func TestGate_NonDestructive(t *testing.T) {
+	confirmation, err := NewGate(time.Minute).Check(Request{Tool: "az_aks_operations", Operation: "show"}, map[string]interface{}{})
+	if err != nil || confirmation != nil {
+		t.Errorf("Expected non-destructive operations to pass, got %v, %v", confirmation, err)
+	}
+}
+
+func TestGate_DeliverToStderr(t *testing.T) {
+	gate := NewGate(time.Minute)
+	var stderr strings.Builder
+	gate.stderr = &stderr
+	if err := gate.SetDelivery(DeliverToStderr); err != nil {
+		t.Fatalf("SetDelivery returned error: %v", err)
+	}
+
+	req := Request{Tool: "az_aks_operations", Operation: "delete", Target: "aks-1"}
+	confirmation, err := gate.Check(req, map[string]interface{}{})
+	if err != nil || confirmation == nil {
+		t.Fatalf("Expected a confirmation request, got %v, %v", confirmation, err)
+	}
+	rendered, _ := confirmation.JSON()
+	if confirmation.Token != "" || strings.Contains(rendered, "confirm-") {
+		t.Errorf("Expected the token to be left out of the tool result, got %s", rendered)
+	}
+
+	// The token is only available to whoever reads the server output
+	output := stderr.String()
+	token := output[strings.LastIndex(output, " ")+1 : len(output)-1]
+	if !strings.HasPrefix(token, "confirm-") || !strings.Contains(output, "aks-1") {
+		t.Fatalf("Expected the token and target on stderr, got %q", output)
+	}
+	if c, err := gate.Check(req, map[string]interface{}{ParamName: token}); err != nil || c != nil {
+		t.Errorf("Expected the token from stderr to be accepted, got %v, %v", c, err)
+	}
+
+	if err := gate.SetDelivery("email"); err == nil {
+		t.Error("Expected an unknown delivery to be rejected")
+	}
+}
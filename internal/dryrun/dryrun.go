@@ -0,0 +1,119 @@
+// Package dryrun builds the previews returned by mutating tools when they are
+// called with dry_run=true. A preview describes what the tool would do, whether
+// the access policy allows it, and how the target would change, without
+// executing anything.
+package dryrun
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// ParamName is the tool argument that requests a dry run
+const ParamName = "dry_run"
+
+// ParamDescription documents the dry_run argument in tool schemas
+const ParamDescription = "Preview the operation without executing it: returns the resolved command or request, the policy decision and the expected changes"
+
+// Requested reports whether the tool arguments ask for a dry run
+func Requested(params map[string]interface{}) bool {
+	switch v := params[ParamName].(type) {
+	case bool:
+		return v
+	case string:
+		requested, _ := strconv.ParseBool(v)
+		return requested
+	}
+	return false
+}
+
+// Policy is the access decision the server would make for the operation
+type Policy struct {
+	Allowed     bool   `json:"allowed"`
+	AccessLevel string `json:"access_level"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+// NewPolicy builds a policy decision from the error returned by an access check
+func NewPolicy(accessLevel string, err error) Policy {
+	if err != nil {
+		return Policy{Allowed: false, AccessLevel: accessLevel, Reason: err.Error()}
+	}
+	return Policy{Allowed: true, AccessLevel: accessLevel}
+}
+
+// Change is the difference between the current and proposed value of one field
+type Change struct {
+	Field    string      `json:"field"`
+	Current  interface{} `json:"current"`
+	Proposed interface{} `json:"proposed"`
+	// Breaking marks a change that breaks existing consumers of the resource
+	Breaking bool `json:"breaking,omitempty"`
+}
+
+// Request describes an SDK or Kubernetes API request the tool would send
+type Request struct {
+	Method     string      `json:"method"`
+	API        string      `json:"api"`
+	ResourceID string      `json:"resource_id,omitempty"`
+	Body       interface{} `json:"body,omitempty"`
+}
+
+// Preview is the result of a dry run
+type Preview struct {
+	DryRun    bool     `json:"dry_run"`
+	Tool      string   `json:"tool"`
+	Operation string   `json:"operation"`
+	Command   string   `json:"command,omitempty"`
+	Request   *Request `json:"request,omitempty"`
+	// Requests lists every request of operations that send more than one
+	Requests []Request `json:"requests,omitempty"`
+	Policy   Policy    `json:"policy"`
+	Changes  []Change  `json:"changes,omitempty"`
+	Notes    []string  `json:"notes,omitempty"`
+}
+
+// New creates a preview for the given tool and operation
+func New(tool, operation string, policy Policy) *Preview {
+	return &Preview{
+		DryRun:    true,
+		Tool:      tool,
+		Operation: operation,
+		Policy:    policy,
+	}
+}
+
+// AddChange records a field change when the proposed value differs from the current one
+func (p *Preview) AddChange(field string, current, proposed interface{}) {
+	if fmt.Sprint(current) == fmt.Sprint(proposed) {
+		p.AddNote(fmt.Sprintf("%s is already %v", field, current))
+		return
+	}
+	p.Changes = append(p.Changes, Change{Field: field, Current: current, Proposed: proposed})
+}
+
+// AddBreakingChange records a field change that breaks existing consumers of the resource,
+// such as queries reading data from where it is sent today
+func (p *Preview) AddBreakingChange(field string, current, proposed interface{}) {
+	p.Changes = append(p.Changes, Change{Field: field, Current: current, Proposed: proposed, Breaking: true})
+}
+
+// AddRequest records one of several requests the operation would send
+func (p *Preview) AddRequest(request Request) {
+	p.Requests = append(p.Requests, request)
+}
+
+// AddNote adds a human-readable remark to the preview
+func (p *Preview) AddNote(note string) {
+	p.Notes = append(p.Notes, note)
+}
+
+// JSON renders the preview
+func (p *Preview) JSON() (string, error) {
+	out, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal dry-run preview: %v", err)
+	}
+	return string(out), nil
+}
@@ -0,0 +1,61 @@
+package dryrun
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestRequested(t *testing.T) {
+	testCases := []struct {
+		value interface{}
+		want  bool
+	}{
+		{true, true},
+		{false, false},
+		{"true", true},
+		{"false", false},
+		{"yes-please", false},
+		{nil, false},
+	}
+
+	for _, tc := range testCases {
+		params := map[string]interface{}{}
+		if tc.value != nil {
+			params[ParamName] = tc.value
+		}
+		if got := Requested(params); got != tc.want {
+			t.Errorf("Requested(%v) = %v, want %v", tc.value, got, tc.want)
+		}
+	}
+}
+
+func TestPreview(t *testing.T) {
+	preview := New("az_aks_operations", "nodepool-scale", NewPolicy("readonly", fmt.Errorf("requires readwrite")))
+	preview.AddChange("node_count", 3, 5)
+	preview.AddChange("vm_size", "Standard_D4s_v5", "Standard_D4s_v5")
+	preview.AddRequest(Request{Method: "PUT", API: "AgentPoolsClient.BeginCreateOrUpdate", ResourceID: "pool-1"})
+	preview.AddRequest(Request{Method: "PUT", API: "AgentPoolsClient.BeginCreateOrUpdate", ResourceID: "pool-2"})
+
+	out, err := preview.JSON()
+	if err != nil {
+		t.Fatalf("JSON returned error: %v", err)
+	}
+
+	var decoded Preview
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatalf("Failed to decode preview: %v", err)
+	}
+	if !decoded.DryRun || decoded.Policy.Allowed || decoded.Policy.Reason != "requires readwrite" {
+		t.Errorf("Unexpected preview header: %+v", decoded)
+	}
+	if len(decoded.Changes) != 1 || decoded.Changes[0].Field != "node_count" {
+		t.Errorf("Expected only the node_count change, got %+v", decoded.Changes)
+	}
+	if decoded.Request != nil || len(decoded.Requests) != 2 || decoded.Requests[1].ResourceID != "pool-2" {
+		t.Errorf("Expected both requests, got %+v", decoded.Requests)
+	}
+	if len(decoded.Notes) != 1 {
+		t.Errorf("Expected a note for the unchanged field, got %+v", decoded.Notes)
+	}
+}
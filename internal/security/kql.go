@@ -0,0 +1,586 @@
+package security
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// kqlTokenKind is the kind of a KQL token
+type kqlTokenKind int
+
+const (
+	kqlIdent kqlTokenKind = iota
+	kqlNumber
+	kqlString
+	kqlPunct
+)
+
+// kqlToken is a token of a KQL query with its byte offsets in the query
+type kqlToken struct {
+	kind  kqlTokenKind
+	text  string
+	start int
+	end   int
+}
+
+// kqlReadOnlyOperators are the tabular operators allowed after a pipe. Everything else,
+// including render, fork, facet, partition and invoke, is rejected.
+var kqlReadOnlyOperators = []string{
+	"as", "consume", "count", "distinct", "evaluate", "extend", "filter", "getschema", "join",
+	"limit", "lookup", "make-series", "mv-apply", "mv-expand", "order", "parse", "parse-kv",
+	"parse-where", "project", "project-away", "project-keep", "project-rename", "project-reorder",
+	"reduce", "sample", "sample-distinct", "search", "serialize", "sort", "summarize", "take",
+	"top", "top-hitters", "top-nested", "union", "where",
+}
+
+// kqlAllowedPlugins are the evaluate plugins that only compute over their input
+var kqlAllowedPlugins = []string{
+	"active_users_count", "activity_counts_metrics", "activity_engagement", "activity_metrics",
+	"autocluster", "bag_unpack", "basket", "dcount_intersect", "diffpatterns", "funnel_sequence",
+	"funnel_sequence_completion", "ipv4_lookup", "ipv6_lookup", "market_basket", "narrow",
+	"new_activity_metrics", "pivot", "preview", "rolling_percentile", "schema_merge",
+	"sequence_detect", "session_count", "sliding_window_counts",
+}
+
+// kqlBlockedFunctions read data outside the queried workspace or from external sources
+var kqlBlockedFunctions = []string{
+	"adx", "app", "arg", "cluster", "database", "external_table", "externaldata", "table", "workspace",
+}
+
+// kqlBlockedStatements are query statements other than let, which change query behavior or
+// declare objects
+var kqlBlockedStatements = []string{"alias", "declare", "pattern", "restrict", "set", "execute"}
+
+// kqlSourceFunctions are the built-in functions that may be used as a tabular data source
+var kqlSourceFunctions = []string{"datatable", "materialize", "range"}
+
+// kqlLetFunctions are the built-in functions a let statement may call when the query is scoped.
+// Anything else could be a stored workspace function that reads unscoped data.
+var kqlLetFunctions = []string{
+	"abs", "ago", "array_length", "bag_pack", "bin", "case", "ceiling", "coalesce", "datatable",
+	"datetime", "dynamic", "endofday", "endofmonth", "endofweek", "extract", "floor", "format_datetime",
+	"iff", "iif", "make_datetime", "make_timespan", "materialize", "max_of", "min_of", "now", "pack",
+	"pack_array", "parse_json", "range", "replace_string", "round", "split", "startofday", "startofmonth",
+	"startofweek", "strcat", "timespan", "tobool", "todatetime", "todecimal", "todouble", "todynamic",
+	"toint", "tolong", "tolower", "toreal", "toscalar", "tostring", "totimespan", "toupper", "trim",
+}
+
+// KQLGuardrails configures the static analysis of a user-supplied KQL query
+type KQLGuardrails struct {
+	// ScopeResourceID, when set, restricts data sources to AllowedTables and wraps every table
+	// reference in a filter on this resource ID
+	ScopeResourceID string
+	AllowedTables   []string
+	// TableFilters are extra where predicates applied with the scope, keyed by table name
+	TableFilters map[string]string
+	// MaxRecords, when positive, caps the rows returned by appending a take
+	MaxRecords int
+}
+
+// KQLAnalysis is the result of analyzing a KQL query
+type KQLAnalysis struct {
+	// Query is the query to run, with the scope and row cap applied
+	Query string
+	// Tables are the tables the query reads, in order of first reference
+	Tables []string
+	// Limits are the row counts of take and limit operators in the query
+	Limits []int
+}
+
+// kqlAnalyzer holds the state of one analysis
+type kqlAnalyzer struct {
+	guardrails   KQLGuardrails
+	tokens       []kqlToken
+	letNames     map[string]bool
+	replacements map[int]string
+	analysis     *KQLAnalysis
+}
+
+// ValidateKQLReadOnly checks that a KQL query only uses read-only operators and statements and
+// does not reach outside the queried workspace or application
+func ValidateKQLReadOnly(query string) error {
+	_, err := AnalyzeKQL(query, KQLGuardrails{})
+	return err
+}
+
+// AnalyzeKQL parses a KQL query and enforces the guardrails: read-only operators and plugins
+// only, no control commands or query statements other than let, no cross-workspace or external
+// data, and, when configured, a resource scope on every table and a cap on returned rows.
+func AnalyzeKQL(query string, guardrails KQLGuardrails) (*KQLAnalysis, error) {
+	tokens, err := tokenizeKQL(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("query is empty")
+	}
+
+	a := &kqlAnalyzer{
+		guardrails:   guardrails,
+		tokens:       tokens,
+		letNames:     make(map[string]bool),
+		replacements: make(map[int]string),
+		analysis:     &KQLAnalysis{},
+	}
+
+	// Function definitions such as let f = (n:int) { ... } are rejected before their bodies are analyzed
+	for i := range tokens {
+		if a.isPunct(i, "{") && a.isPunct(i-1, ")") {
+			return nil, fmt.Errorf("user-defined functions are not allowed")
+		}
+	}
+
+	statements := a.splitStatements()
+	for n, statement := range statements {
+		last := n == len(statements)-1
+		if err := a.checkStatement(statement[0], statement[1], last); err != nil {
+			return nil, err
+		}
+	}
+	if err := a.checkTokens(); err != nil {
+		return nil, err
+	}
+
+	a.analysis.Query = a.rewrite(query)
+	if guardrails.MaxRecords > 0 {
+		a.analysis.Query += fmt.Sprintf(" | take %d", guardrails.MaxRecords)
+	}
+	return a.analysis, nil
+}
+
+// splitStatements returns the [start, end) token ranges of the statements separated by
+// semicolons outside brackets, ignoring empty statements
+func (a *kqlAnalyzer) splitStatements() [][2]int {
+	var statements [][2]int
+	depth, start := 0, 0
+	for i, token := range a.tokens {
+		if token.kind != kqlPunct {
+			continue
+		}
+		switch token.text {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			depth--
+		case ";":
+			if depth == 0 {
+				if i > start {
+					statements = append(statements, [2]int{start, i})
+				}
+				start = i + 1
+			}
+		}
+	}
+	if len(a.tokens) > start {
+		statements = append(statements, [2]int{start, len(a.tokens)})
+	}
+	return statements
+}
+
+// checkStatement checks a query statement: only let statements may precede the tabular expression
+func (a *kqlAnalyzer) checkStatement(start, end int, last bool) error {
+	first := a.tokens[start]
+	if first.kind == kqlPunct && first.text == "." {
+		return fmt.Errorf("control commands are not allowed")
+	}
+	keyword := strings.ToLower(first.text)
+	if first.kind == kqlIdent && slices.Contains(kqlBlockedStatements, keyword) {
+		return fmt.Errorf("'%s' statements are not allowed", keyword)
+	}
+
+	if first.kind == kqlIdent && keyword == "let" {
+		if last {
+			return fmt.Errorf("query must end with a tabular expression")
+		}
+		return a.checkLet(start, end)
+	}
+	if !last {
+		return fmt.Errorf("only let statements may precede the query")
+	}
+
+	if first.kind == kqlIdent && (keyword == "find" || keyword == "search") {
+		if a.scoped() {
+			return fmt.Errorf("'%s' across all tables is not allowed; query a table explicitly", keyword)
+		}
+		return nil
+	}
+	return a.checkSource(start, false)
+}
+
+// checkLet checks a let statement and records the name it binds
+func (a *kqlAnalyzer) checkLet(start, end int) error {
+	if end-start < 4 || a.tokens[start+1].kind != kqlIdent || !a.isPunct(start+2, "=") {
+		return fmt.Errorf("invalid let statement")
+	}
+	if err := a.checkSource(start+3, true); err != nil {
+		return err
+	}
+	a.letNames[a.tokens[start+1].text] = true
+	return nil
+}
+
+// checkSource checks the token at a position where a tabular data source may appear. In a let
+// statement scalar values are also accepted.
+func (a *kqlAnalyzer) checkSource(i int, inLet bool) error {
+	if i >= len(a.tokens) {
+		return fmt.Errorf("query is incomplete")
+	}
+	token := a.tokens[i]
+	switch token.kind {
+	case kqlNumber, kqlString:
+		if inLet {
+			return nil
+		}
+		return fmt.Errorf("unexpected %s at the start of a tabular expression", token.text)
+	case kqlPunct:
+		switch token.text {
+		case "(":
+			return a.checkSource(i+1, inLet)
+		case "*":
+			return fmt.Errorf("wildcard table references are not allowed")
+		case "[":
+			name, err := a.bracketedName(i)
+			if err != nil {
+				return err
+			}
+			return a.addTable(i, name)
+		}
+		if inLet {
+			return nil
+		}
+		return fmt.Errorf("unexpected '%s' at the start of a tabular expression", token.text)
+	}
+
+	name := token.text
+	lower := strings.ToLower(name)
+	if a.isPunct(i+1, "*") && a.tokens[i+1].start == token.end {
+		return fmt.Errorf("wildcard table references are not allowed")
+	}
+	if a.isPunct(i+1, "(") {
+		if slices.Contains(kqlBlockedFunctions, lower) {
+			return fmt.Errorf("function '%s' is not allowed: queries cannot read other workspaces, applications or external data", name)
+		}
+		if !a.scoped() || slices.Contains(kqlSourceFunctions, lower) || (inLet && slices.Contains(kqlLetFunctions, lower)) {
+			return nil
+		}
+		return fmt.Errorf("function '%s' cannot be used as a data source in a scoped query", name)
+	}
+	switch lower {
+	case "print", "range", "datatable", "true", "false":
+		return nil
+	case "union":
+		return a.checkUnion(i)
+	}
+	return a.addTable(i, name)
+}
+
+// bracketedName returns the table name of a ['name'] or ["name"] reference starting at i
+func (a *kqlAnalyzer) bracketedName(i int) (string, error) {
+	if i+2 >= len(a.tokens) || a.tokens[i+1].kind != kqlString || !a.isPunct(i+2, "]") {
+		return "", fmt.Errorf("bracketed table references must have the form ['name']")
+	}
+	quoted := a.tokens[i+1].text
+	name := quoted[1 : len(quoted)-1]
+	if quoted[0] != '\'' && quoted[0] != '"' || name == "" || strings.ContainsAny(name, "\\'\"`") {
+		return "", fmt.Errorf("bracketed table references must have the form ['name']")
+	}
+	return name, nil
+}
+
+// sourceEnd returns the index of the token after the table name at i, which is either an
+// identifier or a bracketed ['name'] reference
+func (a *kqlAnalyzer) sourceEnd(i int) int {
+	if a.isPunct(i, "[") {
+		return i + 3
+	}
+	return i + 1
+}
+
+// addTable records the table reference at i and, for scoped queries, wraps it in the resource filter
+func (a *kqlAnalyzer) addTable(i int, name string) error {
+	if a.letNames[name] {
+		return nil
+	}
+	if a.scoped() {
+		if !slices.Contains(a.guardrails.AllowedTables, name) {
+			return fmt.Errorf("table '%s' is not allowed. Allowed tables: %s", name, strings.Join(a.guardrails.AllowedTables, ", "))
+		}
+		reference := a.tokens[i].text
+		for j := i + 1; j < a.sourceEnd(i); j++ {
+			reference += a.tokens[j].text
+			a.replacements[j] = ""
+		}
+		filter := ""
+		if predicate := a.guardrails.TableFilters[name]; predicate != "" {
+			filter = " | where " + predicate
+		}
+		a.replacements[i] = fmt.Sprintf("(%s | where _ResourceId =~ '%s'%s)", reference, a.guardrails.ScopeResourceID, filter)
+	}
+	if !slices.Contains(a.analysis.Tables, name) {
+		a.analysis.Tables = append(a.analysis.Tables, name)
+	}
+	return nil
+}
+
+// checkUnion checks the comma-separated sources of a union starting at the union keyword
+func (a *kqlAnalyzer) checkUnion(i int) error {
+	i = a.skipOptions(i + 1)
+	for {
+		if err := a.checkSource(i, false); err != nil {
+			return err
+		}
+		// Move past the source to the next comma at the same depth
+		depth := 0
+		for ; i < len(a.tokens); i++ {
+			token := a.tokens[i]
+			if token.kind != kqlPunct {
+				continue
+			}
+			if token.text == "(" {
+				depth++
+			} else if token.text == ")" {
+				if depth == 0 {
+					return nil
+				}
+				depth--
+			} else if depth == 0 && (token.text == "|" || token.text == ";") {
+				return nil
+			} else if depth == 0 && token.text == "," {
+				break
+			}
+		}
+		if i >= len(a.tokens) {
+			return nil
+		}
+		i++
+	}
+}
+
+// skipOptions skips operator options such as kind=inner or hint.strategy=shuffle
+func (a *kqlAnalyzer) skipOptions(i int) int {
+	for i < len(a.tokens) && a.tokens[i].kind == kqlIdent {
+		switch {
+		case a.isPunct(i+1, "="):
+			i += 3
+		case a.isPunct(i+1, ".") && i+3 < len(a.tokens) && a.isPunct(i+3, "="):
+			i += 5
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// checkTokens checks operators after pipes, function calls and nested data sources anywhere in the query
+func (a *kqlAnalyzer) checkTokens() error {
+	for i, token := range a.tokens {
+		switch {
+		case token.kind == kqlIdent && a.isPunct(i+1, "("):
+			if slices.Contains(kqlBlockedFunctions, strings.ToLower(token.text)) {
+				return fmt.Errorf("function '%s' is not allowed: queries cannot read other workspaces, applications or external data", token.text)
+			}
+		case token.kind == kqlIdent && strings.EqualFold(token.text, "externaldata"):
+			return fmt.Errorf("externaldata is not allowed")
+		case token.kind != kqlPunct:
+		case token.text == "|":
+			if err := a.checkOperator(i + 1); err != nil {
+				return err
+			}
+		case token.text == "(":
+			if err := a.checkParenthesizedSource(i); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkParenthesizedSource checks sub-queries in parentheses, such as toscalar(T | ...) or x in (T | ...)
+func (a *kqlAnalyzer) checkParenthesizedSource(i int) error {
+	first := i + 1
+	if first >= len(a.tokens) || a.tokens[first].kind != kqlIdent && !a.isPunct(first, "[") {
+		return nil
+	}
+	previous := ""
+	if i > 0 {
+		previous = strings.ToLower(a.tokens[i-1].text)
+	}
+	tabular := previous == "toscalar" || previous == "materialize"
+	list := previous == "in" || previous == "has_any" || previous == "has_all" || (previous == "~" && i > 1 && strings.EqualFold(a.tokens[i-2].text, "in"))
+
+	if a.tokens[first].kind == kqlIdent && a.isPunct(first+1, "(") {
+		// A call in a list may be a stored function that returns a table; scalar built-ins are fine
+		if tabular || list && !slices.Contains(kqlLetFunctions, strings.ToLower(a.tokens[first].text)) {
+			return a.checkSource(first, false)
+		}
+		return nil
+	}
+	next := a.sourceEnd(first)
+	if tabular || a.isPunct(next, "|") || list && a.isPunct(next, ")") {
+		return a.checkSource(first, false)
+	}
+	return nil
+}
+
+// checkOperator checks the tabular operator following a pipe
+func (a *kqlAnalyzer) checkOperator(i int) error {
+	if i >= len(a.tokens) || a.tokens[i].kind != kqlIdent {
+		return fmt.Errorf("expected an operator after '|'")
+	}
+	// Operators such as project-away are lexed as identifiers joined by adjacent dashes
+	name := strings.ToLower(a.tokens[i].text)
+	next := i + 1
+	for next+1 < len(a.tokens) && a.isPunct(next, "-") && a.tokens[next].start == a.tokens[next-1].end &&
+		a.tokens[next+1].kind == kqlIdent && a.tokens[next+1].start == a.tokens[next].end {
+		name += "-" + strings.ToLower(a.tokens[next+1].text)
+		next += 2
+	}
+	if !slices.Contains(kqlReadOnlyOperators, name) {
+		return fmt.Errorf("operator '%s' is not allowed", name)
+	}
+
+	switch name {
+	case "join", "lookup":
+		return a.checkSource(a.skipOptions(next), false)
+	case "union":
+		return a.checkUnion(i)
+	case "evaluate":
+		next = a.skipOptions(next)
+		if next >= len(a.tokens) || !slices.Contains(kqlAllowedPlugins, strings.ToLower(a.tokens[next].text)) {
+			plugin := ""
+			if next < len(a.tokens) {
+				plugin = a.tokens[next].text
+			}
+			return fmt.Errorf("plugin '%s' is not allowed", plugin)
+		}
+	case "take", "limit":
+		if next < len(a.tokens) && a.tokens[next].kind == kqlNumber {
+			var n int
+			if _, err := fmt.Sscanf(a.tokens[next].text, "%d", &n); err == nil {
+				a.analysis.Limits = append(a.analysis.Limits, n)
+			}
+		}
+	}
+	return nil
+}
+
+// rewrite rebuilds the query with table references replaced, comments dropped and line
+// breaks collapsed so it can be passed as a single command argument
+func (a *kqlAnalyzer) rewrite(query string) string {
+	var b strings.Builder
+	for i, token := range a.tokens {
+		if i > 0 {
+			gap := query[a.tokens[i-1].end:token.start]
+			if strings.TrimSpace(gap) != "" || strings.ContainsAny(gap, "\r\n") {
+				gap = " "
+			}
+			b.WriteString(gap)
+		}
+		if replacement, ok := a.replacements[i]; ok {
+			b.WriteString(replacement)
+		} else {
+			b.WriteString(token.text)
+		}
+	}
+	return b.String()
+}
+
+func (a *kqlAnalyzer) scoped() bool {
+	return a.guardrails.ScopeResourceID != ""
+}
+
+func (a *kqlAnalyzer) isPunct(i int, text string) bool {
+	return i >= 0 && i < len(a.tokens) && a.tokens[i].kind == kqlPunct && a.tokens[i].text == text
+}
+
+// tokenizeKQL splits a KQL query into tokens, dropping whitespace and comments
+func tokenizeKQL(query string) ([]kqlToken, error) {
+	var tokens []kqlToken
+	runes := []rune(query)
+	// offsets maps rune indexes to byte offsets
+	offsets := make([]int, len(runes)+1)
+	offset := 0
+	for i, r := range runes {
+		offsets[i] = offset
+		offset += len(string(r))
+	}
+	offsets[len(runes)] = offset
+
+	add := func(kind kqlTokenKind, start, end int) {
+		tokens = append(tokens, kqlToken{kind: kind, text: query[offsets[start]:offsets[end]], start: offsets[start], end: offsets[end]})
+	}
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '/':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '`' && i+2 < len(runes) && runes[i+1] == '`' && runes[i+2] == '`':
+			start := i
+			for i += 3; i+2 < len(runes) && !(runes[i] == '`' && runes[i+1] == '`' && runes[i+2] == '`'); i++ {
+			}
+			if i+2 >= len(runes) {
+				return nil, fmt.Errorf("unterminated multi-line string")
+			}
+			i += 3
+			add(kqlString, start, i)
+		case r == '\'' || r == '"' || (r == '@' && i+1 < len(runes) && (runes[i+1] == '\'' || runes[i+1] == '"')):
+			start := i
+			verbatim := r == '@'
+			if verbatim {
+				i++
+			}
+			quote := runes[i]
+			i++
+			closed := false
+			for i < len(runes) {
+				switch {
+				case !verbatim && runes[i] == '\\':
+					i += 2
+					continue
+				case runes[i] == quote && verbatim && i+1 < len(runes) && runes[i+1] == quote:
+					i += 2
+					continue
+				case runes[i] == quote:
+					closed = true
+				}
+				i++
+				if closed {
+					break
+				}
+			}
+			if !closed {
+				return nil, fmt.Errorf("unterminated string literal")
+			}
+			add(kqlString, start, i)
+		case unicode.IsLetter(r) || r == '_' || r == '$':
+			start := i
+			for i++; i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_'); i++ {
+			}
+			add(kqlIdent, start, i)
+		case unicode.IsDigit(r):
+			// Numbers include timespan and exponent suffixes such as 5m, 1.5h and 1e6
+			start := i
+			for i++; i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])); i++ {
+			}
+			add(kqlNumber, start, i)
+		default:
+			start := i
+			i++
+			if i < len(runes) {
+				switch string(runes[start : i+1]) {
+				case "==", "!=", "=~", "!~", "<=", ">=", "..", "=>", "<>":
+					i++
+				}
+			}
+			add(kqlPunct, start, i)
+		}
+	}
+	return tokens, nil
+}
@@ -0,0 +1,176 @@
+package security
+
+import (
+	"strings"
+	"testing"
+)
+
+const testScope = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.ContainerService/managedClusters/aks"
+
+var testGuardrails = KQLGuardrails{
+	ScopeResourceID: testScope,
+	AllowedTables:   []string{"KubePodInventory", "KubeEvents", "ContainerLogV2"},
+	MaxRecords:      100,
+}
+
+func TestAnalyzeKQL_ScopesTablesAndCapsRows(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		expectedQuery  string
+		expectedTables []string
+	}{
+		{
+			name:           "single table",
+			query:          "KubeEvents | where Reason == 'BackOff' | take 10",
+			expectedQuery:  "(KubeEvents | where _ResourceId =~ '" + testScope + "') | where Reason == 'BackOff' | take 10 | take 100",
+			expectedTables: []string{"KubeEvents"},
+		},
+		{
+			name:           "let statement and join",
+			query:          "let pods = KubePodInventory | where PodStatus == 'Failed';\npods | join kind=inner (KubeEvents | project Name, Reason) on Name",
+			expectedQuery:  "let pods = (KubePodInventory | where _ResourceId =~ '" + testScope + "') | where PodStatus == 'Failed'; pods | join kind=inner ((KubeEvents | where _ResourceId =~ '" + testScope + "') | project Name, Reason) on Name | take 100",
+			expectedTables: []string{"KubePodInventory", "KubeEvents"},
+		},
+		{
+			name:           "union and comments",
+			query:          "union KubeEvents, (ContainerLogV2 | take 5) // recent\n| count",
+			expectedTables: []string{"KubeEvents", "ContainerLogV2"},
+		},
+		{
+			name:           "subquery in toscalar and dashed operators",
+			query:          "let since = ago(1h); KubeEvents | where TimeGenerated > since | extend total = toscalar(KubePodInventory | count) | project-away Message",
+			expectedTables: []string{"KubeEvents", "KubePodInventory"},
+		},
+		{
+			name:           "bracketed table name",
+			query:          "['KubeEvents'] | union [\"KubePodInventory\"]",
+			expectedQuery:  "(['KubeEvents'] | where _ResourceId =~ '" + testScope + "') | union ([\"KubePodInventory\"] | where _ResourceId =~ '" + testScope + "') | take 100",
+			expectedTables: []string{"KubeEvents", "KubePodInventory"},
+		},
+		{
+			name:           "scalar function in list",
+			query:          "KubeEvents | where Reason in (tolower('BackOff'), 'Failed')",
+			expectedTables: []string{"KubeEvents"},
+		},
+		{
+			name:           "in subquery",
+			query:          "ContainerLogV2 | where PodName in (KubePodInventory | where PodStatus == 'Failed' | project Name)",
+			expectedTables: []string{"ContainerLogV2", "KubePodInventory"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analysis, err := AnalyzeKQL(tt.query, testGuardrails)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.expectedQuery != "" && analysis.Query != tt.expectedQuery {
+				t.Errorf("expected query:\n%s\ngot:\n%s", tt.expectedQuery, analysis.Query)
+			}
+			if strings.Join(analysis.Tables, ",") != strings.Join(tt.expectedTables, ",") {
+				t.Errorf("expected tables %v, got %v", tt.expectedTables, analysis.Tables)
+			}
+			if strings.Contains(analysis.Query, "\n") || strings.Contains(analysis.Query, "// ") {
+				t.Errorf("comments and line breaks must be removed: %q", analysis.Query)
+			}
+			// Every table reference must be scoped
+			for _, table := range tt.expectedTables {
+				for _, rest := range strings.Split(analysis.Query, table)[1:] {
+					if !strings.HasPrefix(strings.TrimLeft(rest, `'"]`), " | where _ResourceId =~") {
+						t.Errorf("unscoped reference to %s in %s", table, analysis.Query)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestAnalyzeKQL_RejectsUnsafeQueries(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantErr string
+	}{
+		{"control command", ".drop table KubeEvents", "control commands"},
+		{"set statement", "set query_take_max_records=100000; KubeEvents", "'set' statements"},
+		{"cross-workspace", "KubeEvents | union workspace('other').KubeEvents", "function 'workspace'"},
+		{"app function", "app('other').requests", "function 'app'"},
+		{"dynamic table reference", "table('SecurityEvent')", "function 'table'"},
+		{"externaldata", "externaldata (x:string) [h@'https://example.com/data.csv']", "externaldata"},
+		{"unscoped table", "SecurityEvent | take 10", "table 'SecurityEvent' is not allowed"},
+		{"table in join", "KubeEvents | join (Heartbeat) on Computer", "table 'Heartbeat' is not allowed"},
+		{"table in union", "union KubeEvents, Heartbeat", "table 'Heartbeat' is not allowed"},
+		{"bracketed table in union", "KubeEvents | union ['Heartbeat']", "table 'Heartbeat' is not allowed"},
+		{"bracketed table in join", "KubeEvents | join kind=inner ['Heartbeat'] on x", "table 'Heartbeat' is not allowed"},
+		{"bracketed table in lookup", "KubeEvents | lookup ([\"Heartbeat\"]) on x", "table 'Heartbeat' is not allowed"},
+		{"bracketed table in subquery", "KubeEvents | where x in (['Heartbeat'] | project x)", "table 'Heartbeat' is not allowed"},
+		{"bracketed table in let", "let t = ['Heartbeat']; t", "table 'Heartbeat' is not allowed"},
+		{"bracketed table as source", "['Heartbeat'] | take 1", "table 'Heartbeat' is not allowed"},
+		{"verbatim bracketed table", "KubeEvents | union [@'Heartbeat']", "must have the form ['name']"},
+		{"bracketed expression", "KubeEvents | union [strcat('Heart', 'beat')]", "must have the form ['name']"},
+		{"stored function in list", "KubeEvents | where x in (MyStoredFunction())", "cannot be used as a data source"},
+		{"stored function in toscalar", "KubeEvents | extend n = toscalar(MyStoredFunction())", "cannot be used as a data source"},
+		{"unexpected source", "KubeEvents | union -1", "unexpected '-'"},
+		{"wildcard union", "union Kube*", "wildcard"},
+		{"search everything", "search 'error'", "across all tables"},
+		{"find", "find 'error'", "across all tables"},
+		{"write-like operator", "KubeEvents | render timechart", "operator 'render'"},
+		{"external plugin", "KubeEvents | evaluate http_request('https://example.com')", "plugin 'http_request'"},
+		{"python plugin", "KubeEvents | evaluate hint.distribution=per_node python(typeof(*), 'x')", "plugin 'python'"},
+		{"stored function as source", "MyStoredFunction()", "cannot be used as a data source"},
+		{"stored function in let", "let x = MyStoredFunction(); x", "cannot be used as a data source"},
+		{"user-defined function", "let f = (n:int) { KubeEvents | take n }; f(1)", "user-defined functions"},
+		{"let only", "let x = 1;", "must end with a tabular expression"},
+		{"unterminated string", "KubeEvents | where Reason == 'BackOff", "unterminated string"},
+		{"statement after query", "KubeEvents; KubePodInventory", "only let statements"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := AnalyzeKQL(tt.query, testGuardrails)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateKQLReadOnly(t *testing.T) {
+	allowed := []string{
+		"requests | where timestamp > ago(1h) | summarize count() by bin(timestamp, 5m)",
+		"requests | join (dependencies | where type == 'Http') on operation_Id",
+		"search 'timeout' | take 10",
+		"let threshold = 5; exceptions | where count_ > threshold",
+	}
+	for _, query := range allowed {
+		if err := ValidateKQLReadOnly(query); err != nil {
+			t.Errorf("expected %q to be allowed, got %v", query, err)
+		}
+	}
+
+	blocked := []string{
+		".set-or-append Target <| requests",
+		"requests | union app('other').requests",
+		"requests | evaluate sql_request('Server=x', 'select 1')",
+	}
+	for _, query := range blocked {
+		if err := ValidateKQLReadOnly(query); err == nil {
+			t.Errorf("expected %q to be blocked", query)
+		}
+	}
+}
+
+func TestAnalyzeKQL_RecordsLimits(t *testing.T) {
+	analysis, err := AnalyzeKQL("KubeEvents | take 5000 | limit 20", testGuardrails)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(analysis.Limits) != 2 || analysis.Limits[0] != 5000 || analysis.Limits[1] != 20 {
+		t.Errorf("expected limits [5000 20], got %v", analysis.Limits)
+	}
+	if !strings.HasSuffix(analysis.Query, "| take 100") {
+		t.Errorf("expected the row cap to be appended, got %s", analysis.Query)
+	}
+}
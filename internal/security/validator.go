@@ -346,51 +346,57 @@ func (v *Validator) isCompleteHereDocument(command string) bool {
 	return false
 }
 
-// validateKQLCommand validates az logs query commands with KQL analytics queries
+// validateKQLCommand validates az logs query commands with KQL analytics queries. The
+// parameters around the query are checked for shell patterns and the query itself is parsed
+// and checked for read-only use by ValidateKQLReadOnly.
 func (v *Validator) validateKQLCommand(command string) error {
-	// Extract the parts of the command outside the analytics-query parameter
-	// and validate those parts separately
-
-	// Split the command to isolate the KQL query
-	parts := strings.Split(command, "--analytics-query")
-	if len(parts) != 2 {
+	index := strings.Index(command, "--analytics-query")
+	if index < 0 {
 		return &ValidationError{Message: "Error: Invalid az logs query command structure"}
 	}
 
-	beforeQuery := parts[0]
-	afterQuery := parts[1]
-
 	// Validate the command structure before the analytics-query
-	if err := v.validateCommandParts(beforeQuery); err != nil {
+	if err := v.validateCommandParts(command[:index]); err != nil {
 		return err
 	}
 
-	// Extract and validate the rest of the command after the KQL query
-	// The afterQuery should start with the quoted KQL, followed by other parameters
-	afterQuery = strings.TrimSpace(afterQuery)
+	// The query must be a double-quoted argument, as the command is split with shell quoting rules
+	afterQuery := strings.TrimSpace(command[index+len("--analytics-query"):])
 	if len(afterQuery) == 0 {
 		return &ValidationError{Message: "Error: Missing KQL query in analytics-query parameter"}
 	}
-
-	// Find the end of the quoted KQL query
-	if afterQuery[0] == '"' {
-		// Find the closing quote (simple approach - assumes no escaped quotes in KQL)
-		closeQuoteIndex := strings.Index(afterQuery[1:], "\"")
-		if closeQuoteIndex == -1 {
-			return &ValidationError{Message: "Error: Unclosed quote in analytics-query parameter"}
+	if afterQuery[0] != '"' {
+		return &ValidationError{Message: "Error: KQL query must be properly quoted"}
+	}
+	closeQuoteIndex := -1
+	for i := 1; i < len(afterQuery); i++ {
+		if afterQuery[i] == '\\' {
+			i++
+			continue
 		}
+		if afterQuery[i] == '"' {
+			closeQuoteIndex = i
+			break
+		}
+	}
+	if closeQuoteIndex == -1 {
+		return &ValidationError{Message: "Error: Unclosed quote in analytics-query parameter"}
+	}
 
-		// Validate any parameters after the KQL query
-		remainingParams := strings.TrimSpace(afterQuery[closeQuoteIndex+2:])
-		if len(remainingParams) > 0 {
-			if err := v.validateCommandParts(remainingParams); err != nil {
-				return err
-			}
+	// Validate any parameters after the KQL query
+	remainingParams := strings.TrimSpace(afterQuery[closeQuoteIndex+1:])
+	if strings.Contains(remainingParams, "--analytics-query") {
+		return &ValidationError{Message: "Error: Invalid az logs query command structure"}
+	}
+	if len(remainingParams) > 0 {
+		if err := v.validateCommandParts(remainingParams); err != nil {
+			return err
 		}
-	} else {
-		return &ValidationError{Message: "Error: KQL query must be properly quoted"}
 	}
 
+	if err := ValidateKQLReadOnly(afterQuery[1:closeQuoteIndex]); err != nil {
+		return &ValidationError{Message: "Error: KQL query is not allowed: " + err.Error()}
+	}
 	return nil
 }
 
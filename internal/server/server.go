@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"fmt"
 	"log"
 
@@ -8,13 +9,19 @@ import (
 	"github.com/Azure/aks-mcp/internal/azureclient"
 	"github.com/Azure/aks-mcp/internal/components/advisor"
 	"github.com/Azure/aks-mcp/internal/components/azaks"
+	"github.com/Azure/aks-mcp/internal/components/baseline"
 	"github.com/Azure/aks-mcp/internal/components/compute"
+	"github.com/Azure/aks-mcp/internal/components/contexts"
 	"github.com/Azure/aks-mcp/internal/components/detectors"
+	"github.com/Azure/aks-mcp/internal/components/fanout"
 	"github.com/Azure/aks-mcp/internal/components/fleet"
 	"github.com/Azure/aks-mcp/internal/components/inspektorgadget"
 	"github.com/Azure/aks-mcp/internal/components/monitor"
 	"github.com/Azure/aks-mcp/internal/components/network"
+	"github.com/Azure/aks-mcp/internal/components/snapshot"
+	"github.com/Azure/aks-mcp/internal/components/upgrade"
 	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/confirm"
 	"github.com/Azure/aks-mcp/internal/k8s"
 	"github.com/Azure/aks-mcp/internal/prompts"
 	"github.com/Azure/aks-mcp/internal/tools"
@@ -66,6 +73,16 @@ func (s *Service) initializeInfrastructure() error {
 	s.azClient = azClient
 	log.Println("Azure client initialized successfully")
 
+	if err := confirm.DefaultGate().SetDelivery(confirm.TokenDelivery(s.cfg.ConfirmationTokenDelivery)); err != nil {
+		return err
+	}
+
+	// Sessions keep their own active cluster context, which is dropped when the session ends
+	hooks := &server.Hooks{}
+	hooks.AddOnUnregisterSession(func(_ context.Context, session server.ClientSession) {
+		s.cfg.ClusterContexts.RemoveSession(session.SessionID())
+	})
+
 	// Create MCP server
 	s.mcpServer = server.NewMCPServer(
 		"AKS MCP",
@@ -74,6 +91,7 @@ func (s *Service) initializeInfrastructure() error {
 		server.WithPromptCapabilities(true),
 		server.WithLogging(),
 		server.WithRecovery(),
+		server.WithHooks(hooks),
 	)
 	log.Println("MCP server initialized successfully")
 
@@ -129,9 +147,18 @@ func (s *Service) Run() error {
 func (s *Service) registerAzureComponents() {
 	log.Println("Registering Azure Components...")
 
+	// Cluster Context Component
+	s.registerClusterContextComponent()
+
 	// AKS Operations Component
 	s.registerAksOpsComponent()
 
+	// Configuration Baseline Component
+	s.registerBaselineComponent()
+
+	// Cluster Snapshot Component
+	s.registerSnapshotComponent()
+
 	// Monitoring Component
 	s.registerMonitoringComponent()
 
@@ -153,6 +180,9 @@ func (s *Service) registerAzureComponents() {
 	// Register Inspektor Gadget tools for observability
 	s.registerInspektorGadgetComponent()
 
+	// Multi-cluster fan-out over the read-only tools above
+	s.registerFanOutComponent()
+
 	log.Println("Azure Components registered successfully")
 }
 
@@ -217,11 +247,109 @@ func (s *Service) registerInspektorGadgetComponent() {
 	s.mcpServer.AddTool(inspektorGadget, tools.CreateResourceHandler(inspektorgadget.InspektorGadgetHandler(gadgetMgr, s.cfg), s.cfg))
 }
 
+// registerClusterContextComponent registers the cluster context tools
+func (s *Service) registerClusterContextComponent() {
+	log.Println("Registering Cluster Context Component")
+
+	log.Println("Registering cluster context tool: set_cluster_context")
+	setTool := contexts.RegisterSetClusterContextTool()
+	s.mcpServer.AddTool(setTool, tools.CreateResourceHandler(contexts.GetSetClusterContextHandler(s.azClient, s.cfg), s.cfg))
+
+	log.Println("Registering cluster context tool: get_cluster_context")
+	getTool := contexts.RegisterGetClusterContextTool()
+	s.mcpServer.AddTool(getTool, tools.CreateResourceHandler(contexts.GetGetClusterContextHandler(s.cfg), s.cfg))
+
+	log.Println("Registering cluster context tool: list_cluster_contexts")
+	listTool := contexts.RegisterListClusterContextsTool()
+	s.mcpServer.AddTool(listTool, tools.CreateResourceHandler(contexts.GetListClusterContextsHandler(s.cfg), s.cfg))
+}
+
+// registerFanOutComponent registers the fan_out tool for running read-only tools across clusters
+func (s *Service) registerFanOutComponent() {
+	log.Println("Registering fan-out tool: fan_out")
+
+	targets := map[string]fanout.Target{
+		"cluster_summary": {
+			Handler:     fanout.ClusterSummaryHandler(s.azClient),
+			ScopeParams: fanout.AKSParamsScope,
+		},
+		"aks_upgrade_plan": {
+			Handler:     upgrade.GetUpgradePlanHandler(s.azClient, s.cfg),
+			ScopeParams: fanout.AKSParamsScope,
+		},
+		"cluster_baseline_check": {
+			Handler:     baseline.GetBaselineCheckHandler(s.azClient, s.cfg),
+			ScopeParams: fanout.AKSParamsScope,
+		},
+		"az_monitoring": {
+			Handler:     monitor.GetAzMonitoringHandler(s.azClient, s.cfg),
+			ScopeParams: fanout.AKSParamsScope,
+			IsReadOnly: func(params map[string]interface{}) bool {
+				operation, _ := params["operation"].(string)
+				return monitor.IsReadOnlyMonitoringOperation(operation)
+			},
+		},
+		"az_network_resources": {
+			Handler:     network.GetAzNetworkResourcesHandler(s.azClient, s.cfg),
+			ScopeParams: fanout.AKSParamsScope,
+		},
+		"get_aks_vmss_info": {
+			Handler:     compute.GetAKSVMSSInfoHandler(s.azClient, s.cfg),
+			ScopeParams: fanout.AKSParamsScope,
+		},
+		"list_detectors": {
+			Handler:     detectors.GetListDetectorsHandler(s.azClient, s.cfg),
+			ScopeParams: fanout.ResourceIDScope,
+		},
+		"run_detector": {
+			Handler:     detectors.GetRunDetectorHandler(s.azClient, s.cfg),
+			ScopeParams: fanout.ResourceIDScope,
+		},
+		"run_detectors_by_category": {
+			Handler:     detectors.GetRunDetectorsByCategoryHandler(s.azClient, s.cfg),
+			ScopeParams: fanout.ResourceIDScope,
+		},
+		"az_aks_operations": {
+			Handler:     tools.ResourceHandlerFunc(azaks.NewAksOperationsExecutor(s.azClient).Execute),
+			ScopeParams: fanout.AKSParamsScope,
+			IsReadOnly: func(params map[string]interface{}) bool {
+				operation, _ := params["operation"].(string)
+				return azaks.IsClusterScopedReadOperation(operation)
+			},
+		},
+	}
+
+	fanOutTool := fanout.RegisterFanOutTool(fanout.TargetNames(targets))
+	s.mcpServer.AddTool(fanOutTool, tools.CreateResourceHandler(fanout.GetFanOutHandler(s.azClient, targets, s.cfg), s.cfg))
+}
+
 // registerAksOpsComponent registers AKS operations tools
 func (s *Service) registerAksOpsComponent() {
 	log.Println("Registering AKS operations tool: az_aks_operations")
 	aksOperationsTool := azaks.RegisterAzAksOperations(s.cfg)
-	s.mcpServer.AddTool(aksOperationsTool, tools.CreateToolHandler(azaks.NewAksOperationsExecutor(), s.cfg))
+	s.mcpServer.AddTool(aksOperationsTool, tools.CreateToolHandler(azaks.NewAksOperationsExecutor(s.azClient), s.cfg))
+
+	log.Println("Registering AKS operations tool: aks_operation_status")
+	statusTool := azaks.RegisterAksOperationStatusTool()
+	s.mcpServer.AddTool(statusTool, tools.CreateResourceHandler(azaks.GetAksOperationStatusHandler(azaks.DefaultOperationTracker(), s.azClient), s.cfg))
+
+	log.Println("Registering AKS operations tool: aks_upgrade_plan")
+	upgradePlanTool := upgrade.RegisterUpgradePlanTool()
+	s.mcpServer.AddTool(upgradePlanTool, tools.CreateResourceHandler(upgrade.GetUpgradePlanHandler(s.azClient, s.cfg), s.cfg))
+}
+
+// registerBaselineComponent registers the configuration baseline tool
+func (s *Service) registerBaselineComponent() {
+	log.Println("Registering baseline tool: cluster_baseline_check")
+	baselineTool := baseline.RegisterBaselineCheckTool()
+	s.mcpServer.AddTool(baselineTool, tools.CreateResourceHandler(baseline.GetBaselineCheckHandler(s.azClient, s.cfg), s.cfg))
+}
+
+// registerSnapshotComponent registers the cluster snapshot tool
+func (s *Service) registerSnapshotComponent() {
+	log.Println("Registering snapshot tool: cluster_snapshot")
+	snapshotTool := snapshot.RegisterClusterSnapshotTool()
+	s.mcpServer.AddTool(snapshotTool, tools.CreateResourceHandler(snapshot.GetClusterSnapshotHandler(s.azClient, s.cfg), s.cfg))
 }
 
 // registerMonitoringComponent registers Azure monitoring tools
@@ -253,6 +381,26 @@ func (s *Service) registerNetworkComponent() {
 	log.Println("Registering network tool: az_network_resources")
 	networkTool := network.RegisterAzNetworkResources()
 	s.mcpServer.AddTool(networkTool, tools.CreateResourceHandler(network.GetAzNetworkResourcesHandler(s.azClient, s.cfg), s.cfg))
+
+	// Register NSG flow evaluation tool
+	log.Println("Registering network tool: network_check_flow")
+	checkFlowTool := network.RegisterCheckFlowTool()
+	s.mcpServer.AddTool(checkFlowTool, tools.CreateResourceHandler(network.GetCheckFlowHandler(s.azClient, s.cfg), s.cfg))
+
+	// Register effective route analysis tool
+	log.Println("Registering network tool: network_route_analysis")
+	routeAnalysisTool := network.RegisterRouteAnalysisTool()
+	s.mcpServer.AddTool(routeAnalysisTool, tools.CreateResourceHandler(network.GetRouteAnalysisHandler(s.azClient, s.cfg), s.cfg))
+
+	// Register outbound connectivity diagnosis tool
+	log.Println("Registering network tool: outbound_diagnose")
+	outboundDiagnoseTool := network.RegisterOutboundDiagnoseTool()
+	s.mcpServer.AddTool(outboundDiagnoseTool, tools.CreateResourceHandler(network.GetOutboundDiagnoseHandler(s.azClient, s.cfg), s.cfg))
+
+	// Register subnet IP capacity planning tool
+	log.Println("Registering network tool: subnet_capacity")
+	subnetCapacityTool := network.RegisterSubnetCapacityTool()
+	s.mcpServer.AddTool(subnetCapacityTool, tools.CreateResourceHandler(network.GetSubnetCapacityHandler(s.azClient, s.cfg), s.cfg))
 }
 
 // registerComputeComponent registers compute-related Azure resource tools (VMSS/VM)
@@ -29,7 +29,7 @@ func (m *MockToolCounter) AddTool(toolName string) {
 	m.toolNames = append(m.toolNames, toolName)
 
 	// Categorize tools
-	azureToolPrefixes := []string{"az_", "azure_", "get_aks_", "list_detectors", "run_detector", "inspektor_gadget_observability"}
+	azureToolPrefixes := []string{"az_", "azure_", "get_aks_", "list_detectors", "run_detector", "inspektor_gadget_observability", "set_cluster_context", "get_cluster_context", "list_cluster_contexts", "fan_out", "aks_operation_status", "aks_upgrade_plan", "cluster_baseline_check", "cluster_snapshot"}
 	k8sToolPrefixes := []string{"kubectl_", "k8s_", "helm", "cilium"}
 
 	isAzureTool := false
@@ -87,24 +87,24 @@ func TestService(t *testing.T) {
 			name:               "ReadOnly_NoOptional",
 			accessLevel:        "readonly",
 			additionalTools:    map[string]bool{},
-			expectedAzureTools: 8, // AKS Ops + Monitoring + Fleet + Network + Compute (VMSS Info only) + Detectors (3) + Advisor + Inspektor Gadget
-			expectedK8sTools:   0, // Will be calculated based on kubectl tools for readonly
+			expectedAzureTools: 20, // Cluster Context (3) + AKS Ops (3) + Baseline + Snapshot + Monitoring + Fleet + Network (5) + Compute (VMSS Info only) + Detectors (3) + Advisor + Inspektor Gadget + Fan-out
+			expectedK8sTools:   0,  // Will be calculated based on kubectl tools for readonly
 			description:        "Readonly access with no optional tools",
 		},
 		{
 			name:               "ReadWrite_NoOptional",
 			accessLevel:        "readwrite",
 			additionalTools:    map[string]bool{},
-			expectedAzureTools: 9, // Same as readonly + 1 read-write VMSS command
-			expectedK8sTools:   0, // Will be calculated based on kubectl tools for readwrite
+			expectedAzureTools: 21, // Same as readonly + 1 read-write VMSS command
+			expectedK8sTools:   0,  // Will be calculated based on kubectl tools for readwrite
 			description:        "Readwrite access with no optional tools",
 		},
 		{
 			name:               "Admin_NoOptional",
 			accessLevel:        "admin",
 			additionalTools:    map[string]bool{},
-			expectedAzureTools: 9, // Same as readwrite (no admin VMSS commands currently)
-			expectedK8sTools:   0, // Will be calculated based on kubectl tools for admin
+			expectedAzureTools: 21, // Same as readwrite (no admin VMSS commands currently)
+			expectedK8sTools:   0,  // Will be calculated based on kubectl tools for admin
 			description:        "Admin access with no optional tools",
 		},
 		{
@@ -114,8 +114,8 @@ func TestService(t *testing.T) {
 				"helm":   true,
 				"cilium": true,
 			},
-			expectedAzureTools: 8, // Same as readonly (Inspektor Gadget now included automatically)
-			expectedK8sTools:   0, // Will be calculated + 2 optional tools
+			expectedAzureTools: 20, // Same as readonly (Inspektor Gadget now included automatically)
+			expectedK8sTools:   0,  // Will be calculated + 2 optional tools
 			description:        "Readonly access with all optional tools",
 		},
 	}
@@ -180,13 +180,17 @@ func TestComponentToolCounts(t *testing.T) {
 			toolCount   int
 			description string
 		}{
-			{"AKS Operations", 1, "az_aks_operations tool"},
+			{"Cluster Context", 3, "set_cluster_context, get_cluster_context, list_cluster_contexts"},
+			{"AKS Operations", 3, "az_aks_operations, aks_operation_status, aks_upgrade_plan"},
+			{"Baseline", 1, "cluster_baseline_check tool"},
+			{"Snapshot", 1, "cluster_snapshot tool"},
 			{"Monitoring", 1, "az_monitoring tool"},
 			{"Fleet", 1, "az_fleet tool"},
-			{"Network", 1, "az_network_resources tool"},
+			{"Network", 5, "az_network_resources, network_check_flow, network_route_analysis, outbound_diagnose, subnet_capacity"},
 			{"Advisor", 1, "az_advisor_recommendation tool"},
 			{"Detectors", 3, "list_detectors, run_detector, run_detectors_by_category"},
 			{"Inspektor Gadget", 1, "inspektor_gadget_observability tool"},
+			{"Fan-out", 1, "fan_out tool"},
 		}
 
 		for _, tc := range testCases {
@@ -306,7 +310,7 @@ func TestExpectedToolsByAccessLevel(t *testing.T) {
 	for _, level := range accessLevels {
 		t.Run("AccessLevel_"+level, func(t *testing.T) {
 			// Azure Components (always the same count, but different capabilities)
-			azureToolsCount := 8 // Base count (including Inspektor Gadget)
+			azureToolsCount := 13 // Base count (including Cluster Context, Inspektor Gadget and Fan-out)
 
 			// Add compute tools based on access level
 			readWriteVmssCount := len(compute.GetReadWriteVmssCommands())
@@ -325,10 +329,11 @@ func TestExpectedToolsByAccessLevel(t *testing.T) {
 
 			t.Logf("=== Access Level: %s ===", level)
 			t.Logf("Azure Tools:")
-			t.Logf("  - AKS Operations: 1")
+			t.Logf("  - Cluster Context: 3")
+			t.Logf("  - AKS Operations: 2")
 			t.Logf("  - Monitoring: 1")
 			t.Logf("  - Fleet: 1")
-			t.Logf("  - Network: 1")
+			t.Logf("  - Network: 5")
 			t.Logf("  - Compute Base: 1 (get_aks_vmss_info)")
 			t.Logf("  - Compute ReadWrite: %d", readWriteVmssCount)
 			if level == "admin" {
@@ -336,6 +341,7 @@ func TestExpectedToolsByAccessLevel(t *testing.T) {
 			}
 			t.Logf("  - Detectors: 3")
 			t.Logf("  - Advisor: 1")
+			t.Logf("  - Fan-out: 1")
 			t.Logf("  - Inspektor Gadget: 1 (automatically enabled)")
 			t.Logf("  Total Azure Tools: %d", azureToolsCount)
 
@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"log"
 
+	"github.com/Azure/aks-mcp/internal/clustercontext"
 	"github.com/Azure/aks-mcp/internal/config"
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
 )
 
 // logToolCall logs the start of a tool call
@@ -32,6 +34,21 @@ func logToolResult(toolName string, result string, err error) {
 }
 
 // CreateToolHandler creates an adapter that converts CommandExecutor to the format expected by MCP server
+// withSession returns a copy of the tool arguments with the MCP session ID of the call, so
+// handlers can keep per-session state such as the active cluster context. A session ID sent
+// by the client is dropped.
+func withSession(ctx context.Context, args map[string]interface{}) map[string]interface{} {
+	params := make(map[string]interface{}, len(args)+1)
+	for key, value := range args {
+		params[key] = value
+	}
+	delete(params, clustercontext.SessionParam)
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		params[clustercontext.SessionParam] = session.SessionID()
+	}
+	return params
+}
+
 func CreateToolHandler(executor CommandExecutor, cfg *config.ConfigData) func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		if cfg.Verbose {
@@ -48,7 +65,7 @@ func CreateToolHandler(executor CommandExecutor, cfg *config.ConfigData) func(ct
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		result, err := executor.Execute(args, cfg)
+		result, err := executor.Execute(withSession(ctx, args), cfg)
 		if cfg.TelemetryService != nil {
 			operation, _ := args["operation"].(string)
 			cfg.TelemetryService.TrackToolInvocation(ctx, req.Params.Name, operation, err == nil)
@@ -83,7 +100,7 @@ func CreateResourceHandler(handler ResourceHandler, cfg *config.ConfigData) func
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		result, err := handler.Handle(args, cfg)
+		result, err := handler.Handle(withSession(ctx, args), cfg)
 
 		// Track tool invocation with minimal data
 		if cfg.TelemetryService != nil {
@@ -1,9 +1,13 @@
 package tools
 
 import (
+	"context"
 	"testing"
 
+	"github.com/Azure/aks-mcp/internal/clustercontext"
 	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
 )
 
 func TestResourceHandlerInterface(t *testing.T) {
@@ -43,3 +47,39 @@ func TestCommandExecutorStillWorks(t *testing.T) {
 		t.Errorf("Expected 'command result', got: %s", result)
 	}
 }
+
+// testSession is a minimal MCP client session
+type testSession struct {
+	id string
+}
+
+func (s testSession) Initialize()                                         {}
+func (s testSession) Initialized() bool                                   { return true }
+func (s testSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return nil }
+func (s testSession) SessionID() string                                   { return s.id }
+
+func TestCreateResourceHandler_SetsSessionID(t *testing.T) {
+	var got map[string]interface{}
+	handler := CreateResourceHandler(ResourceHandlerFunc(func(params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+		got = params
+		return "ok", nil
+	}), config.NewConfig())
+
+	ctx := server.NewMCPServer("test", "1.0").WithContext(context.Background(), testSession{id: "session-1"})
+	req := mcp.CallToolRequest{}
+	// A session ID sent by the client is replaced by the one of the connection
+	req.Params.Arguments = map[string]interface{}{"cluster_name": "aks-1", clustercontext.SessionParam: "session-2"}
+	if _, err := handler(ctx, req); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if clustercontext.SessionID(got) != "session-1" || got["cluster_name"] != "aks-1" {
+		t.Errorf("Expected the connection's session ID to be passed to the handler, got %v", got)
+	}
+
+	if _, err := handler(context.Background(), req); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if clustercontext.SessionID(got) != "" {
+		t.Errorf("Expected no session ID outside of a session, got %v", got)
+	}
+}